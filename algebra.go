@@ -0,0 +1,362 @@
+package json2go
+
+import "sort"
+
+// irDecl identifies declared type of result of Merge or Subtract, built from declared types of a and b.
+type irDecl struct {
+	// op is "merge", "subtract", or "a" and "b" for copies of types of a and b. Empty op isn't memoized.
+	op   string
+	a, b string
+}
+
+// irAlgebra builds schema from declared types of schemas a and b.
+type irAlgebra struct {
+	a, b  *Schema
+	out   *Schema
+	names map[irDecl]string
+	// merged are names of types of result merging declared types of a, or of b, by irDecl with their name only.
+	merged map[irDecl]string
+	// unknownAny is true if any values of b are values of unknown kind, see mergeSamples.
+	unknownAny bool
+}
+
+func newIRAlgebra(a, b *Schema) *irAlgebra {
+	return &irAlgebra{
+		a:      a,
+		b:      b,
+		out:    &Schema{Types: make(map[string]*SchemaType)},
+		names:  make(map[irDecl]string),
+		merged: make(map[irDecl]string),
+	}
+}
+
+// Merge returns schema of values of both schemas, like types inferred from samples of both, e.g. to compose
+// types of responses of several services without feeding their samples again. Root type has name of root type of a.
+//
+// Struct fields present only in one of schemas get omitempty, ints merged with floats are float64, times merged
+// with strings are strings, and other values of different kinds are any values, like in inference.
+// Declared types keep their names, names of types of b conflicting with other types get numeric suffixes.
+func Merge(a, b *Schema) (*Schema, error) {
+	return mergeSchemas(a, b, false)
+}
+
+// mergeSamples is Merge of schema b inferred from samples into schema a, where any values of b are values
+// of unknown kind, like nulls and elements of empty arrays, so they have types of values of a.
+func mergeSamples(a, b *Schema) (*Schema, error) {
+	return mergeSchemas(a, b, true)
+}
+
+func mergeSchemas(a, b *Schema, unknownAny bool) (*Schema, error) {
+	if err := validateIR(a); err != nil {
+		return nil, err
+	}
+	if err := validateIR(b); err != nil {
+		return nil, err
+	}
+
+	m := newIRAlgebra(a, b)
+	m.unknownAny = unknownAny
+	root := m.merge(&SchemaType{Kind: SchemaNamed, Name: a.Root}, &SchemaType{Kind: SchemaNamed, Name: b.Root})
+	m.out.Root = root.Name
+	return m.out, nil
+}
+
+// Subtract returns schema of parts of values of a, which aren't values of b, like fields unique to newer version
+// of API: struct fields of a, which b has no fields with the same keys and types for. Fields with structs, or with
+// slices, maps and pointers of structs, in both schemas, keep only their fields missing in b, fields with different
+// types are kept whole. Root type has name of root type of a, it's an empty struct if nothing is missing in b.
+func Subtract(a, b *Schema) (*Schema, error) {
+	if err := validateIR(a); err != nil {
+		return nil, err
+	}
+	if err := validateIR(b); err != nil {
+		return nil, err
+	}
+
+	m := newIRAlgebra(a, b)
+	root, ok := m.subtract(&SchemaType{Kind: SchemaNamed, Name: a.Root}, &SchemaType{Kind: SchemaNamed, Name: b.Root})
+	if !ok {
+		root = &SchemaType{Kind: SchemaNamed, Name: a.Root}
+		m.out.Types[a.Root] = &SchemaType{Kind: SchemaStruct}
+	}
+	m.out.Root = root.Name
+	return m.out, nil
+}
+
+// declare returns reference to type of result, declared with name, or with name with numeric suffix if it's taken.
+// Type is built by build with declared name, or it isn't declared if build returns nil. Types with the same key are declared once,
+// so recursive types refer to themselves.
+func (m *irAlgebra) declare(key irDecl, name string, build func(name string) *SchemaType) (*SchemaType, bool) {
+	if declared, ok := m.names[key]; ok && key.op != "" {
+		return &SchemaType{Kind: SchemaNamed, Name: declared}, declared != ""
+	}
+
+	for _, taken := m.out.Types[name]; taken; _, taken = m.out.Types[name] {
+		name = nextName(name)
+	}
+	// Name is reserved while type is built.
+	m.out.Types[name] = nil
+	if key.op != "" {
+		m.names[key] = name
+	}
+	t := build(name)
+	if t == nil {
+		delete(m.out.Types, name)
+		if key.op != "" {
+			m.names[key] = ""
+		}
+		return nil, false
+	}
+	m.out.Types[name] = t
+	return &SchemaType{Kind: SchemaNamed, Name: name}, true
+}
+
+// copy returns copy of type of a, or of b, with references to copies of declared types.
+func (m *irAlgebra) copy(t *SchemaType, fromA bool) *SchemaType {
+	if t == nil {
+		return nil
+	}
+	if t.Kind == SchemaNamed {
+		if name, ok := m.mergedWith(t.Name, fromA); ok {
+			// Merged type has values of copied type too.
+			return &SchemaType{Kind: SchemaNamed, Name: name}
+		}
+		s, key := m.b, irDecl{op: "b", b: t.Name}
+		if fromA {
+			s, key = m.a, irDecl{op: "a", a: t.Name}
+		}
+		copied, _ := m.declare(key, t.Name, func(string) *SchemaType {
+			return m.copy(s.Types[t.Name], fromA)
+		})
+		return copied
+	}
+
+	c := *t
+	c.Elem = m.copy(t.Elem, fromA)
+	c.Key = m.copy(t.Key, fromA)
+	c.Fields = nil
+	for _, f := range t.Fields {
+		f.Type = m.copy(f.Type, fromA)
+		c.Fields = append(c.Fields, f)
+	}
+	return &c
+}
+
+// mergedWith returns name of first type of result merging declared type of a, or of b, with other type.
+func (m *irAlgebra) mergedWith(name string, fromA bool) (string, bool) {
+	key := irDecl{b: name}
+	if fromA {
+		key = irDecl{a: name}
+	}
+	merged, ok := m.merged[key]
+	return merged, ok
+}
+
+// merge returns type of values of type x of a and of type y of b.
+func (m *irAlgebra) merge(x, y *SchemaType) *SchemaType {
+	if m.unknownAny && y.Kind == SchemaAny {
+		return m.copy(x, true)
+	}
+	if isNullableSchemaType(x) || isNullableSchemaType(y) {
+		kind := x.Kind
+		if !isNullableSchemaType(x) {
+			kind = y.Kind
+		}
+		return &SchemaType{Kind: kind, Elem: m.merge(nonNullSchemaType(x), nonNullSchemaType(y))}
+	}
+	if x.Kind == SchemaNamed || y.Kind == SchemaNamed {
+		// Types declared in both schemas are merged once, other declared types are merged for each type
+		// they are merged with.
+		key := irDecl{}
+		if x.Kind == SchemaNamed && y.Kind == SchemaNamed {
+			key = irDecl{op: "merge", a: x.Name, b: y.Name}
+		}
+		var sides []irDecl
+		name := ""
+		if y.Kind == SchemaNamed {
+			name = y.Name
+			sides = append(sides, irDecl{b: y.Name})
+			y = m.b.Types[y.Name]
+		}
+		if x.Kind == SchemaNamed {
+			name = x.Name
+			sides = append(sides, irDecl{a: x.Name})
+			x = m.a.Types[x.Name]
+		}
+		merged, _ := m.declare(key, name, func(declared string) *SchemaType {
+			for _, side := range sides {
+				if _, ok := m.merged[side]; !ok {
+					m.merged[side] = declared
+				}
+			}
+			return m.merge(x, y)
+		})
+		return merged
+	}
+
+	switch {
+	case x.Kind == y.Kind:
+		switch x.Kind {
+		case SchemaFloat:
+			bits := x.Bits
+			if y.Bits > bits {
+				bits = y.Bits
+			}
+			return &SchemaType{Kind: SchemaFloat, Bits: bits}
+		case SchemaString:
+			if x.Format != y.Format {
+				return &SchemaType{Kind: SchemaString}
+			}
+		case SchemaSlice:
+			return &SchemaType{Kind: SchemaSlice, Elem: m.merge(x.Elem, y.Elem), SkipNulls: x.SkipNulls && y.SkipNulls}
+		case SchemaMap:
+			key := m.copy(x.Key, true)
+			if mapKeyKind(x) != mapKeyKind(y) {
+				key = &SchemaType{Kind: SchemaString}
+			}
+			return &SchemaType{Kind: SchemaMap, Key: key, Elem: m.merge(x.Elem, y.Elem)}
+		case SchemaStruct:
+			return m.mergeStructs(x, y)
+		}
+		return m.copy(x, true)
+	case x.Kind == SchemaInt && y.Kind == SchemaFloat || x.Kind == SchemaFloat && y.Kind == SchemaInt:
+		return &SchemaType{Kind: SchemaFloat, Bits: 64}
+	case x.Kind == SchemaTime && y.Kind == SchemaString || x.Kind == SchemaString && y.Kind == SchemaTime:
+		return &SchemaType{Kind: SchemaString}
+	case x.Kind == SchemaOpaque || y.Kind == SchemaOpaque:
+		// Raw values keep values of any kind.
+		return &SchemaType{Kind: SchemaOpaque}
+	}
+	return &SchemaType{Kind: SchemaAny}
+}
+
+// mergeStructs returns struct with fields of both structs. Fields of x keep their order, followed by fields of y,
+// unless fields of x are sorted by names, then fields of result are sorted too.
+func (m *irAlgebra) mergeStructs(x, y *SchemaType) *SchemaType {
+	t := &SchemaType{Kind: SchemaStruct, KeepsUnknown: x.KeepsUnknown || y.KeepsUnknown}
+	names := make(map[string]bool)
+	for _, f := range x.Fields {
+		names[f.Name] = true
+		if g := schemaFieldByKey(y, f.Key); g != nil {
+			f.Type = m.merge(f.Type, g.Type)
+			f.OmitEmpty = f.OmitEmpty || g.OmitEmpty
+			f.OmitZero = f.OmitZero || g.OmitZero
+		} else {
+			f.Type = m.copy(f.Type, true)
+			f.OmitEmpty = true
+		}
+		t.Fields = append(t.Fields, f)
+	}
+	for _, g := range y.Fields {
+		if schemaFieldByKey(x, g.Key) != nil {
+			continue
+		}
+		for names[g.Name] {
+			g.Name = nextName(g.Name)
+		}
+		names[g.Name] = true
+		g.Type = m.copy(g.Type, false)
+		g.OmitEmpty = true
+		t.Fields = append(t.Fields, g)
+	}
+
+	sorted := sort.SliceIsSorted(x.Fields, func(i, j int) bool {
+		return x.Fields[i].Name < x.Fields[j].Name
+	})
+	if sorted {
+		sort.SliceStable(t.Fields, func(i, j int) bool {
+			return t.Fields[i].Name < t.Fields[j].Name
+		})
+	}
+	return t
+}
+
+// subtract returns part of type x of a, which isn't in type y of b, and false if there is no such part.
+func (m *irAlgebra) subtract(x, y *SchemaType) (*SchemaType, bool) {
+	if y == nil {
+		return m.copy(x, true), true
+	}
+	if isNullableSchemaType(x) || isNullableSchemaType(y) {
+		t, ok := m.subtract(nonNullSchemaType(x), nonNullSchemaType(y))
+		if ok && isNullableSchemaType(x) {
+			t = &SchemaType{Kind: x.Kind, Elem: t}
+		}
+		return t, ok
+	}
+	if y.Kind == SchemaNamed && x.Kind != SchemaNamed {
+		return m.subtract(x, m.b.Types[y.Name])
+	}
+	if x.Kind == SchemaNamed {
+		key := irDecl{}
+		if y.Kind == SchemaNamed {
+			key = irDecl{op: "subtract", a: x.Name, b: y.Name}
+			y = m.b.Types[y.Name]
+		}
+		name := x.Name
+		x = m.a.Types[x.Name]
+		return m.declare(key, name, func(string) *SchemaType {
+			t, _ := m.subtract(x, y)
+			return t
+		})
+	}
+
+	switch {
+	case x.Kind == SchemaStruct && y.Kind == SchemaStruct:
+		t := &SchemaType{Kind: SchemaStruct, KeepsUnknown: x.KeepsUnknown}
+		for _, f := range x.Fields {
+			ft, ok := m.subtract(f.Type, fieldTypeOrNil(schemaFieldByKey(y, f.Key)))
+			if ok {
+				f.Type = ft
+				t.Fields = append(t.Fields, f)
+			}
+		}
+		return t, len(t.Fields) > 0
+	case x.Kind == SchemaSlice && y.Kind == SchemaSlice:
+		elem, ok := m.subtract(x.Elem, y.Elem)
+		return &SchemaType{Kind: SchemaSlice, Elem: elem, SkipNulls: x.SkipNulls}, ok
+	case x.Kind == SchemaMap && y.Kind == SchemaMap && mapKeyKind(x) == mapKeyKind(y):
+		elem, ok := m.subtract(x.Elem, y.Elem)
+		return &SchemaType{Kind: SchemaMap, Key: m.copy(x.Key, true), Elem: elem}, ok
+	case x.Kind == y.Kind && x.Bits == y.Bits && x.Format == y.Format && x.Kind != SchemaSlice && x.Kind != SchemaMap:
+		return nil, false
+	}
+	return m.copy(x, true), true
+}
+
+// isNullableSchemaType returns true for pointers and optional values.
+func isNullableSchemaType(t *SchemaType) bool {
+	return t != nil && (t.Kind == SchemaPointer || t.Kind == SchemaOptional)
+}
+
+// nonNullSchemaType returns type of values of pointers and optional values, or type itself.
+func nonNullSchemaType(t *SchemaType) *SchemaType {
+	if isNullableSchemaType(t) {
+		return t.Elem
+	}
+	return t
+}
+
+// mapKeyKind returns kind of keys of map type, maps without key type have string keys.
+func mapKeyKind(t *SchemaType) SchemaKind {
+	if t.Key == nil {
+		return SchemaString
+	}
+	return t.Key.Kind
+}
+
+// schemaFieldByKey returns field of struct type with json key, or nil.
+func schemaFieldByKey(t *SchemaType, key string) *SchemaField {
+	for i := range t.Fields {
+		if t.Fields[i].Key == key {
+			return &t.Fields[i]
+		}
+	}
+	return nil
+}
+
+func fieldTypeOrNil(f *SchemaField) *SchemaType {
+	if f == nil {
+		return nil
+	}
+	return f.Type
+}
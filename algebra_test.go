@@ -0,0 +1,110 @@
+package json2go
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSchema returns IR inferred from json samples.
+func testSchema(t *testing.T, rootName string, samples ...string) *Schema {
+	p := NewJSONParser(rootName, OptExtractCommonTypes(true))
+	for _, s := range samples {
+		require.NoError(t, p.FeedBytes([]byte(s)))
+	}
+	return p.Schema()
+}
+
+func TestMerge(t *testing.T) {
+	t.Parallel()
+
+	a := testSchema(t, "User", `{"id":1,"name":"a","created":"2021-01-01T00:00:00Z","home":{"city":"x"},"work":{"city":"y"}}`)
+	b := testSchema(t, "Account", `{"id":1.5,"email":"a@example.com","created":"yesterday","tags":["a"],"home":{"city":"z","zip":"1"}}`)
+	merged, err := Merge(a, b)
+	require.NoError(t, err)
+	code, err := GoEmitter{}.Emit(merged)
+	require.NoError(t, err)
+	assert.Equal(t, "type User struct {\n"+
+		"\tCreated string   `json:\"created\"`\n"+
+		"\tEmail   string   `json:\"email,omitempty\"`\n"+
+		"\tHome    City     `json:\"home\"`\n"+
+		"\tID      float64  `json:\"id\"`\n"+
+		"\tName    string   `json:\"name,omitempty\"`\n"+
+		"\tTags    []string `json:\"tags,omitempty\"`\n"+
+		"\tWork    City     `json:\"work,omitempty\"`\n"+
+		"}\n\n"+
+		"type City struct {\n"+
+		"\tCity string `json:\"city\"`\n"+
+		"\tZip  string `json:\"zip,omitempty\"`\n"+
+		"}\n", string(code))
+
+	// Merging is commutative, except for names and order of fields.
+	merged, err = Merge(b, a)
+	require.NoError(t, err)
+	assert.Equal(t, "Account", merged.Root)
+	assert.Len(t, merged.Types["Account"].Fields, 7)
+
+	merged, err = Merge(testSchema(t, "A", `{"a":1}`), testSchema(t, "B", `{"a":{"b":1}}`))
+	require.NoError(t, err)
+	assert.Equal(t, SchemaAny, merged.Types["A"].Fields[0].Type.Kind)
+
+	_, err = Merge(a, &Schema{Root: "Missing"})
+	assert.True(t, errors.Is(err, ErrInvalidIR), err)
+}
+
+func TestMergeRecursive(t *testing.T) {
+	t.Parallel()
+
+	a := &Schema{Root: "Node", Types: map[string]*SchemaType{"Node": {Kind: SchemaStruct, Fields: []SchemaField{
+		{Name: "Children", Key: "children", Type: &SchemaType{Kind: SchemaSlice, Elem: &SchemaType{Kind: SchemaNamed, Name: "Node"}}},
+		{Name: "ID", Key: "id", Type: &SchemaType{Kind: SchemaInt}},
+	}}}}
+	b := &Schema{Root: "Node", Types: map[string]*SchemaType{"Node": {Kind: SchemaStruct, Fields: []SchemaField{
+		{Name: "Name", Key: "name", Type: &SchemaType{Kind: SchemaString}},
+	}}}}
+	merged, err := Merge(a, b)
+	require.NoError(t, err)
+	code, err := GoEmitter{}.Emit(merged)
+	require.NoError(t, err)
+	assert.Equal(t, "type Node struct {\n"+
+		"\tChildren []Node `json:\"children,omitempty\"`\n"+
+		"\tID       int    `json:\"id,omitempty\"`\n"+
+		"\tName     string `json:\"name,omitempty\"`\n"+
+		"}\n", string(code))
+}
+
+func TestSubtract(t *testing.T) {
+	t.Parallel()
+
+	v1 := testSchema(t, "User", `{"id":1,"name":"a","address":{"city":"x"},"roles":[{"name":"admin"}]}`)
+	v2 := testSchema(t, "User", `{"id":"1","name":"a","email":"a@example.com","address":{"city":"x","zip":"1"},`+
+		`"roles":[{"name":"admin","scope":"all"}]}`)
+	diff, err := Subtract(v2, v1)
+	require.NoError(t, err)
+	code, err := GoEmitter{}.Emit(diff)
+	require.NoError(t, err)
+	assert.Equal(t, "type User struct {\n"+
+		"\tAddress struct {\n"+
+		"\t\tZip string `json:\"zip\"`\n"+
+		"\t} `json:\"address\"`\n"+
+		"\tEmail string `json:\"email\"`\n"+
+		"\tID    string `json:\"id\"`\n"+
+		"\tRoles []struct {\n"+
+		"\t\tScope string `json:\"scope\"`\n"+
+		"\t} `json:\"roles\"`\n"+
+		"}\n", string(code))
+
+	diff, err = Subtract(v1, v2)
+	require.NoError(t, err)
+	code, err = GoEmitter{}.Emit(diff)
+	require.NoError(t, err)
+	assert.Equal(t, "type User struct {\n"+
+		"\tID int `json:\"id\"`\n"+
+		"}\n", string(code))
+
+	diff, err = Subtract(v1, v1)
+	require.NoError(t, err)
+	assert.Equal(t, &Schema{Root: "User", Types: map[string]*SchemaType{"User": {Kind: SchemaStruct}}}, diff)
+}
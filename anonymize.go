@@ -0,0 +1,416 @@
+package json2go
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Range of synthetic times, from 2000 to 2030.
+const (
+	anonymizedTimeMin = 946684800
+	anonymizedTimeMax = 1893456000
+)
+
+// Anonymizer replaces json values with synthetic values of the same formats and lengths, so samples of
+// production data can be shared without leaking personal data, while types inferred from them don't change.
+// Bools, nulls and keys of objects naming attributes are kept. Keys with identifiers, like integers, uuids,
+// email addresses or times, are anonymized like values, so only names of fields with such keys change. Equal values are replaced with equal values, so enums and
+// repeated values stay recognizable:
+//
+//   - letters and digits of strings are replaced with random ones of the same case, other characters are kept,
+//   - numbers keep count of digits, integers stay integers and Unix times stay in the same range,
+//   - times, uuids, email addresses, urls and query strings are replaced with random ones,
+//   - json strings, JWTs and base64 encoded json are decoded, anonymized and encoded back,
+//   - bool strings, like "yes", and empty strings are kept.
+type Anonymizer struct {
+	secret []byte
+}
+
+// NewAnonymizer returns anonymizer with replacements derived from seed. Replacements of the same seed are
+// reproducible, and may be guessed by anyone knowing it. Seed 0 means random seed.
+func NewAnonymizer(seed int64) *Anonymizer {
+	secret := make([]byte, sha256.Size)
+	if seed == 0 {
+		if _, err := rand.Read(secret); err == nil {
+			return &Anonymizer{secret: secret}
+		}
+		seed = time.Now().UnixNano()
+	}
+	sum := sha256.Sum256([]byte(strconv.FormatInt(seed, 10)))
+	return &Anonymizer{secret: sum[:]}
+}
+
+// Value returns copy of json value, decoded to empty interface, with anonymized values.
+func (a *Anonymizer) Value(v interface{}) interface{} {
+	switch typedValue := v.(type) {
+	case map[string]interface{}:
+		obj := make(map[string]interface{}, len(typedValue))
+		for key, value := range typedValue {
+			obj[a.key(key)] = a.Value(value)
+		}
+		return obj
+	case []interface{}:
+		arr := make([]interface{}, len(typedValue))
+		for i, el := range typedValue {
+			arr[i] = a.Value(el)
+		}
+		return arr
+	case string:
+		return a.String(typedValue)
+	case float64:
+		f, err := strconv.ParseFloat(a.number(strconv.FormatFloat(typedValue, 'f', -1, 64)), 64)
+		if err != nil {
+			return typedValue
+		}
+		return f
+	case json.Number:
+		return json.Number(a.number(typedValue.String()))
+	}
+	return v
+}
+
+// JSON returns compact json documents with anonymized values, keeping order of keys.
+func (a *Anonymizer) JSON(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	type container struct {
+		object bool
+		// tokens is a number of keys and values in container.
+		tokens int
+	}
+	var stack []container
+	var out bytes.Buffer
+	for {
+		token, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		isKey := false
+		if len(stack) == 0 {
+			if out.Len() > 0 {
+				out.WriteByte('\n')
+			}
+		} else if top := &stack[len(stack)-1]; token != json.Delim('}') && token != json.Delim(']') {
+			isKey = top.object && top.tokens%2 == 0
+			switch {
+			case top.object && top.tokens%2 == 1:
+				out.WriteByte(':')
+			case top.tokens > 0:
+				out.WriteByte(',')
+			}
+			top.tokens++
+		}
+
+		switch typedToken := token.(type) {
+		case json.Delim:
+			out.WriteRune(rune(typedToken))
+			switch typedToken {
+			case '{', '[':
+				stack = append(stack, container{object: typedToken == '{'})
+			default:
+				stack = stack[:len(stack)-1]
+			}
+		case string:
+			if isKey {
+				typedToken = a.key(typedToken)
+			} else {
+				typedToken = a.String(typedToken)
+			}
+			enc := json.NewEncoder(&out)
+			enc.SetEscapeHTML(false)
+			_ = enc.Encode(typedToken)
+			// Encoder terminates values with newline.
+			out.Truncate(out.Len() - 1)
+		case json.Number:
+			out.WriteString(a.number(typedToken.String()))
+		case bool:
+			out.WriteString(strconv.FormatBool(typedToken))
+		case nil:
+			out.WriteString("null")
+		}
+	}
+	if len(stack) > 0 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return out.Bytes(), nil
+}
+
+// String returns anonymized string of the same format.
+func (a *Anonymizer) String(s string) string {
+	if s == "" || isBoolString(s) {
+		return s
+	}
+	r := a.rand("string", s)
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return r.time(s, t)
+	}
+	if uuidRe.MatchString(s) {
+		return r.uuid()
+	}
+	if _, ok := decodeJWTClaims(s); ok {
+		return a.jwt(s, r)
+	}
+	if isJSONString(s) {
+		if out, err := a.JSON([]byte(s)); err == nil {
+			return string(out)
+		}
+	}
+	if _, ok := decodeBase64JSON(s); ok {
+		return a.base64JSON(s)
+	}
+	if urlRe.MatchString(s) {
+		if u, err := url.Parse(s); err == nil {
+			return a.url(u, r)
+		}
+	}
+	if emailRe.MatchString(s) {
+		return r.scramble(s[:strings.LastIndexByte(s, '@')]) + "@example.com"
+	}
+	if isQueryString(s) {
+		return a.query(s)
+	}
+	if protoDurationRe.MatchString(s) {
+		return r.scrambleDigits(s)
+	}
+	for _, prefix := range []string{"Bearer ", "Basic "} {
+		if strings.HasPrefix(s, prefix) {
+			return prefix + r.scramble(s[len(prefix):])
+		}
+	}
+	if strings.HasPrefix(s, "-----BEGIN ") {
+		// Armor lines of PEM blocks are kept.
+		lines := strings.Split(s, "\n")
+		for i, line := range lines {
+			if !strings.HasPrefix(line, "-----") {
+				lines[i] = r.scramble(line)
+			}
+		}
+		return strings.Join(lines, "\n")
+	}
+	return r.scramble(s)
+}
+
+// key returns anonymized key of object, if it's an identifier, like keys of maps.
+func (a *Anonymizer) key(key string) string {
+	if mapKeyTypeFromKey(key) != mapKeyString || emailRe.MatchString(key) {
+		return a.String(key)
+	}
+	if _, err := time.Parse(time.RFC3339, key); err == nil {
+		return a.String(key)
+	}
+	return key
+}
+
+// number returns anonymized json number of the same count of digits.
+func (a *Anonymizer) number(s string) string {
+	return a.rand("number", s).scrambleDigits(s)
+}
+
+// jwt returns JWT with anonymized header and claims, and random signature.
+func (a *Anonymizer) jwt(s string, r *anonymizerRand) string {
+	parts := strings.Split(s, ".")
+	for i, part := range parts[:2] {
+		data, err := decodeBase64(part)
+		if err != nil {
+			continue
+		}
+		if anonymized, err := a.JSON(data); err == nil {
+			parts[i] = base64.RawURLEncoding.EncodeToString(anonymized)
+		}
+	}
+	parts[2] = r.scramble(parts[2])
+	return strings.Join(parts, ".")
+}
+
+// base64JSON returns base64 string with anonymized json, encoded with the same alphabet and padding.
+func (a *Anonymizer) base64JSON(s string) string {
+	data, _ := decodeBase64(s)
+	anonymized, err := a.JSON(data)
+	if err != nil {
+		return s
+	}
+	encoding := base64.StdEncoding
+	if strings.ContainsAny(s, "-_") {
+		encoding = base64.URLEncoding
+	}
+	if !strings.HasSuffix(s, "=") {
+		encoding = encoding.WithPadding(base64.NoPadding)
+	}
+	return encoding.EncodeToString(anonymized)
+}
+
+// url returns url with example.com host and anonymized user info, path, query and fragment.
+func (a *Anonymizer) url(u *url.URL, r *anonymizerRand) string {
+	if u.User != nil {
+		password, ok := u.User.Password()
+		if ok {
+			u.User = url.UserPassword(r.scramble(u.User.Username()), r.scramble(password))
+		} else {
+			u.User = url.User(r.scramble(u.User.Username()))
+		}
+	}
+	u.Host = "example.com"
+	u.Path = r.scramble(u.Path)
+	u.RawPath = ""
+	if isQueryString(u.RawQuery) {
+		u.RawQuery = a.query(u.RawQuery)
+	} else {
+		u.RawQuery = r.scramble(u.RawQuery)
+	}
+	u.Fragment = r.scramble(u.Fragment)
+	return u.String()
+}
+
+// query returns url encoded query with the same keys, in the same order, and anonymized values.
+func (a *Anonymizer) query(s string) string {
+	pairs := strings.Split(s, "&")
+	for i, pair := range pairs {
+		j := strings.IndexByte(pair, '=')
+		value, err := url.QueryUnescape(pair[j+1:])
+		if err != nil {
+			continue
+		}
+		pairs[i] = pair[:j+1] + url.QueryEscape(a.String(value))
+	}
+	return strings.Join(pairs, "&")
+}
+
+// rand returns random generator of replacements of value of kind, seeded with keyed hash of value.
+func (a *Anonymizer) rand(kind, value string) *anonymizerRand {
+	mac := hmac.New(sha256.New, a.secret)
+	mac.Write([]byte(kind))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	return &anonymizerRand{state: binary.LittleEndian.Uint64(mac.Sum(nil))}
+}
+
+// anonymizerRand is a splitmix64 generator of replacements of single value.
+type anonymizerRand struct {
+	state uint64
+}
+
+// intn returns random number in [0, n).
+func (r *anonymizerRand) intn(n int) int {
+	r.state += 0x9e3779b97f4a7c15
+	z := r.state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	z ^= z >> 31
+	return int(z % uint64(n))
+}
+
+// digit returns random digit, not zero if nonZero is true.
+func (r *anonymizerRand) digit(nonZero bool) rune {
+	if nonZero {
+		return rune('1' + r.intn(9))
+	}
+	return rune('0' + r.intn(10))
+}
+
+// scramble replaces letters and digits of s with random ones, keeping case of letters and other characters.
+// First digits of numbers are zero only if they were, so numbers keep their lengths.
+func (r *anonymizerRand) scramble(s string) string {
+	var b strings.Builder
+	prevDigit := false
+	for _, c := range s {
+		switch {
+		case c >= '0' && c <= '9':
+			if prevDigit || c != '0' {
+				c = r.digit(!prevDigit)
+			}
+			prevDigit = true
+			b.WriteRune(c)
+			continue
+		case unicode.IsUpper(c):
+			c = rune('A' + r.intn(26))
+		case unicode.IsLetter(c):
+			c = rune('a' + r.intn(26))
+		}
+		prevDigit = false
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// scrambleDigits replaces digits of number, or string with number, with random ones. First digits of
+// integer parts and last digits of fractions are zero only if they were, digits of exponents are kept.
+func (r *anonymizerRand) scrambleDigits(s string) string {
+	runes := []rune(s)
+	mantissa := len(runes)
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		mantissa = len([]rune(s[:i]))
+	}
+	fraction := false
+	for i := 0; i < mantissa; i++ {
+		c := runes[i]
+		if c == '.' {
+			fraction = true
+			continue
+		}
+		if c < '0' || c > '9' || c == '0' && (i == 0 || runes[i-1] < '0' || runes[i-1] > '9') {
+			continue
+		}
+		first := !fraction && (i == 0 || runes[i-1] < '0' || runes[i-1] > '9')
+		last := fraction && (i+1 == mantissa || runes[i+1] < '0' || runes[i+1] > '9')
+		if last && c == '0' {
+			continue
+		}
+		runes[i] = r.digit(first || last)
+	}
+	return string(runes)
+}
+
+// time returns random time between 2000 and 2030 in RFC 3339 format, with the same time zone and precision
+// as t, parsed from s.
+func (r *anonymizerRand) time(s string, t time.Time) string {
+	synthetic := time.Unix(int64(anonymizedTimeMin+r.intn(anonymizedTimeMax-anonymizedTimeMin)), 0).In(t.Location())
+	layout := "2006-01-02T15:04:05"
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		digits := 0
+		for _, c := range s[i+1:] {
+			if c < '0' || c > '9' {
+				break
+			}
+			digits++
+		}
+		if digits > 9 {
+			digits = 9
+		}
+		fraction := []rune(strings.Repeat("0", digits))
+		for i := range fraction {
+			fraction[i] = r.digit(i == len(fraction)-1)
+		}
+		nanos, _ := strconv.Atoi(string(fraction) + strings.Repeat("0", 9-digits))
+		synthetic = synthetic.Add(time.Duration(nanos))
+		layout += "." + strings.Repeat("0", digits)
+	}
+	return synthetic.Format(layout + "Z07:00")
+}
+
+// uuid returns random version 4 uuid.
+func (r *anonymizerRand) uuid() string {
+	var b [16]byte
+	for i := range b {
+		b[i] = byte(r.intn(256))
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
@@ -0,0 +1,313 @@
+package json2go
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AppendError is returned by AppendTo, when existing file declares generated types or functions with different
+// shapes. It matches ErrAppendConflict.
+type AppendError struct {
+	Conflicts []AppendConflict
+}
+
+// AppendConflict is a declaration, which exists with different shape.
+type AppendConflict struct {
+	// Name is a name of type or function, or receiver type and name of method, like "Document.UnmarshalJSON".
+	Name string
+	// Existing is an existing declaration.
+	Existing string
+	// Generated is a generated declaration, which wasn't appended.
+	Generated string
+}
+
+func (e *AppendError) Error() string {
+	var names []string
+	for _, c := range e.Conflicts {
+		names = append(names, c.Name)
+	}
+	return ErrAppendConflict.Error() + ": " + strings.Join(names, ", ")
+}
+
+func (e *AppendError) Is(target error) bool {
+	return target == ErrAppendConflict
+}
+
+// AppendTo returns source of existing go file src, with appended generated declarations, which it doesn't have yet,
+// and with imports they use. Declarations existing with the same name and shape, ignoring comments and formatting,
+// are skipped. Declarations existing with different shapes aren't appended, nor methods of such types, and are
+// reported with AppendError, but the rest of file is still returned. Header and output template aren't used.
+func (p *JSONParser) AppendTo(src []byte) (out []byte, err error) {
+	defer recoverError(&err)
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("parsing existing file: %w", err)
+	}
+	existing := make(map[string]goDecl)
+	for _, d := range goDecls(fset, file, src) {
+		existing[d.name] = d
+	}
+
+	nodes, err := p.declNodes()
+	if err != nil {
+		return nil, err
+	}
+	ctx := newASTContext(nodes, p.opts)
+	decls := astGenerateDeclsWithContext(nodes, ctx)
+	if ctx.err != nil {
+		return nil, ctx.err
+	}
+	code := []byte("package generated\n\n" + astPrintDecls(decls))
+	generatedSet := token.NewFileSet()
+	generatedFile, err := parser.ParseFile(generatedSet, "", code, 0)
+	if err != nil {
+		return nil, fmt.Errorf("%w: parsing generated code: %v", ErrInternal, err)
+	}
+
+	var appended []ast.Decl
+	var conflicts []AppendConflict
+	conflicting := make(map[string]bool)
+	for _, d := range goDecls(generatedSet, generatedFile, code) {
+		if conflicting[d.receiver] {
+			continue
+		}
+		e, ok := existing[d.name]
+		switch {
+		case !ok:
+			appended = append(appended, decls[d.index])
+		case e.shape != d.shape:
+			conflicting[d.name] = true
+			conflicts = append(conflicts, AppendConflict{Name: d.name, Existing: e.src, Generated: d.src})
+		}
+	}
+
+	out = src
+	if len(appended) > 0 {
+		out = append(bytes.TrimRight(src, "\n"), "\n\n"+astPrintDecls(appended)+"\n"...)
+		if out, err = fixImports(out, ctx.importSpecs()); err != nil {
+			return src, fmt.Errorf("%w: formatting appended code: %v", ErrInternal, err)
+		}
+	}
+	if len(conflicts) > 0 {
+		return out, &AppendError{Conflicts: conflicts}
+	}
+	return out, nil
+}
+
+// goDecl is a top level declaration of go file, other than import.
+type goDecl struct {
+	// name is a name of type or function, or receiver type and name of method, or names of constants or variables.
+	name string
+	// receiver is a receiver type name of method.
+	receiver string
+	// index is an index of ast declaration in file.
+	index int
+	// shape is a declaration source without comments and formatting.
+	shape string
+	// src is a declaration source, without doc comment.
+	src string
+}
+
+// goDecls returns top level declarations of parsed go file with source src, types of grouped declarations separately.
+func goDecls(fset *token.FileSet, file *ast.File, src []byte) []goDecl {
+	source := func(from, to token.Pos) string {
+		return string(src[fset.Position(from).Offset:fset.Position(to).Offset])
+	}
+
+	var decls []goDecl
+	add := func(d goDecl) {
+		d.shape = goShape(d.src)
+		decls = append(decls, d)
+	}
+	for i, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			name := d.Name.Name
+			receiver := astReceiverTypeName(d)
+			if receiver != "" {
+				name = receiver + "." + name
+			}
+			add(goDecl{name: name, receiver: receiver, index: i, src: source(d.Pos(), d.End())})
+		case *ast.GenDecl:
+			switch d.Tok {
+			case token.IMPORT:
+			case token.TYPE:
+				for _, spec := range d.Specs {
+					ts := spec.(*ast.TypeSpec)
+					add(goDecl{name: ts.Name.Name, index: i, src: "type " + source(ts.Pos(), ts.End())})
+				}
+			default:
+				var names []string
+				for _, spec := range d.Specs {
+					for _, name := range spec.(*ast.ValueSpec).Names {
+						names = append(names, name.Name)
+					}
+				}
+				add(goDecl{name: strings.Join(names, ", "), index: i, src: source(d.Pos(), d.End())})
+			}
+		}
+	}
+	return decls
+}
+
+// goShape returns tokens of go source, without comments, separated with spaces.
+func goShape(src string) string {
+	fset := token.NewFileSet()
+	var s scanner.Scanner
+	s.Init(fset.AddFile("", -1, len(src)), []byte(src), nil, 0)
+
+	var tokens []string
+	for {
+		_, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if lit == "" || tok == token.SEMICOLON {
+			lit = tok.String()
+		}
+		tokens = append(tokens, lit)
+	}
+	return strings.Join(tokens, " ")
+}
+
+// fixImports returns go file source, with added imports of packages of specs used in file, and without unused imports
+// of standard library packages and packages of specs, whose names are known.
+func fixImports(src []byte, specs []string) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	used := make(map[string]bool)
+	ast.Inspect(file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok {
+				used[ident.Name] = true
+			}
+		}
+		return true
+	})
+	known := make(map[string]bool, len(specs))
+	var missing []string
+	imports := make(map[string]bool, len(file.Imports))
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		imports[path] = true
+	}
+	for _, spec := range specs {
+		path, name := importSpecName(spec)
+		known[path] = true
+		if !imports[path] && used[name] {
+			missing = append(missing, spec)
+		}
+	}
+	sort.Strings(missing)
+
+	// Unused imports are cut from the end, so offsets of preceding ones don't change.
+	var cuts [][2]int
+	cut := func(from, to token.Pos) {
+		start, end := fset.Position(from).Offset, fset.Position(to).Offset
+		if start == end {
+			cuts = append(cuts, [2]int{start, end})
+			return
+		}
+		// Whole lines are cut, when nothing else is on them.
+		line := bytes.LastIndexByte(src[:start], '\n') + 1
+		if len(bytes.TrimSpace(src[line:start])) == 0 && end < len(src) && src[end] == '\n' {
+			start, end = line, end+1
+		}
+		cuts = append(cuts, [2]int{start, end})
+	}
+	var block *ast.GenDecl
+	for _, decl := range file.Decls {
+		d, ok := decl.(*ast.GenDecl)
+		if !ok || d.Tok != token.IMPORT {
+			continue
+		}
+		var unused []ast.Spec
+		for _, spec := range d.Specs {
+			is := spec.(*ast.ImportSpec)
+			path, _ := strconv.Unquote(is.Path.Value)
+			name := path[strings.LastIndex(path, "/")+1:]
+			if !known[path] && strings.Contains(strings.Split(path, "/")[0], ".") {
+				continue
+			}
+			if known[path] {
+				name, _ = packageName(path)
+			}
+			if is.Name != nil {
+				name = is.Name.Name
+			}
+			if name != "_" && name != "." && !used[name] {
+				unused = append(unused, spec)
+			}
+		}
+		switch {
+		case len(unused) > 0 && len(unused) == len(d.Specs):
+			cut(d.Pos(), d.End())
+		case len(unused) > 0:
+			for _, spec := range unused {
+				cut(spec.Pos(), spec.End())
+			}
+			fallthrough
+		default:
+			if d.Lparen.IsValid() && block == nil {
+				block = d
+			}
+		}
+	}
+	if len(missing) > 0 {
+		if block != nil {
+			// Missing imports are inserted before the first group, sorted with it.
+			at := fset.Position(block.Rparen).Offset
+			if len(block.Specs) > 0 {
+				at = bytes.LastIndexByte(src[:fset.Position(block.Specs[0].Pos()).Offset], '\n') + 1
+			}
+			cuts = append(cuts, [2]int{at, at})
+		} else {
+			cut(file.Name.End(), file.Name.End())
+		}
+	}
+
+	var out []byte
+	last := 0
+	sort.Slice(cuts, func(i, j int) bool {
+		return cuts[i][0] < cuts[j][0] || cuts[i][0] == cuts[j][0] && cuts[i][1] < cuts[j][1]
+	})
+	for _, c := range cuts {
+		out = append(out, src[last:c[0]]...)
+		if c[0] == c[1] {
+			// Empty cut is where missing imports are inserted.
+			if block != nil {
+				out = append(out, "\t"+strings.Join(missing, "\n\t")+"\n"...)
+			} else {
+				out = append(out, "\n\nimport (\n\t"+strings.Join(missing, "\n\t")+"\n)"...)
+			}
+		}
+		last = c[1]
+	}
+	out = append(out, src[last:]...)
+	return format.Source(out)
+}
+
+// importSpecName returns import path and name of package of import spec, like `alias "path"`.
+func importSpecName(spec string) (path, name string) {
+	i := strings.LastIndex(spec, " ")
+	path, _ = strconv.Unquote(spec[i+1:])
+	if i > 0 {
+		return path, spec[:i]
+	}
+	name, _ = packageName(path)
+	return path, name
+}
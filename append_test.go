@@ -0,0 +1,144 @@
+package json2go
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONParser_AppendTo(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name      string
+		src       string
+		expected  string
+		conflicts []string
+		err       error
+	}{
+		{
+			name: "new types and imports",
+			src: `package api
+
+import "fmt"
+
+// User is a user.
+type User struct {
+	ID int ` + "`json:\"id\"`" + `
+}
+
+func (u User) String() string { return fmt.Sprint(u.ID) }
+`,
+			expected: `package api
+
+import (
+	"time"
+)
+
+import "fmt"
+
+// User is a user.
+type User struct {
+	ID int ` + "`json:\"id\"`" + `
+}
+
+func (u User) String() string { return fmt.Sprint(u.ID) }
+
+type Order struct {
+	At       time.Time ` + "`json:\"at\"`" + `
+	Items    []Item    ` + "`json:\"items\"`" + `
+	Returned []Item    ` + "`json:\"returned\"`" + `
+}
+type Item struct {
+	Sku string ` + "`json:\"sku\"`" + `
+}
+`,
+		},
+		{
+			name: "existing types",
+			src: `package api
+
+import (
+	"fmt"
+	"time"
+)
+
+type (
+	// Item is an item.
+	Item struct {
+		Sku string ` + "`json:\"sku\"`" + ` // stock keeping unit
+	}
+	Order struct {
+		At time.Time ` + "`json:\"at\"`" + `; Items []Item ` + "`json:\"items\"`" + `
+		Returned []Item ` + "`json:\"returned\"`" + `
+	}
+)
+
+var _ = fmt.Sprint
+`,
+		},
+		{
+			name: "conflicts",
+			src: `package api
+
+type Order struct {
+	At string ` + "`json:\"at\"`" + `
+}
+`,
+			expected: `package api
+
+type Order struct {
+	At string ` + "`json:\"at\"`" + `
+}
+
+type Item struct {
+	Sku string ` + "`json:\"sku\"`" + `
+}
+`,
+			conflicts: []string{"Order"},
+			err:       ErrAppendConflict,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := NewJSONParser("Order", OptExtractCommonTypes(true), OptNameMapping(NameMapping{Types: map[string]string{"$.items": "Item"}}))
+			require.NoError(t, p.FeedBytes([]byte(`{"at": "2021-01-01T00:00:00Z", "items": [{"sku": "a"}], "returned": [{"sku": "b"}]}`)))
+
+			out, err := p.AppendTo([]byte(tc.src))
+			if tc.err != nil {
+				require.Error(t, err)
+				assert.True(t, errors.Is(err, tc.err), "got error %v", err)
+				var appendErr *AppendError
+				require.True(t, errors.As(err, &appendErr))
+				var names []string
+				for _, c := range appendErr.Conflicts {
+					names = append(names, c.Name)
+				}
+				assert.Equal(t, tc.conflicts, names)
+			} else {
+				require.NoError(t, err)
+			}
+			if tc.expected == "" {
+				tc.expected = tc.src
+			}
+			assert.Equal(t, tc.expected, string(out))
+		})
+	}
+}
+
+func TestJSONParser_AppendTo_invalidFile(t *testing.T) {
+	t.Parallel()
+
+	p := NewJSONParser(baseTypeName)
+	require.NoError(t, p.FeedBytes([]byte(`{"a": 1}`)))
+
+	_, err := p.AppendTo([]byte("type A struct {"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "parsing existing file")
+}
@@ -4,10 +4,16 @@ import (
 	"bytes"
 	"fmt"
 	"go/ast"
+	"go/format"
+	"go/parser"
 	"go/printer"
 	"go/token"
+	"reflect"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
+	"unicode"
 )
 
 // Printer settings - copy from gofmt.
@@ -23,98 +29,722 @@ const (
 	printerNormalizeNumbers = 1 << 30
 )
 
+// astContext holds ast generation state.
+type astContext struct {
+	opts options
+
+	// helperDecls are additional declarations, like helper types and methods, required by generated types.
+	helperDecls []ast.Decl
+	// names are type names used in generated declarations.
+	names map[string]bool
+	// imports are packages used in generated declarations.
+	imports map[string]bool
+	// sharedHelpers are names of helper types shared by all generated types, by helper id.
+	sharedHelpers map[string]string
+	// importNames are names of packages of custom types in generated code, by import path.
+	importNames map[string]string
+	// importAliases are aliases of imported packages, by import path.
+	importAliases map[string]string
+	// commentedFields are comments with fields commented out, by struct type.
+	commentedFields map[*ast.StructType]*ast.CommentGroup
+	// rawKeys are nodes of fields with keys, which can't be represented in json tags, see astAddKeyMethods.
+	rawKeys map[*ast.Field]*node
+	// err is the first error found during generation. Generation continues, using interface{} for invalid nodes.
+	err error
+}
+
+func newASTContext(rootNodes []*node, opts options) *astContext {
+	ctx := &astContext{
+		opts:            opts,
+		names:           make(map[string]bool),
+		imports:         make(map[string]bool),
+		sharedHelpers:   make(map[string]string),
+		importNames:     make(map[string]string),
+		importAliases:   make(map[string]string),
+		commentedFields: make(map[*ast.StructType]*ast.CommentGroup),
+		rawKeys:         make(map[*ast.Field]*node),
+	}
+	for _, n := range rootNodes {
+		ctx.names[n.name] = true
+	}
+
+	return ctx
+}
+
+// uniqueName returns unused type name based on given name and marks it as used.
+func (ctx *astContext) uniqueName(name string) string {
+	for ctx.names[name] {
+		name = nextName(name)
+	}
+	ctx.names[name] = true
+
+	return name
+}
+
+// addImport marks package as used in generated declarations.
+func (ctx *astContext) addImport(path string) {
+	if path != "" {
+		ctx.imports[path] = true
+	}
+}
+
+// importsList returns sorted list of used packages.
+func (ctx *astContext) importsList() []string {
+	var imports []string
+	for path := range ctx.imports {
+		imports = append(imports, path)
+	}
+	sort.Strings(imports)
+
+	return imports
+}
+
+// addHelper parses go source code and adds declarations to helper declarations. Invalid source fails generation.
+func (ctx *astContext) addHelper(src string) {
+	decls, err := astParseDecls(src)
+	if err != nil {
+		ctx.fail(err)
+		return
+	}
+	ctx.helperDecls = append(ctx.helperDecls, decls...)
+}
+
+// addSharedHelper adds helper declarations generated only once, and returns helper type name.
+// Source code is generated by srcFunc from unique type name.
+func (ctx *astContext) addSharedHelper(name string, srcFunc func(name string) string) string {
+	return ctx.addNamedSharedHelper(name, name, srcFunc)
+}
+
+// addNamedSharedHelper is like addSharedHelper, but helpers with different ids may have the same base name.
+func (ctx *astContext) addNamedSharedHelper(id, name string, srcFunc func(name string) string) string {
+	if uniqueName, ok := ctx.sharedHelpers[id]; ok {
+		return uniqueName
+	}
+
+	uniqueName := ctx.uniqueName(name)
+	ctx.sharedHelpers[id] = uniqueName
+	ctx.addHelper(srcFunc(uniqueName))
+
+	return uniqueName
+}
+
+// fail records generation error. Only first error is kept.
+func (ctx *astContext) fail(err error) {
+	if ctx.err == nil {
+		ctx.err = err
+	}
+}
+
 func astMakeDecls(rootNodes []*node, opts options) []ast.Decl {
-	var decls []ast.Decl
+	decls, _ := astGenerateDecls(rootNodes, opts)
+	return decls
+}
+
+// astGenerateDecls returns declarations of types for root nodes, and first error found during generation.
+func astGenerateDecls(rootNodes []*node, opts options) ([]ast.Decl, error) {
+	ctx := newASTContext(rootNodes, opts)
+	decls := astGenerateDeclsWithContext(rootNodes, ctx)
+
+	return decls, ctx.err
+}
+
+// astGenerateDeclsWithContext generates type declarations and helpers, collecting imports in context.
+func astGenerateDeclsWithContext(rootNodes []*node, ctx *astContext) []ast.Decl {
+	opts := ctx.opts
+	if opts.overridesErr != nil {
+		ctx.fail(opts.overridesErr)
+	}
+	if opts.timeFormatsErr != nil {
+		ctx.fail(opts.timeFormatsErr)
+	}
+	if opts.tagTemplateErr != nil {
+		ctx.fail(fmt.Errorf("invalid tag template: %w", opts.tagTemplateErr))
+	}
+	if opts.stringMethods {
+		if err := validateRedactPatterns(opts.redactPatterns); err != nil {
+			ctx.fail(err)
+		}
+	}
+	if opts.redactedType {
+		if err := validateRedactPatterns(opts.redactedTypePatterns); err != nil {
+			ctx.fail(err)
+		}
+	}
 
+	var decls []ast.Decl
+	identifiers := make(map[string]string)
+	documents := make(map[*node]*ast.StructType)
 	for _, node := range rootNodes {
+		isRoot := node.document
+		wrapper := opts.collapseWrappers && astIsWrapperNode(node)
+		typeExpr := astTypeFromNode(node, ctx)
+		nestedKeys := isRoot && opts.keySplitting == KeySplittingNested
+		spec := astRootTypeSpec(node, typeExpr, opts.stringMethods || opts.easyJSON || nestedKeys, ctx)
+		if wrapper {
+			// Methods can't be declared on type alias.
+			spec.Assign = token.NoPos
+		}
 		decls = append(decls, &ast.GenDecl{
-			Tok: token.TYPE,
-			Specs: []ast.Spec{
-				&ast.TypeSpec{
-					Name: ast.NewIdent(node.name),
-					Type: astTypeFromNode(node, opts),
-				},
-			},
+			Doc:   astTypeDocComment(node, isRoot, typeExpr, ctx),
+			Tok:   token.TYPE,
+			Specs: []ast.Spec{spec},
 		})
+
+		jsonAPI := node.jsonAPI == jsonAPIResource
+		// Types with own json methods or embedded fields don't get other methods.
+		plain := !jsonAPI && !node.cloudEvent && !wrapper
+		if st, ok := typeExpr.(*ast.StructType); ok && jsonAPI {
+			identifiers[node.name] = astAddJSONAPIResource(node, st, ctx)
+		}
+		if st, ok := typeExpr.(*ast.StructType); ok && node.jsonAPI == jsonAPIDocument {
+			documents[node] = st
+		}
+
+		ordered := opts.fieldOrder == FieldOrderOriginal && len(node.keyOrder) > 0 && plain
+		if st, ok := typeExpr.(*ast.StructType); ok && ordered {
+			astAddOrderedMarshaler(node, st, ctx)
+		}
+		presence := opts.presenceTracking && !nestedKeys && plain
+		if st, ok := typeExpr.(*ast.StructType); ok && presence && len(st.Fields.List) > 0 {
+			astAddPresenceTracking(node, st, ctx)
+		}
+		unknownFields := (opts.unknownFields || node.logRecord) && !presence && !nestedKeys && !ordered && plain
+		rawKeys := node.hasRawKeys()
+		if st, ok := typeExpr.(*ast.StructType); ok && opts.fastDecoders && !presence && !nestedKeys && !unknownFields && !rawKeys && plain {
+			astAddDecoder(node, st, ctx)
+		}
+		if st, ok := typeExpr.(*ast.StructType); ok && (unknownFields || rawKeys) {
+			astAddKeyMethods(node, st, unknownFields, ctx)
+		}
+		if nestedKeys {
+			astAddNestKeysUnmarshaler(node, typeExpr, ctx)
+		}
+		if opts.stringMethods {
+			astAddStringMethod(node, typeExpr, ctx)
+		}
 	}
 
-	return decls
+	for _, node := range rootNodes {
+		if st, ok := documents[node]; ok {
+			astAddJSONAPIResolve(node, st, identifiers, ctx)
+		}
+	}
+
+	astInsertCommentedFields(ctx)
+	if opts.goModuleErr != nil {
+		ctx.fail(fmt.Errorf("reading go.mod: %w", opts.goModuleErr))
+	}
+	if opts.goModule != nil {
+		for _, importPath := range ctx.importsList() {
+			if !opts.goModule.provides(importPath) {
+				ctx.fail(fmt.Errorf("%w: package %s isn't provided by module %s or its requirements",
+					ErrMissingDependency, importPath, opts.goModule.path))
+			}
+		}
+	}
+
+	return append(decls, ctx.helperDecls...)
 }
 
 func astPrintDecls(decls []ast.Decl) string {
+	// Use go/printer with settings compatible with gofmt.
+	prn := printer.Config{Mode: printerMode, Tabwidth: tabWidth}
+
+	// Print declarations one by one. Type declarations are printed one after another,
+	// functions and documented types are separated with empty lines.
+	var buf bytes.Buffer
+	for i, decl := range decls {
+		if i > 0 {
+			buf.WriteString("\n")
+			_, isFunc := decl.(*ast.FuncDecl)
+			_, prevIsFunc := decls[i-1].(*ast.FuncDecl)
+			if isFunc || prevIsFunc || astDeclHasDoc(decl) {
+				buf.WriteString("\n")
+			}
+		}
+		buf.WriteString(astPrintDecl(prn, decl))
+	}
+
+	return buf.String()
+}
+
+func astDeclHasDoc(decl ast.Decl) bool {
+	gd, ok := decl.(*ast.GenDecl)
+	return ok && gd.Doc != nil
+}
+
+func astPrintDecl(prn printer.Config, decl ast.Decl) string {
+	fieldDocs, restore := astMarkFieldDocs(decl)
+	defer restore()
+
+	// Declaration is printed as a part of file, so its doc comments are placed correctly.
 	file := &ast.File{
 		Name:  ast.NewIdent("main"),
-		Decls: decls,
+		Decls: []ast.Decl{decl},
 	}
 
-	// Use go/printer with settings compatible with gofmt.
 	var buf bytes.Buffer
-	prn := printer.Config{Mode: printerMode, Tabwidth: tabWidth}
 	prn.Fprint(&buf, token.NewFileSet(), file)
+	repr := buf.String()
+	if len(fieldDocs) > 0 {
+		repr = astInsertFieldDocs(repr, fieldDocs)
+	}
 
 	// Remove go file header
-	repr := buf.String()
 	repr = strings.TrimPrefix(repr, "package main")
 	repr = strings.TrimSpace(repr)
 
 	return repr
 }
 
-func astTypeFromNode(n *node, opts options) ast.Expr {
+// fieldDocMarker prefixes names of struct fields with doc comments while printing declarations.
+const fieldDocMarker = "json2goFieldDoc"
+
+var fieldDocMarkerRe = regexp.MustCompile(fieldDocMarker + `(\d+)_`)
+
+// astMarkFieldDocs replaces doc comments of struct fields with markers in field names.
+// Printer can't place comments without positions, so they are inserted to printed code by astInsertFieldDocs.
+// Returned function restores original fields.
+func astMarkFieldDocs(root ast.Node) ([]*ast.CommentGroup, func()) {
+	var docs []*ast.CommentGroup
+	var restores []func()
+	ast.Inspect(root, func(n ast.Node) bool {
+		f, ok := n.(*ast.Field)
+		if !ok || f.Doc == nil || len(f.Names) == 0 {
+			return true
+		}
+
+		doc, name := f.Doc, f.Names[0]
+		f.Doc = nil
+		f.Names[0] = ast.NewIdent(fmt.Sprintf("%s%d_%s", fieldDocMarker, len(docs), name.Name))
+		docs = append(docs, doc)
+		restores = append(restores, func() {
+			f.Doc = doc
+			f.Names[0] = name
+		})
+		return true
+	})
+
+	return docs, func() {
+		for _, r := range restores {
+			r()
+		}
+	}
+}
+
+// astInsertFieldDocs replaces field markers in printed source code with doc comments, and formats the code again.
+func astInsertFieldDocs(src string, docs []*ast.CommentGroup) string {
+	var buf bytes.Buffer
+	for _, line := range strings.SplitAfter(src, "\n") {
+		if loc := fieldDocMarkerRe.FindStringSubmatchIndex(line); loc != nil {
+			i, _ := strconv.Atoi(line[loc[2]:loc[3]])
+			indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+			for _, c := range docs[i].List {
+				buf.WriteString(indent + c.Text + "\n")
+			}
+			line = line[:loc[0]] + line[loc[1]:]
+			if strings.HasPrefix(strings.TrimSpace(line), commentedFieldName) {
+				// Placeholder of commented out fields.
+				continue
+			}
+		}
+		buf.WriteString(line)
+	}
+
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		return buf.String()
+	}
+	return string(formatted)
+}
+
+// easyJSONMarker marks types for easyjson code generation.
+const easyJSONMarker = "//easyjson:json"
+
+// astTypeDocComment returns doc comment of generated type. Only root type gets description, extracted types
+// are shared by many values, so descriptions of their values are used only for struct fields.
+func astTypeDocComment(n *node, isRoot bool, typeExpr ast.Expr, ctx *astContext) *ast.CommentGroup {
+	var doc *ast.CommentGroup
+	if isRoot {
+		doc = astDescriptionComment(n, ctx)
+	}
+
+	switch typeExpr.(type) {
+	case *ast.StructType, *ast.ArrayType, *ast.MapType:
+		if !ctx.opts.easyJSON {
+			break
+		}
+		if doc == nil {
+			doc = &ast.CommentGroup{}
+		}
+		doc.List = append(doc.List, &ast.Comment{Text: easyJSONMarker})
+	}
+
+	return doc
+}
+
+// astDescriptionComment returns doc comment with description of node's value, or nil if there is no description.
+func astDescriptionComment(n *node, ctx *astContext) *ast.CommentGroup {
+	desc := strings.TrimSpace(ctx.opts.descriptions[n.path])
+	if desc == "" {
+		return nil
+	}
+
+	var comments []*ast.Comment
+	for _, line := range strings.Split(desc, "\n") {
+		text := "//"
+		if line = strings.TrimRightFunc(line, unicode.IsSpace); line != "" {
+			text += " " + line
+		}
+		comments = append(comments, &ast.Comment{Text: text})
+	}
+
+	return &ast.CommentGroup{List: comments}
+}
+
+// astParseDecls parses go source code with declarations.
+// Positions are removed from parsed nodes, so declarations can be printed along with generated ones.
+func astParseDecls(src string) ([]ast.Decl, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), "", "package main\n"+src, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid helper source: %v", ErrInternal, err)
+	}
+
+	posType := reflect.TypeOf(token.NoPos)
+	for _, decl := range file.Decls {
+		ast.Inspect(decl, func(n ast.Node) bool {
+			if n == nil {
+				return false
+			}
+			if it, ok := n.(*ast.InterfaceType); ok && len(it.Methods.List) == 0 {
+				it.Methods = newEmptyInterfaceExpr().(*ast.InterfaceType).Methods
+				return false
+			}
+
+			call, isCall := n.(*ast.CallExpr)
+			variadic := isCall && call.Ellipsis.IsValid()
+
+			v := reflect.ValueOf(n).Elem()
+			for i := 0; i < v.NumField(); i++ {
+				if f := v.Field(i); f.Type() == posType {
+					f.SetInt(int64(token.NoPos))
+				}
+			}
+			if variadic {
+				// Variadic call is printed only if ellipsis has valid position.
+				call.Ellipsis = token.Pos(1)
+			}
+			return true
+		})
+	}
+
+	return file.Decls, nil
+}
+
+// astExprString returns go source representation of expression. Doc comments of struct fields are omitted.
+func astExprString(expr ast.Expr) string {
+	fieldDocs, restore := astMarkFieldDocs(expr)
+	defer restore()
+
+	var buf bytes.Buffer
+	prn := printer.Config{Mode: printerMode, Tabwidth: tabWidth}
+	prn.Fprint(&buf, token.NewFileSet(), expr)
+	if len(fieldDocs) == 0 {
+		return buf.String()
+	}
+
+	var lines []string
+	for _, line := range strings.Split(fieldDocMarkerRe.ReplaceAllString(buf.String(), ""), "\n") {
+		// Commented out fields are omitted.
+		if !strings.HasPrefix(strings.TrimSpace(line), commentedFieldName) {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func astTypeFromNode(n *node, ctx *astContext) ast.Expr {
 	var resultType ast.Expr
 	notRequiredAsPointer := true
 	allowPointer := true
+	arrayLevel := n.arrayLevel
 
 	switch n.t.(type) {
+	case nodeFloatType, nodeIntType, nodeStringType:
+		if ctx.opts.redactedType && astIsSensitiveNode(n, ctx) {
+			resultType = astTypeFromSensitiveNode(ctx)
+			notRequiredAsPointer = ctx.opts.stringPointersWhenKeyMissing
+			break
+		}
+		if ctx.opts.expandJSONStrings && astIsJSONStringNode(n) {
+			resultType = astTypeFromJSONStringNode(n, ctx)
+			break
+		}
+		if ctx.opts.expandQueryStrings && astIsQueryStringNode(n) {
+			resultType = astTypeFromQueryStringNode(n, ctx)
+			break
+		}
+		if ctx.opts.expandBase64JSON && astIsJWTNode(n) {
+			resultType = astTypeFromJWTNode(n, ctx)
+			break
+		}
+		if ctx.opts.expandBase64JSON && astIsBase64JSONNode(n) {
+			resultType = astTypeFromBase64JSONNode(n, ctx)
+			break
+		}
+		if t := astTypeFromTimeFormat(n, ctx); t != nil {
+			resultType = t
+			break
+		}
+		if ctx.opts.protoJSON && astIsProtoDurationNode(n) {
+			resultType = astTypeFromProtoDurationNode(ctx)
+			break
+		}
+		if ctx.opts.protoJSON && astIsProtoInt64Node(n) {
+			resultType = astTypeFromProtoInt64Node(ctx)
+			break
+		}
+		if unit, ok := astEpochUnit(n, ctx.opts.epochUnits); ok && n.timeFormat != timeFormatString {
+			resultType = astTypeFromEpochNode(unit, ctx)
+			break
+		}
+		if ctx.opts.decimals && astIsDecimalNode(n) {
+			resultType = ast.NewIdent(ctx.opts.decimalType)
+			ctx.addImport(ctx.opts.decimalImport)
+			break
+		}
+		if ctx.opts.boolStrings && astIsBoolStringNode(n) {
+			resultType = astTypeFromBoolStringNode(ctx)
+			break
+		}
+		if astIsLocalizedNumberNode(n, ctx.opts.numberLocale) {
+			resultType = astTypeFromLocalizedNumberNode(ctx)
+			break
+		}
+		if t := astTypeFromNonFiniteNode(n, ctx); t != nil {
+			resultType = t
+			break
+		}
+		if t := astTypeFromWideningLattice(n, ctx); t != nil {
+			resultType = t
+			_, isInterface := t.(*ast.InterfaceType)
+			allowPointer = !isInterface
+			break
+		}
+		resultType = astTypeFromSimpleNode(n, ctx)
+		if n.t == nodeTypeString {
+			notRequiredAsPointer = ctx.opts.stringPointersWhenKeyMissing
+		}
 	case nodeBoolType:
 		resultType = ast.NewIdent("bool")
-	case nodeIntType:
-		resultType = ast.NewIdent("int64")
-	case nodeFloatType:
-		resultType = ast.NewIdent("float64")
-	case nodeStringType:
-		resultType = ast.NewIdent("string")
-		notRequiredAsPointer = opts.stringPointersWhenKeyMissing
 	case nodeTimeType:
-		resultType = astTypeFromTimeNode(n, opts)
-		if opts.timeAsStr {
-			notRequiredAsPointer = opts.stringPointersWhenKeyMissing
+		resultType = astTypeFromTimeNode(n, ctx)
+		if ctx.opts.timeAsStr && n.timeFormat != timeFormatRFC3339 {
+			notRequiredAsPointer = ctx.opts.stringPointersWhenKeyMissing
 		}
 	case nodeObjectType:
-		resultType = astStructTypeFromNode(n, opts)
+		if n.chainDepth > 0 {
+			resultType = astTypeFromChainNode(n, ctx)
+			break
+		}
+		if ctx.opts.collapseWrappers && astIsWrapperNode(n) {
+			resultType = astTypeFromWrapperNode(n, ctx)
+			break
+		}
+		resultType = astStructTypeFromNode(n, ctx)
 	case nodeExtractedType:
-		resultType = astTypeFromExtractedNode(n)
+		resultType = astTypeFromExtractedNode(n, ctx)
 	case nodeInterfaceType, nodeInitType:
-		resultType = newEmptyInterfaceExpr()
+		if n.geoCoordinates {
+			resultType = astTypeFromGeoCoordinatesNode(ctx)
+		} else if ctx.opts.tuples && n.isTuple() {
+			// Innermost array is represented by tuple type.
+			resultType = astTypeFromTupleNode(n, ctx)
+			arrayLevel--
+		} else if t := astTypeFromWideningLattice(n, ctx); t != nil {
+			resultType = t
+			if n.t.id() == nodeTypeInterface.id() {
+				notRequiredAsPointer = ctx.opts.stringPointersWhenKeyMissing
+			}
+			break
+		} else {
+			resultType = newEmptyInterfaceExpr()
+		}
 		allowPointer = false
 	case nodeMapType:
-		resultType = astTypeFromMapNode(n, opts)
+		resultType = astTypeFromMapNode(n, ctx)
+		allowPointer = false
+	case nodeRawMessageType:
+		if n.jsonAPI == jsonAPIRelationships {
+			resultType = astTypeFromJSONAPIRelationshipsNode(ctx)
+		} else {
+			resultType = astTypeFromRawMessageNode(n, ctx)
+		}
 		allowPointer = false
 	default:
-		panic(fmt.Sprintf("unknown type: %v", n.t))
+		ctx.fail(fmt.Errorf("%w: %s: unknown type: %v", ErrUnsupportedShape, n.path, n.t))
+		resultType = newEmptyInterfaceExpr()
+		allowPointer = false
 	}
 
-	if astTypeShouldBeAPointer(n, notRequiredAsPointer, allowPointer) {
+	if n.pointer != nil && !n.root && n.arrayLevel == 0 {
+		if *n.pointer && allowPointer && ctx.opts.optionalType {
+			resultType = astOptionalType(resultType, ctx)
+		} else if *n.pointer && allowPointer {
+			resultType = &ast.StarExpr{
+				X: resultType,
+			}
+		}
+	} else if ctx.opts.optionalType && astTypeShouldBeOptional(n, allowPointer) {
+		resultType = astOptionalType(resultType, ctx)
+	} else if astTypeShouldBeAPointer(n, notRequiredAsPointer, allowPointer, ctx) {
 		resultType = &ast.StarExpr{
 			X: resultType,
 		}
 	}
 
-	for i := n.arrayLevel; i > 0; i-- {
+	skipNulls := false
+	if allowPointer && n.arrayWithNulls && arrayLevel > 0 {
+		switch ctx.opts.nullElements {
+		case NullElementsSkip:
+			// Innermost array is represented by slice type skipping nulls.
+			resultType = astSkipNullsType(resultType, ctx)
+			arrayLevel--
+			skipNulls = true
+		case NullElementsWrapper:
+			resultType = astNullableType(resultType, ctx)
+		}
+	}
+
+	for i := arrayLevel; i > 0; i-- {
 		resultType = &ast.ArrayType{
 			Elt: resultType,
 		}
 	}
 
+	if skipNulls && n.root && arrayLevel == 0 {
+		// Type alias preserves "UnmarshalJSON" method of slice type.
+		resultType = ast.NewIdent("= " + astExprString(resultType))
+	}
+
 	return resultType
 }
 
-func astTypeFromTimeNode(n *node, opts options) ast.Expr {
+func astTypeFromSimpleNode(n *node, ctx *astContext) ast.Expr {
+	switch n.t {
+	case nodeTypeInt:
+		return ast.NewIdent("int")
+	case nodeTypeFloat:
+		return astTypeFromFloatNode(n, ctx)
+	}
+	return ast.NewIdent("string")
+}
+
+// astIsDecimalNode checks if node holds money-like values.
+func astIsDecimalNode(n *node) bool {
+	return n.formats&formatDecimal != 0 && isMoneyKey(n.key)
+}
+
+// astIsBoolStringNode checks if node holds bool strings. Digits alone are bools only if both "1" and "0" are seen.
+func astIsBoolStringNode(n *node) bool {
+	if n.t != nodeTypeString || n.formats&formatBoolString == 0 {
+		return false
+	}
+	return n.boolStrings&boolStringWord != 0 || n.boolStrings&(boolStringTrue|boolStringFalse) == boolStringTrue|boolStringFalse
+}
+
+func astTypeFromBoolStringNode(ctx *astContext) ast.Expr {
+	ctx.addImport("encoding/json")
+	ctx.addImport("fmt")
+	ctx.addImport("strconv")
+	ctx.addImport("strings")
+
+	return ast.NewIdent(ctx.addSharedHelper("BoolString", func(name string) string {
+		return fmt.Sprintf(`
+// %[1]s is a bool represented in json as a string, like "true", "yes", "Y" or "1".
+type %[1]s bool
+
+// UnmarshalJSON unmarshals bool from json string or bool.
+func (b *%[1]s) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		var v bool
+		if err := json.Unmarshal(data, &v); err != nil {
+			return err
+		}
+		*b = %[1]s(v)
+		return nil
+	}
+
+	switch strings.ToLower(s) {
+	case "true", "yes", "y", "1":
+		*b = true
+	case "false", "no", "n", "0":
+		*b = false
+	default:
+		return fmt.Errorf("invalid bool value: %%q", s)
+	}
+	return nil
+}
+
+// MarshalJSON marshals bool as json string "true" or "false".
+func (b %[1]s) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strconv.FormatBool(bool(b)))
+}
+`, name)
+	}))
+}
+
+// astIsSensitiveNode checks if node holds strings with credentials, judging by key or values.
+func astIsSensitiveNode(n *node, ctx *astContext) bool {
+	if n.t != nodeTypeString {
+		return false
+	}
+	return n.formats&formatSecret != 0 || isSensitiveKey(n.key, ctx.opts.redactedTypePatterns)
+}
+
+func astTypeFromSensitiveNode(ctx *astContext) ast.Expr {
+	ctx.addImport("encoding/json")
+
+	return ast.NewIdent(ctx.addSharedHelper("Redacted", func(name string) string {
+		return fmt.Sprintf(`
+// %[1]s is a sensitive string. Its value is hidden when formatted or marshaled to json, use string(v) to read it.
+type %[1]s string
+
+// String returns placeholder instead of value.
+func (r %[1]s) String() string {
+	return %[2]q
+}
+
+// GoString returns placeholder instead of value.
+func (r %[1]s) GoString() string {
+	return %[2]q
+}
+
+// MarshalJSON marshals placeholder instead of value.
+func (r %[1]s) MarshalJSON() ([]byte, error) {
+	return json.Marshal(%[2]q)
+}
+`, name, redactedValue)
+	}))
+}
+
+func astTypeFromFloatNode(n *node, ctx *astContext) ast.Expr {
+	if ctx.opts.float32 && !(ctx.opts.float32OnlyLossless && n.needsFloat64) {
+		return ast.NewIdent("float32")
+	}
+	return ast.NewIdent("float64")
+}
+
+func astTypeFromTimeNode(n *node, ctx *astContext) ast.Expr {
 	var resultType ast.Expr
 
-	if opts.timeAsStr {
-		resultType = ast.NewIdent("string")
-	} else if n.root {
+	if ctx.opts.timeAsStr && n.timeFormat != timeFormatRFC3339 {
+		return ast.NewIdent("string")
+	}
+
+	ctx.addImport("time")
+	if n.root {
 		// We have to use type alias here to preserve "UnmarshalJSON" method from time type.
 		resultType = ast.NewIdent("= time.Time")
 	} else {
@@ -124,28 +754,98 @@ func astTypeFromTimeNode(n *node, opts options) ast.Expr {
 	return resultType
 }
 
-func astTypeFromMapNode(n *node, opts options) ast.Expr {
+func astTypeFromRawMessageNode(n *node, ctx *astContext) ast.Expr {
+	ctx.addImport("encoding/json")
+	if n.root && n.arrayLevel == 0 {
+		// Type alias preserves "UnmarshalJSON" and "MarshalJSON" methods of json.RawMessage.
+		return ast.NewIdent("= json.RawMessage")
+	}
+	return ast.NewIdent("json.RawMessage")
+}
+
+// astTypeFromTupleNode creates tuple type for node's innermost array and returns its name.
+// Tuple type is a struct with field for each array position and json marshaling methods converting it from/to array.
+func astTypeFromTupleNode(n *node, ctx *astContext) ast.Expr {
+	name := ctx.uniqueName(singularName(n.name, ctx.opts.singulars) + "Tuple")
+	ctx.addImport("encoding/json")
+
+	var fields, ptrs, values []string
+	for i, pn := range n.tuple {
+		fname := fmt.Sprintf("V%d", i)
+		fields = append(fields, fmt.Sprintf("%s %s", fname, astExprString(astTypeFromNode(pn, ctx))))
+		ptrs = append(ptrs, "&t."+fname)
+		values = append(values, "t."+fname)
+	}
+
+	ctx.addHelper(fmt.Sprintf(`
+type %[1]s struct {
+	%[2]s
+}
+
+// UnmarshalJSON unmarshals json array to tuple fields.
+func (t *%[1]s) UnmarshalJSON(data []byte) error {
+	v := []interface{}{%[3]s}
+	return json.Unmarshal(data, &v)
+}
+
+// MarshalJSON marshals tuple fields to json array.
+func (t %[1]s) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]interface{}{%[4]s})
+}
+`,
+		name,
+		strings.Join(fields, "\n"),
+		strings.Join(ptrs, ", "),
+		strings.Join(values, ", "),
+	))
+
+	if n.root && n.arrayLevel == 1 {
+		// Type alias preserves json marshaling methods of tuple type.
+		return ast.NewIdent("= " + name)
+	}
+	return ast.NewIdent(name)
+}
+
+func astTypeFromMapNode(n *node, ctx *astContext) ast.Expr {
 	var ve ast.Expr
 	if len(n.children) == 0 {
 		ve = newEmptyInterfaceExpr()
 	} else {
-		ve = astTypeFromNode(n.children[0], opts)
+		ve = astTypeFromNode(n.children[0], ctx)
 	}
 	return &ast.MapType{
-		Key:   ast.NewIdent("string"),
+		Key:   astMapKeyType(n, ctx),
 		Value: ve,
 	}
 }
 
-func astTypeFromExtractedNode(n *node) ast.Expr {
+func astMapKeyType(n *node, ctx *astContext) ast.Expr {
+	if ctx.opts.mapKeyTypes {
+		switch n.mapKeyType {
+		case mapKeyInt:
+			return ast.NewIdent("int64")
+		case mapKeyUUID:
+			ctx.addImport("github.com/google/uuid")
+			return ast.NewIdent("uuid.UUID")
+		}
+	}
+	return ast.NewIdent("string")
+}
+
+func astTypeFromExtractedNode(n *node, ctx *astContext) ast.Expr {
 	extName := n.externalTypeID
 	if extName == "" {
 		extName = n.name
 	}
+	if isGoType(extName) {
+		// Custom type set by overrides.
+		importPath, typeName := splitGoType(extName)
+		return ast.NewIdent(ctx.importName(importPath) + "." + typeName)
+	}
 	return ast.NewIdent(extName)
 }
 
-func astStructTypeFromNode(n *node, opts options) *ast.StructType {
+func astStructTypeFromNode(n *node, ctx *astContext) *ast.StructType {
 	typeDesc := &ast.StructType{
 		Fields: &ast.FieldList{
 			List: []*ast.Field{},
@@ -159,6 +859,10 @@ func astStructTypeFromNode(n *node, opts options) *ast.StructType {
 	}
 	var sortedChildren []nodeWithName
 	for _, child := range n.children {
+		if child.cloudEventAttribute {
+			// Context attributes are fields of embedded type.
+			continue
+		}
 		sortedChildren = append(sortedChildren, nodeWithName{
 			name: child.name,
 			node: child,
@@ -169,31 +873,290 @@ func astStructTypeFromNode(n *node, opts options) *ast.StructType {
 	})
 
 	for _, child := range sortedChildren {
-		typeDesc.Fields.List = append(typeDesc.Fields.List, &ast.Field{
-			Names: []*ast.Ident{ast.NewIdent(child.name)},
-			Type:  astTypeFromNode(child.node, opts),
-			Tag:   astJSONTag(child.node.key, !child.node.required),
-		})
+		field := astFieldFromNode(child.node, ctx)
+		if ctx.opts.pii {
+			astAnnotatePII(field, child.node, n, ctx)
+		}
+		typeDesc.Fields.List = append(typeDesc.Fields.List, field)
 	}
+	if n.cloudEvent {
+		astAddCloudEventField(typeDesc, ctx)
+	}
+	astAddCommentedFields(n, typeDesc, ctx)
 
 	return typeDesc
 }
 
-func astJSONTag(key string, omitempty bool) *ast.BasicLit {
-	tag := fmt.Sprintf("%#v", key)
-	tag = strings.Trim(tag, `"`)
-	if omitempty {
-		tag = fmt.Sprintf("`json:\"%s,omitempty\"`", tag)
-	} else {
-		tag = fmt.Sprintf("`json:\"%s\"`", tag)
+// astFieldFromNode returns struct field for attribute node.
+func astFieldFromNode(n *node, ctx *astContext) *ast.Field {
+	fieldType := astTypeFromNode(n, ctx)
+	omit := ""
+	if astIsOptionalType(fieldType, ctx) || (ctx.opts.jsonV2 && !n.required) {
+		omit = "omitzero"
+	} else if !n.required {
+		omit = "omitempty"
+	}
+	asString := ctx.opts.decimals && n.t == nodeTypeString && astIsDecimalNode(n)
+	format := ""
+	if ctx.opts.jsonV2 && n.t == nodeTypeTime && n.arrayLevel == 0 && !ctx.opts.timeAsStr {
+		format = "RFC3339"
+	}
+
+	var tag *ast.BasicLit
+	switch {
+	case n.rawKey:
+		// Value is decoded by json methods of struct, see astAddKeyMethods.
+		tag = &ast.BasicLit{Value: "`json:\"-\"`"}
+	case ctx.opts.tagTemplate != nil:
+		tag = astTagFromTemplate(n, omit, asString, format, ctx)
+	case !isJSONTagName(n.key):
+		// Tag without name doesn't clash with other keys, e.g. key "a" with "a,b", see markUntaggableKeys.
+		tag = astJSONTag("", astJSONTagOptions(omit, asString, format, ctx)...)
+	default:
+		tag = astJSONTag(n.key, astJSONTagOptions(omit, asString, format, ctx)...)
+	}
+	if ctx.opts.verifyTags && !n.rawKey {
+		if err := verifyJSONTag(tag, n.key); err != nil {
+			ctx.fail(fmt.Errorf("%w: %s: %v", ErrUnsupportedShape, n.path, err))
+		}
+	}
+	field := &ast.Field{
+		Doc:   astDescriptionComment(n, ctx),
+		Names: []*ast.Ident{ast.NewIdent(n.name)},
+		Type:  fieldType,
+		Tag:   tag,
+	}
+	if n.rawKey {
+		ctx.rawKeys[field] = n
+	}
+	return field
+}
+
+// astAddOrderedMarshaler adds MarshalJSON method, emitting keys in original order, for named struct type.
+// Fields are copied to anonymous struct with fields in original order, which is then marshaled.
+func astAddOrderedMarshaler(n *node, st *ast.StructType, ctx *astContext) {
+	fieldsByName := make(map[string]*ast.Field)
+	for _, f := range st.Fields.List {
+		fieldsByName[f.Names[0].Name] = f
+	}
+	childrenByKey := make(map[string]*node)
+	for _, c := range n.children {
+		childrenByKey[c.key] = c
+	}
+
+	var orderedFields []*ast.Field
+	seen := make(map[string]bool)
+	for _, k := range n.keyOrder {
+		if c, ok := childrenByKey[k]; ok {
+			orderedFields = append(orderedFields, fieldsByName[c.name])
+			seen[c.name] = true
+		}
+	}
+	// Keys with unknown order are emitted last, in struct order.
+	for _, f := range st.Fields.List {
+		if !seen[f.Names[0].Name] {
+			orderedFields = append(orderedFields, f)
+		}
+	}
+
+	var values []string
+	for _, f := range orderedFields {
+		values = append(values, fmt.Sprintf("%[1]s: v.%[1]s,", f.Names[0].Name))
+	}
+
+	ctx.addImport("encoding/json")
+	ctx.addHelper(fmt.Sprintf(`
+// MarshalJSON marshals %[1]s with keys in original order.
+func (v %[1]s) MarshalJSON() ([]byte, error) {
+	return json.Marshal(%[2]s{
+		%[3]s
+	})
+}
+`,
+		n.name,
+		astExprString(&ast.StructType{Fields: &ast.FieldList{List: orderedFields}}),
+		strings.Join(values, "\n"),
+	))
+}
+
+// astAddPresenceTracking adds hidden bitset of present keys to named struct type, UnmarshalJSON method filling it,
+// and Has<Field>() methods reporting if keys were present.
+func astAddPresenceTracking(n *node, st *ast.StructType, ctx *astContext) {
+	const bitsetField = "present"
+
+	fieldNames := make(map[string]bool)
+	for _, f := range st.Fields.List {
+		fieldNames[f.Names[0].Name] = true
+	}
+
+	childrenByName := make(map[string]*node)
+	for _, c := range n.children {
+		childrenByName[c.name] = c
+	}
+
+	var keys, methods []string
+	for i, f := range st.Fields.List {
+		c := childrenByName[f.Names[0].Name]
+		keys = append(keys, strconv.Quote(c.key))
+
+		method := "Has" + c.name
+		for fieldNames[method] {
+			method = nextName(method)
+		}
+		methods = append(methods, fmt.Sprintf(`
+// %[1]s reports if %[2]q key was present.
+func (v %[3]s) %[1]s() bool {
+	return v.%[4]s[%[5]d]&(1<<%[6]d) != 0
+}
+`, method, c.key, n.name, bitsetField, i/64, i%64))
+	}
+
+	words := (len(keys) + 63) / 64
+	st.Fields.List = append(st.Fields.List, &ast.Field{
+		Names: []*ast.Ident{ast.NewIdent(bitsetField)},
+		Type:  ast.NewIdent(fmt.Sprintf("[%d]uint64", words)),
+	})
+
+	ctx.addImport("encoding/json")
+	ctx.addHelper(fmt.Sprintf(`
+// UnmarshalJSON unmarshals %[1]s, recording which keys were present.
+func (v *%[1]s) UnmarshalJSON(data []byte) error {
+	type plain %[1]s
+	if err := json.Unmarshal(data, (*plain)(v)); err != nil {
+		return err
+	}
+
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return err
+	}
+	v.%[2]s = [%[3]d]uint64{}
+	for i, k := range []string{%[4]s} {
+		if _, ok := keys[k]; ok {
+			v.%[2]s[i/64] |= 1 << (i %% 64)
+		}
+	}
+	return nil
+}
+`, n.name, bitsetField, words, strings.Join(keys, ", ")) + strings.Join(methods, ""))
+}
+
+// stringMethodName is a name of method added with OptStringMethods.
+const stringMethodName = "String"
+
+// astAddStringMethod adds String method, redacting sensitive values, to named struct, slice or map type.
+// Structs with String field don't get the method, it would clash with the field.
+func astAddStringMethod(n *node, typeExpr ast.Expr, ctx *astContext) {
+	switch typedExpr := typeExpr.(type) {
+	case *ast.StructType:
+		for _, f := range typedExpr.Fields.List {
+			for _, name := range f.Names {
+				if name.Name == stringMethodName {
+					return
+				}
+			}
+		}
+	case *ast.ArrayType, *ast.MapType:
+	default:
+		return
+	}
+
+	ctx.addImport("fmt")
+	ctx.addImport("path")
+	ctx.addImport("reflect")
+	ctx.addImport("sort")
+	ctx.addImport("strings")
+	helperName := ctx.addSharedHelper("redactedString", func(name string) string {
+		return redactedStringHelperSrc(name, ctx.opts.redactPatterns)
+	})
+	ctx.addHelper(fmt.Sprintf(`
+// String returns representation of %[1]s with sensitive values redacted.
+func (v %[1]s) String() string {
+	return %[2]s(v)
+}
+`, n.name, helperName))
+}
+
+// astAddNestKeysUnmarshaler adds UnmarshalJSON method to root type, nesting keys with separators
+// the same way as parsed inputs were nested.
+func astAddNestKeysUnmarshaler(n *node, typeExpr ast.Expr, ctx *astContext) {
+	switch typeExpr.(type) {
+	case *ast.StructType, *ast.ArrayType:
+	default:
+		return
+	}
+
+	ctx.addImport("encoding/json")
+	ctx.addImport("sort")
+	ctx.addImport("strings")
+	nestName := ctx.addSharedHelper("nestKeys", func(name string) string {
+		return nestKeysHelperSrc(name, ctx.opts.keySeparators)
+	})
+	ctx.addHelper(fmt.Sprintf(`
+// UnmarshalJSON unmarshals %[1]s, nesting keys with separators into objects.
+func (v *%[1]s) UnmarshalJSON(data []byte) error {
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	nested, err := json.Marshal(%[2]s(raw))
+	if err != nil {
+		return err
+	}
+
+	type plain %[1]s
+	return json.Unmarshal(nested, (*plain)(v))
+}
+`, n.name, nestName))
+}
+
+// astJSONTagOptions returns options of json tag. Omit is an option for omitting empty values, like "omitempty".
+// If asString is true, "string" option is added - value is expected to be encoded as json string.
+// Format is a value of json v2 "format" option.
+func astJSONTagOptions(omit string, asString bool, format string, ctx *astContext) []string {
+	var options []string
+	if omit != "" {
+		options = append(options, omit)
+	}
+	if asString {
+		options = append(options, "string")
+	}
+	if format != "" {
+		options = append(options, "format:"+format)
+	}
+	if ctx.opts.jsonV2 && ctx.opts.jsonV2CaseInsensitive {
+		options = append(options, "case:ignore")
+	}
+
+	return options
+}
+
+// astJSONTag returns json tag for a struct field, with given tag options. Key is escaped in tag, and tag is an
+// interpreted string literal, if key has backquotes, which raw string literal can't contain.
+func astJSONTag(key string, options ...string) *ast.BasicLit {
+	value := key
+	if key == "-" && len(options) == 0 {
+		// Tag "-" skips field, "-," is a key "-".
+		value += ","
+	}
+	for _, o := range options {
+		value += "," + o
+	}
+
+	tag := "json:" + strconv.Quote(value)
+	if strings.Contains(tag, "`") {
+		// Raw string literal can't contain backquotes.
+		return &ast.BasicLit{
+			Value: strconv.Quote(tag),
+		}
 	}
 
 	return &ast.BasicLit{
-		Value: tag,
+		Value: "`" + tag + "`",
 	}
 }
 
-func astTypeShouldBeAPointer(n *node, notRequiredAsPointer bool, allowPointer bool) bool {
+func astTypeShouldBeAPointer(n *node, notRequiredAsPointer bool, allowPointer bool, ctx *astContext) bool {
 	if !allowPointer {
 		return false
 	}
@@ -203,14 +1166,73 @@ func astTypeShouldBeAPointer(n *node, notRequiredAsPointer bool, allowPointer bo
 			return true
 		}
 	} else if n.arrayLevel > 0 {
-		if n.arrayWithNulls {
-			return true
-		}
+		return astElementShouldBeAPointer(n, ctx.opts.sliceElements, ctx.opts.nullElements)
 	}
 
 	return false
 }
 
+// astTypeShouldBeOptional checks if struct attribute may be absent or null, so it should be wrapped in optional type.
+func astTypeShouldBeOptional(n *node, allowPointer bool) bool {
+	return allowPointer && !n.root && n.arrayLevel == 0 && (n.nullable || !n.required)
+}
+
+// astOptionalType returns generic optional type with given value type.
+func astOptionalType(valueType ast.Expr, ctx *astContext) ast.Expr {
+	ctx.addImport("encoding/json")
+	name := ctx.addSharedHelper("Optional", func(name string) string {
+		return fmt.Sprintf(`
+// %[1]s is a value of attribute that may be absent or null.
+// Present is true if attribute was present, even if it was null.
+type %[1]s[T any] struct {
+	Present bool
+	Null    bool
+	Value   T
+}
+
+// UnmarshalJSON unmarshals value, marking it as present.
+func (o *%[1]s[T]) UnmarshalJSON(data []byte) error {
+	var zero T
+	o.Present = true
+	o.Null = string(data) == "null"
+	o.Value = zero
+	if o.Null {
+		return nil
+	}
+	return json.Unmarshal(data, &o.Value)
+}
+
+// MarshalJSON marshals value, or null if it's absent or null.
+func (o %[1]s[T]) MarshalJSON() ([]byte, error) {
+	if !o.Present || o.Null {
+		return []byte("null"), nil
+	}
+	return json.Marshal(o.Value)
+}
+
+// IsZero reports if value is absent. Absent values are omitted by "omitzero" option.
+func (o %[1]s[T]) IsZero() bool {
+	return !o.Present
+}
+`, name)
+	})
+
+	return &ast.IndexExpr{
+		X:     ast.NewIdent(name),
+		Index: valueType,
+	}
+}
+
+// astIsOptionalType checks if type is generic optional type.
+func astIsOptionalType(expr ast.Expr, ctx *astContext) bool {
+	ie, ok := expr.(*ast.IndexExpr)
+	if !ok {
+		return false
+	}
+	name, ok := ie.X.(*ast.Ident)
+	return ok && name.Name == ctx.sharedHelpers["Optional"]
+}
+
 func newEmptyInterfaceExpr() ast.Expr {
 	return &ast.InterfaceType{
 		Methods: &ast.FieldList{
@@ -0,0 +1,171 @@
+package json2go
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"regexp"
+	"strings"
+)
+
+// base64JSONRe matches base64 strings, standard or url safe, with or without padding, that may encode json objects,
+// starting with "ey", or arrays, starting with "W".
+var base64JSONRe = regexp.MustCompile(`^(ey|W[w-z0-9])[A-Za-z0-9+/_-]{2,}={0,2}$`)
+
+// decodeBase64 decodes base64 string in any alphabet, with or without padding.
+func decodeBase64(s string) ([]byte, error) {
+	s = strings.TrimRight(s, "=")
+	if strings.ContainsAny(s, "-_") {
+		return base64.RawURLEncoding.DecodeString(s)
+	}
+	return base64.RawStdEncoding.DecodeString(s)
+}
+
+// decodeBase64JSON decodes json object or array encoded in base64 string.
+func decodeBase64JSON(s string) (interface{}, bool) {
+	if !base64JSONRe.MatchString(s) {
+		return nil, false
+	}
+	data, err := decodeBase64(s)
+	if err != nil || !isJSONString(string(data)) {
+		return nil, false
+	}
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, false
+	}
+	return v, true
+}
+
+// decodeJWTClaims decodes claims from payload of JWT in compact serialization.
+func decodeJWTClaims(s string) (interface{}, bool) {
+	if !jwtRe.MatchString(s) {
+		return nil, false
+	}
+	data, err := decodeBase64(strings.Split(s, ".")[1])
+	if err != nil {
+		return nil, false
+	}
+	var claims map[string]interface{}
+	if err := json.Unmarshal(data, &claims); err != nil {
+		return nil, false
+	}
+	return claims, true
+}
+
+// astIsJWTNode checks if all node's values are JWTs with json claims.
+func astIsJWTNode(n *node) bool {
+	return n.t == nodeTypeString && n.formats&formatJWT != 0 && n.encoded != nil
+}
+
+// astIsBase64JSONNode checks if all node's values are json objects or arrays encoded in base64 strings.
+func astIsBase64JSONNode(n *node) bool {
+	return n.t == nodeTypeString && n.formats&formatBase64JSON != 0 && n.encoded != nil
+}
+
+// astTypeFromJWTNode returns helper type of JWTs, with claims decoded from token payload by UnmarshalJSON.
+func astTypeFromJWTNode(n *node, ctx *astContext) ast.Expr {
+	name := n.name
+	if n.arrayLevel > 0 {
+		// Each element of array is a token.
+		name = singularName(name, ctx.opts.singulars)
+	}
+	name = ctx.uniqueName(name + "JWT")
+	ctx.addImport("encoding/base64")
+	ctx.addImport("encoding/json")
+	ctx.addImport("errors")
+	ctx.addImport("strings")
+	claimsType := astExprString(astTypeFromNode(n.encoded, ctx))
+
+	ctx.addHelper(fmt.Sprintf(`
+// %[1]s is a JWT. Its claims are decoded at runtime, when token is unmarshaled from json string.
+// Signature isn't verified, token must be verified before claims are trusted.
+type %[1]s struct {
+	// Token is encoded token, marshaled unchanged. Changes of claims aren't encoded in it.
+	Token string
+	// Claims are decoded from token payload.
+	Claims %[2]s
+}
+
+// UnmarshalJSON unmarshals token from json string, decoding its claims.
+func (t *%[1]s) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(data, &t.Token); err != nil {
+		return err
+	}
+	parts := strings.Split(t.Token, ".")
+	if len(parts) != 3 {
+		return errors.New("invalid JWT: token must have 3 parts")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(payload, &t.Claims)
+}
+
+// MarshalJSON marshals token as json string.
+func (t %[1]s) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.Token)
+}
+`, name, claimsType))
+
+	return ast.NewIdent(name)
+}
+
+// astTypeFromBase64JSONNode returns helper type of values decoded from base64 strings with encoded json,
+// with json methods converting it from/to base64 string.
+func astTypeFromBase64JSONNode(n *node, ctx *astContext) ast.Expr {
+	name := n.name
+	if n.arrayLevel > 0 {
+		// Each element of array is a base64 string.
+		name = singularName(name, ctx.opts.singulars)
+	}
+	name = ctx.uniqueName(name + "Base64")
+	ctx.addImport("encoding/base64")
+	ctx.addImport("encoding/json")
+	ctx.addImport("strings")
+	valueType := astExprString(astTypeFromNode(n.encoded, ctx))
+
+	ctx.addHelper(fmt.Sprintf(`
+// %[1]s is a json value encoded in base64 string. It's decoded at runtime, when unmarshaled from json string.
+type %[1]s %[2]s
+
+// UnmarshalJSON unmarshals value from json string with base64 encoded json, in standard or url safe alphabet.
+func (v *%[1]s) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	s = strings.TrimRight(s, "=")
+	encoding := base64.RawStdEncoding
+	if strings.ContainsAny(s, "-_") {
+		encoding = base64.RawURLEncoding
+	}
+	decoded, err := encoding.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	type plain %[1]s
+	return json.Unmarshal(decoded, (*plain)(v))
+}
+
+// MarshalJSON marshals value as json string with base64 encoded json, in standard alphabet.
+func (v %[1]s) MarshalJSON() ([]byte, error) {
+	type plain %[1]s
+	data, err := json.Marshal(plain(v))
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(base64.StdEncoding.EncodeToString(data))
+}
+`, name, valueType))
+
+	return ast.NewIdent(name)
+}
@@ -0,0 +1,96 @@
+package json2go
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const base64JSONTestJWT = "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9.eyJzdWIiOiIxMjM0NTY3ODkwIiwiaWF0IjoxNTE2MjM5MDIyfQ.c2ln"
+
+func TestParserBase64JSON(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		disabled bool
+		inputs   []string
+		expected string
+	}{
+		{
+			name:     "disabled",
+			disabled: true,
+			inputs:   []string{`{"v":"` + base64JSONTestJWT + `"}`},
+			expected: "string",
+		},
+		{
+			name:     "jwt",
+			inputs:   []string{`{"v":"` + base64JSONTestJWT + `"}`},
+			expected: "VJWT",
+		},
+		{
+			name:     "base64 object",
+			inputs:   []string{`{"v":"eyJpZCI6MX0="}`, `{"v":"eyJpZCI6Mn0"}`},
+			expected: "VBase64",
+		},
+		{
+			name:     "url safe base64 array",
+			inputs:   []string{`{"v":"WyI_Pz8iXQ"}`},
+			expected: "VBase64",
+		},
+		{
+			name:     "array of tokens",
+			inputs:   []string{`{"v":["` + base64JSONTestJWT + `"]}`},
+			expected: "[]VJWT",
+		},
+		{
+			name:     "not json",
+			inputs:   []string{`{"v":"eyJpZCI6MX0="}`, `{"v":"eWord"}`},
+			expected: "string",
+		},
+		{
+			name:     "jwt and base64 json",
+			inputs:   []string{`{"v":"eyJpZCI6MX0="}`, `{"v":"` + base64JSONTestJWT + `"}`},
+			expected: "string",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			parser := NewJSONParser(baseTypeName, OptExpandBase64JSON(!tc.disabled))
+			for _, input := range tc.inputs {
+				require.NoError(t, parser.FeedBytes([]byte(input)))
+			}
+
+			fields := parser.Fields()
+			require.Len(t, fields, 1)
+			assert.Equal(t, tc.expected, fields[0].Type)
+		})
+	}
+}
+
+func TestParserBase64JSONCode(t *testing.T) {
+	t.Parallel()
+
+	parser := NewJSONParser(baseTypeName, OptExpandBase64JSON(true))
+	require.NoError(t, parser.FeedBytes([]byte(`{"token":"`+base64JSONTestJWT+`","state":"eyJwYWdlIjoyfQ=="}`)))
+
+	out := runGeneratedCode(t, parser, `
+	var d Document
+	if err := json.NewDecoder(os.Stdin).Decode(&d); err != nil {
+		panic(err)
+	}
+	fmt.Println(d.Token.Claims.Sub, d.Token.Claims.Iat, d.State.Page)
+	out, err := json.Marshal(d)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(out))
+`, `{"token":"`+base64JSONTestJWT+`","state":"eyJwYWdlIjo3fQ"}`)
+	assert.Equal(t, "1234567890 1516239022 7\n"+
+		`{"state":"eyJwYWdlIjo3fQ==","token":"`+base64JSONTestJWT+`"}`+"\n", out)
+}
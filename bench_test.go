@@ -0,0 +1,115 @@
+package json2go
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+// benchDocument returns generated json document with array of n objects of mixed structure.
+func benchDocument(n int) []byte {
+	items := make([]interface{}, n)
+	for i := range items {
+		item := map[string]interface{}{
+			"id":      i,
+			"name":    fmt.Sprintf("item %d", i),
+			"price":   float64(i) / 100,
+			"active":  i%2 == 0,
+			"created": "2020-10-03T15:04:05Z",
+			"tags":    []interface{}{"a", "b", fmt.Sprintf("t%d", i%10)},
+			"owner": map[string]interface{}{
+				"id":    i % 100,
+				"email": fmt.Sprintf("user%d@example.com", i%100),
+			},
+		}
+		if i%3 == 0 {
+			item["note"] = nil
+		}
+		if i%5 == 0 {
+			item["attrs"] = map[string]interface{}{
+				fmt.Sprintf("k%d", i%7): i,
+			}
+		}
+		items[i] = item
+	}
+
+	data, err := json.Marshal(map[string]interface{}{
+		"count": n,
+		"items": items,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	return data
+}
+
+func benchmarkFeedBytes(b *testing.B, n int) {
+	data := benchDocument(n)
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		parser := NewJSONParser(baseTypeName)
+		if err := parser.FeedBytes(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFeedBytes1k(b *testing.B)   { benchmarkFeedBytes(b, 1000) }
+func BenchmarkFeedBytes100k(b *testing.B) { benchmarkFeedBytes(b, 100000) }
+
+func benchmarkFeedValue(b *testing.B, n int) {
+	var v interface{}
+	if err := json.Unmarshal(benchDocument(n), &v); err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		parser := NewJSONParser(baseTypeName)
+		parser.FeedValue(v)
+	}
+}
+
+func BenchmarkFeedValue1k(b *testing.B)   { benchmarkFeedValue(b, 1000) }
+func BenchmarkFeedValue100k(b *testing.B) { benchmarkFeedValue(b, 100000) }
+
+func benchmarkFeedValueNDJSON(b *testing.B, opts ...JSONParserOpt) {
+	var docs []interface{}
+	for i := 0; i < 1000; i++ {
+		var v interface{}
+		if err := json.Unmarshal(benchDocument(10), &v); err != nil {
+			b.Fatal(err)
+		}
+		docs = append(docs, v)
+	}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		parser := NewJSONParser(baseTypeName, opts...)
+		for _, v := range docs {
+			parser.FeedValue(v)
+		}
+	}
+}
+
+func BenchmarkFeedValueNDJSON(b *testing.B)       { benchmarkFeedValueNDJSON(b) }
+func BenchmarkFeedValueNDJSONCached(b *testing.B) { benchmarkFeedValueNDJSON(b, OptInputCache(1000)) }
+
+func BenchmarkString(b *testing.B) {
+	parser := NewJSONParser(
+		baseTypeName,
+		OptExtractCommonTypes(true),
+		OptMakeMaps(true, 1),
+	)
+	if err := parser.FeedBytes(benchDocument(1000)); err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_ = parser.String()
+	}
+}
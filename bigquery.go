@@ -0,0 +1,131 @@
+package json2go
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// BigQueryField is a column of BigQuery table schema, as in schema json files of bq tool.
+type BigQueryField struct {
+	Name string `json:"name"`
+	// Type is a BigQuery type, like STRING, INTEGER or RECORD.
+	Type string `json:"type"`
+	// Mode is NULLABLE, REQUIRED or REPEATED.
+	Mode string `json:"mode"`
+	// Fields are fields of RECORD columns.
+	Fields []BigQueryField `json:"fields,omitempty"`
+}
+
+// BigQueryEmitter emits BigQuery table schema json from IR, describing columns of root struct type.
+//
+// Slices are REPEATED, and fields of pointers, optional values or with omitempty are NULLABLE. Structs are RECORDs,
+// with declared types inlined. Values BigQuery can't represent in columns, like nested slices, maps, any values
+// and recursive values, are JSON. Invalid column names are sanitized, e.g. "user-id" becomes "user_id".
+type BigQueryEmitter struct{}
+
+// Emit returns BigQuery table schema json.
+func (BigQueryEmitter) Emit(ir *Schema) ([]byte, error) {
+	if err := validateIR(ir); err != nil {
+		return nil, err
+	}
+	root := irElemType(ir, ir.Types[ir.Root])
+	if root.Kind == SchemaSlice {
+		// Array documents are loaded as rows.
+		root = irElemType(ir, root.Elem)
+	}
+	if root.Kind != SchemaStruct {
+		return nil, fmt.Errorf("%w: BigQuery table schema needs struct root type, not %s", ErrUnsupportedShape, root.Kind)
+	}
+	fields := bigQueryFields(ir, root, map[string]bool{ir.Root: true})
+	return json.MarshalIndent(fields, "", "  ")
+}
+
+// irElemType returns declared type of named type, or type itself.
+func irElemType(ir *Schema, t *SchemaType) *SchemaType {
+	if t.Kind == SchemaNamed {
+		return ir.Types[t.Name]
+	}
+	return t
+}
+
+// bigQueryFields returns columns of struct type. Declared types being expanded are kept in expanding.
+func bigQueryFields(ir *Schema, t *SchemaType, expanding map[string]bool) []BigQueryField {
+	var fields []BigQueryField
+	names := make(map[string]bool)
+	for _, f := range t.Fields {
+		name := bigQueryName(f.Key)
+		for names[strings.ToLower(name)] {
+			name = nextName(name)
+		}
+		names[strings.ToLower(name)] = true
+
+		field := bigQueryField(ir, f.Type, expanding)
+		field.Name = name
+		if field.Mode == "REQUIRED" && (f.OmitEmpty || f.OmitZero) {
+			field.Mode = "NULLABLE"
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// bigQueryField returns column of type, without name.
+func bigQueryField(ir *Schema, t *SchemaType, expanding map[string]bool) BigQueryField {
+	switch t.Kind {
+	case SchemaBool:
+		return BigQueryField{Type: "BOOLEAN", Mode: "REQUIRED"}
+	case SchemaInt:
+		return BigQueryField{Type: "INTEGER", Mode: "REQUIRED"}
+	case SchemaFloat:
+		return BigQueryField{Type: "FLOAT", Mode: "REQUIRED"}
+	case SchemaString:
+		return BigQueryField{Type: "STRING", Mode: "REQUIRED"}
+	case SchemaTime:
+		return BigQueryField{Type: "TIMESTAMP", Mode: "REQUIRED"}
+	case SchemaPointer, SchemaOptional:
+		field := bigQueryField(ir, t.Elem, expanding)
+		if field.Mode == "REQUIRED" {
+			field.Mode = "NULLABLE"
+		}
+		return field
+	case SchemaSlice:
+		elem := bigQueryField(ir, t.Elem, expanding)
+		if elem.Mode == "REPEATED" {
+			// Arrays of arrays aren't supported.
+			break
+		}
+		elem.Mode = "REPEATED"
+		return elem
+	case SchemaStruct:
+		return BigQueryField{Type: "RECORD", Mode: "REQUIRED", Fields: bigQueryFields(ir, t, expanding)}
+	case SchemaNamed:
+		if expanding[t.Name] {
+			break
+		}
+		expanding[t.Name] = true
+		defer delete(expanding, t.Name)
+		return bigQueryField(ir, ir.Types[t.Name], expanding)
+	}
+	// Maps, any values, json.RawMessage and types with own json methods.
+	return BigQueryField{Type: "JSON", Mode: "NULLABLE"}
+}
+
+// bigQueryName returns valid column name for json key: letters, digits and underscores, not starting with digit.
+func bigQueryName(key string) string {
+	name := []byte(key)
+	for i, c := range name {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_') {
+			name[i] = '_'
+		}
+	}
+	if len(name) == 0 || name[0] >= '0' && name[0] <= '9' {
+		name = append([]byte("_"), name...)
+	}
+	return string(name)
+}
+
+// BigQuerySchema returns BigQuery table schema json describing parsed documents, see BigQueryEmitter.
+func (p *JSONParser) BigQuerySchema() ([]byte, error) {
+	return BigQueryEmitter{}.Emit(p.Schema())
+}
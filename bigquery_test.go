@@ -0,0 +1,90 @@
+package json2go
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBigQuerySchema(t *testing.T) {
+	t.Parallel()
+
+	p := NewJSONParser(baseTypeName, OptExtractCommonTypes(true))
+	require.NoError(t, p.FeedBytes([]byte(`[
+		{"id": 1, "user-name": "a", "tags": ["x"], "from": {"zip": "1"}, "to": {"zip": "2"},
+			"at": "2021-01-01T00:00:00Z", "matrix": [[1]], "extra": {"a": 1}},
+		{"id": 2, "user-name": "b", "tags": [], "from": {"zip": "3"}, "to": {"zip": "4"},
+			"at": "2021-01-02T00:00:00Z", "matrix": [], "score": 1.5, "extra": "x"}
+	]`)))
+
+	out, err := p.BigQuerySchema()
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"name": "at", "type": "TIMESTAMP", "mode": "REQUIRED"},
+		{"name": "extra", "type": "JSON", "mode": "NULLABLE"},
+		{"name": "from", "type": "RECORD", "mode": "REQUIRED", "fields": [{"name": "zip", "type": "STRING", "mode": "REQUIRED"}]},
+		{"name": "id", "type": "INTEGER", "mode": "REQUIRED"},
+		{"name": "matrix", "type": "JSON", "mode": "NULLABLE"},
+		{"name": "score", "type": "FLOAT", "mode": "NULLABLE"},
+		{"name": "tags", "type": "STRING", "mode": "REPEATED"},
+		{"name": "to", "type": "RECORD", "mode": "REQUIRED", "fields": [{"name": "zip", "type": "STRING", "mode": "REQUIRED"}]},
+		{"name": "user_name", "type": "STRING", "mode": "REQUIRED"}
+	]`, string(out))
+}
+
+func TestBigQueryEmitter(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		ir       *Schema
+		expected string
+		err      error
+	}{
+		{
+			name: "recursive type",
+			ir: &Schema{Root: "Node", Types: map[string]*SchemaType{"Node": {Kind: SchemaStruct, Fields: []SchemaField{
+				{Name: "Name", Key: "name", Type: &SchemaType{Kind: SchemaString}},
+				{Name: "Children", Key: "children", Type: &SchemaType{Kind: SchemaSlice, Elem: &SchemaType{Kind: SchemaNamed, Name: "Node"}}},
+			}}}},
+			expected: `[{"name": "name", "type": "STRING", "mode": "REQUIRED"}, {"name": "children", "type": "JSON", "mode": "REPEATED"}]`,
+		},
+		{
+			name: "omitempty and sanitized names",
+			ir: &Schema{Root: "Document", Types: map[string]*SchemaType{"Document": {Kind: SchemaStruct, Fields: []SchemaField{
+				{Name: "A", Key: "a.b", Type: &SchemaType{Kind: SchemaBool}, OmitEmpty: true},
+				{Name: "AB", Key: "A_b", Type: &SchemaType{Kind: SchemaBool}},
+				{Name: "N1", Key: "1", Type: &SchemaType{Kind: SchemaOptional, Elem: &SchemaType{Kind: SchemaInt}}},
+			}}}},
+			expected: `[{"name": "a_b", "type": "BOOLEAN", "mode": "NULLABLE"}, {"name": "A_b2", "type": "BOOLEAN", "mode": "REQUIRED"},
+				{"name": "_1", "type": "INTEGER", "mode": "NULLABLE"}]`,
+		},
+		{
+			name: "root isn't struct",
+			ir:   &Schema{Root: "Document", Types: map[string]*SchemaType{"Document": {Kind: SchemaString}}},
+			err:  ErrUnsupportedShape,
+		},
+		{
+			name: "invalid IR",
+			ir:   &Schema{Root: "Document"},
+			err:  ErrInvalidIR,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			out, err := BigQueryEmitter{}.Emit(tc.ir)
+			if tc.err != nil {
+				assert.True(t, errors.Is(err, tc.err), "unexpected error: %v", err)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.expected, string(out))
+		})
+	}
+}
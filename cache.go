@@ -0,0 +1,235 @@
+package json2go
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// subtreeCache remembers hashes of json values grown by nodes of tree, see OptInputCache.
+// Growing a node with the same value again doesn't change inferred types, if nothing in tree changed since then,
+// so walking such values is skipped, and only counts of objects, attributes and strings are replayed.
+type subtreeCache struct {
+	size int
+	// grown are numbers of changes of tree after values with hashes were grown by nodes.
+	grown map[subtreeKey]int
+	// changes is a number of changes of nodes of tree, other than counts.
+	changes int
+}
+
+type subtreeKey struct {
+	n   *node
+	sum uint64
+}
+
+func newSubtreeCache(size uint) *subtreeCache {
+	return &subtreeCache{
+		size:  int(size),
+		grown: make(map[subtreeKey]int),
+	}
+}
+
+// has checks if value with hash was grown by node, and tree didn't change since then.
+func (c *subtreeCache) has(n *node, sum uint64) bool {
+	changes, ok := c.grown[subtreeKey{n: n, sum: sum}]
+	return ok && changes == c.changes
+}
+
+// add remembers value with hash grown by node, unless cache is full.
+func (c *subtreeCache) add(n *node, sum uint64) {
+	key := subtreeKey{n: n, sum: sum}
+	if _, ok := c.grown[key]; ok || len(c.grown) < c.size {
+		c.grown[key] = c.changes
+	}
+}
+
+// nodeState is a state of node changed by growing it, without counts.
+type nodeState struct {
+	t              nodeType
+	arrayLevel     int
+	nullable       bool
+	arrayWithNulls bool
+	seenKinds      int
+	seenTypes      int
+	needsFloat64   bool
+	hasText        bool
+	boolStrings    int
+	formats        int
+	enumValues     int
+	enumInvalid    bool
+	matching       int
+	encoded        bool
+	tuple          int
+	tupleInvalid   bool
+	children       int
+	required       int
+}
+
+func (n *node) state() nodeState {
+	s := nodeState{
+		t:              n.t,
+		arrayLevel:     n.arrayLevel,
+		nullable:       n.nullable,
+		arrayWithNulls: n.arrayWithNulls,
+		seenKinds:      n.seenKinds,
+		seenTypes:      n.seenTypes,
+		needsFloat64:   n.needsFloat64,
+		hasText:        n.hasText,
+		boolStrings:    n.boolStrings,
+		formats:        n.formats,
+		enumValues:     len(n.enumValues),
+		enumInvalid:    n.enumInvalid,
+		matching:       len(n.matching),
+		encoded:        n.encoded != nil,
+		tuple:          len(n.tuple),
+		tupleInvalid:   n.tupleInvalid,
+		children:       len(n.children),
+	}
+	for _, c := range n.children {
+		if c.required {
+			s.required++
+		}
+	}
+	return s
+}
+
+// replay counts objects, attributes and strings of value, which was already grown by node, like grow does.
+func (n *node) replay(input interface{}) {
+	if values, ok := input.(duplicateValues); ok {
+		for _, v := range values {
+			n.replay(v)
+		}
+		return
+	}
+	if input == nil {
+		return
+	}
+	n.replayStrings(input)
+	if ar, ok := input.([]interface{}); ok {
+		n.replayTuple(ar)
+	}
+	if n.t.id() == nodeTypeInterface.id() {
+		return
+	}
+	n.replayChildren(input)
+}
+
+func (n *node) replayStrings(input interface{}) {
+	switch typedInput := input.(type) {
+	case []interface{}:
+		for _, el := range typedInput {
+			n.replayStrings(el)
+		}
+	case string:
+		n.stringValues++
+	}
+}
+
+func (n *node) replayTuple(in []interface{}) {
+	if n.tupleInvalid {
+		return
+	}
+	nested := false
+	for _, el := range in {
+		if ar, ok := el.([]interface{}); ok {
+			nested = true
+			n.replayTuple(ar)
+		}
+	}
+	if nested || len(n.tuple) != len(in) {
+		return
+	}
+	for i, v := range in {
+		n.tuple[i].replay(v)
+	}
+}
+
+func (n *node) replayChildren(in interface{}) {
+	if ar, ok := in.([]interface{}); ok {
+		for _, el := range ar {
+			if el != nil {
+				n.replayChildren(el)
+			}
+		}
+		return
+	}
+	obj, ok := in.(map[string]interface{})
+	if !ok {
+		return
+	}
+	n.objects++
+	n.weightedObjects += n.inputWeight()
+	for k, v := range obj {
+		child := n.getChild(k)
+		child.occurrences++
+		child.weightedOccurrences += n.inputWeight()
+		child.replay(v)
+	}
+}
+
+// hashValue returns FNV-1a hash of decoded json value. Hashes of objects don't depend on order of keys.
+func hashValue(v interface{}) uint64 {
+	h := fnvHash(fnvOffset)
+	switch typedValue := v.(type) {
+	case nil:
+		h = h.byte('n')
+	case bool:
+		if typedValue {
+			h = h.byte('t')
+		} else {
+			h = h.byte('f')
+		}
+	case float64:
+		h = h.byte('d').uint64(math.Float64bits(typedValue))
+	case json.Number:
+		h = h.byte('#').string(string(typedValue))
+	case string:
+		h = h.byte('s').string(typedValue)
+	case []interface{}:
+		h = h.byte('[')
+		for _, el := range typedValue {
+			h = h.uint64(hashValue(el))
+		}
+	case duplicateValues:
+		h = h.byte('*')
+		for _, el := range typedValue {
+			h = h.uint64(hashValue(el))
+		}
+	case map[string]interface{}:
+		// Hashes of attributes are summed, so they don't need to be sorted.
+		var sum uint64
+		for k, el := range typedValue {
+			sum += uint64(fnvHash(fnvOffset).string(k).uint64(hashValue(el)))
+		}
+		h = h.byte('{').uint64(sum)
+	default:
+		h = h.string(fmt.Sprintf("%T:%v", v, v))
+	}
+	return uint64(h)
+}
+
+const (
+	fnvOffset = 14695981039346656037
+	fnvPrime  = 1099511628211
+)
+
+// fnvHash is a 64-bit FNV-1a hash, computed without allocations.
+type fnvHash uint64
+
+func (h fnvHash) byte(b byte) fnvHash {
+	return (h ^ fnvHash(b)) * fnvPrime
+}
+
+func (h fnvHash) string(s string) fnvHash {
+	for i := 0; i < len(s); i++ {
+		h = h.byte(s[i])
+	}
+	return h.byte(0)
+}
+
+func (h fnvHash) uint64(v uint64) fnvHash {
+	for i := 0; i < 8; i++ {
+		h = h.byte(byte(v >> (8 * i)))
+	}
+	return h
+}
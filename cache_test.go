@@ -0,0 +1,168 @@
+package json2go
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashValue(t *testing.T) {
+	t.Parallel()
+
+	decode := func(s string) interface{} {
+		var v interface{}
+		require.NoError(t, json.Unmarshal([]byte(s), &v))
+		return v
+	}
+
+	assert.Equal(t, hashValue(decode(`{"a":1,"b":[1,"x"]}`)), hashValue(decode(`{"b":[1,"x"],"a":1}`)))
+	for _, other := range []string{`{"a":1,"b":[1,"y"]}`, `{"a":1,"b":["x",1]}`, `{"a":1,"c":[1,"x"]}`, `{"a":"1","b":[1,"x"]}`, `[{"a":1,"b":[1,"x"]}]`} {
+		assert.NotEqual(t, hashValue(decode(`{"a":1,"b":[1,"x"]}`)), hashValue(decode(other)), other)
+	}
+	assert.NotEqual(t, hashValue(decode(`{"a":{"b":1},"c":{"d":2}}`)), hashValue(decode(`{"a":{"d":2},"c":{"b":1}}`)))
+}
+
+func TestSubtreeCache(t *testing.T) {
+	t.Parallel()
+
+	c := newSubtreeCache(2)
+	n1, n2 := newNode("a"), newNode("b")
+	c.add(n1, 1)
+	c.add(n2, 1)
+	c.add(n1, 2)
+	assert.True(t, c.has(n1, 1))
+	assert.True(t, c.has(n2, 1))
+	assert.False(t, c.has(n1, 2), "cache should be full")
+
+	c.changes++
+	assert.False(t, c.has(n1, 1), "tree changed")
+	c.add(n1, 1)
+	assert.True(t, c.has(n1, 1))
+}
+
+func TestParserInputCache(t *testing.T) {
+	t.Parallel()
+
+	repeated := func(input string, n int) []string {
+		var inputs []string
+		for i := 0; i < n; i++ {
+			inputs = append(inputs, input)
+		}
+		return inputs
+	}
+
+	testCases := []struct {
+		name   string
+		opts   []JSONParserOpt
+		inputs []string
+	}{
+		{
+			name: "types",
+			inputs: []string{
+				`{"a":1,"b":"2020-10-03T15:04:05Z"}`,
+				`{"a":1,"b":"2020-10-03T15:04:05Z"}`,
+				`{"a":1.5}`,
+				`{"a":1,"b":"2020-10-03T15:04:05Z"}`,
+				`{"a":`,
+				`{"a":`,
+			},
+		},
+		{
+			name:   "comment out",
+			opts:   []JSONParserOpt{OptCommentOutFields(0.6, false)},
+			inputs: append(repeated(`{"a":1,"b":2}`, 9), repeated(`{"a":1}`, 3)...),
+		},
+		{
+			name:   "repeated subtrees",
+			inputs: append(repeated(`{"id":1,"u":{"a":1,"b":2},"l":[{"a":1},{"a":1},{"a":1,"b":"x"}]}`, 3), repeated(`{"id":2,"u":{"a":1}}`, 4)...),
+		},
+		{
+			name:   "comment out repeated subtrees",
+			opts:   []JSONParserOpt{OptCommentOutFields(0.6, false)},
+			inputs: append(repeated(`{"id":1,"u":{"a":1,"b":2},"l":[{"a":1},{"a":1},{"a":1,"b":2}]}`, 3), repeated(`{"id":2,"u":{"a":1}}`, 4)...),
+		},
+		{
+			name:   "structured logs",
+			opts:   []JSONParserOpt{OptStructuredLogs(0.5)},
+			inputs: append(repeated(`{"level":"info","msg":"x","user":"a"}`, 4), repeated(`{"level":"info","msg":"x"}`, 6)...),
+		},
+		{
+			name:   "recency decay",
+			opts:   []JSONParserOpt{OptRecencyDecay(2), OptCommentOutFields(0.5, false)},
+			inputs: append(repeated(`{"a":1,"old":2}`, 6), repeated(`{"a":1,"new":2}`, 3)...),
+		},
+		{
+			name:   "enums and tuples",
+			opts:   []JSONParserOpt{OptTuples(true)},
+			inputs: append(repeated(`{"s":["a","b"],"p":[1,"x"]}`, 5), `{"s":["c"],"p":[2,"y"]}`),
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			parser := NewJSONParser(baseTypeName, tc.opts...)
+			cachedParser := NewJSONParser(baseTypeName, append(tc.opts, OptInputCache(10))...)
+			for _, in := range tc.inputs {
+				err := parser.FeedBytes([]byte(in))
+				cachedErr := cachedParser.FeedBytes([]byte(in))
+				assert.Equal(t, err, cachedErr)
+			}
+
+			assert.Equal(t, parser.String(), cachedParser.String())
+			assert.Equal(t, parser.Warnings(), cachedParser.Warnings())
+			assert.Equal(t, parser.Stats(), cachedParser.Stats())
+		})
+	}
+}
+
+func TestParserInputCacheWeighted(t *testing.T) {
+	t.Parallel()
+
+	parser := NewJSONParser(baseTypeName, OptCommentOutFields(0.5, false))
+	cachedParser := NewJSONParser(baseTypeName, OptCommentOutFields(0.5, false), OptInputCache(10))
+	for _, p := range []*JSONParser{parser, cachedParser} {
+		for i := 0; i < 3; i++ {
+			require.NoError(t, p.FeedWeighted([]byte(`{"a":1,"b":2}`), 1))
+		}
+		require.NoError(t, p.FeedWeighted([]byte(`{"a":1}`), 4))
+	}
+	assert.Equal(t, parser.String(), cachedParser.String())
+	assert.Contains(t, cachedParser.String(), "// B")
+}
+
+func TestParserInputCacheSkipsRepeatedSubtrees(t *testing.T) {
+	t.Parallel()
+
+	parser := NewJSONParser(baseTypeName, OptInputCache(100))
+	for i := 0; i < 5; i++ {
+		require.NoError(t, parser.FeedBytes([]byte(fmt.Sprintf(`{"id":%d,"user":{"name":"a","roles":["x"]}}`, i))))
+	}
+	user := parser.rootNode.getChild("user")
+	require.NotNil(t, user)
+	assert.Equal(t, 5, parser.rootNode.objects)
+	assert.Equal(t, 5, user.objects)
+	assert.Equal(t, 5, user.getChild("name").stringValues)
+	assert.Equal(t, 5, user.getChild("roles").stringValues)
+	assert.True(t, parser.rootNode.cache.has(user, hashValue(map[string]interface{}{"name": "a", "roles": []interface{}{"x"}})))
+}
+
+func TestParserInputCacheCommentOut(t *testing.T) {
+	t.Parallel()
+
+	parser := NewJSONParser(baseTypeName, OptInputCache(10), OptCommentOutFields(0.6, false))
+	for i := 0; i < 12; i++ {
+		input := `{"a":1,"b":2}`
+		if i >= 9 {
+			input = `{"a":1}`
+		}
+		require.NoError(t, parser.FeedBytes([]byte(input)))
+	}
+	assert.NotContains(t, parser.String(), "// B")
+	assert.Equal(t, 12, parser.Stats().Inputs)
+}
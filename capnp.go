@@ -0,0 +1,116 @@
+package json2go
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"unicode"
+)
+
+// CapnpEmitter emits Cap'n Proto schema from IR, declaring struct of root type first.
+//
+// Field numbers follow order of fields. Fields keep go names, in camelCase required by Cap'n Proto. Text, lists and
+// structs can be null in Cap'n Proto, so optional values of other types are unions of Void "unset" and "value"
+// fields. Maps are lists of entries. Times are Unix times in microseconds, any and opaque values are json Text.
+// File id is derived from name of root type, so output is stable.
+type CapnpEmitter struct{}
+
+// Emit returns Cap'n Proto schema.
+func (CapnpEmitter) Emit(ir *Schema) ([]byte, error) {
+	s, err := newIDLSchema(ir, "Cap'n Proto schema", true)
+	if err != nil {
+		return nil, err
+	}
+
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(ir.Root))
+	var b strings.Builder
+	fmt.Fprintf(&b, "@0x%016x;\n", h.Sum64()|1<<63)
+	for _, d := range s.decls {
+		fmt.Fprintf(&b, "\nstruct %s {\n", capnpName(d.name, true))
+		ordinal := 0
+		names := make(map[string]bool)
+		for _, f := range d.fields {
+			typ, scalar, comment := capnpType(ir, s, f.Type)
+			name := capnpName(f.Name, false)
+			for names[name] {
+				name = nextName(name)
+			}
+			names[name] = true
+			if comment != "" {
+				comment = "  # " + comment
+			}
+			if scalar && f.isOptional() {
+				fmt.Fprintf(&b, "  %s :union {\n    unset @%d :Void;\n    value @%d :%s;%s\n  }\n", name, ordinal, ordinal+1, typ, comment)
+				ordinal += 2
+				continue
+			}
+			fmt.Fprintf(&b, "  %s @%d :%s;%s\n", name, ordinal, typ, comment)
+			ordinal++
+		}
+		b.WriteString("}\n")
+	}
+	return []byte(b.String()), nil
+}
+
+// capnpType returns Cap'n Proto type of values, if it's a scalar type, without pointer in Cap'n Proto,
+// and comment describing representation of values.
+func capnpType(ir *Schema, s *idlSchema, t *SchemaType) (typ string, scalar bool, comment string) {
+	switch t.Kind {
+	case SchemaBool:
+		return "Bool", true, ""
+	case SchemaInt:
+		return "Int64", true, ""
+	case SchemaFloat:
+		return fmt.Sprintf("Float%d", t.Bits), true, ""
+	case SchemaString:
+		return "Text", false, ""
+	case SchemaTime:
+		return "Int64", true, "Unix time in microseconds."
+	case SchemaPointer, SchemaOptional:
+		return capnpType(ir, s, t.Elem)
+	case SchemaSlice:
+		elem, _, comment := capnpType(ir, s, t.Elem)
+		return "List(" + elem + ")", false, comment
+	case SchemaMap:
+		return "List(" + capnpName(s.names[t], true) + ")", false, ""
+	case SchemaStruct:
+		return capnpName(s.names[t], true), false, ""
+	case SchemaNamed:
+		return capnpType(ir, s, ir.Types[t.Name])
+	}
+	return "Text", false, "JSON value."
+}
+
+// capnpName returns Cap'n Proto name of go name: letters and digits, with first letter upper case for types,
+// or in camelCase for fields, like "urlPath" of "URLPath".
+func capnpName(name string, upper bool) string {
+	var runes []rune
+	separated := false
+	for _, r := range name {
+		if r >= unicode.MaxASCII || !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			// Words separated with underscores are joined, like "userName" of "user_name".
+			separated = len(runes) > 0
+			continue
+		}
+		if separated {
+			r = unicode.ToUpper(r)
+			separated = false
+		}
+		runes = append(runes, r)
+	}
+	if len(runes) == 0 || unicode.IsDigit(runes[0]) {
+		runes = append([]rune("x"), runes...)
+	}
+	if upper {
+		runes[0] = unicode.ToUpper(runes[0])
+		return string(runes)
+	}
+	return lowerCamelCaseName(string(runes))
+}
+
+// CapnpSchema returns Cap'n Proto schema describing parsed documents, see CapnpEmitter.
+func (p *JSONParser) CapnpSchema() (string, error) {
+	out, err := CapnpEmitter{}.Emit(p.Schema())
+	return string(out), err
+}
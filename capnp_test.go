@@ -0,0 +1,125 @@
+package json2go
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapnpSchema(t *testing.T) {
+	t.Parallel()
+
+	p := NewJSONParser(baseTypeName)
+	require.NoError(t, p.FeedBytes([]byte(`{"id": 1, "URLPath": "a", "tags": ["x"], "addr": {"zip": "1"},
+		"at": "2021-01-01T00:00:00Z", "matrix": [[1.5]], "extra": 1}`)))
+	require.NoError(t, p.FeedBytes([]byte(`{"id": 2, "tags": [], "addr": {"zip": "2"},
+		"at": "2021-01-02T00:00:00Z", "matrix": [[1]], "extra": "x", "score": 0.5}`)))
+
+	schema, err := p.CapnpSchema()
+	require.NoError(t, err)
+	assert.Equal(t, `@0xa311a24c1471a974;
+
+struct Document {
+  addr @0 :DocumentAddr;
+  at @1 :Int64;  # Unix time in microseconds.
+  extra @2 :Text;  # JSON value.
+  id @3 :Int64;
+  matrix @4 :List(List(Float64));
+  score :union {
+    unset @5 :Void;
+    value @6 :Float64;
+  }
+  tags @7 :List(Text);
+  urlPath @8 :Text;
+}
+
+struct DocumentAddr {
+  zip @0 :Text;
+}
+`, schema)
+}
+
+func TestCapnpEmitter(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		ir       *Schema
+		expected string
+		err      error
+	}{
+		{
+			name: "recursive type and maps",
+			ir: &Schema{Root: "Node", Types: map[string]*SchemaType{"Node": {Kind: SchemaStruct, Fields: []SchemaField{
+				{Name: "Children", Key: "children", Type: &SchemaType{Kind: SchemaSlice, Elem: &SchemaType{Kind: SchemaNamed, Name: "Node"}}},
+				{Name: "Counts", Key: "counts", Type: &SchemaType{Kind: SchemaMap, Key: &SchemaType{Kind: SchemaInt},
+					Elem: &SchemaType{Kind: SchemaBool}}},
+			}}}},
+			expected: `@0xe6bd1cc6d2f6b68d;
+
+struct Node {
+  children @0 :List(Node);
+  counts @1 :List(NodeCountsEntry);
+}
+
+struct NodeCountsEntry {
+  key @0 :Int64;
+  value @1 :Bool;
+}
+`,
+		},
+		{
+			name: "array root",
+			ir: &Schema{Root: "Document", Types: map[string]*SchemaType{"Document": {Kind: SchemaSlice, Elem: &SchemaType{
+				Kind: SchemaStruct, Fields: []SchemaField{{Name: "ID", Key: "id", Type: &SchemaType{Kind: SchemaInt}, OmitEmpty: true}},
+			}}}},
+			expected: `@0xa311a24c1471a974;
+
+struct Document {
+  id :union {
+    unset @0 :Void;
+    value @1 :Int64;
+  }
+}
+`,
+		},
+		{
+			name: "root isn't struct",
+			ir:   &Schema{Root: "Document", Types: map[string]*SchemaType{"Document": {Kind: SchemaAny}}},
+			err:  ErrUnsupportedShape,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			out, err := CapnpEmitter{}.Emit(tc.ir)
+			if tc.err != nil {
+				assert.True(t, errors.Is(err, tc.err), "unexpected error: %v", err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(out))
+		})
+	}
+}
+
+func TestCapnpName(t *testing.T) {
+	t.Parallel()
+
+	for name, expected := range map[string]string{
+		"URLPath":   "urlPath",
+		"ID":        "id",
+		"UserID":    "userID",
+		"user_name": "userName",
+		"X1":        "x1",
+		"1st":       "x1st",
+	} {
+		assert.Equal(t, expected, capnpName(name, false), name)
+	}
+	assert.Equal(t, "DocumentAddr", capnpName("documentAddr", true))
+}
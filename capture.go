@@ -0,0 +1,295 @@
+package json2go
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand"
+	"mime"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// defaultCaptureMaxBodySize is a default limit of size of bodies recorded by Capture.
+const defaultCaptureMaxBodySize = 1 << 20
+
+// defaultCaptureMaxNames is a default limit of number of names of responses recorded by Capture.
+const defaultCaptureMaxNames = 1000
+
+// Capture records json bodies of http responses into converters at runtime, so a running service continuously learns
+// types of responses of its upstream dependencies, with Transport, or of its own handlers, with Middleware.
+// Responses are grouped by name of request, each name has own converter and root type.
+// Generated types are dumped on demand with Generate, or served by Capture as http handler.
+// Zero value records all json responses up to 1 MiB, grouped by path of request, with up to 1000 names.
+type Capture struct {
+	// Name returns name of root type of response to request, like "GetUser". Default name is made of path of request,
+	// without segments with numeric ids, e.g. "UsersOrders" for "/users/42/orders". Responses with empty names aren't
+	// recorded.
+	Name func(r *http.Request) string
+	// SampleRate is a fraction of responses recorded, like 0.01 for one of hundred responses. 0 means all responses.
+	SampleRate float64
+	// MaxBodySize limits size in bytes of recorded bodies, larger bodies aren't recorded. 0 means 1 MiB.
+	MaxBodySize int64
+	// MaxNames limits number of names of recorded responses, so paths with ids, which aren't recognized by default
+	// name, don't grow converters without bounds. Responses with other names aren't recorded, and ErrTooManyNames
+	// is reported to ErrorHandler. 0 means 1000.
+	MaxNames int
+	// Opts are options of converters.
+	Opts []JSONParserOpt
+	// ErrorHandler is called with errors of bodies, which couldn't be recorded, like invalid json bodies.
+	// Nil ignores them.
+	ErrorHandler func(name string, err error)
+
+	mu         sync.Mutex
+	converters map[string]*Converter
+}
+
+// Transport returns http.RoundTripper recording json bodies of successful responses of next, or of
+// http.DefaultTransport if next is nil. Bodies are recorded when they are read to the end by client.
+func (c *Capture) Transport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return captureTransport{c: c, next: next}
+}
+
+type captureTransport struct {
+	c    *Capture
+	next http.RoundTripper
+}
+
+func (t captureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || resp.StatusCode >= 300 || !isJSONContentType(resp.Header.Get("Content-Type")) {
+		return resp, err
+	}
+	if resp.ContentLength > t.c.maxBodySize() {
+		return resp, nil
+	}
+	name := t.c.name(req)
+	if name == "" || !t.c.sampled() {
+		return resp, nil
+	}
+
+	resp.Body = &captureBody{ReadCloser: resp.Body, limit: t.c.maxBodySize(), record: func(body []byte) {
+		t.c.record(name, body)
+	}}
+	return resp, nil
+}
+
+// captureBody is a response body, which records body read to the end, unless it's larger than limit.
+type captureBody struct {
+	io.ReadCloser
+	limit    int64
+	buf      bytes.Buffer
+	overflow bool
+	recorded bool
+	record   func(body []byte)
+}
+
+func (b *captureBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if !b.overflow {
+		if int64(b.buf.Len()+n) > b.limit {
+			b.overflow = true
+			b.buf = bytes.Buffer{}
+		} else {
+			b.buf.Write(p[:n])
+		}
+	}
+	if err == io.EOF && !b.overflow && !b.recorded {
+		b.recorded = true
+		b.record(b.buf.Bytes())
+	}
+	return n, err
+}
+
+// Middleware returns http handler recording json bodies of successful responses of next.
+func (c *Capture) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := c.name(r)
+		if name == "" || !c.sampled() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &captureResponseWriter{ResponseWriter: w, limit: c.maxBodySize(), status: http.StatusOK}
+		next.ServeHTTP(cw, r)
+		if !cw.overflow && cw.status < 300 && isJSONContentType(w.Header().Get("Content-Type")) {
+			c.record(name, cw.buf.Bytes())
+		}
+	})
+}
+
+// captureResponseWriter is a response writer, which keeps copy of body, unless it's larger than limit.
+type captureResponseWriter struct {
+	http.ResponseWriter
+	limit       int64
+	buf         bytes.Buffer
+	overflow    bool
+	status      int
+	wroteHeader bool
+}
+
+func (w *captureResponseWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *captureResponseWriter) Write(p []byte) (int, error) {
+	w.wroteHeader = true
+	if !w.overflow {
+		if int64(w.buf.Len()+len(p)) > w.limit {
+			w.overflow = true
+			w.buf = bytes.Buffer{}
+		} else {
+			w.buf.Write(p)
+		}
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Flush flushes response of streaming handlers, if underlying writer supports it.
+func (w *captureResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Names returns sorted names of recorded responses.
+func (c *Capture) Names() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	names := make([]string, 0, len(c.converters))
+	for name := range c.converters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Converter returns converter of responses with name, or nil if none was recorded.
+func (c *Capture) Converter(name string) *Converter {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.converters[name]
+}
+
+// Generate returns go types of all recorded responses, sorted by name, like ConvertAll does, so types common for
+// responses, like with OptExtractCommonTypes, are declared once. Paths of values start with names of responses.
+func (c *Capture) Generate() (string, error) {
+	names := c.Names()
+	roots := make([]*node, len(names))
+	for i, name := range names {
+		roots[i] = c.Converter(name).cloneRoot()
+	}
+	return generateRoots(names, roots, c.Opts...)
+}
+
+// ServeHTTP serves go types of all recorded responses, see Generate, e.g. at debug endpoint of service.
+func (c *Capture) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	code, err := c.Generate()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = io.WriteString(w, code)
+}
+
+// record feeds body to converter of responses with name.
+func (c *Capture) record(name string, body []byte) {
+	c.mu.Lock()
+	if c.converters == nil {
+		c.converters = make(map[string]*Converter)
+	}
+	converter, ok := c.converters[name]
+	if !ok {
+		if n := len(c.converters); n >= c.maxNames() {
+			c.mu.Unlock()
+			if c.ErrorHandler != nil {
+				c.ErrorHandler(name, fmt.Errorf("%w: responses of %d names are recorded", ErrTooManyNames, n))
+			}
+			return
+		}
+		converter = NewConverter(name, c.Opts...)
+		c.converters[name] = converter
+	}
+	c.mu.Unlock()
+
+	if err := converter.Feed(body); err != nil && c.ErrorHandler != nil {
+		c.ErrorHandler(name, err)
+	}
+}
+
+func (c *Capture) name(r *http.Request) string {
+	if c.Name != nil {
+		return c.Name(r)
+	}
+	return captureName(r.URL.Path)
+}
+
+func (c *Capture) sampled() bool {
+	return c.SampleRate <= 0 || c.SampleRate >= 1 || rand.Float64() < c.SampleRate
+}
+
+func (c *Capture) maxBodySize() int64 {
+	if c.MaxBodySize <= 0 {
+		return defaultCaptureMaxBodySize
+	}
+	return c.MaxBodySize
+}
+
+func (c *Capture) maxNames() int {
+	if c.MaxNames <= 0 {
+		return defaultCaptureMaxNames
+	}
+	return c.MaxNames
+}
+
+// captureName returns type name of responses of requests with path. Segments with digits only, or with long
+// hexadecimal ids, like UUIDs, are skipped.
+func captureName(path string) string {
+	var name strings.Builder
+	for _, segment := range strings.Split(path, "/") {
+		if isCaptureID(segment) {
+			continue
+		}
+		name.WriteString(attrName(strings.NewReplacer("-", "_", ".", "_").Replace(segment)))
+	}
+	if name.Len() == 0 {
+		return "Root"
+	}
+	return name.String()
+}
+
+// isCaptureID checks if path segment looks like id: it has digits only, or it's a long hexadecimal number.
+func isCaptureID(segment string) bool {
+	digits, hex := 0, 0
+	for _, r := range segment {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case r >= 'a' && r <= 'f' || r >= 'A' && r <= 'F' || r == '-':
+			hex++
+		default:
+			return false
+		}
+	}
+	return digits > 0 && (hex == 0 || digits+hex >= 16)
+}
+
+// isJSONContentType checks if content type is json, like "application/json" or "application/problem+json".
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
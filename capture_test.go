@@ -0,0 +1,155 @@
+package json2go
+
+import (
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCaptureTransport(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/users/42":
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			_, _ = w.Write([]byte(`{"id":42,"name":"a"}`))
+		case "/users/43":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"id":43,"email":"b@example.com"}`))
+		case "/large":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"items":[` + strings.Repeat(`1,`, 100) + `1]}`))
+		case "/text":
+			_, _ = w.Write([]byte(`{"id":1}`))
+		case "/invalid":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"id":`))
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"not found"}`))
+		}
+	}))
+	defer server.Close()
+
+	var failed []string
+	capture := &Capture{MaxBodySize: 100, ErrorHandler: func(name string, err error) {
+		assert.True(t, errors.Is(err, ErrInvalidJSON), err)
+		failed = append(failed, name)
+	}}
+	client := &http.Client{Transport: capture.Transport(nil)}
+	for _, path := range []string{"/users/42", "/users/43", "/large", "/text", "/invalid", "/missing"} {
+		resp, err := client.Get(server.URL + path)
+		require.NoError(t, err)
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		assert.NotEmpty(t, body, path)
+	}
+
+	assert.Equal(t, []string{"Invalid"}, failed)
+	assert.Equal(t, []string{"Invalid", "Users"}, capture.Names())
+	code, err := capture.Converter("Users").Generate()
+	require.NoError(t, err)
+	assert.Equal(t, normalizeStr(`type Users struct {
+		Email string `+"`json:\"email,omitempty\"`"+`
+		ID    int    `+"`json:\"id\"`"+`
+		Name  string `+"`json:\"name,omitempty\"`"+`
+}`), normalizeStr(code))
+	assert.Nil(t, capture.Converter("Missing"))
+}
+
+func TestCaptureMiddleware(t *testing.T) {
+	t.Parallel()
+
+	capture := &Capture{Name: func(r *http.Request) string {
+		return r.Method + "Status"
+	}}
+	handler := capture.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("fail") != "" {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`{"error":"failed"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true,`))
+		_, _ = w.Write([]byte(`"uptime":1.5}`))
+	}))
+	for _, target := range []string{"/status", "/status?fail=1"} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		assert.NotEmpty(t, rec.Body.String())
+	}
+
+	rec := httptest.NewRecorder()
+	capture.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/types", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, normalizeStr(`type GETStatus struct {
+		Ok     bool    `+"`json:\"ok\"`"+`
+		Uptime float64 `+"`json:\"uptime\"`"+`
+}`), normalizeStr(rec.Body.String()))
+}
+
+func TestCaptureGenerateCommonTypes(t *testing.T) {
+	t.Parallel()
+
+	capture := &Capture{Opts: []JSONParserOpt{OptExtractCommonTypes(true)}}
+	capture.record("Users", []byte(`{"a":{"id":1,"n":"x"},"b":{"id":2,"n":"y"}}`))
+	capture.record("Orders", []byte(`{"a":{"id":"s","n":true},"b":{"id":"t","n":false}}`))
+	capture.record("Items", []byte(`{"x":{"id":1,"n":"x"}}`))
+
+	code, err := capture.Generate()
+	require.NoError(t, err)
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "types.go", "package types\n\n"+code, 0)
+	require.NoError(t, err, code)
+	_, err = (&types.Config{}).Check("types", fset, []*ast.File{file}, nil)
+	require.NoError(t, err, code)
+	assert.Equal(t, 1, strings.Count(code, "type IDN struct"), code)
+	assert.Contains(t, code, "type Items struct")
+	assert.Contains(t, code, "type Orders struct")
+	assert.Contains(t, code, "type Users struct")
+}
+
+func TestCaptureMaxNames(t *testing.T) {
+	t.Parallel()
+
+	var errs []error
+	capture := &Capture{MaxNames: 2, ErrorHandler: func(name string, err error) {
+		assert.Equal(t, "C", name)
+		errs = append(errs, err)
+	}}
+	capture.record("A", []byte(`{"id":1}`))
+	capture.record("B", []byte(`{"id":1}`))
+	capture.record("C", []byte(`{"id":1}`))
+	capture.record("A", []byte(`{"id":2,"n":"x"}`))
+	assert.Equal(t, []string{"A", "B"}, capture.Names())
+	require.Len(t, errs, 1)
+	assert.True(t, errors.Is(errs[0], ErrTooManyNames), "%v", errs[0])
+	assert.EqualError(t, errs[0], "too many names: responses of 2 names are recorded")
+}
+
+func TestCaptureName(t *testing.T) {
+	t.Parallel()
+
+	for path, expected := range map[string]string{
+		"/users/42/orders":                            "UsersOrders",
+		"/v1/user-profiles":                           "V1UserProfiles",
+		"/items/3f2b8c1e-9a4d-4e2b-8c1d-2e3f4a5b6c7d": "Items",
+		"/feed.json":                                  "FeedJSON",
+		"/":                                           "Root",
+	} {
+		assert.Equal(t, expected, captureName(path), path)
+	}
+}
@@ -0,0 +1,100 @@
+package json2go
+
+import (
+	"fmt"
+	"go/ast"
+	"strings"
+)
+
+// flattenChains marks fields of objects of tree with chains of at least minDepth nested objects, which have exactly
+// one key everywhere they appear, like "a" of `{"a": {"b": {"c": 1}}}`, so they are flattened to values at ends of
+// chains. Fields are renamed with names of all keys of chains, like ABC, unless the name is taken by other field.
+func flattenChains(root *node, minDepth uint) {
+	fieldNames := make(map[string]bool)
+	for _, c := range root.children {
+		fieldNames[c.name] = true
+	}
+	for _, c := range root.children {
+		depth := chainDepth(c)
+		if root.t.id() != nodeTypeObject.id() || depth < int(minDepth) {
+			flattenChains(c, minDepth)
+			continue
+		}
+
+		c.chainDepth = depth
+		name, value := c.name, c
+		for i := 0; i < depth; i++ {
+			value = value.children[0]
+			name += value.name
+		}
+		if !fieldNames[name] {
+			fieldNames[name] = true
+			c.name = name
+		}
+		c.logf("chain of %d nested objects is flattened to field %s", depth, c.name)
+		flattenChains(value, minDepth)
+	}
+}
+
+// chainDepth returns number of nested objects with single keys, starting at node, which can be flattened.
+func chainDepth(n *node) int {
+	depth := 0
+	for n.arrayLevel == 0 && !n.root && astIsWrapperNode(n) {
+		depth++
+		n = n.children[0]
+	}
+	return depth
+}
+
+// astTypeFromChainNode returns helper type of value at end of chain of nested objects starting at node, with json
+// methods converting it from/to the objects, see OptFlattenChains.
+func astTypeFromChainNode(n *node, ctx *astContext) ast.Expr {
+	value := n
+	var keys []string
+	for i := 0; i < n.chainDepth; i++ {
+		value = value.children[0]
+		keys = append(keys, value.key)
+	}
+	ctx.addImport("encoding/json")
+	valueType := astTypeFromNode(value, ctx)
+
+	// Helper type is declared once for chain, also if its type is generated again, like by Fields.
+	name := ctx.addNamedSharedHelper("chain:"+n.path, n.name, func(name string) string {
+		// Values of anonymous struct types are converted to plain type with the same fields, like wrappers are.
+		plain, valueExpr := "", astExprString(valueType)
+		if _, ok := valueType.(*ast.StructType); ok {
+			plain, valueExpr = "type plain "+name+"\n\t", "plain"
+		}
+		chain := valueExpr
+		for i := len(keys) - 1; i >= 0; i-- {
+			chain = fmt.Sprintf("struct {\n\t\tValue %s %s\n\t}", chain, astJSONTag(keys[i]).Value)
+		}
+		field := "chain" + strings.Repeat(".Value", len(keys))
+
+		return fmt.Sprintf(`
+// %[1]s is a value at %[2]q keys of json objects nesting it.
+type %[1]s %[3]s
+
+// UnmarshalJSON unmarshals value from json objects nesting it.
+func (v *%[1]s) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	%[4]svar chain %[5]s
+	if err := json.Unmarshal(data, &chain); err != nil {
+		return err
+	}
+	*v = %[1]s(%[6]s)
+	return nil
+}
+
+// MarshalJSON marshals value nested in json objects.
+func (v %[1]s) MarshalJSON() ([]byte, error) {
+	%[4]svar chain %[5]s
+	%[6]s = %[7]s(v)
+	return json.Marshal(chain)
+}
+`, name, strings.Join(keys, "."), astExprString(valueType), plain, chain, field, valueExpr)
+	})
+	return ast.NewIdent(name)
+}
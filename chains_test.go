@@ -0,0 +1,121 @@
+package json2go
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserFlattenChains(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		minDepth uint
+		inputs   []string
+		expected []string
+	}{
+		{
+			name:     "disabled",
+			inputs:   []string{`{"a":{"b":{"c":1}}}`},
+			expected: []string{"A struct{...}"},
+		},
+		{
+			name:     "scalar",
+			minDepth: 2,
+			inputs:   []string{`{"a":{"b":{"c":{"d":1}}}}`},
+			expected: []string{"ABCD ABCD"},
+		},
+		{
+			name:     "too short",
+			minDepth: 3,
+			inputs:   []string{`{"a":{"b":{"c":1}}}`},
+			expected: []string{"A struct{...}"},
+		},
+		{
+			name:     "struct value",
+			minDepth: 2,
+			inputs:   []string{`{"a":{"b":{"c":{"x":1,"y":2}}}}`},
+			expected: []string{"ABC ABC"},
+		},
+		{
+			name:     "taken name",
+			minDepth: 2,
+			inputs:   []string{`{"a":{"b":{"c":1}},"ABC":2}`},
+			expected: []string{"ABC int", "A A"},
+		},
+		{
+			name:     "array of chains",
+			minDepth: 2,
+			inputs:   []string{`{"a":[{"b":{"c":1}}]}`},
+			expected: []string{"A []struct{...}"},
+		},
+		{
+			name:     "in array",
+			minDepth: 2,
+			inputs:   []string{`{"a":[{"b":{"c":{"d":1}}}]}`},
+			expected: []string{"A []struct{...}", "BCD BCD"},
+		},
+		{
+			name:     "nested",
+			minDepth: 2,
+			inputs:   []string{`{"a":{"b":{"c":{"x":{"y":{"z":1}},"n":2}}}}`},
+			expected: []string{"ABC ABC", "N int", "XYZ XYZ"},
+		},
+		{
+			name:     "missing key",
+			minDepth: 2,
+			inputs:   []string{`{"a":{"b":{"c":1}}}`, `{"a":{"b":{}}}`},
+			expected: []string{"A struct{...}", "B struct{...}"},
+		},
+		{
+			name:     "nullable value",
+			minDepth: 2,
+			inputs:   []string{`{"a":{"b":{"c":1}}}`, `{"a":{"b":{"c":null}}}`},
+			expected: []string{"A struct{...}", "B struct{...}"},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			parser := NewJSONParser(baseTypeName, OptFlattenChains(tc.minDepth))
+			for _, input := range tc.inputs {
+				require.NoError(t, parser.FeedBytes([]byte(input)))
+			}
+
+			var fields []string
+			for _, f := range parser.Fields() {
+				if f.Name != "" && len(fields) < len(tc.expected) {
+					fields = append(fields, f.Name+" "+f.Type)
+				}
+			}
+			assert.Equal(t, tc.expected, fields)
+		})
+	}
+}
+
+func TestParserFlattenChainsCode(t *testing.T) {
+	t.Parallel()
+
+	parser := NewJSONParser(baseTypeName, OptFlattenChains(2), OptExtractCommonTypes(true))
+	require.NoError(t, parser.FeedBytes([]byte(`{"id":1,"spec":{"template":{"metadata":{"name":"a","labels":["x"]}}},"status":{"result":{"code":200}}}`)))
+
+	out := runGeneratedCode(t, parser, `
+	var d Document
+	if err := json.NewDecoder(os.Stdin).Decode(&d); err != nil {
+		panic(err)
+	}
+	fmt.Println(d.ID, d.SpecTemplateMetadata.Name, d.SpecTemplateMetadata.Labels, d.StatusResultCode)
+	out, err := json.Marshal(d)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(out))
+`, `{"id":2,"spec":{"template":{"metadata":{"name":"b","labels":["y","z"]}}},"status":{"result":{"code":404}}}`)
+	assert.Equal(t, "2 b [y z] 404\n"+
+		`{"id":2,"spec":{"template":{"metadata":{"labels":["y","z"],"name":"b"}}},"status":{"result":{"code":404}}}`+"\n", out)
+}
@@ -0,0 +1,101 @@
+package json2go
+
+import (
+	"fmt"
+	"go/ast"
+)
+
+// cloudEventAttributes are keys of CloudEvents context attributes defined by specification.
+var cloudEventAttributes = map[string]bool{
+	"specversion":     true,
+	"id":              true,
+	"source":          true,
+	"type":            true,
+	"datacontenttype": true,
+	"dataschema":      true,
+	"subject":         true,
+	"time":            true,
+}
+
+// cloudEventFieldNames are names of embedded CloudEvent field and of its fields promoted to structs of events.
+var cloudEventFieldNames = map[string]bool{
+	"CloudEvent":      true,
+	"SpecVersion":     true,
+	"ID":              true,
+	"Source":          true,
+	"Type":            true,
+	"DataContentType": true,
+	"DataSchema":      true,
+	"Subject":         true,
+	"Time":            true,
+}
+
+// applyCloudEvents recognizes CloudEvents envelopes in json format: root objects, or arrays of batch format,
+// with string "specversion", "id", "source" and "type" attributes. Context attributes of specification are
+// generated as embedded helper type, and data payload gets named type. Other fields with names of embedded or
+// promoted fields are renamed, so selectors aren't ambiguous, warn is called for each of them.
+func applyCloudEvents(root *node, warn func(string)) {
+	if root.t.id() != nodeTypeObject.id() || root.arrayLevel > 1 {
+		return
+	}
+	for _, key := range []string{"specversion", "id", "source", "type"} {
+		c := root.getChild(key)
+		if c == nil || c.t != nodeTypeString || c.arrayLevel > 0 {
+			return
+		}
+	}
+
+	root.logf("values are CloudEvents")
+	root.cloudEvent = true
+	for _, c := range root.children {
+		c.cloudEventAttribute = cloudEventAttributes[c.key]
+		if c.key == "data" && c.t.id() == nodeTypeObject.id() {
+			c.cloudEventData = true
+		}
+	}
+	fieldNames := make(map[string]bool)
+	for _, c := range root.children {
+		if !c.cloudEventAttribute {
+			fieldNames[c.name] = true
+		}
+	}
+	for _, c := range root.children {
+		if c.cloudEventAttribute || !cloudEventFieldNames[c.name] {
+			continue
+		}
+		name := nextName(c.name)
+		for cloudEventFieldNames[name] || fieldNames[name] {
+			name = nextName(name)
+		}
+		fieldNames[name] = true
+		warn(fmt.Sprintf("%s: field name %q conflicts with field promoted from embedded CloudEvent, renamed to %q",
+			c.path, c.name, name))
+		c.name = name
+	}
+}
+
+// astCloudEventType returns name of helper type with context attributes of CloudEvents.
+func astCloudEventType(ctx *astContext) string {
+	ctx.addImport("time")
+	return ctx.addSharedHelper("CloudEvent", func(name string) string {
+		return fmt.Sprintf(`
+// %[1]s has context attributes of CloudEvents envelope.
+type %[1]s struct {
+	SpecVersion     string     `+"`json:\"specversion\"`"+`
+	ID              string     `+"`json:\"id\"`"+`
+	Source          string     `+"`json:\"source\"`"+`
+	Type            string     `+"`json:\"type\"`"+`
+	DataContentType string     `+"`json:\"datacontenttype,omitempty\"`"+`
+	DataSchema      string     `+"`json:\"dataschema,omitempty\"`"+`
+	Subject         string     `+"`json:\"subject,omitempty\"`"+`
+	Time            *time.Time `+"`json:\"time,omitempty\"`"+`
+}
+`, name)
+	})
+}
+
+// astAddCloudEventField adds embedded field of CloudEvents context attributes to struct type of event.
+func astAddCloudEventField(st *ast.StructType, ctx *astContext) {
+	field := &ast.Field{Type: ast.NewIdent(astCloudEventType(ctx))}
+	st.Fields.List = append([]*ast.Field{field}, st.Fields.List...)
+}
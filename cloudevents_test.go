@@ -0,0 +1,110 @@
+package json2go
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const cloudEventTestDocument = `{"specversion":"1.0","type":"com.example.order.created","source":"/orders","id":"A234",` +
+	`"time":"2018-04-05T17:31:00Z","tenant":"acme","data":{"number":123,"total":9.5}}`
+
+func TestParserCloudEvents(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		disabled bool
+		input    string
+		expected string
+	}{
+		{
+			name:     "disabled",
+			disabled: true,
+			input:    cloudEventTestDocument,
+			expected: "\tSpecversion string    `json:\"specversion\"`\n",
+		},
+		{
+			name:  "envelope",
+			input: cloudEventTestDocument,
+			expected: "type Document struct {\n" +
+				"\tCloudEvent\n" +
+				"\tData   DocumentData `json:\"data\"`\n" +
+				"\tTenant string       `json:\"tenant\"`\n" +
+				"}\n" +
+				"type DocumentData struct {\n" +
+				"\tNumber int     `json:\"number\"`\n" +
+				"\tTotal  float64 `json:\"total\"`\n" +
+				"}",
+		},
+		{
+			name:     "batch",
+			input:    "[" + cloudEventTestDocument + "]",
+			expected: "type Document []struct {\n\tCloudEvent\n",
+		},
+		{
+			name:     "missing required attribute",
+			input:    `{"specversion":"1.0","type":"a","id":"1","data":{"a":1}}`,
+			expected: "\tSpecversion string `json:\"specversion\"`\n",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			parser := NewJSONParser(baseTypeName, OptCloudEvents(!tc.disabled))
+			require.NoError(t, parser.FeedBytes([]byte(tc.input)))
+			out, err := parser.Generate()
+			require.NoError(t, err)
+			assert.Contains(t, out, tc.expected)
+		})
+	}
+}
+
+func TestParserCloudEventsCode(t *testing.T) {
+	t.Parallel()
+
+	parser := NewJSONParser(baseTypeName, OptCloudEvents(true))
+	require.NoError(t, parser.FeedBytes([]byte(cloudEventTestDocument)))
+
+	out := runGeneratedCode(t, parser, `
+	var d Document
+	if err := json.NewDecoder(os.Stdin).Decode(&d); err != nil {
+		panic(err)
+	}
+	fmt.Println(d.Type, d.Time.Year(), d.Tenant, d.Data.Number)
+	out, err := json.Marshal(d)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(out))
+`, cloudEventTestDocument)
+	assert.Equal(t, "com.example.order.created 2018 acme 123\n"+
+		`{"specversion":"1.0","id":"A234","source":"/orders","type":"com.example.order.created",`+
+		`"time":"2018-04-05T17:31:00Z","data":{"number":123,"total":9.5},"tenant":"acme"}`+"\n", out)
+}
+
+func TestParserCloudEventsPromotionConflicts(t *testing.T) {
+	t.Parallel()
+
+	parser := NewJSONParser(baseTypeName, OptCloudEvents(true))
+	require.NoError(t, parser.FeedBytes([]byte(`{"specversion":"1.0","type":"a","source":"/b","id":"1",`+
+		`"ID":2,"cloud_event":"c","spec_version":"d","Type2":true,"data":{"a":1}}`)))
+
+	out := runGeneratedCode(t, parser, `
+	var d Document
+	if err := json.NewDecoder(os.Stdin).Decode(&d); err != nil {
+		panic(err)
+	}
+	fmt.Println(d.ID, d.ID2, d.CloudEvent.Type, d.CloudEvent2, d.SpecVersion2, d.Type2)
+	`, `{"specversion":"1.0","type":"a","source":"/b","id":"1","ID":2,"cloud_event":"c","spec_version":"d","Type2":true,"data":{"a":1}}`)
+	assert.Equal(t, "1 2 a c d true\n", out)
+	assert.Equal(t, []string{
+		`$.ID: field name "ID" conflicts with field promoted from embedded CloudEvent, renamed to "ID2"`,
+		`$.cloud_event: field name "CloudEvent" conflicts with field promoted from embedded CloudEvent, renamed to "CloudEvent2"`,
+		`$.spec_version: field name "SpecVersion" conflicts with field promoted from embedded CloudEvent, renamed to "SpecVersion2"`,
+	}, parser.Warnings())
+}
@@ -0,0 +1,5 @@
+package main
+
+// Code of Converter service is generated from proto/json2go.proto into package json2gov1 of this module.
+//go:generate mkdir -p json2gov1
+//go:generate protoc -I ../../proto --go_out=json2gov1 --go_opt=paths=source_relative --go_opt=Mjson2go.proto=github.com/heucoder/json2go/cmd/json2go-grpc/json2gov1 --go-grpc_out=json2gov1 --go-grpc_opt=paths=source_relative --go-grpc_opt=Mjson2go.proto=github.com/heucoder/json2go/cmd/json2go-grpc/json2gov1 json2go.proto
@@ -0,0 +1,11 @@
+module github.com/heucoder/json2go/cmd/json2go-grpc
+
+go 1.13
+
+require (
+	github.com/heucoder/json2go v0.0.0
+	google.golang.org/grpc v1.36.0
+	google.golang.org/protobuf v1.25.0
+)
+
+replace github.com/heucoder/json2go => ../..
@@ -0,0 +1,186 @@
+// Command json2go-grpc serves Converter gRPC service defined in proto/json2go.proto, delegating to json2go.Service.
+//
+// It's a separate module, so json2go module doesn't depend on gRPC. Code of the service is generated with
+// go generate, which needs protoc with protoc-gen-go and protoc-gen-go-grpc plugins:
+//
+//	go generate && go mod tidy && go build
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"os"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/heucoder/json2go"
+	"github.com/heucoder/json2go/cmd/json2go-grpc/json2gov1"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:50051", "Address to listen on")
+	profilesFile := flag.String("profiles", "", "Yaml file with configs by profile name, selected by requests")
+	maxInput := flag.Int64("max-input", 10<<20, "Maximum size in bytes of samples of request, 0 means no limit")
+	maxNodes := flag.Uint("max-nodes", 0, "Maximum number of distinct paths of values in samples of request, 0 means no limit")
+	rate := flag.Float64("rate", 0, "Requests per second allowed for each client address, 0 means no limit")
+	burst := flag.Int("burst", 10, "Requests allowed in bursts of each client address, see -rate")
+	flag.Parse()
+
+	service := &json2go.Service{
+		Opts:         []json2go.JSONParserOpt{json2go.OptMaxNodes(*maxNodes)},
+		MaxInputSize: *maxInput,
+	}
+	if *profilesFile != "" {
+		profiles, err := readProfiles(*profilesFile)
+		if err != nil {
+			log.Fatalf("reading profiles: %v", err)
+		}
+		service.Profiles = profiles
+	}
+	if *rate > 0 {
+		service.RateLimiter = json2go.NewRateLimiter(*rate, *burst)
+	}
+
+	lis, err := net.Listen("tcp", *addr)
+	if err != nil {
+		log.Fatalf("listening: %v", err)
+	}
+	s := grpc.NewServer(grpc.MaxRecvMsgSize(int(*maxInput) + 1<<20))
+	json2gov1.RegisterConverterServer(s, &server{service: service})
+	if err := s.Serve(lis); err != nil {
+		log.Fatalf("serving: %v", err)
+	}
+}
+
+func readProfiles(path string) (map[string]json2go.Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return json2go.ReadProfiles(f)
+}
+
+// server converts messages of Converter service to types of json2go.Service.
+type server struct {
+	json2gov1.UnimplementedConverterServer
+
+	service *json2go.Service
+}
+
+func (s *server) Convert(ctx context.Context, req *json2gov1.ConvertRequest) (*json2gov1.ConvertResponse, error) {
+	config, err := readConfig(req.GetConfig())
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.service.Convert(clientContext(ctx), &json2go.ConvertRequest{
+		Config:  config,
+		Profile: req.GetProfile(),
+		Samples: req.GetSamples(),
+	})
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return convertResponse(resp), nil
+}
+
+func (s *server) ConvertStream(stream json2gov1.Converter_ConvertStreamServer) error {
+	resp, err := s.service.ConvertStream(clientContext(stream.Context()), &chunkReceiver{stream: stream})
+	if err != nil {
+		return statusError(err)
+	}
+	return stream.SendAndClose(convertResponse(resp))
+}
+
+func (s *server) Diff(ctx context.Context, req *json2gov1.DiffRequest) (*json2gov1.DiffResponse, error) {
+	config, err := readConfig(req.GetConfig())
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.service.Diff(clientContext(ctx), &json2go.DiffRequest{
+		Config:      config,
+		Profile:     req.GetProfile(),
+		BaseSamples: req.GetBaseSamples(),
+		Samples:     req.GetSamples(),
+	})
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &json2gov1.DiffResponse{BaseCode: resp.BaseCode, Code: resp.Code, Diff: resp.Diff}, nil
+}
+
+// chunkReceiver receives chunks of uploaded stream, converting messages.
+type chunkReceiver struct {
+	stream json2gov1.Converter_ConvertStreamServer
+}
+
+func (r *chunkReceiver) Recv() (*json2go.ConvertChunk, error) {
+	msg, err := r.stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	chunk := &json2go.ConvertChunk{Profile: msg.GetProfile(), Data: msg.GetData()}
+	if msg.GetConfig() != nil {
+		config, err := readConfig(msg.GetConfig())
+		if err != nil {
+			return nil, err
+		}
+		chunk.Config = &config
+	}
+	return chunk, nil
+}
+
+// readConfig returns config decoded from json of message. Missing config is a default config.
+func readConfig(msg *json2gov1.Config) (json2go.Config, error) {
+	var config json2go.Config
+	if len(msg.GetJson()) == 0 {
+		return config, nil
+	}
+	if err := json.Unmarshal(msg.GetJson(), &config); err != nil {
+		return config, status.Error(codes.InvalidArgument, fmt.Sprintf("invalid config: %v", err))
+	}
+	return config, nil
+}
+
+func convertResponse(resp *json2go.ConvertResponse) *json2gov1.ConvertResponse {
+	return &json2gov1.ConvertResponse{Code: resp.Code, Imports: resp.Imports, Warnings: resp.Warnings}
+}
+
+// clientContext returns context identifying client by its address, for rate limits of service.
+func clientContext(ctx context.Context) context.Context {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ctx
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		host = p.Addr.String()
+	}
+	return json2go.ContextWithClient(ctx, host)
+}
+
+// statusError returns gRPC status error of service error. Errors of chunk receiver are returned as they are.
+func statusError(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	code := codes.InvalidArgument
+	switch {
+	case errors.Is(err, context.Canceled):
+		code = codes.Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		code = codes.DeadlineExceeded
+	case errors.Is(err, json2go.ErrRateLimited), errors.Is(err, json2go.ErrInputTooLarge):
+		code = codes.ResourceExhausted
+	}
+	return status.Error(code, err.Error())
+}
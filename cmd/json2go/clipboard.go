@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os/exec"
+	"runtime"
+)
+
+// clipboardTool is an external command reading or writing system clipboard.
+type clipboardTool struct {
+	name string
+	args []string
+}
+
+// clipboardTools returns commands reading (paste) and writing (copy) clipboard, in order of preference.
+func clipboardTools() (pasteTools, copyTools []clipboardTool) {
+	switch runtime.GOOS {
+	case "darwin":
+		return []clipboardTool{{name: "pbpaste"}}, []clipboardTool{{name: "pbcopy"}}
+	case "windows":
+		return []clipboardTool{{name: "powershell.exe", args: []string{"-NoProfile", "-Command", "Get-Clipboard -Raw"}}},
+			[]clipboardTool{{name: "clip.exe"}}
+	}
+	pasteTools = []clipboardTool{
+		{name: "wl-paste", args: []string{"--no-newline"}},
+		{name: "xclip", args: []string{"-selection", "clipboard", "-out"}},
+		{name: "xsel", args: []string{"--clipboard", "--output"}},
+	}
+	copyTools = []clipboardTool{
+		{name: "wl-copy"},
+		{name: "xclip", args: []string{"-selection", "clipboard", "-in"}},
+		{name: "xsel", args: []string{"--clipboard", "--input"}},
+	}
+	return pasteTools, copyTools
+}
+
+var errNoClipboardTool = errors.New("no clipboard tool found, install wl-clipboard, xclip or xsel")
+
+// readClipboard returns content of system clipboard.
+func readClipboard() ([]byte, error) {
+	pasteTools, _ := clipboardTools()
+	for _, t := range pasteTools {
+		if _, err := exec.LookPath(t.name); err != nil {
+			continue
+		}
+		return exec.Command(t.name, t.args...).Output()
+	}
+	return nil, errNoClipboardTool
+}
+
+// writeClipboard replaces content of system clipboard with data.
+func writeClipboard(data []byte) error {
+	_, copyTools := clipboardTools()
+	for _, t := range copyTools {
+		if _, err := exec.LookPath(t.name); err != nil {
+			continue
+		}
+		cmd := exec.Command(t.name, t.args...)
+		cmd.Stdin = bytes.NewReader(data)
+		return cmd.Run()
+	}
+	return errNoClipboardTool
+}
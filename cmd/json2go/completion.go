@@ -0,0 +1,208 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// flagValues are values of flags with fixed choices, offered by shell completions.
+var flagValues = map[string][]string{
+	"completion":    {"bash", "zsh", "fish"},
+	"diagnostics":   {"json", "sarif"},
+	"empty":         {"default", "struct", "map", "raw", "skip"},
+	"invalid-utf8":  {"replace", "skip", "latin1", "error"},
+	"geojson":       {"none", "structs", "orb"},
+	"hal":           {"none", "typed", "strip"},
+	"ks":            {"none", "camel", "nested"},
+	"lang":          {"kotlin", "java", "csharp", "swift", "dart", "rust", "python", "python-dataclass", "zod", "cue", "pkl"},
+	"ne":            {"pointers", "skip", "wrapper"},
+	"number-locale": {"none", "en", "de", "fr", "ch"},
+	"output":        {"text", "json"},
+	"root-types":    {"auto", "alias", "defined"},
+	"se":            {"inferred", "values", "pointers"},
+	"strict":        {"none", "interfaces", "mixed", "lossless"},
+}
+
+// fileFlags are flags with paths of files, completed by shells with file names.
+var fileFlags = map[string]bool{
+	"append":          true,
+	"choices":         true,
+	"config":          true,
+	"config-out":      true,
+	"desc":            true,
+	"diagnostics-out": true,
+	"header-template": true,
+	"module":          true,
+	"monitor":         true,
+	"names":           true,
+	"packages-dir":    true,
+	"patch":           true,
+	"profile":         true,
+	"protoset":        true,
+	"rpc-profiles":    true,
+	"template":        true,
+	"trace":           true,
+}
+
+// cliFlag is a description of command line flag.
+type cliFlag struct {
+	name     string
+	usage    string
+	defValue string
+	// valueName is a name of value, like "string", empty for bool flags.
+	valueName string
+}
+
+// cliFlags returns descriptions of flags of command line, sorted by name.
+func cliFlags(fs *flag.FlagSet) []cliFlag {
+	var flags []cliFlag
+	fs.VisitAll(func(f *flag.Flag) {
+		valueName, usage := flag.UnquoteUsage(f)
+		if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() {
+			valueName = ""
+		}
+		flags = append(flags, cliFlag{name: f.Name, usage: usage, defValue: f.DefValue, valueName: valueName})
+	})
+	sort.Slice(flags, func(i, j int) bool {
+		return flags[i].name < flags[j].name
+	})
+	return flags
+}
+
+// printCompletion prints completion script of flags for shell: bash, zsh or fish.
+func printCompletion(w io.Writer, fs *flag.FlagSet, shell string) error {
+	flags := cliFlags(fs)
+	switch shell {
+	case "bash":
+		printBashCompletion(w, flags)
+	case "zsh":
+		printZshCompletion(w, flags)
+	case "fish":
+		printFishCompletion(w, flags)
+	default:
+		return fmt.Errorf("unknown shell: %s", shell)
+	}
+	return nil
+}
+
+func printBashCompletion(w io.Writer, flags []cliFlag) {
+	fmt.Fprintln(w, "# bash completion of json2go, generated with json2go -completion bash")
+	fmt.Fprintln(w, "_json2go() {")
+	fmt.Fprintln(w, "\tlocal cur=\"${COMP_WORDS[COMP_CWORD]}\" prev=\"${COMP_WORDS[COMP_CWORD-1]}\"")
+	fmt.Fprintln(w, "\tcase \"$prev\" in")
+	var names, files, others []string
+	for _, f := range flags {
+		names = append(names, "-"+f.name)
+		switch {
+		case f.valueName == "":
+		case len(flagValues[f.name]) > 0:
+			fmt.Fprintf(w, "\t-%s|--%s)\n\t\tCOMPREPLY=($(compgen -W %q -- \"$cur\"))\n\t\treturn\n\t\t;;\n",
+				f.name, f.name, strings.Join(flagValues[f.name], " "))
+		case fileFlags[f.name]:
+			files = append(files, "-"+f.name, "--"+f.name)
+		default:
+			others = append(others, "-"+f.name, "--"+f.name)
+		}
+	}
+	if len(files) > 0 {
+		fmt.Fprintf(w, "\t%s)\n\t\tCOMPREPLY=($(compgen -f -- \"$cur\"))\n\t\treturn\n\t\t;;\n", strings.Join(files, "|"))
+	}
+	if len(others) > 0 {
+		fmt.Fprintf(w, "\t%s)\n\t\tCOMPREPLY=()\n\t\treturn\n\t\t;;\n", strings.Join(others, "|"))
+	}
+	fmt.Fprintln(w, "\tesac")
+	fmt.Fprintf(w, "\tCOMPREPLY=($(compgen -W %q -- \"$cur\"))\n", strings.Join(names, " "))
+	fmt.Fprintln(w, "}")
+	fmt.Fprintln(w, "complete -F _json2go json2go")
+}
+
+func printZshCompletion(w io.Writer, flags []cliFlag) {
+	fmt.Fprintln(w, "#compdef json2go")
+	fmt.Fprintln(w, "# zsh completion of json2go, generated with json2go -completion zsh")
+	fmt.Fprintln(w, "_arguments \\")
+	escape := strings.NewReplacer("'", `'\''`, "[", `\[`, "]", `\]`, ":", `\:`, "\n", " ")
+	for i, f := range flags {
+		spec := "-" + f.name + "[" + escape.Replace(f.usage) + "]"
+		switch {
+		case f.valueName == "":
+		case len(flagValues[f.name]) > 0:
+			spec += ":" + f.valueName + ":(" + strings.Join(flagValues[f.name], " ") + ")"
+		case fileFlags[f.name]:
+			spec += ":" + f.valueName + ":_files"
+		default:
+			spec += ":" + f.valueName + ": "
+		}
+		end := " \\"
+		if i == len(flags)-1 {
+			end = ""
+		}
+		fmt.Fprintf(w, "\t'%s'%s\n", spec, end)
+	}
+}
+
+func printFishCompletion(w io.Writer, flags []cliFlag) {
+	fmt.Fprintln(w, "# fish completion of json2go, generated with json2go -completion fish")
+	escape := strings.NewReplacer(`\`, `\\`, "'", `\'`, "\n", " ")
+	for _, f := range flags {
+		line := fmt.Sprintf("complete -c json2go -o %s -d '%s'", f.name, escape.Replace(f.usage))
+		switch {
+		case f.valueName == "":
+		case len(flagValues[f.name]) > 0:
+			line += fmt.Sprintf(" -x -a '%s'", strings.Join(flagValues[f.name], " "))
+		case fileFlags[f.name]:
+			line += " -r -F"
+		default:
+			line += " -x"
+		}
+		fmt.Fprintln(w, line)
+	}
+}
+
+// printManPage prints man page of json2go with flags, in roff format.
+func printManPage(w io.Writer, fs *flag.FlagSet) {
+	escape := strings.NewReplacer(`\`, `\e`, "-", `\-`)
+	text := func(s string) string {
+		s = escape.Replace(s)
+		if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+			s = `\&` + s
+		}
+		return s
+	}
+
+	fmt.Fprintln(w, `.TH JSON2GO 1 "" "json2go" "User Commands"`)
+	fmt.Fprintln(w, ".SH NAME")
+	fmt.Fprintln(w, `json2go \- generate go types fitting json documents`)
+	fmt.Fprintln(w, ".SH SYNOPSIS")
+	fmt.Fprintln(w, ".B json2go")
+	fmt.Fprintln(w, `[\fIoptions\fR] < \fIdocument.json\fR`)
+	fmt.Fprintln(w, ".SH DESCRIPTION")
+	fmt.Fprintln(w, "json2go reads json document from stdin and prints go types, which unmarshal it without losing information.")
+	fmt.Fprintln(w, "Options select other inputs, like Kafka topics or schema registries, and other outputs, like schemas")
+	fmt.Fprintln(w, "and types of other languages.")
+	fmt.Fprintln(w, ".SH OPTIONS")
+	for _, f := range cliFlags(fs) {
+		fmt.Fprintln(w, ".TP")
+		if f.valueName == "" {
+			fmt.Fprintf(w, ".B \\-%s\n", text(f.name))
+		} else {
+			fmt.Fprintf(w, ".BI \\-%s \" %s\"\n", text(f.name), text(f.valueName))
+		}
+		usage := f.usage
+		if f.defValue != "" && f.defValue != "false" && f.defValue != "0" {
+			usage += fmt.Sprintf(" (default %q)", f.defValue)
+		}
+		fmt.Fprintln(w, text(usage))
+		if values := flagValues[f.name]; len(values) > 0 {
+			fmt.Fprintln(w, ".br")
+			fmt.Fprintln(w, text("Values: "+strings.Join(values, ", ")+"."))
+		}
+	}
+	fmt.Fprintln(w, ".SH EXAMPLES")
+	fmt.Fprintln(w, ".nf")
+	fmt.Fprintln(w, text(`curl -s https://api.github.com/users/octocat | json2go -n User`))
+	fmt.Fprintln(w, text(`json2go -n Event -lang zod < event.json`))
+	fmt.Fprintln(w, ".fi")
+}
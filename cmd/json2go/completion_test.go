@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os/exec"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testFlagSet returns flags covering completion kinds: bool, fixed values, file and other values.
+func testFlagSet() *flag.FlagSet {
+	fs := flag.NewFlagSet("json2go", flag.ContinueOnError)
+	fs.Bool("v", false, "Log 'decisions' to stderr")
+	fs.String("lang", "", "Generate `language` types [beta]: kotlin or java")
+	fs.String("config", "", "Read options from file")
+	fs.String("n", "Document", "Type name")
+	return fs
+}
+
+func TestPrintCompletion(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		shell    string
+		expected string
+	}{
+		{
+			shell: "bash",
+			expected: `# bash completion of json2go, generated with json2go -completion bash
+_json2go() {
+	local cur="${COMP_WORDS[COMP_CWORD]}" prev="${COMP_WORDS[COMP_CWORD-1]}"
+	case "$prev" in
+	-lang|--lang)
+		COMPREPLY=($(compgen -W "kotlin java csharp swift dart rust python python-dataclass zod cue pkl" -- "$cur"))
+		return
+		;;
+	-config|--config)
+		COMPREPLY=($(compgen -f -- "$cur"))
+		return
+		;;
+	-n|--n)
+		COMPREPLY=()
+		return
+		;;
+	esac
+	COMPREPLY=($(compgen -W "-config -lang -n -v" -- "$cur"))
+}
+complete -F _json2go json2go
+`,
+		},
+		{
+			shell: "zsh",
+			expected: `#compdef json2go
+# zsh completion of json2go, generated with json2go -completion zsh
+_arguments \
+	'-config[Read options from file]:string:_files' \
+	'-lang[Generate language types \[beta\]\: kotlin or java]:language:(kotlin java csharp swift dart rust python python-dataclass zod cue pkl)' \
+	'-n[Type name]:string: ' \
+	'-v[Log '\''decisions'\'' to stderr]'
+`,
+		},
+		{
+			shell: "fish",
+			expected: `# fish completion of json2go, generated with json2go -completion fish
+complete -c json2go -o config -d 'Read options from file' -r -F
+complete -c json2go -o lang -d 'Generate language types [beta]: kotlin or java' -x -a 'kotlin java csharp swift dart rust python python-dataclass zod cue pkl'
+complete -c json2go -o n -d 'Type name' -x
+complete -c json2go -o v -d 'Log \'decisions\' to stderr'
+`,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.shell, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			require.NoError(t, printCompletion(&buf, testFlagSet(), tc.shell))
+			assert.Equal(t, tc.expected, buf.String())
+		})
+	}
+
+	assert.EqualError(t, printCompletion(&bytes.Buffer{}, testFlagSet(), "tcsh"), "unknown shell: tcsh")
+}
+
+func TestPrintManPage(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	printManPage(&buf, testFlagSet())
+	out := buf.String()
+	assert.Contains(t, out, ".TH JSON2GO 1 \"\" \"json2go\" \"User Commands\"\n")
+	assert.Contains(t, out, ".TP\n.B \\-v\nLog 'decisions' to stderr\n")
+	assert.Contains(t, out, ".TP\n.BI \\-lang \" language\"\nGenerate language types [beta]: kotlin or java\n"+
+		".br\nValues: kotlin, java, csharp, swift, dart, rust, python, python\\-dataclass, zod, cue, pkl.\n")
+	assert.Contains(t, out, ".TP\n.BI \\-n \" string\"\nType name (default \"Document\")\n")
+	assert.Contains(t, out, ".nf\ncurl \\-s https://api.github.com/users/octocat | json2go \\-n User\n")
+}
+
+func TestCompletionScripts(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash isn't installed")
+	}
+	stdout, stderr, code := runCLI(t, nil, "-completion", "bash")
+	require.Equal(t, 0, code, stderr)
+	out, err := exec.Command("bash", "-n", "-c", stdout).CombinedOutput()
+	assert.NoError(t, err, "%s", out)
+}
+
+// TestCompletionFlags checks that flags with completed values are flags of command, and their values are valid.
+func TestCompletionFlags(t *testing.T) {
+	t.Parallel()
+
+	stdout, stderr, code := runCLI(t, nil, "-completion", "fish")
+	require.Equal(t, 0, code, stderr)
+	kinds := make(map[string]string)
+	for _, m := range regexp.MustCompile(`(?m)^complete -c json2go -o (\S+) -d '(?:[^'\\]|\\.)*'(.*)$`).FindAllStringSubmatch(stdout, -1) {
+		kinds[m[1]] = m[2]
+	}
+	require.NotEmpty(t, kinds)
+
+	for name := range fileFlags {
+		assert.Equal(t, " -r -F", kinds[name], "file flag -%s", name)
+	}
+	for name, values := range flagValues {
+		assert.Contains(t, kinds[name], " -x -a '", "flag -%s", name)
+		for _, v := range values {
+			_, stderr, code := runCLI(t, []byte(`{"id":1}`), "-"+name, v)
+			assert.NotEqual(t, 2, code, "-%s %s: %s", name, v, stderr)
+		}
+		// Values are validated, so passing valid values above isn't accidental.
+		_, _, code := runCLI(t, []byte(`{"id":1}`), "-"+name, "invalid")
+		assert.Equal(t, 2, code, "-%s invalid", name)
+	}
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// binary is a path of command built for tests.
+var binary string
+
+func TestMain(m *testing.M) {
+	dir, err := ioutil.TempDir("", "json2go")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	binary = filepath.Join(dir, "json2go")
+	if out, err := exec.Command("go", "build", "-o", binary, ".").CombinedOutput(); err != nil {
+		fmt.Fprintf(os.Stderr, "building command: %v\n%s", err, out)
+		os.Exit(1)
+	}
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// runCLI runs command with input and arguments, and returns its stdout, stderr and exit code.
+func runCLI(t *testing.T, input []byte, args ...string) (string, string, int) {
+	t.Helper()
+
+	cmd := exec.Command(binary, args...)
+	cmd.Dir = t.TempDir()
+	cmd.Stdin = bytes.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return stdout.String(), stderr.String(), exitErr.ExitCode()
+	}
+	require.NoError(t, err)
+	return stdout.String(), stderr.String(), 0
+}
+
+func TestSamplesEncoding(t *testing.T) {
+	t.Parallel()
+
+	input := append([]byte{0xef, 0xbb, 0xbf}, "{\"id\":1,\"name\":\"Z\xfcrich\"}"...)
+	for _, args := range [][]string{
+		{"-lang", "kotlin"},
+		{"-ir"},
+		{"-fake", "1"},
+		{"-minimize"},
+		{"-elasticsearch"},
+		{"-bigquery"},
+	} {
+		stdout, stderr, code := runCLI(t, input, args...)
+		assert.Equal(t, 0, code, "%v: %s", args, stderr)
+		assert.NotEmpty(t, stdout, args)
+		assert.Contains(t, stderr, "warning: input: invalid UTF-8 bytes replaced with U+FFFD", args)
+	}
+
+	_, stderr, code := runCLI(t, input, "-lang", "kotlin", "-invalid-utf8", "error")
+	assert.Equal(t, 3, code)
+	assert.Contains(t, stderr, "invalid UTF-8 byte 0xfc")
+}
+
+func TestSamplesNonFiniteNumbers(t *testing.T) {
+	t.Parallel()
+
+	input := []byte(`{"a":1.5,"b":NaN} {"a":Infinity,"b":2}`)
+	for _, args := range [][]string{
+		{"-lang", "rust"},
+		{"-ir"},
+		{"-elasticsearch"},
+		{"-minimize"},
+	} {
+		stdout, stderr, code := runCLI(t, input, append([]string{"-non-finite"}, args...)...)
+		assert.Equal(t, 0, code, "%v: %s", args, stderr)
+		assert.NotEmpty(t, stdout, args)
+
+		_, _, code = runCLI(t, input, args...)
+		assert.Equal(t, 3, code, args)
+	}
+}
+
+func TestFloat32Modes(t *testing.T) {
+	t.Parallel()
+
+	input := []byte(`{"a":1.5,"b":3.141592653589793}`)
+	testCases := []struct {
+		args     []string
+		expected []string
+	}{
+		{args: nil, expected: []string{"A float64", "B float64"}},
+		{args: []string{"-f32"}, expected: []string{"A float32", "B float64"}},
+		{args: []string{"-f32=lossless"}, expected: []string{"A float32", "B float64"}},
+		{args: []string{"-f32=always"}, expected: []string{"A float32", "B float32"}},
+		{args: []string{"-f32=false"}, expected: []string{"A float64", "B float64"}},
+	}
+
+	for _, tc := range testCases {
+		stdout, stderr, code := runCLI(t, input, tc.args...)
+		require.Equal(t, 0, code, "%v: %s", tc.args, stderr)
+		for _, field := range tc.expected {
+			assert.Regexp(t, strings.Replace(field, " ", `\s+`, 1), stdout, tc.args)
+		}
+	}
+
+	_, _, code := runCLI(t, input, "-f32=invalid")
+	assert.Equal(t, 2, code)
+}
+
+func TestOutputJSON(t *testing.T) {
+	t.Parallel()
+
+	input := []byte(`{"id":1,"name":"a"}`)
+	testCases := []struct {
+		args     []string
+		expected string
+	}{
+		{args: nil, expected: "type Document struct"},
+		{args: []string{"-lang", "kotlin"}, expected: "data class Document"},
+		{args: []string{"-ir"}, expected: `"name"`},
+		{args: []string{"-minimize"}, expected: `{"id":1,"name":"a"}`},
+		{args: []string{"-completion", "bash"}, expected: "complete"},
+	}
+	for _, tc := range testCases {
+		stdout, stderr, code := runCLI(t, input, append([]string{"-output", "json"}, tc.args...)...)
+		require.Equal(t, 0, code, "%v: %s", tc.args, stderr)
+
+		var r result
+		require.NoError(t, json.Unmarshal([]byte(stdout), &r), tc.args)
+		assert.Equal(t, 0, r.ExitCode, tc.args)
+		assert.Contains(t, r.Code, tc.expected, tc.args)
+	}
+
+	for _, args := range [][]string{{"-rpc"}, {"-monitor", "monitor.json"}} {
+		stdout, _, code := runCLI(t, input, append([]string{"-output", "json"}, args...)...)
+		assert.Equal(t, 2, code, args)
+
+		var r result
+		require.NoError(t, json.Unmarshal([]byte(stdout), &r), args)
+		assert.Equal(t, 2, r.ExitCode, args)
+		assert.Contains(t, r.Error, "-output json can't be used", args)
+	}
+}
+
+func TestLanguageStringFormats(t *testing.T) {
+	t.Parallel()
+
+	input := []byte(`[{"id":"3f2504e0-4f89-11d3-9a0c-0305e82c3301","s":"a"},{"id":"3f2504e0-4f89-11d3-9a0c-0305e82c3302","s":"a"}]`)
+	stdout, stderr, code := runCLI(t, input, "-lang", "zod")
+	require.Equal(t, 0, code, stderr)
+	assert.Contains(t, stdout, "id: z.string().uuid(),")
+	assert.Contains(t, stdout, `s: z.enum(["a"]),`)
+}
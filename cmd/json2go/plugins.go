@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"plugin"
+	"strings"
+
+	"github.com/heucoder/json2go"
+)
+
+// loadPlugins loads plugins from comma separated list of go plugins (.so files), registering json2go plugins
+// in init functions, and commands of plugin processes. Returned plugins are registered plugins and started processes.
+func loadPlugins(list []string) ([]*json2go.Plugin, error) {
+	var processes []*json2go.Plugin
+	for _, p := range list {
+		if strings.HasSuffix(p, ".so") {
+			if _, err := plugin.Open(p); err != nil {
+				closePlugins(processes)
+				return nil, fmt.Errorf("loading go plugin %s: %w", p, err)
+			}
+			continue
+		}
+
+		fields := strings.Fields(p)
+		proc, err := json2go.StartPluginProcess(fields[0], fields[1:]...)
+		if err != nil {
+			closePlugins(processes)
+			return nil, fmt.Errorf("starting plugin %s: %w", p, err)
+		}
+		processes = append(processes, proc)
+	}
+	return append(json2go.Plugins(), processes...), nil
+}
+
+// closePlugins stops plugin processes.
+func closePlugins(plugins []*json2go.Plugin) {
+	for _, p := range plugins {
+		_ = p.Close()
+	}
+}
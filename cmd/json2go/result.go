@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/heucoder/json2go"
+)
+
+// result is a machine-readable result of command, printed to stdout with -output json.
+type result struct {
+	// ExitCode is an exit code of command, see json2go.ExitCode.
+	ExitCode int `json:"exitCode"`
+	// Error is a message of failure.
+	Error string `json:"error,omitempty"`
+	// Code is a generated code, or an output of other modes, like -lang or -ir.
+	Code string `json:"code,omitempty"`
+	// Warnings are warnings of parser.
+	Warnings []string `json:"warnings"`
+	// Stats are statistics of parser, missing for failures before parsing.
+	Stats *json2go.Stats `json:"stats,omitempty"`
+	// CodeStats are statistics of generated code, per file, with -stats.
+	CodeStats []json2go.CodeStats `json:"codeStats,omitempty"`
+	// Diff is a line diff of file changed with -append or -patch.
+	Diff string `json:"diff,omitempty"`
+
+	// out is an output of modes written to stdout.
+	out bytes.Buffer
+}
+
+// jsonResult is a result of command, when it's printed as json.
+var jsonResult *result
+
+// stdout is where output of modes is written, it's collected in jsonResult with -output json.
+var stdout io.Writer = os.Stdout
+
+// collect sets warnings and statistics of parser in result.
+func (r *result) collect(parser *json2go.JSONParser) {
+	r.Warnings = parser.Warnings()
+	stats := parser.Stats()
+	r.Stats = &stats
+}
+
+// print prints result as json to stdout.
+func (r *result) print() {
+	if r.Warnings == nil {
+		r.Warnings = []string{}
+	}
+	if r.Code == "" {
+		r.Code = r.out.String()
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		log.Fatalf("encoding result: %v", err)
+	}
+	os.Stdout.Write(append(data, '\n'))
+}
+
+// usageError is an error of invalid flags.
+type usageError struct {
+	err error
+}
+
+func (e usageError) Error() string {
+	return e.err.Error()
+}
+
+func (e usageError) Unwrap() error {
+	return e.err
+}
+
+// exitCode returns exit code of class of error.
+func exitCode(err error) int {
+	if errors.As(err, &usageError{}) {
+		return json2go.ExitUsage
+	}
+	return json2go.ExitCode(err)
+}
+
+// fatal reports error, as json result with -output json, and exits with exit code of its class.
+func fatal(err error) {
+	code := exitCode(err)
+	if jsonResult != nil {
+		jsonResult.ExitCode = code
+		jsonResult.Error = err.Error()
+		jsonResult.print()
+	} else {
+		log.Print(err)
+	}
+	os.Exit(code)
+}
+
+// fatalf reports error formatted like with fmt.Errorf, see fatal.
+func fatalf(format string, args ...interface{}) {
+	fatal(fmt.Errorf(format, args...))
+}
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/heucoder/json2go"
+	"gopkg.in/yaml.v2"
+)
+
+// choices are user choices made in review, reusable in next runs.
+type choices struct {
+	Names     json2go.NameMapping `yaml:"names,omitempty"`
+	Overrides json2go.Overrides   `yaml:"overrides,omitempty"`
+	// Required, Optional and Nullable are paths of attributes forced to be required, optional or nullable,
+	// see json2go.Config.
+	Required []string `yaml:"required,omitempty"`
+	Optional []string `yaml:"optional,omitempty"`
+	Nullable []string `yaml:"nullable,omitempty"`
+}
+
+// read merges choices from file, if it exists. Choices from file take precedence.
+func (c *choices) read(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var fc choices
+	if err := yaml.Unmarshal(data, &fc); err != nil {
+		return err
+	}
+	for p, name := range fc.Names.Fields {
+		setString(&c.Names.Fields, p, name)
+	}
+	for p, name := range fc.Names.Types {
+		setString(&c.Names.Types, p, name)
+	}
+	for p, kind := range fc.Overrides.Types {
+		setString(&c.Overrides.Types, p, kind)
+	}
+	for p, pointer := range fc.Overrides.Pointers {
+		setPointer(&c.Overrides, p, onOff(pointer))
+	}
+	for _, p := range fc.Overrides.Exclude {
+		c.Overrides.Exclude = append(removeString(c.Overrides.Exclude, p), p)
+	}
+	for _, p := range fc.Required {
+		c.setOptional(p, "off")
+	}
+	for _, p := range fc.Optional {
+		c.setOptional(p, "on")
+	}
+	for _, p := range fc.Nullable {
+		c.setNullable(p, "on")
+	}
+	return nil
+}
+
+// setOptional forces attribute at path to be optional (on), required (off), or removes forced presence (auto).
+func (c *choices) setOptional(path, mode string) error {
+	if mode != "on" && mode != "off" && mode != "auto" {
+		return fmt.Errorf("invalid optional mode %q, use on, off or auto", mode)
+	}
+	c.Required = removeString(c.Required, path)
+	c.Optional = removeString(c.Optional, path)
+	switch mode {
+	case "on":
+		c.Optional = append(c.Optional, path)
+	case "off":
+		c.Required = append(c.Required, path)
+	}
+	return nil
+}
+
+// setNullable forces value at path to be nullable (on), or removes forced nullability (auto).
+func (c *choices) setNullable(path, mode string) error {
+	switch mode {
+	case "on":
+		c.Nullable = append(removeString(c.Nullable, path), path)
+	case "auto":
+		c.Nullable = removeString(c.Nullable, path)
+	default:
+		return fmt.Errorf("invalid nullable mode %q, use on or auto", mode)
+	}
+	return nil
+}
+
+func onOff(v bool) string {
+	if v {
+		return "on"
+	}
+	return "off"
+}
+
+func (c choices) write(path string) error {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+func setString(m *map[string]string, k, v string) {
+	if *m == nil {
+		*m = make(map[string]string)
+	}
+	(*m)[k] = v
+}
+
+const reviewHelp = `Commands:
+  list                   show fields of generated types
+  code                   show generated code
+  rename <path> <name>   rename struct field
+  typename <path> <name> rename type, "$" is the root type
+  type <path> <kind>     force kind: bool, int, float, string, time, any, raw, map, or auto
+  pointer <path> <mode>  force pointer: on, off, or auto
+  optional <path> <mode> force attribute to be optional (on), required (off), or auto
+  nullable <path> <mode> force value to be nullable (on), or auto
+  exclude <path>         leave attribute out of generated types
+  include <path>         undo exclude
+  done                   finish review and generate code
+`
+
+// review lets user adjust inferred types of json values of content in terminal, and returns updated choices.
+// Commands are read from terminal, as stdin may contain input json.
+func review(content []byte, c choices, newParser func(choices) *json2go.JSONParser) (choices, error) {
+	tty, err := openTerminal()
+	if err != nil {
+		return c, err
+	}
+	defer tty.Close()
+
+	out := os.Stderr
+	parse := func() *json2go.JSONParser {
+		p := newParser(c)
+		// Content was already consumed without errors.
+		_ = p.FeedReader(bytes.NewReader(content))
+		return p
+	}
+
+	printFields(out, parse().Fields())
+	fmt.Fprint(out, "\nType \"help\" for commands.\n")
+
+	in := bufio.NewScanner(tty)
+	for {
+		fmt.Fprint(out, "> ")
+		if !in.Scan() {
+			return c, in.Err()
+		}
+
+		args := strings.Fields(in.Text())
+		if len(args) == 0 {
+			continue
+		}
+		cmd, args := args[0], args[1:]
+
+		var cmdErr error
+		switch {
+		case cmd == "done":
+			return c, nil
+		case cmd == "help":
+			fmt.Fprint(out, reviewHelp)
+		case cmd == "list":
+			printFields(out, parse().Fields())
+		case cmd == "code":
+			code, err := parse().Generate()
+			if err != nil {
+				cmdErr = err
+				break
+			}
+			fmt.Fprintf(out, "%s\n", code)
+		case cmd == "rename" && len(args) == 2:
+			setString(&c.Names.Fields, args[0], args[1])
+		case cmd == "typename" && len(args) == 2:
+			setString(&c.Names.Types, args[0], args[1])
+		case cmd == "type" && len(args) == 2:
+			if args[1] == "auto" {
+				delete(c.Overrides.Types, args[0])
+				break
+			}
+			setString(&c.Overrides.Types, args[0], args[1])
+			if _, err := parse().Generate(); err != nil {
+				delete(c.Overrides.Types, args[0])
+				cmdErr = err
+			}
+		case cmd == "pointer" && len(args) == 2:
+			cmdErr = setPointer(&c.Overrides, args[0], args[1])
+		case cmd == "optional" && len(args) == 2:
+			cmdErr = c.setOptional(args[0], args[1])
+		case cmd == "nullable" && len(args) == 2:
+			cmdErr = c.setNullable(args[0], args[1])
+		case cmd == "exclude" && len(args) == 1:
+			c.Overrides.Exclude = append(removeString(c.Overrides.Exclude, args[0]), args[0])
+		case cmd == "include" && len(args) == 1:
+			c.Overrides.Exclude = removeString(c.Overrides.Exclude, args[0])
+		default:
+			cmdErr = fmt.Errorf("invalid command, type \"help\" for commands")
+		}
+		if cmdErr != nil {
+			fmt.Fprintf(out, "error: %v\n", cmdErr)
+		}
+	}
+}
+
+func setPointer(o *json2go.Overrides, path, mode string) error {
+	switch mode {
+	case "auto":
+		delete(o.Pointers, path)
+		return nil
+	case "on", "off":
+		if o.Pointers == nil {
+			o.Pointers = make(map[string]bool)
+		}
+		o.Pointers[path] = mode == "on"
+		return nil
+	}
+	return fmt.Errorf("invalid pointer mode %q, use on, off or auto", mode)
+}
+
+func removeString(list []string, s string) []string {
+	var result []string
+	for _, v := range list {
+		if v != s {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// printFields prints fields grouped by type, indented by depth of path.
+func printFields(w io.Writer, fields []json2go.Field) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	owner := ""
+	for _, f := range fields {
+		if f.Owner != owner {
+			owner = f.Owner
+			fmt.Fprintf(tw, "%s\t\t\t\n", owner)
+		}
+
+		depth := strings.Count(f.Path, ".")
+		name := f.Name
+		if name == "" {
+			name = "(map value)"
+		}
+		var flags []string
+		if !f.Required {
+			flags = append(flags, "optional")
+		}
+		if f.Nullable {
+			flags = append(flags, "nullable")
+		}
+		fmt.Fprintf(tw, "%s%s\t%s\t%s\t%s\n", strings.Repeat("  ", depth), name, f.Type, f.Path, strings.Join(flags, ","))
+	}
+	tw.Flush()
+}
+
+// openTerminal opens controlling terminal for reading.
+func openTerminal() (*os.File, error) {
+	name := "/dev/tty"
+	if runtime.GOOS == "windows" {
+		name = "CONIN$"
+	}
+	return os.Open(name)
+}
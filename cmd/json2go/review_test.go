@@ -0,0 +1,82 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/heucoder/json2go"
+)
+
+func TestChoicesRead(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "choices.yaml")
+	require.NoError(t, ioutil.WriteFile(path, []byte(`names:
+  fields:
+    a: A2
+overrides:
+  types:
+    b: string
+  pointers:
+    c: false
+  exclude: [d]
+required: [e]
+optional: [f]
+nullable: [g]
+`), 0644))
+
+	c := choices{
+		Names: json2go.NameMapping{Fields: map[string]string{"a": "A1", "x": "X"}},
+		Overrides: json2go.Overrides{
+			Types:    map[string]string{"b": "int", "y": "time"},
+			Pointers: map[string]bool{"c": true, "z": true},
+			Exclude:  []string{"d", "w"},
+		},
+		Required: []string{"f"},
+		Optional: []string{"e", "v"},
+		Nullable: []string{"g", "u"},
+	}
+	require.NoError(t, c.read(path))
+
+	assert.Equal(t, map[string]string{"a": "A2", "x": "X"}, c.Names.Fields)
+	assert.Equal(t, map[string]string{"b": "string", "y": "time"}, c.Overrides.Types)
+	assert.Equal(t, map[string]bool{"c": false, "z": true}, c.Overrides.Pointers)
+	assert.Equal(t, []string{"w", "d"}, c.Overrides.Exclude)
+	assert.Equal(t, []string{"e"}, c.Required)
+	assert.Equal(t, []string{"v", "f"}, c.Optional)
+	assert.Equal(t, []string{"u", "g"}, c.Nullable)
+
+	// Missing file keeps choices.
+	require.NoError(t, c.read(filepath.Join(t.TempDir(), "missing.yaml")))
+	assert.Equal(t, []string{"e"}, c.Required)
+}
+
+func TestChoicesSetOptional(t *testing.T) {
+	t.Parallel()
+
+	var c choices
+	require.NoError(t, c.setOptional("a", "on"))
+	require.NoError(t, c.setOptional("b", "off"))
+	assert.Equal(t, []string{"a"}, c.Optional)
+	assert.Equal(t, []string{"b"}, c.Required)
+
+	require.NoError(t, c.setOptional("a", "off"))
+	assert.Empty(t, c.Optional)
+	assert.Equal(t, []string{"b", "a"}, c.Required)
+
+	require.NoError(t, c.setOptional("b", "auto"))
+	assert.Equal(t, []string{"a"}, c.Required)
+	assert.EqualError(t, c.setOptional("a", "yes"), `invalid optional mode "yes", use on, off or auto`)
+	assert.Equal(t, []string{"a"}, c.Required)
+
+	require.NoError(t, c.setNullable("a", "on"))
+	require.NoError(t, c.setNullable("a", "on"))
+	assert.Equal(t, []string{"a"}, c.Nullable)
+	require.NoError(t, c.setNullable("a", "auto"))
+	assert.Empty(t, c.Nullable)
+	assert.EqualError(t, c.setNullable("a", "off"), `invalid nullable mode "off", use on or auto`)
+}
@@ -0,0 +1,150 @@
+package json2go
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"sort"
+	"strconv"
+)
+
+// CodeStats are statistics of generated go code, to track its complexity over time, see AnalyzeCode.
+// Fields of anonymous structs are counted as fields of types declaring them.
+type CodeStats struct {
+	// File is a name of file, like "billing/types.go", empty for code returned by Generate.
+	File string `json:"file,omitempty"`
+	// Lines is a number of lines of code.
+	Lines int `json:"lines"`
+	// Imports are sorted import paths of code.
+	Imports []string `json:"imports"`
+	// Types are statistics of declared types, in order of code.
+	Types []TypeStats `json:"types"`
+	// Fields is a number of struct fields of all types.
+	Fields int `json:"fields"`
+	// PointerFields is a number of struct fields of pointer types.
+	PointerFields int `json:"pointerFields"`
+	// InterfaceFields is a number of struct fields with interface{} values, like []interface{}.
+	InterfaceFields int `json:"interfaceFields"`
+}
+
+// TypeStats are statistics of declared type.
+type TypeStats struct {
+	Name            string `json:"name"`
+	Lines           int    `json:"lines"`
+	Fields          int    `json:"fields"`
+	PointerFields   int    `json:"pointerFields"`
+	InterfaceFields int    `json:"interfaceFields"`
+}
+
+func (s CodeStats) String() string {
+	return fmt.Sprintf("%d types, %d fields (%d pointers, %d interface{}), %d lines, %d imports",
+		len(s.Types), s.Fields, s.PointerFields, s.InterfaceFields, s.Lines, len(s.Imports))
+}
+
+func (s TypeStats) String() string {
+	return fmt.Sprintf("%s: %d fields (%d pointers, %d interface{}), %d lines",
+		s.Name, s.Fields, s.PointerFields, s.InterfaceFields, s.Lines)
+}
+
+// AnalyzeCode returns statistics of go code of file with name, or of declarations without package clause, like
+// code returned by Generate. Types declared in function bodies aren't counted.
+func AnalyzeCode(name string, src []byte) (CodeStats, error) {
+	fset := token.NewFileSet()
+	f, err := goparser.ParseFile(fset, name, src, 0)
+	if err != nil {
+		// Declarations are parsed with package clause prepended to their first line, so lines don't change.
+		var declsErr error
+		if f, declsErr = goparser.ParseFile(fset, name, append([]byte("package generated;"), src...), 0); declsErr != nil {
+			return CodeStats{}, fmt.Errorf("parsing go code: %w", err)
+		}
+	}
+
+	s := CodeStats{File: name, Lines: bytes.Count(src, []byte("\n")), Imports: []string{}, Types: []TypeStats{}}
+	if len(src) > 0 && src[len(src)-1] != '\n' {
+		s.Lines++
+	}
+	for _, imp := range f.Imports {
+		if path, err := strconv.Unquote(imp.Path.Value); err == nil {
+			s.Imports = append(s.Imports, path)
+		}
+	}
+	sort.Strings(s.Imports)
+
+	for _, decl := range f.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			ts := spec.(*ast.TypeSpec)
+			t := TypeStats{
+				Name:  ts.Name.Name,
+				Lines: fset.Position(ts.End()).Line - fset.Position(ts.Pos()).Line + 1,
+			}
+			if st, ok := ts.Type.(*ast.StructType); ok {
+				t.countFields(st)
+			} else {
+				ast.Inspect(ts.Type, func(n ast.Node) bool {
+					if st, ok := n.(*ast.StructType); ok {
+						t.countFields(st)
+						return false
+					}
+					return true
+				})
+			}
+			s.Types = append(s.Types, t)
+			s.Fields += t.Fields
+			s.PointerFields += t.PointerFields
+			s.InterfaceFields += t.InterfaceFields
+		}
+	}
+	return s, nil
+}
+
+// countFields counts fields of struct, and of anonymous structs of their types.
+func (t *TypeStats) countFields(st *ast.StructType) {
+	for _, f := range st.Fields.List {
+		n := len(f.Names)
+		if n == 0 {
+			// Embedded field.
+			n = 1
+		}
+		t.Fields += n
+		if _, ok := f.Type.(*ast.StarExpr); ok {
+			t.PointerFields += n
+		}
+		hasInterface := false
+		ast.Inspect(f.Type, func(node ast.Node) bool {
+			switch e := node.(type) {
+			case *ast.StructType:
+				t.countFields(e)
+				return false
+			case *ast.InterfaceType:
+				hasInterface = true
+			}
+			return true
+		})
+		if hasInterface {
+			t.InterfaceFields += n
+		}
+	}
+}
+
+// CodeStats returns statistics of generated code, see AnalyzeCode. Imports of generated code are included,
+// also if they aren't part of code.
+func (p *JSONParser) CodeStats() (CodeStats, error) {
+	out, err := p.Generate()
+	if err != nil {
+		return CodeStats{}, err
+	}
+	s, err := AnalyzeCode("", []byte(out))
+	if err != nil {
+		return CodeStats{}, fmt.Errorf("%w: %v", ErrInternal, err)
+	}
+	if len(s.Imports) == 0 {
+		s.Imports = append(s.Imports, p.Imports()...)
+	}
+	return s, nil
+}
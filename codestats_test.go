@@ -0,0 +1,121 @@
+package json2go
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzeCode(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		file     string
+		src      string
+		expected CodeStats
+	}{
+		{
+			name: "file",
+			file: "types.go",
+			src: `package billing
+
+import (
+	"time"
+	"encoding/json"
+)
+
+type Invoice struct {
+	ID  int       ` + "`json:\"id\"`" + `
+	At  time.Time ` + "`json:\"at\"`" + `
+	Raw json.RawMessage
+}
+`,
+			expected: CodeStats{
+				File:    "types.go",
+				Lines:   12,
+				Imports: []string{"encoding/json", "time"},
+				Types:   []TypeStats{{Name: "Invoice", Lines: 5, Fields: 3}},
+				Fields:  3,
+			},
+		},
+		{
+			name: "declarations",
+			src: `type Document struct {
+	Name *string
+	Tags []interface{}
+	User struct {
+		ID    int
+		Extra map[string]interface{}
+		Next  *Document
+	}
+	Embedded
+}
+
+type Embedded struct {
+	A, B int
+}
+
+type IDs []int`,
+			expected: CodeStats{
+				Lines:   16,
+				Imports: []string{},
+				Types: []TypeStats{
+					{Name: "Document", Lines: 10, Fields: 7, PointerFields: 2, InterfaceFields: 2},
+					{Name: "Embedded", Lines: 3, Fields: 2},
+					{Name: "IDs", Lines: 1},
+				},
+				Fields:          9,
+				PointerFields:   2,
+				InterfaceFields: 2,
+			},
+		},
+		{
+			name: "slice of structs",
+			src: `type Items []struct {
+	ID int
+}
+`,
+			expected: CodeStats{
+				Lines:   3,
+				Imports: []string{},
+				Types:   []TypeStats{{Name: "Items", Lines: 3, Fields: 1}},
+				Fields:  1,
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			s, err := AnalyzeCode(tc.file, []byte(tc.src))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, s)
+		})
+	}
+}
+
+func TestAnalyzeCodeInvalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := AnalyzeCode("", []byte("type Document struct {"))
+	require.Error(t, err)
+}
+
+func TestParserCodeStats(t *testing.T) {
+	t.Parallel()
+
+	parser := NewJSONParser(baseTypeName, OptExtractCommonTypes(true))
+	require.NoError(t, parser.FeedBytes([]byte(`{"at": "2021-01-02T03:04:05Z", "user": {"name": "a"}, "data": [1, "x"]}`)))
+	require.NoError(t, parser.FeedBytes([]byte(`{"at": "2021-01-02T03:04:05Z", "data": []}`)))
+
+	s, err := parser.CodeStats()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"time"}, s.Imports)
+	assert.Equal(t, "1 types, 4 fields (1 pointers, 1 interface{}), 7 lines, 1 imports", s.String())
+	require.Len(t, s.Types, 1)
+	assert.Equal(t, "Document: 4 fields (1 pointers, 1 interface{}), 7 lines", s.Types[0].String())
+}
@@ -0,0 +1,91 @@
+package json2go
+
+import (
+	"fmt"
+	"go/ast"
+	"strings"
+)
+
+// commentedFieldName is a name of placeholder fields, replaced by commented out fields in printed code.
+const commentedFieldName = "json2goCommentedField"
+
+// kindNames are names of json value kinds, in order of kind bits.
+var kindNames = []string{"bool", "number", "string", "object", "array"}
+
+// commentOutFields moves attributes of objects present in less than minPresence of objects,
+// or with values of different json kinds if unstable is true, to commented fields of their parents.
+// Forced attributes are never commented out.
+func commentOutFields(n *node, minPresence float64, unstable bool, forced map[string]bool) {
+	if n.t.id() == nodeTypeObject.id() {
+		var children []*node
+		for _, c := range n.children {
+			if c.lowConfidence = lowConfidenceReason(n, c, minPresence, unstable); c.lowConfidence != "" && !forced[c.path] {
+				n.commented = append(n.commented, c)
+				continue
+			}
+			c.lowConfidence = ""
+			children = append(children, c)
+		}
+		n.children = children
+	}
+
+	for _, c := range n.children {
+		commentOutFields(c, minPresence, unstable, forced)
+	}
+}
+
+func lowConfidenceReason(parent, n *node, minPresence float64, unstable bool) string {
+	if presence, _ := n.presence(parent); presence < minPresence {
+		return n.presenceDesc(parent, "objects")
+	}
+	if unstable && n.t.id() != nodeTypeRawMessage.id() && n.kindsCount() > 1 {
+		return "values of different kinds: " + strings.Join(n.kindNames(), ", ")
+	}
+	return ""
+}
+
+// kindNames returns names of json value kinds seen by node.
+func (n *node) kindNames() []string {
+	var kinds []string
+	for i, name := range kindNames {
+		if n.seenKinds&(1<<i) != 0 {
+			kinds = append(kinds, name)
+		}
+	}
+	return kinds
+}
+
+// astAddCommentedFields registers fields of struct, which are commented out. Fields are created in separate context,
+// so types used only by them aren't imported. Placeholders are added to struct by astInsertCommentedFields,
+// after methods of struct are generated.
+func astAddCommentedFields(n *node, st *ast.StructType, ctx *astContext) {
+	if len(n.commented) == 0 {
+		return
+	}
+
+	fieldCtx := newASTContext(nil, ctx.opts)
+	var comments []*ast.Comment
+	for _, c := range n.commented {
+		field := astFieldFromNode(c, fieldCtx)
+		field.Doc = nil
+
+		lines := strings.Split(astExprString(&ast.StructType{Fields: &ast.FieldList{List: []*ast.Field{field}}}), "\n")
+		comments = append(comments, &ast.Comment{Text: fmt.Sprintf("// %s: %s, uncomment to use it.", c.name, c.lowConfidence)})
+		// Lines between braces of printed struct are the field.
+		for _, line := range lines[1 : len(lines)-1] {
+			comments = append(comments, &ast.Comment{Text: "// " + strings.TrimPrefix(line, "\t")})
+		}
+	}
+	ctx.commentedFields[st] = &ast.CommentGroup{List: comments}
+}
+
+// astInsertCommentedFields adds placeholder fields documented with commented out fields to structs.
+func astInsertCommentedFields(ctx *astContext) {
+	for st, doc := range ctx.commentedFields {
+		st.Fields.List = append(st.Fields.List, &ast.Field{
+			Doc:   doc,
+			Names: []*ast.Ident{ast.NewIdent(commentedFieldName)},
+			Type:  ast.NewIdent("struct{}"),
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package json2go
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserCommentOutFields(t *testing.T) {
+	t.Parallel()
+
+	input := `[
+		{"id":1,"v":1,"n":{"a":1}},
+		{"id":2,"v":"x","n":{"a":1,"t":"2020-01-01T00:00:00Z"}},
+		{"id":3,"v":1,"rare":{"x":1,"y":[1]},"n":{"a":1}}
+	]`
+
+	testCases := []struct {
+		name     string
+		opts     []JSONParserOpt
+		expected string
+	}{
+		{
+			name: "rare and unstable",
+			opts: []JSONParserOpt{OptCommentOutFields(0.5, true)},
+			expected: "type Document []struct {\n" +
+				"\tID int `json:\"id\"`\n" +
+				"\tN  struct {\n" +
+				"\t\tA int `json:\"a\"`\n" +
+				"\t\t// T: present in 1 of 3 objects, uncomment to use it.\n" +
+				"\t\t// T *time.Time `json:\"t,omitempty\"`\n" +
+				"\t} `json:\"n\"`\n" +
+				"\t// Rare: present in 1 of 3 objects, uncomment to use it.\n" +
+				"\t// Rare *struct {\n" +
+				"\t// \tX int   `json:\"x\"`\n" +
+				"\t// \tY []int `json:\"y\"`\n" +
+				"\t// } `json:\"rare,omitempty\"`\n" +
+				"\t// V: values of different kinds: number, string, uncomment to use it.\n" +
+				"\t// V interface{} `json:\"v\"`\n" +
+				"}",
+		},
+		{
+			name: "rare only",
+			opts: []JSONParserOpt{OptCommentOutFields(0.5, false), OptForceRequired("$.rare")},
+			expected: "type Document []struct {\n" +
+				"\tID int `json:\"id\"`\n" +
+				"\tN  struct {\n" +
+				"\t\tA int `json:\"a\"`\n" +
+				"\t\t// T: present in 1 of 3 objects, uncomment to use it.\n" +
+				"\t\t// T *time.Time `json:\"t,omitempty\"`\n" +
+				"\t} `json:\"n\"`\n" +
+				"\tRare struct {\n" +
+				"\t\tX int   `json:\"x\"`\n" +
+				"\t\tY []int `json:\"y\"`\n" +
+				"\t} `json:\"rare\"`\n" +
+				"\tV interface{} `json:\"v\"`\n" +
+				"}",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := NewJSONParser("Document", tc.opts...)
+			require.NoError(t, p.FeedBytes([]byte(input)))
+			out, err := p.Generate()
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, out)
+			// Types of commented out fields aren't imported.
+			assert.Empty(t, p.Imports())
+		})
+	}
+}
+
+func TestParserCommentOutFieldsWithMethods(t *testing.T) {
+	t.Parallel()
+
+	input := `[{"id":1},{"id":2},{"id":3,"rare":true}]`
+	p := NewJSONParser("Document", OptCommentOutFields(0.5, false), OptEasyJSON(true), OptPresenceTracking(true))
+	require.NoError(t, p.FeedBytes([]byte(input)))
+
+	out := runGeneratedCode(t, p, `
+	var doc Document
+	if err := json.NewDecoder(os.Stdin).Decode(&doc); err != nil {
+		panic(err)
+	}
+	fmt.Print(len(doc), doc[2].HasID())
+`, input)
+	assert.Equal(t, "3 true", out)
+}
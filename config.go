@@ -0,0 +1,244 @@
+package json2go
+
+import "sort"
+
+// Config is a serializable set of parser options, for callers passing options as data, like remote clients.
+// Zero value means default options. Options without fields, like OptLogger, are set with JSONParserOpt.
+type Config struct {
+	// Version is a version of json2go module, which resolved config, see Resolve. It doesn't change options.
+	Version                      string            `json:"version,omitempty" yaml:"version,omitempty"`
+	RootName                     string            `json:"rootName,omitempty" yaml:"rootName,omitempty"`
+	ExtractCommonTypes           bool              `json:"extractCommonTypes,omitempty" yaml:"extractCommonTypes,omitempty"`
+	StringPointersWhenKeyMissing bool              `json:"stringPointersWhenKeyMissing,omitempty" yaml:"stringPointersWhenKeyMissing,omitempty"`
+	SkipEmptyKeys                bool              `json:"skipEmptyKeys,omitempty" yaml:"skipEmptyKeys,omitempty"`
+	MakeMaps                     bool              `json:"makeMaps,omitempty" yaml:"makeMaps,omitempty"`
+	MakeMapsMinAttributes        uint              `json:"makeMapsMinAttributes,omitempty" yaml:"makeMapsMinAttributes,omitempty"`
+	MakeMapsMaxDepth             uint              `json:"makeMapsMaxDepth,omitempty" yaml:"makeMapsMaxDepth,omitempty"`
+	MapKeyTypes                  bool              `json:"mapKeyTypes,omitempty" yaml:"mapKeyTypes,omitempty"`
+	Tuples                       bool              `json:"tuples,omitempty" yaml:"tuples,omitempty"`
+	CollapseWrappers             bool              `json:"collapseWrappers,omitempty" yaml:"collapseWrappers,omitempty"`
+	FlattenChains                uint              `json:"flattenChains,omitempty" yaml:"flattenChains,omitempty"`
+	Float32                      bool              `json:"float32,omitempty" yaml:"float32,omitempty"`
+	Float32Always                bool              `json:"float32Always,omitempty" yaml:"float32Always,omitempty"`
+	NonFiniteNumbers             bool              `json:"nonFiniteNumbers,omitempty" yaml:"nonFiniteNumbers,omitempty"`
+	Decimal                      bool              `json:"decimal,omitempty" yaml:"decimal,omitempty"`
+	CoerceBooleanStrings         bool              `json:"coerceBooleanStrings,omitempty" yaml:"coerceBooleanStrings,omitempty"`
+	NumberLocale                 string            `json:"numberLocale,omitempty" yaml:"numberLocale,omitempty"`
+	EpochUnits                   []string          `json:"epochUnits,omitempty" yaml:"epochUnits,omitempty"`
+	OptionalType                 bool              `json:"optionalType,omitempty" yaml:"optionalType,omitempty"`
+	PresenceTracking             bool              `json:"presenceTracking,omitempty" yaml:"presenceTracking,omitempty"`
+	UnknownFields                bool              `json:"unknownFields,omitempty" yaml:"unknownFields,omitempty"`
+	CommentMinPresence           float64           `json:"commentMinPresence,omitempty" yaml:"commentMinPresence,omitempty"`
+	CommentUnstable              bool              `json:"commentUnstable,omitempty" yaml:"commentUnstable,omitempty"`
+	TimeAsString                 bool              `json:"timeAsString,omitempty" yaml:"timeAsString,omitempty"`
+	ExpandJSONStrings            bool              `json:"expandJSONStrings,omitempty" yaml:"expandJSONStrings,omitempty"`
+	ExpandQueryStrings           bool              `json:"expandQueryStrings,omitempty" yaml:"expandQueryStrings,omitempty"`
+	ExpandBase64JSON             bool              `json:"expandBase64JSON,omitempty" yaml:"expandBase64JSON,omitempty"`
+	StringFormats                bool              `json:"stringFormats,omitempty" yaml:"stringFormats,omitempty"`
+	ProtoJSON                    bool              `json:"protoJSON,omitempty" yaml:"protoJSON,omitempty"`
+	GeoJSON                      string            `json:"geoJSON,omitempty" yaml:"geoJSON,omitempty"`
+	JSONAPI                      bool              `json:"jsonAPI,omitempty" yaml:"jsonAPI,omitempty"`
+	HAL                          string            `json:"hal,omitempty" yaml:"hal,omitempty"`
+	CloudEvents                  bool              `json:"cloudEvents,omitempty" yaml:"cloudEvents,omitempty"`
+	StructuredLogs               float64           `json:"structuredLogs,omitempty" yaml:"structuredLogs,omitempty"`
+	RecencyHalfLife              uint              `json:"recencyHalfLife,omitempty" yaml:"recencyHalfLife,omitempty"`
+	MaxGeneratedFields           uint              `json:"maxGeneratedFields,omitempty" yaml:"maxGeneratedFields,omitempty"`
+	TimeFormats                  map[string]string `json:"timeFormats,omitempty" yaml:"timeFormats,omitempty"`
+	RawMessagePaths              []string          `json:"rawMessagePaths,omitempty" yaml:"rawMessagePaths,omitempty"`
+	MapPaths                     []string          `json:"mapPaths,omitempty" yaml:"mapPaths,omitempty"`
+	StructPaths                  []string          `json:"structPaths,omitempty" yaml:"structPaths,omitempty"`
+	RawMessageMinKinds           uint              `json:"rawMessageMinKinds,omitempty" yaml:"rawMessageMinKinds,omitempty"`
+	FieldOrderOriginal           bool              `json:"fieldOrderOriginal,omitempty" yaml:"fieldOrderOriginal,omitempty"`
+	TypeOrderReferencedBefore    bool              `json:"typeOrderReferencedBefore,omitempty" yaml:"typeOrderReferencedBefore,omitempty"`
+	SampleLimit                  uint              `json:"sampleLimit,omitempty" yaml:"sampleLimit,omitempty"`
+	SampleRandom                 bool              `json:"sampleRandom,omitempty" yaml:"sampleRandom,omitempty"`
+	OutlierTolerance             float64           `json:"outlierTolerance,omitempty" yaml:"outlierTolerance,omitempty"`
+	ExampleMaxLength             uint              `json:"exampleMaxLength,omitempty" yaml:"exampleMaxLength,omitempty"`
+	ExampleMaxSize               uint              `json:"exampleMaxSize,omitempty" yaml:"exampleMaxSize,omitempty"`
+	Widening                     string            `json:"widening,omitempty" yaml:"widening,omitempty"`
+	Anonymize                    bool              `json:"anonymize,omitempty" yaml:"anonymize,omitempty"`
+	AnonymizeSeed                int64             `json:"anonymizeSeed,omitempty" yaml:"anonymizeSeed,omitempty"`
+	PII                          bool              `json:"pii,omitempty" yaml:"pii,omitempty"`
+	PIITag                       string            `json:"piiTag,omitempty" yaml:"piiTag,omitempty"`
+	KeySplitting                 string            `json:"keySplitting,omitempty" yaml:"keySplitting,omitempty"`
+	RootTypes                    string            `json:"rootTypes,omitempty" yaml:"rootTypes,omitempty"`
+	RootElementType              bool              `json:"rootElementType,omitempty" yaml:"rootElementType,omitempty"`
+	RootElementTypeName          string            `json:"rootElementTypeName,omitempty" yaml:"rootElementTypeName,omitempty"`
+	SliceElements                string            `json:"sliceElements,omitempty" yaml:"sliceElements,omitempty"`
+	NullElements                 string            `json:"nullElements,omitempty" yaml:"nullElements,omitempty"`
+	EmptyValues                  string            `json:"emptyValues,omitempty" yaml:"emptyValues,omitempty"`
+	InvalidUTF8                  string            `json:"invalidUTF8,omitempty" yaml:"invalidUTF8,omitempty"`
+	MaxDepth                     uint              `json:"maxDepth,omitempty" yaml:"maxDepth,omitempty"`
+	TagTemplate                  string            `json:"tagTemplate,omitempty" yaml:"tagTemplate,omitempty"`
+	OutputTemplate               string            `json:"outputTemplate,omitempty" yaml:"outputTemplate,omitempty"`
+	Header                       bool              `json:"header,omitempty" yaml:"header,omitempty"`
+	HeaderTemplate               string            `json:"headerTemplate,omitempty" yaml:"headerTemplate,omitempty"`
+	HeaderTimestamp              bool              `json:"headerTimestamp,omitempty" yaml:"headerTimestamp,omitempty"`
+	HeaderConfig                 bool              `json:"headerConfig,omitempty" yaml:"headerConfig,omitempty"`
+	Descriptions                 map[string]string `json:"descriptions,omitempty" yaml:"descriptions,omitempty"`
+	StringMethods                bool              `json:"stringMethods,omitempty" yaml:"stringMethods,omitempty"`
+	RedactedType                 bool              `json:"redactedType,omitempty" yaml:"redactedType,omitempty"`
+	RedactPatterns               []string          `json:"redactPatterns,omitempty" yaml:"redactPatterns,omitempty"`
+	JSONv2                       bool              `json:"jsonV2,omitempty" yaml:"jsonV2,omitempty"`
+	JSONv2CaseInsensitive        bool              `json:"jsonV2CaseInsensitive,omitempty" yaml:"jsonV2CaseInsensitive,omitempty"`
+	EasyJSON                     bool              `json:"easyJSON,omitempty" yaml:"easyJSON,omitempty"`
+	FastDecoders                 bool              `json:"fastDecoders,omitempty" yaml:"fastDecoders,omitempty"`
+	Required                     []string          `json:"required,omitempty" yaml:"required,omitempty"`
+	Optional                     []string          `json:"optional,omitempty" yaml:"optional,omitempty"`
+	Nullable                     []string          `json:"nullable,omitempty" yaml:"nullable,omitempty"`
+	Names                        NameMapping       `json:"names,omitempty" yaml:"names,omitempty"`
+	Overrides                    Overrides         `json:"overrides,omitempty" yaml:"overrides,omitempty"`
+	GoModule                     string            `json:"goModule,omitempty" yaml:"goModule,omitempty"`
+	TypeCheck                    bool              `json:"typeCheck,omitempty" yaml:"typeCheck,omitempty"`
+	VerifyDecoding               bool              `json:"verifyDecoding,omitempty" yaml:"verifyDecoding,omitempty"`
+	VerifyTags                   bool              `json:"verifyTags,omitempty" yaml:"verifyTags,omitempty"`
+	Strictness                   string            `json:"strictness,omitempty" yaml:"strictness,omitempty"`
+}
+
+// defaultRootName is a name of root type, when Config doesn't set it.
+const defaultRootName = "Document"
+
+// Opts returns parser options set by config.
+func (c Config) Opts() []JSONParserOpt {
+	opts := []JSONParserOpt{
+		OptExtractCommonTypes(c.ExtractCommonTypes),
+		OptStringPointersWhenKeyMissing(c.StringPointersWhenKeyMissing),
+		OptSkipEmptyKeys(c.SkipEmptyKeys),
+		OptMakeMaps(c.MakeMaps, c.MakeMapsMinAttributes),
+		OptMakeMapsMaxDepth(c.MakeMapsMaxDepth),
+		OptMapKeyTypes(c.MapKeyTypes),
+		OptTuples(c.Tuples),
+		OptCollapseWrappers(c.CollapseWrappers),
+		OptFlattenChains(c.FlattenChains),
+		OptFloat32(c.Float32 || c.Float32Always, !c.Float32Always),
+		OptDecimal(c.Decimal),
+		OptNonFiniteNumbers(c.NonFiniteNumbers),
+		OptCoerceBooleanStrings(c.CoerceBooleanStrings),
+		OptOptionalType(c.OptionalType),
+		OptPresenceTracking(c.PresenceTracking),
+		OptUnknownFields(c.UnknownFields),
+		OptCommentOutFields(c.CommentMinPresence, c.CommentUnstable),
+		OptTimeAsString(c.TimeAsString),
+		OptExpandJSONStrings(c.ExpandJSONStrings),
+		OptExpandQueryStrings(c.ExpandQueryStrings),
+		OptExpandBase64JSON(c.ExpandBase64JSON),
+		OptStringFormats(c.StringFormats),
+		OptProtoJSON(c.ProtoJSON),
+		OptJSONAPI(c.JSONAPI),
+		OptCloudEvents(c.CloudEvents),
+		OptStructuredLogs(c.StructuredLogs),
+		OptRecencyDecay(c.RecencyHalfLife),
+		OptMaxGeneratedFields(c.MaxGeneratedFields),
+		OptRawMessageForUnstable(c.RawMessageMinKinds > 0, c.RawMessageMinKinds),
+		OptMaxDepth(c.MaxDepth),
+		OptAnonymize(c.Anonymize, c.AnonymizeSeed),
+		OptPII(c.PII, c.PIITag),
+		OptTagTemplate(c.TagTemplate),
+		OptOutputTemplate(c.OutputTemplate),
+		OptHeader(c.Header, c.HeaderTemplate),
+		OptHeaderTimestamp(c.HeaderTimestamp),
+		OptHeaderConfig(c.HeaderConfig, c),
+		OptDescriptions(c.Descriptions),
+		OptStringMethods(c.StringMethods, c.RedactPatterns...),
+		OptRedactedType(c.RedactedType, c.RedactPatterns...),
+		OptJSONv2(c.JSONv2, c.JSONv2CaseInsensitive),
+		OptEasyJSON(c.EasyJSON),
+		OptFastDecoders(c.FastDecoders),
+		OptOverrides(c.Overrides),
+		OptTypeCheck(c.TypeCheck),
+		OptVerifyDecoding(c.VerifyDecoding),
+		OptVerifyTags(c.VerifyTags),
+		OptForceRequired(c.Required...),
+		OptForceOptional(c.Optional...),
+		OptForceNullable(c.Nullable...),
+		OptOutlierTolerance(c.OutlierTolerance),
+		OptExampleLimits(c.ExampleMaxLength, c.ExampleMaxSize),
+		OptRootElementType(c.RootElementType, c.RootElementTypeName),
+	}
+	if c.SampleRandom {
+		opts = append(opts, OptReservoirSample(c.SampleLimit))
+	} else {
+		opts = append(opts, OptSampleLimit(c.SampleLimit))
+	}
+	if lattice, err := ParseWideningLattice(c.Widening); err == nil {
+		opts = append(opts, OptWidening(lattice))
+	}
+	if policy, err := ParseKeySplitting(c.KeySplitting); err == nil {
+		opts = append(opts, OptKeySplitting(policy, ""))
+	}
+	if repr, err := ParseGeoJSON(c.GeoJSON); err == nil {
+		opts = append(opts, OptGeoJSON(repr))
+	}
+	if handling, err := ParseHAL(c.HAL); err == nil {
+		opts = append(opts, OptHAL(handling))
+	}
+	if kind, err := ParseRootTypes(c.RootTypes); err == nil {
+		opts = append(opts, OptRootTypes(kind))
+	}
+	if policy, err := ParseSliceElements(c.SliceElements); err == nil {
+		opts = append(opts, OptSliceElements(policy))
+	}
+	if repr, err := ParseNullElements(c.NullElements); err == nil {
+		opts = append(opts, OptNullElements(repr))
+	}
+	if policy, err := ParseEmptyValues(c.EmptyValues); err == nil {
+		opts = append(opts, OptEmptyValues(policy))
+	}
+	if policy, err := ParseInvalidUTF8(c.InvalidUTF8); err == nil {
+		opts = append(opts, OptInvalidUTF8(policy))
+	}
+	if locale, err := ParseNumberLocale(c.NumberLocale); err == nil {
+		opts = append(opts, OptNumberLocale(locale))
+	}
+	var epochUnits []EpochUnit
+	for _, name := range c.EpochUnits {
+		if unit, err := ParseEpochUnit(name); err == nil {
+			epochUnits = append(epochUnits, unit)
+		}
+	}
+	if len(epochUnits) > 0 {
+		opts = append(opts, OptEpochTimes(epochUnits...))
+	}
+	if level, err := ParseStrictness(c.Strictness); err == nil {
+		opts = append(opts, OptStrict(level))
+	}
+	var timePaths []string
+	for path := range c.TimeFormats {
+		timePaths = append(timePaths, path)
+	}
+	sort.Strings(timePaths)
+	for _, path := range timePaths {
+		opts = append(opts, OptTimeAt(path, c.TimeFormats[path]))
+	}
+	if len(c.RawMessagePaths) > 0 {
+		opts = append(opts, OptRawMessageAt(c.RawMessagePaths...))
+	}
+	if len(c.MapPaths) > 0 {
+		opts = append(opts, OptMapAt(c.MapPaths...))
+	}
+	if len(c.StructPaths) > 0 {
+		opts = append(opts, OptStructAt(c.StructPaths...))
+	}
+	if len(c.Names.Fields) > 0 || len(c.Names.Types) > 0 {
+		opts = append(opts, OptNameMapping(c.Names))
+	}
+	if c.FieldOrderOriginal {
+		opts = append(opts, OptFieldOrder(FieldOrderOriginal))
+	}
+	if c.GoModule != "" {
+		opts = append(opts, OptGoModule(c.GoModule))
+	}
+	if c.TypeOrderReferencedBefore {
+		opts = append(opts, OptTypeOrder(TypeOrderReferencedBefore))
+	}
+	return opts
+}
+
+// NewParser returns parser with options set by config, followed by opts.
+func (c Config) NewParser(opts ...JSONParserOpt) *JSONParser {
+	name := c.RootName
+	if name == "" {
+		name = defaultRootName
+	}
+	return NewJSONParser(name, append(c.Opts(), opts...)...)
+}
@@ -0,0 +1,122 @@
+package json2go
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// Converter is a JSONParser safe for concurrent use, e.g. by handlers of server requests feeding shared types.
+// Inputs are decoded before locking the parser, when options don't need raw input, so concurrent feeds
+// only wait for each other while inferring types.
+type Converter struct {
+	mu sync.Mutex
+	p  *JSONParser
+}
+
+// NewConverter creates new converter, options are the same as options of NewJSONParser.
+func NewConverter(rootTypeName string, opts ...JSONParserOpt) *Converter {
+	return &Converter{p: NewJSONParser(rootTypeName, opts...)}
+}
+
+// Feed consumes json input, see JSONParser.FeedBytes.
+func (c *Converter) Feed(input []byte) error {
+	if c.p.needsRawInput() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return c.p.FeedBytes(input)
+	}
+
+	input, err := decodeInput(input, c.p.opts.invalidUTF8, c.warner(WarningEncoding))
+	if err != nil {
+		return err
+	}
+	if c.p.opts.nonFiniteNumbers {
+		input = quoteNonFiniteNumbers(input)
+	}
+	var v interface{}
+	if err := json.Unmarshal(input, &v); err != nil {
+		return invalidJSONError{err: err}
+	}
+	return c.Infer(v)
+}
+
+// Infer consumes value decoded by Tokenizer, see JSONParser.Infer.
+func (c *Converter) Infer(v interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.p.Infer(v)
+}
+
+// FeedReaderContext consumes stream of json documents from reader, see JSONParser.FeedReaderContext.
+// Documents are consumed one by one, so other feeds aren't blocked until the stream ends.
+func (c *Converter) FeedReaderContext(ctx context.Context, r io.Reader) error {
+	var dr io.Reader = &decodingReader{r: &contextReader{ctx: ctx, r: r}, d: inputDecoder{policy: c.p.opts.invalidUTF8, warn: c.warner(WarningEncoding)}}
+	if c.p.opts.nonFiniteNumbers {
+		dr = &nonFiniteReader{r: dr}
+	}
+	reader := NewJSONReader(dr)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		doc, err := reader.ReadDocument()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			return err
+		}
+		if err := c.Feed(doc); err != nil {
+			return err
+		}
+	}
+}
+
+// warner returns function adding warnings of category to parser.
+func (c *Converter) warner(category string) func(msg string) {
+	return func(msg string) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.p.warn(category, msg)
+	}
+}
+
+// cloneRoot returns copy of tree of inputs consumed so far.
+func (c *Converter) cloneRoot() *node {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.p.rootNode.clone()
+}
+
+// Generate returns go types of inputs consumed so far, see JSONParser.Generate.
+func (c *Converter) Generate() (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.p.Generate()
+}
+
+// Imports returns sorted list of packages used in generated types, see JSONParser.Imports.
+func (c *Converter) Imports() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.p.Imports()
+}
+
+// Warnings returns warnings about consumed inputs, see JSONParser.Warnings.
+func (c *Converter) Warnings() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.p.Warnings()
+}
+
+// Schema returns IR of inputs consumed so far, see JSONParser.Schema.
+func (c *Converter) Schema() *Schema {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.p.Schema()
+}
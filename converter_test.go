@@ -0,0 +1,97 @@
+package json2go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConverterConcurrentFeed(t *testing.T) {
+	t.Parallel()
+
+	for _, opts := range [][]JSONParserOpt{
+		nil,
+		{OptFieldOrder(FieldOrderOriginal), OptInputCache(10)},
+	} {
+		c := NewConverter(baseTypeName, opts...)
+		expected := NewJSONParser(baseTypeName, opts...)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			input := fmt.Sprintf(`{"id":%d,"name":"n","tags":["a"],"sub":{"k%d":true}}`, i, i%4)
+			require.NoError(t, expected.FeedBytes([]byte(input)))
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, c.Feed([]byte(input)))
+				_, _ = c.Generate()
+				_ = c.Warnings()
+			}()
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, c.FeedReaderContext(context.Background(), strings.NewReader(`{"id":1} {"id":2}`)))
+		}()
+		wg.Wait()
+		require.NoError(t, expected.FeedReader(strings.NewReader(`{"id":1} {"id":2}`)))
+
+		code, err := c.Generate()
+		require.NoError(t, err)
+		expectedCode, err := expected.Generate()
+		require.NoError(t, err)
+		assert.Equal(t, expectedCode, code)
+		assert.Equal(t, expected.Imports(), c.Imports())
+		assert.Equal(t, expected.Schema(), c.Schema())
+	}
+}
+
+func TestConverterErrors(t *testing.T) {
+	t.Parallel()
+
+	c := NewConverter(baseTypeName, OptMaxDepth(1))
+	assert.True(t, errors.Is(c.Feed([]byte(`{"a":`)), ErrInvalidJSON))
+	assert.True(t, errors.Is(c.Feed([]byte(`{"a":{"b":{}}}`)), ErrDepthExceeded))
+	assert.True(t, errors.Is(c.FeedReaderContext(context.Background(), strings.NewReader(`{"a":`)), ErrInvalidJSON))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, context.Canceled, c.FeedReaderContext(ctx, strings.NewReader(`{"a":1}`)))
+}
+
+func TestStatelessAPIConcurrentCalls(t *testing.T) {
+	t.Parallel()
+
+	opts := []JSONParserOpt{
+		OptExtractCommonTypes(true),
+		OptOverrides(Overrides{Types: map[string]string{"$.id": "string"}}),
+		OptNameMapping(NameMapping{Fields: map[string]string{"$.url": "Link"}}),
+		OptSingulars(map[string]string{"items": "Entry"}),
+	}
+	input := `{"id":1,"url":"u","items":[{"a":1}],"other":{"a":2}}`
+	expected, err := ConvertContext(context.Background(), strings.NewReader(input), baseTypeName, opts...)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			out, err := ConvertContext(context.Background(), strings.NewReader(input), baseTypeName, opts...)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, out)
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := ConvertAll(map[string][]byte{"A": []byte(input), "B": []byte(input)}, opts...)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+}
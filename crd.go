@@ -0,0 +1,110 @@
+package json2go
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v2"
+)
+
+// crdPreserveUnknown is an OpenAPI extension of Kubernetes, keeping values not described by schema.
+const crdPreserveUnknown = "x-kubernetes-preserve-unknown-fields"
+
+// CRDEmitter emits yaml "openAPIV3Schema" block of Kubernetes CustomResourceDefinition version from IR,
+// so custom resources can be authored starting from sample objects.
+//
+// Schema is structural, as Kubernetes requires: declared types are inlined, and values of any or opaque kinds,
+// like json.RawMessage, are marked with x-kubernetes-preserve-unknown-fields. Fields of pointers, optional values
+// and fields with omitempty aren't required. Metadata field of root type is an object without properties,
+// as metadata of custom resources is validated by Kubernetes.
+type CRDEmitter struct{}
+
+// Emit returns "openAPIV3Schema" block describing root type of IR.
+func (CRDEmitter) Emit(ir *Schema) ([]byte, error) {
+	if err := validateIR(ir); err != nil {
+		return nil, err
+	}
+	root := crdSchema(ir, ir.Types[ir.Root], map[string]bool{ir.Root: true})
+	return yaml.Marshal(yaml.MapSlice{{Key: "openAPIV3Schema", Value: crdRootSchema(root)}})
+}
+
+// crdRootSchema replaces schema of metadata of custom resource object with an object without properties.
+func crdRootSchema(root yaml.MapSlice) yaml.MapSlice {
+	for _, item := range root {
+		if item.Key != "properties" {
+			continue
+		}
+		props := item.Value.(yaml.MapSlice)
+		for i := range props {
+			if props[i].Key == "metadata" {
+				props[i].Value = yaml.MapSlice{{Key: "type", Value: "object"}}
+			}
+		}
+	}
+	return root
+}
+
+// crdSchema returns structural OpenAPI schema of type. Declared types being expanded are kept in expanding,
+// recursive references keep unknown fields.
+func crdSchema(ir *Schema, t *SchemaType, expanding map[string]bool) yaml.MapSlice {
+	switch t.Kind {
+	case SchemaBool:
+		return yaml.MapSlice{{Key: "type", Value: "boolean"}}
+	case SchemaInt:
+		return yaml.MapSlice{{Key: "type", Value: "integer"}, {Key: "format", Value: "int64"}}
+	case SchemaFloat:
+		return yaml.MapSlice{{Key: "type", Value: "number"}}
+	case SchemaString:
+		return yaml.MapSlice{{Key: "type", Value: "string"}}
+	case SchemaTime:
+		return yaml.MapSlice{{Key: "type", Value: "string"}, {Key: "format", Value: "date-time"}}
+	case SchemaPointer, SchemaOptional:
+		return append(crdSchema(ir, t.Elem, expanding), yaml.MapItem{Key: "nullable", Value: true})
+	case SchemaSlice:
+		items := crdSchema(ir, t.Elem, expanding)
+		if t.SkipNulls {
+			items = append(items, yaml.MapItem{Key: "nullable", Value: true})
+		}
+		return yaml.MapSlice{{Key: "type", Value: "array"}, {Key: "items", Value: items}}
+	case SchemaMap:
+		return yaml.MapSlice{{Key: "type", Value: "object"}, {Key: "additionalProperties", Value: crdSchema(ir, t.Elem, expanding)}}
+	case SchemaStruct:
+		schema := yaml.MapSlice{{Key: "type", Value: "object"}}
+		props := yaml.MapSlice{}
+		var required []string
+		for _, f := range t.Fields {
+			props = append(props, yaml.MapItem{Key: f.Key, Value: crdSchema(ir, f.Type, expanding)})
+			if !f.OmitEmpty && !f.OmitZero && f.Type.Kind != SchemaPointer && f.Type.Kind != SchemaOptional {
+				required = append(required, f.Key)
+			}
+		}
+		if len(props) > 0 {
+			schema = append(schema, yaml.MapItem{Key: "properties", Value: props})
+		}
+		if len(required) > 0 {
+			schema = append(schema, yaml.MapItem{Key: "required", Value: required})
+		}
+		if t.KeepsUnknown {
+			schema = append(schema, yaml.MapItem{Key: crdPreserveUnknown, Value: true})
+		}
+		return schema
+	case SchemaNamed:
+		if expanding[t.Name] {
+			return yaml.MapSlice{{Key: "type", Value: "object"}, {Key: crdPreserveUnknown, Value: true}}
+		}
+		expanding[t.Name] = true
+		defer delete(expanding, t.Name)
+		return crdSchema(ir, ir.Types[t.Name], expanding)
+	}
+	// Any values, json.RawMessage and types with own json methods.
+	return yaml.MapSlice{{Key: crdPreserveUnknown, Value: true}}
+}
+
+// CRDSchema returns "openAPIV3Schema" block of Kubernetes CustomResourceDefinition describing parsed documents,
+// see CRDEmitter.
+func (p *JSONParser) CRDSchema() ([]byte, error) {
+	out, err := CRDEmitter{}.Emit(p.Schema())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInternal, err)
+	}
+	return out, nil
+}
@@ -0,0 +1,136 @@
+package json2go
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCRDSchema(t *testing.T) {
+	t.Parallel()
+
+	p := NewJSONParser(baseTypeName, OptExtractCommonTypes(true), OptRawMessageAt("$.spec.template"), OptUnknownFields(true))
+	require.NoError(t, p.FeedBytes([]byte(`{
+		"apiVersion": "example.com/v1",
+		"kind": "Backup",
+		"metadata": {"name": "daily", "labels": {"app": "db"}},
+		"spec": {
+			"schedule": "0 0 * * *",
+			"retention": 7.5,
+			"template": {"any": ["thing"]},
+			"from": {"host": "a", "port": 1},
+			"to": {"host": "b", "port": 2},
+			"startAt": "2021-01-01T00:00:00Z"
+		}
+	}`)))
+	require.NoError(t, p.FeedBytes([]byte(`{
+		"apiVersion": "example.com/v1",
+		"kind": "Backup",
+		"metadata": {"name": "weekly"},
+		"spec": {"schedule": "0 0 * * 0", "retention": 1, "template": null, "from": {"host": "a", "port": 1},
+			"to": {"host": "b", "port": 2}, "startAt": null}
+	}`)))
+
+	out, err := p.CRDSchema()
+	require.NoError(t, err)
+	assert.Equal(t, `openAPIV3Schema:
+  type: object
+  properties:
+    apiVersion:
+      type: string
+    kind:
+      type: string
+    metadata:
+      type: object
+    spec:
+      type: object
+      properties:
+        from:
+          type: object
+          properties:
+            host:
+              type: string
+            port:
+              type: integer
+              format: int64
+          required:
+          - host
+          - port
+          x-kubernetes-preserve-unknown-fields: true
+        retention:
+          type: number
+        schedule:
+          type: string
+        startAt:
+          type: string
+          format: date-time
+          nullable: true
+        template:
+          x-kubernetes-preserve-unknown-fields: true
+        to:
+          type: object
+          properties:
+            host:
+              type: string
+            port:
+              type: integer
+              format: int64
+          required:
+          - host
+          - port
+          x-kubernetes-preserve-unknown-fields: true
+      required:
+      - from
+      - retention
+      - schedule
+      - template
+      - to
+  required:
+  - apiVersion
+  - kind
+  - metadata
+  - spec
+  x-kubernetes-preserve-unknown-fields: true
+`, string(out))
+}
+
+func TestCRDEmitter(t *testing.T) {
+	t.Parallel()
+
+	out, err := CRDEmitter{}.Emit(&Schema{
+		Root: "Node",
+		Types: map[string]*SchemaType{
+			"Node": {Kind: SchemaStruct, Fields: []SchemaField{
+				{Name: "Children", Key: "children", Type: &SchemaType{Kind: SchemaSlice, SkipNulls: true,
+					Elem: &SchemaType{Kind: SchemaNamed, Name: "Node"}}, OmitEmpty: true},
+				{Name: "Labels", Key: "labels", Type: &SchemaType{Kind: SchemaMap, Elem: &SchemaType{Kind: SchemaBool}}},
+				{Name: "Value", Key: "value", Type: &SchemaType{Kind: SchemaOptional, Elem: &SchemaType{Kind: SchemaAny}}},
+			}},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `openAPIV3Schema:
+  type: object
+  properties:
+    children:
+      type: array
+      items:
+        type: object
+        x-kubernetes-preserve-unknown-fields: true
+        nullable: true
+    labels:
+      type: object
+      additionalProperties:
+        type: boolean
+    value:
+      x-kubernetes-preserve-unknown-fields: true
+      nullable: true
+  required:
+  - labels
+`, string(out))
+
+	_, err = CRDEmitter{}.Emit(&Schema{Root: "Missing"})
+	assert.True(t, errors.Is(err, ErrInvalidIR), "%v", err)
+}
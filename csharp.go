@@ -0,0 +1,116 @@
+package json2go
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CSharpEmitter emits C# records serialized with System.Text.Json from IR, root record first.
+//
+// Records and properties keep go names, with JsonPropertyName attributes of json keys. Nullable reference types
+// are enabled: fields of pointers, optional values or with omitempty are nullable, other properties are required.
+// Fields with omitempty are ignored when null. Times are DateTimeOffsets, uuids are Guids and any or opaque values
+// are JsonElements.
+type CSharpEmitter struct{}
+
+// Emit returns C# source of records.
+func (CSharpEmitter) Emit(ir *Schema) ([]byte, error) {
+	s, err := newIDLSchema(ir, "C# record", false)
+	if err != nil {
+		return nil, err
+	}
+
+	usings := map[string]bool{"System.Text.Json.Serialization": true}
+	var records strings.Builder
+	for _, d := range s.decls {
+		fmt.Fprintf(&records, "\npublic record %s\n{\n", d.name)
+		names := map[string]bool{d.name: true}
+		for i, f := range d.fields {
+			name := f.Name
+			for names[name] {
+				// Members can't have names of enclosing types.
+				name = nextName(name)
+			}
+			names[name] = true
+
+			if i > 0 {
+				records.WriteString("\n")
+			}
+			fmt.Fprintf(&records, "    [JsonPropertyName(%s)]\n", strconv.Quote(f.Key))
+			typ := csharpType(ir, s, f.Type, usings)
+			required := "required "
+			if f.isOptional() {
+				required = ""
+				typ = strings.TrimSuffix(typ, "?") + "?"
+			}
+			if f.OmitEmpty || f.OmitZero {
+				records.WriteString("    [JsonIgnore(Condition = JsonIgnoreCondition.WhenWritingNull)]\n")
+			}
+			fmt.Fprintf(&records, "    public %s%s %s { get; init; }\n", required, typ, name)
+		}
+		records.WriteString("}\n")
+	}
+
+	names := make([]string, 0, len(usings))
+	for name := range usings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "using %s;\n", name)
+	}
+	b.WriteString("\n#nullable enable\n")
+	b.WriteString(records.String())
+	return []byte(b.String()), nil
+}
+
+// csharpType returns C# type of values. Namespaces of used types are added to usings.
+func csharpType(ir *Schema, s *idlSchema, t *SchemaType, usings map[string]bool) string {
+	switch t.Kind {
+	case SchemaBool:
+		return "bool"
+	case SchemaInt:
+		return "long"
+	case SchemaFloat:
+		if t.Bits == 32 {
+			return "float"
+		}
+		return "double"
+	case SchemaString:
+		if t.Format == SchemaFormatUUID {
+			usings["System"] = true
+			return "Guid"
+		}
+		return "string"
+	case SchemaTime:
+		usings["System"] = true
+		return "DateTimeOffset"
+	case SchemaPointer, SchemaOptional:
+		return strings.TrimSuffix(csharpType(ir, s, t.Elem, usings), "?") + "?"
+	case SchemaSlice:
+		usings["System.Collections.Generic"] = true
+		return "List<" + csharpType(ir, s, t.Elem, usings) + ">"
+	case SchemaMap:
+		usings["System.Collections.Generic"] = true
+		key := "string"
+		if t.Key != nil {
+			key = strings.TrimSuffix(csharpType(ir, s, t.Key, usings), "?")
+		}
+		return "Dictionary<" + key + ", " + csharpType(ir, s, t.Elem, usings) + ">"
+	case SchemaStruct:
+		return s.names[t]
+	case SchemaNamed:
+		return csharpType(ir, s, ir.Types[t.Name], usings)
+	}
+	usings["System.Text.Json"] = true
+	return "JsonElement"
+}
+
+// CSharpTypes returns C# records describing parsed documents, see CSharpEmitter.
+func (p *JSONParser) CSharpTypes() (string, error) {
+	out, err := CSharpEmitter{}.Emit(p.Schema())
+	return string(out), err
+}
@@ -0,0 +1,127 @@
+package json2go
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSharpTypes(t *testing.T) {
+	t.Parallel()
+
+	p := NewJSONParser(baseTypeName)
+	require.NoError(t, p.FeedBytes([]byte(`{"id": 1, "name": "a", "tags": ["x"], "addr": {"zip": "1"},
+		"at": "2021-01-01T00:00:00Z", "extra": 1}`)))
+	require.NoError(t, p.FeedBytes([]byte(`{"id": 2, "tags": [], "addr": {"zip": "2"},
+		"at": "2021-01-02T00:00:00Z", "extra": "x", "score": 0.5}`)))
+
+	src, err := p.CSharpTypes()
+	require.NoError(t, err)
+	assert.Equal(t, `using System;
+using System.Collections.Generic;
+using System.Text.Json;
+using System.Text.Json.Serialization;
+
+#nullable enable
+
+public record Document
+{
+    [JsonPropertyName("addr")]
+    public required DocumentAddr Addr { get; init; }
+
+    [JsonPropertyName("at")]
+    public required DateTimeOffset At { get; init; }
+
+    [JsonPropertyName("extra")]
+    public required JsonElement Extra { get; init; }
+
+    [JsonPropertyName("id")]
+    public required long ID { get; init; }
+
+    [JsonPropertyName("name")]
+    [JsonIgnore(Condition = JsonIgnoreCondition.WhenWritingNull)]
+    public string? Name { get; init; }
+
+    [JsonPropertyName("score")]
+    [JsonIgnore(Condition = JsonIgnoreCondition.WhenWritingNull)]
+    public double? Score { get; init; }
+
+    [JsonPropertyName("tags")]
+    public required List<string> Tags { get; init; }
+}
+
+public record DocumentAddr
+{
+    [JsonPropertyName("zip")]
+    public required string Zip { get; init; }
+}
+`, src)
+}
+
+func TestCSharpEmitter(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		ir       *Schema
+		expected string
+		err      error
+	}{
+		{
+			name: "recursive type, maps and empty record",
+			ir: &Schema{Root: "Node", Types: map[string]*SchemaType{
+				"Node": {Kind: SchemaStruct, Fields: []SchemaField{
+					{Name: "Node", Key: "node", Type: &SchemaType{Kind: SchemaPointer, Elem: &SchemaType{Kind: SchemaNamed, Name: "Node"}}},
+					{Name: "Votes", Key: "votes", Type: &SchemaType{Kind: SchemaMap, Key: &SchemaType{Kind: SchemaString, Format: SchemaFormatUUID},
+						Elem: &SchemaType{Kind: SchemaSlice, Elem: &SchemaType{Kind: SchemaOptional, Elem: &SchemaType{Kind: SchemaInt}}}}},
+					{Name: "Meta", Key: "meta", Type: &SchemaType{Kind: SchemaNamed, Name: "Meta"}},
+				}},
+				"Meta": {Kind: SchemaStruct},
+			}},
+			expected: `using System;
+using System.Collections.Generic;
+using System.Text.Json.Serialization;
+
+#nullable enable
+
+public record Node
+{
+    [JsonPropertyName("node")]
+    public Node? Node2 { get; init; }
+
+    [JsonPropertyName("votes")]
+    public required Dictionary<Guid, List<long?>> Votes { get; init; }
+
+    [JsonPropertyName("meta")]
+    public required Meta Meta { get; init; }
+}
+
+public record Meta
+{
+}
+`,
+		},
+		{
+			name: "root isn't struct",
+			ir:   &Schema{Root: "Document", Types: map[string]*SchemaType{"Document": {Kind: SchemaString}}},
+			err:  ErrUnsupportedShape,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			out, err := CSharpEmitter{}.Emit(tc.ir)
+			if tc.err != nil {
+				assert.True(t, errors.Is(err, tc.err), "unexpected error: %v", err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(out))
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package json2go
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// cueIdentifierRe matches CUE identifiers, which don't need quotes as field labels.
+var cueIdentifierRe = regexp.MustCompile(`^[A-Za-z$][\w$]*$`)
+
+// cuePredeclared are keywords and predeclared identifiers of CUE, and imported package. Fields with such labels
+// would shadow them, so labels are quoted.
+var cuePredeclared = map[string]bool{
+	"_": true, "and": true, "bool": true, "bytes": true, "close": true, "div": true, "false": true, "float": true,
+	"for": true, "if": true, "import": true, "in": true, "int": true, "len": true, "let": true, "mod": true,
+	"null": true, "number": true, "or": true, "package": true, "quo": true, "rem": true, "string": true,
+	"time": true, "true": true,
+}
+
+// CUESchema returns CUE definitions describing parsed documents, for validation of configuration files.
+// Root definition is first, followed by definitions of extracted types. Like ZodSchema, definitions are derived
+// from parsed values:
+//
+//   - strings with uuids are constrained with pattern, times are time.Time,
+//   - strings with few distinct keyword values, each seen twice on average, are disjunctions of values,
+//   - attributes missing in some objects are optional, and nullable values may be null.
+//
+// Formats and enums of strings are tracked by parser only with OptStringFormats.
+func (p *JSONParser) CUESchema() (string, error) {
+	nodes, err := p.outputNodes()
+	if err != nil {
+		return "", err
+	}
+
+	var defs strings.Builder
+	var c cueSchema
+	for _, n := range nodes {
+		fmt.Fprintf(&defs, "\n#%s: %s\n", n.name, c.value(n, ""))
+	}
+
+	var b strings.Builder
+	if c.usesTime {
+		b.WriteString("import \"time\"\n")
+	}
+	out := b.String() + defs.String()
+	return strings.TrimPrefix(out, "\n"), nil
+}
+
+// cueSchema builds CUE definitions of nodes.
+type cueSchema struct {
+	// usesTime is true if definitions use time package.
+	usesTime bool
+}
+
+// value returns CUE constraint of node's values, with lines indented with indent.
+func (c *cueSchema) value(n *node, indent string) string {
+	var value string
+	switch n.t.id() {
+	case nodeTypeBool.id():
+		value = "bool"
+	case nodeTypeInt.id():
+		value = "int"
+	case nodeTypeFloat.id():
+		value = "number"
+	case nodeTypeString.id():
+		value = c.string(n)
+	case nodeTypeTime.id():
+		c.usesTime = true
+		value = "time.Time"
+	case nodeTypeObject.id():
+		value = c.object(n, indent)
+	case nodeTypeMap.id():
+		elem := "_"
+		if len(n.children) > 0 {
+			elem = c.value(n.children[0], indent+"\t")
+		}
+		value = "{[string]: " + elem + "}"
+	case nodeTypeExtracted.id():
+		value = "_"
+		if !isGoType(n.externalTypeID) {
+			value = "#" + n.externalTypeID
+		}
+	default:
+		value = "_"
+	}
+
+	unknown := value == "_"
+	for i := n.arrayLevel; i > 0; i-- {
+		if n.arrayWithNulls && i == n.arrayLevel && !unknown {
+			value = "(" + value + " | null)"
+		}
+		value = "[..." + value + "]"
+		unknown = false
+	}
+	if n.nullable && !n.root && !unknown {
+		value += " | null"
+	}
+	return value
+}
+
+// string returns CUE constraint of strings, with pattern of uuids or enum values.
+func (c *cueSchema) string(n *node) string {
+	if n.formats&formatUUID != 0 {
+		return "string & =~" + strconv.Quote(uuidRe.String())
+	}
+	if n.isEnum() {
+		values := make([]string, 0, len(n.enumValues))
+		for _, v := range n.enumValues {
+			values = append(values, strconv.Quote(v))
+		}
+		return strings.Join(values, " | ")
+	}
+	return "string"
+}
+
+// object returns CUE struct with node's attributes.
+func (c *cueSchema) object(n *node, indent string) string {
+	if len(n.children) == 0 {
+		return "{}"
+	}
+	var b strings.Builder
+	b.WriteString("{\n")
+	for _, child := range n.children {
+		label := child.key
+		if !cueIdentifierRe.MatchString(label) || cuePredeclared[label] {
+			label = strconv.Quote(label)
+		}
+		if !child.required {
+			label += "?"
+		}
+		fmt.Fprintf(&b, "%s\t%s: %s\n", indent, label, c.value(child, indent+"\t"))
+	}
+	b.WriteString(indent + "}")
+	return b.String()
+}
@@ -0,0 +1,84 @@
+package json2go
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCUESchema(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		opts     []JSONParserOpt
+		inputs   []string
+		expected string
+	}{
+		{
+			name: "formats, enums and optional fields",
+			inputs: []string{
+				`{"id": "3f2504e0-4f89-11d3-9a0c-0305e82c3301", "status": "active", "user-name": "a b",
+					"at": "2021-01-01T00:00:00Z", "n": 1, "f": 1.5, "string": "a b", "tags": ["a", null], "limits": {"cpu": 1}}`,
+				`{"id": "4f2504e0-4f89-11d3-9a0c-0305e82c3301", "status": "disabled", "at": null, "n": 2, "f": 2,
+					"string": "c d", "tags": []}`,
+				`{"id": "5f2504e0-4f89-11d3-9a0c-0305e82c3301", "status": "active", "at": null, "n": 3, "f": 3,
+					"string": "e f", "tags": []}`,
+				`{"id": "6f2504e0-4f89-11d3-9a0c-0305e82c3301", "status": "disabled", "at": null, "n": 4, "f": 4,
+					"string": "g h", "tags": []}`,
+			},
+			expected: `import "time"
+
+#Document: {
+	at: time.Time | null
+	f: number
+	id: string & =~"^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$"
+	limits?: {
+		cpu: int
+	}
+	n: int
+	status: "active" | "disabled"
+	"string": string
+	tags: [...(string | null)]
+	"user-name"?: string
+}
+`,
+		},
+		{
+			name: "extracted types",
+			opts: []JSONParserOpt{OptExtractCommonTypes(true)},
+			inputs: []string{
+				`[{"from": {"city": "a", "zip": "1"}, "to": {"city": "b", "zip": "2"}, "tags": {"a": 1}}]`,
+			},
+			expected: `#Document: [...{
+	from: #CityZip
+	tags: {
+		a: int
+	}
+	to: #CityZip
+}]
+
+#CityZip: {
+	city: string
+	zip: string
+}
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := NewJSONParser(baseTypeName, append(tc.opts, OptStringFormats(true))...)
+			for _, input := range tc.inputs {
+				require.NoError(t, p.FeedBytes([]byte(input)))
+			}
+			src, err := p.CUESchema()
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, src)
+		})
+	}
+}
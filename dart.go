@@ -0,0 +1,131 @@
+package json2go
+
+import (
+	"fmt"
+	"strings"
+)
+
+// dartReserved are reserved words of Dart, which can't be field names.
+var dartReserved = map[string]bool{
+	"assert": true, "break": true, "case": true, "catch": true, "class": true, "const": true, "continue": true,
+	"default": true, "do": true, "else": true, "enum": true, "extends": true, "false": true, "final": true,
+	"finally": true, "for": true, "if": true, "in": true, "is": true, "new": true, "null": true, "rethrow": true,
+	"return": true, "super": true, "switch": true, "this": true, "throw": true, "true": true, "try": true,
+	"var": true, "void": true, "while": true, "with": true,
+}
+
+// DartEmitter emits Dart classes annotated for json_serializable from IR, root class first.
+//
+// Classes keep go names and fields are go names in lower camel case, with @JsonKey names of json keys when they
+// differ. Fields of pointers, optional values or with omitempty are nullable, omitempty fields aren't written
+// when null. Every class has fromJson and toJson stubs delegating to code generated into part file named after
+// root type. Times are DateTimes, and any or opaque values are Objects.
+type DartEmitter struct{}
+
+// Emit returns Dart source of classes.
+func (DartEmitter) Emit(ir *Schema) ([]byte, error) {
+	s, err := newIDLSchema(ir, "Dart class", false)
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	b.WriteString("import 'package:json_annotation/json_annotation.dart';\n\n")
+	fmt.Fprintf(&b, "part '%s.g.dart';\n", snakeCaseName(s.decls[0].name))
+	for _, d := range s.decls {
+		fmt.Fprintf(&b, "\n@JsonSerializable(explicitToJson: true)\nclass %s {\n", d.name)
+
+		var params []string
+		names := make(map[string]bool)
+		for _, f := range d.fields {
+			name := lowerCamelCaseName(f.Name)
+			if dartReserved[name] {
+				name += "_"
+			}
+			for names[name] {
+				name = nextName(name)
+			}
+			names[name] = true
+
+			typ := dartType(ir, s, f.Type)
+			if f.isOptional() && !strings.HasSuffix(typ, "?") {
+				typ += "?"
+			}
+			var key []string
+			if name != f.Key {
+				key = append(key, "name: "+dartString(f.Key))
+			}
+			if f.OmitEmpty && strings.HasSuffix(typ, "?") {
+				key = append(key, "includeIfNull: false")
+			}
+			if len(key) > 0 {
+				fmt.Fprintf(&b, "  @JsonKey(%s)\n", strings.Join(key, ", "))
+			}
+			fmt.Fprintf(&b, "  final %s %s;\n", typ, name)
+
+			if strings.HasSuffix(typ, "?") {
+				params = append(params, "this."+name)
+			} else {
+				params = append(params, "required this."+name)
+			}
+		}
+		if len(d.fields) > 0 {
+			b.WriteString("\n")
+		}
+		if len(params) > 0 {
+			fmt.Fprintf(&b, "  const %s({\n", d.name)
+			for _, param := range params {
+				fmt.Fprintf(&b, "    %s,\n", param)
+			}
+			b.WriteString("  });\n")
+		} else {
+			fmt.Fprintf(&b, "  const %s();\n", d.name)
+		}
+		fmt.Fprintf(&b, "\n  factory %[1]s.fromJson(Map<String, dynamic> json) => _$%[1]sFromJson(json);\n", d.name)
+		fmt.Fprintf(&b, "\n  Map<String, dynamic> toJson() => _$%sToJson(this);\n}\n", d.name)
+	}
+	return []byte(b.String()), nil
+}
+
+// dartType returns Dart type of values.
+func dartType(ir *Schema, s *idlSchema, t *SchemaType) string {
+	switch t.Kind {
+	case SchemaBool:
+		return "bool"
+	case SchemaInt:
+		return "int"
+	case SchemaFloat:
+		return "double"
+	case SchemaString:
+		return "String"
+	case SchemaTime:
+		return "DateTime"
+	case SchemaPointer, SchemaOptional:
+		return strings.TrimSuffix(dartType(ir, s, t.Elem), "?") + "?"
+	case SchemaSlice:
+		return "List<" + dartType(ir, s, t.Elem) + ">"
+	case SchemaMap:
+		key := "String"
+		if t.Key != nil && t.Key.Kind == SchemaInt {
+			key = "int"
+		}
+		return "Map<" + key + ", " + dartType(ir, s, t.Elem) + ">"
+	case SchemaStruct:
+		return s.names[t]
+	case SchemaNamed:
+		return dartType(ir, s, ir.Types[t.Name])
+	}
+	return "Object?"
+}
+
+// dartString returns Dart string literal of s, in single quotes.
+func dartString(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `'`, `\'`, `$`, `\$`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	return "'" + r.Replace(s) + "'"
+}
+
+// DartTypes returns Dart json_serializable classes describing parsed documents, see DartEmitter.
+func (p *JSONParser) DartTypes() (string, error) {
+	out, err := DartEmitter{}.Emit(p.Schema())
+	return string(out), err
+}
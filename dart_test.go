@@ -0,0 +1,140 @@
+package json2go
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDartTypes(t *testing.T) {
+	t.Parallel()
+
+	p := NewJSONParser(baseTypeName)
+	require.NoError(t, p.FeedBytes([]byte(`{"id": 1, "user_name": "a", "tags": ["x"], "addr": {"zip": "1"},
+		"at": "2021-01-01T00:00:00Z", "extra": 1, "class": "c"}`)))
+	require.NoError(t, p.FeedBytes([]byte(`{"id": 2, "tags": [], "addr": {"zip": "2"},
+		"at": "2021-01-02T00:00:00Z", "extra": "x", "class": "c"}`)))
+
+	src, err := p.DartTypes()
+	require.NoError(t, err)
+	assert.Equal(t, `import 'package:json_annotation/json_annotation.dart';
+
+part 'document.g.dart';
+
+@JsonSerializable(explicitToJson: true)
+class Document {
+  final DocumentAddr addr;
+  final DateTime at;
+  @JsonKey(name: 'class')
+  final String class_;
+  final Object? extra;
+  final int id;
+  final List<String> tags;
+  @JsonKey(name: 'user_name', includeIfNull: false)
+  final String? userName;
+
+  const Document({
+    required this.addr,
+    required this.at,
+    required this.class_,
+    this.extra,
+    required this.id,
+    required this.tags,
+    this.userName,
+  });
+
+  factory Document.fromJson(Map<String, dynamic> json) => _$DocumentFromJson(json);
+
+  Map<String, dynamic> toJson() => _$DocumentToJson(this);
+}
+
+@JsonSerializable(explicitToJson: true)
+class DocumentAddr {
+  final String zip;
+
+  const DocumentAddr({
+    required this.zip,
+  });
+
+  factory DocumentAddr.fromJson(Map<String, dynamic> json) => _$DocumentAddrFromJson(json);
+
+  Map<String, dynamic> toJson() => _$DocumentAddrToJson(this);
+}
+`, src)
+}
+
+func TestDartEmitter(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		ir       *Schema
+		expected string
+		err      error
+	}{
+		{
+			name: "recursive type, maps and empty class",
+			ir: &Schema{Root: "TreeNode", Types: map[string]*SchemaType{
+				"TreeNode": {Kind: SchemaStruct, Fields: []SchemaField{
+					{Name: "Parent", Key: "parent", Type: &SchemaType{Kind: SchemaPointer, Elem: &SchemaType{Kind: SchemaNamed, Name: "TreeNode"}}},
+					{Name: "Weights", Key: "$weights", Type: &SchemaType{Kind: SchemaMap, Key: &SchemaType{Kind: SchemaInt, Bits: 64},
+						Elem: &SchemaType{Kind: SchemaSlice, Elem: &SchemaType{Kind: SchemaFloat, Bits: 32}}}},
+					{Name: "Meta", Key: "meta", Type: &SchemaType{Kind: SchemaStruct}},
+				}},
+			}},
+			expected: `import 'package:json_annotation/json_annotation.dart';
+
+part 'tree_node.g.dart';
+
+@JsonSerializable(explicitToJson: true)
+class TreeNode {
+  final TreeNode? parent;
+  @JsonKey(name: '\$weights')
+  final Map<int, List<double>> weights;
+  final TreeNodeMeta meta;
+
+  const TreeNode({
+    this.parent,
+    required this.weights,
+    required this.meta,
+  });
+
+  factory TreeNode.fromJson(Map<String, dynamic> json) => _$TreeNodeFromJson(json);
+
+  Map<String, dynamic> toJson() => _$TreeNodeToJson(this);
+}
+
+@JsonSerializable(explicitToJson: true)
+class TreeNodeMeta {
+  const TreeNodeMeta();
+
+  factory TreeNodeMeta.fromJson(Map<String, dynamic> json) => _$TreeNodeMetaFromJson(json);
+
+  Map<String, dynamic> toJson() => _$TreeNodeMetaToJson(this);
+}
+`,
+		},
+		{
+			name: "root isn't struct",
+			ir:   &Schema{Root: "Document", Types: map[string]*SchemaType{"Document": {Kind: SchemaString}}},
+			err:  ErrUnsupportedShape,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			out, err := DartEmitter{}.Emit(tc.ir)
+			if tc.err != nil {
+				assert.True(t, errors.Is(err, tc.err), "unexpected error: %v", err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(out))
+		})
+	}
+}
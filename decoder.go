@@ -0,0 +1,337 @@
+package json2go
+
+import (
+	"fmt"
+	"go/ast"
+	"strconv"
+	"strings"
+)
+
+// decoderScalarReaders are names of scanner methods reading scalar values of go types.
+var decoderScalarReaders = map[string]string{
+	"string":  "readString",
+	"int":     "readInt",
+	"float64": "readFloat64",
+	"float32": "readFloat32",
+	"bool":    "readBool",
+}
+
+// astAddDecoder adds UnmarshalJSON method, decoding scalar fields of named struct type without reflection.
+// Values of other fields are unmarshaled with encoding/json.
+func astAddDecoder(n *node, st *ast.StructType, ctx *astContext) {
+	childrenByName := make(map[string]*node)
+	for _, c := range n.children {
+		childrenByName[c.name] = c
+	}
+
+	ctx.addImport("encoding/json")
+	ctx.addImport("fmt")
+	ctx.addImport("strconv")
+	scanner := ctx.addSharedHelper("jsonScanner", decoderScannerSrc)
+
+	var cases []string
+	for _, f := range st.Fields.List {
+		c, ok := childrenByName[f.Names[0].Name]
+		if !ok {
+			continue
+		}
+		cases = append(cases, fmt.Sprintf("case %s:\n%s", strconv.Quote(c.key), decoderFieldSrc(f)))
+	}
+
+	ctx.addHelper(fmt.Sprintf(`
+// UnmarshalJSON unmarshals %[1]s, decoding scalar fields without reflection. Keys are matched case sensitively.
+func (v *%[1]s) UnmarshalJSON(data []byte) error {
+	s := %[2]s{data: data}
+	if s.readNull() {
+		return s.end()
+	}
+	if err := s.objectStart(); err != nil {
+		return err
+	}
+	for {
+		key, ok, err := s.nextKey()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			break
+		}
+
+		switch string(key) {
+		%[3]s
+		default:
+			if _, err := s.skipValue(); err != nil {
+				return err
+			}
+		}
+	}
+	return s.end()
+}
+`, n.name, scanner, strings.Join(cases, "\n")))
+}
+
+// decoderFieldSrc returns source code decoding value of struct field.
+func decoderFieldSrc(f *ast.Field) string {
+	name := f.Names[0].Name
+
+	if id, ok := f.Type.(*ast.Ident); ok {
+		if reader, ok := decoderScalarReaders[id.Name]; ok {
+			return fmt.Sprintf(`
+			if !s.readNull() {
+				if v.%[1]s, err = s.%[2]s(); err != nil {
+					return err
+				}
+			}`, name, reader)
+		}
+	}
+	if star, ok := f.Type.(*ast.StarExpr); ok {
+		if id, ok := star.X.(*ast.Ident); ok {
+			if reader, ok := decoderScalarReaders[id.Name]; ok {
+				return fmt.Sprintf(`
+			if s.readNull() {
+				v.%[1]s = nil
+			} else {
+				x, err := s.%[2]s()
+				if err != nil {
+					return err
+				}
+				v.%[1]s = &x
+			}`, name, reader)
+			}
+		}
+	}
+
+	return fmt.Sprintf(`
+			raw, err := s.skipValue()
+			if err != nil {
+				return err
+			}
+			if err := json.Unmarshal(raw, &v.%s); err != nil {
+				return err
+			}`, name)
+}
+
+// decoderScannerSrc returns source code of json scanner type used by generated decoders.
+func decoderScannerSrc(name string) string {
+	return fmt.Sprintf(`
+// %[1]s reads json values without reflection.
+type %[1]s struct {
+	data []byte
+	pos  int
+	keys int
+}
+
+func (s *%[1]s) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("json: offset %%d: %%s", s.pos, fmt.Sprintf(format, args...))
+}
+
+func (s *%[1]s) skipSpace() {
+	for s.pos < len(s.data) {
+		switch s.data[s.pos] {
+		case ' ', '\t', '\n', '\r':
+			s.pos++
+		default:
+			return
+		}
+	}
+}
+
+// end checks if there is nothing but white space after decoded value.
+func (s *%[1]s) end() error {
+	s.skipSpace()
+	if s.pos != len(s.data) {
+		return s.errorf("unexpected data after value")
+	}
+	return nil
+}
+
+func (s *%[1]s) objectStart() error {
+	s.skipSpace()
+	if s.pos >= len(s.data) || s.data[s.pos] != '{' {
+		return s.errorf("object expected")
+	}
+	s.pos++
+	return nil
+}
+
+// nextKey reads next key of object, ok is false at the end of object.
+func (s *%[1]s) nextKey() (key []byte, ok bool, err error) {
+	s.skipSpace()
+	if s.pos < len(s.data) && s.data[s.pos] == '}' {
+		s.pos++
+		return nil, false, nil
+	}
+	if s.keys > 0 {
+		if s.pos >= len(s.data) || s.data[s.pos] != ',' {
+			return nil, false, s.errorf("comma expected")
+		}
+		s.pos++
+		s.skipSpace()
+	}
+	s.keys++
+
+	raw, err := s.skipString()
+	if err != nil {
+		return nil, false, err
+	}
+	key = raw[1 : len(raw)-1]
+	for _, c := range key {
+		if c == '\\' {
+			var k string
+			if err := json.Unmarshal(raw, &k); err != nil {
+				return nil, false, err
+			}
+			key = []byte(k)
+			break
+		}
+	}
+
+	s.skipSpace()
+	if s.pos >= len(s.data) || s.data[s.pos] != ':' {
+		return nil, false, s.errorf("colon expected")
+	}
+	s.pos++
+	return key, true, nil
+}
+
+// readNull consumes null literal, if it's next value.
+func (s *%[1]s) readNull() bool {
+	s.skipSpace()
+	if len(s.data)-s.pos >= 4 && string(s.data[s.pos:s.pos+4]) == "null" {
+		s.pos += 4
+		return true
+	}
+	return false
+}
+
+func (s *%[1]s) readString() (string, error) {
+	s.skipSpace()
+	raw, err := s.skipString()
+	if err != nil {
+		return "", err
+	}
+	for _, c := range raw[1 : len(raw)-1] {
+		if c == '\\' || c >= 0x80 {
+			// Escapes and utf-8 validation are handled by encoding/json.
+			var v string
+			err := json.Unmarshal(raw, &v)
+			return v, err
+		}
+	}
+	return string(raw[1 : len(raw)-1]), nil
+}
+
+func (s *%[1]s) readInt() (int, error) {
+	v, err := strconv.ParseInt(string(s.readNumber()), 10, 0)
+	if err != nil {
+		return 0, s.errorf("invalid int: %%v", err)
+	}
+	return int(v), nil
+}
+
+func (s *%[1]s) readFloat64() (float64, error) {
+	v, err := strconv.ParseFloat(string(s.readNumber()), 64)
+	if err != nil {
+		return 0, s.errorf("invalid float: %%v", err)
+	}
+	return v, nil
+}
+
+func (s *%[1]s) readFloat32() (float32, error) {
+	v, err := strconv.ParseFloat(string(s.readNumber()), 32)
+	if err != nil {
+		return 0, s.errorf("invalid float: %%v", err)
+	}
+	return float32(v), nil
+}
+
+func (s *%[1]s) readBool() (bool, error) {
+	s.skipSpace()
+	switch {
+	case len(s.data)-s.pos >= 4 && string(s.data[s.pos:s.pos+4]) == "true":
+		s.pos += 4
+		return true, nil
+	case len(s.data)-s.pos >= 5 && string(s.data[s.pos:s.pos+5]) == "false":
+		s.pos += 5
+		return false, nil
+	}
+	return false, s.errorf("bool expected")
+}
+
+func (s *%[1]s) readNumber() []byte {
+	s.skipSpace()
+	start := s.pos
+	for s.pos < len(s.data) {
+		switch c := s.data[s.pos]; {
+		case c >= '0' && c <= '9', c == '-', c == '+', c == '.', c == 'e', c == 'E':
+			s.pos++
+			continue
+		}
+		break
+	}
+	return s.data[start:s.pos]
+}
+
+// skipString skips string and returns its raw bytes, with quotes.
+func (s *%[1]s) skipString() ([]byte, error) {
+	if s.pos >= len(s.data) || s.data[s.pos] != '"' {
+		return nil, s.errorf("string expected")
+	}
+	start := s.pos
+	for s.pos++; s.pos < len(s.data); s.pos++ {
+		switch s.data[s.pos] {
+		case '\\':
+			s.pos++
+		case '"':
+			s.pos++
+			return s.data[start:s.pos], nil
+		}
+	}
+	return nil, s.errorf("unterminated string")
+}
+
+// skipValue skips any json value and returns its raw bytes.
+func (s *%[1]s) skipValue() ([]byte, error) {
+	s.skipSpace()
+	start := s.pos
+	depth := 0
+	for s.pos < len(s.data) {
+		switch s.data[s.pos] {
+		case '"':
+			if _, err := s.skipString(); err != nil {
+				return nil, err
+			}
+		case '{', '[':
+			depth++
+			s.pos++
+		case '}', ']':
+			if depth == 0 {
+				return s.data[start:s.pos], nil
+			}
+			depth--
+			s.pos++
+		case ',':
+			if depth == 0 {
+				return s.data[start:s.pos], nil
+			}
+			s.pos++
+		default:
+			s.pos++
+		}
+		if depth == 0 {
+			s.skipSpace()
+			if s.pos < len(s.data) {
+				switch s.data[s.pos] {
+				case ',', '}', ']':
+					return s.data[start:s.pos], nil
+				}
+			}
+		}
+	}
+	if depth != 0 || start == s.pos {
+		return nil, s.errorf("unexpected end of data")
+	}
+	return s.data[start:s.pos], nil
+}
+`, name)
+}
@@ -0,0 +1,78 @@
+package json2go
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserFastDecoders(t *testing.T) {
+	inputs := []string{
+		`{"id":1,"name":"a","score":1.5,"active":true,"nick":"x","tags":["a"],"user":{"id":2,"email":"e"}}`,
+		` { "id" : 2 , "name" : "b\"é\n" , "score" : -2e3 , "active" : false , "nick" : null , "extra" : {"a":[1,{"b":"}"}]} , "user" : {"id":3,"email":"f"} } `,
+		`{"name":"zażółć","score":3}`,
+		`null`,
+	}
+
+	parser := NewJSONParser(baseTypeName, OptFastDecoders(true), OptExtractCommonTypes(true))
+	for _, in := range inputs {
+		require.NoError(t, parser.FeedBytes([]byte(in)))
+	}
+	generated := parser.String()
+	assert.Contains(t, generated, "func (v *Document) UnmarshalJSON(data []byte) error {")
+	assert.Contains(t, generated, "fmt.Sprintf(format, args...)")
+
+	// Every input is decoded with generated decoder and with encoding/json, results should be equal.
+	// Invalid inputs should fail in both cases.
+	out := runGeneratedCode(t, parser, `
+	type plain Document
+	sc := bufio.NewScanner(os.Stdin)
+	for sc.Scan() {
+		var doc Document
+		var plainDoc plain
+		err := json.Unmarshal(sc.Bytes(), &doc)
+		plainErr := json.Unmarshal(sc.Bytes(), &plainDoc)
+		if (err == nil) != (plainErr == nil) {
+			fmt.Printf("error mismatch: %v, %v\n", err, plainErr)
+			continue
+		}
+		if err != nil {
+			fmt.Println("error")
+			continue
+		}
+		got, _ := json.Marshal(doc)
+		want, _ := json.Marshal(plainDoc)
+		if string(got) != string(want) {
+			fmt.Printf("mismatch: %s, %s\n", got, want)
+			continue
+		}
+		fmt.Println("ok")
+	}
+
+	data := []byte(`+"`"+inputs[0]+"`"+`)
+	fast := testing.Benchmark(func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			var doc Document
+			_ = json.Unmarshal(data, &doc)
+		}
+	})
+	reflection := testing.Benchmark(func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			var doc plain
+			_ = json.Unmarshal(data, &doc)
+		}
+	})
+	fmt.Println(fast.AllocsPerOp() <= reflection.AllocsPerOp())
+`, strings.Join(append(inputs,
+		`{"id":1.5}`,
+		`{"id":1,}`,
+		`{"id":1 "name":"a"}`,
+		`{"name":"a"`,
+		`{"active":"yes"}`,
+		`[]`,
+	), "\n"))
+
+	assert.Equal(t, strings.Repeat("ok\n", 4)+strings.Repeat("error\n", 6)+"true\n", out)
+}
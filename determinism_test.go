@@ -0,0 +1,76 @@
+package json2go
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestDeterministicOutput tests that identical inputs and options give byte-identical output, regardless of order
+// of map iteration.
+func TestDeterministicOutput(t *testing.T) {
+	t.Parallel()
+
+	const runs = 20
+
+	files, err := filepath.Glob("test/parser/*/*.json")
+	require.NoError(t, err)
+	inputs := map[string][]byte{
+		"colliding names": []byte(`{"user_id":1,"userId":"a","UserID":true,"k-1":1,"k_1":2,"K1":3,"type":"a","Type":"b",` +
+			`"items":[{"a":1,"b":{"x":1,"y":2}},{"c":{"x":3,"y":4}}],"p":{"q":{"r":1}},"p2":{"q":{"r":1}},` +
+			`"m":{"aa":{"v":1},"bb":{"v":2},"cc":{"v":3},"dd":{"v":4},"ee":{"v":5}},"e":["x","y","z"],"w":{"v":[1,2]}}`),
+	}
+	for _, file := range files {
+		input, err := ioutil.ReadFile(file)
+		require.NoError(t, err)
+		inputs[strings.TrimPrefix(file, "test/parser/")] = input
+	}
+
+	optionSets := map[string][]JSONParserOpt{
+		"default": nil,
+		"extract": {OptExtractCommonTypes(true), OptStringPointersWhenKeyMissing(true)},
+		"maps":    {OptMakeMaps(true, 3), OptMapKeyTypes(true), OptTuples(true)},
+		"original order": {OptFieldOrder(FieldOrderOriginal), OptExtractCommonTypes(true),
+			OptCollapseWrappers(true)},
+		"widening": {OptWidening(WideningLattice{IntFloat: WideningNumber, NumberString: WideningString,
+			BoolString: WideningString}), OptOutlierTolerance(0.5), OptRawMessageForUnstable(true, 3)},
+		"sampling": {OptReservoirSample(2), OptAnonymize(true, 1), OptKeySplitting(KeySplittingNested, "._")},
+		"comments": {OptCommentOutFields(0.5, true), OptStringMethods(true), OptPresenceTracking(true)},
+	}
+
+	for name, input := range inputs {
+		for optsName, opts := range optionSets {
+			name, input, opts := name, input, opts
+			t.Run(name+"/"+optsName, func(t *testing.T) {
+				t.Parallel()
+				first := generateAll(t, input, opts)
+				for i := 1; i < runs; i++ {
+					require.Equal(t, first, generateAll(t, input, opts), "run %d", i)
+				}
+			})
+		}
+	}
+}
+
+// generateAll returns go types, other outputs or their errors, and warnings of parser fed with input.
+func generateAll(t *testing.T, input []byte, opts []JSONParserOpt) string {
+	t.Helper()
+
+	parser := NewJSONParser("Document", opts...)
+	require.NoError(t, parser.FeedBytes(input))
+	code, err := parser.Generate()
+	require.NoError(t, err)
+	outputs := []string{code, strings.Join(parser.Warnings(), "\n")}
+	schema, err := parser.JSONSchema()
+	outputs = append(outputs, string(schema), fmt.Sprint(err))
+	zod, err := parser.ZodSchema()
+	outputs = append(outputs, zod, fmt.Sprint(err))
+	kotlin, err := parser.KotlinTypes()
+	outputs = append(outputs, kotlin, fmt.Sprint(err))
+
+	return strings.Join(outputs, "\n---\n")
+}
@@ -0,0 +1,201 @@
+package json2go
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Severity is a severity of diagnostic.
+type Severity int
+
+const (
+	// SeverityNote is a severity of information about inference, like dropped precision.
+	SeverityNote Severity = iota
+	// SeverityWarning is a severity of problems, which may need attention, like renamed fields.
+	SeverityWarning
+	// SeverityError is a severity of problems failing generation, like violations of strictness set with OptStrict.
+	SeverityError
+)
+
+// ParseSeverity returns severity by name: "note", "warning" or "error".
+func ParseSeverity(name string) (Severity, error) {
+	switch name {
+	case "note":
+		return SeverityNote, nil
+	case "warning":
+		return SeverityWarning, nil
+	case "error":
+		return SeverityError, nil
+	}
+	return SeverityNote, fmt.Errorf("unknown severity: %s", name)
+}
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	}
+	return "note"
+}
+
+// MarshalText marshals severity as its name.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText unmarshals severity from its name.
+func (s *Severity) UnmarshalText(text []byte) error {
+	severity, err := ParseSeverity(string(text))
+	if err != nil {
+		return err
+	}
+	*s = severity
+	return nil
+}
+
+// DiagnosticNameCollision is a category of diagnostics about keys with the same field names, renamed to
+// distinct ones, like "user_id" and "userId", generated as UserID and UserID2.
+const DiagnosticNameCollision = "name_collision"
+
+// Diagnostic is a problem found during inference, with severity. Diagnostics are marshaled to json as objects with
+// "severity", "category", "path" and "message" attributes, or to SARIF with MarshalSARIF.
+type Diagnostic struct {
+	// Severity is a severity of problem.
+	Severity Severity `json:"severity"`
+	// Category is a category of problem: warning category, like "duplicate_key" (see WarningDuplicateKey),
+	// "name_collision", or "strict_" with strictness failing on compromise, like "strict_interfaces".
+	Category string `json:"category"`
+	// Path is a json path of values, like "$.user.id", empty for problems of whole inputs.
+	Path string `json:"path,omitempty"`
+	// Message describes problem.
+	Message string `json:"message"`
+}
+
+// Diagnostics returns problems found in parsed inputs and generated types: warnings, compromises of inference and
+// collisions of field names. Compromises failing strictness set with OptStrict are errors, values represented
+// by interface{} or types of values of different kinds are warnings, and other compromises are notes.
+// Diagnostics are sorted by path, warnings of whole inputs first.
+func (p *JSONParser) Diagnostics() []Diagnostic {
+	var diagnostics []Diagnostic
+	for _, w := range p.warnings {
+		d := Diagnostic{Severity: SeverityWarning, Category: w.category, Message: w.msg}
+		if i := strings.Index(w.msg, ": "); strings.HasPrefix(w.msg, rootPath) && i > 0 {
+			d.Path, d.Message = w.msg[:i], w.msg[i+2:]
+		}
+		diagnostics = append(diagnostics, d)
+	}
+
+	opts := p.opts
+	opts.strictness = StrictnessLossless
+	nodes, _ := p.outputNodes()
+	if err, ok := strictCheck(p.rootNode, nodes, opts).(*StrictError); ok {
+		for _, v := range err.Violations {
+			d := Diagnostic{Severity: SeverityNote, Category: "strict_" + v.Level.String(), Path: v.Path, Message: v.Reason}
+			switch {
+			case v.Level <= p.opts.strictness:
+				d.Severity = SeverityError
+			case v.Level < StrictnessLossless:
+				d.Severity = SeverityWarning
+			}
+			diagnostics = append(diagnostics, d)
+		}
+	}
+
+	diagnostics = append(diagnostics, nameCollisions(p.rootNode)...)
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		return diagnostics[i].Path < diagnostics[j].Path
+	})
+	return diagnostics
+}
+
+// nameCollisions returns diagnostics of children of node and its descendants, renamed because their keys have
+// the same field names.
+func nameCollisions(n *node) []Diagnostic {
+	var diagnostics []Diagnostic
+	byName := make(map[string][]*node)
+	for _, c := range n.children {
+		name := attrName(c.key)
+		byName[name] = append(byName[name], c)
+	}
+	for _, c := range n.children {
+		name := attrName(c.key)
+		if name == "" || c.name == name || len(byName[name]) < 2 {
+			continue
+		}
+		// Colliding key is a key, which got the field name, or the first one.
+		var other *node
+		for _, o := range byName[name] {
+			if o != c && (other == nil || o.name == name) {
+				other = o
+			}
+		}
+		diagnostics = append(diagnostics, Diagnostic{
+			Severity: SeverityWarning,
+			Category: DiagnosticNameCollision,
+			Path:     c.path,
+			Message:  fmt.Sprintf("key %q has the same field name %s as key %q, renamed to %s", c.key, name, other.key, c.name),
+		})
+	}
+	for _, c := range n.children {
+		diagnostics = append(diagnostics, nameCollisions(c)...)
+	}
+	return diagnostics
+}
+
+// MarshalSARIF returns SARIF 2.1.0 log of diagnostics, with run of json2go tool, for code scanning tools and
+// review bots. Categories are rule ids, and json paths are logical locations. If artifact, like input file
+// name, isn't empty, results are located in it.
+func MarshalSARIF(diagnostics []Diagnostic, artifact string) ([]byte, error) {
+	type object = map[string]interface{}
+
+	var rules []object
+	ruleIndexes := make(map[string]int)
+	var results []object
+	for _, d := range diagnostics {
+		index, ok := ruleIndexes[d.Category]
+		if !ok {
+			index = len(rules)
+			ruleIndexes[d.Category] = index
+			rules = append(rules, object{"id": d.Category})
+		}
+
+		result := object{
+			"ruleId":    d.Category,
+			"ruleIndex": index,
+			"level":     d.Severity.String(),
+			"message":   object{"text": d.Message},
+		}
+		location := object{}
+		if artifact != "" {
+			location["physicalLocation"] = object{"artifactLocation": object{"uri": artifact}}
+		}
+		if d.Path != "" {
+			location["logicalLocations"] = []object{{"fullyQualifiedName": d.Path, "kind": "member"}}
+			result["message"] = object{"text": d.Path + ": " + d.Message}
+		}
+		if len(location) > 0 {
+			result["locations"] = []object{location}
+		}
+		results = append(results, result)
+	}
+
+	driver := object{"name": "json2go", "informationUri": "https://" + modulePath}
+	if version := moduleVersion(); version != "" {
+		driver["version"] = version
+	}
+	if len(rules) > 0 {
+		driver["rules"] = rules
+	}
+	if results == nil {
+		results = []object{}
+	}
+	return json.MarshalIndent(object{
+		"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+		"version": "2.1.0",
+		"runs":    []object{{"tool": object{"driver": driver}, "results": results}},
+	}, "", "  ")
+}
@@ -0,0 +1,93 @@
+package json2go
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSeverity(t *testing.T) {
+	t.Parallel()
+
+	for _, s := range []Severity{SeverityNote, SeverityWarning, SeverityError} {
+		parsed, err := ParseSeverity(s.String())
+		require.NoError(t, err)
+		assert.Equal(t, s, parsed)
+	}
+	_, err := ParseSeverity("fatal")
+	assert.EqualError(t, err, "unknown severity: fatal")
+}
+
+func TestJSONParser_Diagnostics(t *testing.T) {
+	t.Parallel()
+
+	p := NewJSONParser(baseTypeName, OptStrict(StrictnessInterfaces), OptSkipEmptyKeys(true),
+		OptDuplicateKeys(DuplicateKeysLastWins), OptMaxDepth(3))
+	require.NoError(t, p.FeedBytes([]byte(`{"user_id": 1, "mixed": [1, "a"], "n": null, "id": 1, "id": 2,
+		"a": {"user": {"Name": "x"}}}`)))
+	require.NoError(t, p.FeedBytes([]byte(`{"userId": 2, "a": {"user": {"name": "y"}}}`)))
+	p.FeedValue(map[string]interface{}{"a": map[string]interface{}{"b": map[string]interface{}{"c": map[string]interface{}{}}}})
+
+	diagnostics := p.Diagnostics()
+	assert.Equal(t, []Diagnostic{
+		{Severity: SeverityWarning, Category: WarningDepthExceeded, Message: "depth exceeded: input is nested deeper than 3 levels"},
+		{Severity: SeverityWarning, Category: DiagnosticNameCollision, Path: "$.a.user.name",
+			Message: `key "name" has the same field name Name as key "Name", renamed to Name2`},
+		{Severity: SeverityWarning, Category: WarningDuplicateKey, Path: "$.id", Message: "duplicate key"},
+		{Severity: SeverityError, Category: "strict_interfaces", Path: "$.mixed",
+			Message: "interface{} for values of different kinds: number, string, array"},
+		{Severity: SeverityNote, Category: "strict_lossless", Path: "$.n", Message: "key skipped, it has only null values"},
+		{Severity: SeverityWarning, Category: DiagnosticNameCollision, Path: "$.userId",
+			Message: `key "userId" has the same field name UserID as key "user_id", renamed to UserID2`},
+	}, diagnostics)
+
+	data, err := json.Marshal(diagnostics[0])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"severity": "warning", "category": "depth_exceeded",
+		"message": "depth exceeded: input is nested deeper than 3 levels"}`, string(data))
+	var d Diagnostic
+	require.NoError(t, json.Unmarshal(data, &d))
+	assert.Equal(t, diagnostics[0], d)
+}
+
+func TestMarshalSARIF(t *testing.T) {
+	t.Parallel()
+
+	data, err := MarshalSARIF([]Diagnostic{
+		{Severity: SeverityWarning, Category: WarningSampled, Message: "sampled"},
+		{Severity: SeverityError, Category: "strict_interfaces", Path: "$.a", Message: "interface{}"},
+		{Severity: SeverityNote, Category: WarningSampled, Path: "$.b", Message: "sampled"},
+	}, "in.json")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+		"version": "2.1.0",
+		"runs": [{
+			"tool": {"driver": {
+				"name": "json2go",
+				"informationUri": "https://github.com/heucoder/json2go",
+				"rules": [{"id": "sampled"}, {"id": "strict_interfaces"}]
+			}},
+			"results": [
+				{"ruleId": "sampled", "ruleIndex": 0, "level": "warning", "message": {"text": "sampled"},
+					"locations": [{"physicalLocation": {"artifactLocation": {"uri": "in.json"}}}]},
+				{"ruleId": "strict_interfaces", "ruleIndex": 1, "level": "error", "message": {"text": "$.a: interface{}"},
+					"locations": [{"physicalLocation": {"artifactLocation": {"uri": "in.json"}},
+						"logicalLocations": [{"fullyQualifiedName": "$.a", "kind": "member"}]}]},
+				{"ruleId": "sampled", "ruleIndex": 0, "level": "note", "message": {"text": "$.b: sampled"},
+					"locations": [{"physicalLocation": {"artifactLocation": {"uri": "in.json"}},
+						"logicalLocations": [{"fullyQualifiedName": "$.b", "kind": "member"}]}]}
+			]
+		}]
+	}`, string(data))
+
+	data, err = MarshalSARIF(nil, "")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+		"version": "2.1.0",
+		"runs": [{"tool": {"driver": {"name": "json2go", "informationUri": "https://github.com/heucoder/json2go"}}, "results": []}]
+	}`, string(data))
+}
@@ -0,0 +1,83 @@
+package json2go
+
+import "strings"
+
+// maxDiffCells limits size of table of longest common subsequence computed by DiffLines, so large texts,
+// e.g. sent by remote clients to Service.Diff, don't exhaust memory.
+const maxDiffCells = 1 << 20
+
+// DiffLines returns line diff of texts a and b. Lines are prefixed with "-" when removed,
+// "+" when added, and " " when unchanged. Empty string is returned for equal texts.
+// Common first and last lines are kept, and if texts between them have too many lines for minimal diff,
+// all their lines are reported as removed and added.
+func DiffLines(a, b string) string {
+	if a == b {
+		return ""
+	}
+	al := strings.Split(a, "\n")
+	bl := strings.Split(b, "\n")
+
+	var sb strings.Builder
+	prefix := 0
+	for prefix < len(al) && prefix < len(bl) && al[prefix] == bl[prefix] {
+		sb.WriteString(" " + al[prefix] + "\n")
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(al)-prefix && suffix < len(bl)-prefix && al[len(al)-1-suffix] == bl[len(bl)-1-suffix] {
+		suffix++
+	}
+
+	diffLines(&sb, al[prefix:len(al)-suffix], bl[prefix:len(bl)-suffix])
+	for _, l := range al[len(al)-suffix:] {
+		sb.WriteString(" " + l + "\n")
+	}
+	return sb.String()
+}
+
+// diffLines writes minimal diff of lines al and bl, or all lines as removed and added if they don't fit maxDiffCells.
+func diffLines(sb *strings.Builder, al, bl []string) {
+	if (len(al)+1)*(len(bl)+1) > maxDiffCells {
+		for _, l := range al {
+			sb.WriteString("-" + l + "\n")
+		}
+		for _, l := range bl {
+			sb.WriteString("+" + l + "\n")
+		}
+		return
+	}
+
+	// lcs[i][j] is a length of longest common subsequence of al[i:] and bl[j:].
+	lcs := make([][]int, len(al)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(bl)+1)
+	}
+	for i := len(al) - 1; i >= 0; i-- {
+		for j := len(bl) - 1; j >= 0; j-- {
+			switch {
+			case al[i] == bl[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(al) || j < len(bl) {
+		switch {
+		case i < len(al) && j < len(bl) && al[i] == bl[j]:
+			sb.WriteString(" " + al[i] + "\n")
+			i++
+			j++
+		case j == len(bl) || (i < len(al) && lcs[i+1][j] >= lcs[i][j+1]):
+			sb.WriteString("-" + al[i] + "\n")
+			i++
+		default:
+			sb.WriteString("+" + bl[j] + "\n")
+			j++
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package json2go
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffLines(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		a        string
+		b        string
+		expected string
+	}{
+		{
+			name: "equal",
+			a:    "a\nb",
+			b:    "a\nb",
+		},
+		{
+			name:     "changed line",
+			a:        "a\nb\nc",
+			b:        "a\nx\nc",
+			expected: " a\n-b\n+x\n c\n",
+		},
+		{
+			name:     "added lines",
+			a:        "a\nd",
+			b:        "a\nb\nc\nd",
+			expected: " a\n+b\n+c\n d\n",
+		},
+		{
+			name:     "removed lines",
+			a:        "a\nb\nc",
+			b:        "c",
+			expected: "-a\n-b\n c\n",
+		},
+		{
+			name:     "repeated lines",
+			a:        "x\na\nx\nb\nx",
+			b:        "x\nb\nx\na\nx",
+			expected: " x\n-a\n-x\n b\n+x\n+a\n x\n",
+		},
+		{
+			name:     "empty",
+			a:        "",
+			b:        "a",
+			expected: "-\n+a\n",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, DiffLines(tc.a, tc.b))
+		})
+	}
+}
+
+func TestDiffLinesLarge(t *testing.T) {
+	t.Parallel()
+
+	var a, b []string
+	for i := 0; i < 3000; i++ {
+		a = append(a, fmt.Sprintf("a%d", i))
+		b = append(b, fmt.Sprintf("b%d", i))
+	}
+	text := func(lines []string) string {
+		return "type T struct {\n" + strings.Join(lines, "\n") + "\n}"
+	}
+
+	diff := DiffLines(text(a), text(b))
+	lines := strings.Split(strings.TrimSuffix(diff, "\n"), "\n")
+	assert.Len(t, lines, 6002)
+	assert.Equal(t, " type T struct {", lines[0])
+	assert.Equal(t, "-a0", lines[1])
+	assert.Equal(t, "+b0", lines[3001])
+	assert.Equal(t, " }", lines[6001])
+
+	// Common first and last lines don't count into limit.
+	changed := append([]string{}, b...)
+	changed[1500] = "x"
+	diff = DiffLines(text(b), text(changed))
+	assert.Equal(t, 3003, strings.Count(diff, "\n"))
+	assert.Contains(t, diff, " b1499\n-b1500\n+x\n b1501\n")
+}
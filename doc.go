@@ -1,2 +1,13 @@
 // Package json2go implements decoding json strings to go type representation.
+//
+// Concurrency: JSONParser isn't safe for concurrent use, even its methods generating output change its state.
+// Converter wraps JSONParser for concurrent use. Package functions, like ConvertContext, ConvertAll,
+// MinimizeSamples or VerifyRoundTrip, and Service methods are safe for concurrent calls, also with the same options.
+// Options are only read by parsers, but loggers, progress callbacks and plugins shared by parsers
+// must be safe for concurrent use too.
+//
+// Reproducibility: identical inputs, fed in the same order, and identical options give byte-identical output, so
+// generated code can be checked in and verified, like with golden tests written by WriteGoldenTest. Order of map
+// iteration doesn't change names or order of types, fields or warnings. Exceptions are explicit: OptAnonymize with
+// seed 0 uses random seed, and OptHeaderTimestamp adds current time, unless SOURCE_DATE_EPOCH is set.
 package json2go
@@ -0,0 +1,505 @@
+package json2go
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// DriftTarget is an endpoint monitored by DriftMonitor.
+type DriftTarget struct {
+	// Name identifies target, and it's a name of root type, unless config has other root name.
+	Name string `json:"name" yaml:"name"`
+	// URL is fetched with GET requests, responses are json documents.
+	URL string `json:"url" yaml:"url"`
+	// Header is added to requests, like authorization header.
+	Header http.Header `json:"header,omitempty" yaml:"header,omitempty"`
+	// Interval is a time between checks.
+	Interval time.Duration `json:"interval" yaml:"interval"`
+	// Config is a config of inference, or Profile selects profile of service.
+	Config  Config `json:"config" yaml:"config,omitempty"`
+	Profile string `json:"profile,omitempty" yaml:"profile,omitempty"`
+	// Webhook is an URL receiving DriftEvent in json body of POST request, when types change.
+	Webhook string `json:"webhook,omitempty" yaml:"webhook,omitempty"`
+}
+
+// DriftEvent is a change of types of responses of target.
+type DriftEvent struct {
+	Target string    `json:"target"`
+	URL    string    `json:"url"`
+	Time   time.Time `json:"time"`
+	// BaseCode are go types stored before check, Code are go types stored after check, see GoEmitter.
+	BaseCode string `json:"baseCode"`
+	Code     string `json:"code"`
+	// Diff is a line diff of BaseCode and Code, see DiffLines.
+	Diff string `json:"diff"`
+	// Changes describe changes of stored types by paths of values, like "$.id: int changed to string",
+	// "$.email: added" or "$.name: removed".
+	Changes []string `json:"changes"`
+	// Schema is IR of types merged from stored types and types of checked response, see MarshalIR.
+	Schema json.RawMessage `json:"schema"`
+}
+
+// DriftStore stores IR of types of targets between checks, and restarts of monitor.
+type DriftStore interface {
+	// Load returns stored schema of target, or nil if there is none.
+	Load(target string) (*Schema, error)
+	// Save stores schema of target.
+	Save(target string, s *Schema) error
+}
+
+// DirDriftStore is a directory, storing schemas of targets in IR files named by target, like "users.json".
+type DirDriftStore string
+
+// Load returns schema of target read from its file, or nil if there is no file.
+func (d DirDriftStore) Load(target string) (*Schema, error) {
+	data, err := ioutil.ReadFile(filepath.Join(string(d), target+".json"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return UnmarshalIR(data)
+}
+
+// Save writes schema of target to its file.
+func (d DirDriftStore) Save(target string, s *Schema) error {
+	data, err := MarshalIR(s)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(string(d), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(string(d), target+".json"), data, 0644)
+}
+
+// memoryDriftStore is a DriftStore keeping schemas in memory, used by DriftMonitor without store.
+type memoryDriftStore struct {
+	mu      sync.Mutex
+	schemas map[string]*Schema
+}
+
+func (m *memoryDriftStore) Load(target string) (*Schema, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.schemas[target], nil
+}
+
+func (m *memoryDriftStore) Save(target string, s *Schema) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.schemas == nil {
+		m.schemas = make(map[string]*Schema)
+	}
+	m.schemas[target] = s
+	return nil
+}
+
+// DriftMonitor periodically fetches json responses of registered targets, infers their types with service,
+// and compares them with types stored by previous checks. Changed types are reported as DriftEvent to webhook
+// of target, and stored as new base once webhook accepts them, so failed deliveries are retried by next checks.
+// First check of target only stores its types.
+//
+// Types of each response are merged into stored types, see Merge, so keys, which are optional already, missing
+// in response, and values, which are sometimes null, aren't changes. Changes are keys of stored types missing
+// in response, new keys, and values of other kinds, including widened kinds, like ints changed to floats.
+type DriftMonitor struct {
+	// Service infers types, applying its options and profiles. Nil means service without options.
+	Service *Service
+	// Store stores types between checks. Nil means types are kept in memory.
+	Store DriftStore
+	// Client is used for requests to targets and webhooks, http.DefaultClient if nil.
+	Client *http.Client
+	// MaxResponseSize limits size in bytes of responses of targets, larger responses fail checks. 0 means 10 MiB.
+	MaxResponseSize int64
+	// OnDrift is called with changes found by checks, also for targets without webhook.
+	OnDrift func(DriftEvent)
+	// ErrorHandler is called with errors of scheduled checks, like unreachable targets. Nil ignores them.
+	ErrorHandler func(target string, err error)
+
+	mu      sync.Mutex
+	targets map[string]*driftSchedule
+	memory  memoryDriftStore
+	wake    chan struct{}
+}
+
+// driftSchedule is a registered target with time of its next check.
+type driftSchedule struct {
+	target DriftTarget
+	next   time.Time
+}
+
+// ReadDriftTargets reads yaml, or json, encoded list of targets, like:
+//
+//	# targets.yaml
+//	- name: users
+//	  url: https://api.example.com/users
+//	  header:
+//	    Authorization: ["Bearer ${API_TOKEN}"]
+//	  interval: 1h
+//	  webhook: https://hooks.example.com/drift
+//
+// Environment variables referenced in header values are expanded, so secrets aren't stored in file.
+func ReadDriftTargets(r io.Reader) ([]DriftTarget, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var targets []DriftTarget
+	if err := yaml.UnmarshalStrict(data, &targets); err != nil {
+		return nil, err
+	}
+	for _, t := range targets {
+		for _, values := range t.Header {
+			for i, v := range values {
+				values[i] = os.ExpandEnv(v)
+			}
+		}
+	}
+	return targets, nil
+}
+
+// Register adds target, replacing registered target with the same name. Target is checked immediately by Run.
+func (m *DriftMonitor) Register(t DriftTarget) error {
+	switch {
+	case t.Name == "":
+		return errors.New("drift target without name")
+	case strings.ContainsAny(t.Name, `/\`):
+		return fmt.Errorf("drift target name %q has path separators", t.Name)
+	case t.URL == "":
+		return fmt.Errorf("drift target %s without url", t.Name)
+	case t.Interval <= 0:
+		return fmt.Errorf("drift target %s without interval", t.Name)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.targets == nil {
+		m.targets = make(map[string]*driftSchedule)
+	}
+	m.targets[t.Name] = &driftSchedule{target: t}
+	m.signal()
+	return nil
+}
+
+// Unregister removes target. Stored types of target are kept.
+func (m *DriftMonitor) Unregister(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.targets, name)
+	m.signal()
+}
+
+// Targets returns registered targets, sorted by name.
+func (m *DriftMonitor) Targets() []DriftTarget {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	targets := make([]DriftTarget, 0, len(m.targets))
+	for _, s := range m.targets {
+		targets = append(targets, s.target)
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		return targets[i].Name < targets[j].Name
+	})
+	return targets
+}
+
+// signal wakes Run up, to reschedule checks. It's called with mutex locked.
+func (m *DriftMonitor) signal() {
+	if m.wake == nil {
+		m.wake = make(chan struct{}, 1)
+	}
+	select {
+	case m.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run checks registered targets at their intervals, until context is canceled, its error is returned then.
+// Errors of checks are passed to ErrorHandler, and targets are checked again at next interval.
+func (m *DriftMonitor) Run(ctx context.Context) error {
+	for {
+		m.mu.Lock()
+		if m.wake == nil {
+			m.wake = make(chan struct{}, 1)
+		}
+		wake := m.wake
+		now := time.Now()
+		var due []DriftTarget
+		next := now.Add(time.Hour)
+		for _, s := range m.targets {
+			if !s.next.After(now) {
+				due = append(due, s.target)
+				s.next = now.Add(s.target.Interval)
+			}
+			if s.next.Before(next) {
+				next = s.next
+			}
+		}
+		m.mu.Unlock()
+
+		sort.Slice(due, func(i, j int) bool {
+			return due[i].Name < due[j].Name
+		})
+		for _, t := range due {
+			if _, err := m.check(ctx, t); err != nil && ctx.Err() == nil && m.ErrorHandler != nil {
+				m.ErrorHandler(t.Name, err)
+			}
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		case <-wake:
+			timer.Stop()
+		}
+	}
+}
+
+// Check checks registered target immediately, and returns change of its types, or nil if types didn't change.
+func (m *DriftMonitor) Check(ctx context.Context, name string) (*DriftEvent, error) {
+	m.mu.Lock()
+	s, ok := m.targets[name]
+	m.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown drift target: %s", name)
+	}
+	return m.check(ctx, s.target)
+}
+
+func (m *DriftMonitor) check(ctx context.Context, t DriftTarget) (*DriftEvent, error) {
+	body, err := m.fetch(ctx, t)
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", t.URL, err)
+	}
+	if t.Config.RootName == "" {
+		t.Config.RootName = t.Name
+	}
+	service := m.Service
+	if service == nil {
+		service = &Service{}
+	}
+	if err := service.admit(ctx, int64(len(body))); err != nil {
+		return nil, err
+	}
+	p, err := service.parse(ctx, t.Config, t.Profile, [][]byte{body})
+	if err != nil {
+		return nil, err
+	}
+	schema := p.Schema()
+
+	store := m.Store
+	if store == nil {
+		store = &m.memory
+	}
+	base, err := store.Load(t.Name)
+	if err != nil {
+		return nil, fmt.Errorf("loading types of %s: %w", t.Name, err)
+	}
+	event, merged, err := driftEvent(t, base, schema)
+	if err != nil {
+		return nil, err
+	}
+	if merged == nil {
+		return nil, nil
+	}
+
+	if event != nil {
+		if m.OnDrift != nil {
+			m.OnDrift(*event)
+		}
+		if t.Webhook != "" {
+			hook := httpJSONRequest{client: m.Client, method: http.MethodPost, url: t.Webhook,
+				contentType: "application/json", accept: "application/json", body: event}
+			if err := hook.do(ctx, nil); err != nil {
+				// Types aren't stored, so the same drift is reported again by next check.
+				return event, fmt.Errorf("sending drift of %s to webhook: %w", t.Name, err)
+			}
+		}
+	}
+	if err := store.Save(t.Name, merged); err != nil {
+		return event, fmt.Errorf("storing types of %s: %w", t.Name, err)
+	}
+	return event, nil
+}
+
+// defaultDriftMaxResponseSize is a default limit of size of responses of targets checked by DriftMonitor.
+const defaultDriftMaxResponseSize = 10 << 20
+
+func (m *DriftMonitor) maxResponseSize() int64 {
+	if m.MaxResponseSize <= 0 {
+		return defaultDriftMaxResponseSize
+	}
+	return m.MaxResponseSize
+}
+
+// fetch returns response body of target.
+func (m *DriftMonitor) fetch(ctx context.Context, t DriftTarget) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, t.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	for key, values := range t.Header {
+		for _, v := range values {
+			req.Header.Add(key, v)
+		}
+	}
+	req.Header.Set("Accept", "application/json")
+
+	client := m.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	limit := m.maxResponseSize()
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		return nil, httpError{status: resp.StatusCode, message: http.StatusText(resp.StatusCode)}
+	}
+	if int64(len(body)) > limit {
+		return nil, fmt.Errorf("%w: response of %s is larger than %d bytes", ErrInputTooLarge, t.Name, limit)
+	}
+	return body, nil
+}
+
+// driftEvent merges types of checked response into base types, and returns change from base types, or nil if
+// there is no base, or types didn't change, see driftChanges. Merged types are returned to be stored, they are nil
+// if they are the same as base types.
+func driftEvent(t DriftTarget, base, schema *Schema) (*DriftEvent, *Schema, error) {
+	if base == nil {
+		return nil, schema, nil
+	}
+	merged, err := mergeSamples(base, schema)
+	if err != nil {
+		return nil, nil, err
+	}
+	baseIR, err := MarshalIR(base)
+	if err != nil {
+		return nil, nil, err
+	}
+	ir, err := MarshalIR(merged)
+	if err != nil {
+		return nil, nil, err
+	}
+	if bytes.Equal(baseIR, ir) {
+		return nil, nil, nil
+	}
+	changes := driftChanges(base, merged)
+	if len(changes) == 0 {
+		return nil, merged, nil
+	}
+
+	baseCode, err := GoEmitter{}.Emit(base)
+	if err != nil {
+		return nil, nil, err
+	}
+	code, err := GoEmitter{}.Emit(merged)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &DriftEvent{
+		Target:   t.Name,
+		URL:      t.URL,
+		Time:     time.Now().UTC(),
+		BaseCode: string(baseCode),
+		Code:     string(code),
+		Diff:     DiffLines(string(baseCode), string(code)),
+		Changes:  changes,
+		Schema:   ir,
+	}, merged, nil
+}
+
+// driftChanges returns changes of base types in types merged from base types and other types, see mergeSamples:
+// required keys of base types, which are optional in merged types, new keys, and values of other kinds.
+// Nullability of values isn't a change.
+func driftChanges(base, merged *Schema) []string {
+	var changes []string
+	seen := make(map[[2]string]bool)
+	var walk func(path string, x, y *SchemaType)
+	walk = func(path string, x, y *SchemaType) {
+		x, y = nonNullSchemaType(x), nonNullSchemaType(y)
+		if x.Kind == SchemaNamed && y.Kind == SchemaNamed {
+			key := [2]string{x.Name, y.Name}
+			if seen[key] {
+				return
+			}
+			seen[key] = true
+		}
+		if x.Kind == SchemaNamed {
+			x = nonNullSchemaType(base.Types[x.Name])
+		}
+		if y.Kind == SchemaNamed {
+			y = nonNullSchemaType(merged.Types[y.Name])
+		}
+
+		if x.Kind != y.Kind || x.Bits != y.Bits || x.Format != y.Format {
+			changes = append(changes, fmt.Sprintf("%s: %s changed to %s", path, driftKind(x), driftKind(y)))
+			return
+		}
+		switch x.Kind {
+		case SchemaSlice:
+			walk(path, x.Elem, y.Elem)
+		case SchemaMap:
+			if mapKeyKind(x) != mapKeyKind(y) {
+				changes = append(changes, fmt.Sprintf("%s: %s keys changed to %s", path, mapKeyKind(x), mapKeyKind(y)))
+			}
+			walk(path, x.Elem, y.Elem)
+		case SchemaStruct:
+			for _, f := range x.Fields {
+				g := schemaFieldByKey(y, f.Key)
+				switch {
+				case g == nil || !f.OmitEmpty && g.OmitEmpty:
+					changes = append(changes, childPath(path, f.Key)+": removed")
+				default:
+					walk(childPath(path, f.Key), f.Type, g.Type)
+				}
+			}
+			for _, g := range y.Fields {
+				if schemaFieldByKey(x, g.Key) == nil {
+					changes = append(changes, childPath(path, g.Key)+": added")
+				}
+			}
+		}
+	}
+	walk(rootPath, &SchemaType{Kind: SchemaNamed, Name: base.Root}, &SchemaType{Kind: SchemaNamed, Name: merged.Root})
+	return changes
+}
+
+// driftKind describes kind of type in changes, like "float32" or "string (uuid)".
+func driftKind(t *SchemaType) string {
+	switch {
+	case t.Bits != 0:
+		return fmt.Sprintf("%s%d", t.Kind, t.Bits)
+	case t.Format != "":
+		return fmt.Sprintf("%s (%s)", t.Kind, t.Format)
+	}
+	return string(t.Kind)
+}
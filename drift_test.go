@@ -0,0 +1,278 @@
+package json2go
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDriftMonitorCheck(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	response := `{"id":1,"name":"a"}`
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		_, _ = w.Write([]byte(response))
+	}))
+	defer api.Close()
+
+	var events []DriftEvent
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var e DriftEvent
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&e))
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, e)
+	}))
+	defer hook.Close()
+
+	store := DirDriftStore(t.TempDir())
+	m := &DriftMonitor{Store: store}
+	target := DriftTarget{
+		Name:     "User",
+		URL:      api.URL,
+		Header:   http.Header{"Authorization": {"Bearer token"}},
+		Interval: time.Hour,
+		Webhook:  hook.URL,
+	}
+	require.NoError(t, m.Register(target))
+
+	e, err := m.Check(context.Background(), "User")
+	require.NoError(t, err)
+	assert.Nil(t, e)
+	e, err = m.Check(context.Background(), "User")
+	require.NoError(t, err)
+	assert.Nil(t, e)
+
+	mu.Lock()
+	response = `{"id":"1","name":"a","email":"a@example.com"}`
+	mu.Unlock()
+	e, err = m.Check(context.Background(), "User")
+	require.NoError(t, err)
+	require.NotNil(t, e)
+	assert.Equal(t, "User", e.Target)
+	assert.Equal(t, api.URL, e.URL)
+	assert.Equal(t, ` type User struct {
+-	ID   int    `+"`json:\"id\"`"+`
+-	Name string `+"`json:\"name\"`"+`
++	Email string      `+"`json:\"email,omitempty\"`"+`
++	ID    interface{} `+"`json:\"id\"`"+`
++	Name  string      `+"`json:\"name\"`"+`
+ }
+ 
+`, e.Diff)
+	assert.Equal(t, []string{"$.id: int changed to any", "$.email: added"}, e.Changes)
+	mu.Lock()
+	require.Len(t, events, 1)
+	assert.Equal(t, e.Diff, events[0].Diff)
+	mu.Unlock()
+	stored, err := store.Load("User")
+	require.NoError(t, err)
+	ir, err := MarshalIR(stored)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(e.Schema), string(ir))
+
+	// New monitor compares responses with stored types.
+	m = &DriftMonitor{Store: store}
+	require.NoError(t, m.Register(target))
+	e, err = m.Check(context.Background(), "User")
+	require.NoError(t, err)
+	assert.Nil(t, e)
+
+	target.Header = nil
+	require.NoError(t, m.Register(target))
+	_, err = m.Check(context.Background(), "User")
+	assert.EqualError(t, err, "fetching "+api.URL+": 401 Unauthorized")
+	_, err = m.Check(context.Background(), "Missing")
+	assert.Error(t, err)
+}
+
+func TestDriftMonitorWebhookRetry(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	response, hookStatus, deliveries := `{"id":1}`, http.StatusServiceUnavailable, 0
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		_, _ = w.Write([]byte(response))
+	}))
+	defer api.Close()
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		deliveries++
+		w.WriteHeader(hookStatus)
+	}))
+	defer hook.Close()
+
+	m := &DriftMonitor{}
+	require.NoError(t, m.Register(DriftTarget{Name: "User", URL: api.URL, Interval: time.Hour, Webhook: hook.URL}))
+	e, err := m.Check(context.Background(), "User")
+	require.NoError(t, err)
+	assert.Nil(t, e)
+
+	mu.Lock()
+	response = `{"id":"1"}`
+	mu.Unlock()
+	e, err = m.Check(context.Background(), "User")
+	assert.Error(t, err)
+	assert.NotNil(t, e)
+
+	// Drift wasn't delivered, so it's reported again.
+	mu.Lock()
+	hookStatus = http.StatusOK
+	mu.Unlock()
+	e, err = m.Check(context.Background(), "User")
+	require.NoError(t, err)
+	assert.NotNil(t, e)
+	e, err = m.Check(context.Background(), "User")
+	require.NoError(t, err)
+	assert.Nil(t, e)
+
+	mu.Lock()
+	assert.Equal(t, 2, deliveries)
+	mu.Unlock()
+}
+
+func TestDriftMonitorMaxResponseSize(t *testing.T) {
+	t.Parallel()
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"id":1,"name":"abcdef"}`))
+	}))
+	defer api.Close()
+
+	m := &DriftMonitor{MaxResponseSize: 10}
+	require.NoError(t, m.Register(DriftTarget{Name: "User", URL: api.URL, Interval: time.Hour}))
+	_, err := m.Check(context.Background(), "User")
+	assert.True(t, errors.Is(err, ErrInputTooLarge), "%v", err)
+
+	m.MaxResponseSize = 100
+	_, err = m.Check(context.Background(), "User")
+	assert.NoError(t, err)
+}
+
+func TestDriftMonitorRun(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	requests := 0
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+		if requests > 2 {
+			_, _ = w.Write([]byte(`{"id":1,"active":true}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":1}`))
+	}))
+	defer api.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	drifts := make(chan DriftEvent, 1)
+	m := &DriftMonitor{OnDrift: func(e DriftEvent) {
+		drifts <- e
+		cancel()
+	}}
+	require.NoError(t, m.Register(DriftTarget{Name: "Status", URL: api.URL, Interval: 10 * time.Millisecond}))
+
+	assert.Equal(t, context.Canceled, m.Run(ctx))
+	e := <-drifts
+	assert.Contains(t, e.Diff, "+\tActive bool `json:\"active,omitempty\"`")
+}
+
+func TestDriftMonitorMergesResponses(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	response := `{"id":1,"name":"a","tags":["x"],"parent":{"id":2}}`
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		_, _ = w.Write([]byte(response))
+	}))
+	defer api.Close()
+
+	m := &DriftMonitor{}
+	require.NoError(t, m.Register(DriftTarget{Name: "User", URL: api.URL, Interval: time.Hour}))
+	check := func(body string) *DriftEvent {
+		mu.Lock()
+		response = body
+		mu.Unlock()
+		e, err := m.Check(context.Background(), "User")
+		require.NoError(t, err)
+		return e
+	}
+	require.Nil(t, check(`{"id":1,"name":"a","tags":["x"],"parent":{"id":2}}`))
+
+	// Nulls and empty arrays are values of stored types.
+	assert.Nil(t, check(`{"id":1,"name":null,"tags":[],"parent":null}`))
+	assert.Nil(t, check(`{"id":1,"name":"a","tags":["x"],"parent":{"id":2}}`))
+
+	e := check(`{"id":1,"tags":["x"],"parent":{"id":2,"name":"b"}}`)
+	require.NotNil(t, e)
+	assert.Equal(t, []string{"$.name: removed", "$.parent.name: added"}, e.Changes)
+	// Keys, which are optional already, aren't changes.
+	assert.Nil(t, check(`{"id":1,"tags":["x"],"parent":{"id":2}}`))
+	assert.Nil(t, check(`{"id":1,"name":"a","tags":["x"],"parent":{"id":2,"name":"b"}}`))
+
+	e = check(`{"id":1.5,"tags":["2006-01-02T15:04:05Z"],"parent":{"id":2}}`)
+	require.NotNil(t, e)
+	assert.Equal(t, []string{"$.id: int changed to float64"}, e.Changes)
+	e = check(`{"id":1,"tags":[1],"parent":{"id":2}}`)
+	require.NotNil(t, e)
+	assert.Equal(t, []string{"$.tags: string changed to any"}, e.Changes)
+}
+
+func TestDriftTargets(t *testing.T) {
+	t.Parallel()
+
+	os.Setenv("JSON2GO_DRIFT_TEST_TOKEN", "secret")
+	targets, err := ReadDriftTargets(strings.NewReader(`
+- name: users
+  url: https://api.example.com/users
+  header:
+    Authorization: ["Bearer ${JSON2GO_DRIFT_TEST_TOKEN}"]
+  interval: 1h
+  config:
+    rootName: User
+  webhook: https://hooks.example.com/drift
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []DriftTarget{{
+		Name:     "users",
+		URL:      "https://api.example.com/users",
+		Header:   http.Header{"Authorization": {"Bearer secret"}},
+		Interval: time.Hour,
+		Config:   Config{RootName: "User"},
+		Webhook:  "https://hooks.example.com/drift",
+	}}, targets)
+
+	var m DriftMonitor
+	require.NoError(t, m.Register(targets[0]))
+	assert.Equal(t, targets, m.Targets())
+	m.Unregister("users")
+	assert.Empty(t, m.Targets())
+
+	assert.Error(t, m.Register(DriftTarget{Name: "a", URL: "http://localhost"}))
+	assert.Error(t, m.Register(DriftTarget{Name: "../a", URL: "http://localhost", Interval: time.Second}))
+}
@@ -0,0 +1,107 @@
+package json2go
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// DuplicateKeys is a policy of handling duplicate keys in json objects.
+type DuplicateKeys int
+
+const (
+	// DuplicateKeysLastWins uses last value of duplicate key, like encoding/json.
+	DuplicateKeysLastWins DuplicateKeys = iota
+	// DuplicateKeysFirstWins uses first value of duplicate key.
+	DuplicateKeysFirstWins
+	// DuplicateKeysError makes parsing input with duplicate keys fail.
+	DuplicateKeysError
+	// DuplicateKeysMergeTypes uses all values of duplicate key to infer its type.
+	DuplicateKeysMergeTypes
+)
+
+// duplicateValues are values of duplicate key. All of them are used to grow a node.
+type duplicateValues []interface{}
+
+// decodeWithDuplicateKeys decodes json document to generic value, handling duplicate keys according to policy.
+// warn is called for each duplicate key.
+func decodeWithDuplicateKeys(data []byte, policy DuplicateKeys, warn func(string)) (interface{}, error) {
+	jd := json.NewDecoder(bytes.NewReader(data))
+	v, err := decodeValue(jd, rootPath, policy, warn)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := jd.Token(); err != io.EOF {
+		return nil, fmt.Errorf("invalid data after top-level value")
+	}
+
+	return v, nil
+}
+
+func decodeValue(jd *json.Decoder, path string, policy DuplicateKeys, warn func(string)) (interface{}, error) {
+	t, err := jd.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t {
+	case json.Delim('{'):
+		obj := make(map[string]interface{})
+		for jd.More() {
+			kt, err := jd.Token()
+			if err != nil {
+				return nil, err
+			}
+			key, ok := kt.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid object key: %v", kt)
+			}
+			kpath := childPath(path, key)
+			v, err := decodeValue(jd, kpath, policy, warn)
+			if err != nil {
+				return nil, err
+			}
+
+			prev, duplicate := obj[key]
+			if !duplicate {
+				obj[key] = v
+				continue
+			}
+
+			switch policy {
+			case DuplicateKeysError:
+				return nil, fmt.Errorf("%s: %w", kpath, ErrDuplicateKey)
+			case DuplicateKeysFirstWins:
+			case DuplicateKeysMergeTypes:
+				if dv, ok := prev.(duplicateValues); ok {
+					obj[key] = append(dv, v)
+				} else {
+					obj[key] = duplicateValues{prev, v}
+				}
+			default:
+				obj[key] = v
+			}
+			warn(fmt.Sprintf("%s: duplicate key", kpath))
+		}
+		if _, err := jd.Token(); err != nil {
+			return nil, err
+		}
+		return obj, nil
+	case json.Delim('['):
+		arr := make([]interface{}, 0)
+		for jd.More() {
+			v, err := decodeValue(jd, path, policy, warn)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		}
+		if _, err := jd.Token(); err != nil {
+			return nil, err
+		}
+		return arr, nil
+	}
+
+	return t, nil
+}
@@ -0,0 +1,112 @@
+package json2go
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeWithDuplicateKeys(t *testing.T) {
+	t.Parallel()
+
+	input := `{"a":1,"b":{"c":"x","c":true},"a":"y","d":[{"e":1,"e":2}]}`
+
+	testCases := []struct {
+		name        string
+		policy      DuplicateKeys
+		expected    interface{}
+		expectedErr bool
+	}{
+		{
+			name:   "last wins",
+			policy: DuplicateKeysLastWins,
+			expected: map[string]interface{}{
+				"a": "y",
+				"b": map[string]interface{}{"c": true},
+				"d": []interface{}{map[string]interface{}{"e": 2.0}},
+			},
+		},
+		{
+			name:   "first wins",
+			policy: DuplicateKeysFirstWins,
+			expected: map[string]interface{}{
+				"a": 1.0,
+				"b": map[string]interface{}{"c": "x"},
+				"d": []interface{}{map[string]interface{}{"e": 1.0}},
+			},
+		},
+		{
+			name:   "merge types",
+			policy: DuplicateKeysMergeTypes,
+			expected: map[string]interface{}{
+				"a": duplicateValues{1.0, "y"},
+				"b": map[string]interface{}{"c": duplicateValues{"x", true}},
+				"d": []interface{}{map[string]interface{}{"e": duplicateValues{1.0, 2.0}}},
+			},
+		},
+		{
+			name:        "error",
+			policy:      DuplicateKeysError,
+			expectedErr: true,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			var warnings []string
+			v, err := decodeWithDuplicateKeys([]byte(input), tc.policy, func(w string) {
+				warnings = append(warnings, w)
+			})
+			if tc.expectedErr {
+				assert.EqualError(t, err, "$.b.c: duplicate key")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, v)
+			assert.Equal(t, []string{"$.b.c: duplicate key", "$.a: duplicate key", "$.d.e: duplicate key"}, warnings)
+		})
+	}
+}
+
+func TestDecodeWithDuplicateKeysMatchesUnmarshal(t *testing.T) {
+	t.Parallel()
+
+	inputs := []string{
+		`null`,
+		`"text"`,
+		`[1, 2.5, "x", [], {}, [null, true]]`,
+		`{"a": {"b": [{"c": 1}]}, "d": false}`,
+		`{"a": 1} x`,
+		`{"a": `,
+		`[1, 2`,
+	}
+
+	for _, in := range inputs {
+		var expected interface{}
+		expectedErr := json.Unmarshal([]byte(in), &expected)
+
+		v, err := decodeWithDuplicateKeys([]byte(in), DuplicateKeysError, func(string) {})
+		if expectedErr != nil {
+			assert.Error(t, err, in)
+			continue
+		}
+		require.NoError(t, err, in)
+		assert.Equal(t, expected, v, in)
+	}
+}
+
+func TestParserDuplicateKeys(t *testing.T) {
+	t.Parallel()
+
+	parser := NewJSONParser(baseTypeName, OptDuplicateKeys(DuplicateKeysMergeTypes))
+	for i := 0; i < 2; i++ {
+		err := parser.FeedBytes([]byte(`{"a":1,"a":1.5,"b":"x"}`))
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, normalizeStr("type Document struct {\n A float64 `json:\"a\"`\n B string `json:\"b\"`\n}"), normalizeStr(parser.String()))
+	assert.Equal(t, []string{"$.a: duplicate key"}, parser.Warnings())
+}
@@ -0,0 +1,133 @@
+package json2go
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// elasticsearchIgnoreAbove is a length of longest strings indexed as keywords, like in dynamic mappings of Elasticsearch.
+const elasticsearchIgnoreAbove = 256
+
+// isKeywordString checks if string is a short token without whitespace, like id, code or enum value,
+// indexed by Elasticsearch as keyword.
+func isKeywordString(s string) bool {
+	return len(s) <= elasticsearchIgnoreAbove && !strings.ContainsAny(s, " \t\r\n")
+}
+
+// isTextValue checks if value is a string, or array with string, that isn't a keyword.
+func isTextValue(v interface{}) bool {
+	switch typedValue := v.(type) {
+	case []interface{}:
+		for _, el := range typedValue {
+			if isTextValue(el) {
+				return true
+			}
+		}
+	case string:
+		return !isKeywordString(typedValue)
+	}
+	return false
+}
+
+// ElasticsearchMapping returns Elasticsearch index mapping describing parsed documents, like {"mappings": {...}}.
+// Unlike dynamic mapping, it's derived from all parsed values:
+//
+//   - strings without whitespace of at most 256 characters are keywords, other strings are text with keyword subfield,
+//   - times are dates, and so are Unix times with units of OptEpochTimes, in seconds or milliseconds,
+//   - arrays of objects are nested, so their attributes are queried together,
+//   - maps are flattened, so their keys don't add fields,
+//   - values of different kinds aren't indexed.
+//
+// Extracted types are inlined, recursive values aren't indexed.
+func (p *JSONParser) ElasticsearchMapping() ([]byte, error) {
+	nodes, err := p.outputNodes()
+	if err != nil {
+		return nil, err
+	}
+	types := make(map[string]*node, len(nodes))
+	for _, n := range nodes {
+		types[n.name] = n
+	}
+
+	m := elasticsearchMapping{opts: p.opts, types: types, expanding: map[string]bool{nodes[0].name: true}}
+	mappings := m.object(nodes[0])
+	if nodes[0].t.id() != nodeTypeObject.id() {
+		// Documents must be objects, other values aren't indexed.
+		mappings = map[string]interface{}{"dynamic": false}
+	}
+	return json.MarshalIndent(map[string]interface{}{"mappings": mappings}, "", "  ")
+}
+
+// elasticsearchMapping builds mappings of fields of nodes.
+type elasticsearchMapping struct {
+	opts  options
+	types map[string]*node
+	// expanding are extracted types being inlined.
+	expanding map[string]bool
+}
+
+// object returns mapping of object node with properties of its attributes.
+func (m elasticsearchMapping) object(n *node) map[string]interface{} {
+	props := make(map[string]interface{})
+	for _, c := range n.children {
+		if field := m.field(c); field != nil {
+			props[c.key] = field
+		}
+	}
+	mapping := map[string]interface{}{"properties": props}
+	if n.arrayLevel > 0 && !n.root {
+		mapping["type"] = "nested"
+	}
+	return mapping
+}
+
+// field returns mapping of node's values, or nil if node has only nulls. Arrays have mappings of their elements.
+func (m elasticsearchMapping) field(n *node) map[string]interface{} {
+	switch n.t.id() {
+	case nodeTypeBool.id():
+		return map[string]interface{}{"type": "boolean"}
+	case nodeTypeInt.id():
+		if unit, ok := astEpochUnit(n, m.opts.epochUnits); ok && unit == EpochSeconds {
+			return map[string]interface{}{"type": "date", "format": "epoch_second"}
+		} else if ok && unit == EpochMillis {
+			return map[string]interface{}{"type": "date", "format": "epoch_millis"}
+		}
+		return map[string]interface{}{"type": "long"}
+	case nodeTypeFloat.id():
+		return map[string]interface{}{"type": "double"}
+	case nodeTypeTime.id():
+		return map[string]interface{}{"type": "date"}
+	case nodeTypeString.id():
+		if !n.hasText {
+			return map[string]interface{}{"type": "keyword"}
+		}
+		return map[string]interface{}{
+			"type": "text",
+			"fields": map[string]interface{}{
+				"keyword": map[string]interface{}{"type": "keyword", "ignore_above": elasticsearchIgnoreAbove},
+			},
+		}
+	case nodeTypeObject.id():
+		return m.object(n)
+	case nodeTypeMap.id():
+		return map[string]interface{}{"type": "flattened"}
+	case nodeTypeExtracted.id():
+		t, ok := m.types[n.externalTypeID]
+		if !ok || m.expanding[n.externalTypeID] {
+			// Custom types and recursive values.
+			break
+		}
+		m.expanding[n.externalTypeID] = true
+		defer delete(m.expanding, n.externalTypeID)
+
+		expanded := *t
+		expanded.key = n.key
+		expanded.root = false
+		expanded.arrayLevel = n.arrayLevel
+		return m.field(&expanded)
+	case nodeTypeInit.id():
+		return nil
+	}
+	// Values of different kinds are kept in source only.
+	return map[string]interface{}{"type": "object", "enabled": false}
+}
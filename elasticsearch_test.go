@@ -0,0 +1,91 @@
+package json2go
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserElasticsearchMapping(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		opts     []JSONParserOpt
+		inputs   []string
+		expected string
+	}{
+		{
+			name:     "keyword",
+			inputs:   []string{`{"v":"A-1"}`, `{"v":"b"}`},
+			expected: `{"type":"keyword"}`,
+		},
+		{
+			name:     "text",
+			inputs:   []string{`{"v":"A-1"}`, `{"v":"hello world"}`},
+			expected: `{"type":"text","fields":{"keyword":{"type":"keyword","ignore_above":256}}}`,
+		},
+		{
+			name:     "date",
+			inputs:   []string{`{"v":"2021-01-01T00:00:00Z"}`},
+			expected: `{"type":"date"}`,
+		},
+		{
+			name:     "unix time",
+			opts:     []JSONParserOpt{OptEpochTimes(EpochMillis)},
+			inputs:   []string{`{"v":{"created_at":1609459200123}}`},
+			expected: `{"properties":{"created_at":{"type":"date","format":"epoch_millis"}}}`,
+		},
+		{
+			name:     "numbers",
+			inputs:   []string{`{"v":[1,2.5]}`},
+			expected: `{"type":"double"}`,
+		},
+		{
+			name:     "nested",
+			inputs:   []string{`{"v":[{"a":true}]}`},
+			expected: `{"type":"nested","properties":{"a":{"type":"boolean"}}}`,
+		},
+		{
+			name:     "extracted",
+			opts:     []JSONParserOpt{OptExtractCommonTypes(true)},
+			inputs:   []string{`{"v":{"a":{"b":1,"c":"x"},"d":{"b":2,"c":"y"}}}`},
+			expected: `{"properties":{"a":{"properties":{"b":{"type":"long"},"c":{"type":"keyword"}}},"d":{"properties":{"b":{"type":"long"},"c":{"type":"keyword"}}}}}`,
+		},
+		{
+			name:     "map",
+			opts:     []JSONParserOpt{OptMakeMaps(true, 2)},
+			inputs:   []string{`{"v":{"a":1,"b":2,"c":3}}`},
+			expected: `{"type":"flattened"}`,
+		},
+		{
+			name:     "different kinds",
+			inputs:   []string{`{"v":1}`, `{"v":"a"}`},
+			expected: `{"type":"object","enabled":false}`,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			parser := NewJSONParser(baseTypeName, tc.opts...)
+			for _, input := range tc.inputs {
+				require.NoError(t, parser.FeedBytes([]byte(input)))
+			}
+			out, err := parser.ElasticsearchMapping()
+			require.NoError(t, err)
+
+			var mapping struct {
+				Mappings struct {
+					Properties map[string]json.RawMessage `json:"properties"`
+				} `json:"mappings"`
+			}
+			require.NoError(t, json.Unmarshal(out, &mapping))
+			assert.JSONEq(t, tc.expected, string(mapping.Mappings.Properties["v"]))
+		})
+	}
+}
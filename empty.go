@@ -0,0 +1,86 @@
+package json2go
+
+import "fmt"
+
+// EmptyValues is a policy of values seen only as empty objects, or empty arrays, in which element types are unknown.
+type EmptyValues int
+
+const (
+	// EmptyValuesDefault uses struct{} for empty objects and []interface{} for empty arrays, which are skipped
+	// with OptSkipEmptyKeys.
+	EmptyValuesDefault EmptyValues = iota
+	// EmptyValuesStruct uses struct{} for empty objects and elements of empty arrays, like `[]struct{}`.
+	EmptyValuesStruct
+	// EmptyValuesMap uses map[string]interface{} for empty objects and elements of empty arrays.
+	EmptyValuesMap
+	// EmptyValuesRaw uses json.RawMessage for empty objects and empty arrays, so values are decoded later.
+	EmptyValuesRaw
+	// EmptyValuesSkip skips keys of empty objects and empty arrays, warning is reported for each of them.
+	// Empty values, which aren't values of object keys, use defaults.
+	EmptyValuesSkip
+)
+
+// ParseEmptyValues returns empty values policy by name: "default", "struct", "map", "raw" or "skip".
+// Empty name means default.
+func ParseEmptyValues(name string) (EmptyValues, error) {
+	switch name {
+	case "", "default":
+		return EmptyValuesDefault, nil
+	case "struct":
+		return EmptyValuesStruct, nil
+	case "map":
+		return EmptyValuesMap, nil
+	case "raw":
+		return EmptyValuesRaw, nil
+	case "skip":
+		return EmptyValuesSkip, nil
+	}
+	return EmptyValuesDefault, fmt.Errorf("unknown empty values policy: %s", name)
+}
+
+// applyEmptyValues applies policy to descendants of node seen only as empty objects or empty arrays.
+// warn is called for each skipped key.
+func applyEmptyValues(n *node, policy EmptyValues, warn func(string)) {
+	if policy == EmptyValuesDefault {
+		return
+	}
+
+	children := make([]*node, 0, len(n.children))
+	for _, c := range n.children {
+		emptyObject := c.t == nodeTypeObject && len(c.children) == 0 && c.seenKinds&^kindArray == kindObject
+		emptyArray := c.t.id() == nodeTypeInit.id() && c.arrayLevel > 0
+		if !emptyObject && !emptyArray {
+			applyEmptyValues(c, policy, warn)
+			children = append(children, c)
+			continue
+		}
+
+		switch policy {
+		case EmptyValuesStruct:
+			c.t = nodeTypeObject
+		case EmptyValuesMap:
+			value := newNode("")
+			value.t = nodeTypeInterface
+			value.setPath(childPath(c.path, mapValuePathKey))
+			c.t = nodeTypeMap
+			c.mapKeyType = mapKeyString
+			c.children = []*node{value}
+		case EmptyValuesRaw:
+			c.t = nodeTypeRawMessage
+			c.arrayLevel = 0
+			c.arrayWithNulls = false
+		case EmptyValuesSkip:
+			if n.t.id() == nodeTypeObject.id() {
+				values := "empty objects"
+				if emptyArray {
+					values = "empty arrays"
+				}
+				c.logf("key skipped, it has only %s", values)
+				warn(fmt.Sprintf("%s: key skipped, it has only %s", c.path, values))
+				continue
+			}
+		}
+		children = append(children, c)
+	}
+	n.children = children
+}
@@ -0,0 +1,124 @@
+package json2go
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEmptyValues(t *testing.T) {
+	t.Parallel()
+
+	for name, expected := range map[string]EmptyValues{
+		"":        EmptyValuesDefault,
+		"default": EmptyValuesDefault,
+		"struct":  EmptyValuesStruct,
+		"map":     EmptyValuesMap,
+		"raw":     EmptyValuesRaw,
+		"skip":    EmptyValuesSkip,
+	} {
+		policy, err := ParseEmptyValues(name)
+		require.NoError(t, err)
+		assert.Equal(t, expected, policy, name)
+	}
+	_, err := ParseEmptyValues("none")
+	assert.Error(t, err)
+}
+
+func TestParserEmptyValues(t *testing.T) {
+	t.Parallel()
+
+	const input = `{"a":{},"b":[],"c":[null],"d":{"e":[],"f":1}}`
+	testCases := []struct {
+		name     string
+		policy   EmptyValues
+		expected map[string]string
+		warnings []string
+	}{
+		{
+			name:   "default",
+			policy: EmptyValuesDefault,
+			expected: map[string]string{
+				"$.a": "struct{...}", "$.d": "struct{...}", "$.d.f": "int",
+			},
+		},
+		{
+			name:   "struct",
+			policy: EmptyValuesStruct,
+			expected: map[string]string{
+				"$.a": "struct{...}", "$.b": "[]struct{...}", "$.c": "[]*struct{...}", "$.d": "struct{...}",
+				"$.d.e": "[]struct{...}", "$.d.f": "int",
+			},
+		},
+		{
+			name:   "map",
+			policy: EmptyValuesMap,
+			expected: map[string]string{
+				"$.a": "map[string]interface{}", "$.a.*": "interface{}",
+				"$.b": "[]map[string]interface{}", "$.b.*": "interface{}",
+				"$.c": "[]map[string]interface{}", "$.c.*": "interface{}",
+				"$.d": "struct{...}", "$.d.e": "[]map[string]interface{}", "$.d.e.*": "interface{}", "$.d.f": "int",
+			},
+		},
+		{
+			name:   "raw",
+			policy: EmptyValuesRaw,
+			expected: map[string]string{
+				"$.a": "json.RawMessage", "$.b": "json.RawMessage", "$.c": "json.RawMessage", "$.d": "struct{...}",
+				"$.d.e": "json.RawMessage", "$.d.f": "int",
+			},
+		},
+		{
+			name:   "skip",
+			policy: EmptyValuesSkip,
+			expected: map[string]string{
+				"$.d": "struct{...}", "$.d.f": "int",
+			},
+			warnings: []string{
+				"$.a: key skipped, it has only empty objects",
+				"$.b: key skipped, it has only empty arrays",
+				"$.c: key skipped, it has only empty arrays",
+				"$.d.e: key skipped, it has only empty arrays",
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			parser := NewJSONParser(baseTypeName, OptEmptyValues(tc.policy), OptSkipEmptyKeys(true))
+			require.NoError(t, parser.FeedBytes([]byte(input)))
+			fields := make(map[string]string)
+			for _, f := range parser.Fields() {
+				fields[f.Path] = f.Type
+			}
+			assert.Equal(t, tc.expected, fields)
+			assert.Equal(t, tc.warnings, parser.Warnings())
+		})
+	}
+}
+
+func TestParserEmptyValuesCode(t *testing.T) {
+	t.Parallel()
+
+	for _, policy := range []EmptyValues{EmptyValuesStruct, EmptyValuesMap, EmptyValuesRaw} {
+		parser := NewJSONParser(baseTypeName, OptEmptyValues(policy))
+		require.NoError(t, parser.FeedBytes([]byte(`{"a":{},"b":[]}`)))
+
+		out := runGeneratedCode(t, parser, `
+	var d Document
+	if err := json.NewDecoder(os.Stdin).Decode(&d); err != nil {
+		panic(err)
+	}
+	out, err := json.Marshal(d)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(out))
+	`, `{"a":{},"b":[]}`)
+		assert.Equal(t, "{\"a\":{},\"b\":[]}\n", out, policy)
+	}
+}
@@ -0,0 +1,198 @@
+package json2go
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// InvalidUTF8 is a policy of bytes of input, which aren't valid UTF-8.
+type InvalidUTF8 int
+
+const (
+	// InvalidUTF8Replace replaces invalid bytes with U+FFFD replacement character, like encoding/json does.
+	InvalidUTF8Replace InvalidUTF8 = iota
+	// InvalidUTF8Skip removes invalid bytes.
+	InvalidUTF8Skip
+	// InvalidUTF8Latin1 decodes invalid bytes as ISO-8859-1 characters, which exports of legacy systems often have.
+	InvalidUTF8Latin1
+	// InvalidUTF8Error fails input with invalid bytes.
+	InvalidUTF8Error
+)
+
+// ParseInvalidUTF8 returns invalid UTF-8 policy by name: "replace", "skip", "latin1" or "error".
+// Empty name means replace.
+func ParseInvalidUTF8(name string) (InvalidUTF8, error) {
+	switch name {
+	case "", "replace":
+		return InvalidUTF8Replace, nil
+	case "skip":
+		return InvalidUTF8Skip, nil
+	case "latin1":
+		return InvalidUTF8Latin1, nil
+	case "error":
+		return InvalidUTF8Error, nil
+	}
+	return InvalidUTF8Replace, fmt.Errorf("unknown invalid UTF-8 policy: %s", name)
+}
+
+// Encodings of input detected by inputDecoder.
+const (
+	encodingUnknown = iota
+	encodingUTF8
+	encodingUTF16LE
+	encodingUTF16BE
+)
+
+// inputDecoder decodes input to UTF-8. Encoding is detected from first bytes: UTF-8 and UTF-16 byte order marks are
+// removed, and UTF-16 without byte order mark is recognized by zero bytes of first ASCII character of json.
+// It keeps state between parts of stream.
+type inputDecoder struct {
+	policy   InvalidUTF8
+	warn     func(string)
+	encoding int
+	offset   int // offset of next byte in input
+}
+
+// decode appends decoded data to out. Unless final, bytes at the end of data, which may begin character continued
+// in next part of stream, aren't consumed, their number is returned.
+func (d *inputDecoder) decode(out, data []byte, final bool) ([]byte, int, error) {
+	if d.encoding == encodingUnknown {
+		if len(data) < 3 && !final {
+			return out, len(data), nil
+		}
+		bom := 0
+		switch {
+		case bytes.HasPrefix(data, []byte{0xef, 0xbb, 0xbf}):
+			d.encoding, bom = encodingUTF8, 3
+		case bytes.HasPrefix(data, []byte{0xff, 0xfe}):
+			d.encoding, bom = encodingUTF16LE, 2
+		case bytes.HasPrefix(data, []byte{0xfe, 0xff}):
+			d.encoding, bom = encodingUTF16BE, 2
+		case len(data) >= 2 && data[0] != 0 && data[1] == 0:
+			d.encoding = encodingUTF16LE
+		case len(data) >= 2 && data[0] == 0 && data[1] != 0:
+			d.encoding = encodingUTF16BE
+		default:
+			d.encoding = encodingUTF8
+		}
+		data = data[bom:]
+		d.offset += bom
+	}
+
+	if d.encoding != encodingUTF8 {
+		return d.decodeUTF16(out, data, final)
+	}
+	return d.decodeUTF8(out, data, final)
+}
+
+func (d *inputDecoder) decodeUTF8(out, data []byte, final bool) ([]byte, int, error) {
+	if utf8.Valid(data) {
+		d.offset += len(data)
+		if len(out) == 0 {
+			// Valid input isn't copied.
+			return data, 0, nil
+		}
+		return append(out, data...), 0, nil
+	}
+
+	for i := 0; i < len(data); {
+		r, size := utf8.DecodeRune(data[i:])
+		if r != utf8.RuneError || size > 1 {
+			out = append(out, data[i:i+size]...)
+			i += size
+			continue
+		}
+		if !final && !utf8.FullRune(data[i:]) {
+			d.offset += i
+			return out, len(data) - i, nil
+		}
+
+		switch d.policy {
+		case InvalidUTF8Replace:
+			out = append(out, string(utf8.RuneError)...)
+			d.warn("input: invalid UTF-8 bytes replaced with U+FFFD")
+		case InvalidUTF8Skip:
+			d.warn("input: invalid UTF-8 bytes skipped")
+		case InvalidUTF8Latin1:
+			out = append(out, string(rune(data[i]))...)
+			d.warn("input: invalid UTF-8 bytes decoded as ISO-8859-1")
+		case InvalidUTF8Error:
+			return out, 0, invalidJSONError{err: fmt.Errorf("invalid UTF-8 byte 0x%02x at offset %d", data[i], d.offset+i)}
+		}
+		i++
+	}
+	d.offset += len(data)
+	return out, 0, nil
+}
+
+// decodeUTF16 decodes UTF-16 data. Unpaired surrogates are replaced with U+FFFD.
+func (d *inputDecoder) decodeUTF16(out, data []byte, final bool) ([]byte, int, error) {
+	units := make([]uint16, 0, len(data)/2)
+	for i := 0; i+1 < len(data); i += 2 {
+		if d.encoding == encodingUTF16LE {
+			units = append(units, uint16(data[i])|uint16(data[i+1])<<8)
+		} else {
+			units = append(units, uint16(data[i])<<8|uint16(data[i+1]))
+		}
+	}
+
+	pending := len(data) % 2
+	if last := len(units) - 1; !final && last >= 0 && units[last] >= 0xd800 && units[last] < 0xdc00 {
+		// High surrogate may be paired with first unit of next part.
+		units = units[:len(units)-1]
+		pending += 2
+	}
+	if final && pending > 0 {
+		return out, 0, invalidJSONError{err: errors.New("invalid UTF-16 input: odd number of bytes")}
+	}
+	for _, r := range utf16.Decode(units) {
+		out = append(out, string(r)...)
+	}
+	d.offset += len(data) - pending
+	return out, pending, nil
+}
+
+// decodeInput returns input decoded to UTF-8, see inputDecoder.
+func decodeInput(data []byte, policy InvalidUTF8, warn func(string)) ([]byte, error) {
+	d := inputDecoder{policy: policy, warn: warn}
+	out, _, err := d.decode(nil, data, true)
+	return out, err
+}
+
+// decodingReader is a reader of input decoded to UTF-8, see inputDecoder.
+type decodingReader struct {
+	r   io.Reader
+	d   inputDecoder
+	in  []byte // bytes read, which weren't decoded yet
+	out []byte // decoded bytes, which weren't returned yet
+	err error
+}
+
+func (r *decodingReader) Read(b []byte) (int, error) {
+	for len(r.out) == 0 && r.err == nil {
+		chunk := make([]byte, len(r.in)+len(b)+4)
+		copy(chunk, r.in)
+		n, err := r.r.Read(chunk[len(r.in):])
+		chunk = chunk[:len(r.in)+n]
+		r.err = err
+
+		var pending int
+		var decodeErr error
+		r.out, pending, decodeErr = r.d.decode(r.out[:0], chunk, err != nil)
+		r.in = append(r.in[:0], chunk[len(chunk)-pending:]...)
+		if decodeErr != nil {
+			r.err = decodeErr
+		}
+	}
+	if len(r.out) == 0 {
+		return 0, r.err
+	}
+
+	n := copy(b, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
@@ -0,0 +1,204 @@
+package json2go
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"testing"
+	"testing/iotest"
+	"unicode/utf16"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// encodeUTF16 returns s encoded as UTF-16, little endian if le, with optional byte order mark.
+func encodeUTF16(s string, le, bom bool) []byte {
+	var out []byte
+	units := utf16.Encode([]rune(s))
+	if bom {
+		units = append([]uint16{0xfeff}, units...)
+	}
+	for _, u := range units {
+		if le {
+			out = append(out, byte(u), byte(u>>8))
+		} else {
+			out = append(out, byte(u>>8), byte(u))
+		}
+	}
+	return out
+}
+
+func TestParseInvalidUTF8(t *testing.T) {
+	t.Parallel()
+
+	for name, expected := range map[string]InvalidUTF8{
+		"":        InvalidUTF8Replace,
+		"replace": InvalidUTF8Replace,
+		"skip":    InvalidUTF8Skip,
+		"latin1":  InvalidUTF8Latin1,
+		"error":   InvalidUTF8Error,
+	} {
+		policy, err := ParseInvalidUTF8(name)
+		require.NoError(t, err)
+		assert.Equal(t, expected, policy, name)
+	}
+	_, err := ParseInvalidUTF8("ignore")
+	assert.Error(t, err)
+}
+
+func TestDecodeInput(t *testing.T) {
+	t.Parallel()
+
+	const text = `{"name":"Zürich 😀"}`
+	testCases := []struct {
+		name     string
+		input    []byte
+		policy   InvalidUTF8
+		expected string
+		warning  string
+		err      bool
+	}{
+		{
+			name:     "utf-8",
+			input:    []byte(text),
+			expected: text,
+		},
+		{
+			name:     "utf-8 bom",
+			input:    append([]byte{0xef, 0xbb, 0xbf}, text...),
+			expected: text,
+		},
+		{
+			name:     "utf-16le bom",
+			input:    encodeUTF16(text, true, true),
+			expected: text,
+		},
+		{
+			name:     "utf-16be bom",
+			input:    encodeUTF16(text, false, true),
+			expected: text,
+		},
+		{
+			name:     "utf-16le",
+			input:    encodeUTF16(text, true, false),
+			expected: text,
+		},
+		{
+			name:     "utf-16be",
+			input:    encodeUTF16(text, false, false),
+			expected: text,
+		},
+		{
+			name:   "utf-16 odd length",
+			input:  append(encodeUTF16(text, true, true), 0),
+			policy: InvalidUTF8Replace,
+			err:    true,
+		},
+		{
+			name:     "replace",
+			input:    []byte("{\"name\":\"Z\xfcrich\"}"),
+			policy:   InvalidUTF8Replace,
+			expected: `{"name":"Z�rich"}`,
+			warning:  "input: invalid UTF-8 bytes replaced with U+FFFD",
+		},
+		{
+			name:     "skip",
+			input:    []byte("{\"name\":\"Z\xfcrich\"}"),
+			policy:   InvalidUTF8Skip,
+			expected: `{"name":"Zrich"}`,
+			warning:  "input: invalid UTF-8 bytes skipped",
+		},
+		{
+			name:     "latin1",
+			input:    []byte("{\"name\":\"Z\xfcrich 😀\"}"),
+			policy:   InvalidUTF8Latin1,
+			expected: `{"name":"Zürich 😀"}`,
+			warning:  "input: invalid UTF-8 bytes decoded as ISO-8859-1",
+		},
+		{
+			name:   "error",
+			input:  []byte("{\"name\":\"Z\xfcrich\"}"),
+			policy: InvalidUTF8Error,
+			err:    true,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var warnings []string
+			warn := func(msg string) {
+				warnings = append(warnings, msg)
+			}
+			out, err := decodeInput(tc.input, tc.policy, warn)
+			streamed, streamErr := ioutil.ReadAll(&decodingReader{
+				r: iotest.OneByteReader(bytes.NewReader(tc.input)),
+				d: inputDecoder{policy: tc.policy, warn: warn},
+			})
+			if tc.err {
+				assert.True(t, errors.Is(err, ErrInvalidJSON), err)
+				assert.True(t, errors.Is(streamErr, ErrInvalidJSON), streamErr)
+				return
+			}
+			require.NoError(t, err)
+			require.NoError(t, streamErr)
+			assert.Equal(t, tc.expected, string(out))
+			assert.Equal(t, tc.expected, string(streamed))
+			if tc.warning != "" {
+				assert.Contains(t, warnings, tc.warning)
+			} else {
+				assert.Empty(t, warnings)
+			}
+		})
+	}
+}
+
+func TestParserInputEncodings(t *testing.T) {
+	t.Parallel()
+
+	input := encodeUTF16(`{"name":"Zürich","id":1}`, true, true)
+
+	parser := NewJSONParser(baseTypeName)
+	require.NoError(t, parser.FeedBytes(input))
+	require.NoError(t, parser.FeedReader(bytes.NewReader(input)))
+	require.NoError(t, parser.FeedBytes([]byte("{\"name\":\"Z\xfcrich\",\"id\":2}")))
+	assert.Equal(t, []string{"input: invalid UTF-8 bytes replaced with U+FFFD"}, parser.Warnings())
+	assert.Equal(t, normalizeStr(`type Document struct {
+		ID   int    `+"`json:\"id\"`"+`
+		Name string `+"`json:\"name\"`"+`
+}`), normalizeStr(parser.String()))
+
+	parser = NewJSONParser(baseTypeName, OptInvalidUTF8(InvalidUTF8Error))
+	err := parser.FeedBytes([]byte("{\"name\":\"Z\xfcrich\"}"))
+	assert.EqualError(t, err, "invalid UTF-8 byte 0xfc at offset 10")
+}
+
+func TestConverterInputEncodings(t *testing.T) {
+	t.Parallel()
+
+	inputs := [][]byte{
+		append([]byte{0xef, 0xbb, 0xbf}, `{"name":"Zürich","id":1}`...),
+		encodeUTF16(`{"name":"Zürich","id":2}`, true, true),
+		encodeUTF16(`{"name":"Zürich","id":3}`, false, false),
+		[]byte("{\"name\":\"Z\xfcrich\",\"id\":4}"),
+	}
+	for _, opts := range [][]JSONParserOpt{nil, {OptFieldOrder(FieldOrderOriginal)}} {
+		c := NewConverter(baseTypeName, opts...)
+		for _, input := range inputs {
+			require.NoError(t, c.Feed(input))
+			require.NoError(t, c.FeedReaderContext(context.Background(), bytes.NewReader(input)))
+		}
+		assert.Equal(t, []string{"input: invalid UTF-8 bytes replaced with U+FFFD"}, c.Warnings())
+		code, err := c.Generate()
+		require.NoError(t, err)
+		assert.Contains(t, code, "Name string")
+	}
+
+	c := NewConverter(baseTypeName, OptInvalidUTF8(InvalidUTF8Error))
+	assert.EqualError(t, c.Feed([]byte("{\"name\":\"Z\xfcrich\"}")), "invalid UTF-8 byte 0xfc at offset 10")
+	assert.Error(t, c.FeedReaderContext(context.Background(), bytes.NewReader([]byte("{\"name\":\"Z\xfcrich\"}"))))
+}
@@ -0,0 +1,81 @@
+package json2go
+
+import "sort"
+
+const (
+	// maxEnumValues is a maximum number of distinct values of enums.
+	maxEnumValues = 10
+	// maxEnumValueLength is a maximum length of enum values.
+	maxEnumValueLength = 32
+)
+
+// growEnum counts string values, and adds them to distinct values of node, if node tracks enums, see isEnum.
+// Nulls and values of other kinds are ignored.
+func (n *node) growEnum(v interface{}) {
+	switch typedValue := v.(type) {
+	case []interface{}:
+		for _, el := range typedValue {
+			n.growEnum(el)
+		}
+	case string:
+		n.stringValues++
+		if !n.enums || n.enumInvalid {
+			return
+		}
+		if len(typedValue) == 0 || len(typedValue) > maxEnumValueLength || !isKeywordString(typedValue) {
+			n.enumInvalid = true
+			n.enumValues = nil
+			return
+		}
+		if i := sort.SearchStrings(n.enumValues, typedValue); i < len(n.enumValues) && n.enumValues[i] == typedValue {
+			return
+		}
+		n.enumValues = addEnumValues(n.enumValues, typedValue)
+		if len(n.enumValues) > maxEnumValues {
+			n.enumInvalid = true
+			n.enumValues = nil
+		}
+	}
+}
+
+// addEnumValues returns sorted set of values with added values. Set isn't changed.
+func addEnumValues(set []string, values ...string) []string {
+	result := append([]string(nil), set...)
+	for _, v := range values {
+		i := sort.SearchStrings(result, v)
+		if i < len(result) && result[i] == v {
+			continue
+		}
+		result = append(result, "")
+		copy(result[i+1:], result[i:])
+		result[i] = v
+	}
+	return result
+}
+
+// mergeEnum merges distinct string values of other node into values of node.
+func (n *node) mergeEnum(other *node) {
+	n.stringValues += other.stringValues
+	if n.enumInvalid || other.enumInvalid {
+		n.enumInvalid = true
+		n.enumValues = nil
+		return
+	}
+	n.enumValues = addEnumValues(n.enumValues, other.enumValues...)
+	if len(n.enumValues) > maxEnumValues {
+		n.enumInvalid = true
+		n.enumValues = nil
+	}
+}
+
+// isEnum checks if string values of node are an enum: there are few distinct short keywords, each seen twice
+// on average, and they have no other formats, like numbers or uuids.
+func (n *node) isEnum() bool {
+	if n.t.id() != nodeTypeString.id() || n.enumInvalid || len(n.enumValues) == 0 {
+		return false
+	}
+	if n.stringValues < 2*len(n.enumValues) {
+		return false
+	}
+	return n.formats&(formatDecimal|formatDigitsString|formatBoolString|formatUUID|formatEmail|formatURL) == 0
+}
@@ -0,0 +1,122 @@
+package json2go
+
+import (
+	"fmt"
+	"go/ast"
+	"strings"
+)
+
+// EpochUnit is a unit of Unix time, represented in json as a number. See OptEpochTimes.
+type EpochUnit int
+
+const (
+	// EpochSeconds are seconds since Unix epoch, like 1609459200.
+	EpochSeconds EpochUnit = iota
+	// EpochMillis are milliseconds since Unix epoch, like 1609459200000.
+	EpochMillis
+	// EpochMicros are microseconds since Unix epoch, like 1609459200000000.
+	EpochMicros
+	// EpochNanos are nanoseconds since Unix epoch, like 1609459200000000000.
+	EpochNanos
+)
+
+// ParseEpochUnit returns unit of Unix time by name: "s", "ms", "us" or "ns".
+func ParseEpochUnit(name string) (EpochUnit, error) {
+	switch name {
+	case "s":
+		return EpochSeconds, nil
+	case "ms":
+		return EpochMillis, nil
+	case "us":
+		return EpochMicros, nil
+	case "ns":
+		return EpochNanos, nil
+	}
+	return EpochSeconds, fmt.Errorf("unknown epoch unit: %s", name)
+}
+
+// epochUnit describes Unix time unit.
+type epochUnit struct {
+	name   string
+	helper string
+	// perSecond is a number of units in second.
+	perSecond int64
+	// min and max are magnitudes of recent times in unit, from 1973 to 2286.
+	min, max float64
+	format   int
+}
+
+var epochUnits = map[EpochUnit]epochUnit{
+	EpochSeconds: {name: "seconds", helper: "EpochSeconds", perSecond: 1, min: 1e8, max: 1e10, format: formatEpochSeconds},
+	EpochMillis:  {name: "milliseconds", helper: "EpochMillis", perSecond: 1e3, min: 1e11, max: 1e13, format: formatEpochMillis},
+	EpochMicros:  {name: "microseconds", helper: "EpochMicros", perSecond: 1e6, min: 1e14, max: 1e16, format: formatEpochMicros},
+	EpochNanos:   {name: "nanoseconds", helper: "EpochNanos", perSecond: 1e9, min: 1e17, max: 1e19, format: formatEpochNanos},
+}
+
+// epochFormats returns formats of Unix times in units, which magnitude matches integer f.
+func epochFormats(f float64) int {
+	var formats int
+	for _, u := range epochUnits {
+		if f >= u.min && f < u.max {
+			formats |= u.format
+		}
+	}
+	return formats
+}
+
+// isEpochKey checks if object key looks like a name of timestamp attribute, like "created_at", "updatedAt" or "ts".
+func isEpochKey(key string) bool {
+	words := strings.Split(snakeCaseName(attrName(key)), "_")
+	switch words[len(words)-1] {
+	case "at", "ts", "time", "timestamp", "date", "datetime", "epoch", "created", "updated", "modified", "expires":
+		return true
+	}
+	return false
+}
+
+// astEpochUnit returns unit of Unix times, if node holds integers with key and magnitude of timestamps in one of units.
+func astEpochUnit(n *node, units []EpochUnit) (EpochUnit, bool) {
+	if n.t != nodeTypeInt || !isEpochKey(n.key) {
+		return 0, false
+	}
+	for _, unit := range units {
+		if n.formats&epochUnits[unit].format != 0 {
+			return unit, true
+		}
+	}
+	return 0, false
+}
+
+// astTypeFromEpochNode returns helper type of times unmarshaled from json numbers with Unix time in unit.
+func astTypeFromEpochNode(unit EpochUnit, ctx *astContext) ast.Expr {
+	u := epochUnits[unit]
+	ctx.addImport("encoding/json")
+	ctx.addImport("time")
+
+	return ast.NewIdent(ctx.addSharedHelper(u.helper, func(name string) string {
+		return fmt.Sprintf(`
+// %[1]s is a time represented in json as a number of %[2]s since Unix epoch.
+type %[1]s struct {
+	time.Time
+}
+
+// UnmarshalJSON unmarshals time from json number of %[2]s since Unix epoch.
+func (t *%[1]s) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var v int64
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	t.Time = time.Unix(v/%[3]d, v%%%[3]d*%[4]d).UTC()
+	return nil
+}
+
+// MarshalJSON marshals time as json number of %[2]s since Unix epoch.
+func (t %[1]s) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.Unix()*%[3]d + int64(t.Nanosecond())/%[4]d)
+}
+`, name, u.name, u.perSecond, 1e9/u.perSecond)
+	}))
+}
@@ -0,0 +1,114 @@
+package json2go
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserEpochTimes(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		units    []EpochUnit
+		input    string
+		expected string
+	}{
+		{
+			name:     "disabled",
+			input:    `{"created_at":1609459200}`,
+			expected: "int",
+		},
+		{
+			name:     "seconds",
+			units:    []EpochUnit{EpochSeconds, EpochMillis},
+			input:    `{"created_at":1609459200}`,
+			expected: "EpochSeconds",
+		},
+		{
+			name:     "millis",
+			units:    []EpochUnit{EpochSeconds, EpochMillis},
+			input:    `{"updatedAt":1609459200123}`,
+			expected: "EpochMillis",
+		},
+		{
+			name:     "unit not selected",
+			units:    []EpochUnit{EpochSeconds},
+			input:    `{"ts":1609459200123456789}`,
+			expected: "int",
+		},
+		{
+			name:     "nanos",
+			units:    []EpochUnit{EpochNanos},
+			input:    `{"ts":1609459200123456789}`,
+			expected: "EpochNanos",
+		},
+		{
+			name:     "not a timestamp key",
+			units:    []EpochUnit{EpochSeconds},
+			input:    `{"count":1609459200}`,
+			expected: "int",
+		},
+		{
+			name:     "small value",
+			units:    []EpochUnit{EpochSeconds},
+			input:    `{"created_at":1200}`,
+			expected: "int",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			parser := NewJSONParser(baseTypeName, OptEpochTimes(tc.units...))
+			require.NoError(t, parser.FeedBytes([]byte(tc.input)))
+
+			fields := parser.Fields()
+			require.Len(t, fields, 1)
+			assert.Equal(t, tc.expected, fields[0].Type)
+		})
+	}
+}
+
+func TestParserEpochTimesCode(t *testing.T) {
+	t.Parallel()
+
+	parser := NewJSONParser(baseTypeName, OptEpochTimes(EpochSeconds, EpochMillis))
+	require.NoError(t, parser.FeedBytes([]byte(`{"created_at":1609459200,"expires_at":1609459200123}`)))
+
+	out := runGeneratedCode(t, parser, `
+	var d Document
+	if err := json.NewDecoder(os.Stdin).Decode(&d); err != nil {
+		panic(err)
+	}
+	fmt.Println(d.CreatedAt.Format(time.RFC3339Nano), d.ExpiresAt.Format(time.RFC3339Nano))
+	out, err := json.Marshal(d)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(out))
+`, `{"created_at":1612137600,"expires_at":1612137600456}`)
+	assert.Equal(t, "2021-02-01T00:00:00Z 2021-02-01T00:00:00.456Z\n"+
+		`{"created_at":1612137600,"expires_at":1612137600456}`+"\n", out)
+}
+
+func TestParserEpochFormatsNeedOption(t *testing.T) {
+	t.Parallel()
+
+	input := []byte(`{"created_at":1600000000}`)
+	p := NewJSONParser(baseTypeName)
+	require.NoError(t, p.FeedBytes(input))
+	assert.Zero(t, p.rootNode.getChild("created_at").formats&formatsEpoch)
+
+	p = NewJSONParser(baseTypeName, OptEpochTimes(EpochMillis))
+	require.NoError(t, p.FeedBytes(input))
+	assert.Zero(t, p.rootNode.getChild("created_at").formats&formatsEpoch, "only formats of wanted units are detected")
+
+	p = NewJSONParser(baseTypeName, OptEpochTimes(EpochSeconds))
+	require.NoError(t, p.FeedBytes(input))
+	assert.Equal(t, formatEpochSeconds, p.rootNode.getChild("created_at").formats&formatsEpoch)
+}
@@ -0,0 +1,125 @@
+package json2go
+
+import "errors"
+
+// Errors returned by parser. Returned errors may wrap them with more details, use errors.Is to check them.
+var (
+	// ErrInvalidJSON is returned when input is not a valid json.
+	ErrInvalidJSON = errors.New("invalid json")
+	// ErrDuplicateKey is returned when input has duplicate keys and DuplicateKeysError policy is used.
+	ErrDuplicateKey = errors.New("duplicate key")
+	// ErrDepthExceeded is returned when input is nested deeper than limit set with OptMaxDepth.
+	ErrDepthExceeded = errors.New("depth exceeded")
+	// ErrNodeBudgetExceeded is returned when inferred types have more nodes than limit set with OptMaxNodes.
+	ErrNodeBudgetExceeded = errors.New("node budget exceeded")
+	// ErrInputTooLarge is returned by Service for requests with inputs larger than Service.MaxInputSize.
+	ErrInputTooLarge = errors.New("input too large")
+	// ErrRateLimited is returned by Service for requests of clients exceeding rate set with Service.RateLimiter.
+	ErrRateLimited = errors.New("rate limited")
+	// ErrUnsupportedShape is returned when parsed values can't be represented as go type.
+	ErrUnsupportedShape = errors.New("unsupported shape")
+	// ErrIncompatibleSchema is returned when pushed schema isn't compatible with schema in registry.
+	ErrIncompatibleSchema = errors.New("incompatible schema")
+	// ErrMissingDependency is returned when generated code uses package not required by go module set with OptGoModule.
+	ErrMissingDependency = errors.New("missing dependency")
+	// ErrInvalidCode is returned when generated code doesn't type check, see OptTypeCheck.
+	ErrInvalidCode = errors.New("invalid generated code")
+	// ErrDecodeMismatch is returned when samples don't decode to generated types, see VerifyDecoding.
+	// Returned error is DecodeError, listing paths of values, which don't fit their types.
+	ErrDecodeMismatch = errors.New("samples don't decode to generated types")
+	// ErrStrict is returned when inference compromises values with strictness set with OptStrict.
+	// Returned error is StrictError, listing compromised paths.
+	ErrStrict = errors.New("strict mode violation")
+	// ErrInvalidIR is returned by UnmarshalIR and MarshalIR for incomplete schemas or documents of unsupported versions.
+	ErrInvalidIR = errors.New("invalid IR")
+	// ErrAppendConflict is returned by AppendTo, when existing file declares generated types with different shapes.
+	// Returned error is AppendError, listing conflicts.
+	ErrAppendConflict = errors.New("append conflict")
+	// ErrMissingRegion is returned by Patch, when file doesn't have single region of generated code.
+	ErrMissingRegion = errors.New("missing region of generated code")
+	// ErrUnknownProfile is returned by Service for requests selecting profiles it doesn't have.
+	ErrUnknownProfile = errors.New("unknown profile")
+	// ErrTooManyNames is reported by Capture for responses with new names, when it has Capture.MaxNames names already.
+	ErrTooManyNames = errors.New("too many names")
+	// ErrInternal is returned when parser reaches unexpected state.
+	ErrInternal = errors.New("internal error")
+)
+
+// invalidJSONError is json decoding error. It matches ErrInvalidJSON and unwraps to original error.
+type invalidJSONError struct {
+	err error
+}
+
+func (e invalidJSONError) Error() string {
+	return e.err.Error()
+}
+
+func (e invalidJSONError) Unwrap() error {
+	return e.err
+}
+
+func (e invalidJSONError) Is(target error) bool {
+	return target == ErrInvalidJSON
+}
+
+// Exit codes of json2go command by classes of failures. They are stable, so scripts may react to them.
+const (
+	// ExitOK is an exit code of success.
+	ExitOK = 0
+	// ExitFailure is an exit code of failures of other classes, like unreadable files or unavailable servers.
+	ExitFailure = 1
+	// ExitUsage is an exit code of invalid flags or options, like unknown profiles, the same as of flag package.
+	ExitUsage = 2
+	// ExitInvalidInput is an exit code of invalid inputs, like invalid json, duplicate keys or too large inputs.
+	ExitInvalidInput = 3
+	// ExitLimitExceeded is an exit code of exceeded limits, like maximum depth or node budget.
+	ExitLimitExceeded = 4
+	// ExitStrict is an exit code of compromised values failing strictness, or values without go representation.
+	ExitStrict = 5
+	// ExitInvalidCode is an exit code of generated code, which doesn't type check, uses missing dependencies or
+	// doesn't decode samples.
+	ExitInvalidCode = 6
+	// ExitConflict is an exit code of conflicts with existing code or schemas, like AppendError, missing regions
+	// of generated code or schemas incompatible with registry.
+	ExitConflict = 7
+	// ExitInternal is an exit code of internal errors, like EX_SOFTWARE of sysexits.h.
+	ExitInternal = 70
+)
+
+// exitCodes are exit codes of errors, checked in order.
+var exitCodes = []struct {
+	err  error
+	code int
+}{
+	{ErrInternal, ExitInternal},
+	{ErrUnknownProfile, ExitUsage},
+	{ErrInvalidJSON, ExitInvalidInput},
+	{ErrDuplicateKey, ExitInvalidInput},
+	{ErrInputTooLarge, ExitInvalidInput},
+	{ErrInvalidIR, ExitInvalidInput},
+	{ErrDepthExceeded, ExitLimitExceeded},
+	{ErrNodeBudgetExceeded, ExitLimitExceeded},
+	{ErrRateLimited, ExitLimitExceeded},
+	{ErrStrict, ExitStrict},
+	{ErrUnsupportedShape, ExitStrict},
+	{ErrInvalidCode, ExitInvalidCode},
+	{ErrMissingDependency, ExitInvalidCode},
+	{ErrDecodeMismatch, ExitInvalidCode},
+	{ErrAppendConflict, ExitConflict},
+	{ErrMissingRegion, ExitConflict},
+	{ErrIncompatibleSchema, ExitConflict},
+}
+
+// ExitCode returns exit code of class of error: ExitOK for nil, and ExitFailure for errors not matching errors
+// of parser.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	for _, c := range exitCodes {
+		if errors.Is(err, c.err) {
+			return c.code
+		}
+	}
+	return ExitFailure
+}
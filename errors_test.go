@@ -0,0 +1,50 @@
+package json2go
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExitCode(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{name: "nil", err: nil, code: ExitOK},
+		{name: "other", err: errors.New("connection refused"), code: ExitFailure},
+		{name: "invalid json", err: invalidJSONError{err: errors.New("unexpected EOF")}, code: ExitInvalidInput},
+		{name: "wrapped", err: fmt.Errorf("sample 1: %w", ErrDuplicateKey), code: ExitInvalidInput},
+		{name: "limit", err: ErrNodeBudgetExceeded, code: ExitLimitExceeded},
+		{name: "strict", err: &StrictError{}, code: ExitStrict},
+		{name: "invalid code", err: fmt.Errorf("%w: undefined: x", ErrInvalidCode), code: ExitInvalidCode},
+		{name: "conflict", err: &AppendError{}, code: ExitConflict},
+		{name: "unknown profile", err: ErrUnknownProfile, code: ExitUsage},
+		{name: "internal", err: fmt.Errorf("%w: parsing generated code: %v", ErrInternal, ErrInvalidJSON), code: ExitInternal},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.code, ExitCode(tc.err))
+		})
+	}
+}
+
+func TestInternalErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := mergeNodes(nil)
+	assert.True(t, errors.Is(err, ErrInternal), "%v", err)
+
+	_, err = astParseDecls("func (")
+	assert.True(t, errors.Is(err, ErrInternal), "%v", err)
+	ctx := newASTContext(nil, options{})
+	ctx.addHelper("func (")
+	assert.True(t, errors.Is(ctx.err, ErrInternal), "%v", ctx.err)
+}
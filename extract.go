@@ -6,10 +6,26 @@ import (
 	"strings"
 )
 
-func extractCommonSubtrees(root *node) []*node {
+// Names of types extracted from arrays are singularized, singulars overrides built-in plural forms.
+// Assigned are type names by json path, allocated in previous runs (see NameMapping). Structure found at
+// assigned path gets its name, so names of existing types don't change when new structures are found.
+// Assigned names aren't used for other structures.
+// Reserved names are names of root object attributes declared as separate types (see ConvertAll),
+// extracted type uses reserved name only if it describes attribute with that name.
+// If extraction fails, types extracted before are returned with error.
+func extractCommonSubtrees(root *node, singulars, assigned map[string]string, reserved ...string) ([]*node, error) {
 	rootNames := map[string]bool{
 		root.name: true,
 	}
+	for _, name := range assigned {
+		rootNames[name] = true
+	}
+	reservedNames := make(map[string]bool)
+	for _, name := range reserved {
+		rootNames[name] = true
+		reservedNames[name] = true
+	}
+	claimed := make(map[string]bool)
 
 	extractedSize := 0
 	nodes := []*node{root}
@@ -17,7 +33,17 @@ func extractCommonSubtrees(root *node) []*node {
 		extractedSize = len(nodes)
 		result := nodes
 		for _, n := range nodes {
-			extNode := extractCommonSubtree(n, rootNames)
+			extNode, err := extractCommonSubtree(n, extractNames{
+				used:      rootNames,
+				reserved:  reservedNames,
+				assigned:  assigned,
+				claimed:   claimed,
+				singulars: singulars,
+			})
+			if err != nil {
+				// Types extracted so far are kept, as tree refers to them.
+				return result, err
+			}
 			if extNode != nil {
 				result = append(result, extNode)
 			}
@@ -25,11 +51,30 @@ func extractCommonSubtrees(root *node) []*node {
 		nodes = result
 	}
 
-	return nodes
+	return nodes, nil
+}
+
+// extractNames keeps names allocated for extracted types.
+type extractNames struct {
+	used      map[string]bool
+	reserved  map[string]bool
+	assigned  map[string]string
+	claimed   map[string]bool
+	singulars map[string]string
+}
+
+// assignedName returns name assigned to any of nodes paths, if it isn't claimed by other type yet.
+func (en extractNames) assignedName(nodes []*node) (string, bool) {
+	for _, n := range nodes {
+		if name, ok := en.assigned[n.path]; ok && !en.claimed[name] {
+			return name, true
+		}
+	}
+	return "", false
 }
 
 // extractCommonSubtree extracts at most one common subtree to new root node
-func extractCommonSubtree(root *node, rootNames map[string]bool) *node {
+func extractCommonSubtree(root *node, names extractNames) (*node, error) {
 	// Find all structures in object tree.
 	structDataM := make(map[string]structNodes)
 	objectTreeInfo(root, structDataM)
@@ -49,7 +94,7 @@ func extractCommonSubtree(root *node, rootNames map[string]bool) *node {
 		delete(structDataM, k)
 	}
 	if len(structDataM) == 0 {
-		return nil
+		return nil, nil
 	}
 
 	// Create list, sorted by tree depth, asceding. We want to start extracting from simpliest subtrees.
@@ -62,7 +107,11 @@ func extractCommonSubtree(root *node, rootNames map[string]bool) *node {
 		l2 := strings.Count(structData[j].structureID, structIDlevelSeparator)
 
 		if l1 == l2 { // if struct depth is equal, compare by first node key
-			return structData[i].nodes[0].key < structData[j].nodes[0].key
+			k1, k2 := structData[i].nodes[0].key, structData[j].nodes[0].key
+			if k1 == k2 {
+				return structData[i].structureID < structData[j].structureID
+			}
+			return k1 < k2
 		}
 		return l1 < l2
 	})
@@ -72,14 +121,30 @@ func extractCommonSubtree(root *node, rootNames map[string]bool) *node {
 		if extractedName == "" {
 			continue
 		}
+		if nodesAreArrays(info.nodes) {
+			// Type describes array element.
+			extractedName = singularName(extractedName, names.singulars)
+		}
 
-		for rootNames[extractedName] {
-			extractedName = nextName(extractedName)
-			extractedKey = nextName(extractedKey)
+		if name, ok := names.assignedName(info.nodes); ok {
+			extractedName = name
+		} else {
+			if names.reserved[extractedName] && describesAttribute(root, info.nodes, extractedName) {
+				delete(names.reserved, extractedName)
+				delete(names.used, extractedName)
+			}
+			for names.used[extractedName] {
+				extractedName = nextName(extractedName)
+				extractedKey = nextName(extractedKey)
+			}
 		}
-		rootNames[extractedName] = true
+		names.used[extractedName] = true
+		names.claimed[extractedName] = true
 
-		extractedNode := mergeNodes(info.nodes)
+		extractedNode, err := mergeNodes(info.nodes)
+		if err != nil {
+			return nil, err
+		}
 		extractedNode.name = extractedName
 		extractedNode.key = extractedKey
 		extractedNode.root = true
@@ -91,10 +156,25 @@ func extractCommonSubtree(root *node, rootNames map[string]bool) *node {
 			modNode.children = nil
 		})
 
-		return extractedNode // exit after first successful extract
+		return extractedNode, nil // exit after first successful extract
 	}
 
-	return nil
+	return nil, nil
+}
+
+// describesAttribute returns true if nodes contain root's non-array attribute with given name.
+func describesAttribute(root *node, nodes []*node, name string) bool {
+	for _, c := range root.children {
+		if c.name != name || c.arrayLevel > 0 {
+			continue
+		}
+		for _, n := range nodes {
+			if n == c {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 type structNodes struct {
@@ -103,6 +183,10 @@ type structNodes struct {
 }
 
 func objectTreeInfo(n *node, infos map[string]structNodes) {
+	if n.chainDepth > 0 {
+		// Objects of flattened chains aren't declared.
+		return
+	}
 	switch n.t.id() {
 	case nodeTypeObject.id():
 	case nodeTypeMap.id():
@@ -133,6 +217,13 @@ func objectTreeInfo(n *node, infos map[string]structNodes) {
 // if `withKey` is true, node's key name is added to id.
 func structureID(n *node, withKey bool) string {
 	id := n.t.id()
+	if n.t == nodeTypeExtracted {
+		// Values of different extracted or custom types have different structure.
+		id += ":" + n.externalTypeID
+	}
+	if n.chainDepth > 0 {
+		id += fmt.Sprintf(":chain%d", n.chainDepth)
+	}
 	if withKey {
 		id = fmt.Sprintf("%s.%s", n.key, id)
 	}
@@ -149,6 +240,16 @@ func structureID(n *node, withKey bool) string {
 	return result
 }
 
+func nodesAreArrays(nodes []*node) bool {
+	for _, n := range nodes {
+		if n.arrayLevel == 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
 // makeNameFromNodes is helper function trying to find the best name (and key) from list of nodes.
 func makeNameFromNodes(nodes []*node) (key, name string) {
 	if len(nodes) == 0 {
@@ -179,20 +280,30 @@ func makeNameFromNodes(nodes []*node) (key, name string) {
 }
 
 // mergeNodes merges multiple nodes into one.
+// Merged node has all children of merged nodes.
 // If any of nodes is not required, merged node is also not required.
 // If any of nodes is nullable, merged node is also nullable.
-// Children of merged nodes are also merged by the same rules.
-func mergeNodes(nodes []*node) *node {
-	if len(nodes) == 0 { // This should never happen
-		panic("mergeNodes called with empty node list!")
+// Children of merged nodes are also merged by the same rules. Empty list of nodes is an internal error.
+func mergeNodes(nodes []*node) (*node, error) {
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("%w: merging empty list of nodes", ErrInternal)
 	}
 	if len(nodes) == 1 {
-		return nodes[0]
+		return nodes[0], nil
 	}
 
 	// Set main attributes of merged node.
 	merged := *nodes[0]
+	merged.keyOrder = nil
+	merged.objects = 0
+	merged.occurrences = 0
+	merged.weightedObjects = 0
+	merged.weightedOccurrences = 0
 	for _, n := range nodes {
+		merged.objects += n.objects
+		merged.occurrences += n.occurrences
+		merged.weightedObjects += n.weightedObjects
+		merged.weightedOccurrences += n.weightedOccurrences
 		if n.t.expands(merged.t) {
 			merged.t = n.t
 		}
@@ -203,26 +314,65 @@ func mergeNodes(nodes []*node) *node {
 		if n.nullable {
 			merged.nullable = true
 		}
+		if n.needsFloat64 {
+			merged.needsFloat64 = true
+		}
+		if n.hasText {
+			merged.hasText = true
+		}
+		merged.boolStrings |= n.boolStrings
+		if n != nodes[0] {
+			merged.mergeEnum(n)
+		}
+		if n.input > 0 && (merged.input == 0 || n.input < merged.input) {
+			merged.input = n.input
+		}
+		if merged.example == nil {
+			merged.example = n.example
+		}
+		merged.seenKinds |= n.seenKinds
+		merged.seenTypes |= n.seenTypes
+		merged.formats &= n.formats
+		merged.keyOrder = mergeKeyOrder(merged.keyOrder, n.keyOrder...)
 	}
 
 	// Set attributes of merged node's children recurently.
-	if len(merged.children) > 0 {
-		for i, cn := range merged.children {
-			cnodes := make([]*node, 0, len(nodes))
-			for _, n := range nodes {
-				v := n.getChild(cn.key)
-				if v == nil {
-					continue
-				}
-				cnodes = append(cnodes, v)
-			}
-			if len(cnodes) > 1 {
-				merged.children[i] = mergeNodes(cnodes)
+	// Merged node has union of all nodes children. Child missing in any of nodes is not required.
+	var keys []string
+	childrenByKey := make(map[string][]*node)
+	for _, n := range nodes {
+		for _, c := range n.children {
+			if _, ok := childrenByKey[c.key]; !ok {
+				keys = append(keys, c.key)
 			}
+			childrenByKey[c.key] = append(childrenByKey[c.key], c)
+		}
+	}
+	sort.Strings(keys)
+
+	var children []*node
+	names := make(map[string]bool)
+	for _, k := range keys {
+		cnodes := childrenByKey[k]
+		cn, err := mergeNodes(cnodes)
+		if err != nil {
+			return nil, err
 		}
+		if len(cnodes) == 1 {
+			cn = cn.clone()
+		}
+		if len(cnodes) < len(nodes) {
+			cn.required = false
+		}
+		for names[cn.name] {
+			cn.name = nextName(cn.name)
+		}
+		names[cn.name] = true
+		children = append(children, cn)
 	}
+	merged.children = children
 
-	return &merged
+	return &merged, nil
 }
 
 // modifyTree executes function f on all nodes in subtree with given structure id
@@ -235,3 +385,125 @@ func modifyTree(root *node, structID string, f func(*node)) {
 		modifyTree(child, structID, f)
 	}
 }
+
+// extractNestedStructs extracts all inline structs to new root nodes, so generated code has named struct types only.
+// Names of types of array elements are singularized, singulars overrides built-in plural forms.
+// Struct found at assigned path gets its name, see extractCommonSubtrees.
+func extractNestedStructs(nodes []*node, singulars, assigned map[string]string) []*node {
+	names := extractNames{
+		used:      make(map[string]bool),
+		assigned:  assigned,
+		claimed:   make(map[string]bool),
+		singulars: singulars,
+	}
+	for _, name := range assigned {
+		names.used[name] = true
+	}
+	for _, n := range nodes {
+		names.used[n.name] = true
+		names.claimed[n.name] = true
+	}
+
+	for i := 0; i < len(nodes); i++ {
+		n := nodes[i]
+		if n.root && n.arrayLevel > 0 && n.t.id() == nodeTypeObject.id() {
+			// Root array of structs.
+			nodes = append(nodes, extractStruct(n, n.name, names))
+			continue
+		}
+		nodes = extractChildStructs(n, nodes, names)
+	}
+
+	return nodes
+}
+
+// extractMarkedStructs extracts inline structs of marked nodes to new root nodes, so they have named types.
+// Function marked returns type names of marked nodes, or empty string. Names are allocated like in extractNestedStructs.
+func extractMarkedStructs(nodes []*node, singulars, assigned map[string]string, marked func(n *node) string) []*node {
+	names := extractNames{
+		used:      make(map[string]bool),
+		assigned:  assigned,
+		claimed:   make(map[string]bool),
+		singulars: singulars,
+	}
+	for _, name := range assigned {
+		names.used[name] = true
+	}
+	for _, n := range nodes {
+		names.used[n.name] = true
+		names.claimed[n.name] = true
+	}
+
+	var walk func(n *node)
+	walk = func(n *node) {
+		for _, c := range n.children {
+			if name := marked(c); name != "" && c.t.id() == nodeTypeObject.id() {
+				nodes = append(nodes, extractStruct(c, name, names))
+				continue
+			}
+			walk(c)
+		}
+	}
+	for i := 0; i < len(nodes); i++ {
+		walk(nodes[i])
+	}
+	return nodes
+}
+
+func extractChildStructs(n *node, nodes []*node, names extractNames) []*node {
+	children := append(append([]*node(nil), n.children...), n.tuple...)
+	for _, c := range children {
+		if c.t.id() != nodeTypeObject.id() {
+			nodes = extractChildStructs(c, nodes, names)
+			continue
+		}
+
+		name := c.name
+		if name == "" {
+			// Map values have no names.
+			name = n.name + "Value"
+		}
+		nodes = append(nodes, extractStruct(c, name, names))
+	}
+
+	return nodes
+}
+
+// extractStruct moves struct node to new root node, and makes it refer to it.
+func extractStruct(n *node, name string, names extractNames) *node {
+	if n.arrayLevel > 0 {
+		if singular := singularName(name, names.singulars); singular != name {
+			name = singular
+		} else {
+			name += "Item"
+		}
+	}
+	return extractNamedStruct(n, name, names)
+}
+
+// extractNamedStruct is like extractStruct, but name of array elements isn't made singular.
+func extractNamedStruct(n *node, name string, names extractNames) *node {
+	if assigned, ok := names.assignedName([]*node{n}); ok {
+		name = assigned
+	} else {
+		for names.used[name] {
+			name = nextName(name)
+		}
+	}
+	names.used[name] = true
+	names.claimed[name] = true
+
+	extracted := *n
+	extracted.name = name
+	extracted.root = true
+	extracted.document = false
+	extracted.arrayLevel = 0
+	extracted.nullable = false
+	extracted.required = true
+
+	n.t = nodeTypeExtracted
+	n.externalTypeID = name
+	n.children = nil
+
+	return &extracted
+}
@@ -0,0 +1,192 @@
+package json2go
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"time"
+)
+
+// fakeMaxDepth limits nesting of values generated by Generate, so recursive types produce finite documents.
+const fakeMaxDepth = 16
+
+// fakeMaxElements is a maximum number of elements of generated arrays and maps.
+const fakeMaxElements = 3
+
+// fakeLetters are characters of generated strings and map keys.
+const fakeLetters = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+// Generate returns random json document, which decodes to generated types described by ir, see JSONParser.Schema.
+// Documents are reproducible, the same seed gives the same document. Optional values, like pointers or fields
+// with omitempty option, are randomly null or absent. Values of opaque types are null.
+func Generate(ir *Schema, seed int64) ([]byte, error) {
+	if ir == nil || ir.Types[ir.Root] == nil {
+		return nil, errors.New("schema has no root type")
+	}
+
+	f := faker{
+		schema: ir,
+		rnd:    rand.New(rand.NewSource(seed)),
+	}
+	f.value(&SchemaType{Kind: SchemaNamed, Name: ir.Root}, 0)
+	return f.buf.Bytes(), nil
+}
+
+// faker writes random json values of schema types.
+type faker struct {
+	schema *Schema
+	rnd    *rand.Rand
+	buf    bytes.Buffer
+}
+
+// resolve returns declared type of named type.
+func (f *faker) resolve(t *SchemaType) *SchemaType {
+	for depth := 0; t.Kind == SchemaNamed; depth++ {
+		declared := f.schema.Types[t.Name]
+		if declared == nil || depth > len(f.schema.Types) {
+			return &SchemaType{Kind: SchemaOpaque}
+		}
+		t = declared
+	}
+	return t
+}
+
+func (f *faker) value(t *SchemaType, depth int) {
+	t = f.resolve(t)
+	deep := depth >= fakeMaxDepth
+	switch t.Kind {
+	case SchemaBool:
+		f.buf.WriteString(strconv.FormatBool(f.rnd.Intn(2) == 0))
+	case SchemaInt:
+		f.buf.WriteString(strconv.Itoa(f.rnd.Intn(2000) - 1000))
+	case SchemaFloat:
+		f.buf.WriteString(formatFloat(float64(f.rnd.Intn(200000)-100000)/100, t.Bits))
+	case SchemaString:
+		if t.Format == SchemaFormatUUID {
+			f.string(f.uuid())
+			return
+		}
+		f.string(f.word())
+	case SchemaTime:
+		tm := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(f.rnd.Int63n(int64(30 * 365 * 24 * time.Hour))))
+		f.string(tm.Truncate(time.Second).Format(time.RFC3339))
+	case SchemaAny:
+		f.any(depth)
+	case SchemaPointer, SchemaOptional:
+		if deep || f.rnd.Intn(4) == 0 {
+			f.buf.WriteString("null")
+			return
+		}
+		f.value(t.Elem, depth+1)
+	case SchemaSlice:
+		f.buf.WriteString("[")
+		for i, n := 0, f.elements(deep); i < n; i++ {
+			if i > 0 {
+				f.buf.WriteString(",")
+			}
+			if t.SkipNulls && f.rnd.Intn(4) == 0 {
+				f.buf.WriteString("null")
+				continue
+			}
+			f.value(t.Elem, depth+1)
+		}
+		f.buf.WriteString("]")
+	case SchemaMap:
+		f.buf.WriteString("{")
+		keys := make(map[string]bool)
+		for i, n := 0, f.elements(deep); i < n; i++ {
+			key := f.word()
+			if t.Key != nil && f.resolve(t.Key).Kind == SchemaInt {
+				key = strconv.Itoa(f.rnd.Intn(1000))
+			}
+			if t.Key != nil && f.resolve(t.Key).Format == SchemaFormatUUID {
+				key = f.uuid()
+			}
+			if keys[key] {
+				continue
+			}
+			if len(keys) > 0 {
+				f.buf.WriteString(",")
+			}
+			keys[key] = true
+			f.string(key)
+			f.buf.WriteString(":")
+			f.value(t.Elem, depth+1)
+		}
+		f.buf.WriteString("}")
+	case SchemaStruct:
+		f.object(t, depth)
+	default:
+		f.buf.WriteString("null")
+	}
+}
+
+// object writes json object with attributes of struct type fields.
+func (f *faker) object(t *SchemaType, depth int) {
+	f.buf.WriteString("{")
+	first := true
+	for _, field := range t.Fields {
+		kind := f.resolve(field.Type).Kind
+		optional := field.OmitEmpty || field.OmitZero || kind == SchemaPointer || kind == SchemaOptional
+		if optional && (depth >= fakeMaxDepth || f.rnd.Intn(4) == 0) {
+			continue
+		}
+		if !first {
+			f.buf.WriteString(",")
+		}
+		first = false
+		f.string(field.Key)
+		f.buf.WriteString(":")
+		f.value(field.Type, depth+1)
+	}
+	f.buf.WriteString("}")
+}
+
+// any writes random json scalar.
+func (f *faker) any(depth int) {
+	switch f.rnd.Intn(4) {
+	case 0:
+		f.buf.WriteString("null")
+	case 1:
+		f.value(&SchemaType{Kind: SchemaBool}, depth)
+	case 2:
+		f.value(&SchemaType{Kind: SchemaFloat, Bits: 64}, depth)
+	default:
+		f.value(&SchemaType{Kind: SchemaString}, depth)
+	}
+}
+
+// elements returns random number of array or map elements.
+func (f *faker) elements(deep bool) int {
+	if deep {
+		return 0
+	}
+	return f.rnd.Intn(fakeMaxElements + 1)
+}
+
+func (f *faker) word() string {
+	b := make([]byte, 1+f.rnd.Intn(8))
+	for i := range b {
+		b[i] = fakeLetters[f.rnd.Intn(len(fakeLetters))]
+	}
+	return string(b)
+}
+
+// uuid returns random version 4 uuid.
+func (f *faker) uuid() string {
+	b := make([]byte, 16)
+	for i := range b {
+		b[i] = byte(f.rnd.Intn(256))
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+func (f *faker) string(s string) {
+	data, _ := json.Marshal(s)
+	f.buf.Write(data)
+}
@@ -0,0 +1,79 @@
+package json2go
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+
+	input := `{"id":1,"price":1.5,"name":"a","at":"2021-01-01T00:00:00Z","tags":["x"],"counts":{"a":1,"b":2,"c":3},"user":{"n":1,"extra":null},"any":[1,"a"]}`
+
+	testCases := []struct {
+		name string
+		opts []JSONParserOpt
+	}{
+		{name: "default"},
+		{name: "maps and optional", opts: []JSONParserOpt{OptMakeMaps(true, 3), OptOptionalType(true)}},
+		{name: "float32 and values", opts: []JSONParserOpt{OptFloat32(true, true), OptSliceElements(SliceElementsValues)}},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			parser := NewJSONParser(baseTypeName, tc.opts...)
+			require.NoError(t, parser.FeedBytes([]byte(input)))
+			require.NoError(t, parser.FeedBytes([]byte(`{"id":2,"price":2,"name":null,"tags":[],"counts":{},"user":null,"any":[]}`)))
+			schema := parser.Schema()
+
+			for seed := int64(0); seed < 20; seed++ {
+				doc, err := Generate(schema, seed)
+				require.NoError(t, err)
+				require.True(t, json.Valid(doc), string(doc))
+
+				again, err := Generate(schema, seed)
+				require.NoError(t, err)
+				assert.Equal(t, string(doc), string(again))
+
+				report, err := VerifyRoundTrip(doc, schema)
+				require.NoError(t, err)
+				for _, issue := range report.Issues {
+					assert.NotContains(t, []RoundTripIssueKind{RoundTripDroppedKey, RoundTripTypeMismatch, RoundTripLostPrecision}, issue.Kind, "%s: %s", doc, issue)
+				}
+			}
+		})
+	}
+
+	_, err := Generate(&Schema{}, 1)
+	assert.Error(t, err)
+}
+
+func TestGenerateUUIDs(t *testing.T) {
+	t.Parallel()
+
+	uuid := &SchemaType{Kind: SchemaString, Format: SchemaFormatUUID}
+	schema := &Schema{Root: "Document", Types: map[string]*SchemaType{"Document": {Kind: SchemaStruct, Fields: []SchemaField{
+		{Name: "ID", Key: "id", Type: uuid},
+		{Name: "Counts", Key: "counts", Type: &SchemaType{Kind: SchemaMap, Key: uuid, Elem: &SchemaType{Kind: SchemaInt}}},
+	}}}}
+
+	for seed := int64(0); seed < 10; seed++ {
+		doc, err := Generate(schema, seed)
+		require.NoError(t, err)
+		var v struct {
+			ID     string         `json:"id"`
+			Counts map[string]int `json:"counts"`
+		}
+		require.NoError(t, json.Unmarshal(doc, &v))
+		assert.Regexp(t, uuidRe, v.ID)
+		for key := range v.Counts {
+			assert.Regexp(t, uuidRe, key)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package json2go
+
+import (
+	"go/ast"
+)
+
+// Field describes generated struct field or map value, for reviewing inferred types.
+type Field struct {
+	// Path is a json path of value, like "$.user.id". Map values have "*" path element.
+	Path string
+	// Owner is a name of generated type containing the field.
+	Owner string
+	// Name is a name of struct field, empty for map values.
+	Name string
+	// Type is a go type of field. Nested struct types are shortened to "struct{...}".
+	Type string
+	// Required is true if value was present in all parsed objects.
+	Required bool
+	// Nullable is true if value was null in any of parsed objects.
+	Nullable bool
+}
+
+// Fields returns fields of generated types, in order of generated code.
+func (p *JSONParser) Fields() []Field {
+	nodes, _ := p.declNodes()
+	ctx := newASTContext(nodes, p.opts)
+
+	var fields []Field
+	var walk func(owner string, n *node)
+	walk = func(owner string, n *node) {
+		for _, c := range n.children {
+			fields = append(fields, Field{
+				Path:     c.path,
+				Owner:    owner,
+				Name:     c.name,
+				Type:     astExprString(astShortenStructs(astTypeFromNode(c, ctx))),
+				Required: c.required,
+				Nullable: c.nullable,
+			})
+			// Objects of flattened chains aren't declared, fields of values at their ends are.
+			for depth := c.chainDepth; depth > 0; depth-- {
+				c = c.children[0]
+			}
+			walk(owner, c)
+		}
+	}
+	for _, n := range nodes {
+		walk(n.name, n)
+	}
+
+	return fields
+}
+
+// astShortenStructs replaces struct types in expression with "struct{...}".
+func astShortenStructs(expr ast.Expr) ast.Expr {
+	switch e := expr.(type) {
+	case *ast.StructType:
+		return ast.NewIdent("struct{...}")
+	case *ast.StarExpr:
+		e.X = astShortenStructs(e.X)
+	case *ast.ArrayType:
+		e.Elt = astShortenStructs(e.Elt)
+	case *ast.MapType:
+		e.Value = astShortenStructs(e.Value)
+	case *ast.IndexExpr:
+		e.Index = astShortenStructs(e.Index)
+	}
+	return expr
+}
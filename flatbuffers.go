@@ -0,0 +1,107 @@
+package json2go
+
+import (
+	"fmt"
+	"strings"
+)
+
+// flatBuffersScalars are FlatBuffers types of scalar values, which can't be required.
+var flatBuffersScalars = map[string]bool{"bool": true, "long": true, "float": true, "double": true}
+
+// FlatBuffersEmitter emits FlatBuffers schema from IR, with tables of structs and root_type of root struct.
+//
+// Fields keep json keys, so flatc converts json documents with the schema. Optional scalars default to null,
+// required strings, vectors and tables are marked with required attribute. Maps are vectors of entry tables
+// sorted by keys. Times are Unix times in microseconds. Any values, opaque values and vectors of vectors,
+// not supported by FlatBuffers, are flexbuffers.
+type FlatBuffersEmitter struct{}
+
+// Emit returns FlatBuffers schema.
+func (FlatBuffersEmitter) Emit(ir *Schema) ([]byte, error) {
+	s, err := newIDLSchema(ir, "FlatBuffers schema", true)
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	for i, d := range s.decls {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "table %s {\n", capnpName(d.name, true))
+		names := make(map[string]bool)
+		for _, f := range d.fields {
+			name := bigQueryName(f.Key)
+			for names[name] {
+				name = nextName(name)
+			}
+			names[name] = true
+
+			typ, comment := flatBuffersType(ir, s, f.Type)
+			var attrs []string
+			switch {
+			case typ == "":
+				typ = "[ubyte]"
+				attrs = append(attrs, "flexbuffer")
+			case flatBuffersScalars[typ] && f.isOptional():
+				typ += " = null"
+			case !flatBuffersScalars[typ] && !f.isOptional():
+				attrs = append(attrs, "required")
+			}
+			if d.entry && f.Key == "key" {
+				attrs = append(attrs, "key")
+			}
+			if len(attrs) > 0 {
+				typ += " (" + strings.Join(attrs, ", ") + ")"
+			}
+			if comment != "" {
+				comment = "  // " + comment
+			}
+			fmt.Fprintf(&b, "  %s:%s;%s\n", name, typ, comment)
+		}
+		b.WriteString("}\n")
+	}
+	fmt.Fprintf(&b, "\nroot_type %s;\n", capnpName(s.decls[0].name, true))
+	return []byte(b.String()), nil
+}
+
+// flatBuffersType returns FlatBuffers type of values and comment describing their representation,
+// or empty type for values represented as flexbuffers.
+func flatBuffersType(ir *Schema, s *idlSchema, t *SchemaType) (typ string, comment string) {
+	switch t.Kind {
+	case SchemaBool:
+		return "bool", ""
+	case SchemaInt:
+		return "long", ""
+	case SchemaFloat:
+		if t.Bits == 32 {
+			return "float", ""
+		}
+		return "double", ""
+	case SchemaString:
+		return "string", ""
+	case SchemaTime:
+		return "long", "Unix time in microseconds."
+	case SchemaPointer, SchemaOptional:
+		return flatBuffersType(ir, s, t.Elem)
+	case SchemaSlice:
+		elem, comment := flatBuffersType(ir, s, t.Elem)
+		if elem == "" || strings.HasPrefix(elem, "[") {
+			return "", ""
+		}
+		return "[" + elem + "]", comment
+	case SchemaMap:
+		return "[" + capnpName(s.names[t], true) + "]", ""
+	case SchemaStruct:
+		return capnpName(s.names[t], true), ""
+	case SchemaNamed:
+		return flatBuffersType(ir, s, ir.Types[t.Name])
+	}
+	return "", ""
+}
+
+// FlatBuffersSchema returns FlatBuffers schema describing parsed documents, see FlatBuffersEmitter.
+func (p *JSONParser) FlatBuffersSchema() (string, error) {
+	out, err := FlatBuffersEmitter{}.Emit(p.Schema())
+	return string(out), err
+}
@@ -0,0 +1,90 @@
+package json2go
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlatBuffersSchema(t *testing.T) {
+	t.Parallel()
+
+	p := NewJSONParser(baseTypeName)
+	require.NoError(t, p.FeedBytes([]byte(`{"id": 1, "user-name": "a", "tags": ["x"], "addr": {"zip": "1"},
+		"at": "2021-01-01T00:00:00Z", "matrix": [[1.5]], "extra": 1}`)))
+	require.NoError(t, p.FeedBytes([]byte(`{"id": 2, "tags": [], "addr": {"zip": "2"},
+		"at": "2021-01-02T00:00:00Z", "matrix": [[1]], "extra": "x", "score": 0.5}`)))
+
+	schema, err := p.FlatBuffersSchema()
+	require.NoError(t, err)
+	assert.Equal(t, `table Document {
+  addr:DocumentAddr (required);
+  at:long;  // Unix time in microseconds.
+  extra:[ubyte] (flexbuffer);
+  id:long;
+  matrix:[ubyte] (flexbuffer);
+  score:double = null;
+  tags:[string] (required);
+  user_name:string;
+}
+
+table DocumentAddr {
+  zip:string (required);
+}
+
+root_type Document;
+`, schema)
+}
+
+func TestFlatBuffersEmitter(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		ir       *Schema
+		expected string
+		err      error
+	}{
+		{
+			name: "recursive type and maps",
+			ir: &Schema{Root: "Node", Types: map[string]*SchemaType{"Node": {Kind: SchemaStruct, Fields: []SchemaField{
+				{Name: "Children", Key: "children", Type: &SchemaType{Kind: SchemaSlice, Elem: &SchemaType{Kind: SchemaNamed, Name: "Node"}}},
+				{Name: "Labels", Key: "labels", Type: &SchemaType{Kind: SchemaMap, Elem: &SchemaType{Kind: SchemaString}}, OmitEmpty: true},
+			}}}},
+			expected: `table Node {
+  children:[Node] (required);
+  labels:[NodeLabelsEntry];
+}
+
+table NodeLabelsEntry {
+  key:string (required, key);
+  value:string (required);
+}
+
+root_type Node;
+`,
+		},
+		{
+			name: "root isn't struct",
+			ir:   &Schema{Root: "Document", Types: map[string]*SchemaType{"Document": {Kind: SchemaString}}},
+			err:  ErrUnsupportedShape,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			out, err := FlatBuffersEmitter{}.Emit(tc.ir)
+			if tc.err != nil {
+				assert.True(t, errors.Is(err, tc.err), "unexpected error: %v", err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(out))
+		})
+	}
+}
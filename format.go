@@ -0,0 +1,236 @@
+package json2go
+
+import (
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Formats of json values. Node keeps formats common for all its values.
+const (
+	// formatDecimal is a number, or a string with a number, with at most 2 decimal places.
+	formatDecimal = 1 << iota
+	// formatBoolString is a string with bool value, like "true", "yes", "Y" or "0".
+	formatBoolString
+	// formatSecret is a string looking like a credential, like JWT, authorization header value or private key.
+	formatSecret
+	// formatDigitsString is a string with integer without separators, like "-12", see NumberLocale.
+	formatDigitsString
+	// formatNumberEnglish, formatNumberGerman, formatNumberFrench and formatNumberSwiss are strings with numbers
+	// formatted in locale, see NumberLocale.
+	formatNumberEnglish
+	formatNumberGerman
+	formatNumberFrench
+	formatNumberSwiss
+	// formatEpochSeconds, formatEpochMillis, formatEpochMicros and formatEpochNanos are integers with magnitude
+	// of Unix time in given units, see EpochUnit.
+	formatEpochSeconds
+	formatEpochMillis
+	formatEpochMicros
+	formatEpochNanos
+	// formatJSONString is a string with json object or array, like "{\"id\":1}", see OptExpandJSONStrings.
+	formatJSONString
+	// formatQueryString is a string with url encoded query or form body, like "a=1&b=2", see OptExpandQueryStrings.
+	formatQueryString
+	// formatProtoDuration is a string with google.protobuf.Duration, like "1.5s", see OptProtoJSON.
+	formatProtoDuration
+	// formatProtoInt64 is a string with 64-bit integer, like int64 values in proto json, see OptProtoJSON.
+	formatProtoInt64
+	// formatJWT is a JWT with json claims, see OptExpandBase64JSON.
+	formatJWT
+	// formatBase64JSON is a base64 string with json object or array, see OptExpandBase64JSON.
+	formatBase64JSON
+	// formatUUID, formatEmail and formatURL are strings with uuids, email addresses and absolute http urls.
+	formatUUID
+	formatEmail
+	formatURL
+	// formatPhone is a string looking like a phone number, formatSSN is a string with US social security number,
+	// see OptPII.
+	formatPhone
+	formatSSN
+
+	formatsAll = formatDecimal | formatBoolString | formatSecret | formatDigitsString |
+		formatNumberEnglish | formatNumberGerman | formatNumberFrench | formatNumberSwiss |
+		formatEpochSeconds | formatEpochMillis | formatEpochMicros | formatEpochNanos | formatJSONString | formatQueryString |
+		formatProtoDuration | formatProtoInt64 | formatJWT | formatBase64JSON | formatUUID | formatEmail | formatURL |
+		formatPhone | formatSSN
+	// formatsEncoded are formats of strings with encoded values, which are decoded into node's encoded tree.
+	formatsEncoded = formatJSONString | formatQueryString | formatJWT | formatBase64JSON
+	// formatsEpoch are formats of integers with Unix times.
+	formatsEpoch = formatEpochSeconds | formatEpochMillis | formatEpochMicros | formatEpochNanos
+)
+
+var (
+	decimalStringRe = regexp.MustCompile(`^-?\d+(\.\d{1,2})?$`)
+	digitsStringRe  = regexp.MustCompile(`^[-+]?\d+$`)
+	jwtRe           = regexp.MustCompile(`^eyJ[\w-]+\.[\w-]+\.[\w-]*$`)
+	emailRe         = regexp.MustCompile(`^[^\s@]+@[^\s@]+\.[^\s@.]+$`)
+	urlRe           = regexp.MustCompile(`^https?://[^\s/?#]+[^\s]*$`)
+)
+
+// valueFormats returns formats matching json value, out of wanted formats. Other formats aren't detected.
+// For arrays, formats common for all elements are returned. Nulls match all formats.
+func valueFormats(v interface{}, wanted int) int {
+	switch typedValue := v.(type) {
+	case nil:
+		return wanted
+	case []interface{}:
+		formats := wanted
+		for _, el := range typedValue {
+			if formats == 0 {
+				break
+			}
+			formats &= valueFormats(el, formats)
+		}
+		return formats
+	case string:
+		return stringFormats(typedValue, wanted)
+	case float64:
+		return numberFormats(typedValue, wanted)
+	case float32:
+		return numberFormats(float64(typedValue), wanted)
+	case int, int8, int16, int32, int64:
+		return numberFormats(float64(reflect.ValueOf(typedValue).Int()), wanted)
+	}
+
+	return 0
+}
+
+// stringFormats returns formats of string s, out of wanted formats.
+func stringFormats(s string, wanted int) int {
+	var formats int
+	if wanted&formatDecimal != 0 && decimalStringRe.MatchString(s) {
+		formats |= formatDecimal
+	}
+	if wanted&formatBoolString != 0 && isBoolString(s) {
+		formats |= formatBoolString
+	}
+	if wanted&formatSecret != 0 && isSecretString(s) {
+		formats |= formatSecret
+	}
+	if wanted&formatDigitsString != 0 && digitsStringRe.MatchString(s) {
+		formats |= formatDigitsString
+	}
+	for _, l := range numberLocales {
+		if wanted&l.format != 0 && l.re.MatchString(s) {
+			formats |= l.format
+		}
+	}
+	if wanted&formatJSONString != 0 && isJSONString(s) {
+		formats |= formatJSONString
+	}
+	if wanted&formatQueryString != 0 && isQueryString(s) {
+		formats |= formatQueryString
+	}
+	if wanted&formatProtoDuration != 0 && protoDurationRe.MatchString(s) {
+		formats |= formatProtoDuration
+	}
+	if wanted&formatProtoInt64 != 0 && protoInt64Re.MatchString(s) {
+		formats |= formatProtoInt64
+	}
+	if wanted&formatJWT != 0 {
+		if _, ok := decodeJWTClaims(s); ok {
+			formats |= formatJWT
+		}
+	}
+	if wanted&formatBase64JSON != 0 {
+		if _, ok := decodeBase64JSON(s); ok {
+			formats |= formatBase64JSON
+		}
+	}
+	if wanted&formatUUID != 0 && uuidRe.MatchString(s) {
+		formats |= formatUUID
+	}
+	if wanted&formatEmail != 0 && emailRe.MatchString(s) {
+		formats |= formatEmail
+	}
+	if wanted&formatURL != 0 && urlRe.MatchString(s) {
+		formats |= formatURL
+	}
+	if wanted&formatPhone != 0 && isPhoneString(s) {
+		formats |= formatPhone
+	}
+	if wanted&formatSSN != 0 && isSSNString(s) {
+		formats |= formatSSN
+	}
+
+	return formats
+}
+
+// isBoolString checks if string is one of known bool representations.
+func isBoolString(s string) bool {
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "y", "n", "1", "0":
+		return true
+	}
+
+	return false
+}
+
+// Kinds of bool strings seen by node. Digits are bools only if both of them are seen, or with other bool strings,
+// so constant "1" stays a string.
+const (
+	boolStringTrue = 1 << iota
+	boolStringFalse
+	boolStringWord
+)
+
+// boolStringKinds returns kinds of bool string s.
+func boolStringKinds(s string) int {
+	switch strings.ToLower(s) {
+	case "1":
+		return boolStringTrue
+	case "0":
+		return boolStringFalse
+	case "true", "yes", "y":
+		return boolStringTrue | boolStringWord
+	case "false", "no", "n":
+		return boolStringFalse | boolStringWord
+	}
+
+	return 0
+}
+
+// isSecretString checks if string looks like a credential.
+func isSecretString(s string) bool {
+	if strings.HasPrefix(s, "Bearer ") || strings.HasPrefix(s, "Basic ") || strings.HasPrefix(s, "-----BEGIN ") {
+		return true
+	}
+	return jwtRe.MatchString(s)
+}
+
+// numberFormats returns formats of number f, out of wanted formats.
+func numberFormats(f float64, wanted int) int {
+	if f == math.Trunc(f) {
+		formats := formatDecimal
+		if wanted&formatsEpoch != 0 {
+			formats |= epochFormats(f)
+		}
+		return formats & wanted
+	}
+	if wanted&formatDecimal == 0 {
+		return 0
+	}
+
+	var formats int
+	repr := strconv.FormatFloat(f, 'f', -1, 64)
+	if i := strings.IndexByte(repr, '.'); i < 0 || len(repr)-i-1 <= 2 {
+		formats |= formatDecimal
+	}
+
+	return formats
+}
+
+// isMoneyKey checks if object key looks like a name of money amount attribute.
+func isMoneyKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, suffix := range []string{"amount", "price", "total"} {
+		if strings.HasSuffix(key, suffix) {
+			return true
+		}
+	}
+
+	return false
+}
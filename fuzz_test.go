@@ -0,0 +1,48 @@
+//go:build go1.18
+// +build go1.18
+
+package json2go
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func FuzzParser(f *testing.F) {
+	files, _ := filepath.Glob("test/parser/*/*.json")
+	for _, name := range files {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(data)
+	}
+	f.Add([]byte(`{"a":[1,"x",[true]],"a":{"b":null}}`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		p := NewJSONParser(
+			baseTypeName,
+			OptExtractCommonTypes(true),
+			OptMakeMaps(true, 2),
+			OptRawMessageForUnstable(true, 3),
+			OptTuples(true),
+			OptMapKeyTypes(true),
+			OptFieldOrder(FieldOrderOriginal),
+			OptDuplicateKeys(DuplicateKeysMergeTypes),
+		)
+		if err := p.FeedBytes(data); err != nil {
+			return
+		}
+
+		out, err := p.Generate()
+		if err != nil {
+			t.Fatalf("generating type: %v", err)
+		}
+		if _, err := parser.ParseFile(token.NewFileSet(), "", "package main\n"+out, 0); err != nil {
+			t.Fatalf("invalid generated code: %v\n%s", err, out)
+		}
+	})
+}
@@ -0,0 +1,148 @@
+package json2go
+
+import (
+	"fmt"
+	"go/ast"
+)
+
+// GeoJSON is a representation of GeoJSON geometries, see OptGeoJSON.
+type GeoJSON int
+
+const (
+	// GeoJSONNone doesn't recognize GeoJSON, geometries are represented like other objects.
+	GeoJSONNone GeoJSON = iota
+	// GeoJSONStructs generates structs of geometries with coordinates of float64 slices, never tuples.
+	// Coordinates of geometries of different types are represented by GeoCoordinates helper type.
+	GeoJSONStructs
+	// GeoJSONOrb represents geometries by geojson.Geometry type of github.com/paulmach/orb/geojson package.
+	// Features and feature collections are still structs, with properties of generated types.
+	GeoJSONOrb
+)
+
+// geoJSONOrbGeometry is a type of geometries represented with GeoJSONOrb.
+const geoJSONOrbGeometry = "github.com/paulmach/orb/geojson.Geometry"
+
+// ParseGeoJSON returns representation of GeoJSON geometries by name: "none", "structs" or "orb".
+// Empty name means none.
+func ParseGeoJSON(name string) (GeoJSON, error) {
+	switch name {
+	case "", "none":
+		return GeoJSONNone, nil
+	case "structs":
+		return GeoJSONStructs, nil
+	case "orb":
+		return GeoJSONOrb, nil
+	}
+	return GeoJSONNone, fmt.Errorf("unknown GeoJSON representation: %s", name)
+}
+
+// isGeoJSONGeometry checks if node is an object with GeoJSON geometry: string type and coordinates,
+// or geometries of geometry collection.
+func isGeoJSONGeometry(n *node) bool {
+	if n.t.id() != nodeTypeObject.id() {
+		return false
+	}
+	t := n.getChild("type")
+	if t == nil || t.t != nodeTypeString || t.arrayLevel > 0 {
+		return false
+	}
+	return n.getChild("coordinates") != nil || n.getChild("geometries") != nil
+}
+
+// applyGeoJSON represents GeoJSON geometries in subtree according to repr.
+func applyGeoJSON(n *node, repr GeoJSON) {
+	if repr == GeoJSONNone {
+		return
+	}
+	if isGeoJSONGeometry(n) {
+		if repr == GeoJSONOrb {
+			n.logf("values are GeoJSON geometries")
+			forceGoType(n, geoJSONOrbGeometry)
+			return
+		}
+		if c := n.getChild("coordinates"); c != nil {
+			n.logf("values are GeoJSON geometries")
+			geoJSONCoordinates(c)
+		}
+	}
+	if bbox := n.getChild("bbox"); bbox != nil && n.t.id() == nodeTypeObject.id() && bbox.t.id() == nodeTypeInt.id() {
+		bbox.t = nodeTypeFloat
+	}
+	for _, c := range n.children {
+		applyGeoJSON(c, repr)
+	}
+}
+
+// geoJSONCoordinates sets type of coordinates: nested slices of float64, when all geometries have the same depth,
+// otherwise GeoCoordinates helper type.
+func geoJSONCoordinates(n *node) {
+	n.tuple = nil
+	n.tupleInvalid = true
+	switch n.t.id() {
+	case nodeTypeInt.id(), nodeTypeFloat.id():
+		n.t = nodeTypeFloat
+	case nodeTypeInterface.id():
+		n.geoCoordinates = true
+		n.arrayLevel = 0
+		n.arrayWithNulls = false
+	}
+}
+
+// astTypeFromGeoCoordinatesNode returns helper type of coordinates of geometries of different types.
+func astTypeFromGeoCoordinatesNode(ctx *astContext) ast.Expr {
+	ctx.addImport("encoding/json")
+	ctx.addImport("errors")
+
+	return ast.NewIdent(ctx.addSharedHelper("GeoCoordinates", func(name string) string {
+		return fmt.Sprintf(`
+// %[1]s are GeoJSON coordinates, nested according to geometry type. Only one field is set.
+type %[1]s struct {
+	// Position is set for points.
+	Position []float64
+	// Positions are set for line strings and multi points.
+	Positions [][]float64
+	// Rings are set for polygons and multi line strings.
+	Rings [][][]float64
+	// Polygons are set for multi polygons.
+	Polygons [][][][]float64
+}
+
+// UnmarshalJSON unmarshals coordinates nested in arrays of any depth.
+func (c *%[1]s) UnmarshalJSON(data []byte) error {
+	*c = %[1]s{}
+	if string(data) == "null" {
+		return nil
+	}
+	if json.Unmarshal(data, &c.Position) == nil {
+		return nil
+	}
+	c.Position = nil
+	if json.Unmarshal(data, &c.Positions) == nil {
+		return nil
+	}
+	c.Positions = nil
+	if json.Unmarshal(data, &c.Rings) == nil {
+		return nil
+	}
+	c.Rings = nil
+	if err := json.Unmarshal(data, &c.Polygons); err != nil {
+		return errors.New("invalid GeoJSON coordinates")
+	}
+	return nil
+}
+
+// MarshalJSON marshals set coordinates.
+func (c %[1]s) MarshalJSON() ([]byte, error) {
+	switch {
+	case c.Position != nil:
+		return json.Marshal(c.Position)
+	case c.Positions != nil:
+		return json.Marshal(c.Positions)
+	case c.Rings != nil:
+		return json.Marshal(c.Rings)
+	}
+	return json.Marshal(c.Polygons)
+}
+`, name)
+	}))
+}
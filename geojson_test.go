@@ -0,0 +1,133 @@
+package json2go
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserGeoJSON(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		repr     GeoJSON
+		inputs   []string
+		field    string
+		expected string
+	}{
+		{
+			name:     "none",
+			repr:     GeoJSONNone,
+			inputs:   []string{`{"type":"Point","coordinates":[1,2]}`, `{"type":"LineString","coordinates":[[1,2],[3,4]]}`},
+			field:    "Coordinates",
+			expected: "interface{}",
+		},
+		{
+			name:     "point",
+			repr:     GeoJSONStructs,
+			inputs:   []string{`{"type":"Point","coordinates":[1,2]}`},
+			field:    "Coordinates",
+			expected: "[]float64",
+		},
+		{
+			name:     "polygon",
+			repr:     GeoJSONStructs,
+			inputs:   []string{`{"type":"Polygon","coordinates":[[[1,2],[3.5,4],[1,2]]]}`},
+			field:    "Coordinates",
+			expected: "[][][]float64",
+		},
+		{
+			name:     "mixed geometries",
+			repr:     GeoJSONStructs,
+			inputs:   []string{`{"type":"Point","coordinates":[1,2]}`, `{"type":"LineString","coordinates":[[1,2],[3,4]]}`},
+			field:    "Coordinates",
+			expected: "GeoCoordinates",
+		},
+		{
+			name:     "bbox",
+			repr:     GeoJSONStructs,
+			inputs:   []string{`{"type":"Point","coordinates":[1,2],"bbox":[1,2,1,2]}`},
+			field:    "Bbox",
+			expected: "[]float64",
+		},
+		{
+			name:     "not geometry",
+			repr:     GeoJSONStructs,
+			inputs:   []string{`{"type":1,"coordinates":[1,2]}`},
+			field:    "Coordinates",
+			expected: "[]int",
+		},
+		{
+			name:     "orb geometry",
+			repr:     GeoJSONOrb,
+			inputs:   []string{`{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"properties":{"a":1}}`},
+			field:    "Geometry",
+			expected: "geojson.Geometry",
+		},
+		{
+			name:     "orb properties",
+			repr:     GeoJSONOrb,
+			inputs:   []string{`{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"properties":{"a":1}}`},
+			field:    "Properties",
+			expected: "struct{...}",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			parser := NewJSONParser(baseTypeName, OptGeoJSON(tc.repr), OptTuples(true))
+			for _, input := range tc.inputs {
+				require.NoError(t, parser.FeedBytes([]byte(input)))
+			}
+
+			var found bool
+			for _, f := range parser.Fields() {
+				if f.Name == tc.field {
+					found = true
+					assert.Equal(t, tc.expected, f.Type)
+				}
+			}
+			assert.True(t, found, "no field %s", tc.field)
+		})
+	}
+}
+
+func TestParserGeoJSONCode(t *testing.T) {
+	t.Parallel()
+
+	parser := NewJSONParser(baseTypeName, OptGeoJSON(GeoJSONStructs))
+	require.NoError(t, parser.FeedBytes([]byte(`{"type":"Point","coordinates":[1,2]}`)))
+	require.NoError(t, parser.FeedBytes([]byte(`{"type":"MultiPolygon","coordinates":[[[[1,2],[3,4],[1,2]]]]}`)))
+
+	out := runGeneratedCode(t, parser, `
+	var d []Document
+	if err := json.NewDecoder(os.Stdin).Decode(&d); err != nil {
+		panic(err)
+	}
+	fmt.Println(d[0].Coordinates.Position, d[1].Coordinates.Polygons)
+	out, err := json.Marshal(d)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(out))
+`, `[{"type":"Point","coordinates":[1.5,2]},{"type":"LineString","coordinates":[[1,2],[3,4]]}]`)
+	assert.Equal(t, "[1.5 2] []\n"+
+		`[{"coordinates":[1.5,2],"type":"Point"},{"coordinates":[[1,2],[3,4]],"type":"LineString"}]`+"\n", out)
+}
+
+func TestParseGeoJSON(t *testing.T) {
+	t.Parallel()
+
+	for name, expected := range map[string]GeoJSON{"": GeoJSONNone, "none": GeoJSONNone, "structs": GeoJSONStructs, "orb": GeoJSONOrb} {
+		repr, err := ParseGeoJSON(name)
+		require.NoError(t, err)
+		assert.Equal(t, expected, repr)
+	}
+	_, err := ParseGeoJSON("geos")
+	assert.Error(t, err)
+}
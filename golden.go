@@ -0,0 +1,185 @@
+package json2go
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Golden fixture layout, relative to package directory.
+const (
+	goldenDir        = "testdata/json2go"
+	goldenConfigFile = "config.yaml"
+	goldenTypesFile  = "types.golden"
+	goldenSampleGlob = "sample_*.json"
+	goldenTestFile   = "json2go_golden_test.go"
+)
+
+// GoldenFixture is a set of json samples with options, and go types generated for them.
+// Fixtures stored with WriteGoldenFixture are checked by test generated with WriteGoldenTest,
+// so changes of generated types, e.g. after upgrading json2go, are detected.
+type GoldenFixture struct {
+	Name    string
+	Config  Config
+	Samples [][]byte
+	// Types are generated go types.
+	Types string
+}
+
+// Generate returns go types generated for fixture samples.
+func (f GoldenFixture) Generate() (string, error) {
+	p := f.Config.NewParser()
+	for i, s := range f.Samples {
+		if err := p.FeedBytes(s); err != nil {
+			return "", fmt.Errorf("sample %d: %w", i+1, err)
+		}
+	}
+	return p.Generate()
+}
+
+// WriteGoldenFixture generates types for fixture samples, and writes fixture to
+// testdata/json2go/<name> directory of package in dir. Types set in fixture are ignored.
+// If config anonymizes values, anonymized samples are written, with config that doesn't anonymize them again.
+func WriteGoldenFixture(dir string, f GoldenFixture) error {
+	if f.Config.Anonymize {
+		anonymizer := NewAnonymizer(f.Config.AnonymizeSeed)
+		samples := make([][]byte, len(f.Samples))
+		for i, s := range f.Samples {
+			anonymized, err := anonymizer.JSON(s)
+			if err != nil {
+				return fmt.Errorf("sample %d: %w", i+1, err)
+			}
+			samples[i] = anonymized
+		}
+		f.Samples = samples
+		f.Config.Anonymize = false
+		f.Config.AnonymizeSeed = 0
+	}
+
+	types, err := f.Generate()
+	if err != nil {
+		return err
+	}
+
+	fixtureDir := filepath.Join(dir, goldenDir, f.Name)
+	if err := os.MkdirAll(fixtureDir, 0755); err != nil {
+		return err
+	}
+	old, _ := filepath.Glob(filepath.Join(fixtureDir, goldenSampleGlob))
+	for _, path := range old {
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+
+	config, err := yaml.Marshal(f.Config)
+	if err != nil {
+		return err
+	}
+	files := map[string][]byte{
+		goldenConfigFile: config,
+		goldenTypesFile:  []byte(types + "\n"),
+	}
+	for i, s := range f.Samples {
+		files[fmt.Sprintf("sample_%03d.json", i+1)] = s
+	}
+	for name, data := range files {
+		if err := ioutil.WriteFile(filepath.Join(fixtureDir, name), data, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadGoldenFixture reads fixture from directory written by WriteGoldenFixture.
+func ReadGoldenFixture(fixtureDir string) (GoldenFixture, error) {
+	f := GoldenFixture{Name: filepath.Base(fixtureDir)}
+
+	config, err := ioutil.ReadFile(filepath.Join(fixtureDir, goldenConfigFile))
+	if err != nil {
+		return f, err
+	}
+	if err := yaml.Unmarshal(config, &f.Config); err != nil {
+		return f, fmt.Errorf("%s: %w", goldenConfigFile, err)
+	}
+
+	types, err := ioutil.ReadFile(filepath.Join(fixtureDir, goldenTypesFile))
+	if err != nil {
+		return f, err
+	}
+	f.Types = strings.TrimSuffix(string(types), "\n")
+
+	paths, err := filepath.Glob(filepath.Join(fixtureDir, goldenSampleGlob))
+	if err != nil {
+		return f, err
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		s, err := ioutil.ReadFile(path)
+		if err != nil {
+			return f, err
+		}
+		f.Samples = append(f.Samples, s)
+	}
+	return f, nil
+}
+
+// WriteGoldenTest writes table-driven test of package pkg in dir, checking all fixtures in testdata/json2go.
+// Run test with -update-json2go flag to regenerate types of fixtures.
+func WriteGoldenTest(dir, pkg string) error {
+	src := strings.Replace(goldenTestSrc, "package golden", "package "+pkg, 1)
+	return ioutil.WriteFile(filepath.Join(dir, goldenTestFile), []byte(src), 0644)
+}
+
+const goldenTestSrc = `// Code generated by json2go. DO NOT EDIT.
+
+package golden
+
+import (
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/heucoder/json2go"
+)
+
+var updateJSON2Go = flag.Bool("update-json2go", false, "update types generated by json2go in testdata/json2go")
+
+// TestJSON2GoGolden checks that json2go generates the same types for samples in testdata/json2go.
+func TestJSON2GoGolden(t *testing.T) {
+	dirs, err := filepath.Glob("testdata/json2go/*")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, dir := range dirs {
+		dir := dir
+		t.Run(filepath.Base(dir), func(t *testing.T) {
+			fixture, err := json2go.ReadGoldenFixture(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			types, err := fixture.Generate()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *updateJSON2Go {
+				if err := ioutil.WriteFile(filepath.Join(dir, "types.golden"), []byte(types+"\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if types != fixture.Types {
+				t.Errorf("generated types changed, run tests with -update-json2go to accept changes\nwant:\n%s\n\ngot:\n%s", fixture.Types, types)
+			}
+		})
+	}
+}
+`
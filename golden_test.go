@@ -0,0 +1,104 @@
+package json2go
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoldenFixture(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	fixture := GoldenFixture{
+		Name:    "users",
+		Config:  Config{RootName: "User", SkipEmptyKeys: true},
+		Samples: [][]byte{[]byte(`{"id":1,"name":"x"}`), []byte(`{"id":2,"tags":["a"]}`)},
+	}
+	require.NoError(t, WriteGoldenFixture(dir, fixture))
+
+	read, err := ReadGoldenFixture(filepath.Join(dir, "testdata", "json2go", "users"))
+	require.NoError(t, err)
+	assert.Equal(t, fixture.Config, read.Config)
+	assert.Equal(t, fixture.Samples, read.Samples)
+	assert.Equal(t, `type User struct {
+	ID   int      `+"`json:\"id\"`"+`
+	Name string   `+"`json:\"name,omitempty\"`"+`
+	Tags []string `+"`json:\"tags,omitempty\"`"+`
+}`, read.Types)
+
+	// Rewriting fixture replaces samples.
+	fixture.Samples = fixture.Samples[:1]
+	require.NoError(t, WriteGoldenFixture(dir, fixture))
+	read, err = ReadGoldenFixture(filepath.Join(dir, "testdata", "json2go", "users"))
+	require.NoError(t, err)
+	assert.Len(t, read.Samples, 1)
+}
+
+func TestGoldenFixtureAnonymized(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	fixture := GoldenFixture{
+		Name:    "users",
+		Config:  Config{RootName: "User", Anonymize: true, AnonymizeSeed: 1},
+		Samples: [][]byte{[]byte(`{"name": "john", "email": "john@acme.com"}`)},
+	}
+	require.NoError(t, WriteGoldenFixture(dir, fixture))
+
+	read, err := ReadGoldenFixture(filepath.Join(dir, "testdata", "json2go", "users"))
+	require.NoError(t, err)
+	assert.Equal(t, Config{RootName: "User"}, read.Config)
+	require.Len(t, read.Samples, 1)
+	assert.Regexp(t, `^\{"name":"[a-z]{4}","email":"[a-z]{4}@example\.com"\}$`, string(read.Samples[0]))
+	assert.Equal(t, `type User struct {
+	Email string `+"`json:\"email\"`"+`
+	Name  string `+"`json:\"name\"`"+`
+}`, read.Types)
+}
+
+func TestGoldenTest(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs go test")
+	}
+
+	moduleDir, err := os.Getwd()
+	require.NoError(t, err)
+	goSum, err := ioutil.ReadFile(filepath.Join(moduleDir, "go.sum"))
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	goMod := fmt.Sprintf("module example.com/api\n\ngo 1.13\n\nrequire github.com/heucoder/json2go v0.0.0\n\nreplace github.com/heucoder/json2go => %s\n", moduleDir)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "go.sum"), goSum, 0600))
+	require.NoError(t, WriteGoldenTest(dir, "api"))
+	require.NoError(t, WriteGoldenFixture(dir, GoldenFixture{Name: "events", Samples: [][]byte{[]byte(`{"id":1}`)}}))
+
+	goTest := func(args ...string) (string, error) {
+		cmd := exec.Command("go", append([]string{"test", "-mod=mod", "."}, args...)...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		return string(out), err
+	}
+
+	out, err := goTest()
+	require.NoError(t, err, out)
+
+	// Changed types are detected, and can be accepted.
+	typesFile := filepath.Join(dir, "testdata", "json2go", "events", "types.golden")
+	require.NoError(t, ioutil.WriteFile(typesFile, []byte("type Document struct{}\n"), 0600))
+	out, err = goTest()
+	assert.Error(t, err)
+	assert.Contains(t, out, "generated types changed")
+
+	out, err = goTest("-update-json2go")
+	require.NoError(t, err, out)
+	out, err = goTest()
+	require.NoError(t, err, out)
+}
@@ -0,0 +1,136 @@
+package json2go
+
+import "fmt"
+
+// HAL is a handling of HAL hypermedia conventions: "_links" and "_embedded" members of resource objects.
+type HAL int
+
+const (
+	// HALNone doesn't recognize HAL, links are represented like other objects.
+	HALNone HAL = iota
+	// HALTyped represents all links by one shared Link type, and embedded resources by named types.
+	HALTyped
+	// HALStrip removes links and HAL-FORMS templates from generated types, only resource state is kept.
+	HALStrip
+)
+
+// halLinkTypeName is a base name of shared type of HAL links.
+const halLinkTypeName = "Link"
+
+// ParseHAL returns handling of HAL conventions by name: "none", "typed" or "strip". Empty name means none.
+func ParseHAL(name string) (HAL, error) {
+	switch name {
+	case "", "none":
+		return HALNone, nil
+	case "typed":
+		return HALTyped, nil
+	case "strip":
+		return HALStrip, nil
+	}
+	return HALNone, fmt.Errorf("unknown HAL handling: %s", name)
+}
+
+// isHALLinks checks if node is a "_links" object, with link objects or arrays of link objects by relation.
+func isHALLinks(n *node) bool {
+	if n.key != "_links" || n.t.id() != nodeTypeObject.id() || n.arrayLevel > 0 || len(n.children) == 0 {
+		return false
+	}
+	for _, c := range n.children {
+		if !isHALLink(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// isHALLink checks if node is a link object, with string href.
+func isHALLink(n *node) bool {
+	if n.t.id() != nodeTypeObject.id() || n.arrayLevel > 1 {
+		return false
+	}
+	href := n.getChild("href")
+	return href != nil && href.t == nodeTypeString && href.arrayLevel == 0
+}
+
+// applyHAL handles HAL links and embedded resources in subtree. With HALTyped, link nodes refer to shared type
+// and embedded resources are marked for extraction, see extractMarkedStructs. Node of shared type of all links
+// is returned, or nil if there are no links.
+func applyHAL(root *node, handling HAL) (*node, error) {
+	if handling == HALNone {
+		return nil, nil
+	}
+
+	var links []*node
+	var walk func(n *node)
+	walk = func(n *node) {
+		if n.t.id() == nodeTypeObject.id() {
+			var children []*node
+			for _, c := range n.children {
+				switch {
+				case handling == HALStrip && (c.key == "_templates" || isHALLinks(c)):
+					n.logf("%s removed, it's HAL hypermedia", c.key)
+					continue
+				case handling == HALTyped && isHALLinks(c):
+					links = append(links, c.children...)
+				case handling == HALTyped && c.key == "_embedded" && c.t.id() == nodeTypeObject.id() && c.arrayLevel == 0:
+					for _, e := range c.children {
+						e.halEmbedded = e.t.id() == nodeTypeObject.id()
+					}
+				}
+				children = append(children, c)
+			}
+			n.children = children
+		}
+		for _, c := range n.children {
+			walk(c)
+		}
+	}
+	walk(root)
+	if len(links) == 0 {
+		return nil, nil
+	}
+
+	merged, err := mergeNodes(links)
+	if err != nil {
+		return nil, err
+	}
+	link := merged.clone()
+	link.name = halLinkTypeName
+	link.root = true
+	link.document = false
+	link.arrayLevel = 0
+	link.arrayWithNulls = false
+	link.nullable = false
+	link.required = true
+	for _, n := range links {
+		n.logf("values are HAL links")
+		forceGoType(n, "")
+		n.halLink = true
+	}
+	return link, nil
+}
+
+// addHALLinkType adds shared type of HAL links to extracted types, with unique name.
+func addHALLinkType(nodes []*node, link *node) []*node {
+	used := make(map[string]bool)
+	for _, n := range nodes {
+		used[n.name] = true
+	}
+	for used[link.name] {
+		link.name = nextName(link.name)
+	}
+
+	var walk func(n *node)
+	walk = func(n *node) {
+		if n.halLink {
+			n.externalTypeID = link.name
+		}
+		for _, c := range n.children {
+			walk(c)
+		}
+	}
+	for _, n := range nodes {
+		walk(n)
+	}
+	return append(nodes, link)
+}
@@ -0,0 +1,112 @@
+package json2go
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const halTestDocument = `{
+	"_links": {"self": {"href": "/orders"}, "find": {"href": "/orders{?id}", "templated": true}},
+	"_embedded": {
+		"orders": [
+			{"_links": {"self": {"href": "/orders/123"}, "customer": {"href": "/customers/7809"}}, "total": 30.5},
+			{"_links": {"self": {"href": "/orders/124"}, "customer": {"href": "/customers/12369"}}, "total": 20}
+		]
+	},
+	"count": 2
+}`
+
+func TestParserHAL(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		handling HAL
+		extract  bool
+		input    string
+		expected string
+	}{
+		{
+			name:     "none",
+			handling: HALNone,
+			input:    halTestDocument,
+			expected: "\t\tFind struct {\n\t\t\tHref      string `json:\"href\"`",
+		},
+		{
+			name:     "shared link type",
+			handling: HALTyped,
+			input:    halTestDocument,
+			expected: "type Link struct {\n" +
+				"\tHref      string `json:\"href\"`\n" +
+				"\tTemplated *bool  `json:\"templated,omitempty\"`\n" +
+				"}",
+		},
+		{
+			name:     "links",
+			handling: HALTyped,
+			input:    halTestDocument,
+			expected: "\t\tCustomer Link `json:\"customer\"`\n\t\tSelf     Link `json:\"self\"`\n",
+		},
+		{
+			name:     "embedded resources",
+			handling: HALTyped,
+			input:    halTestDocument,
+			expected: "\t\tOrders []Order `json:\"orders\"`\n",
+		},
+		{
+			name:     "link type name taken",
+			handling: HALTyped,
+			extract:  true,
+			input:    `{"_links":{"self":{"href":"/a"}},"a":{"link":{"url":"/b"}},"b":{"link":{"url":"/c"}}}`,
+			expected: "type Link3 struct {\n\tHref string `json:\"href\"`\n}",
+		},
+		{
+			name:     "not links",
+			handling: HALTyped,
+			input:    `{"_links":{"self":"/a"}}`,
+			expected: "\tLinks struct {\n\t\tSelf string `json:\"self\"`\n\t} `json:\"_links\"`",
+		},
+		{
+			name:     "strip",
+			handling: HALStrip,
+			input:    halTestDocument,
+			expected: "type Document struct {\n" +
+				"\tCount    int `json:\"count\"`\n" +
+				"\tEmbedded struct {\n" +
+				"\t\tOrders []struct {\n" +
+				"\t\t\tTotal float64 `json:\"total\"`\n" +
+				"\t\t} `json:\"orders\"`\n" +
+				"\t} `json:\"_embedded\"`\n" +
+				"}",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			parser := NewJSONParser(baseTypeName, OptHAL(tc.handling), OptExtractCommonTypes(tc.extract))
+			require.NoError(t, parser.FeedBytes([]byte(tc.input)))
+			out, err := parser.Generate()
+			require.NoError(t, err)
+			assert.Contains(t, out, tc.expected)
+			_, err = MarshalIR(parser.Schema())
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestParseHAL(t *testing.T) {
+	t.Parallel()
+
+	for name, expected := range map[string]HAL{"": HALNone, "none": HALNone, "typed": HALTyped, "strip": HALStrip} {
+		handling, err := ParseHAL(name)
+		require.NoError(t, err)
+		assert.Equal(t, expected, handling)
+	}
+	_, err := ParseHAL("siren")
+	assert.Error(t, err)
+}
@@ -0,0 +1,164 @@
+package json2go
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// defaultHeaderTemplate renders header marking output as generated code, recognized by go tooling.
+const defaultHeaderTemplate = `// Code generated by json2go{{if .Version}} {{.Version}}{{end}}. DO NOT EDIT.
+{{- if .Command}}
+// Command: {{.Command}}
+{{- end}}
+{{- if .Config}}
+// Config: {{.Config}}
+{{- end}}
+{{- if not .Time.IsZero}}
+// Generated at: {{.Time.Format "2006-01-02T15:04:05Z07:00"}}
+{{- end}}
+{{- range .Sources}}
+// Source: {{.Name}} (sha256:{{.SHA256}})
+{{- end}}`
+
+// HeaderData is a description of generation, used to execute header template set with OptHeader.
+type HeaderData struct {
+	// Version is a version of json2go module, empty if unknown.
+	Version string
+	// Time is a time of generation, zero unless enabled with OptHeaderTimestamp.
+	Time time.Time
+	// Command is a command line generating code, set with OptHeaderCommand.
+	Command string
+	// Config is a json encoded resolved config, set with OptHeaderConfig. Default template renders it in line
+	// starting with "// Config: ", read by ReadConfig.
+	Config string
+	// Sources are inputs of parser, added with AddHeaderSource.
+	Sources []HeaderSource
+}
+
+// HeaderSource is a parsed input described in header.
+type HeaderSource struct {
+	// Name is a name of input, like file name.
+	Name string
+	// SHA256 is a hex encoded sha256 hash of input.
+	SHA256 string
+}
+
+// OptHeader toggles comment header at the beginning of generated code, with generation metadata.
+// Header is a text/template executed with HeaderData, which has to render go comments. Empty template means default
+// header, marking code as generated, with json2go version, command line and hashes of sources, if set.
+// If template is invalid, Generate returns error. Header doesn't change between runs with the same inputs,
+// unless timestamp is enabled with OptHeaderTimestamp.
+func OptHeader(v bool, tmpl string) JSONParserOpt {
+	return func(o *options) {
+		o.header = v
+		if tmpl == "" {
+			tmpl = defaultHeaderTemplate
+		}
+		o.headerTemplate, o.headerTemplateErr = template.New("header").Funcs(outputTemplateFuncs).Parse(tmpl)
+	}
+}
+
+// OptHeaderTimestamp toggles time of generation in header, see OptHeader. If SOURCE_DATE_EPOCH environment
+// variable is set, it's used as the time, for reproducible builds.
+func OptHeaderTimestamp(v bool) JSONParserOpt {
+	return func(o *options) {
+		o.headerTimestamp = v
+	}
+}
+
+// OptHeaderCommand sets command line generating code, described in header, see OptHeader.
+// Arguments with spaces or special characters are quoted.
+func OptHeaderCommand(args ...string) JSONParserOpt {
+	return func(o *options) {
+		quoted := make([]string, len(args))
+		for i, arg := range args {
+			quoted[i] = arg
+			if arg == "" || strings.ContainsAny(arg, " \t\n\"'`\\$*?;&|<>()") {
+				quoted[i] = strconv.Quote(arg)
+			}
+		}
+		o.headerCommand = strings.Join(quoted, " ")
+	}
+}
+
+// AddHeaderSource adds input with name, like file name, to sources described in header, see OptHeader.
+// Data isn't parsed, only hashed.
+func (p *JSONParser) AddHeaderSource(name string, data []byte) {
+	sum := sha256.Sum256(data)
+	p.headerSources = append(p.headerSources, HeaderSource{Name: name, SHA256: hex.EncodeToString(sum[:])})
+}
+
+// header returns rendered header, or empty string if it's disabled.
+func (p *JSONParser) header() (string, error) {
+	if !p.opts.header {
+		return "", nil
+	}
+	if p.opts.headerTemplateErr != nil {
+		return "", fmt.Errorf("invalid header template: %w", p.opts.headerTemplateErr)
+	}
+	if p.opts.headerConfigErr != nil {
+		return "", fmt.Errorf("resolving config of header: %w", p.opts.headerConfigErr)
+	}
+	data := HeaderData{
+		Version: moduleVersion(),
+		Command: p.opts.headerCommand,
+		Config:  p.opts.headerConfig,
+		Sources: p.headerSources,
+	}
+	if p.opts.headerTimestamp {
+		data.Time = generationTime()
+	}
+
+	var buf bytes.Buffer
+	if err := p.opts.headerTemplate.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("executing header template: %w", err)
+	}
+	header := strings.TrimSpace(buf.String())
+	for _, line := range strings.Split(header, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "//") {
+			return "", fmt.Errorf("header template rendered line, which isn't a comment: %q", line)
+		}
+	}
+	return header, nil
+}
+
+// generationTime returns current time in UTC, or time set with SOURCE_DATE_EPOCH environment variable.
+func generationTime() time.Time {
+	if epoch, err := strconv.ParseInt(os.Getenv("SOURCE_DATE_EPOCH"), 10, 64); err == nil {
+		return time.Unix(epoch, 0).UTC()
+	}
+	return time.Now().UTC().Truncate(time.Second)
+}
+
+// moduleVersion returns version of json2go module built into binary, or empty string if it's unknown,
+// like in tests and builds of working copies.
+func moduleVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	module := &info.Main
+	for _, dep := range info.Deps {
+		if dep.Path == modulePath {
+			module = dep
+		}
+	}
+	if module.Path != modulePath || module.Version == "(devel)" {
+		return ""
+	}
+	if module.Replace != nil {
+		return module.Replace.Version
+	}
+	return module.Version
+}
+
+// modulePath is an import path of json2go module.
+const modulePath = "github.com/heucoder/json2go"
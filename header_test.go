@@ -0,0 +1,108 @@
+package json2go
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptHeader(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		opts     []JSONParserOpt
+		expected string
+		err      string
+	}{
+		{
+			name: "disabled",
+			opts: []JSONParserOpt{OptHeaderCommand("json2go")},
+			expected: `type Document struct {
+	A int ` + "`json:\"a\"`" + `
+}`,
+		},
+		{
+			name: "default",
+			opts: []JSONParserOpt{OptHeader(true, ""), OptHeaderCommand("json2go", "-n", "My Root", "-tag", "")},
+			expected: `// Code generated by json2go. DO NOT EDIT.
+// Command: json2go -n "My Root" -tag ""
+// Source: input.json (sha256:f9d86028c6e0d64e225186f96acb69338b2c59764df79162107f5c4bb34d1310)
+
+type Document struct {
+	A int ` + "`json:\"a\"`" + `
+}`,
+		},
+		{
+			name: "template",
+			opts: []JSONParserOpt{OptHeader(true, `// Generated from{{range .Sources}} {{.Name}}{{end}}.
+// Timestamp: {{.Time.IsZero}}`)},
+			expected: `// Generated from input.json.
+// Timestamp: true
+
+type Document struct {
+	A int ` + "`json:\"a\"`" + `
+}`,
+		},
+		{
+			name: "output template",
+			opts: []JSONParserOpt{OptHeader(true, "// Generated."), OptOutputTemplate(`
+{{- define "methods"}}func (d *{{.Name}}) Reset() { *d = {{.Name}}{} }{{end}}`)},
+			expected: `// Generated.
+
+type Document struct {
+	A int ` + "`json:\"a\"`" + `
+}
+
+func (d *Document) Reset() { *d = Document{} }`,
+		},
+		{
+			name: "invalid template",
+			opts: []JSONParserOpt{OptHeader(true, "// {{.Version")},
+			err:  "invalid header template",
+		},
+		{
+			name: "not comment",
+			opts: []JSONParserOpt{OptHeader(true, "package main")},
+			err:  `header template rendered line, which isn't a comment: "package main"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := NewJSONParser(baseTypeName, tc.opts...)
+			input := []byte(`{"a": 1}`)
+			require.NoError(t, p.FeedBytes(input))
+			p.AddHeaderSource("input.json", input)
+
+			out, err := p.Generate()
+			if tc.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, out)
+			assert.Equal(t, tc.expected, p.String())
+		})
+	}
+}
+
+func TestOptHeaderTimestamp(t *testing.T) {
+	t.Parallel()
+
+	p := NewJSONParser(baseTypeName, OptHeader(true, ""), OptHeaderTimestamp(true))
+	require.NoError(t, p.FeedBytes([]byte(`{"a": 1}`)))
+
+	out, err := p.Generate()
+	require.NoError(t, err)
+	assert.Regexp(t, regexp.MustCompile(`^// Code generated by json2go\. DO NOT EDIT\.
+// Generated at: \d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}Z
+
+type Document struct`), out)
+}
@@ -0,0 +1,94 @@
+package json2go
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// httpError is an error response of http API.
+type httpError struct {
+	status  int
+	code    int
+	message string
+}
+
+func (e httpError) Error() string {
+	if e.code != 0 {
+		return fmt.Sprintf("%d %s (error code %d)", e.status, e.message, e.code)
+	}
+	return fmt.Sprintf("%d %s", e.status, e.message)
+}
+
+// httpJSONRequest is a request to http API with json request and response bodies.
+type httpJSONRequest struct {
+	client      *http.Client // http.DefaultClient if nil
+	method      string
+	url         string
+	contentType string
+	accept      string
+	body        interface{} // encoded as json, if not nil
+}
+
+// do sends request and decodes response body into result, if it's not nil.
+// Error responses are returned as httpError.
+func (r httpJSONRequest) do(ctx context.Context, result interface{}) error {
+	var reqBody io.Reader
+	if r.body != nil {
+		data, err := json.Marshal(r.body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(r.method, r.url, reqBody)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", r.accept)
+	if r.body != nil {
+		req.Header.Set("Content-Type", r.contentType)
+	}
+
+	client := r.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		// Confluent and RabbitMQ APIs error formats.
+		var e struct {
+			ErrorCode int    `json:"error_code"`
+			Message   string `json:"message"`
+			Reason    string `json:"reason"`
+		}
+		_ = json.Unmarshal(data, &e)
+		if e.Message == "" {
+			e.Message = e.Reason
+		}
+		if e.Message == "" {
+			e.Message = http.StatusText(resp.StatusCode)
+		}
+		return httpError{status: resp.StatusCode, code: e.ErrorCode, message: e.Message}
+	}
+
+	if result == nil || len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, result)
+}
@@ -0,0 +1,96 @@
+package json2go
+
+import "fmt"
+
+// idlDecl is a struct declared in schema of serialization format with declared types, like Cap'n Proto.
+type idlDecl struct {
+	name   string
+	t      *SchemaType
+	fields []SchemaField
+	// entry is true for entries of maps, with key and value fields.
+	entry bool
+}
+
+// idlSchema are structs of IR declared in schema of serialization format, root struct first.
+type idlSchema struct {
+	decls []idlDecl
+	// names are names of declared structs and of map entries, by type.
+	names map[*SchemaType]string
+}
+
+// newIDLSchema returns declarations of structs of IR. Declared types keep names, anonymous structs are named
+// after their parents and fields, like "DocumentAddress". Maps are lists of entries with key and value fields,
+// declared as structs named like "DocumentLabelsEntry", if format has either no maps or maps with limitations.
+func newIDLSchema(ir *Schema, what string, entries bool) (*idlSchema, error) {
+	if err := validateIR(ir); err != nil {
+		return nil, err
+	}
+	s := &idlSchema{names: make(map[*SchemaType]string)}
+	used := make(map[string]bool)
+	for name, t := range ir.Types {
+		if t.Kind == SchemaStruct {
+			s.names[t] = name
+		}
+		used[name] = true
+	}
+	root := irElemType(ir, ir.Types[ir.Root])
+	if root.Kind == SchemaSlice {
+		// Array documents are sequences of messages.
+		root = irElemType(ir, root.Elem)
+		if _, ok := s.names[root]; !ok {
+			s.names[root] = ir.Root
+		}
+	}
+	if root.Kind != SchemaStruct {
+		return nil, fmt.Errorf("%w: %s needs struct root type, not %s", ErrUnsupportedShape, what, root.Kind)
+	}
+
+	visited := make(map[*SchemaType]bool)
+	var visit func(t *SchemaType, name string)
+	visit = func(t *SchemaType, name string) {
+		switch t.Kind {
+		case SchemaPointer, SchemaOptional, SchemaSlice:
+			visit(t.Elem, name)
+		case SchemaNamed:
+			visit(ir.Types[t.Name], t.Name)
+		case SchemaMap:
+			if !entries {
+				visit(t.Elem, name)
+				break
+			}
+			fallthrough
+		case SchemaStruct:
+			if visited[t] {
+				return
+			}
+			visited[t] = true
+			fields := t.Fields
+			if t.Kind == SchemaMap {
+				name += "Entry"
+				key := t.Key
+				if key == nil {
+					key = &SchemaType{Kind: SchemaString}
+				}
+				fields = []SchemaField{{Name: "Key", Key: "key", Type: key}, {Name: "Value", Key: "value", Type: t.Elem}}
+			}
+			declName, ok := s.names[t]
+			if !ok {
+				for declName = name; used[declName]; declName = nextName(declName) {
+				}
+				used[declName] = true
+				s.names[t] = declName
+			}
+			s.decls = append(s.decls, idlDecl{name: declName, t: t, fields: fields, entry: t.Kind == SchemaMap})
+			for _, f := range fields {
+				visit(f.Type, declName+f.Name)
+			}
+		}
+	}
+	visit(root, ir.Root)
+	return s, nil
+}
+
+// isOptional checks if field can be missing: it has pointer or optional type, or omitempty.
+func (f SchemaField) isOptional() bool {
+	return f.OmitEmpty || f.OmitZero || f.Type.Kind == SchemaPointer || f.Type.Kind == SchemaOptional
+}
@@ -0,0 +1,203 @@
+package json2go
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// goTypeRe matches qualified go types, like "github.com/google/uuid.UUID".
+var goTypeRe = regexp.MustCompile(`^(\S*/)?[^/\s.]+(\.[^/\s.]+)*\.([A-Z]\w*)$`)
+
+// isGoType checks if override type is a qualified go type, like "github.com/google/uuid.UUID" or "time.Duration".
+func isGoType(t string) bool {
+	return goTypeRe.MatchString(t)
+}
+
+// splitGoType splits qualified go type into import path and type name.
+func splitGoType(t string) (importPath, typeName string) {
+	i := strings.LastIndex(t, ".")
+	return t[:i], t[i+1:]
+}
+
+// majorVersionRe matches major version suffixes of import paths, like "v2".
+var majorVersionRe = regexp.MustCompile(`^v[0-9]+$`)
+
+// packageName returns conventional name of package with given import path, and whether it differs from
+// last element of path, so import needs alias. E.g. "github.com/mattn/go-sqlite3" is "sqlite3",
+// "gopkg.in/yaml.v2" is "yaml" and "github.com/jackc/pgx/v5" is "pgx", without alias.
+func packageName(importPath string) (string, bool) {
+	elems := strings.Split(importPath, "/")
+	last := elems[len(elems)-1]
+	if majorVersionRe.MatchString(last) && len(elems) > 1 {
+		last = elems[len(elems)-2]
+	}
+	if i := strings.Index(last, ".v"); i > 0 && majorVersionRe.MatchString(last[i+1:]) {
+		return last[:i], false
+	}
+
+	name := strings.TrimSuffix(strings.TrimPrefix(last, "go-"), "-go")
+	name = strings.Map(func(r rune) rune {
+		if r == '_' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9') {
+			return r
+		}
+		return -1
+	}, strings.ToLower(name))
+	if name == "" || !token.IsIdentifier(name) || token.Lookup(name).IsKeyword() {
+		name = "pkg" + name
+	}
+	return name, name != last
+}
+
+// reservedPackages returns names of packages used by generated code.
+func reservedPackages(opts options) []string {
+	return []string{"json", "time", "fmt", "strings", "sort", "path", "reflect", "strconv", "uuid", path.Base(opts.decimalImport)}
+}
+
+// importName returns name of package used in generated code, and marks it as imported.
+// Package is imported with alias if its name differs from last element of import path,
+// or other package with the same name is used.
+func (ctx *astContext) importName(importPath string) string {
+	if name, ok := ctx.importNames[importPath]; ok {
+		return name
+	}
+
+	used := make(map[string]bool)
+	for _, name := range ctx.importNames {
+		used[name] = true
+	}
+	for _, name := range reservedPackages(ctx.opts) {
+		used[name] = true
+	}
+	name, alias := packageName(importPath)
+	if used[name] && !ctx.isReservedImport(importPath, name) {
+		for used[name] {
+			name = nextName(name)
+		}
+		alias = true
+	}
+
+	ctx.importNames[importPath] = name
+	if alias {
+		ctx.importAliases[importPath] = name
+	}
+	ctx.addImport(importPath)
+	return name
+}
+
+// isReservedImport checks if package is the one used by generated code under given name.
+func (ctx *astContext) isReservedImport(importPath, name string) bool {
+	switch name {
+	case "uuid":
+		return importPath == "github.com/google/uuid"
+	case path.Base(ctx.opts.decimalImport):
+		return importPath == ctx.opts.decimalImport
+	}
+	return importPath == name || importPath == "encoding/"+name || importPath == "path/"+name
+}
+
+// importSpecs returns sorted list of imports of used packages, like `"time"` or `sqlite3 "github.com/mattn/go-sqlite3"`.
+func (ctx *astContext) importSpecs() []string {
+	var specs []string
+	for _, p := range ctx.importsList() {
+		spec := strconv.Quote(p)
+		if alias, ok := ctx.importAliases[p]; ok {
+			spec = alias + " " + spec
+		}
+		specs = append(specs, spec)
+	}
+	return specs
+}
+
+// goModule is a description of go module, read from go.mod file.
+type goModule struct {
+	path     string
+	requires []string
+}
+
+// readGoModule reads go.mod file of module containing given directory.
+func readGoModule(dir string) (*goModule, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		data, err := ioutil.ReadFile(filepath.Join(dir, "go.mod"))
+		if err == nil {
+			return parseGoModule(data)
+		}
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, fmt.Errorf("go.mod not found")
+		}
+		dir = parent
+	}
+}
+
+// parseGoModule parses module path and required modules from go.mod file.
+func parseGoModule(data []byte) (*goModule, error) {
+	m := &goModule{}
+	inRequire := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch {
+		case inRequire && fields[0] == ")":
+			inRequire = false
+		case inRequire:
+			m.requires = append(m.requires, unquoteModulePath(fields[0]))
+		case fields[0] == "module" && len(fields) > 1:
+			m.path = unquoteModulePath(fields[1])
+		case fields[0] == "require" && len(fields) > 1 && fields[1] == "(":
+			inRequire = true
+		case fields[0] == "require" && len(fields) > 1:
+			m.requires = append(m.requires, unquoteModulePath(fields[1]))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if m.path == "" {
+		return nil, fmt.Errorf("go.mod has no module directive")
+	}
+	return m, nil
+}
+
+func unquoteModulePath(s string) string {
+	if p, err := strconv.Unquote(s); err == nil {
+		return p
+	}
+	return s
+}
+
+// provides checks if package with given import path is standard, or belongs to module or its requirements.
+func (m *goModule) provides(importPath string) bool {
+	if isStdPackage(importPath) {
+		return true
+	}
+	for _, mp := range append([]string{m.path}, m.requires...) {
+		if importPath == mp || strings.HasPrefix(importPath, mp+"/") {
+			return true
+		}
+	}
+	return false
+}
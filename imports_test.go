@@ -0,0 +1,130 @@
+package json2go
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackageName(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		importPath string
+		name       string
+		alias      bool
+	}{
+		{importPath: "time", name: "time"},
+		{importPath: "encoding/json", name: "json"},
+		{importPath: "github.com/google/uuid", name: "uuid"},
+		{importPath: "github.com/jackc/pgx/v5", name: "pgx"},
+		{importPath: "gopkg.in/yaml.v2", name: "yaml"},
+		{importPath: "github.com/mattn/go-sqlite3", name: "sqlite3", alias: true},
+		{importPath: "example.com/my.pkg", name: "mypkg", alias: true},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.importPath, func(t *testing.T) {
+			t.Parallel()
+
+			name, alias := packageName(tc.importPath)
+			assert.Equal(t, tc.name, name)
+			assert.Equal(t, tc.alias, alias)
+		})
+	}
+}
+
+func TestParseGoModule(t *testing.T) {
+	t.Parallel()
+
+	m, err := parseGoModule([]byte(`module example.com/app // comment
+
+go 1.21
+
+require github.com/google/uuid v1.6.0
+
+require (
+	"github.com/jackc/pgx/v5" v5.5.0
+	gopkg.in/yaml.v2 v2.4.0 // indirect
+)
+`))
+	require.NoError(t, err)
+	assert.Equal(t, "example.com/app", m.path)
+	assert.Equal(t, []string{"github.com/google/uuid", "github.com/jackc/pgx/v5", "gopkg.in/yaml.v2"}, m.requires)
+
+	assert.True(t, m.provides("time"))
+	assert.True(t, m.provides("example.com/app/internal/ids"))
+	assert.True(t, m.provides("github.com/jackc/pgx/v5/pgtype"))
+	assert.False(t, m.provides("github.com/jackc/pgx"))
+	assert.False(t, m.provides("example.com/application"))
+
+	_, err = parseGoModule([]byte("go 1.21\n"))
+	assert.Error(t, err)
+}
+
+func TestParserGoTypeOverrides(t *testing.T) {
+	t.Parallel()
+
+	input := `{"id":"a","ref":"b","db":"c","tags":["d"]}`
+
+	parser := NewJSONParser(baseTypeName, OptOverrides(Overrides{Types: map[string]string{
+		"$.id":   "github.com/google/uuid.UUID",
+		"$.ref":  "example.com/uuid.ID",
+		"$.db":   "github.com/mattn/go-sqlite3.SQLiteConn",
+		"$.tags": "time.Duration",
+	}}))
+	require.NoError(t, parser.FeedBytes([]byte(input)))
+
+	out, err := parser.Generate()
+	require.NoError(t, err)
+	assert.Equal(t, `type Document struct {
+	Db   sqlite3.SQLiteConn `+"`json:\"db\"`"+`
+	ID   uuid.UUID          `+"`json:\"id\"`"+`
+	Ref  uuid2.ID           `+"`json:\"ref\"`"+`
+	Tags []time.Duration    `+"`json:\"tags\"`"+`
+}`, out)
+	assert.Equal(t, []string{
+		`uuid2 "example.com/uuid"`,
+		`"github.com/google/uuid"`,
+		`sqlite3 "github.com/mattn/go-sqlite3"`,
+		`"time"`,
+	}, parser.ImportSpecs())
+}
+
+func TestParserGoModule(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "json2go")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "go.mod"),
+		[]byte("module example.com/app\n\nrequire github.com/google/uuid v1.6.0\n"), 0o600))
+	sub := filepath.Join(dir, "models")
+	require.NoError(t, os.Mkdir(sub, 0o700))
+
+	newParser := func(goType string) *JSONParser {
+		parser := NewJSONParser(baseTypeName, OptGoModule(sub), OptOverrides(Overrides{
+			Types: map[string]string{"$.id": goType},
+		}))
+		require.NoError(t, parser.FeedBytes([]byte(`{"id":"a"}`)))
+		return parser
+	}
+
+	_, err = newParser("github.com/google/uuid.UUID").Generate()
+	assert.NoError(t, err)
+	_, err = newParser("example.com/app/ids.ID").Generate()
+	assert.NoError(t, err)
+
+	_, err = newParser("github.com/gofrs/uuid.UUID").Generate()
+	assert.True(t, errors.Is(err, ErrMissingDependency), "%v", err)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(sub, "go.mod"), []byte("go 1.21\n"), 0o600))
+	_, err = newParser("github.com/google/uuid.UUID").Generate()
+	assert.Error(t, err)
+}
@@ -0,0 +1,129 @@
+package json2go
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Reasons of values represented by interface{}, see InterfaceFinding.
+const (
+	// InterfaceMixedKinds is a reason of values of different json kinds, like numbers and strings.
+	InterfaceMixedKinds = "mixed_kinds"
+	// InterfaceIncompatibleTypes is a reason of values of the same kinds with types that can't be combined,
+	// like arrays of different depths.
+	InterfaceIncompatibleTypes = "incompatible_types"
+	// InterfaceNullOnly is a reason of values, which were only nulls.
+	InterfaceNullOnly = "null_only"
+	// InterfaceEmptyArrays is a reason of elements of arrays, which were only empty or had only null elements.
+	InterfaceEmptyArrays = "empty_arrays"
+	// InterfaceWidening is a reason of values of two types, combined to interface{} by widening lattice,
+	// see OptWidening.
+	InterfaceWidening = "widening"
+)
+
+// InterfaceFinding is a json path of values represented by interface{} in generated types, with reason and
+// suggestions of options or overrides giving them other types.
+type InterfaceFinding struct {
+	// Path is a json path of values, like "$.user.id".
+	Path string `json:"path"`
+	// Reason is a reason of interface{}, like "mixed_kinds", see InterfaceMixedKinds.
+	Reason string `json:"reason"`
+	// Kinds are json kinds of values, like "number" and "string".
+	Kinds []string `json:"kinds,omitempty"`
+	// Message explains reason.
+	Message string `json:"message"`
+	// Suggestions are options or overrides, which would resolve interface{}, with flags of command line.
+	Suggestions []string `json:"suggestions"`
+}
+
+// InterfaceReport returns paths of values represented by interface{} in generated types, sorted by path, with reasons
+// and suggestions resolving them. Values with type interface{} forced with Overrides aren't reported.
+func (p *JSONParser) InterfaceReport() []InterfaceFinding {
+	var findings []InterfaceFinding
+	seen := make(map[string]bool)
+
+	var walk func(n *node, attribute bool)
+	walk = func(n *node, attribute bool) {
+		if p.opts.overrides.Types[n.path] == "" {
+			if f, ok := p.interfaceFinding(n, attribute); ok && !seen[f.Path+" "+f.Reason] {
+				seen[f.Path+" "+f.Reason] = true
+				findings = append(findings, f)
+			}
+		}
+		for _, c := range n.children {
+			walk(c, n.t.id() == nodeTypeObject.id())
+		}
+		if p.opts.tuples && n.isTuple() {
+			for _, c := range n.tuple {
+				walk(c, false)
+			}
+		}
+	}
+	nodes, _ := p.outputNodes()
+	for _, n := range nodes {
+		walk(n, false)
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Path < findings[j].Path
+	})
+	return findings
+}
+
+// interfaceFinding returns finding of node, if its values are represented by interface{}. attribute is true for
+// attributes of objects.
+func (p *JSONParser) interfaceFinding(n *node, attribute bool) (InterfaceFinding, bool) {
+	f := InterfaceFinding{Path: n.path, Kinds: n.kindNames()}
+	override := fmt.Sprintf(`override type, like Overrides.Types[%q] = "string" (-choices)`, n.path)
+	raw := fmt.Sprintf("OptRawMessageAt(%q), to decode values later (-rp %s)", n.path, n.path)
+
+	switch {
+	case p.opts.widening.widensInterface(n):
+		// Values have other type than interface{}.
+		return InterfaceFinding{}, false
+	case p.opts.widening.widening(n) == WideningInterface:
+		f.Reason = InterfaceWidening
+		f.Message = "values of different types, combined to interface{} by widening lattice"
+		f.Suggestions = append(f.Suggestions, "OptWidening with other widening of pair of types (-widen)", override)
+	case n.t.id() == nodeTypeInterface.id() && n.kindsCount() > 1:
+		f.Reason = InterfaceMixedKinds
+		f.Message = "values of different kinds: " + strings.Join(f.Kinds, ", ")
+		switch n.seenKinds &^ kindArray {
+		case kindNumber | kindString:
+			f.Suggestions = append(f.Suggestions, `OptWidening with "number+string=number" or "number+string=string" (-widen)`)
+		case kindBool | kindString:
+			f.Suggestions = append(f.Suggestions, `OptWidening with "bool+string=string" (-widen)`)
+		}
+		if n.arrayLevel > 0 {
+			f.Suggestions = append(f.Suggestions, "OptOutlierTolerance, if few elements are of other kinds (-outlier-tolerance)")
+		}
+		if !p.opts.rawMessageForUnstable {
+			f.Suggestions = append(f.Suggestions, fmt.Sprintf("OptRawMessageForUnstable(true, %d), to decode values later (-ru %d)",
+				n.kindsCount(), n.kindsCount()))
+		} else {
+			f.Suggestions = append(f.Suggestions, raw)
+		}
+		f.Suggestions = append(f.Suggestions, override)
+	case n.t.id() == nodeTypeInterface.id():
+		f.Reason = InterfaceIncompatibleTypes
+		f.Message = "values of incompatible types, like arrays of different depths"
+		f.Suggestions = append(f.Suggestions, raw, override)
+	case n.t.id() == nodeTypeInit.id() && n.arrayLevel > 0:
+		f.Reason = InterfaceEmptyArrays
+		f.Message = "arrays were empty or had only null elements"
+		f.Suggestions = append(f.Suggestions, "samples with elements of arrays",
+			"OptEmptyValues with other policy than default, like EmptyValuesRaw (-empty raw)", override)
+	case n.t.id() == nodeTypeInit.id():
+		f.Reason = InterfaceNullOnly
+		f.Message = "values were only nulls"
+		f.Suggestions = append(f.Suggestions, "samples with values other than null")
+		if attribute && !p.opts.skipEmptyKeys {
+			f.Suggestions = append(f.Suggestions, "OptSkipEmptyKeys, to leave key out (-k)")
+		}
+		f.Suggestions = append(f.Suggestions, override)
+	default:
+		return InterfaceFinding{}, false
+	}
+	return f, true
+}
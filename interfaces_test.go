@@ -0,0 +1,114 @@
+package json2go
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONParser_InterfaceReport(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		opts     []JSONParserOpt
+		inputs   []string
+		expected []InterfaceFinding
+	}{
+		{
+			name:   "typed",
+			inputs: []string{`{"a":1,"b":["x"]}`},
+		},
+		{
+			name:   "number and string",
+			inputs: []string{`{"a":1}`, `{"a":"x"}`},
+			expected: []InterfaceFinding{{
+				Path: "$.a", Reason: InterfaceMixedKinds, Kinds: []string{"number", "string"},
+				Message: "values of different kinds: number, string",
+				Suggestions: []string{
+					`OptWidening with "number+string=number" or "number+string=string" (-widen)`,
+					"OptRawMessageForUnstable(true, 2), to decode values later (-ru 2)",
+					`override type, like Overrides.Types["$.a"] = "string" (-choices)`,
+				},
+			}},
+		},
+		{
+			name:   "array elements",
+			opts:   []JSONParserOpt{OptRawMessageForUnstable(true, 4)},
+			inputs: []string{`{"a":[true,{"b":1}]}`},
+			expected: []InterfaceFinding{{
+				Path: "$.a", Reason: InterfaceMixedKinds, Kinds: []string{"bool", "object", "array"},
+				Message: "values of different kinds: bool, object, array",
+				Suggestions: []string{
+					"OptOutlierTolerance, if few elements are of other kinds (-outlier-tolerance)",
+					`OptRawMessageAt("$.a"), to decode values later (-rp $.a)`,
+					`override type, like Overrides.Types["$.a"] = "string" (-choices)`,
+				},
+			}},
+		},
+		{
+			name:   "null only",
+			inputs: []string{`{"a":null,"b":[null],"c":[]}`},
+			expected: []InterfaceFinding{
+				{
+					Path: "$.a", Reason: InterfaceNullOnly, Message: "values were only nulls",
+					Suggestions: []string{
+						"samples with values other than null",
+						"OptSkipEmptyKeys, to leave key out (-k)",
+						`override type, like Overrides.Types["$.a"] = "string" (-choices)`,
+					},
+				},
+				{
+					Path: "$.b", Reason: InterfaceEmptyArrays, Kinds: []string{"array"},
+					Message: "arrays were empty or had only null elements",
+					Suggestions: []string{
+						"samples with elements of arrays",
+						"OptEmptyValues with other policy than default, like EmptyValuesRaw (-empty raw)",
+						`override type, like Overrides.Types["$.b"] = "string" (-choices)`,
+					},
+				},
+				{
+					Path: "$.c", Reason: InterfaceEmptyArrays, Kinds: []string{"array"},
+					Message: "arrays were empty or had only null elements",
+					Suggestions: []string{
+						"samples with elements of arrays",
+						"OptEmptyValues with other policy than default, like EmptyValuesRaw (-empty raw)",
+						`override type, like Overrides.Types["$.c"] = "string" (-choices)`,
+					},
+				},
+			},
+		},
+		{
+			name:   "widening",
+			opts:   []JSONParserOpt{OptWidening(WideningLattice{IntFloat: WideningInterface, NumberString: WideningString})},
+			inputs: []string{`{"a":1,"b":1}`, `{"a":1.5,"b":"x"}`},
+			expected: []InterfaceFinding{{
+				Path: "$.a", Reason: InterfaceWidening, Kinds: []string{"number"},
+				Message: "values of different types, combined to interface{} by widening lattice",
+				Suggestions: []string{
+					"OptWidening with other widening of pair of types (-widen)",
+					`override type, like Overrides.Types["$.a"] = "string" (-choices)`,
+				},
+			}},
+		},
+		{
+			name:   "overridden",
+			opts:   []JSONParserOpt{OptOverrides(Overrides{Types: map[string]string{"$.a": "any"}})},
+			inputs: []string{`{"a":1}`, `{"a":"x"}`},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			parser := NewJSONParser(baseTypeName, tc.opts...)
+			for _, input := range tc.inputs {
+				require.NoError(t, parser.FeedBytes([]byte(input)))
+			}
+			assert.Equal(t, tc.expected, parser.InterfaceReport())
+		})
+	}
+}
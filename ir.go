@@ -0,0 +1,229 @@
+package json2go
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// IRVersion is a version of json form of Schema, written by MarshalIR.
+//
+// Versioning policy: version is incremented only on incompatible changes, like renamed or removed attributes,
+// or changed meaning of values. Compatible changes, like new optional attributes or new kinds, don't change it,
+// so readers of IR should ignore unknown attributes. UnmarshalIR reads documents of all previous versions,
+// migrating them to current version, and rejects documents of newer versions.
+//
+// Version 0 are documents without version, marshaled from Schema before it got json attribute names,
+// with go field names, like "Root" or "OmitEmpty".
+const IRVersion = 1
+
+// IRJSONSchema is a JSON Schema of documents written by MarshalIR, for tools producing or consuming IR.
+const IRJSONSchema = `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "$id": "https://github.com/heucoder/json2go/ir.schema.json",
+  "title": "json2go IR",
+  "description": "Generated go types, telling how they decode and encode json values.",
+  "type": "object",
+  "required": ["version", "root", "types"],
+  "properties": {
+    "version": {"const": 1},
+    "root": {"type": "string", "description": "Name of root type."},
+    "types": {
+      "type": "object",
+      "description": "Declared types, by name.",
+      "additionalProperties": {"$ref": "#/$defs/type"}
+    }
+  },
+  "$defs": {
+    "type": {
+      "type": "object",
+      "required": ["kind"],
+      "properties": {
+        "kind": {
+          "enum": ["bool", "int", "float", "string", "time", "any", "opaque", "pointer", "slice", "map", "struct", "optional", "named"]
+        },
+        "name": {"type": "string", "description": "Name of declared type, for named kind."},
+        "bits": {"enum": [32, 64], "description": "Size of floats."},
+        "format": {"type": "string", "description": "Format of strings, like uuid."},
+        "elem": {"$ref": "#/$defs/type", "description": "Type of pointed value, slice element, map value or optional value."},
+        "key": {"$ref": "#/$defs/type", "description": "Type of map keys, string or int."},
+        "skipNulls": {"type": "boolean", "description": "Slice skips null elements."},
+        "fields": {"type": "array", "items": {"$ref": "#/$defs/field"}, "description": "Struct fields encoded in json."},
+        "keepsUnknown": {"type": "boolean", "description": "Struct keeps values of unknown keys."}
+      }
+    },
+    "field": {
+      "type": "object",
+      "required": ["name", "key", "type"],
+      "properties": {
+        "name": {"type": "string", "description": "Go field name."},
+        "key": {"type": "string", "description": "Json key."},
+        "type": {"$ref": "#/$defs/type"},
+        "omitEmpty": {"type": "boolean"},
+        "omitZero": {"type": "boolean"}
+      }
+    }
+  }
+}
+`
+
+// irDocument is a json form of Schema.
+type irDocument struct {
+	Version int `json:"version"`
+	Schema
+}
+
+// irMigrations migrate IR documents decoded to generic values. Migration at index i migrates version i to i+1.
+var irMigrations = []func(doc map[string]interface{}) error{
+	migrateIRv0,
+}
+
+// MarshalIR returns json form of schema, described by IRJSONSchema.
+func MarshalIR(s *Schema) ([]byte, error) {
+	if err := validateIR(s); err != nil {
+		return nil, err
+	}
+	return json.Marshal(irDocument{Version: IRVersion, Schema: *s})
+}
+
+// UnmarshalIR returns schema from json form written by MarshalIR, or by other tools. Documents of previous versions
+// are migrated, see IRVersion. Invalid documents are reported with error matching ErrInvalidIR.
+func UnmarshalIR(data []byte) (*Schema, error) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIR, err)
+	}
+
+	version := 0
+	if v, ok := doc["version"]; ok {
+		f, ok := v.(float64)
+		if !ok || f != math.Trunc(f) || f < 1 {
+			return nil, fmt.Errorf("%w: invalid version: %v", ErrInvalidIR, v)
+		}
+		if f > IRVersion {
+			return nil, fmt.Errorf("%w: version %v is newer than supported version %d", ErrInvalidIR, f, IRVersion)
+		}
+		version = int(f)
+	}
+	for ; version < IRVersion; version++ {
+		if err := irMigrations[version](doc); err != nil {
+			return nil, fmt.Errorf("%w: migrating version %d: %v", ErrInvalidIR, version, err)
+		}
+	}
+	doc["version"] = IRVersion
+
+	migrated, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInternal, err)
+	}
+	var ir irDocument
+	if err := json.NewDecoder(bytes.NewReader(migrated)).Decode(&ir); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIR, err)
+	}
+	if err := validateIR(&ir.Schema); err != nil {
+		return nil, err
+	}
+	return &ir.Schema, nil
+}
+
+// validateIR checks if schema is complete: root type and referenced types are declared, and types have attributes
+// required by their kinds.
+func validateIR(s *Schema) error {
+	if s == nil {
+		return fmt.Errorf("%w: no schema", ErrInvalidIR)
+	}
+	if _, ok := s.Types[s.Root]; !ok {
+		return fmt.Errorf("%w: root type %q isn't declared", ErrInvalidIR, s.Root)
+	}
+	names := make([]string, 0, len(s.Types))
+	for name := range s.Types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := validateIRType(s, s.Types[name], "types."+name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateIRType(s *Schema, t *SchemaType, path string) error {
+	if t == nil {
+		return fmt.Errorf("%w: %s: no type", ErrInvalidIR, path)
+	}
+	switch t.Kind {
+	case SchemaBool, SchemaInt, SchemaString, SchemaTime, SchemaAny, SchemaOpaque:
+	case SchemaFloat:
+		if t.Bits != 32 && t.Bits != 64 {
+			return fmt.Errorf("%w: %s: invalid float bits: %d", ErrInvalidIR, path, t.Bits)
+		}
+	case SchemaNamed:
+		if _, ok := s.Types[t.Name]; !ok {
+			return fmt.Errorf("%w: %s: type %q isn't declared", ErrInvalidIR, path, t.Name)
+		}
+	case SchemaPointer, SchemaSlice, SchemaOptional:
+		return validateIRType(s, t.Elem, path+".elem")
+	case SchemaMap:
+		if t.Key != nil && t.Key.Kind != SchemaString && t.Key.Kind != SchemaInt {
+			return fmt.Errorf("%w: %s.key: invalid map key kind: %s", ErrInvalidIR, path, t.Key.Kind)
+		}
+		return validateIRType(s, t.Elem, path+".elem")
+	case SchemaStruct:
+		for i, f := range t.Fields {
+			fieldPath := fmt.Sprintf("%s.fields[%d]", path, i)
+			if f.Name == "" {
+				return fmt.Errorf("%w: %s: no field name", ErrInvalidIR, fieldPath)
+			}
+			if err := validateIRType(s, f.Type, fieldPath+".type"); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("%w: %s: unknown kind: %q", ErrInvalidIR, path, t.Kind)
+	}
+	return nil
+}
+
+// migrateIRv0 renames go field names of version 0, like "OmitEmpty", to json attribute names, like "omitEmpty".
+func migrateIRv0(doc map[string]interface{}) error {
+	lowerIRKeys(doc)
+	types, _ := doc["types"].(map[string]interface{})
+	for _, t := range types {
+		migrateIRv0Type(t)
+	}
+	return nil
+}
+
+func migrateIRv0Type(v interface{}) {
+	t, ok := v.(map[string]interface{})
+	if !ok {
+		return
+	}
+	lowerIRKeys(t)
+	migrateIRv0Type(t["elem"])
+	migrateIRv0Type(t["key"])
+	fields, _ := t["fields"].([]interface{})
+	for _, f := range fields {
+		if field, ok := f.(map[string]interface{}); ok {
+			lowerIRKeys(field)
+			migrateIRv0Type(field["type"])
+		}
+	}
+}
+
+// lowerIRKeys lowers first letters of object keys.
+func lowerIRKeys(obj map[string]interface{}) {
+	for key, v := range obj {
+		if key == "" {
+			continue
+		}
+		if lower := strings.ToLower(key[:1]) + key[1:]; lower != key {
+			delete(obj, key)
+			obj[lower] = v
+		}
+	}
+}
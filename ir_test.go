@@ -0,0 +1,115 @@
+package json2go
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalIR(t *testing.T) {
+	t.Parallel()
+
+	parser := NewJSONParser(baseTypeName, OptExtractCommonTypes(true))
+	require.NoError(t, parser.FeedBytes([]byte(`{"a":{"x":1.5,"y":[true]},"b":{"x":2.5,"y":[false]},"m":null}`)))
+	schema := parser.Schema()
+
+	data, err := MarshalIR(schema)
+	require.NoError(t, err)
+
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &doc))
+	assert.Equal(t, float64(IRVersion), doc["version"])
+	assert.Equal(t, "Document", doc["root"])
+
+	decoded, err := UnmarshalIR(data)
+	require.NoError(t, err)
+	assert.Equal(t, schema, decoded)
+}
+
+func TestUnmarshalIRVersion0(t *testing.T) {
+	t.Parallel()
+
+	schema, err := UnmarshalIR([]byte(`{"Root":"Document","Types":{"Document":{"Kind":"struct","Name":"","Bits":0,` +
+		`"Elem":null,"Key":null,"SkipNulls":false,"Fields":[{"Name":"ID","Key":"id","Type":{"Kind":"float","Bits":64},` +
+		`"OmitEmpty":true,"OmitZero":false}],"KeepsUnknown":false}}}`))
+	require.NoError(t, err)
+	assert.Equal(t, &Schema{
+		Root: "Document",
+		Types: map[string]*SchemaType{
+			"Document": {
+				Kind:   SchemaStruct,
+				Fields: []SchemaField{{Name: "ID", Key: "id", Type: &SchemaType{Kind: SchemaFloat, Bits: 64}, OmitEmpty: true}},
+			},
+		},
+	}, schema)
+}
+
+func TestUnmarshalIRInvalid(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name  string
+		input string
+	}{
+		{name: "invalid json", input: `{`},
+		{name: "newer version", input: `{"version":2,"root":"A","types":{"A":{"kind":"int"}}}`},
+		{name: "invalid version", input: `{"version":"1","root":"A","types":{"A":{"kind":"int"}}}`},
+		{name: "missing root", input: `{"version":1,"root":"B","types":{"A":{"kind":"int"}}}`},
+		{name: "unknown kind", input: `{"version":1,"root":"A","types":{"A":{"kind":"complex"}}}`},
+		{name: "missing elem", input: `{"version":1,"root":"A","types":{"A":{"kind":"slice"}}}`},
+		{name: "float bits", input: `{"version":1,"root":"A","types":{"A":{"kind":"float","bits":16}}}`},
+		{name: "undeclared type", input: `{"version":1,"root":"A","types":{"A":{"kind":"pointer","elem":{"kind":"named","name":"B"}}}}`},
+		{
+			name:  "field without type",
+			input: `{"version":1,"root":"A","types":{"A":{"kind":"struct","fields":[{"name":"X","key":"x"}]}}}`,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := UnmarshalIR([]byte(tc.input))
+			assert.True(t, errors.Is(err, ErrInvalidIR), "%v", err)
+		})
+	}
+}
+
+func TestUnmarshalIRUnknownAttributes(t *testing.T) {
+	t.Parallel()
+
+	schema, err := UnmarshalIR([]byte(`{"version":1,"root":"A","generator":"other","types":{"A":{"kind":"int","doc":"x"}}}`))
+	require.NoError(t, err)
+	assert.Equal(t, &Schema{Root: "A", Types: map[string]*SchemaType{"A": {Kind: SchemaInt}}}, schema)
+}
+
+func TestIRJSONSchema(t *testing.T) {
+	t.Parallel()
+
+	var schema struct {
+		Properties struct {
+			Version struct {
+				Const int `json:"const"`
+			} `json:"version"`
+		} `json:"properties"`
+		Defs struct {
+			Type struct {
+				Properties struct {
+					Kind struct {
+						Enum []SchemaKind `json:"enum"`
+					} `json:"kind"`
+				} `json:"properties"`
+			} `json:"type"`
+		} `json:"$defs"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(IRJSONSchema), &schema))
+	assert.Equal(t, IRVersion, schema.Properties.Version.Const)
+	assert.ElementsMatch(t, []SchemaKind{
+		SchemaBool, SchemaInt, SchemaFloat, SchemaString, SchemaTime, SchemaAny, SchemaOpaque,
+		SchemaPointer, SchemaSlice, SchemaMap, SchemaStruct, SchemaOptional, SchemaNamed,
+	}, schema.Defs.Type.Properties.Kind.Enum)
+}
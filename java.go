@@ -0,0 +1,149 @@
+package json2go
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// javaReserved are keywords and literals of Java, and names of Object methods, which can't be record components.
+var javaReserved = map[string]bool{
+	"abstract": true, "assert": true, "boolean": true, "break": true, "byte": true, "case": true, "catch": true,
+	"char": true, "class": true, "const": true, "continue": true, "default": true, "do": true, "double": true,
+	"else": true, "enum": true, "extends": true, "false": true, "final": true, "finally": true, "float": true,
+	"for": true, "goto": true, "if": true, "implements": true, "import": true, "instanceof": true, "int": true,
+	"interface": true, "long": true, "native": true, "new": true, "null": true, "package": true, "private": true,
+	"protected": true, "public": true, "return": true, "short": true, "static": true, "strictfp": true,
+	"super": true, "switch": true, "synchronized": true, "this": true, "throw": true, "throws": true,
+	"transient": true, "true": true, "try": true, "void": true, "volatile": true, "while": true,
+	"clone": true, "finalize": true, "getClass": true, "hashCode": true, "notify": true, "notifyAll": true,
+	"toString": true, "wait": true,
+}
+
+// javaPrimitives are primitive types of Java, by boxed types.
+var javaPrimitives = map[string]string{"Boolean": "boolean", "Long": "long", "Float": "float", "Double": "double"}
+
+// JavaEmitter emits Java records with Jackson annotations from IR, as a source file of root record, with records
+// of other structs nested in it.
+//
+// Records and components keep go names, components in lower camel case, with JsonProperty annotations of json keys.
+// Fields of pointers, optional values or with omitempty have boxed types and are omitted when null, other scalars
+// are primitives. Times are instants, read with jackson-datatype-jsr310 module, and any and opaque values are
+// json nodes.
+type JavaEmitter struct{}
+
+// Emit returns Java source of records.
+func (JavaEmitter) Emit(ir *Schema) ([]byte, error) {
+	s, err := newIDLSchema(ir, "Java record", false)
+	if err != nil {
+		return nil, err
+	}
+
+	imports := map[string]bool{"com.fasterxml.jackson.annotation.JsonProperty": true}
+	var records strings.Builder
+	for i, d := range s.decls {
+		indent := ""
+		if i > 0 {
+			indent = "    "
+		}
+		if i > 1 {
+			records.WriteString("\n")
+		}
+		fmt.Fprintf(&records, "%spublic record %s(", indent, d.name)
+		names := make(map[string]bool)
+		for j, f := range d.fields {
+			name := lowerCamelCaseName(f.Name)
+			if javaReserved[name] {
+				name += "_"
+			}
+			for names[name] {
+				name = nextName(name)
+			}
+			names[name] = true
+
+			typ := javaType(ir, s, f.Type, imports)
+			annotations := "@JsonProperty(" + strconv.Quote(f.Key) + ")"
+			if f.isOptional() {
+				imports["com.fasterxml.jackson.annotation.JsonInclude"] = true
+				annotations += " @JsonInclude(JsonInclude.Include.NON_NULL)"
+			} else if primitive, ok := javaPrimitives[typ]; ok && f.Type.Kind != SchemaPointer && f.Type.Kind != SchemaOptional {
+				typ = primitive
+			}
+			separator := ","
+			if j == len(d.fields)-1 {
+				separator = ""
+			}
+			fmt.Fprintf(&records, "\n%s    %s %s %s%s", indent, annotations, typ, name, separator)
+		}
+		if len(d.fields) > 0 {
+			records.WriteString("\n" + indent)
+		}
+		records.WriteString(") {\n")
+		if i > 0 {
+			fmt.Fprintf(&records, "%s}\n", indent)
+		}
+	}
+	records.WriteString("}\n")
+
+	names := make([]string, 0, len(imports))
+	for name := range imports {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "import %s;\n", name)
+	}
+	b.WriteString("\n")
+	b.WriteString(records.String())
+	return []byte(b.String()), nil
+}
+
+// javaType returns boxed Java type of values. Imports of used types are added to imports.
+func javaType(ir *Schema, s *idlSchema, t *SchemaType, imports map[string]bool) string {
+	switch t.Kind {
+	case SchemaBool:
+		return "Boolean"
+	case SchemaInt:
+		return "Long"
+	case SchemaFloat:
+		if t.Bits == 32 {
+			return "Float"
+		}
+		return "Double"
+	case SchemaString:
+		if t.Format == SchemaFormatUUID {
+			imports["java.util.UUID"] = true
+			return "UUID"
+		}
+		return "String"
+	case SchemaTime:
+		imports["java.time.Instant"] = true
+		return "Instant"
+	case SchemaPointer, SchemaOptional:
+		return javaType(ir, s, t.Elem, imports)
+	case SchemaSlice:
+		imports["java.util.List"] = true
+		return "List<" + javaType(ir, s, t.Elem, imports) + ">"
+	case SchemaMap:
+		imports["java.util.Map"] = true
+		key := &SchemaType{Kind: SchemaString}
+		if t.Key != nil {
+			key = t.Key
+		}
+		return "Map<" + javaType(ir, s, key, imports) + ", " + javaType(ir, s, t.Elem, imports) + ">"
+	case SchemaStruct:
+		return s.names[t]
+	case SchemaNamed:
+		return javaType(ir, s, ir.Types[t.Name], imports)
+	}
+	imports["com.fasterxml.jackson.databind.JsonNode"] = true
+	return "JsonNode"
+}
+
+// JavaTypes returns Java records describing parsed documents, see JavaEmitter.
+func (p *JSONParser) JavaTypes() (string, error) {
+	out, err := JavaEmitter{}.Emit(p.Schema())
+	return string(out), err
+}
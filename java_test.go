@@ -0,0 +1,104 @@
+package json2go
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJavaTypes(t *testing.T) {
+	t.Parallel()
+
+	p := NewJSONParser(baseTypeName, OptMakeMaps(true, 2), OptMapKeyTypes(true))
+	require.NoError(t, p.FeedBytes([]byte(`{"id": 1, "user-name": "a", "tags": ["x"], "addr": {"zip": "1"},
+		"at": "2021-01-01T00:00:00Z", "extra": 1, "class": "c",
+		"votes": {"3f2504e0-4f89-11d3-9a0c-0305e82c3301": 1, "4f2504e0-4f89-11d3-9a0c-0305e82c3301": 2}}`)))
+	require.NoError(t, p.FeedBytes([]byte(`{"id": 2, "tags": [], "addr": {"zip": "2"},
+		"at": "2021-01-02T00:00:00Z", "extra": "x", "score": 0.5, "class": "c", "votes": {}}`)))
+
+	src, err := p.JavaTypes()
+	require.NoError(t, err)
+	assert.Equal(t, `import com.fasterxml.jackson.annotation.JsonInclude;
+import com.fasterxml.jackson.annotation.JsonProperty;
+import com.fasterxml.jackson.databind.JsonNode;
+import java.time.Instant;
+import java.util.List;
+import java.util.Map;
+import java.util.UUID;
+
+public record Document(
+    @JsonProperty("addr") DocumentAddr addr,
+    @JsonProperty("at") Instant at,
+    @JsonProperty("class") String class_,
+    @JsonProperty("extra") JsonNode extra,
+    @JsonProperty("id") long id,
+    @JsonProperty("score") @JsonInclude(JsonInclude.Include.NON_NULL) Double score,
+    @JsonProperty("tags") List<String> tags,
+    @JsonProperty("user-name") @JsonInclude(JsonInclude.Include.NON_NULL) String username,
+    @JsonProperty("votes") Map<UUID, Long> votes
+) {
+    public record DocumentAddr(
+        @JsonProperty("zip") String zip
+    ) {
+    }
+}
+`, src)
+}
+
+func TestJavaEmitter(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		ir       *Schema
+		expected string
+		err      error
+	}{
+		{
+			name: "recursive type and empty record",
+			ir: &Schema{Root: "Node", Types: map[string]*SchemaType{
+				"Node": {Kind: SchemaStruct, Fields: []SchemaField{
+					{Name: "Children", Key: "children", Type: &SchemaType{Kind: SchemaSlice, Elem: &SchemaType{Kind: SchemaNamed, Name: "Node"}}},
+					{Name: "HashCode", Key: "hash", Type: &SchemaType{Kind: SchemaPointer, Elem: &SchemaType{Kind: SchemaInt}}},
+					{Name: "Meta", Key: "meta", Type: &SchemaType{Kind: SchemaNamed, Name: "Meta"}},
+				}},
+				"Meta": {Kind: SchemaStruct},
+			}},
+			expected: `import com.fasterxml.jackson.annotation.JsonInclude;
+import com.fasterxml.jackson.annotation.JsonProperty;
+import java.util.List;
+
+public record Node(
+    @JsonProperty("children") List<Node> children,
+    @JsonProperty("hash") @JsonInclude(JsonInclude.Include.NON_NULL) Long hashCode_,
+    @JsonProperty("meta") Meta meta
+) {
+    public record Meta() {
+    }
+}
+`,
+		},
+		{
+			name: "root isn't struct",
+			ir:   &Schema{Root: "Document", Types: map[string]*SchemaType{"Document": {Kind: SchemaString}}},
+			err:  ErrUnsupportedShape,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			out, err := JavaEmitter{}.Emit(tc.ir)
+			if tc.err != nil {
+				assert.True(t, errors.Is(err, tc.err), "unexpected error: %v", err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(out))
+		})
+	}
+}
@@ -0,0 +1,371 @@
+package json2go
+
+import (
+	"fmt"
+	"go/ast"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// jsonAPIRole is a role of node in JSON:API document, see OptJSONAPI.
+type jsonAPIRole int
+
+const (
+	jsonAPINone jsonAPIRole = iota
+	// jsonAPIDocument is a top level object with primary data, resolving included resources.
+	jsonAPIDocument
+	// jsonAPIResource is a resource object, with attributes flattened into it.
+	jsonAPIResource
+	// jsonAPIAttribute is an attribute of resource object, encoded in its "attributes" member.
+	jsonAPIAttribute
+	// jsonAPIRelationships is a "relationships" member of resource object.
+	jsonAPIRelationships
+)
+
+// jsonAPIMembers are keys of members of resource objects, attributes with these keys aren't flattened.
+var jsonAPIMembers = []string{"id", "lid", "type", "attributes", "relationships", "links", "meta"}
+
+// applyJSONAPI recognizes JSON:API document with primary data of resource objects, and flattens attributes
+// of resources of primary data and included resources.
+func applyJSONAPI(root *node) {
+	if root.t.id() != nodeTypeObject.id() || root.arrayLevel > 0 {
+		return
+	}
+	data := root.getChild("data")
+	if data == nil || data.arrayLevel > 1 || !isJSONAPIResource(data) {
+		return
+	}
+	root.logf("values are JSON:API documents")
+	root.jsonAPI = jsonAPIDocument
+	flattenJSONAPIResource(data)
+	if included := root.getChild("included"); included != nil && included.arrayLevel == 1 && isJSONAPIResource(included) {
+		flattenJSONAPIResource(included)
+	}
+}
+
+// isJSONAPIResource checks if node is JSON:API resource object, with string type and id or attributes.
+func isJSONAPIResource(n *node) bool {
+	if n.t.id() != nodeTypeObject.id() {
+		return false
+	}
+	if t := n.getChild("type"); t == nil || t.t != nodeTypeString || t.arrayLevel > 0 {
+		return false
+	}
+	for _, key := range []string{"attributes", "relationships"} {
+		if c := n.getChild(key); c != nil && (c.t.id() != nodeTypeObject.id() || c.arrayLevel > 0) {
+			return false
+		}
+	}
+	return n.getChild("id") != nil || n.getChild("attributes") != nil
+}
+
+// flattenJSONAPIResource moves attributes of resource into resource node, unless their keys clash with
+// resource members, and makes its relationships decoded by helper type.
+func flattenJSONAPIResource(n *node) {
+	n.jsonAPI = jsonAPIResource
+	if rel := n.getChild("relationships"); rel != nil {
+		for _, c := range rel.children {
+			n.jsonAPIRelations = append(n.jsonAPIRelations, c.key)
+		}
+		rel.jsonAPI = jsonAPIRelationships
+		rel.t = nodeTypeRawMessage
+		rel.children = nil
+		rel.commented = nil
+	}
+
+	attrs := n.getChild("attributes")
+	if attrs == nil {
+		return
+	}
+	for _, c := range append(append([]*node(nil), attrs.children...), attrs.commented...) {
+		// Keys of go structs are matched case insensitively.
+		for _, key := range jsonAPIMembers {
+			if strings.EqualFold(c.key, key) {
+				n.logf("attribute %q isn't flattened, it clashes with member %q", c.key, key)
+				return
+			}
+		}
+	}
+
+	var children []*node
+	for _, c := range n.children {
+		if c != attrs {
+			children = append(children, c)
+		}
+	}
+	n.children = children
+	for _, c := range attrs.children {
+		c.jsonAPI = jsonAPIAttribute
+		if !attrs.required || attrs.nullable {
+			c.required = false
+		}
+		for n.hasChildNamed(c.name) {
+			c.name = nextName(c.name)
+		}
+		n.children = append(n.children, c)
+	}
+	for _, c := range attrs.commented {
+		c.jsonAPI = jsonAPIAttribute
+		n.commented = append(n.commented, c)
+	}
+	sort.Slice(n.children, func(i, j int) bool {
+		return n.children[i].key < n.children[j].key
+	})
+}
+
+// astAddJSONAPIResource adds methods of named resource type: UnmarshalJSON and MarshalJSON moving attributes
+// from and to "attributes" member, accessors of relationships and Identifier method, if resource has string id.
+// Name of Identifier method is returned, or empty string.
+func astAddJSONAPIResource(n *node, st *ast.StructType, ctx *astContext) string {
+	fieldTypes := make(map[string]string)
+	for _, f := range st.Fields.List {
+		fieldTypes[f.Names[0].Name] = astExprString(f.Type)
+	}
+	methods := make(map[string]bool)
+	methodName := func(name string) string {
+		for fieldTypes[name] != "" || methods[name] {
+			name = nextName(name)
+		}
+		methods[name] = true
+		return name
+	}
+
+	var attrs []string
+	var relationships, idField, typeField string
+	for _, c := range n.children {
+		switch {
+		case c.jsonAPI == jsonAPIAttribute:
+			attrs = append(attrs, strconv.Quote(c.key))
+		case c.jsonAPI == jsonAPIRelationships:
+			relationships = c.name
+		case c.key == "id" && c.t == nodeTypeString && c.arrayLevel == 0:
+			idField = c.name
+		case c.key == "type":
+			typeField = c.name
+		}
+	}
+	for _, c := range n.commented {
+		if c.jsonAPI == jsonAPIAttribute {
+			attrs = append(attrs, strconv.Quote(c.key))
+		}
+	}
+
+	ctx.addImport("encoding/json")
+	if len(attrs) > 0 {
+		ctx.addHelper(fmt.Sprintf(`
+// UnmarshalJSON unmarshals %[1]s resource object, with fields of its attributes.
+func (v *%[1]s) UnmarshalJSON(data []byte) error {
+	type plain %[1]s
+	if err := json.Unmarshal(data, (*plain)(v)); err != nil {
+		return err
+	}
+
+	var members struct {
+		Attributes json.RawMessage `+"`json:\"attributes\"`"+`
+	}
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+	if len(members.Attributes) == 0 || string(members.Attributes) == "null" {
+		return nil
+	}
+	return json.Unmarshal(members.Attributes, (*plain)(v))
+}
+
+// MarshalJSON marshals %[1]s resource object, with fields of its attributes in "attributes" member.
+func (v %[1]s) MarshalJSON() ([]byte, error) {
+	type plain %[1]s
+	data, err := json.Marshal(plain(v))
+	if err != nil {
+		return nil, err
+	}
+
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		return nil, err
+	}
+	attributes := make(map[string]json.RawMessage)
+	for _, k := range []string{%[2]s} {
+		if value, ok := members[k]; ok {
+			attributes[k] = value
+			delete(members, k)
+		}
+	}
+	if len(attributes) > 0 {
+		if members["attributes"], err = json.Marshal(attributes); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(members)
+}
+`, n.name, strings.Join(attrs, ", ")))
+	}
+
+	if relationships != "" {
+		for _, key := range n.jsonAPIRelations {
+			ctx.addHelper(fmt.Sprintf(`
+// %[2]s returns identifiers of resources linked by relationship %[5]q of %[1]s.
+func (v %[1]s) %[2]s() []%[3]s {
+	return v.%[4]s.Identifiers(%[5]q)
+}
+`, n.name, methodName(attrName(key)), astJSONAPIIdentifierType(ctx), relationships, key))
+		}
+	}
+
+	idType, typeType := fieldTypes[idField], fieldTypes[typeField]
+	if (idType != "string" && idType != "*string") || (typeType != "string" && typeType != "*string") {
+		return ""
+	}
+	identifier := methodName("Identifier")
+	ctx.addHelper(fmt.Sprintf(`
+// %[2]s returns identifier of %[1]s resource.
+func (v %[1]s) %[2]s() %[3]s {
+	return %[3]s{Type: %[4]s, ID: %[5]s}
+}
+`, n.name, identifier, astJSONAPIIdentifierType(ctx), astJSONAPIString("v."+typeField, typeType, ctx),
+		astJSONAPIString("v."+idField, idType, ctx)))
+	return identifier
+}
+
+// astJSONAPIString returns expression of string value of field of type string or *string.
+func astJSONAPIString(field, fieldType string, ctx *astContext) string {
+	if fieldType == "string" {
+		return field
+	}
+	name := ctx.addSharedHelper("jsonAPIString", func(name string) string {
+		return fmt.Sprintf(`
+// %[1]s returns string pointed by s, or empty string.
+func %[1]s(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+`, name)
+	})
+	return name + "(" + field + ")"
+}
+
+// astAddJSONAPIResolve adds method of document type, returning included resource by identifier.
+// Identifier methods of resource types are given by type name.
+func astAddJSONAPIResolve(n *node, st *ast.StructType, identifiers map[string]string, ctx *astContext) {
+	included := n.getChild("included")
+	if included == nil || included.t.id() != nodeTypeExtracted.id() || included.arrayLevel != 1 {
+		return
+	}
+	identifier := identifiers[included.externalTypeID]
+	if identifier == "" {
+		return
+	}
+	var field ast.Expr
+	name := "Resolve"
+	for _, f := range st.Fields.List {
+		if f.Names[0].Name == included.name {
+			field = f.Type
+		}
+		if f.Names[0].Name == name {
+			name = nextName(name)
+		}
+	}
+	if astExprString(field) != "[]"+included.externalTypeID {
+		return
+	}
+
+	ctx.addHelper(fmt.Sprintf(`
+// %[2]s returns included resource with identifier, or nil if document doesn't include it.
+func (d %[1]s) %[2]s(id %[3]s) *%[4]s {
+	for i := range d.%[5]s {
+		if d.%[5]s[i].%[6]s() == id {
+			return &d.%[5]s[i]
+		}
+	}
+	return nil
+}
+`, n.name, name, astJSONAPIIdentifierType(ctx), included.externalTypeID, included.name, identifier))
+}
+
+// astJSONAPIIdentifierType returns name of helper type of JSON:API resource identifiers.
+func astJSONAPIIdentifierType(ctx *astContext) string {
+	return ctx.addSharedHelper("JSONAPIIdentifier", func(name string) string {
+		return fmt.Sprintf(`
+// %[1]s identifies JSON:API resource.
+type %[1]s struct {
+	Type string `+"`json:\"type\"`"+`
+	ID   string `+"`json:\"id\"`"+`
+}
+`, name)
+	})
+}
+
+// astTypeFromJSONAPIRelationshipsNode returns helper type of relationships of JSON:API resources, by name.
+func astTypeFromJSONAPIRelationshipsNode(ctx *astContext) ast.Expr {
+	ctx.addImport("encoding/json")
+	identifier := astJSONAPIIdentifierType(ctx)
+
+	linkage := ctx.addSharedHelper("JSONAPILinkage", func(name string) string {
+		return fmt.Sprintf(`
+// %[1]s is a resource linkage of JSON:API relationship: null, single identifier or array of identifiers.
+type %[1]s struct {
+	// Many is true for arrays of identifiers of to-many relationships.
+	Many        bool
+	Identifiers []%[2]s
+}
+
+// UnmarshalJSON unmarshals null, single identifier or array of identifiers.
+func (l *%[1]s) UnmarshalJSON(data []byte) error {
+	*l = %[1]s{}
+	switch {
+	case string(data) == "null":
+		return nil
+	case len(data) > 0 && data[0] == '[':
+		l.Many = true
+		return json.Unmarshal(data, &l.Identifiers)
+	}
+	var id %[2]s
+	if err := json.Unmarshal(data, &id); err != nil {
+		return err
+	}
+	l.Identifiers = []%[2]s{id}
+	return nil
+}
+
+// MarshalJSON marshals identifiers as array, if linkage is to-many, otherwise as single identifier or null.
+func (l %[1]s) MarshalJSON() ([]byte, error) {
+	switch {
+	case l.Many:
+		return json.Marshal(append([]%[2]s{}, l.Identifiers...))
+	case len(l.Identifiers) == 0:
+		return []byte("null"), nil
+	}
+	return json.Marshal(l.Identifiers[0])
+}
+`, name, identifier)
+	})
+
+	relationship := ctx.addSharedHelper("JSONAPIRelationship", func(name string) string {
+		return fmt.Sprintf(`
+// %[1]s is a JSON:API relationship.
+type %[1]s struct {
+	Data  *%[2]s          `+"`json:\"data,omitempty\"`"+`
+	Links json.RawMessage `+"`json:\"links,omitempty\"`"+`
+	Meta  json.RawMessage `+"`json:\"meta,omitempty\"`"+`
+}
+`, name, linkage)
+	})
+
+	return ast.NewIdent(ctx.addSharedHelper("JSONAPIRelationships", func(name string) string {
+		return fmt.Sprintf(`
+// %[1]s are relationships of JSON:API resource, by name.
+type %[1]s map[string]%[2]s
+
+// Identifiers returns identifiers of resources linked by relationship with name.
+func (r %[1]s) Identifiers(name string) []%[3]s {
+	if rel, ok := r[name]; ok && rel.Data != nil {
+		return rel.Data.Identifiers
+	}
+	return nil
+}
+`, name, relationship, identifier)
+	}))
+}
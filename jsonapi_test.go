@@ -0,0 +1,115 @@
+package json2go
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const jsonAPITestDocument = `{
+	"data": [{
+		"type": "articles",
+		"id": "1",
+		"attributes": {"title": "Rails is Omakase"},
+		"relationships": {
+			"author": {"links": {"self": "/articles/1/relationships/author"}, "data": {"type": "people", "id": "9"}},
+			"comments": {"data": [{"type": "comments", "id": "5"}, {"type": "comments", "id": "12"}]}
+		}
+	}],
+	"included": [
+		{"type": "people", "id": "9", "attributes": {"name": "Dan"}},
+		{"type": "comments", "id": "5", "attributes": {"body": "First!"}}
+	]
+}`
+
+func TestParserJSONAPI(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		disabled bool
+		input    string
+		expected string
+	}{
+		{
+			name:     "disabled",
+			disabled: true,
+			input:    jsonAPITestDocument,
+			expected: "type Document struct {\n\tData []struct {\n\t\tAttributes struct {",
+		},
+		{
+			name:  "flattened attributes",
+			input: jsonAPITestDocument,
+			expected: "type DataItem struct {\n" +
+				"\tID            string               `json:\"id\"`\n" +
+				"\tRelationships JSONAPIRelationships `json:\"relationships\"`\n" +
+				"\tTitle         string               `json:\"title\"`\n" +
+				"\tType          string               `json:\"type\"`\n" +
+				"}",
+		},
+		{
+			name:     "included",
+			input:    jsonAPITestDocument,
+			expected: "func (d Document) Resolve(id JSONAPIIdentifier) *IncludedItem {",
+		},
+		{
+			name:     "relationship accessor",
+			input:    jsonAPITestDocument,
+			expected: "func (v DataItem) Comments() []JSONAPIIdentifier {",
+		},
+		{
+			name:     "clashing attribute",
+			input:    `{"data":{"type":"a","id":"1","attributes":{"ID":2}}}`,
+			expected: "\tAttributes struct {\n\t\tID int `json:\"ID\"`\n\t} `json:\"attributes\"`",
+		},
+		{
+			name:     "not resource",
+			input:    `{"data":{"kind":"a","id":"1","attributes":{"b":2}}}`,
+			expected: "\tData struct {\n\t\tAttributes struct {",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			parser := NewJSONParser(baseTypeName, OptJSONAPI(!tc.disabled))
+			require.NoError(t, parser.FeedBytes([]byte(tc.input)))
+			out, err := parser.Generate()
+			require.NoError(t, err)
+			assert.Contains(t, out, tc.expected)
+		})
+	}
+}
+
+func TestParserJSONAPICode(t *testing.T) {
+	t.Parallel()
+
+	parser := NewJSONParser(baseTypeName, OptJSONAPI(true))
+	require.NoError(t, parser.FeedBytes([]byte(jsonAPITestDocument)))
+
+	out := runGeneratedCode(t, parser, `
+	var d Document
+	if err := json.NewDecoder(os.Stdin).Decode(&d); err != nil {
+		panic(err)
+	}
+	article := d.Data[0]
+	fmt.Println(article.Title, article.Author(), article.Comments())
+	for _, id := range article.Comments() {
+		if comment := d.Resolve(id); comment != nil {
+			fmt.Println(comment.Body)
+		}
+	}
+	out, err := json.Marshal(article)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(out))
+`, jsonAPITestDocument)
+	assert.Equal(t, "Rails is Omakase [{people 9}] [{comments 5} {comments 12}]\nFirst!\n"+
+		`{"attributes":{"title":"Rails is Omakase"},"id":"1","relationships":{"author":{"data":{"type":"people","id":"9"},`+
+		`"links":{"self":"/articles/1/relationships/author"}},"comments":{"data":[{"type":"comments","id":"5"},`+
+		`{"type":"comments","id":"12"}]}},"type":"articles"}`+"\n", out)
+}
@@ -0,0 +1,107 @@
+package json2go
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"strings"
+)
+
+// isJSONString checks if string is json object or array encoded in json string, like "{\"id\":1}".
+func isJSONString(s string) bool {
+	s = strings.TrimSpace(s)
+	if s == "" || (s[0] != '{' && s[0] != '[') {
+		return false
+	}
+	return json.Valid([]byte(s))
+}
+
+// growEncoded grows node of values decoded from json strings, url query strings, JWTs or base64 strings in input.
+func (n *node) growEncoded(input interface{}) {
+	switch typedInput := input.(type) {
+	case string:
+		// Formats of node are already narrowed by the value, and contain only formats wanted by options.
+		var v interface{}
+		switch {
+		case n.formats&formatQueryString != 0:
+			v = decodeQueryString(typedInput)
+		case n.formats&formatJWT != 0:
+			v, _ = decodeJWTClaims(typedInput)
+		case n.formats&formatBase64JSON != 0:
+			v, _ = decodeBase64JSON(typedInput)
+		case n.formats&formatJSONString != 0:
+			if err := json.Unmarshal([]byte(typedInput), &v); err != nil {
+				return
+			}
+		default:
+			return
+		}
+		if n.encoded == nil {
+			n.encoded = newNode(n.key)
+			n.encoded.name = n.name
+			n.encoded.path = n.path
+			n.encoded.logger = n.logger
+			n.encoded.budget = n.budget
+			n.encoded.growCtx = n.growCtx
+			n.encoded.cache = n.cache
+			n.encoded.inputs = n.inputs
+			n.encoded.weight = n.weight
+			n.encoded.examples = n.examples
+			n.encoded.formats, n.encoded.formatsWanted, n.encoded.enums = n.formatsWanted, n.formatsWanted, n.enums
+			n.encoded.detectors, n.encoded.matching = n.detectors, n.detectors
+		}
+		n.encoded.grow(v)
+	case []interface{}:
+		for _, el := range typedInput {
+			n.growEncoded(el)
+		}
+	}
+}
+
+// astIsJSONStringNode checks if all node's values are json objects or arrays encoded in json strings.
+func astIsJSONStringNode(n *node) bool {
+	return n.t == nodeTypeString && n.formats&formatJSONString != 0 && n.encoded != nil
+}
+
+// astTypeFromJSONStringNode returns helper type of values decoded from json strings, with json methods
+// converting it from/to json string.
+func astTypeFromJSONStringNode(n *node, ctx *astContext) ast.Expr {
+	name := n.name
+	if n.arrayLevel > 0 {
+		// Each element of array is a json string.
+		name = singularName(name, ctx.opts.singulars)
+	}
+	name = ctx.uniqueName(name + "JSON")
+	ctx.addImport("encoding/json")
+	valueType := astExprString(astTypeFromNode(n.encoded, ctx))
+
+	ctx.addHelper(fmt.Sprintf(`
+// %[1]s is a json value encoded in json string.
+type %[1]s %[2]s
+
+// UnmarshalJSON unmarshals value from json string with encoded json.
+func (v *%[1]s) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	type plain %[1]s
+	return json.Unmarshal([]byte(s), (*plain)(v))
+}
+
+// MarshalJSON marshals value as json string with encoded json.
+func (v %[1]s) MarshalJSON() ([]byte, error) {
+	type plain %[1]s
+	data, err := json.Marshal(plain(v))
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(data))
+}
+`, name, valueType))
+
+	return ast.NewIdent(name)
+}
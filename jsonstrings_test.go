@@ -0,0 +1,124 @@
+package json2go
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserExpandJSONStrings(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		expand   bool
+		inputs   []string
+		expected string
+	}{
+		{
+			name:     "disabled",
+			inputs:   []string{`{"meta":"{\"id\":1}"}`},
+			expected: "string",
+		},
+		{
+			name:     "object",
+			expand:   true,
+			inputs:   []string{`{"meta":"{\"id\":1}"}`, `{"meta":null}`},
+			expected: "*MetaJSON",
+		},
+		{
+			name:     "array of strings",
+			expand:   true,
+			inputs:   []string{`{"meta":["[1,2]","[]"]}`},
+			expected: "[]MetaJSON",
+		},
+		{
+			name:     "some plain strings",
+			expand:   true,
+			inputs:   []string{`{"meta":"{\"id\":1}"}`, `{"meta":"id"}`},
+			expected: "string",
+		},
+		{
+			name:     "json scalars",
+			expand:   true,
+			inputs:   []string{`{"meta":"12"}`},
+			expected: "string",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			parser := NewJSONParser(baseTypeName, OptExpandJSONStrings(tc.expand))
+			for _, input := range tc.inputs {
+				require.NoError(t, parser.FeedBytes([]byte(input)))
+			}
+
+			fields := parser.Fields()
+			require.Len(t, fields, 1)
+			assert.Equal(t, tc.expected, fields[0].Type)
+		})
+	}
+}
+
+func TestParserExpandJSONStringsCode(t *testing.T) {
+	t.Parallel()
+
+	parser := NewJSONParser(baseTypeName, OptExpandJSONStrings(true))
+	require.NoError(t, parser.FeedBytes([]byte(`{"meta":"{\"id\":1,\"tags\":\"[\\\"a\\\"]\"}","items":"[{\"n\":1.5}]"}`)))
+
+	out := runGeneratedCode(t, parser, `
+	var d Document
+	if err := json.NewDecoder(os.Stdin).Decode(&d); err != nil {
+		panic(err)
+	}
+	fmt.Println(d.Meta.ID, d.Meta.Tags[0], d.Items[0].N)
+	out, err := json.Marshal(d)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(out))
+`, `{"meta":"{\"id\":7,\"tags\":\"[\\\"b\\\"]\"}","items":"[{\"n\":2.5}]"}`)
+	assert.Equal(t, "7 b 2.5\n"+
+		`{"items":"[{\"n\":2.5}]","meta":"{\"id\":7,\"tags\":\"[\\\"b\\\"]\"}"}`+"\n", out)
+}
+
+func TestParserEncodedStringsNeedOptions(t *testing.T) {
+	t.Parallel()
+
+	const jwt = "eyJhbGciOiJIUzI1NiJ9.eyJzdWIiOiIxIn0.c2ln"
+	input := []byte(`{"meta":"{\"id\":1}","query":"a=1&b=2","token":"` + jwt + `","data":"eyJpZCI6MX0="}`)
+	testCases := []struct {
+		name    string
+		opts    []JSONParserOpt
+		encoded []string
+	}{
+		{name: "no options"},
+		{name: "json strings", opts: []JSONParserOpt{OptExpandJSONStrings(true)}, encoded: []string{"meta"}},
+		{name: "query strings", opts: []JSONParserOpt{OptExpandQueryStrings(true)}, encoded: []string{"query"}},
+		{name: "base64 json", opts: []JSONParserOpt{OptExpandBase64JSON(true)}, encoded: []string{"data", "token"}},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := NewJSONParser(baseTypeName, tc.opts...)
+			require.NoError(t, p.FeedBytes(input))
+			var encoded []string
+			for _, c := range p.rootNode.children {
+				if c.encoded != nil {
+					encoded = append(encoded, c.key)
+					assert.NotZero(t, c.formats&formatsEncoded, c.key)
+				} else {
+					assert.Zero(t, c.formats&formatsEncoded, c.key)
+				}
+			}
+			assert.ElementsMatch(t, tc.encoded, encoded)
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package json2go
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// KeySplitting is a policy of handling keys with separators, like "x-request-id", "user.name" or "ns:field".
+type KeySplitting int
+
+const (
+	// KeySplittingNone ignores separators, they are just removed from field names.
+	KeySplittingNone KeySplitting = iota
+	// KeySplittingCamelCase treats separators as word boundaries in field names,
+	// e.g. "x-request-id" becomes "XRequestID".
+	KeySplittingCamelCase
+	// KeySplittingNested explodes keys with separators into nested objects,
+	// e.g. "user.name" becomes "name" attribute of "user" object.
+	// Root type gets UnmarshalJSON method nesting keys the same way.
+	KeySplittingNested
+)
+
+// ParseKeySplitting returns key splitting policy by name: "none", "camel" or "nested". Empty name means none.
+func ParseKeySplitting(name string) (KeySplitting, error) {
+	switch name {
+	case "", "none":
+		return KeySplittingNone, nil
+	case "camel":
+		return KeySplittingCamelCase, nil
+	case "nested":
+		return KeySplittingNested, nil
+	}
+	return KeySplittingNone, fmt.Errorf("unknown key splitting policy: %s", name)
+}
+
+// DefaultKeySeparators are separators used by key splitting policies, when no separators are set.
+const DefaultKeySeparators = "-.:"
+
+// splitKey splits key on separators. If key has no separators, or any part is empty, nil is returned.
+func splitKey(key, separators string) []string {
+	var parts []string
+	start := 0
+	for i, r := range key {
+		if !strings.ContainsRune(separators, r) {
+			continue
+		}
+		if i == start {
+			return nil
+		}
+		parts = append(parts, key[start:i])
+		start = i + len(string(r))
+	}
+	if parts == nil || start == len(key) {
+		return nil
+	}
+
+	return append(parts, key[start:])
+}
+
+// nestKeys returns copy of json value with object keys with separators exploded into nested objects.
+// If key can't be nested, because its path is already used by a value that is not an object, it is left as is.
+func nestKeys(v interface{}, separators string) interface{} {
+	switch typedValue := v.(type) {
+	case []interface{}:
+		out := make([]interface{}, len(typedValue))
+		for i, el := range typedValue {
+			out[i] = nestKeys(el, separators)
+		}
+		return out
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(typedValue))
+		var splitKeys []string
+		for k, el := range typedValue {
+			if splitKey(k, separators) != nil {
+				splitKeys = append(splitKeys, k)
+				continue
+			}
+			out[k] = nestKeys(el, separators)
+		}
+
+		sort.Strings(splitKeys)
+		for _, k := range splitKeys {
+			el := nestKeys(typedValue[k], separators)
+			if !nestKey(out, splitKey(k, separators), el) {
+				out[k] = el
+			}
+		}
+		return out
+	}
+
+	return v
+}
+
+// nestKey puts value in nested objects, creating them if needed. If path is already used, false is returned.
+func nestKey(obj map[string]interface{}, path []string, v interface{}) bool {
+	cur := obj
+	for _, k := range path[:len(path)-1] {
+		switch next := cur[k].(type) {
+		case map[string]interface{}:
+			cur = next
+		case nil:
+			if _, ok := cur[k]; ok {
+				return false
+			}
+			m := make(map[string]interface{})
+			cur[k] = m
+			cur = m
+		default:
+			return false
+		}
+	}
+
+	last := path[len(path)-1]
+	if _, ok := cur[last]; ok {
+		return false
+	}
+	cur[last] = v
+
+	return true
+}
+
+// camelCaseKeyName returns field name for key, treating separators as word boundaries.
+func camelCaseKeyName(key, separators string) string {
+	return attrName(strings.Map(func(r rune) rune {
+		if strings.ContainsRune(separators, r) {
+			return '_'
+		}
+		return r
+	}, key))
+}
+
+// renameSeparatedKeys sets names of attributes with separators in keys, treating separators as word boundaries.
+func renameSeparatedKeys(n *node, separators string) {
+	for _, c := range n.children {
+		if c.key != "" && strings.ContainsAny(c.key, separators) {
+			if name := camelCaseKeyName(c.key, separators); name != "" && name != c.name {
+				for n.hasChildNamed(name) {
+					name = nextName(name)
+				}
+				c.name = name
+			}
+		}
+		renameSeparatedKeys(c, separators)
+	}
+}
+
+// nestKeysHelperSrc returns source code of generated function nesting keys, working like nestKeys.
+func nestKeysHelperSrc(name, separators string) string {
+	return fmt.Sprintf(`
+// %[1]s explodes object keys with separators into nested objects.
+func %[1]s(v interface{}) interface{} {
+	switch tv := v.(type) {
+	case []interface{}:
+		for i := range tv {
+			tv[i] = %[1]s(tv[i])
+		}
+		return tv
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(tv))
+		var splitKeys []string
+		for k, el := range tv {
+			if %[1]sSplit(k) != nil {
+				splitKeys = append(splitKeys, k)
+				continue
+			}
+			out[k] = %[1]s(el)
+		}
+		sort.Strings(splitKeys)
+		for _, k := range splitKeys {
+			el := %[1]s(tv[k])
+			path := %[1]sSplit(k)
+			cur := out
+			ok := true
+			for _, pk := range path[:len(path)-1] {
+				if next, isObj := cur[pk].(map[string]interface{}); isObj {
+					cur = next
+				} else if _, used := cur[pk]; used {
+					ok = false
+					break
+				} else {
+					m := make(map[string]interface{})
+					cur[pk] = m
+					cur = m
+				}
+			}
+			if _, used := cur[path[len(path)-1]]; !ok || used {
+				out[k] = el
+				continue
+			}
+			cur[path[len(path)-1]] = el
+		}
+		return out
+	}
+	return v
+}
+
+// %[1]sSplit splits key on separators. If key has no separators, or any part is empty, nil is returned.
+func %[1]sSplit(key string) []string {
+	parts := strings.FieldsFunc(key, func(r rune) bool {
+		return strings.ContainsRune(%[2]q, r)
+	})
+	if len(parts) < 2 || len(strings.Join(parts, "")) != len(key)-len(parts)+1 {
+		return nil
+	}
+	return parts
+}
+`, name, separators)
+}
@@ -0,0 +1,89 @@
+package json2go
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitKey(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		key    string
+		result []string
+	}{
+		{key: "name", result: nil},
+		{key: "user.name", result: []string{"user", "name"}},
+		{key: "x-request-id", result: []string{"x", "request", "id"}},
+		{key: "ns:user.id", result: []string{"ns", "user", "id"}},
+		{key: ".name", result: nil},
+		{key: "name.", result: nil},
+		{key: "user..name", result: nil},
+		{key: "-", result: nil},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.key, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.result, splitKey(tc.key, DefaultKeySeparators))
+		})
+	}
+}
+
+func TestNestKeys(t *testing.T) {
+	t.Parallel()
+
+	in := map[string]interface{}{
+		"id":        1.0,
+		"user.name": "x",
+		"user.id":   2.0,
+		"a":         "b",
+		"a.b":       "c",
+		"list": []interface{}{
+			map[string]interface{}{"x-y": true},
+		},
+		"n..m": 3.0,
+	}
+	expected := map[string]interface{}{
+		"id": 1.0,
+		"user": map[string]interface{}{
+			"name": "x",
+			"id":   2.0,
+		},
+		"a":   "b",
+		"a.b": "c",
+		"list": []interface{}{
+			map[string]interface{}{
+				"x": map[string]interface{}{"y": true},
+			},
+		},
+		"n..m": 3.0,
+	}
+
+	assert.Equal(t, expected, nestKeys(in, DefaultKeySeparators))
+	assert.Contains(t, in, "user.name", "input should not be modified")
+}
+
+func TestCamelCaseKeyName(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		key  string
+		name string
+	}{
+		{key: "x-request-id", name: "XRequestID"},
+		{key: "user.name", name: "UserName"},
+		{key: "ns:type", name: "NsType"},
+		{key: "content-type", name: "ContentType"},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.key, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.name, camelCaseKeyName(tc.key, DefaultKeySeparators))
+		})
+	}
+}
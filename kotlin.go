@@ -0,0 +1,123 @@
+package json2go
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// kotlinKeywords are hard keywords of Kotlin, quoted with backticks in property names.
+var kotlinKeywords = map[string]bool{
+	"as": true, "break": true, "class": true, "continue": true, "do": true, "else": true, "false": true, "for": true,
+	"fun": true, "if": true, "in": true, "interface": true, "is": true, "null": true, "object": true, "package": true,
+	"return": true, "super": true, "this": true, "throw": true, "true": true, "try": true, "typealias": true,
+	"typeof": true, "val": true, "var": true, "when": true, "while": true,
+}
+
+// KotlinEmitter emits Kotlin data classes serialized with kotlinx.serialization from IR, root class first.
+//
+// Classes and properties keep go names, properties in lower camel case, with SerialName annotations of json keys.
+// Fields of pointers, optional values or with omitempty are nullable, with null defaults. Times are kotlinx.datetime
+// instants, any and opaque values are json elements.
+type KotlinEmitter struct{}
+
+// Emit returns Kotlin source of data classes.
+func (KotlinEmitter) Emit(ir *Schema) ([]byte, error) {
+	s, err := newIDLSchema(ir, "Kotlin data class", false)
+	if err != nil {
+		return nil, err
+	}
+
+	imports := map[string]bool{"kotlinx.serialization.Serializable": true}
+	var classes strings.Builder
+	for _, d := range s.decls {
+		fmt.Fprintf(&classes, "\n@Serializable\n")
+		if len(d.fields) == 0 {
+			// Data classes need properties.
+			fmt.Fprintf(&classes, "class %s\n", d.name)
+			continue
+		}
+		fmt.Fprintf(&classes, "data class %s(\n", d.name)
+		names := make(map[string]bool)
+		for _, f := range d.fields {
+			name := lowerCamelCaseName(f.Name)
+			for names[name] {
+				name = nextName(name)
+			}
+			names[name] = true
+			if name != f.Key {
+				imports["kotlinx.serialization.SerialName"] = true
+				fmt.Fprintf(&classes, "    @SerialName(%s)\n", kotlinString(f.Key))
+			}
+			if kotlinKeywords[name] {
+				name = "`" + name + "`"
+			}
+			typ := kotlinType(ir, s, f.Type, imports)
+			if f.isOptional() {
+				typ = strings.TrimSuffix(typ, "?") + "? = null"
+			}
+			fmt.Fprintf(&classes, "    val %s: %s,\n", name, typ)
+		}
+		classes.WriteString(")\n")
+	}
+
+	names := make([]string, 0, len(imports))
+	for name := range imports {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "import %s\n", name)
+	}
+	b.WriteString(classes.String())
+	return []byte(b.String()), nil
+}
+
+// kotlinType returns Kotlin type of values. Imports of used types are added to imports.
+func kotlinType(ir *Schema, s *idlSchema, t *SchemaType, imports map[string]bool) string {
+	switch t.Kind {
+	case SchemaBool:
+		return "Boolean"
+	case SchemaInt:
+		return "Long"
+	case SchemaFloat:
+		if t.Bits == 32 {
+			return "Float"
+		}
+		return "Double"
+	case SchemaString:
+		return "String"
+	case SchemaTime:
+		imports["kotlinx.datetime.Instant"] = true
+		return "Instant"
+	case SchemaPointer, SchemaOptional:
+		return strings.TrimSuffix(kotlinType(ir, s, t.Elem, imports), "?") + "?"
+	case SchemaSlice:
+		return "List<" + kotlinType(ir, s, t.Elem, imports) + ">"
+	case SchemaMap:
+		key := "String"
+		if t.Key != nil && t.Key.Kind == SchemaInt {
+			key = "Long"
+		}
+		return "Map<" + key + ", " + kotlinType(ir, s, t.Elem, imports) + ">"
+	case SchemaStruct:
+		return s.names[t]
+	case SchemaNamed:
+		return kotlinType(ir, s, ir.Types[t.Name], imports)
+	}
+	imports["kotlinx.serialization.json.JsonElement"] = true
+	return "JsonElement"
+}
+
+// kotlinString returns Kotlin string literal, with dollar signs escaped.
+func kotlinString(s string) string {
+	return strings.Replace(strconv.Quote(s), "$", `\$`, -1)
+}
+
+// KotlinTypes returns Kotlin data classes describing parsed documents, see KotlinEmitter.
+func (p *JSONParser) KotlinTypes() (string, error) {
+	out, err := KotlinEmitter{}.Emit(p.Schema())
+	return string(out), err
+}
@@ -0,0 +1,101 @@
+package json2go
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKotlinTypes(t *testing.T) {
+	t.Parallel()
+
+	p := NewJSONParser(baseTypeName)
+	require.NoError(t, p.FeedBytes([]byte(`{"id": 1, "user_name": "a", "tags": ["x"], "addr": {"zip": "1"},
+		"at": "2021-01-01T00:00:00Z", "extra": 1, "class": "c"}`)))
+	require.NoError(t, p.FeedBytes([]byte(`{"id": 2, "tags": [], "addr": {"zip": "2"},
+		"at": "2021-01-02T00:00:00Z", "extra": "x", "score": 0.5, "class": "c"}`)))
+
+	src, err := p.KotlinTypes()
+	require.NoError(t, err)
+	assert.Equal(t, `import kotlinx.datetime.Instant
+import kotlinx.serialization.SerialName
+import kotlinx.serialization.Serializable
+import kotlinx.serialization.json.JsonElement
+
+@Serializable
+data class Document(
+    val addr: DocumentAddr,
+    val at: Instant,
+    val `+"`class`"+`: String,
+    val extra: JsonElement,
+    val id: Long,
+    val score: Double? = null,
+    val tags: List<String>,
+    @SerialName("user_name")
+    val userName: String? = null,
+)
+
+@Serializable
+data class DocumentAddr(
+    val zip: String,
+)
+`, src)
+}
+
+func TestKotlinEmitter(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		ir       *Schema
+		expected string
+		err      error
+	}{
+		{
+			name: "recursive type, maps and empty class",
+			ir: &Schema{Root: "Node", Types: map[string]*SchemaType{
+				"Node": {Kind: SchemaStruct, Fields: []SchemaField{
+					{Name: "Parent", Key: "parent", Type: &SchemaType{Kind: SchemaPointer, Elem: &SchemaType{Kind: SchemaNamed, Name: "Node"}}},
+					{Name: "Counts", Key: "counts", Type: &SchemaType{Kind: SchemaMap, Key: &SchemaType{Kind: SchemaInt},
+						Elem: &SchemaType{Kind: SchemaPointer, Elem: &SchemaType{Kind: SchemaFloat, Bits: 32}}}},
+					{Name: "Meta", Key: "meta", Type: &SchemaType{Kind: SchemaNamed, Name: "Meta"}},
+				}},
+				"Meta": {Kind: SchemaStruct},
+			}},
+			expected: `import kotlinx.serialization.Serializable
+
+@Serializable
+data class Node(
+    val parent: Node? = null,
+    val counts: Map<Long, Float?>,
+    val meta: Meta,
+)
+
+@Serializable
+class Meta
+`,
+		},
+		{
+			name: "root isn't struct",
+			ir:   &Schema{Root: "Document", Types: map[string]*SchemaType{"Document": {Kind: SchemaString}}},
+			err:  ErrUnsupportedShape,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			out, err := KotlinEmitter{}.Emit(tc.ir)
+			if tc.err != nil {
+				assert.True(t, errors.Is(err, tc.err), "unexpected error: %v", err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(out))
+		})
+	}
+}
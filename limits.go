@@ -0,0 +1,136 @@
+package json2go
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// nodeBudget is a number of nodes, which can be still added to inferred types tree, see OptMaxNodes.
+type nodeBudget struct {
+	left int
+}
+
+// nodeBudgetExceeded is a panic value stopping growth of tree, when budget is spent.
+type nodeBudgetExceeded struct{}
+
+// spend takes node from budget, panicking with nodeBudgetExceeded when budget is spent. Nil budget is unlimited.
+func (b *nodeBudget) spend() {
+	if b == nil {
+		return
+	}
+	if b.left <= 0 {
+		panic(nodeBudgetExceeded{})
+	}
+	b.left--
+}
+
+// recoverNodeBudget recovers from panic of spent node budget, setting error. Other panics are passed on.
+func recoverNodeBudget(err *error) {
+	if r := recover(); r != nil {
+		if _, ok := r.(nodeBudgetExceeded); !ok {
+			panic(r)
+		}
+		*err = fmt.Errorf("%w: input adds more nodes than limit", ErrNodeBudgetExceeded)
+	}
+}
+
+// maxRateLimiterClients is a number of tracked clients, above which clients with full buckets are forgotten.
+const maxRateLimiterClients = 10000
+
+// RateLimiter limits rate of requests of each client, see Service.RateLimiter. Clients are identified by
+// ContextWithClient. Each client has a bucket of burst tokens, refilled with rate tokens per second.
+// Each request takes one token, requests finding empty bucket are rejected.
+type RateLimiter struct {
+	rate  float64
+	burst float64
+
+	mu      sync.Mutex
+	buckets map[string]*rateBucket
+	now     func() time.Time
+}
+
+type rateBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter returns rate limiter allowing rate requests per second of each client, with bursts of burst requests.
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	return &RateLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: make(map[string]*rateBucket),
+		now:     time.Now,
+	}
+}
+
+// Allow takes token from bucket of client, and reports if there was one.
+func (l *RateLimiter) Allow(client string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if len(l.buckets) >= maxRateLimiterClients {
+		for c, b := range l.buckets {
+			if b.refill(now, l.rate, l.burst) == l.burst {
+				delete(l.buckets, c)
+			}
+		}
+	}
+	b, ok := l.buckets[client]
+	if !ok {
+		b = &rateBucket{tokens: l.burst, last: now}
+		l.buckets[client] = b
+	}
+	if b.refill(now, l.rate, l.burst) < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// refill adds tokens for time passed since last refill, up to burst, and returns tokens.
+func (b *rateBucket) refill(now time.Time, rate, burst float64) float64 {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens += elapsed * rate
+		if b.tokens > burst {
+			b.tokens = burst
+		}
+		b.last = now
+	}
+	return b.tokens
+}
+
+// clientKey is a context key of client identifier.
+type clientKey struct{}
+
+// ContextWithClient returns context of request of client, like remote address or API key, used by Service
+// to limit rate of client's requests.
+func ContextWithClient(ctx context.Context, client string) context.Context {
+	return context.WithValue(ctx, clientKey{}, client)
+}
+
+// clientFromContext returns client set with ContextWithClient, or empty string.
+func clientFromContext(ctx context.Context) string {
+	client, _ := ctx.Value(clientKey{}).(string)
+	return client
+}
+
+// sizeLimitReader is a reader failing with ErrInputTooLarge, when more than limit bytes are read.
+type sizeLimitReader struct {
+	r     io.Reader
+	limit int64
+	n     int64
+}
+
+func (r *sizeLimitReader) Read(b []byte) (int, error) {
+	n, err := r.r.Read(b)
+	r.n += int64(n)
+	if r.n > r.limit {
+		return n, fmt.Errorf("%w: more than %d bytes", ErrInputTooLarge, r.limit)
+	}
+	return n, err
+}
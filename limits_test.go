@@ -0,0 +1,88 @@
+package json2go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptMaxNodes(t *testing.T) {
+	t.Parallel()
+
+	p := NewJSONParser(baseTypeName, OptMaxNodes(5))
+	require.NoError(t, p.FeedBytes([]byte(`{"a":{"b":1},"c":[{"b":2}]}`)), "root, a, a.b, c and c.b")
+	require.NoError(t, p.FeedBytes([]byte(`{"a":{"b":3}}`)), "existing nodes don't spend budget")
+	err := p.FeedBytes([]byte(`{"d":1}`))
+	assert.True(t, errors.Is(err, ErrNodeBudgetExceeded), "%v", err)
+
+	keys := make([]string, 100)
+	for i := range keys {
+		keys[i] = fmt.Sprintf(`"k%d":%d`, i, i)
+	}
+	err = NewJSONParser(baseTypeName, OptMaxNodes(50), OptExpandJSONStrings(true)).
+		FeedBytes([]byte(fmt.Sprintf(`{"s":%q}`, "{"+strings.Join(keys, ",")+"}")))
+	assert.True(t, errors.Is(err, ErrNodeBudgetExceeded), "nodes of encoded values spend budget: %v", err)
+
+	p = NewJSONParser(baseTypeName, OptMaxNodes(3))
+	require.NoError(t, p.FeedBytes([]byte(`{"a":1,"b":"x"}`)))
+	_, err = p.Generate()
+	assert.NoError(t, err, "generation doesn't spend budget")
+}
+
+func TestRateLimiter(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := NewRateLimiter(2, 3)
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		assert.True(t, l.Allow("a"), "burst request %d", i)
+	}
+	assert.False(t, l.Allow("a"))
+	assert.True(t, l.Allow("b"), "clients have separate limits")
+
+	now = now.Add(600 * time.Millisecond)
+	assert.True(t, l.Allow("a"), "token is refilled")
+	assert.False(t, l.Allow("a"))
+
+	now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		assert.True(t, l.Allow("a"), "bucket is refilled up to burst, request %d", i)
+	}
+	assert.False(t, l.Allow("a"))
+}
+
+func TestServiceLimits(t *testing.T) {
+	t.Parallel()
+
+	s := &Service{
+		Opts:         []JSONParserOpt{OptMaxNodes(3)},
+		MaxInputSize: 20,
+		RateLimiter:  NewRateLimiter(0, 2),
+	}
+	ctx := ContextWithClient(context.Background(), "client")
+
+	_, err := s.Convert(ctx, &ConvertRequest{Samples: [][]byte{[]byte(`{"a":1}`), []byte(`{"b":1,"c":1,"d":1}`)}})
+	assert.True(t, errors.Is(err, ErrInputTooLarge), "%v", err)
+
+	_, err = s.ConvertStream(ctx, &chunks{{Data: []byte(`{"a":1}`)}, {Data: []byte(`{"b":[1,2,3,4,5,6,7,8,9]}`)}})
+	assert.True(t, errors.Is(err, ErrInputTooLarge), "%v", err)
+
+	_, err = s.Diff(ctx, &DiffRequest{})
+	assert.True(t, errors.Is(err, ErrRateLimited), "%v", err)
+
+	other := ContextWithClient(context.Background(), "other")
+	_, err = s.Convert(other, &ConvertRequest{Samples: [][]byte{[]byte(`{"a":1,"b":2,"c":3}`)}})
+	assert.True(t, errors.Is(err, ErrNodeBudgetExceeded), "%v", err)
+
+	resp, err := s.Convert(other, &ConvertRequest{Samples: [][]byte{[]byte(`{"a":1,"b":2}`)}})
+	require.NoError(t, err)
+	assert.Equal(t, "type Document struct {\n\tA int `json:\"a\"`\n\tB int `json:\"b\"`\n}", resp.Code)
+}
@@ -0,0 +1,51 @@
+package json2go
+
+// logFieldNames are field names of conventional keys of structured log records, written by common loggers like
+// zap, zerolog, logrus, slog or logstash.
+var logFieldNames = map[string]string{
+	"level":      "Level",
+	"lvl":        "Level",
+	"severity":   "Level",
+	"ts":         "Time",
+	"time":       "Time",
+	"timestamp":  "Time",
+	"@timestamp": "Time",
+	"msg":        "Message",
+	"message":    "Message",
+	"caller":     "Caller",
+	"source":     "Caller",
+}
+
+// applyStructuredLogs treats root objects as structured log records. Conventional keys get conventional field
+// names, other keys present in less than minPresence fraction of records are removed, so their values are kept in
+// Extra map of root type.
+func applyStructuredLogs(root *node, minPresence float64) {
+	if root.t.id() != nodeTypeObject.id() || root.arrayLevel > 0 {
+		return
+	}
+
+	root.logRecord = true
+	fieldNames := make(map[string]bool)
+	for _, c := range root.children {
+		if _, ok := logFieldNames[c.key]; !ok {
+			fieldNames[c.name] = true
+		}
+	}
+	var children []*node
+	for _, c := range root.children {
+		if name, ok := logFieldNames[c.key]; ok {
+			if !fieldNames[name] {
+				fieldNames[name] = true
+				c.name = name
+			}
+			children = append(children, c)
+			continue
+		}
+		if presence, _ := c.presence(root); presence < minPresence {
+			root.logf("key %q %s is kept in extra values", c.key, c.presenceDesc(root, "records"))
+			continue
+		}
+		children = append(children, c)
+	}
+	root.children = children
+}
@@ -0,0 +1,38 @@
+package json2go
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserStructuredLogs(t *testing.T) {
+	t.Parallel()
+
+	p := NewJSONParser("Entry", OptStructuredLogs(0.5))
+	for _, record := range []string{
+		`{"level":"info","ts":1609459200.5,"msg":"started","caller":"main.go:10","service":"api","port":8080}`,
+		`{"level":"info","ts":1609459201.5,"msg":"request","service":"api","request_id":"a","path":"/users"}`,
+		`{"level":"error","ts":1609459202.5,"msg":"failed","service":"api","user_id":42,"error":"timeout"}`,
+	} {
+		require.NoError(t, p.FeedBytes([]byte(record)))
+	}
+	out, err := p.Generate()
+	require.NoError(t, err)
+	assert.Contains(t, out, "type Entry struct {\n"+
+		"\tCaller  string                     `json:\"caller,omitempty\"`\n"+
+		"\tLevel   string                     `json:\"level\"`\n"+
+		"\tMessage string                     `json:\"msg\"`\n"+
+		"\tService string                     `json:\"service\"`\n"+
+		"\tTime    float64                    `json:\"ts\"`\n"+
+		"\tExtra   map[string]json.RawMessage `json:\"-\"`\n"+
+		"}")
+	assert.Contains(t, out, "[]string{\"caller\", \"level\", \"msg\", \"service\", \"ts\"}")
+
+	p = NewJSONParser("Entry", OptStructuredLogs(0.5))
+	require.NoError(t, p.FeedBytes([]byte(`[{"msg":"a","id":1}]`)))
+	out, err = p.Generate()
+	require.NoError(t, err)
+	assert.NotContains(t, out, "Extra")
+}
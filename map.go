@@ -1,60 +1,152 @@
 package json2go
 
-import "strings"
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
 
-func convertViableObjectsToMaps(root *node, minAttributes uint) {
-	// for _, c := range root.children {
-	// 	if c.t.id() != nodeTypeObject.id() {
-	// 		continue
-	// 	}
+// Map key types, that can be used instead of string.
+const (
+	mapKeyString = ""
+	mapKeyInt    = "int"
+	mapKeyUUID   = "uuid"
+)
 
-	// 	convertViableObjectsToMaps(c, minAttributes)
+var uuidRe = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
 
-	// 	if tryConvertToMap(c, minAttributes) {
-	// 		continue
-	// 	}
-	// }
-
-	tryConvertToMap(root, minAttributes)
+// convertViableObjectsToMaps converts objects in tree to maps, where possible.
+// Conversion starts from the top of the tree, so when maxDepth limits number of nested map levels,
+// outermost objects are converted.
+// maxDepth equal to 0 means no limit. Objects at structPaths aren't converted, see OptStructAt.
+func convertViableObjectsToMaps(root *node, minAttributes uint, maxDepth uint, structPaths map[string]bool) {
+	convertToMaps(root, minAttributes, maxDepth, 0, structPaths)
 }
 
-func tryConvertToMap(n *node, minAttributes uint) bool {
-	if len(n.children) < int(minAttributes) {
-		return false
+// convertToMaps converts node subtree to maps. depth is a number of map levels directly above the node.
+func convertToMaps(n *node, minAttributes uint, maxDepth uint, depth uint, structPaths map[string]bool) {
+	if n.t == nodeTypeMap {
+		// Map forced with OptMapAt.
+		convertToMaps(n.children[0], minAttributes, maxDepth, depth+1, structPaths)
+		return
 	}
-	if len(n.children) < 1 {
-		return false
+	if (maxDepth == 0 || depth < maxDepth) && tryConvertToMap(n, minAttributes, structPaths) {
+		convertToMaps(n.children[0], minAttributes, maxDepth, depth+1, structPaths)
+		return
 	}
 
-	// Children has to have same type and structure.
-	t := n.children[0].t
-	sid := mergeNumsStructureID(n.children[0], false)
 	for _, c := range n.children {
-		if !t.expands(c.t) && !c.t.expands(t) {
-			return false
-		}
-		if mergeNumsStructureID(c, false) != sid {
-			return false
-		}
+		convertToMaps(c, minAttributes, maxDepth, 0, structPaths)
+	}
+}
+
+func tryConvertToMap(n *node, minAttributes uint, structPaths map[string]bool) bool {
+	if structPaths[n.path] || mapValueStructureID(n, minAttributes) == "" {
+		return false
+	}
+	// Map value type node. Node has children, checked by mapValueStructureID, so merging doesn't fail.
+	newNode, err := mergeNodes(n.children)
+	if err != nil {
+		return false
 	}
 
 	// Convert this node to map.
 	n.t = nodeTypeMap
+	n.mapKeyType = mapKeyTypeFromNodes(n.children)
 
-	// Add child as map value type node
-	newNode := mergeNodes(n.children)
 	newNode.key = ""
 	newNode.name = ""
 	newNode.required = true
+	newNode.setPath(childPath(n.path, mapValuePathKey))
 	n.children = []*node{newNode}
 
 	return true
 }
 
-func mergeNumsStructureID(n *node, withKey bool) string {
-	sid := structureID(n, withKey)
-	sid = strings.Replace(sid, nodeTypeInt.id(), "number", -1)
-	sid = strings.Replace(sid, nodeTypeFloat.id(), "number", -1)
+// forceMaps converts objects at paths to maps, regardless of their attributes, see OptMapAt.
+func forceMaps(n *node, paths map[string]bool) {
+	if paths[n.path] && n.t.id() == nodeTypeObject.id() {
+		forceKind(n, nodeTypeMap)
+	}
+	for _, c := range n.children {
+		forceMaps(c, paths)
+	}
+}
+
+// mapValueStructureID returns structure id of map value, if node can be converted to map.
+// If node can't be converted, empty string is returned.
+func mapValueStructureID(n *node, minAttributes uint) string {
+	if len(n.children) < int(minAttributes) {
+		return ""
+	}
+	if len(n.children) < 1 {
+		return ""
+	}
+
+	// Children has to have same type and structure.
+	t := n.children[0].t
+	sid := mapStructureID(n.children[0], minAttributes, false)
+	for _, c := range n.children[1:] {
+		if !t.expands(c.t) && !c.t.expands(t) {
+			return ""
+		}
+		if mapStructureID(c, minAttributes, false) != sid {
+			return ""
+		}
+	}
 
 	return sid
 }
+
+// mapStructureID works like structureID, but treats all viable objects in subtree as maps.
+// Numeric types are treated as the same type.
+func mapStructureID(n *node, minAttributes uint, withKey bool) string {
+	id := n.t.id()
+	if id == nodeTypeInt.id() || id == nodeTypeFloat.id() {
+		id = "number"
+	}
+
+	if vid := mapValueStructureID(n, minAttributes); vid != "" {
+		id = nodeTypeMap.id() + structIDlevelSeparator + vid
+	} else if len(n.children) > 0 {
+		parts := make([]string, 0, len(n.children))
+		for _, child := range n.children {
+			parts = append(parts, mapStructureID(child, minAttributes, true))
+		}
+		id += structIDlevelSeparator + strings.Join(parts, ",")
+	}
+
+	if withKey {
+		id = n.key + "." + id
+	}
+
+	return id
+}
+
+// mapKeyTypeFromNodes returns key type fitting keys of all given nodes.
+func mapKeyTypeFromNodes(nodes []*node) string {
+	if len(nodes) == 0 {
+		return mapKeyString
+	}
+
+	keyType := mapKeyTypeFromKey(nodes[0].key)
+	for _, n := range nodes[1:] {
+		if mapKeyTypeFromKey(n.key) != keyType {
+			return mapKeyString
+		}
+	}
+
+	return keyType
+}
+
+func mapKeyTypeFromKey(key string) string {
+	// Only canonical integers are accepted, so marshaling map back results in the same keys.
+	if v, err := strconv.ParseInt(key, 10, 64); err == nil && strconv.FormatInt(v, 10) == key {
+		return mapKeyInt
+	}
+	if uuidRe.MatchString(key) {
+		return mapKeyUUID
+	}
+
+	return mapKeyString
+}
@@ -0,0 +1,135 @@
+package json2go
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapKeyTypeFromNodes(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		keys     []string
+		expected string
+	}{
+		{
+			name:     "no keys",
+			expected: mapKeyString,
+		},
+		{
+			name:     "strings",
+			keys:     []string{"a", "b"},
+			expected: mapKeyString,
+		},
+		{
+			name:     "ints",
+			keys:     []string{"1", "-2", "300"},
+			expected: mapKeyInt,
+		},
+		{
+			name:     "non canonical ints",
+			keys:     []string{"1", "02"},
+			expected: mapKeyString,
+		},
+		{
+			name:     "ints and strings",
+			keys:     []string{"1", "b"},
+			expected: mapKeyString,
+		},
+		{
+			name: "uuids",
+			keys: []string{
+				"7c9e6679-7425-40de-944b-e07fc1f90ae7",
+				"C56A4180-65AA-42EC-A945-5FD21DEC0538",
+			},
+			expected: mapKeyUUID,
+		},
+		{
+			name: "uuids and ints",
+			keys: []string{
+				"7c9e6679-7425-40de-944b-e07fc1f90ae7",
+				"12",
+			},
+			expected: mapKeyString,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			var nodes []*node
+			for _, k := range tc.keys {
+				nodes = append(nodes, newNode(k))
+			}
+			assert.Equal(t, tc.expected, mapKeyTypeFromNodes(nodes))
+		})
+	}
+}
+
+func TestParserMapAndStructAt(t *testing.T) {
+	t.Parallel()
+
+	const input = `{"translations":{"en":"a","de":"b"},` +
+		`"config":{"a":{"x":1},"b":{"x":2},"c":{"x":3},"d":{"x":4},"e":{"x":5}}}`
+	testCases := []struct {
+		name     string
+		opts     []JSONParserOpt
+		expected map[string]string
+	}{
+		{
+			name: "default",
+			expected: map[string]string{
+				"$.translations": "struct{...}", "$.translations.en": "string", "$.translations.de": "string",
+				"$.config": "struct{...}", "$.config.a": "struct{...}", "$.config.a.x": "int",
+				"$.config.b": "struct{...}", "$.config.b.x": "int", "$.config.c": "struct{...}", "$.config.c.x": "int",
+				"$.config.d": "struct{...}", "$.config.d.x": "int", "$.config.e": "struct{...}", "$.config.e.x": "int",
+			},
+		},
+		{
+			name: "map at",
+			opts: []JSONParserOpt{OptMapAt("$.translations", "$.config.a", "$.missing")},
+			expected: map[string]string{
+				"$.translations": "map[string]string", "$.translations.*": "string",
+				"$.config": "struct{...}", "$.config.a": "map[string]int", "$.config.a.*": "int",
+				"$.config.b": "struct{...}", "$.config.b.x": "int", "$.config.c": "struct{...}", "$.config.c.x": "int",
+				"$.config.d": "struct{...}", "$.config.d.x": "int", "$.config.e": "struct{...}", "$.config.e.x": "int",
+			},
+		},
+		{
+			name: "struct at",
+			opts: []JSONParserOpt{OptMakeMaps(true, 2), OptStructAt("$.config")},
+			expected: map[string]string{
+				"$.translations": "map[string]string", "$.translations.*": "string",
+				"$.config": "struct{...}", "$.config.a": "struct{...}", "$.config.a.x": "int",
+				"$.config.b": "struct{...}", "$.config.b.x": "int", "$.config.c": "struct{...}", "$.config.c.x": "int",
+				"$.config.d": "struct{...}", "$.config.d.x": "int", "$.config.e": "struct{...}", "$.config.e.x": "int",
+			},
+		},
+		{
+			name: "map and struct at",
+			opts: []JSONParserOpt{OptMakeMaps(true, 2), OptMapAt("$.config"), OptStructAt("$.translations")},
+			expected: map[string]string{
+				"$.translations": "struct{...}", "$.translations.en": "string", "$.translations.de": "string",
+				"$.config": "map[string]struct{...}", "$.config.*": "struct{...}", "$.config.*.x": "int",
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			parser := NewJSONParser(baseTypeName, tc.opts...)
+			require.NoError(t, parser.FeedBytes([]byte(input)))
+			fields := make(map[string]string)
+			for _, f := range parser.Fields() {
+				fields[f.Path] = f.Type
+			}
+			assert.Equal(t, tc.expected, fields)
+		})
+	}
+}
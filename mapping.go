@@ -0,0 +1,114 @@
+package json2go
+
+import (
+	"io"
+	"io/ioutil"
+
+	"gopkg.in/yaml.v2"
+)
+
+// NameMappingFile is a conventional name of name mapping file.
+const NameMappingFile = "names.json2go.yaml"
+
+// NameMapping keeps user chosen names of generated struct fields and types, by json path.
+// Mapping can be stored in a file, so manual renames survive types regeneration.
+// Stored type names are also kept by extracted types, so new samples don't renumber suffixes like "Item2".
+// Names clashing with go keywords, predeclared identifiers or imported packages get "Type" or "Field" suffix.
+type NameMapping struct {
+	// Fields are names of struct fields, by path of json attribute, like "$.user.id".
+	Fields map[string]string `json:"fields,omitempty" yaml:"fields,omitempty"`
+	// Types are names of generated types, by path of json value, like "$" for root type.
+	Types map[string]string `json:"types,omitempty" yaml:"types,omitempty"`
+}
+
+// ReadNameMapping reads yaml encoded name mapping.
+func ReadNameMapping(r io.Reader) (NameMapping, error) {
+	var m NameMapping
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return m, err
+	}
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return m, err
+	}
+
+	return m, nil
+}
+
+// Write writes name mapping encoded as yaml.
+func (m NameMapping) Write(w io.Writer) error {
+	data, err := yaml.Marshal(m)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// applyFieldNames renames struct fields in tree according to mapping.
+func (m NameMapping) applyFieldNames(n *node) {
+	for _, c := range n.children {
+		if name, ok := m.Fields[c.path]; ok && c.name != "" {
+			c.name = name
+		}
+		m.applyFieldNames(c)
+	}
+}
+
+// applyTypeNames renames root and extracted types according to mapping.
+func (m NameMapping) applyTypeNames(nodes []*node) {
+	for _, n := range nodes {
+		name, ok := m.Types[n.path]
+		if !ok || name == n.name {
+			continue
+		}
+
+		for _, rn := range nodes {
+			renameExtractedType(rn, n.name, name)
+		}
+		n.name = name
+	}
+}
+
+// assignedTypes returns type names by path, nil mapping has none.
+func (m *NameMapping) assignedTypes() map[string]string {
+	if m == nil {
+		return nil
+	}
+	return m.Types
+}
+
+func renameExtractedType(n *node, oldName, newName string) {
+	if n.t == nodeTypeExtracted && n.externalTypeID == oldName {
+		n.externalTypeID = newName
+	}
+	for _, c := range n.children {
+		renameExtractedType(c, oldName, newName)
+	}
+}
+
+// addSuggestions adds current names of fields and types missing in mapping.
+func (m *NameMapping) addSuggestions(nodes []*node) {
+	if m.Fields == nil {
+		m.Fields = make(map[string]string)
+	}
+	if m.Types == nil {
+		m.Types = make(map[string]string)
+	}
+
+	var addFields func(n *node)
+	addFields = func(n *node) {
+		for _, c := range n.children {
+			if _, ok := m.Fields[c.path]; !ok && c.name != "" {
+				m.Fields[c.path] = c.name
+			}
+			addFields(c)
+		}
+	}
+	for _, n := range nodes {
+		if _, ok := m.Types[n.path]; !ok {
+			m.Types[n.path] = n.name
+		}
+		addFields(n)
+	}
+}
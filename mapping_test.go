@@ -0,0 +1,125 @@
+package json2go
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNameMappingReadWrite(t *testing.T) {
+	t.Parallel()
+
+	m := NameMapping{
+		Fields: map[string]string{
+			"$.user_id": "UID",
+			"$.a.b":     "Bee",
+		},
+		Types: map[string]string{
+			"$": "Root",
+		},
+	}
+
+	var buf bytes.Buffer
+	err := m.Write(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, `fields:
+  $.a.b: Bee
+  $.user_id: UID
+types:
+  $: Root
+`, buf.String())
+
+	m2, err := ReadNameMapping(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, m, m2)
+
+	_, err = ReadNameMapping(strings.NewReader("fields: [1"))
+	assert.Error(t, err)
+}
+
+func TestParserNameMapping(t *testing.T) {
+	t.Parallel()
+
+	input := `{"user_id":1,"author":{"name":"a","id":1},"editor":{"name":"b","id":2},"tags":{"a1":{"v":1},"a2":{"v":2}}}`
+	m := NameMapping{
+		Fields: map[string]string{
+			"$.user_id":  "UID",
+			"$.author":   "Writer",
+			"$.tags.*.v": "Value",
+		},
+		Types: map[string]string{
+			"$":        "Root",
+			"$.author": "Person",
+		},
+	}
+
+	parser := NewJSONParser(
+		baseTypeName,
+		OptExtractCommonTypes(true),
+		OptMakeMaps(true, 2),
+		OptNameMapping(m),
+	)
+	err := parser.FeedBytes([]byte(input))
+	require.NoError(t, err)
+
+	assert.Equal(t, normalizeStr(`
+type Root struct {
+	Editor  Person           `+"`json:\"editor\"`"+`
+	Tags    map[string]struct {
+		Value int `+"`json:\"v\"`"+`
+	} `+"`json:\"tags\"`"+`
+	UID    int    `+"`json:\"user_id\"`"+`
+	Writer Person `+"`json:\"author\"`"+`
+}
+type Person struct {
+	ID   int    `+"`json:\"id\"`"+`
+	Name string `+"`json:\"name\"`"+`
+}`), normalizeStr(parser.String()))
+
+	suggested := parser.SuggestNames(m)
+	assert.Equal(t, NameMapping{
+		Fields: map[string]string{
+			"$.author":      "Writer",
+			"$.author.id":   "ID",
+			"$.author.name": "Name",
+			"$.editor":      "Editor",
+			"$.tags":        "Tags",
+			"$.tags.*.v":    "Value",
+			"$.user_id":     "UID",
+		},
+		Types: map[string]string{
+			"$":        "Root",
+			"$.author": "Person",
+		},
+	}, suggested)
+	assert.Len(t, m.Fields, 3, "original mapping shouldn't be modified")
+}
+
+func TestParserNameMappingStableSuffixes(t *testing.T) {
+	t.Parallel()
+
+	first := `{"p":{"item":{"b":1},"p":1},"q":{"item":{"b":1},"q":1}}`
+	second := `{"a":{"item":{"a":1},"a":1},"c":{"item":{"a":1},"c":1},"p":{"item":{"b":1},"p":1},"q":{"item":{"b":1},"q":1}}`
+
+	for _, easyJSON := range []bool{false, true} {
+		p := NewJSONParser("Doc", OptExtractCommonTypes(true), OptEasyJSON(easyJSON))
+		require.NoError(t, p.FeedBytes([]byte(first)))
+		m := p.SuggestNames(NameMapping{})
+		assert.Equal(t, "Item", m.Types["$.p.item"])
+
+		// Without mapping, new structure sorted first would take "Item" name.
+		p = NewJSONParser("Doc", OptExtractCommonTypes(true), OptEasyJSON(easyJSON))
+		require.NoError(t, p.FeedBytes([]byte(second)))
+		assert.Contains(t, p.String(), "type Item struct {\n\tA int")
+
+		p = NewJSONParser("Doc", OptExtractCommonTypes(true), OptEasyJSON(easyJSON), OptNameMapping(m))
+		require.NoError(t, p.FeedBytes([]byte(second)))
+		out := p.String()
+		assert.Contains(t, out, "type Item struct {\n\tB int")
+		assert.Contains(t, out, "type Item2 struct {\n\tA int")
+		assert.Equal(t, 1, strings.Count(out, "type Item struct"))
+	}
+}
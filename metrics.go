@@ -0,0 +1,87 @@
+package json2go
+
+import "time"
+
+// Categories of warnings, counted by Metrics.
+const (
+	// WarningDuplicateKey is a warning about duplicate key of json object, see OptDuplicateKeys.
+	WarningDuplicateKey = "duplicate_key"
+	// WarningDepthExceeded is a warning about value skipped by FeedValue, see OptMaxDepth.
+	WarningDepthExceeded = "depth_exceeded"
+	// WarningSampled is a warning about sampled array, see OptSampleLimit.
+	WarningSampled = "sampled"
+	// WarningOutlier is a warning about array elements rejected as outliers, see OptOutlierTolerance.
+	WarningOutlier = "outlier"
+	// WarningReservedName is a warning about renamed field or type, clashing with reserved name.
+	WarningReservedName = "reserved_name"
+	// WarningEmptyValue is a warning about key skipped, because it had only empty values, see OptEmptyValues.
+	WarningEmptyValue = "empty_value"
+	// WarningUntaggableKey is a warning about key, which can't be represented in json tag, see OptVerifyTags.
+	WarningUntaggableKey = "untaggable_key"
+	// WarningEncoding is a warning about invalid UTF-8 bytes of input, see OptInvalidUTF8.
+	WarningEncoding = "encoding"
+	// WarningPlugin is a warning about failure of plugin, see OptPlugins.
+	WarningPlugin = "plugin"
+	// WarningSkippedMessage is a warning about invalid message skipped by FeedSource.
+	WarningSkippedMessage = "skipped_message"
+	// WarningSummarized is a warning about object summarized as map or json.RawMessage, see OptMaxGeneratedFields.
+	WarningSummarized = "summarized"
+)
+
+// Metrics receives measurements of parser, e.g. to export them as Prometheus histograms and counters,
+// when json2go runs as a service. It's set with OptMetrics. Parsers sharing metrics call them concurrently.
+type Metrics interface {
+	// ObserveInputSize observes size in bytes of json document consumed as bytes.
+	ObserveInputSize(bytes int)
+	// ObserveConversion observes conversion finished by Generate: time spent consuming inputs and generating
+	// types, and number of nodes of inferred types tree.
+	ObserveConversion(duration time.Duration, nodes int)
+	// IncWarnings counts reported warning of category, like WarningSampled. Repeated warnings are counted once.
+	IncWarnings(category string)
+}
+
+// measureFeed adds time spent consuming input since start.
+func (p *JSONParser) measureFeed(start time.Time) {
+	p.busy += time.Since(start)
+}
+
+// measureConversion observes conversion, with generation started at start.
+func (p *JSONParser) measureConversion(start time.Time) {
+	p.opts.metrics.ObserveConversion(p.busy+time.Since(start), p.rootNode.count())
+}
+
+// count returns number of nodes in subtree.
+func (n *node) count() int {
+	c := 1
+	for _, child := range n.children {
+		c += child.count()
+	}
+	return c
+}
+
+// Stats are statistics of consumed inputs and generated types.
+type Stats struct {
+	// Inputs is a number of consumed json inputs.
+	Inputs int `json:"inputs"`
+	// Types is a number of generated named types.
+	Types int `json:"types"`
+	// Fields is a number of struct fields of generated types, with fields of anonymous structs.
+	Fields int `json:"fields"`
+	// Nodes is a number of nodes of inferred types tree.
+	Nodes int `json:"nodes"`
+	// Warnings is a number of warnings, see Warnings.
+	Warnings int `json:"warnings"`
+}
+
+// Stats returns statistics of consumed inputs and generated types.
+func (p *JSONParser) Stats() Stats {
+	s := Stats{Inputs: p.inputs, Nodes: p.rootNode.count(), Warnings: len(p.warnings)}
+	for _, e := range p.Trace() {
+		if e.Field == "" {
+			s.Types++
+		} else {
+			s.Fields++
+		}
+	}
+	return s
+}
@@ -0,0 +1,67 @@
+package json2go
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testMetrics records measurements.
+type testMetrics struct {
+	inputSizes []int
+	durations  []time.Duration
+	nodes      []int
+	warnings   map[string]int
+}
+
+func (m *testMetrics) ObserveInputSize(bytes int) {
+	m.inputSizes = append(m.inputSizes, bytes)
+}
+
+func (m *testMetrics) ObserveConversion(duration time.Duration, nodes int) {
+	m.durations = append(m.durations, duration)
+	m.nodes = append(m.nodes, nodes)
+}
+
+func (m *testMetrics) IncWarnings(category string) {
+	if m.warnings == nil {
+		m.warnings = make(map[string]int)
+	}
+	m.warnings[category]++
+}
+
+func TestMetrics(t *testing.T) {
+	t.Parallel()
+
+	m := &testMetrics{}
+	p := NewJSONParser(baseTypeName, OptNameMapping(NameMapping{Types: map[string]string{"$": "error"}}), OptMetrics(m), OptSampleLimit(1), OptDuplicateKeys(DuplicateKeysLastWins), OptMaxDepth(2))
+	require.NoError(t, p.FeedReader(strings.NewReader(`{"id":1,"a":[1,2]} {"a":[3,4,5],"a":[6]}`)))
+	p.FeedValue(map[string]interface{}{"b": map[string]interface{}{"c": map[string]interface{}{}}})
+	_, err := p.Generate()
+	require.NoError(t, err)
+
+	assert.Equal(t, []int{18, 21}, m.inputSizes)
+	require.Len(t, m.durations, 1)
+	assert.True(t, m.durations[0] > 0)
+	assert.Equal(t, []int{3}, m.nodes, "root, id and a")
+	assert.Equal(t, map[string]int{
+		WarningSampled:       1,
+		WarningDuplicateKey:  1,
+		WarningDepthExceeded: 1,
+		WarningReservedName:  1,
+	}, m.warnings)
+}
+
+func TestStats(t *testing.T) {
+	t.Parallel()
+
+	p := NewJSONParser(baseTypeName, OptSampleLimit(1))
+	require.NoError(t, p.FeedBytes([]byte(`{"id":1,"user":{"name":"a","address":{"city":"b"}},"tags":["x","y"]}`)))
+	require.NoError(t, p.FeedBytes([]byte(`{"id":2}`)))
+
+	assert.Equal(t, Stats{Inputs: 2, Types: 1, Fields: 6, Nodes: 7, Warnings: 1}, p.Stats(),
+		"id, user, user.address, user.address.city, user.name and tags fields")
+}
@@ -0,0 +1,74 @@
+package json2go
+
+// MinimizeSamples returns indices of a small subset of samples, for which generated types are the same as for all samples.
+// It's useful for keeping representative fixtures instead of many redundant captures.
+// Subset is minimal in the sense that none of its samples can be removed, but a smaller subset may exist.
+// Samples are consumed as bytes, ErrInvalidJSON is returned for invalid samples.
+func MinimizeSamples(samples [][]byte, opts ...JSONParserOpt) ([]int, error) {
+	generate := func(indices []int) (string, error) {
+		p := NewJSONParser(defaultRootName, opts...)
+		for _, i := range indices {
+			if err := p.FeedBytes(samples[i]); err != nil {
+				return "", err
+			}
+		}
+		return p.Generate()
+	}
+
+	all := make([]int, len(samples))
+	for i := range samples {
+		all[i] = i
+	}
+	expected, err := generate(all)
+	if err != nil {
+		return nil, err
+	}
+
+	// Select samples changing types generated for selected ones, until they generate expected types.
+	selected := make(map[int]bool)
+	for {
+		p := NewJSONParser(defaultRootName, opts...)
+		for _, i := range all {
+			if selected[i] {
+				_ = p.FeedBytes(samples[i])
+			}
+		}
+		out, _ := p.Generate()
+		if out == expected {
+			break
+		}
+
+		added := false
+		for _, i := range all {
+			if selected[i] {
+				continue
+			}
+			_ = p.FeedBytes(samples[i])
+			if o, _ := p.Generate(); o != out {
+				selected[i] = true
+				added = true
+				out = o
+			}
+		}
+		if !added {
+			// Generated types depend on order of samples, so all are needed.
+			return all, nil
+		}
+	}
+
+	// Remove samples not needed by others.
+	var result []int
+	for _, i := range all {
+		if selected[i] {
+			result = append(result, i)
+		}
+	}
+	for j := len(result) - 1; j >= 0; j-- {
+		candidate := append(append([]int(nil), result[:j]...), result[j+1:]...)
+		if out, err := generate(candidate); err == nil && out == expected {
+			result = candidate
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,58 @@
+package json2go
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMinimizeSamples(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		samples  []string
+		expected []int
+	}{
+		{
+			name:     "redundant samples",
+			samples:  []string{`{"id":1}`, `{"id":2}`, `{"id":3,"name":"x"}`, `{"id":4,"name":"y"}`},
+			expected: []int{0, 2},
+		},
+		{
+			name:     "sample covered by later ones",
+			samples:  []string{`{"id":1}`, `{"id":1.5}`, `{"id":2.5,"x":true}`},
+			expected: []int{0, 2},
+		},
+		{
+			name:     "combination needed",
+			samples:  []string{`{"a":1}`, `{"a":1,"b":1}`, `{"b":1}`, `{"a":1,"b":1}`},
+			expected: []int{0, 2},
+		},
+		{
+			name:     "single sample",
+			samples:  []string{`[1,2]`},
+			expected: []int{0},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var samples [][]byte
+			for _, s := range tc.samples {
+				samples = append(samples, []byte(s))
+			}
+			result, err := MinimizeSamples(samples, OptStringPointersWhenKeyMissing(true))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+
+	_, err := MinimizeSamples([][]byte{[]byte(`{`)})
+	assert.True(t, errors.Is(err, ErrInvalidJSON))
+}
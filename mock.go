@@ -0,0 +1,80 @@
+package json2go
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strconv"
+)
+
+// MockServer returns source of go file of package pkg, with http handler serving random json documents fitting
+// generated root type, so code depending on real service can be developed against it. Handler serves one of given number
+// of documents, generated with Generate from consecutive seeds. In package main, file is a standalone server.
+// File doesn't depend on json2go or generated types.
+func (p *JSONParser) MockServer(pkg string, responses int, seed int64) (string, error) {
+	if responses <= 0 {
+		return "", fmt.Errorf("invalid number of mock responses: %d", responses)
+	}
+	schema := p.Schema()
+
+	var docs bytes.Buffer
+	for i := 0; i < responses; i++ {
+		doc, err := Generate(schema, seed+int64(i))
+		if err != nil {
+			return "", err
+		}
+		lit := "`" + string(doc) + "`"
+		if !strconv.CanBackquote(string(doc)) {
+			lit = strconv.Quote(string(doc))
+		}
+		docs.WriteString("\t" + lit + ",\n")
+	}
+
+	var imports string
+	if pkg == "main" {
+		imports = "\n\"flag\"\n\"log\""
+	}
+	var src bytes.Buffer
+	fmt.Fprintf(&src, mockServerSrc, pkg, schema.Root, docs.String(), imports)
+	if pkg == "main" {
+		fmt.Fprintf(&src, mockServerMainSrc, schema.Root)
+	}
+	out, err := format.Source(src.Bytes())
+	if err != nil {
+		return "", fmt.Errorf("%w: formatting mock server: %v", ErrInternal, err)
+	}
+	return string(out), nil
+}
+
+const mockServerSrc = `// Code generated by json2go. DO NOT EDIT.
+
+package %[1]s
+
+import (%[4]s
+	"math/rand"
+	"net/http"
+)
+
+// %[2]sResponses are random json documents fitting %[2]s type.
+var %[2]sResponses = []string{
+%[3]s}
+
+// %[2]sHandler serves random document from %[2]sResponses.
+func %[2]sHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(%[2]sResponses[rand.Intn(len(%[2]sResponses))]))
+	})
+}
+`
+
+const mockServerMainSrc = `
+func main() {
+	addr := flag.String("addr", "localhost:8080", "Address of mock server")
+	flag.Parse()
+
+	http.Handle("/", %[1]sHandler())
+	log.Printf("serving %[1]s on %%s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, nil))
+}
+`
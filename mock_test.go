@@ -0,0 +1,41 @@
+package json2go
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserMockServer(t *testing.T) {
+	t.Parallel()
+
+	p := NewJSONParser("User")
+	require.NoError(t, p.FeedBytes([]byte(`{"id":1,"name":"a","tags":["x"]}`)))
+
+	for _, pkg := range []string{"mock", "main"} {
+		src, err := p.MockServer(pkg, 3, 7)
+		require.NoError(t, err)
+		assert.Contains(t, src, "func UserHandler() http.Handler {")
+		for seed := int64(7); seed < 10; seed++ {
+			doc, err := Generate(p.Schema(), seed)
+			require.NoError(t, err)
+			assert.Contains(t, src, "`"+string(doc)+"`")
+		}
+
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, "mock.go", src, 0)
+		require.NoError(t, err)
+		conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+		_, err = conf.Check(pkg, fset, []*ast.File{file}, nil)
+		assert.NoError(t, err, src)
+	}
+
+	_, err := p.MockServer("mock", 0, 1)
+	assert.Error(t, err)
+}
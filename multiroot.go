@@ -0,0 +1,114 @@
+package json2go
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// ConvertAll returns go representation of multiple root types, one for each named json sample,
+// like responses of different API endpoints. Types common for samples are declared once.
+// Names are used as type names, paths of values start with name, like "$.User.id".
+func ConvertAll(samples map[string][]byte, opts ...JSONParserOpt) (string, error) {
+	if len(samples) == 0 {
+		return "", nil
+	}
+	p := NewJSONParser("", opts...)
+	p.multiRoot = true
+
+	names := make([]string, 0, len(samples))
+	for name := range samples {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// Samples are consumed as attributes of single object, so common types are found in all of them.
+	var buf bytes.Buffer
+	buf.WriteString("{")
+	for i, name := range names {
+		var v interface{}
+		if err := json.Unmarshal(samples[name], &v); err != nil {
+			return "", fmt.Errorf("%s: %w", name, invalidJSONError{err: err})
+		}
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		key, _ := json.Marshal(name)
+		buf.Write(key)
+		buf.WriteString(":")
+		buf.Write(samples[name])
+	}
+	buf.WriteString("}")
+
+	if err := p.FeedBytes(buf.Bytes()); err != nil {
+		return "", err
+	}
+	return p.Generate()
+}
+
+// generateRoots returns go representation of multiple root types, like ConvertAll, of trees grown by other parsers.
+// Trees are copied as attributes of root object of new parser, named by names.
+func generateRoots(names []string, roots []*node, opts ...JSONParserOpt) (string, error) {
+	if len(roots) == 0 {
+		return "", nil
+	}
+	p := NewJSONParser("", opts...)
+	p.multiRoot = true
+	p.inputs = 1
+	p.rootNode.t = nodeTypeObject
+	p.rootNode.objects = 1
+	p.rootNode.weightedObjects = 1
+	for i, root := range roots {
+		c := root.clone()
+		c.key, c.name = names[i], names[i]
+		c.root, c.document = false, false
+		c.occurrences, c.weightedOccurrences = 1, 1
+		c.rebase(childPath(rootPath, names[i]))
+		p.rootNode.children = append(p.rootNode.children, c)
+	}
+	return p.Generate()
+}
+
+// rebase sets paths of tree, starting with path of its root.
+func (n *node) rebase(path string) {
+	n.path = path
+	for _, c := range n.children {
+		c.rebase(childPath(path, c.key))
+	}
+	for _, pn := range n.tuple {
+		pn.rebase(path)
+	}
+	if n.encoded != nil {
+		n.encoded.rebase(path)
+	}
+}
+
+// splitRoots returns attributes of multi root object as root nodes, followed by other extracted types.
+// Attributes that are extracted types with the same name are replaced by these types.
+func splitRoots(root *node, extracted []*node) []*node {
+	byName := make(map[string]*node, len(extracted))
+	for _, n := range extracted {
+		byName[n.name] = n
+	}
+
+	var roots []*node
+	for _, c := range root.children {
+		if n, ok := byName[c.externalTypeID]; ok && c.t == nodeTypeExtracted && c.externalTypeID == c.name {
+			delete(byName, n.name)
+			roots = append(roots, n)
+			continue
+		}
+		c.root = true
+		c.document = true
+		c.required = true
+		c.nullable = false
+		roots = append(roots, c)
+	}
+	for _, n := range extracted {
+		if _, ok := byName[n.name]; ok {
+			roots = append(roots, n)
+		}
+	}
+	return roots
+}
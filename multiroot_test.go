@@ -0,0 +1,46 @@
+package json2go
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertAll(t *testing.T) {
+	t.Parallel()
+
+	out, err := ConvertAll(map[string][]byte{
+		"User":   []byte(`{"id":1,"address":{"city":"a","street":"b"}}`),
+		"Order":  []byte(`{"id":2,"shipping":{"city":"c","street":"d"},"items":[{"sku":"x"}]}`),
+		"Status": []byte(`"ok"`),
+		"Users":  []byte(`[{"id":1,"address":{"city":"a","street":"b"}}]`),
+	}, OptExtractCommonTypes(true))
+	require.NoError(t, err)
+	assert.Equal(t, `type Order struct {
+	ID    int `+"`json:\"id\"`"+`
+	Items []struct {
+		Sku string `+"`json:\"sku\"`"+`
+	} `+"`json:\"items\"`"+`
+	Shipping CityStreet `+"`json:\"shipping\"`"+`
+}
+type CityStreet struct {
+	City   string `+"`json:\"city\"`"+`
+	Street string `+"`json:\"street\"`"+`
+}
+type Status string
+type User struct {
+	Address CityStreet `+"`json:\"address\"`"+`
+	ID      int        `+"`json:\"id\"`"+`
+}
+type Users []User`, out)
+
+	_, err = ConvertAll(map[string][]byte{"A": []byte(`{}`), "B": []byte(`{`)})
+	assert.True(t, errors.Is(err, ErrInvalidJSON))
+	assert.Contains(t, err.Error(), "B: ")
+
+	out, err = ConvertAll(nil)
+	require.NoError(t, err)
+	assert.Empty(t, out)
+}
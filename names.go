@@ -2,6 +2,7 @@ package json2go
 
 import (
 	"bytes"
+	"go/token"
 	"regexp"
 	"strconv"
 	"strings"
@@ -25,13 +26,13 @@ func attrName(fieldName string) string {
 	}
 
 	// words := strings.Split(fieldName, "_")
-	for i, word := range words {
+	for _, word := range words {
 		if u := strings.ToUpper(word); commonInitialisms[u] {
 			b.WriteString(u)
 			continue
 		}
 
-		word = removeInvalidChars(word, i == 0) // on 0 remove first digits
+		word = removeInvalidChars(word, b.Len() == 0) // name can't start with digits
 		if len(word) == 0 {
 			continue
 		}
@@ -46,7 +47,7 @@ func attrName(fieldName string) string {
 	if b.Len() == 0 { // check if this is number
 		if _, err := strconv.Atoi(fieldName); err == nil {
 			b.WriteString("Key")
-			b.WriteString(fieldName)
+			b.WriteString(removeInvalidChars(fieldName, false)) // remove sign
 		}
 	}
 
@@ -133,6 +134,42 @@ func nextName(name string) string {
 	return re.ReplaceAllString(name, strconv.Itoa(num+1))
 }
 
+// snakeCaseName converts go name to snake case, e.g. "XRequestID" to "x_request_id".
+func snakeCaseName(name string) string {
+	runes := []rune(name)
+
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+
+	return b.String()
+}
+
+// lowerCamelCaseName converts go name to lower camel case, e.g. "IDValue" to "idValue".
+func lowerCamelCaseName(name string) string {
+	runes := []rune(name)
+	for i := range runes {
+		if !unicode.IsUpper(runes[i]) {
+			break
+		}
+		// Last upper letter of initialism followed by lower letter starts next word.
+		if i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+			break
+		}
+		runes[i] = unicode.ToLower(runes[i])
+	}
+
+	return string(runes)
+}
+
 // commonInitialisms is a set of common initialisms.
 //
 // source: https://github.com/golang/lint/blob/master/lint.go
@@ -176,3 +213,152 @@ var commonInitialisms = map[string]bool{
 	"XSRF":  true,
 	"XSS":   true,
 }
+
+// singularName converts name of array attribute to a name of its element, e.g. "OrderItems" to "OrderItem".
+// Only last word of name is changed. Overrides map lowercase plural words to their singular forms.
+func singularName(name string, overrides map[string]string) string {
+	runes := []rune(name)
+	if len(runes) < 2 {
+		return name
+	}
+
+	// Plural initialism, like "URLs".
+	if last := len(runes) - 1; runes[last] == 's' && unicode.IsUpper(runes[last-1]) {
+		return string(runes[:last])
+	}
+
+	start := -1
+	for i := len(runes) - 1; i >= 0; i-- {
+		if unicode.IsUpper(runes[i]) {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		start = 0
+	}
+	word := string(runes[start:])
+	if commonInitialisms[strings.ToUpper(word)] {
+		return name
+	}
+
+	singular := singularWord(strings.ToLower(word), overrides)
+	if singular == "" || singular == strings.ToLower(word) {
+		return name
+	}
+	if unicode.IsUpper(runes[start]) {
+		singular = strings.ToUpper(singular[:1]) + singular[1:]
+	}
+
+	return string(runes[:start]) + singular
+}
+
+// singularWord returns singular form of lowercase english noun.
+func singularWord(word string, overrides map[string]string) string {
+	if s, ok := overrides[word]; ok {
+		return s
+	}
+	if s, ok := irregularSingulars[word]; ok {
+		return s
+	}
+
+	switch {
+	case len(word) <= 2:
+		return word
+	case strings.HasSuffix(word, "ies") && len(word) > 4:
+		return strings.TrimSuffix(word, "ies") + "y"
+	case strings.HasSuffix(word, "sses"),
+		strings.HasSuffix(word, "uses"),
+		strings.HasSuffix(word, "xes"),
+		strings.HasSuffix(word, "zes"),
+		strings.HasSuffix(word, "ches"),
+		strings.HasSuffix(word, "shes"):
+		return strings.TrimSuffix(word, "es")
+	case strings.HasSuffix(word, "ss"),
+		strings.HasSuffix(word, "us"),
+		strings.HasSuffix(word, "is"):
+		return word
+	case strings.HasSuffix(word, "s"):
+		return strings.TrimSuffix(word, "s")
+	}
+
+	return word
+}
+
+// irregularSingulars are singular forms of irregular and uncountable english nouns.
+var irregularSingulars = map[string]string{
+	"analyses":    "analysis",
+	"appendices":  "appendix",
+	"axes":        "axis",
+	"calves":      "calf",
+	"children":    "child",
+	"cookies":     "cookie",
+	"crises":      "crisis",
+	"criteria":    "criterion",
+	"data":        "data",
+	"diagnoses":   "diagnosis",
+	"feet":        "foot",
+	"geese":       "goose",
+	"halves":      "half",
+	"hypotheses":  "hypothesis",
+	"indices":     "index",
+	"information": "information",
+	"knives":      "knife",
+	"leaves":      "leaf",
+	"lives":       "life",
+	"loaves":      "loaf",
+	"matrices":    "matrix",
+	"media":       "media",
+	"men":         "man",
+	"metadata":    "metadata",
+	"mice":        "mouse",
+	"movies":      "movie",
+	"news":        "news",
+	"oxen":        "ox",
+	"people":      "person",
+	"phenomena":   "phenomenon",
+	"quizzes":     "quiz",
+	"selves":      "self",
+	"series":      "series",
+	"shelves":     "shelf",
+	"species":     "species",
+	"synopses":    "synopsis",
+	"teeth":       "tooth",
+	"theses":      "thesis",
+	"thieves":     "thief",
+	"vertices":    "vertex",
+	"wives":       "wife",
+	"wolves":      "wolf",
+	"women":       "woman",
+}
+
+// Suffixes added to generated names clashing with go keywords, predeclared identifiers or imported packages.
+const (
+	reservedTypeNameSuffix  = "Type"
+	reservedFieldNameSuffix = "Field"
+)
+
+// predeclaredNames are go predeclared identifiers.
+var predeclaredNames = map[string]bool{
+	"any": true, "bool": true, "byte": true, "comparable": true, "complex64": true, "complex128": true,
+	"error": true, "float32": true, "float64": true, "int": true, "int8": true, "int16": true, "int32": true,
+	"int64": true, "rune": true, "string": true, "uint": true, "uint8": true, "uint16": true, "uint32": true,
+	"uint64": true, "uintptr": true, "true": true, "false": true, "iota": true, "nil": true, "append": true,
+	"cap": true, "clear": true, "close": true, "complex": true, "copy": true, "delete": true, "imag": true,
+	"len": true, "make": true, "max": true, "min": true, "new": true, "panic": true, "print": true,
+	"println": true, "real": true, "recover": true,
+}
+
+// isReservedName checks if name clashes with go keyword, predeclared identifier or one of packages names.
+func isReservedName(name string, packages []string) bool {
+	if token.IsKeyword(name) || predeclaredNames[name] {
+		return true
+	}
+	for _, p := range packages {
+		if name == p {
+			return true
+		}
+	}
+
+	return false
+}
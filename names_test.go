@@ -74,6 +74,16 @@ func TestNames(t *testing.T) {
 			fieldName:    "123key",
 			expectedName: "Key",
 		},
+		{
+			name:         "starting with separator and digits",
+			fieldName:    "_123key",
+			expectedName: "Key",
+		},
+		{
+			name:         "signed number",
+			fieldName:    "+10",
+			expectedName: "Key10",
+		},
 		{
 			name:         "name with digits",
 			fieldName:    "key_666",
@@ -202,3 +212,75 @@ func TestNextName(t *testing.T) {
 		})
 	}
 }
+
+func TestSingularName(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name      string
+		input     string
+		overrides map[string]string
+		expected  string
+	}{
+		{name: "regular", input: "Orders", expected: "Order"},
+		{name: "last word", input: "OrderItems", expected: "OrderItem"},
+		{name: "ies", input: "Categories", expected: "Category"},
+		{name: "es", input: "Addresses", expected: "Address"},
+		{name: "ches", input: "Matches", expected: "Match"},
+		{name: "uses", input: "Statuses", expected: "Status"},
+		{name: "already singular", input: "Status", expected: "Status"},
+		{name: "irregular", input: "People", expected: "Person"},
+		{name: "irregular last word", input: "ActiveChildren", expected: "ActiveChild"},
+		{name: "uncountable", input: "Data", expected: "Data"},
+		{name: "plural initialism", input: "URLs", expected: "URL"},
+		{name: "initialism", input: "CSS", expected: "CSS"},
+		{name: "lowercase", input: "items", expected: "item"},
+		{name: "override", input: "Octopi", overrides: map[string]string{"octopi": "octopus"}, expected: "Octopus"},
+		{name: "empty", input: "", expected: ""},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, singularName(tc.input, tc.overrides))
+		})
+	}
+}
+
+func TestIsReservedName(t *testing.T) {
+	t.Parallel()
+
+	packages := []string{"json", "time"}
+	for _, name := range []string{"type", "func", "string", "error", "nil", "len", "json", "time"} {
+		assert.True(t, isReservedName(name, packages), name)
+	}
+	for _, name := range []string{"Type", "String", "Document", "strings", "value"} {
+		assert.False(t, isReservedName(name, packages), name)
+	}
+}
+
+func TestCaseNames(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		input string
+		snake string
+		camel string
+	}{
+		{input: "Name", snake: "name", camel: "name"},
+		{input: "UserID", snake: "user_id", camel: "userID"},
+		{input: "XRequestID", snake: "x_request_id", camel: "xRequestID"},
+		{input: "IDValue", snake: "id_value", camel: "idValue"},
+		{input: "URL", snake: "url", camel: "url"},
+		{input: "Key1Value", snake: "key1_value", camel: "key1Value"},
+		{input: "", snake: "", camel: ""},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.input, func(t *testing.T) {
+			assert.Equal(t, tc.snake, snakeCaseName(tc.input))
+			assert.Equal(t, tc.camel, lowerCamelCaseName(tc.input))
+		})
+	}
+}
@@ -3,47 +3,188 @@ package json2go
 import (
 	"bytes"
 	"fmt"
+	"math"
 	"sort"
+	"strconv"
+	"strings"
 )
 
 const (
 	baseTypeName           = "Document"
 	structIDlevelSeparator = "|"
+	rootPath               = "$"
+
+	// maxTupleLength is maximum length of array considered as a tuple.
+	maxTupleLength = 16
+	// mapValuePathKey is a key used in paths of map values.
+	mapValuePathKey = "*"
+	// emptyKeyName is a name of attribute with key that can't be converted to go identifier.
+	emptyKeyName = "Field"
+)
+
+// Kinds of json values, used to measure how unstable node's input is.
+const (
+	kindBool = 1 << iota
+	kindNumber
+	kindString
+	kindObject
+	kindArray
 )
 
 type node struct {
-	root           bool
-	nullable       bool
-	required       bool
-	key            string
-	name           string
-	t              nodeType
-	externalTypeID string
-	children       []*node
-	arrayLevel     int
-	arrayWithNulls bool
+	root                bool
+	document            bool // true for type of whole parsed document, false for extracted types
+	nullable            bool
+	required            bool
+	key                 string
+	name                string
+	path                string
+	t                   nodeType
+	externalTypeID      string
+	children            []*node
+	arrayLevel          int
+	arrayWithNulls      bool
+	seenKinds           int
+	seenTypes           int      // scalar types of values, see scalarTypes
+	needsFloat64        bool     // true if any of numeric values can't be represented as float32 without precision loss
+	hasText             bool     // true if any of string values is long or has whitespace, see isKeywordString
+	boolStrings         int      // kinds of bool strings seen, see boolStringKinds
+	formats             int      // formats common for all values
+	formatsWanted       int      // formats detected in values, used by enabled options, see options.formatsWanted
+	stringValues        int      // number of string values
+	enumValues          []string // distinct string values, if they may be an enum, see isEnum
+	enumInvalid         bool     // true if string values can't be an enum
+	enums               bool     // true if string values are tracked as enums, see OptStringFormats
+	mapKeyType          string
+	timeFormat          string     // time format forced with OptTimeAt
+	encoded             *node      // node of values decoded from strings, see formatsEncoded
+	detectors           []Detector // detectors of plugins, see OptPlugins
+	matching            []Detector // detectors matching all string values
+	tuple               []*node    // nodes for each position of innermost arrays
+	tupleInvalid        bool       // true if innermost arrays can't be represented as a tuple
+	keyOrder            []string   // children keys in order of their first appearance
+	rawKey              bool       // true if key can't be represented in json tag, see markUntaggableKeys
+	pointer             *bool      // forced pointer or value type, nil if inferred
+	objects             int        // number of parsed objects
+	occurrences         int        // number of parsed objects with node's key
+	weightedObjects     float64    // total weight of inputs of parsed objects, see FeedWeighted
+	weightedOccurrences float64    // total weight of inputs of parsed objects with node's key
+	commented           []*node    // children with low confidence, emitted as comments
+	lowConfidence       string     // reason of commenting out node's field
+	logger              Logger
+	budget              *nodeBudget    // limit of nodes shared by tree, see OptMaxNodes
+	growCtx             *growContext   // context of input growing tree, shared by its nodes
+	cache               *subtreeCache  // values grown by nodes of tree, see OptInputCache
+	geoCoordinates      bool           // true for coordinates of GeoJSON geometries of different types
+	jsonAPI             jsonAPIRole    // role in JSON:API document, see OptJSONAPI
+	jsonAPIRelations    []string       // keys of relationships of JSON:API resource
+	halLink             bool           // true for links of HAL resources, referring to shared type
+	halEmbedded         bool           // true for embedded HAL resources
+	cloudEvent          bool           // true for CloudEvents envelopes, see OptCloudEvents
+	cloudEventAttribute bool           // true for context attributes of CloudEvents defined by specification
+	cloudEventData      bool           // true for data payload of CloudEvents
+	logRecord           bool           // true for root of structured log records, see OptStructuredLogs
+	chainDepth          int            // number of nested objects flattened to value of field, see OptFlattenChains
+	inputs              *int           // number of inputs grown by tree, shared by its nodes, see Trace
+	weight              *float64       // weight of input grown by tree, shared by its nodes, see FeedWeighted
+	input               int            // number of input, in which node got its first value, see Trace
+	example             interface{}    // first scalar value, see Trace
+	examples            *exampleBudget // limits of examples shared by tree, see OptExampleLimits
 }
 
 func newNode(key string) *node {
 	return &node{
-		key:      key,
-		name:     attrName(key),
-		t:        nodeTypeInit,
-		nullable: false,
-		required: true,
+		key:           key,
+		name:          attrName(key),
+		t:             nodeTypeInit,
+		nullable:      false,
+		required:      true,
+		formats:       formatsAll,
+		formatsWanted: formatsAll,
+		enums:         true,
 	}
 }
 
 func (n *node) grow(input interface{}) {
+	if values, ok := input.(duplicateValues); ok {
+		for _, v := range values {
+			n.grow(v)
+		}
+		return
+	}
+	n.growCtx.check()
+	if n.cache != nil {
+		state := n.state()
+		switch input.(type) {
+		case map[string]interface{}, []interface{}:
+			sum := hashValue(input)
+			if n.cache.has(n, sum) {
+				n.replay(input)
+				return
+			}
+			defer n.cache.add(n, sum)
+		}
+		defer func() {
+			if n.state() != state {
+				n.cache.changes++
+			}
+		}()
+	}
+	if n.input == 0 && n.inputs != nil {
+		n.input = *n.inputs
+	}
 	if input == nil {
 		n.nullable = true
 		return
 	}
+	if n.example == nil {
+		n.example = n.examples.example(input)
+	}
+
+	n.seenKinds |= valueKind(input)
+	n.seenTypes |= scalarTypes(input)
+	if !n.needsFloat64 && !fitsFloat32(input) {
+		n.needsFloat64 = true
+	}
+	if !n.hasText && isTextValue(input) {
+		n.hasText = true
+	}
+	if n.formats != 0 {
+		n.formats &= valueFormats(input, n.formats)
+	}
+	if s, ok := input.(string); ok && n.formats&formatBoolString != 0 {
+		n.boolStrings |= boolStringKinds(s)
+	}
+	n.growEnum(input)
+	if len(n.matching) > 0 {
+		n.matching = matchingDetectors(n.matching, input)
+	}
+	if n.formats&formatsEncoded != 0 {
+		n.growEncoded(input)
+	} else {
+		n.encoded = nil
+	}
+
+	// Tuple structure is tracked also for interface nodes, because arrays with mixed types are always interfaces.
+	if ar, ok := input.([]interface{}); ok {
+		n.growTuple(ar)
+	} else {
+		n.tupleInvalid = true
+		n.tuple = nil
+	}
 
 	if n.t.id() == nodeTypeInterface.id() {
 		return //nothing to do now
 	}
 
+	if n.logger != nil && n.t != nodeTypeInit {
+		defer func(t nodeType, arrayLevel int) {
+			if n.t != t || n.arrayLevel != arrayLevel {
+				n.logf("type changed from %s to %s", typeDesc(t, arrayLevel), typeDesc(n.t, n.arrayLevel))
+			}
+		}(n.t, n.arrayLevel)
+	}
+
 	n.growChildrenFromData(input)
 
 	switch typedInput := input.(type) {
@@ -55,33 +196,109 @@ func (n *node) grow(input interface{}) {
 		}
 
 		localLevel, localType, nullable := arrayStructure(typedInput, n.t)
+		if localLevel < 0 {
+			// Arrays with inconsistent depth can be represented only as interface.
+			n.t = nodeTypeInterface
+			n.arrayLevel = 0
+			n.children = nil
+			break
+		}
 		if n.t == nodeTypeInit {
 			n.t = localType
 			n.arrayLevel = localLevel
-		} else if n.arrayLevel != localLevel || n.t != localType {
+		} else if n.arrayLevel != localLevel || !localType.expands(n.t) {
 			n.t = nodeTypeInterface
 			n.arrayLevel = 0
+		} else {
+			// Type may be widened, e.g. from int to float.
+			n.t = localType
 		}
-		n.arrayWithNulls = nullable
+		n.arrayWithNulls = n.arrayWithNulls || nullable
 	default:
 		n.t = growType(n.t, typedInput)
 		n.arrayLevel = 0
 	}
 }
 
+// growTuple grows tuple structure from array. Tuple is a fixed length array with simple values of different types.
+// For nested arrays, innermost arrays are used.
+func (n *node) growTuple(in []interface{}) {
+	if n.tupleInvalid {
+		return
+	}
+
+	nested := false
+	for _, el := range in {
+		switch typedEl := el.(type) {
+		case []interface{}:
+			nested = true
+			n.growTuple(typedEl)
+		case map[string]interface{}:
+			// Only simple values are supported in tuples.
+			n.tupleInvalid = true
+			n.tuple = nil
+			return
+		}
+	}
+	if nested || len(in) == 0 {
+		return
+	}
+
+	if len(in) > maxTupleLength || (n.tuple != nil && len(n.tuple) != len(in)) {
+		n.tupleInvalid = true
+		n.tuple = nil
+		return
+	}
+
+	if n.tuple == nil {
+		for range in {
+			pn := newNode("")
+			pn.path = n.path
+			pn.logger = n.logger
+			pn.budget = n.budget
+			pn.growCtx = n.growCtx
+			pn.cache = n.cache
+			pn.inputs = n.inputs
+			pn.weight = n.weight
+			pn.examples = n.examples
+			pn.formats, pn.formatsWanted, pn.enums = n.formatsWanted, n.formatsWanted, n.enums
+			pn.detectors, pn.matching = n.detectors, n.detectors
+			n.tuple = append(n.tuple, pn)
+		}
+	}
+	for i, v := range in {
+		n.tuple[i].grow(v)
+	}
+}
+
+// isTuple returns true if node is an array that can be represented as a tuple.
+func (n *node) isTuple() bool {
+	return n.arrayLevel > 0 && !n.tupleInvalid && len(n.tuple) > 1
+}
+
 func (n *node) getOrCreateChild(key string) (*node, bool) {
 	if child := n.getChild(key); child != nil {
 		return child, false
 	}
 
-	childrenNames := make(map[string]bool)
-	for _, c := range n.children {
-		childrenNames[c.name] = true
-	}
-
+	n.budget.spend()
 	child := newNode(key)
+	child.path = childPath(n.path, key)
+	child.logger = n.logger
+	child.budget = n.budget
+	child.growCtx = n.growCtx
+	child.cache = n.cache
+	child.inputs = n.inputs
+	child.weight = n.weight
+	child.examples = n.examples
+	child.formats, child.formatsWanted, child.enums = n.formatsWanted, n.formatsWanted, n.enums
+	child.detectors, child.matching = n.detectors, n.detectors
+	if child.name == "" {
+		// Key has no characters valid in go identifier.
+		child.name = emptyKeyName
+	}
 
-	for childrenNames[child.name] {
+	for n.hasChildNamed(child.name) {
 		child.name = nextName(child.name)
 	}
 
@@ -89,6 +306,27 @@ func (n *node) getOrCreateChild(key string) (*node, bool) {
 	return child, true
 }
 
+func (n *node) hasChildNamed(name string) bool {
+	for _, c := range n.children {
+		if c.name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasRawKeys checks if children keys can't be represented in json tags and are decoded by json methods of n.
+func (n *node) hasRawKeys() bool {
+	for _, c := range n.children {
+		if c.rawKey {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (n *node) getChild(key string) *node {
 	for _, child := range n.children {
 		if child.key == key {
@@ -106,6 +344,11 @@ func (n *node) growChildrenFromData(in interface{}) {
 
 	if ar, ok := in.([]interface{}); ok {
 		for i := range ar {
+			if ar[i] == nil {
+				// Null elements don't change structure of other elements.
+				continue
+			}
+			n.growCtx.check()
 			n.growChildrenFromData(ar[i])
 		}
 		return
@@ -118,18 +361,32 @@ func (n *node) growChildrenFromData(in interface{}) {
 	}
 
 	alreadyHasChildren := (n.children != nil)
-	usedKeys := make(map[string]bool)
-	for k, v := range obj {
-		child, created := n.getOrCreateChild(k)
-		if created && alreadyHasChildren {
+	n.objects++
+	n.weightedObjects += n.inputWeight()
+	// New children are created in order of keys, so names of keys with the same field names don't depend
+	// on order of map iteration.
+	var newKeys []string
+	for k := range obj {
+		if n.getChild(k) == nil {
+			newKeys = append(newKeys, k)
+		}
+	}
+	sort.Strings(newKeys)
+	for _, k := range newKeys {
+		child, _ := n.getOrCreateChild(k)
+		if alreadyHasChildren {
 			child.required = false
 		}
+	}
+	for k, v := range obj {
+		child := n.getChild(k)
+		child.occurrences++
+		child.weightedOccurrences += n.inputWeight()
 		child.grow(v)
-		usedKeys[k] = true
 	}
 
 	for _, child := range n.children {
-		if !usedKeys[child.key] {
+		if _, ok := obj[child.key]; !ok {
 			child.required = false
 		}
 	}
@@ -145,6 +402,15 @@ func (n *node) sort() {
 	}
 }
 
+// kindsCount returns number of distinct json value kinds seen by this node.
+func (n *node) kindsCount() int {
+	count := 0
+	for k := n.seenKinds; k > 0; k &= k - 1 {
+		count++
+	}
+	return count
+}
+
 func (n *node) compare(n2 *node) bool {
 	if n.key != n2.key {
 		return false
@@ -212,9 +478,158 @@ func (n *node) clone() *node {
 		children = append(children, c.clone())
 	}
 	n2.children = children
+
+	var tuple []*node
+	for _, pn := range n.tuple {
+		tuple = append(tuple, pn.clone())
+	}
+	n2.tuple = tuple
+	n2.keyOrder = append([]string(nil), n.keyOrder...)
+	if n.encoded != nil {
+		n2.encoded = n.encoded.clone()
+	}
+	// Copies aren't grown by inputs, so they don't spend budget or use cache.
+	n2.budget = nil
+	n2.growCtx = nil
+	n2.cache = nil
+
 	return &n2
 }
 
+// valueKind returns kind of json value. For arrays, kinds of all elements are included.
+func valueKind(v interface{}) int {
+	switch typedValue := v.(type) {
+	case bool:
+		return kindBool
+	case string:
+		return kindString
+	case map[string]interface{}:
+		return kindObject
+	case []interface{}:
+		k := kindArray
+		for _, el := range typedValue {
+			k |= valueKind(el)
+		}
+		return k
+	case nil:
+		return 0
+	}
+
+	return kindNumber
+}
+
+// fitsFloat32 checks if numeric value (or all numeric values in array) can be represented as float32
+// without losing precision of its decimal representation.
+func fitsFloat32(v interface{}) bool {
+	var f float64
+	switch typedValue := v.(type) {
+	case []interface{}:
+		for _, el := range typedValue {
+			if !fitsFloat32(el) {
+				return false
+			}
+		}
+		return true
+	case float64:
+		f = typedValue
+	case float32:
+		return true
+	case int:
+		f = float64(typedValue)
+	case int8, int16:
+		return true
+	case int32:
+		f = float64(typedValue)
+	case int64:
+		f = float64(typedValue)
+	default:
+		return true
+	}
+
+	if f == math.Trunc(f) && math.Abs(f) <= 1<<24 {
+		// Integers are exactly representable as float32 up to 2^24.
+		return true
+	}
+
+	return strconv.FormatFloat(f, 'g', -1, 64) == strconv.FormatFloat(float64(float32(f)), 'g', -1, 32)
+}
+
+// logf reports inference decision about node to logger.
+func (n *node) logf(format string, args ...interface{}) {
+	if n.logger != nil {
+		n.logger(n.path + ": " + fmt.Sprintf(format, args...))
+	}
+}
+
+// typeDesc returns readable description of node type, like "[]int".
+func typeDesc(t nodeType, arrayLevel int) string {
+	return strings.Repeat("[]", arrayLevel) + t.id()
+}
+
+// exceedsDepth checks if json value has objects or arrays nested deeper than maxDepth levels.
+func exceedsDepth(v interface{}, maxDepth int) bool {
+	var children []interface{}
+	switch typedValue := v.(type) {
+	case map[string]interface{}:
+		for _, c := range typedValue {
+			children = append(children, c)
+		}
+	case []interface{}:
+		children = typedValue
+	case duplicateValues:
+		for _, c := range typedValue {
+			if exceedsDepth(c, maxDepth) {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+
+	if maxDepth == 0 {
+		return true
+	}
+	for _, c := range children {
+		if exceedsDepth(c, maxDepth-1) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// addKeyOrder merges keys order, stored by object path, into nodes subtree.
+func (n *node) addKeyOrder(order map[string][]string) {
+	n.keyOrder = mergeKeyOrder(n.keyOrder, order[n.path]...)
+	for _, c := range n.children {
+		c.addKeyOrder(order)
+	}
+}
+
+// setPath sets path of node and updates paths of its subtree.
+func (n *node) setPath(path string) {
+	n.path = path
+	for _, c := range n.children {
+		c.setPath(childPath(path, c.key))
+	}
+	for _, pn := range n.tuple {
+		pn.path = path
+	}
+	if n.encoded != nil {
+		n.encoded.setPath(path)
+	}
+}
+
+// childPath returns path of attribute `key` in object with given path.
+// Arrays are transparent in paths, so all elements of an array share the same path.
+func childPath(path, key string) string {
+	if path == "" {
+		path = rootPath
+	}
+	return path + "." + key
+}
+
 // arrayStructure returns array depth and elements type. If array is nested and has no consistent structure, level -1 is returned.
 func arrayStructure(in []interface{}, inType nodeType) (depth int, outType nodeType, nullable bool) {
 	if inType == nil {
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestJSONNodeCompare(t *testing.T) {
@@ -1799,7 +1800,8 @@ func TestJSONNodeExtractCommonSubtrees(t *testing.T) {
 
 			opts := options{}
 
-			nodes := extractCommonSubtrees(tc.root)
+			nodes, err := extractCommonSubtrees(tc.root, nil, nil)
+			require.NoError(t, err)
 			if !assert.Equal(t, len(tc.expected), len(nodes)) {
 				t.Logf("\n%s\n\n", astPrintDecls(astMakeDecls(nodes, opts)))
 				t.FailNow()
@@ -2008,3 +2010,172 @@ func TestArrayStructureType(t *testing.T) {
 		})
 	}
 }
+
+func TestJSONNodeTuple(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		expands       []interface{}
+		expectedTuple bool
+		expectedTypes []nodeType
+	}{
+		{
+			name:          "not an array",
+			expands:       []interface{}{"x"},
+			expectedTuple: false,
+		},
+		{
+			name: "pair",
+			expands: []interface{}{
+				[]interface{}{"x", 1.5},
+			},
+			expectedTuple: true,
+			expectedTypes: []nodeType{nodeTypeString, nodeTypeFloat},
+		},
+		{
+			name: "pairs in multiple inputs",
+			expands: []interface{}{
+				[]interface{}{"x", 1},
+				[]interface{}{"y", 1.5},
+			},
+			expectedTuple: true,
+			expectedTypes: []nodeType{nodeTypeString, nodeTypeFloat},
+		},
+		{
+			name: "nested pairs",
+			expands: []interface{}{
+				[]interface{}{
+					[]interface{}{"x", true},
+					[]interface{}{"y", false},
+				},
+			},
+			expectedTuple: true,
+			expectedTypes: []nodeType{nodeTypeString, nodeTypeBool},
+		},
+		{
+			name: "different lengths",
+			expands: []interface{}{
+				[]interface{}{"x", true},
+				[]interface{}{"y", false, 1},
+			},
+			expectedTuple: false,
+		},
+		{
+			name: "single element",
+			expands: []interface{}{
+				[]interface{}{"x"},
+			},
+			expectedTuple: false,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			n := newNode(baseTypeName)
+			for _, v := range tc.expands {
+				n.grow(v)
+			}
+
+			assert.Equal(t, tc.expectedTuple, n.isTuple())
+			if !tc.expectedTuple {
+				return
+			}
+
+			var types []nodeType
+			for _, pn := range n.tuple {
+				types = append(types, pn.t)
+			}
+			assert.Equal(t, tc.expectedTypes, types)
+		})
+	}
+}
+
+func TestJSONNodeNumericArrays(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name              string
+		expands           []interface{}
+		expectedType      nodeType
+		expectedLevel     int
+		expectedWithNulls bool
+	}{
+		{
+			name: "matrix of ints",
+			expands: []interface{}{
+				[]interface{}{[]interface{}{1, 2}, []interface{}{3, 4}},
+			},
+			expectedType:  nodeTypeInt,
+			expectedLevel: 2,
+		},
+		{
+			name: "matrix of ints and floats",
+			expands: []interface{}{
+				[]interface{}{[]interface{}{1, 2}, []interface{}{3.5, 4}},
+			},
+			expectedType:  nodeTypeFloat,
+			expectedLevel: 2,
+		},
+		{
+			name: "ints widened to floats in next input",
+			expands: []interface{}{
+				[]interface{}{[]interface{}{1, 2}},
+				[]interface{}{[]interface{}{1.5}},
+			},
+			expectedType:  nodeTypeFloat,
+			expectedLevel: 2,
+		},
+		{
+			name: "floats and ints in next input",
+			expands: []interface{}{
+				[]interface{}{[]interface{}{1.5}},
+				[]interface{}{[]interface{}{1, 2}},
+			},
+			expectedType:  nodeTypeFloat,
+			expectedLevel: 2,
+		},
+		{
+			name: "nulls in previous input",
+			expands: []interface{}{
+				[]interface{}{[]interface{}{1, nil}},
+				[]interface{}{[]interface{}{1, 2}},
+			},
+			expectedType:      nodeTypeInt,
+			expectedLevel:     2,
+			expectedWithNulls: true,
+		},
+		{
+			name: "different depth in next input",
+			expands: []interface{}{
+				[]interface{}{[]interface{}{1, 2}},
+				[]interface{}{1, 2},
+			},
+			expectedType:  nodeTypeInterface,
+			expectedLevel: 0,
+		},
+		{
+			name: "ragged array",
+			expands: []interface{}{
+				[]interface{}{[]interface{}{1, 2}, []interface{}{[]interface{}{3}}},
+			},
+			expectedType:  nodeTypeInterface,
+			expectedLevel: 0,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			n := newNode(baseTypeName)
+			for _, v := range tc.expands {
+				n.grow(v)
+			}
+
+			assert.Equal(t, tc.expectedType, n.t)
+			assert.Equal(t, tc.expectedLevel, n.arrayLevel)
+			assert.Equal(t, tc.expectedWithNulls, n.arrayWithNulls)
+		})
+	}
+}
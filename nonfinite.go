@@ -0,0 +1,280 @@
+package json2go
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"io"
+	"math"
+	"strings"
+)
+
+// nonFiniteMarker prefixes NaN and infinity literals quoted by nonFiniteQuoter, so they are distinguished from
+// strings with the same text, see nonFiniteValues.
+const nonFiniteMarker = "\x00"
+
+// nonFiniteQuoter quotes NaN, Infinity and -Infinity literals, which aren't valid json, as strings with
+// nonFiniteMarker. It keeps state between parts of stream.
+type nonFiniteQuoter struct {
+	inString bool
+	escaped  bool
+}
+
+// isNonFiniteLiteral checks if s is NaN or infinity literal, optionally signed.
+func isNonFiniteLiteral(s string) bool {
+	switch s {
+	case "NaN", "+NaN", "-NaN", "Infinity", "+Infinity", "-Infinity":
+		return true
+	}
+	return false
+}
+
+// quote appends data with quoted literals to out. Unless final, bytes at the end of data, which may begin literal
+// continued in next part of stream, aren't consumed, their number is returned.
+func (q *nonFiniteQuoter) quote(out, data []byte, final bool) ([]byte, int) {
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case q.inString:
+			q.inString = q.escaped || c != '"'
+			q.escaped = !q.escaped && c == '\\'
+		case c == '"':
+			q.inString = true
+		case c == 'N' || c == 'I' || c == '-' || c == '+':
+			j := i + 1
+			for j < len(data) && isASCIILetter(data[j]) {
+				j++
+			}
+			if j == len(data) && !final {
+				return out, len(data) - i
+			}
+			if isNonFiniteLiteral(string(data[i:j])) {
+				out = append(out, `"\u0000`...)
+				out = append(out, data[i:j]...)
+				out = append(out, '"')
+				i = j - 1
+				continue
+			}
+		}
+		out = append(out, c)
+	}
+	return out, 0
+}
+
+func isASCIILetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// quoteNonFiniteNumbers returns json data with NaN and infinity literals quoted, see nonFiniteQuoter.
+func quoteNonFiniteNumbers(data []byte) []byte {
+	var q nonFiniteQuoter
+	out, _ := q.quote(make([]byte, 0, len(data)), data, true)
+	return out
+}
+
+// unquoteNonFiniteNumbers returns json data with literals quoted by nonFiniteQuoter restored.
+func unquoteNonFiniteNumbers(data []byte) []byte {
+	const quotePrefix = `"\u0000`
+	out := make([]byte, 0, len(data))
+	for i := 0; i < len(data); i++ {
+		if data[i] != '"' {
+			out = append(out, data[i])
+			continue
+		}
+		if bytes.HasPrefix(data[i:], []byte(quotePrefix)) {
+			if end := bytes.IndexByte(data[i+len(quotePrefix):], '"'); end >= 0 {
+				literal := data[i+len(quotePrefix) : i+len(quotePrefix)+end]
+				if isNonFiniteLiteral(string(literal)) {
+					out = append(out, literal...)
+					i += len(quotePrefix) + end
+					continue
+				}
+			}
+		}
+		// Other strings are copied as they are.
+		j := i + 1
+		for j < len(data) && data[j] != '"' {
+			if data[j] == '\\' {
+				j++
+			}
+			j++
+		}
+		if j >= len(data) {
+			return append(out, data[i:]...)
+		}
+		out = append(out, data[i:j+1]...)
+		i = j
+	}
+	return out
+}
+
+// nonFiniteReader is a reader of json stream with NaN and infinity literals quoted, see nonFiniteQuoter.
+type nonFiniteReader struct {
+	r   io.Reader
+	q   nonFiniteQuoter
+	in  []byte // bytes read, which weren't quoted yet
+	out []byte // quoted bytes, which weren't returned yet
+	err error
+}
+
+func (r *nonFiniteReader) Read(b []byte) (int, error) {
+	for len(r.out) == 0 && r.err == nil {
+		chunk := make([]byte, len(r.in)+len(b)+1)
+		copy(chunk, r.in)
+		n, err := r.r.Read(chunk[len(r.in):])
+		chunk = chunk[:len(r.in)+n]
+		r.err = err
+
+		var pending int
+		r.out, pending = r.q.quote(r.out[:0], chunk, err != nil)
+		r.in = append(r.in[:0], chunk[len(chunk)-pending:]...)
+	}
+	if len(r.out) == 0 {
+		return 0, r.err
+	}
+
+	n := copy(b, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+// nonFiniteValues returns json value with strings of literals quoted by nonFiniteQuoter replaced with NaN and
+// infinities.
+func nonFiniteValues(v interface{}) interface{} {
+	switch typedValue := v.(type) {
+	case string:
+		if !strings.HasPrefix(typedValue, nonFiniteMarker) || !isNonFiniteLiteral(typedValue[len(nonFiniteMarker):]) {
+			return v
+		}
+		literal := typedValue[len(nonFiniteMarker):]
+		if strings.HasSuffix(literal, "NaN") {
+			return math.NaN()
+		}
+		if literal[0] == '-' {
+			return math.Inf(-1)
+		}
+		return math.Inf(1)
+	case map[string]interface{}:
+		for k, el := range typedValue {
+			typedValue[k] = nonFiniteValues(el)
+		}
+	case []interface{}:
+		for i, el := range typedValue {
+			typedValue[i] = nonFiniteValues(el)
+		}
+	case duplicateValues:
+		for i, el := range typedValue {
+			typedValue[i] = nonFiniteValues(el)
+		}
+	}
+	return v
+}
+
+// nonFiniteTypes returns bits of NaN, infinities and negative zero of numeric value.
+func nonFiniteTypes(f float64) int {
+	switch {
+	case math.IsNaN(f) || math.IsInf(f, 0):
+		return seenNonFinite
+	case f == 0 && math.Signbit(f):
+		return seenNegativeZero
+	}
+	return 0
+}
+
+// astTypeFromNonFiniteNode returns type of numbers with NaN, infinities or negative zero, or nil for default type.
+// Numbers with negative zero are float64, numbers with NaN or infinities have helper type.
+func astTypeFromNonFiniteNode(n *node, ctx *astContext) ast.Expr {
+	if !ctx.opts.nonFiniteNumbers || n.t.id() == nodeTypeString.id() {
+		return nil
+	}
+	if n.seenTypes&seenNonFinite != 0 {
+		return astNonFiniteFloatType(ctx)
+	}
+	if n.seenTypes&seenNegativeZero != 0 && n.t.id() == nodeTypeInt.id() {
+		return astTypeFromFloatNode(n, ctx)
+	}
+	return nil
+}
+
+// astNonFiniteFloatType returns helper float type, unmarshaled also from NaN and infinity literals quoted as strings,
+// with helper function quoting them.
+func astNonFiniteFloatType(ctx *astContext) ast.Expr {
+	ctx.addImport("encoding/json")
+	ctx.addImport("fmt")
+	ctx.addImport("math")
+	name := ctx.addSharedHelper("NonFiniteFloat", func(name string) string {
+		return fmt.Sprintf(`
+// %[1]s is a float64, unmarshaled also from strings with NaN, Infinity or -Infinity. Json data with such literals
+// isn't valid json, it has to be prepared with Quote%[1]ss first.
+type %[1]s float64
+
+// UnmarshalJSON unmarshals number from json number, or from string with NaN, Infinity or -Infinity.
+func (f *%[1]s) UnmarshalJSON(data []byte) error {
+	if len(data) == 0 || data[0] != '"' {
+		return json.Unmarshal(data, (*float64)(f))
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	switch s {
+	case "NaN", "+NaN", "-NaN":
+		*f = %[1]s(math.NaN())
+	case "Infinity", "+Infinity":
+		*f = %[1]s(math.Inf(1))
+	case "-Infinity":
+		*f = %[1]s(math.Inf(-1))
+	default:
+		return fmt.Errorf("invalid number: %%q", s)
+	}
+	return nil
+}
+
+// MarshalJSON marshals number as json number, or NaN and infinities as strings, as json has no literals for them.
+func (f %[1]s) MarshalJSON() ([]byte, error) {
+	switch v := float64(f); {
+	case math.IsNaN(v):
+		return []byte(`+"`"+`"NaN"`+"`"+`), nil
+	case math.IsInf(v, 1):
+		return []byte(`+"`"+`"Infinity"`+"`"+`), nil
+	case math.IsInf(v, -1):
+		return []byte(`+"`"+`"-Infinity"`+"`"+`), nil
+	}
+	return json.Marshal(float64(f))
+}
+
+// Quote%[1]ss returns copy of json data with NaN, Infinity and -Infinity literals quoted as strings, so it can be
+// unmarshaled to %[1]s values.
+func Quote%[1]ss(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	inString, escaped := false, false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case inString:
+			inString = escaped || c != '"'
+			escaped = !escaped && c == '\\'
+		case c == '"':
+			inString = true
+		case c == 'N' || c == 'I' || c == '-' || c == '+':
+			j := i + 1
+			for j < len(data) && (data[j] >= 'a' && data[j] <= 'z' || data[j] >= 'A' && data[j] <= 'Z') {
+				j++
+			}
+			switch literal := string(data[i:j]); literal {
+			case "NaN", "+NaN", "-NaN", "Infinity", "+Infinity", "-Infinity":
+				out = append(out, '"')
+				out = append(out, literal...)
+				out = append(out, '"')
+				i = j - 1
+				continue
+			}
+		}
+		out = append(out, c)
+	}
+	return out
+}
+`, name)
+	})
+	return ast.NewIdent(name)
+}
@@ -0,0 +1,148 @@
+package json2go
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuoteNonFiniteNumbers(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "literals",
+			input:    `[NaN,Infinity,-Infinity,+Infinity,-NaN]`,
+			expected: `["\u0000NaN","\u0000Infinity","\u0000-Infinity","\u0000+Infinity","\u0000-NaN"]`,
+		},
+		{
+			name:     "numbers",
+			input:    `[-1,1e-5,1E+5,-0,true,null]`,
+			expected: `[-1,1e-5,1E+5,-0,true,null]`,
+		},
+		{
+			name:     "strings",
+			input:    `{"NaN":"Infinity","a\"NaN":"\\",  "b" : NaN}`,
+			expected: `{"NaN":"Infinity","a\"NaN":"\\",  "b" : "\u0000NaN"}`,
+		},
+		{
+			name:     "other words",
+			input:    `{"a":Nope,"b":Infinite}`,
+			expected: `{"a":Nope,"b":Infinite}`,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, string(quoteNonFiniteNumbers([]byte(tc.input))))
+			assert.Equal(t, tc.input, string(unquoteNonFiniteNumbers([]byte(tc.expected))))
+			out, err := ioutil.ReadAll(&nonFiniteReader{r: iotest.OneByteReader(strings.NewReader(tc.input))})
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(out))
+		})
+	}
+}
+
+func TestParserNonFiniteNumbers(t *testing.T) {
+	t.Parallel()
+
+	const input = `{"a":1.5,"b":NaN,"c":[1,Infinity,-Infinity],"d":-0,"e":"NaN","f":1}`
+	expected := map[string]string{
+		"$.a": "float64", "$.b": "NonFiniteFloat", "$.c": "[]NonFiniteFloat", "$.d": "float64", "$.e": "string",
+		"$.f": "int",
+	}
+
+	for _, stream := range []bool{false, true} {
+		parser := NewJSONParser(baseTypeName, OptNonFiniteNumbers(true))
+		if stream {
+			require.NoError(t, parser.FeedReader(iotest.HalfReader(strings.NewReader(input+input))))
+		} else {
+			require.NoError(t, parser.FeedBytes([]byte(input)))
+		}
+		fields := make(map[string]string)
+		for _, f := range parser.Fields() {
+			fields[f.Path] = f.Type
+		}
+		assert.Equal(t, expected, fields)
+	}
+
+	parser := NewJSONParser(baseTypeName)
+	assert.Error(t, parser.FeedBytes([]byte(input)))
+	require.NoError(t, parser.FeedBytes([]byte(`{"d":-0}`)))
+	assert.Contains(t, parser.String(), "D int")
+}
+
+func TestParserNonFiniteNumbersCode(t *testing.T) {
+	t.Parallel()
+
+	const input = `{"a":NaN,"b":[1.5,Infinity,-Infinity],"c":"NaN"}`
+	parser := NewJSONParser(baseTypeName, OptNonFiniteNumbers(true))
+	require.NoError(t, parser.FeedBytes([]byte(input)))
+
+	out := runGeneratedCode(t, parser, `
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Scan()
+	var d Document
+	if err := json.Unmarshal(QuoteNonFiniteFloats(scanner.Bytes()), &d); err != nil {
+		panic(err)
+	}
+	fmt.Println(math.IsNaN(float64(d.A)), math.IsInf(float64(d.B[1]), 1), math.IsInf(float64(d.B[2]), -1))
+	out, err := json.Marshal(d)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(out))
+	`, input)
+	assert.Equal(t, "true true true\n{\"a\":\"NaN\",\"b\":[1.5,\"Infinity\",\"-Infinity\"],\"c\":\"NaN\"}\n", out)
+	assert.Contains(t, parser.String(), "func QuoteNonFiniteFloats(data []byte) []byte")
+}
+
+func TestConverterNonFiniteNumbers(t *testing.T) {
+	t.Parallel()
+
+	const input = `{"a":1.5,"b":NaN,"c":[1,Infinity,-Infinity]}`
+	expected := NewJSONParser(baseTypeName, OptNonFiniteNumbers(true))
+	require.NoError(t, expected.FeedBytes([]byte(input)))
+	expectedCode, err := expected.Generate()
+	require.NoError(t, err)
+
+	for _, opts := range [][]JSONParserOpt{{OptNonFiniteNumbers(true)}, {OptNonFiniteNumbers(true), OptFieldOrder(FieldOrderOriginal)}} {
+		c := NewConverter(baseTypeName, opts...)
+		require.NoError(t, c.Feed([]byte(input)))
+		require.NoError(t, c.FeedReaderContext(context.Background(), iotest.OneByteReader(strings.NewReader(input+" "+input))))
+		code, err := c.Generate()
+		require.NoError(t, err)
+		if len(opts) == 1 {
+			assert.Equal(t, expectedCode, code)
+		}
+		assert.Contains(t, code, "B NonFiniteFloat")
+	}
+}
+
+func TestInputReaderNonFiniteNumbers(t *testing.T) {
+	t.Parallel()
+
+	r := NewInputReader(iotest.OneByteReader(strings.NewReader(`{"a":NaN,"b":"NaN"} [-Infinity]`)), nil, OptNonFiniteNumbers(true))
+	doc, err := r.ReadDocument()
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":NaN,"b":"NaN"}`, string(doc))
+	doc, err = r.ReadDocument()
+	require.NoError(t, err)
+	assert.Equal(t, `[-Infinity]`, string(doc))
+
+	_, err = NewInputReader(strings.NewReader(`{"a":NaN}`), nil).ReadDocument()
+	assert.True(t, errors.Is(err, ErrInvalidJSON))
+}
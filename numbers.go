@@ -0,0 +1,124 @@
+package json2go
+
+import (
+	"fmt"
+	"go/ast"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// NumberLocale is a format of numbers represented in json as strings, with thousands separators and decimal separator
+// of locale, like "1.234,56". Attributes with such strings are unmarshaled as numbers.
+type NumberLocale int
+
+const (
+	// NumberLocaleNone keeps strings with numbers as strings.
+	NumberLocaleNone NumberLocale = iota
+	// NumberLocaleEnglish formats numbers like "1,234.56".
+	NumberLocaleEnglish
+	// NumberLocaleGerman formats numbers like "1.234,56".
+	NumberLocaleGerman
+	// NumberLocaleFrench formats numbers like "1 234,56", thousands are separated with space or non-breaking space.
+	NumberLocaleFrench
+	// NumberLocaleSwiss formats numbers like "1'234.56".
+	NumberLocaleSwiss
+)
+
+// ParseNumberLocale returns number locale by name: "none", "en", "de", "fr" or "ch". Empty name means none.
+func ParseNumberLocale(name string) (NumberLocale, error) {
+	switch name {
+	case "", "none":
+		return NumberLocaleNone, nil
+	case "en":
+		return NumberLocaleEnglish, nil
+	case "de":
+		return NumberLocaleGerman, nil
+	case "fr":
+		return NumberLocaleFrench, nil
+	case "ch":
+		return NumberLocaleSwiss, nil
+	}
+	return NumberLocaleNone, fmt.Errorf("unknown number locale: %s", name)
+}
+
+// numberLocale describes separators of numbers formatted in locale.
+type numberLocale struct {
+	decimal   string
+	thousands []string
+	example   string
+	format    int
+	re        *regexp.Regexp
+}
+
+var numberLocales = map[NumberLocale]*numberLocale{
+	NumberLocaleEnglish: newNumberLocale(".", []string{","}, "1,234.56", formatNumberEnglish),
+	NumberLocaleGerman:  newNumberLocale(",", []string{"."}, "1.234,56", formatNumberGerman),
+	NumberLocaleFrench:  newNumberLocale(",", []string{" ", "\u00a0", "\u202f"}, "1 234,56", formatNumberFrench),
+	NumberLocaleSwiss:   newNumberLocale(".", []string{"'"}, "1'234.56", formatNumberSwiss),
+}
+
+func newNumberLocale(decimal string, thousands []string, example string, format int) *numberLocale {
+	var seps []string
+	for _, t := range thousands {
+		seps = append(seps, regexp.QuoteMeta(t))
+	}
+	thousandsRe := "(" + strings.Join(seps, "|") + ")"
+	return &numberLocale{
+		decimal:   decimal,
+		thousands: thousands,
+		example:   example,
+		format:    format,
+		re: regexp.MustCompile(`^[-+]?(\d{1,3}(` + thousandsRe + `\d{3})+|0|[1-9]\d*)(` +
+			regexp.QuoteMeta(decimal) + `\d+)?$`),
+	}
+}
+
+// astIsLocalizedNumberNode checks if all node's values are strings with numbers formatted in locale,
+// and not all of them are plain integers, which are likely identifiers.
+func astIsLocalizedNumberNode(n *node, locale NumberLocale) bool {
+	l, ok := numberLocales[locale]
+	return ok && n.t == nodeTypeString && n.formats&l.format != 0 && n.formats&formatDigitsString == 0
+}
+
+// astTypeFromLocalizedNumberNode returns helper type of numbers unmarshaled from json strings formatted in locale.
+func astTypeFromLocalizedNumberNode(ctx *astContext) ast.Expr {
+	l := numberLocales[ctx.opts.numberLocale]
+	ctx.addImport("encoding/json")
+	ctx.addImport("fmt")
+	ctx.addImport("strconv")
+	ctx.addImport("strings")
+
+	var replacements []string
+	for _, t := range l.thousands {
+		replacements = append(replacements, strconv.Quote(t), `""`)
+	}
+	replacements = append(replacements, strconv.Quote(l.decimal), `"."`)
+
+	return ast.NewIdent(ctx.addSharedHelper("LocalizedNumber", func(name string) string {
+		return fmt.Sprintf(`
+// %[1]s is a number represented in json as a string formatted in locale, like %[2]q.
+type %[1]s float64
+
+// UnmarshalJSON unmarshals number from json string or number.
+func (n *%[1]s) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		var v float64
+		if err := json.Unmarshal(data, &v); err != nil {
+			return err
+		}
+		*n = %[1]s(v)
+		return nil
+	}
+
+	v, err := strconv.ParseFloat(strings.NewReplacer(%[3]s).Replace(s), 64)
+	if err != nil {
+		return fmt.Errorf("invalid number value: %%q", s)
+	}
+	*n = %[1]s(v)
+	return nil
+}
+`, name, l.example, strings.Join(replacements, ", "))
+	}))
+}
@@ -0,0 +1,90 @@
+package json2go
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserNumberLocale(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		locale   NumberLocale
+		input    string
+		expected string
+	}{
+		{
+			name:     "none",
+			locale:   NumberLocaleNone,
+			input:    `{"price":"1.234,56"}`,
+			expected: "string",
+		},
+		{
+			name:     "german",
+			locale:   NumberLocaleGerman,
+			input:    `{"price":"1.234,56"}`,
+			expected: "LocalizedNumber",
+		},
+		{
+			name:     "english",
+			locale:   NumberLocaleEnglish,
+			input:    `{"price":"1.234,56"}`,
+			expected: "string",
+		},
+		{
+			name:     "plain integers",
+			locale:   NumberLocaleGerman,
+			input:    `{"price":"1234"}`,
+			expected: "string",
+		},
+		{
+			name:     "swiss",
+			locale:   NumberLocaleSwiss,
+			input:    `{"price":"1'234"}`,
+			expected: "LocalizedNumber",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			parser := NewJSONParser(baseTypeName, OptNumberLocale(tc.locale))
+			require.NoError(t, parser.FeedBytes([]byte(tc.input)))
+			require.NoError(t, parser.FeedBytes([]byte(`{"price":null}`)))
+
+			fields := parser.Fields()
+			require.Len(t, fields, 1)
+			assert.Equal(t, "*"+tc.expected, fields[0].Type)
+		})
+	}
+}
+
+func TestParserNumberLocaleCode(t *testing.T) {
+	t.Parallel()
+
+	input := `{"amount":"-1 234 567,5"}
+{"amount":"0,25"}
+{"amount":12}`
+
+	parser := NewJSONParser(baseTypeName, OptNumberLocale(NumberLocaleFrench))
+	require.NoError(t, parser.FeedBytes([]byte(`{"amount":"1 234,56"}`)))
+	require.NoError(t, parser.FeedBytes([]byte(`{"amount":"7"}`)))
+
+	out := runGeneratedCode(t, parser, `
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		var d Document
+		if err := json.Unmarshal(scanner.Bytes(), &d); err != nil {
+			fmt.Println(err)
+			continue
+		}
+		fmt.Println(float64(d.Amount))
+	}
+`, input)
+	assert.Equal(t, "-1.2345675e+06\n0.25\n12\n", out)
+}
@@ -0,0 +1,253 @@
+package json2go
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/token"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DecodeFailure is a value of sample, which doesn't decode to generated type, found by VerifyDecoding.
+type DecodeFailure struct {
+	// Sample is an index of sample.
+	Sample int
+	// Path is a json path of value in sample, like "$.items[1].id".
+	Path string
+	// Reason is a decoding error.
+	Reason string
+}
+
+func (f DecodeFailure) String() string {
+	return fmt.Sprintf("sample %d: %s: %s", f.Sample, f.Path, f.Reason)
+}
+
+// DecodeError is returned by VerifyDecoding, and by Generate with OptVerifyDecoding, when samples don't decode
+// to generated types. It matches ErrDecodeMismatch.
+type DecodeError struct {
+	Failures []DecodeFailure
+}
+
+func (e *DecodeError) Error() string {
+	var failures []string
+	for _, f := range e.Failures {
+		failures = append(failures, f.String())
+	}
+	return ErrDecodeMismatch.Error() + ": " + strings.Join(failures, "; ")
+}
+
+func (e *DecodeError) Is(target error) bool {
+	return target == ErrDecodeMismatch
+}
+
+// VerifyDecoding decodes json samples with encoding/json, with unknown fields disallowed, to go types constructed
+// with reflection from schema, and returns DecodeError, listing paths of values, which don't fit their types.
+// Unlike VerifyRoundTrip, decoding isn't simulated, so it catches inference bugs, before code is written.
+// Samples, which don't decode, and samples of recursive types, which reflection can't construct, are decoded
+// value by value. Types with own json methods are opaque and accept any values, and structs keeping values of
+// unknown keys accept any keys.
+func VerifyDecoding(samples [][]byte, generatedIR *Schema) error {
+	if generatedIR == nil || generatedIR.Types[generatedIR.Root] == nil {
+		return errors.New("schema has no root type")
+	}
+
+	o := decodingOracle{schema: generatedIR, types: make(map[string]reflect.Type)}
+	root := &SchemaType{Kind: SchemaNamed, Name: generatedIR.Root}
+	o.reflectType(root)
+	for i, sample := range samples {
+		if !json.Valid(sample) {
+			return invalidJSONError{err: fmt.Errorf("sample %d isn't valid json", i)}
+		}
+		if !o.recursive && o.decode(sample, root) == nil {
+			continue
+		}
+		// Values of sample are decoded one by one, to find paths of failing ones.
+		o.sample = i
+		o.locate(sample, root, rootPath)
+	}
+	if len(o.failures) > 0 {
+		return &DecodeError{Failures: o.failures}
+	}
+	return nil
+}
+
+// decodingOracle keeps state of VerifyDecoding.
+type decodingOracle struct {
+	schema *Schema
+	// types are reflect types of declared types, by names, nil for types being constructed.
+	types map[string]reflect.Type
+	// recursive is true, if reflect types accept any values nested in recursive types.
+	recursive bool
+	sample    int
+	failures  []DecodeFailure
+}
+
+func (o *decodingOracle) fail(path string, err error) {
+	if err == nil {
+		return
+	}
+	reason := strings.TrimPrefix(err.Error(), "json: ")
+	o.failures = append(o.failures, DecodeFailure{Sample: o.sample, Path: path, Reason: reason})
+}
+
+// decode decodes json value to reflect type of t, with unknown fields disallowed.
+func (o *decodingOracle) decode(data []byte, t *SchemaType) error {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.DisallowUnknownFields()
+	return d.Decode(reflect.New(o.reflectType(t)).Interface())
+}
+
+// resolve returns declared type of named type.
+func (o *decodingOracle) resolve(t *SchemaType) *SchemaType {
+	for depth := 0; t.Kind == SchemaNamed; depth++ {
+		declared := o.schema.Types[t.Name]
+		if declared == nil || depth > len(o.schema.Types) {
+			return &SchemaType{Kind: SchemaOpaque}
+		}
+		t = declared
+	}
+	return t
+}
+
+// reflectType returns go type of t, decoding json values like generated type.
+func (o *decodingOracle) reflectType(t *SchemaType) reflect.Type {
+	switch t.Kind {
+	case SchemaNamed:
+		rt, ok := o.types[t.Name]
+		if rt != nil {
+			return rt
+		}
+		declared := o.schema.Types[t.Name]
+		if ok || declared == nil {
+			// Reflection can't construct recursive types.
+			o.recursive = true
+			return reflect.TypeOf((*interface{})(nil)).Elem()
+		}
+		o.types[t.Name] = nil
+		rt = o.reflectType(declared)
+		o.types[t.Name] = rt
+		return rt
+	case SchemaBool:
+		return reflect.TypeOf(false)
+	case SchemaInt:
+		return reflect.TypeOf(int64(0))
+	case SchemaFloat:
+		if t.Bits == 32 {
+			return reflect.TypeOf(float32(0))
+		}
+		return reflect.TypeOf(float64(0))
+	case SchemaString:
+		return reflect.TypeOf("")
+	case SchemaTime:
+		return reflect.TypeOf(time.Time{})
+	case SchemaAny:
+		return reflect.TypeOf((*interface{})(nil)).Elem()
+	case SchemaPointer, SchemaOptional:
+		return reflect.PtrTo(o.reflectType(t.Elem))
+	case SchemaSlice:
+		return reflect.SliceOf(o.reflectType(t.Elem))
+	case SchemaMap:
+		return reflect.MapOf(o.reflectType(t.Key), o.reflectType(t.Elem))
+	case SchemaStruct:
+		var fields []reflect.StructField
+		names := make(map[string]bool)
+		for i, f := range t.Fields {
+			name := f.Name
+			if !token.IsIdentifier(name) || !token.IsExported(name) || names[name] {
+				name = fmt.Sprintf("Field%d", i)
+			}
+			names[name] = true
+			fields = append(fields, reflect.StructField{
+				Name: name,
+				Type: o.reflectType(f.Type),
+				Tag:  reflect.StructTag("json:" + strconv.Quote(f.Key)),
+			})
+		}
+		return reflect.StructOf(fields)
+	}
+	return reflect.TypeOf(json.RawMessage(nil))
+}
+
+// locate decodes json value to type t, adding failures of values, which don't fit their types. Containers are
+// decoded element by element.
+func (o *decodingOracle) locate(data []byte, t *SchemaType, path string) {
+	t = o.resolve(t)
+	switch t.Kind {
+	case SchemaPointer, SchemaOptional:
+		if !bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+			o.locate(data, t.Elem, path)
+		}
+	case SchemaSlice:
+		var elems []json.RawMessage
+		if err := json.Unmarshal(data, &elems); err != nil {
+			o.fail(path, o.decode(data, t))
+			return
+		}
+		for i, e := range elems {
+			o.locate(e, t.Elem, fmt.Sprintf("%s[%d]", path, i))
+		}
+	case SchemaMap:
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(data, &obj); err != nil {
+			o.fail(path, o.decode(data, t))
+			return
+		}
+		for _, k := range sortedRawKeys(obj) {
+			if o.resolve(t.Key).Kind == SchemaInt {
+				if _, err := strconv.ParseInt(k, 10, 64); err != nil {
+					o.fail(path+"."+k, fmt.Errorf("key %q into int", k))
+					continue
+				}
+			}
+			o.locate(obj[k], t.Elem, path+"."+k)
+		}
+	case SchemaStruct:
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(data, &obj); err != nil {
+			o.fail(path, o.decode(data, t))
+			return
+		}
+		for _, k := range sortedRawKeys(obj) {
+			f := structFieldOfKey(t, k)
+			if f == nil {
+				if !t.KeepsUnknown {
+					o.fail(path+"."+k, fmt.Errorf("unknown field %q", k))
+				}
+				continue
+			}
+			o.locate(obj[k], f.Type, path+"."+k)
+		}
+	default:
+		o.fail(path, o.decode(data, t))
+	}
+}
+
+// structFieldOfKey returns field decoded from key by encoding/json, preferring exact match over case insensitive
+// one, or nil.
+func structFieldOfKey(t *SchemaType, key string) *SchemaField {
+	for i := range t.Fields {
+		if t.Fields[i].Key == key {
+			return &t.Fields[i]
+		}
+	}
+	for i := range t.Fields {
+		if strings.EqualFold(t.Fields[i].Key, key) {
+			return &t.Fields[i]
+		}
+	}
+	return nil
+}
+
+func sortedRawKeys(obj map[string]json.RawMessage) []string {
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,135 @@
+package json2go
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyDecoding(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		inferred []string
+		opts     []JSONParserOpt
+		samples  []string
+		expected []DecodeFailure
+	}{
+		{
+			name:     "fitting",
+			inferred: []string{`{"id": 1, "tags": ["a"], "at": "2021-01-02T03:04:05Z", "meta": {"x": 1.5}}`},
+			samples: []string{
+				`{"id": 2, "tags": null, "at": "2022-01-02T03:04:05Z", "meta": {"x": 2}}`,
+				`{"ID": 3}`,
+			},
+		},
+		{
+			name:     "mismatches",
+			inferred: []string{`{"id": 1, "user": {"name": "x", "age": 3}, "items": [{"price": 1.5}]}`},
+			samples: []string{
+				`{"id": 1.5, "user": {"name": 1, "age": 3, "email": "x"}, "items": [{"price": 1}, {"price": "1"}]}`,
+				`{"id": 1, "user": "x", "items": {}}`,
+			},
+			expected: []DecodeFailure{
+				{Sample: 0, Path: "$.id", Reason: "cannot unmarshal number 1.5 into Go value of type int64"},
+				{Sample: 0, Path: "$.items[1].price", Reason: "cannot unmarshal string into Go value of type float64"},
+				{Sample: 0, Path: "$.user.email", Reason: `unknown field "email"`},
+				{Sample: 0, Path: "$.user.name", Reason: "cannot unmarshal number into Go value of type string"},
+				{Sample: 1, Path: "$.items", Reason: "cannot unmarshal object into Go value of type []struct { Price float64 \"json:\\\"price\\\"\" }"},
+				{Sample: 1, Path: "$.user", Reason: "cannot unmarshal string into Go value of type struct { Age int64 \"json:\\\"age\\\"\"; Name string \"json:\\\"name\\\"\" }"},
+			},
+		},
+		{
+			name:     "maps",
+			inferred: []string{`{"1": {"n": 1}, "2": {"n": 2}, "3": {"n": 3}}`},
+			opts:     []JSONParserOpt{OptMakeMaps(true, 3), OptMapKeyTypes(true)},
+			samples:  []string{`{"4": {"n": 4}, "x": {"n": 5}, "6": {"n": true}}`},
+			expected: []DecodeFailure{
+				{Sample: 0, Path: "$.6.n", Reason: "cannot unmarshal bool into Go value of type int64"},
+				{Sample: 0, Path: "$.x", Reason: `key "x" into int`},
+			},
+		},
+		{
+			name:     "unknown fields",
+			inferred: []string{`{"a": 1, "b": {"c": 2}}`},
+			opts:     []JSONParserOpt{OptUnknownFields(true)},
+			samples:  []string{`{"a": 1, "d": 3, "b": {"c": 2, "e": 4}}`},
+			expected: []DecodeFailure{
+				{Sample: 0, Path: "$.b.e", Reason: `unknown field "e"`},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := NewJSONParser(baseTypeName, tc.opts...)
+			for _, s := range tc.inferred {
+				require.NoError(t, p.FeedBytes([]byte(s)))
+			}
+			var samples [][]byte
+			for _, s := range tc.samples {
+				samples = append(samples, []byte(s))
+			}
+
+			err := VerifyDecoding(samples, p.Schema())
+			if tc.expected == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, ErrDecodeMismatch))
+			var decodeErr *DecodeError
+			require.True(t, errors.As(err, &decodeErr))
+			assert.Equal(t, tc.expected, decodeErr.Failures)
+		})
+	}
+}
+
+func TestVerifyDecodingRecursive(t *testing.T) {
+	t.Parallel()
+
+	schema := &Schema{Root: "Node", Types: map[string]*SchemaType{
+		"Node": {Kind: SchemaStruct, Fields: []SchemaField{
+			{Name: "Name", Key: "name", Type: &SchemaType{Kind: SchemaString}},
+			{Name: "Children", Key: "children", Type: &SchemaType{Kind: SchemaSlice, Elem: &SchemaType{Kind: SchemaNamed, Name: "Node"}}},
+		}},
+	}}
+	samples := [][]byte{
+		[]byte(`{"name": "a", "children": [{"name": "b", "children": [{"name": "c"}]}]}`),
+		[]byte(`{"name": "a", "children": [{"name": "b", "children": [{"name": 1, "size": 2}]}]}`),
+	}
+
+	err := VerifyDecoding(samples, schema)
+	require.Error(t, err)
+	var decodeErr *DecodeError
+	require.True(t, errors.As(err, &decodeErr))
+	assert.Equal(t, []DecodeFailure{
+		{Sample: 1, Path: "$.children[0].children[0].name", Reason: "cannot unmarshal number into Go value of type string"},
+		{Sample: 1, Path: "$.children[0].children[0].size", Reason: `unknown field "size"`},
+	}, decodeErr.Failures)
+}
+
+func TestOptVerifyDecoding(t *testing.T) {
+	t.Parallel()
+
+	p := NewJSONParser(baseTypeName, OptVerifyDecoding(true), OptSampleLimit(2))
+	require.NoError(t, p.FeedBytes([]byte(`{"values": [1, 2, "three"]}`)))
+	_, err := p.Generate()
+	require.Error(t, err)
+	assert.Equal(t, `samples don't decode to generated types: sample 0: $.values[2]: cannot unmarshal string into Go value of type int64`, err.Error())
+	assert.Equal(t, ExitInvalidCode, ExitCode(err))
+
+	p = NewJSONParser(baseTypeName, OptVerifyDecoding(true))
+	require.NoError(t, p.FeedBytes([]byte(`{"values": [1, 2, "three"]}`)))
+	require.NoError(t, p.FeedReader(strings.NewReader(`{"values": [4]} {"values": null}`)))
+	_, err = p.Generate()
+	require.NoError(t, err)
+	assert.Len(t, p.samples, 3)
+}
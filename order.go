@@ -0,0 +1,144 @@
+package json2go
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// FieldOrder is an order of keys in json marshaled from generated types.
+type FieldOrder int
+
+const (
+	// FieldOrderStruct is encoding/json default - keys are marshaled in struct fields order.
+	FieldOrderStruct FieldOrder = iota
+	// FieldOrderOriginal marshals keys in order of their first appearance in parsed json documents.
+	FieldOrderOriginal
+)
+
+// TypeOrder is an order of declarations of generated types referring to each other.
+type TypeOrder int
+
+const (
+	// TypeOrderReferencedAfter declares type after the first type referring to it, so code reads top-down.
+	TypeOrderReferencedAfter TypeOrder = iota
+	// TypeOrderReferencedBefore declares type before all types referring to it.
+	TypeOrderReferencedBefore
+)
+
+// orderNodes returns nodes of types ordered by references between them, starting from first node.
+// Types referring to each other are declared in order of their first reference.
+func orderNodes(nodes []*node, order TypeOrder) []*node {
+	byName := make(map[string]*node, len(nodes))
+	for _, n := range nodes {
+		byName[n.name] = n
+	}
+
+	result := make([]*node, 0, len(nodes))
+	visited := make(map[*node]bool, len(nodes))
+	var visit func(n *node)
+	visit = func(n *node) {
+		if visited[n] {
+			return
+		}
+		// Node is marked before its references are visited, so cycles end here.
+		visited[n] = true
+		if order == TypeOrderReferencedAfter {
+			result = append(result, n)
+		}
+		for _, name := range referencedTypes(n, nil) {
+			if rn, ok := byName[name]; ok {
+				visit(rn)
+			}
+		}
+		if order == TypeOrderReferencedBefore {
+			result = append(result, n)
+		}
+	}
+	for _, n := range nodes {
+		visit(n)
+	}
+
+	return result
+}
+
+// referencedTypes appends names of extracted types used in node's subtree, in fields order.
+func referencedTypes(n *node, names []string) []string {
+	if n.t == nodeTypeExtracted {
+		names = append(names, n.externalTypeID)
+	}
+	for _, c := range n.children {
+		names = referencedTypes(c, names)
+	}
+	for _, c := range n.tuple {
+		names = referencedTypes(c, names)
+	}
+	return names
+}
+
+// jsonKeyOrder returns keys of all objects in json document, in order of their first appearance, by object path.
+func jsonKeyOrder(data []byte) (map[string][]string, error) {
+	order := make(map[string][]string)
+	jd := json.NewDecoder(bytes.NewReader(data))
+	if err := readKeyOrder(jd, rootPath, order); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+func readKeyOrder(jd *json.Decoder, path string, order map[string][]string) error {
+	t, err := jd.Token()
+	if err != nil {
+		return err
+	}
+
+	switch t {
+	case json.Delim('{'):
+		for jd.More() {
+			kt, err := jd.Token()
+			if err != nil {
+				return err
+			}
+			key, ok := kt.(string)
+			if !ok {
+				return fmt.Errorf("invalid object key: %v", kt)
+			}
+			order[path] = mergeKeyOrder(order[path], key)
+			if err := readKeyOrder(jd, childPath(path, key), order); err != nil {
+				return err
+			}
+		}
+	case json.Delim('['):
+		// Arrays are transparent in paths.
+		for jd.More() {
+			if err := readKeyOrder(jd, path, order); err != nil {
+				return err
+			}
+		}
+	default:
+		return nil
+	}
+
+	// Read closing delimiter.
+	_, err = jd.Token()
+	return err
+}
+
+// mergeKeyOrder appends keys missing in order.
+func mergeKeyOrder(order []string, keys ...string) []string {
+	for _, k := range keys {
+		found := false
+		for _, ok := range order {
+			if ok == k {
+				found = true
+				break
+			}
+		}
+		if !found {
+			order = append(order, k)
+		}
+	}
+
+	return order
+}
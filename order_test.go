@@ -0,0 +1,125 @@
+package json2go
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONKeyOrder(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		input    string
+		expected map[string][]string
+	}{
+		{
+			name:     "simple value",
+			input:    `"test"`,
+			expected: map[string][]string{},
+		},
+		{
+			name:  "object",
+			input: `{"z":1,"a":2,"m":null}`,
+			expected: map[string][]string{
+				"$": {"z", "a", "m"},
+			},
+		},
+		{
+			name:  "nested objects",
+			input: `{"z":{"y":1,"b":[1,2]},"a":{"c":{"x":true}}}`,
+			expected: map[string][]string{
+				"$":     {"z", "a"},
+				"$.z":   {"y", "b"},
+				"$.a":   {"c"},
+				"$.a.c": {"x"},
+			},
+		},
+		{
+			name:  "array of objects",
+			input: `[{"z":1,"b":2},{"c":3,"b":4,"a":5}]`,
+			expected: map[string][]string{
+				"$": {"z", "b", "c", "a"},
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			order, err := jsonKeyOrder([]byte(tc.input))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, order)
+		})
+	}
+}
+
+func TestTypeOrder(t *testing.T) {
+	t.Parallel()
+
+	input := `{"z":{"x":1,"y":2},"w":{"x":1,"y":2},"a":{"u":{"m":1,"n":"s"},"v":{"x":1,"y":2}},"b":{"u":{"m":1,"n":"s"},"v":{"x":1,"y":2}}}`
+	testCases := []struct {
+		name     string
+		order    TypeOrder
+		expected []string
+	}{
+		{
+			name:     "referenced after",
+			order:    TypeOrderReferencedAfter,
+			expected: []string{"Doc", "UV", "U", "XY"},
+		},
+		{
+			name:     "referenced before",
+			order:    TypeOrderReferencedBefore,
+			expected: []string{"U", "XY", "UV", "Doc"},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := NewJSONParser("Doc", OptExtractCommonTypes(true), OptTypeOrder(tc.order))
+			require.NoError(t, p.FeedBytes([]byte(input)))
+
+			nodes, err := p.declNodes()
+			require.NoError(t, err)
+			var names []string
+			for _, n := range nodes {
+				names = append(names, n.name)
+			}
+			assert.Equal(t, tc.expected, names)
+		})
+	}
+}
+
+func TestOrderNodesCycle(t *testing.T) {
+	t.Parallel()
+
+	a := &node{name: "A", root: true, t: nodeTypeObject, children: []*node{
+		{name: "B", t: nodeTypeExtracted, externalTypeID: "B"},
+	}}
+	b := &node{name: "B", root: true, t: nodeTypeObject, children: []*node{
+		{name: "A", t: nodeTypeExtracted, externalTypeID: "A"},
+		{name: "C", t: nodeTypeExtracted, externalTypeID: "C"},
+	}}
+	c := &node{name: "C", root: true, t: nodeTypeString}
+
+	assert.Equal(t, []*node{a, b, c}, orderNodes([]*node{a, c, b}, TypeOrderReferencedAfter))
+	assert.Equal(t, []*node{c, b, a}, orderNodes([]*node{a, c, b}, TypeOrderReferencedBefore))
+}
+
+func TestTypeOrderRootDescription(t *testing.T) {
+	t.Parallel()
+
+	p := NewJSONParser("Doc",
+		OptExtractCommonTypes(true),
+		OptTypeOrder(TypeOrderReferencedBefore),
+		OptDescriptions(map[string]string{"$": "Doc is a document."}),
+	)
+	require.NoError(t, p.FeedBytes([]byte(`{"a":{"x":1},"b":{"x":2}}`)))
+	assert.Equal(t, "type X struct {\n\tX int `json:\"x\"`\n}\n\n// Doc is a document.\ntype Doc struct {\n\tA X `json:\"a\"`\n\tB X `json:\"b\"`\n}", p.String())
+}
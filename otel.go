@@ -0,0 +1,152 @@
+package json2go
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v2"
+)
+
+// OTelEmitter emits yaml attribute group of OpenTelemetry semantic conventions registry from IR, listing attributes
+// with their names, types and requirement levels, e.g. to formalize structure of json log or event payloads.
+//
+// Attributes are values of flat documents: keys of nested objects are joined with dots, like "user.id", as
+// namespaces of attribute names. Maps are template attributes, like "labels.<key>". Fields of pointers, optional
+// values, fields with omitempty and fields of such objects are recommended, other fields are required.
+// Values without attribute types, like arrays of objects or any values, fail with ErrUnsupportedShape.
+type OTelEmitter struct{}
+
+// otelAttribute is an attribute of attribute group.
+type otelAttribute struct {
+	id       string
+	typ      string
+	required bool
+	path     string
+}
+
+// Emit returns yaml document with attribute group of root struct type, with id like "registry.document".
+func (OTelEmitter) Emit(ir *Schema) ([]byte, error) {
+	if err := validateIR(ir); err != nil {
+		return nil, err
+	}
+	root := irElemType(ir, ir.Types[ir.Root])
+	if root.Kind == SchemaSlice {
+		// Array documents are lists of events.
+		root = irElemType(ir, root.Elem)
+	}
+	if root.Kind != SchemaStruct {
+		return nil, fmt.Errorf("%w: OpenTelemetry attributes need struct root type, not %s", ErrUnsupportedShape, root.Kind)
+	}
+
+	var attributes []otelAttribute
+	if err := otelAttributes(ir, root, "", rootPath, true, map[string]bool{ir.Root: true}, &attributes); err != nil {
+		return nil, err
+	}
+	items := make([]yaml.MapSlice, 0, len(attributes))
+	for _, a := range attributes {
+		level := "recommended"
+		if a.required {
+			level = "required"
+		}
+		items = append(items, yaml.MapSlice{
+			{Key: "id", Value: a.id},
+			{Key: "type", Value: a.typ},
+			{Key: "requirement_level", Value: level},
+			{Key: "stability", Value: "development"},
+			{Key: "brief", Value: "Value at " + a.path + "."},
+		})
+	}
+
+	return yaml.Marshal(yaml.MapSlice{{Key: "groups", Value: []yaml.MapSlice{{
+		{Key: "id", Value: "registry." + snakeCaseName(ir.Root)},
+		{Key: "type", Value: "attribute_group"},
+		{Key: "brief", Value: "Attributes of " + ir.Root + " documents."},
+		{Key: "attributes", Value: items},
+	}}}})
+}
+
+// otelAttributes appends attributes of fields of struct type, with names prefixed with prefix. Declared types being
+// expanded are kept in expanding, recursive references fail.
+func otelAttributes(ir *Schema, t *SchemaType, prefix, path string, required bool, expanding map[string]bool,
+	attributes *[]otelAttribute) error {
+	for _, f := range t.Fields {
+		fieldPath := childPath(path, f.Key)
+		fieldRequired := required && !f.OmitEmpty && !f.OmitZero
+		ft := f.Type
+		if ft.Kind == SchemaPointer || ft.Kind == SchemaOptional {
+			fieldRequired = false
+			ft = ft.Elem
+		}
+
+		name := ""
+		if ft.Kind == SchemaNamed {
+			name = ft.Name
+			ft = ir.Types[name]
+		}
+		if ft.Kind == SchemaStruct {
+			if expanding[name] {
+				return fmt.Errorf("%w: %s: recursive values have no OpenTelemetry attributes", ErrUnsupportedShape, fieldPath)
+			}
+			if name != "" {
+				expanding[name] = true
+			}
+			err := otelAttributes(ir, ft, prefix+f.Key+".", fieldPath, fieldRequired, expanding, attributes)
+			delete(expanding, name)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		id := prefix + f.Key
+		template := ft.Kind == SchemaMap
+		if template {
+			// Keys of map are suffixes of names of template attribute.
+			id += ".<key>"
+			ft = irElemType(ir, ft.Elem)
+			if ft.Kind == SchemaPointer || ft.Kind == SchemaOptional {
+				ft = irElemType(ir, ft.Elem)
+			}
+		}
+		typ, ok := otelType(ir, ft)
+		if !ok {
+			return fmt.Errorf("%w: %s: %s values have no OpenTelemetry attribute type", ErrUnsupportedShape, fieldPath, ft.Kind)
+		}
+		if template {
+			typ = "template[" + typ + "]"
+		}
+		*attributes = append(*attributes, otelAttribute{id: id, typ: typ, required: fieldRequired, path: fieldPath})
+	}
+	return nil
+}
+
+// otelType returns attribute type of scalar type, or of slice of scalars.
+func otelType(ir *Schema, t *SchemaType) (string, bool) {
+	switch t.Kind {
+	case SchemaBool:
+		return "boolean", true
+	case SchemaInt:
+		return "int", true
+	case SchemaFloat:
+		return "double", true
+	case SchemaString, SchemaTime:
+		return "string", true
+	case SchemaSlice:
+		elem := irElemType(ir, t.Elem)
+		if elem.Kind == SchemaPointer || elem.Kind == SchemaOptional {
+			elem = irElemType(ir, elem.Elem)
+		}
+		if elem.Kind == SchemaSlice {
+			return "", false
+		}
+		if typ, ok := otelType(ir, elem); ok {
+			return typ + "[]", true
+		}
+	}
+	return "", false
+}
+
+// OTelAttributes returns yaml attribute group of OpenTelemetry semantic conventions registry describing parsed
+// documents, see OTelEmitter.
+func (p *JSONParser) OTelAttributes() ([]byte, error) {
+	return OTelEmitter{}.Emit(p.Schema())
+}
@@ -0,0 +1,109 @@
+package json2go
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOTelAttributes(t *testing.T) {
+	t.Parallel()
+
+	p := NewJSONParser("LogEvent", OptExtractCommonTypes(true), OptMapAt("$.labels"))
+	require.NoError(t, p.FeedBytes([]byte(`{
+		"level": "info",
+		"time": "2021-01-01T00:00:00Z",
+		"duration": 1.5,
+		"http": {"method": "GET", "status": 200, "client": {"host": "a", "port": 1}},
+		"server": {"host": "b", "port": 2},
+		"tags": ["a"],
+		"retry": true,
+		"labels": {"app": "db", "env": "prod", "team": "x", "region": "eu", "zone": "a"}
+	}`)))
+	require.NoError(t, p.FeedBytes([]byte(`{
+		"level": "warn",
+		"time": "2021-01-01T00:00:01Z",
+		"duration": 2,
+		"http": {"method": "POST", "status": 500, "client": {"host": "c", "port": 3}},
+		"server": null,
+		"tags": [],
+		"labels": {"app": "db"}
+	}`)))
+
+	out, err := p.OTelAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, `groups:
+- id: registry.log_event
+  type: attribute_group
+  brief: Attributes of LogEvent documents.
+  attributes:
+  - id: duration
+    type: double
+    requirement_level: required
+    stability: development
+    brief: Value at $.duration.
+  - id: http.client.host
+    type: string
+    requirement_level: required
+    stability: development
+    brief: Value at $.http.client.host.
+  - id: http.client.port
+    type: int
+    requirement_level: required
+    stability: development
+    brief: Value at $.http.client.port.
+  - id: http.method
+    type: string
+    requirement_level: required
+    stability: development
+    brief: Value at $.http.method.
+  - id: http.status
+    type: int
+    requirement_level: required
+    stability: development
+    brief: Value at $.http.status.
+  - id: labels.<key>
+    type: template[string]
+    requirement_level: required
+    stability: development
+    brief: Value at $.labels.
+  - id: level
+    type: string
+    requirement_level: required
+    stability: development
+    brief: Value at $.level.
+  - id: retry
+    type: boolean
+    requirement_level: recommended
+    stability: development
+    brief: Value at $.retry.
+  - id: server.host
+    type: string
+    requirement_level: recommended
+    stability: development
+    brief: Value at $.server.host.
+  - id: server.port
+    type: int
+    requirement_level: recommended
+    stability: development
+    brief: Value at $.server.port.
+  - id: tags
+    type: string[]
+    requirement_level: required
+    stability: development
+    brief: Value at $.tags.
+  - id: time
+    type: string
+    requirement_level: required
+    stability: development
+    brief: Value at $.time.
+`, string(out))
+
+	p = NewJSONParser(baseTypeName)
+	require.NoError(t, p.FeedBytes([]byte(`{"id":1,"items":[{"name":"a"}]}`)))
+	_, err = p.OTelAttributes()
+	assert.True(t, errors.Is(err, ErrUnsupportedShape), err)
+	assert.EqualError(t, err, "unsupported shape: $.items: slice values have no OpenTelemetry attribute type")
+}
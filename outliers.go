@@ -0,0 +1,106 @@
+package json2go
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// maxOutlierIndices is a maximum number of indices of rejected elements listed in warning.
+const maxOutlierIndices = 10
+
+// rejectOutliers returns copy of json value with outliers removed from arrays: elements of kinds other than kind of
+// majority of elements, if there are at most tolerance fraction of them. Nulls aren't outliers. warn is called for
+// each array with rejected elements.
+func rejectOutliers(v interface{}, path string, tolerance float64, warn func(string)) interface{} {
+	switch typedValue := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(typedValue))
+		// Keys are sorted, so warnings are in the same order.
+		for _, k := range sortedKeys(typedValue) {
+			out[k] = rejectOutliers(typedValue[k], childPath(path, k), tolerance, warn)
+		}
+		return out
+	case duplicateValues:
+		out := make(duplicateValues, len(typedValue))
+		for i, el := range typedValue {
+			out[i] = rejectOutliers(el, path, tolerance, warn)
+		}
+		return out
+	case []interface{}:
+		outliers := arrayOutliers(typedValue, tolerance)
+		if len(outliers) > 0 {
+			warn(outliersWarning(typedValue, outliers, path))
+		}
+		out := make([]interface{}, 0, len(typedValue)-len(outliers))
+		for i, el := range typedValue {
+			if len(outliers) > 0 && outliers[0] == i {
+				outliers = outliers[1:]
+				continue
+			}
+			out = append(out, rejectOutliers(el, path, tolerance, warn))
+		}
+		return out
+	}
+
+	return v
+}
+
+// elementKind returns kind of array element, without kinds of elements of nested arrays.
+func elementKind(v interface{}) int {
+	if _, ok := v.([]interface{}); ok {
+		return kindArray
+	}
+	return valueKind(v)
+}
+
+// arrayOutliers returns sorted indices of outliers of array, or nil if elements of minority kinds are more than
+// tolerance fraction of non-null elements.
+func arrayOutliers(ar []interface{}, tolerance float64) []int {
+	counts := make(map[int]int)
+	total := 0
+	for _, el := range ar {
+		if el != nil {
+			counts[elementKind(el)]++
+			total++
+		}
+	}
+	if len(counts) < 2 {
+		return nil
+	}
+	majority := 0
+	for kind, count := range counts {
+		if count > counts[majority] || (count == counts[majority] && kind < majority) {
+			majority = kind
+		}
+	}
+	if float64(total-counts[majority]) > tolerance*float64(total) {
+		return nil
+	}
+
+	var outliers []int
+	for i, el := range ar {
+		if el != nil && elementKind(el) != majority {
+			outliers = append(outliers, i)
+		}
+	}
+	return outliers
+}
+
+// outliersWarning returns warning about outliers rejected from array at path.
+func outliersWarning(ar []interface{}, outliers []int, path string) string {
+	var indices []string
+	for _, i := range outliers {
+		if len(indices) == maxOutlierIndices {
+			indices = append(indices, "…")
+			break
+		}
+		indices = append(indices, strconv.Itoa(i))
+	}
+	at := "indices"
+	if len(outliers) == 1 {
+		at = "index"
+	}
+	return fmt.Sprintf("%s: %d of %d elements rejected as outliers of different kinds, at %s %s",
+		path, len(outliers), len(ar), at, strings.Join(indices, ", "))
+}
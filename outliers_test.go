@@ -0,0 +1,99 @@
+package json2go
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserOutlierTolerance(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name      string
+		tolerance float64
+		input     string
+		expected  string
+		warnings  []string
+	}{
+		{
+			name:     "disabled",
+			input:    `{"a":[1,2,3,"x"]}`,
+			expected: "[]interface{}",
+		},
+		{
+			name:      "string in ints",
+			tolerance: 0.25,
+			input:     `{"a":[1,2,3,"x"]}`,
+			expected:  "[]int",
+			warnings:  []string{"$.a: 1 of 4 elements rejected as outliers of different kinds, at index 3"},
+		},
+		{
+			name:      "too many outliers",
+			tolerance: 0.2,
+			input:     `{"a":[1,2,3,"x"]}`,
+			expected:  "[]interface{}",
+		},
+		{
+			name:      "nulls",
+			tolerance: 0.25,
+			input:     `{"a":[null,1,null,2,true,3]}`,
+			expected:  "[]*int",
+			warnings:  []string{"$.a: 1 of 6 elements rejected as outliers of different kinds, at index 4"},
+		},
+		{
+			name:      "nested arrays",
+			tolerance: 0.5,
+			input:     `{"a":[[1,"x",2],[3],4]}`,
+			expected:  "[][]int",
+			warnings: []string{
+				"$.a: 1 of 3 elements rejected as outliers of different kinds, at index 2",
+				"$.a: 1 of 3 elements rejected as outliers of different kinds, at index 1",
+			},
+		},
+		{
+			name:      "objects",
+			tolerance: 0.5,
+			input:     `{"a":[{"id":1},"x",{"id":2}]}`,
+			expected:  "[]struct{...}",
+			warnings:  []string{"$.a: 1 of 3 elements rejected as outliers of different kinds, at index 1"},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			parser := NewJSONParser(baseTypeName, OptOutlierTolerance(tc.tolerance))
+			require.NoError(t, parser.FeedBytes([]byte(tc.input)))
+
+			fields := parser.Fields()
+			require.NotEmpty(t, fields)
+			assert.Equal(t, tc.expected, fields[0].Type)
+			assert.Equal(t, tc.warnings, parser.Warnings())
+		})
+	}
+}
+
+func TestArrayOutliersIndices(t *testing.T) {
+	t.Parallel()
+
+	ar := make([]interface{}, 1000)
+	for i := range ar {
+		ar[i] = float64(i)
+	}
+	for i := 0; i < 12; i++ {
+		ar[i*10] = "x"
+	}
+
+	var warnings []string
+	out := rejectOutliers(ar, rootPath, 0.05, func(msg string) {
+		warnings = append(warnings, msg)
+	})
+	assert.Len(t, out, 988)
+	assert.Equal(t, []string{
+		"$: 12 of 1000 elements rejected as outliers of different kinds, at indices 0, 10, 20, 30, 40, 50, 60, 70, 80, 90, …",
+	}, warnings)
+}
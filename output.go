@@ -0,0 +1,221 @@
+package json2go
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// baseOutputTemplate defines templates rendering output, that can be redefined by user template.
+const baseOutputTemplate = `
+{{- define "header"}}{{if .Header}}{{.Header}}
+{{end}}{{end}}
+{{- define "type"}}{{.Decl}}{{if .Methods}}
+
+{{.Methods}}{{end}}{{end}}
+{{- define "methods"}}{{end}}
+{{- template "header" .}}
+{{range .Types}}
+{{template "type" .}}
+
+{{template "methods" .}}
+{{end}}
+{{.Helpers}}`
+
+// outputTemplateFuncs are functions available in output templates.
+var outputTemplateFuncs = template.FuncMap{
+	"snake": snakeCaseName,
+	"camel": lowerCamelCaseName,
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"join":  strings.Join,
+}
+
+// OutputData is a description of generated code, used to execute output template set with OptOutputTemplate.
+type OutputData struct {
+	// Header is a comment header set with OptHeader, empty if it's disabled.
+	Header string
+	// Imports are packages used in generated code.
+	Imports []string
+	// ImportSpecs are imports of packages used in generated code, with aliases if needed.
+	ImportSpecs []string
+	// Types are generated types, root type first.
+	Types []OutputType
+	// Helpers are declarations of helper types and functions, shared by generated types.
+	Helpers string
+	// Code is the whole generated code, rendered without template.
+	Code string
+}
+
+// OutputType is a description of generated type.
+type OutputType struct {
+	// Name is a type name.
+	Name string
+	// Path is a json path of values of the type, like "$" for root type.
+	Path string
+	// Kind is "struct", "array", "map" or "other".
+	Kind string
+	// Decl is a type declaration, with doc comment.
+	Decl string
+	// Methods are declarations of methods generated for the type, like MarshalJSON.
+	Methods string
+	// Fields are struct fields, empty for other kinds.
+	Fields []OutputField
+}
+
+// OutputField is a description of struct field.
+type OutputField struct {
+	// Name is a go field name.
+	Name string
+	// Key is a json key, empty for fields without json representation.
+	Key string
+	// Path is a json path of the field, like "$.user.id".
+	Path string
+	// Type is a go type of the field.
+	Type string
+	// Tag is a struct tag, without quotes.
+	Tag string
+	// Required is true if key was present in all objects.
+	Required bool
+	// Nullable is true if value was null in any of objects.
+	Nullable bool
+}
+
+// OptOutputTemplate sets text/template customizing rendered code, executed with OutputData.
+// Template may redefine "header" (beginning of output, rendering header set with OptHeader), "type" (executed for each OutputType, renders declaration and methods),
+// and "methods" (executed for each OutputType, renders extra methods) templates, or render whole output.
+// Functions snake, camel, lower, upper and join are available. Output has to be valid go code, it's formatted with gofmt.
+// If template is invalid, Generate returns error. Empty template means default output.
+func OptOutputTemplate(tmpl string) JSONParserOpt {
+	return func(o *options) {
+		o.outputTemplate, o.outputTemplateErr = nil, nil
+		if tmpl == "" {
+			return
+		}
+		t := template.Must(template.New("output").Funcs(outputTemplateFuncs).Parse(baseOutputTemplate))
+		o.outputTemplate, o.outputTemplateErr = t.Parse(tmpl)
+		if o.outputTemplateErr != nil {
+			o.outputTemplate = nil
+		}
+	}
+}
+
+// renderOutput returns code of declarations generated for nodes, rendered with output template.
+func renderOutput(nodes []*node, decls []ast.Decl, code, header string, ctx *astContext, t *template.Template) (string, error) {
+	data := OutputData{
+		Header:      header,
+		Imports:     ctx.importsList(),
+		ImportSpecs: ctx.importSpecs(),
+		Code:        code,
+	}
+
+	types := make(map[string]int)
+	for _, n := range nodes {
+		types[n.name] = len(data.Types)
+		data.Types = append(data.Types, OutputType{Name: n.name, Path: n.path, Kind: "other"})
+	}
+
+	methods := make([][]ast.Decl, len(data.Types))
+	var helpers []ast.Decl
+	for _, decl := range decls {
+		switch d := decl.(type) {
+		case *ast.GenDecl:
+			if ts, ok := astTypeSpec(d); ok {
+				if i, ok := types[ts.Name.Name]; ok {
+					data.Types[i].Decl = astPrintDecls([]ast.Decl{d})
+					data.Types[i].Kind, data.Types[i].Fields = outputTypeKind(ts.Type, nodes[i])
+					continue
+				}
+			}
+		case *ast.FuncDecl:
+			if i, ok := types[astReceiverTypeName(d)]; ok {
+				methods[i] = append(methods[i], d)
+				continue
+			}
+		}
+		helpers = append(helpers, decl)
+	}
+	for i := range data.Types {
+		data.Types[i].Methods = astPrintDecls(methods[i])
+	}
+	data.Helpers = astPrintDecls(helpers)
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return code, fmt.Errorf("executing output template: %w", err)
+	}
+	out, err := format.Source(buf.Bytes())
+	if err != nil {
+		return buf.String(), fmt.Errorf("output template rendered invalid go code: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// astTypeSpec returns spec of declaration of single type.
+func astTypeSpec(d *ast.GenDecl) (*ast.TypeSpec, bool) {
+	if d.Tok != token.TYPE || len(d.Specs) != 1 {
+		return nil, false
+	}
+	ts, ok := d.Specs[0].(*ast.TypeSpec)
+	return ts, ok
+}
+
+// astReceiverTypeName returns name of method receiver type, or empty string for functions.
+func astReceiverTypeName(d *ast.FuncDecl) string {
+	if d.Recv == nil || len(d.Recv.List) == 0 {
+		return ""
+	}
+	t := d.Recv.List[0].Type
+	if star, ok := t.(*ast.StarExpr); ok {
+		t = star.X
+	}
+	if ident, ok := t.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+// outputTypeKind returns kind of type expression, and fields of struct types.
+func outputTypeKind(expr ast.Expr, n *node) (string, []OutputField) {
+	switch t := expr.(type) {
+	case *ast.ArrayType:
+		return "array", nil
+	case *ast.MapType:
+		return "map", nil
+	case *ast.StructType:
+		children := make(map[string]*node, len(n.children))
+		for _, c := range n.children {
+			children[c.name] = c
+		}
+
+		var fields []OutputField
+		for _, f := range t.Fields.List {
+			for _, name := range f.Names {
+				if name.Name == commentedFieldName {
+					continue
+				}
+				of := OutputField{
+					Name: name.Name,
+					Type: astExprString(f.Type),
+				}
+				if f.Tag != nil {
+					of.Tag, _ = strconv.Unquote(f.Tag.Value)
+				}
+				if c, ok := children[name.Name]; ok {
+					of.Key = c.key
+					of.Path = c.path
+					of.Required = c.required
+					of.Nullable = c.nullable
+				}
+				fields = append(fields, of)
+			}
+		}
+		return "struct", fields
+	}
+	return "other", nil
+}
@@ -0,0 +1,63 @@
+package json2go
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserOutputTemplate(t *testing.T) {
+	t.Parallel()
+
+	input := `{"id":1,"user":{"name":"x"},"tags":["a"]}`
+
+	tmpl := `
+{{- define "header"}}// Code generated by json2go. DO NOT EDIT.
+// Imports: {{join .Imports ", "}}
+{{end}}
+{{- define "methods"}}{{if eq .Kind "struct"}}
+// Columns returns column names of {{.Name}}.
+func ({{.Name}}) Columns() []string {
+	return []string{ {{- range $i, $f := .Fields}}{{if $i}}, {{end}}"{{snake $f.Name}}"{{end -}} }
+}
+{{end}}{{end}}`
+
+	parser := NewJSONParser(baseTypeName, OptOutputTemplate(tmpl), OptStringMethods(true))
+	require.NoError(t, parser.FeedBytes([]byte(input)))
+
+	out, err := parser.Generate()
+	require.NoError(t, err)
+	assert.Contains(t, out, "// Code generated by json2go. DO NOT EDIT.\n// Imports: fmt, path, reflect, sort, strings\n\ntype Document struct {")
+	assert.Contains(t, out, `
+// Columns returns column names of Document.
+func (Document) Columns() []string {
+	return []string{"id", "tags", "user"}
+}`)
+	assert.Contains(t, out, "func (v Document) String() string {")
+	assert.Contains(t, out, "func redactedString(")
+	assert.Equal(t, out, parser.String())
+
+	// Whole output can be rendered by template.
+	parser = NewJSONParser(baseTypeName, OptOutputTemplate(
+		`{{range .Types}}{{range .Fields}}// {{.Name}} {{.Path}} {{.Required}} {{.Tag}}{{if eq .Name "Tags"}} {{.Type}}{{end}}
+{{end}}{{end}}`,
+	))
+	require.NoError(t, parser.FeedBytes([]byte(input)))
+	out, err = parser.Generate()
+	require.NoError(t, err)
+	assert.Equal(t, `// ID $.id true json:"id"
+// Tags $.tags true json:"tags" []string
+// User $.user true json:"user"`, out)
+}
+
+func TestParserOutputTemplateErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, tmpl := range []string{`{{`, `{{.Missing}}`, `type {`} {
+		parser := NewJSONParser(baseTypeName, OptOutputTemplate(tmpl))
+		require.NoError(t, parser.FeedBytes([]byte(`{"id":1}`)))
+		_, err := parser.Generate()
+		assert.Error(t, err, tmpl)
+	}
+}
@@ -0,0 +1,137 @@
+package json2go
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Kinds of values that can be forced with Overrides.
+var overrideKinds = map[string]nodeType{
+	"bool":   nodeTypeBool,
+	"int":    nodeTypeInt,
+	"float":  nodeTypeFloat,
+	"string": nodeTypeString,
+	"time":   nodeTypeTime,
+	"any":    nodeTypeInterface,
+	"raw":    nodeTypeRawMessage,
+	"map":    nodeTypeMap,
+}
+
+// Overrides are choices overriding inferred types, by json path, like "$.user.id".
+type Overrides struct {
+	// Exclude are paths of attributes left out of generated types.
+	Exclude []string `json:"exclude,omitempty" yaml:"exclude,omitempty"`
+	// Types are kinds of values: bool, int, float, string, time, any, raw (json.RawMessage) or map,
+	// or custom go types qualified with import path, like "github.com/google/uuid.UUID" or "time.Duration".
+	// Array levels of values are kept.
+	Types map[string]string `json:"types,omitempty" yaml:"types,omitempty"`
+	// Pointers force pointer (true) or value (false) types of attributes.
+	// With OptOptionalType, true means optional type.
+	Pointers map[string]bool `json:"pointers,omitempty" yaml:"pointers,omitempty"`
+}
+
+// validate returns error if overrides use unknown kinds.
+func (o Overrides) validate() error {
+	var invalid []string
+	for path, kind := range o.Types {
+		if _, ok := overrideKinds[kind]; !ok && !isGoType(kind) {
+			invalid = append(invalid, fmt.Sprintf("%s: %q", path, kind))
+		}
+	}
+	if len(invalid) > 0 {
+		sort.Strings(invalid)
+		return fmt.Errorf("invalid type overrides, allowed types are bool, int, float, string, time, any, raw, map and qualified go types: %s",
+			strings.Join(invalid, ", "))
+	}
+	return nil
+}
+
+// apply applies overrides to node subtree.
+func (o Overrides) apply(n *node) {
+	exclude := make(map[string]bool, len(o.Exclude))
+	for _, p := range o.Exclude {
+		exclude[p] = true
+	}
+	o.applyTo(n, exclude)
+}
+
+func (o Overrides) applyTo(n *node, exclude map[string]bool) {
+	if kind, ok := o.Types[n.path]; ok && isGoType(kind) {
+		forceGoType(n, kind)
+	} else if ok {
+		forceKind(n, overrideKinds[kind])
+	}
+	if v, ok := o.Pointers[n.path]; ok {
+		n.pointer = &v
+	}
+
+	children := n.children[:0]
+	for _, c := range n.children {
+		if exclude[c.path] && c.key != "" {
+			continue
+		}
+		o.applyTo(c, exclude)
+		children = append(children, c)
+	}
+	n.children = children
+}
+
+// forceKind changes type of node values.
+func forceKind(n *node, t nodeType) {
+	if t == nil {
+		return
+	}
+
+	if t == nodeTypeMap {
+		if n.t == nodeTypeMap {
+			return
+		}
+		value := newNode("")
+		value.t = nodeTypeInterface
+		if merged, err := mergeNodes(n.children); len(n.children) > 0 && err == nil {
+			value = merged
+			value.key = ""
+			value.name = ""
+			value.required = true
+		}
+		value.setPath(childPath(n.path, mapValuePathKey))
+		n.mapKeyType = mapKeyTypeFromNodes(n.children)
+		n.children = []*node{value}
+		n.t = nodeTypeMap
+		return
+	}
+
+	n.t = t
+	n.children = nil
+	n.tuple = nil
+	n.keyOrder = nil
+}
+
+// forceGoType changes type of node values to custom go type, like "github.com/google/uuid.UUID".
+func forceGoType(n *node, goType string) {
+	n.t = nodeTypeExtracted
+	n.externalTypeID = goType
+	n.children = nil
+	n.tuple = nil
+	n.keyOrder = nil
+}
+
+// forcePresence sets requiredness and nullability of nodes in subtree, by path.
+func forcePresence(n *node, required, nullable map[string]bool) {
+	if len(required) == 0 && len(nullable) == 0 {
+		return
+	}
+
+	if !n.root {
+		if v, ok := required[n.path]; ok {
+			n.required = v
+		}
+		if nullable[n.path] {
+			n.nullable = true
+		}
+	}
+	for _, c := range n.children {
+		forcePresence(c, required, nullable)
+	}
+}
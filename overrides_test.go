@@ -0,0 +1,77 @@
+package json2go
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserOverrides(t *testing.T) {
+	t.Parallel()
+
+	input := `{"id":1,"name":"x","debug":{"trace":"t"},"score":2,"meta":{"a":1,"b":2},"tags":["a"],"note":null}`
+
+	parser := NewJSONParser(baseTypeName, OptOverrides(Overrides{
+		Exclude:  []string{"$.debug"},
+		Types:    map[string]string{"$.score": "float", "$.meta": "map", "$.tags": "any", "$.note": "string"},
+		Pointers: map[string]bool{"$.name": true, "$.note": false},
+	}))
+	require.NoError(t, parser.FeedBytes([]byte(input)))
+
+	out, err := parser.Generate()
+	require.NoError(t, err)
+	assert.Equal(t, `type Document struct {
+	ID    int            `+"`json:\"id\"`"+`
+	Meta  map[string]int `+"`json:\"meta\"`"+`
+	Name  *string        `+"`json:\"name\"`"+`
+	Note  string         `+"`json:\"note\"`"+`
+	Score float64        `+"`json:\"score\"`"+`
+	Tags  []interface{}  `+"`json:\"tags\"`"+`
+}`, out)
+
+	parser = NewJSONParser(baseTypeName, OptOverrides(Overrides{Types: map[string]string{"$.id": "int64"}}))
+	require.NoError(t, parser.FeedBytes([]byte(input)))
+	_, err = parser.Generate()
+	assert.EqualError(t, err, `invalid type overrides, allowed types are bool, int, float, string, time, any, raw, map and qualified go types: $.id: "int64"`)
+}
+
+func TestParserFields(t *testing.T) {
+	t.Parallel()
+
+	parser := NewJSONParser(baseTypeName, OptMakeMaps(true, 2))
+	require.NoError(t, parser.FeedBytes([]byte(`{"id":1,"user":{"name":"x"},"items":[{"n":1}],"counts":{"a":1,"b":2}}`)))
+	require.NoError(t, parser.FeedBytes([]byte(`{"id":2,"user":null,"items":[],"counts":{}}`)))
+
+	assert.Equal(t, []Field{
+		{Path: "$.counts", Owner: "Document", Name: "Counts", Type: "map[string]int", Required: true},
+		{Path: "$.counts.*", Owner: "Document", Type: "int", Required: true},
+		{Path: "$.id", Owner: "Document", Name: "ID", Type: "int", Required: true},
+		{Path: "$.items", Owner: "Document", Name: "Items", Type: "[]struct{...}", Required: true},
+		{Path: "$.items.n", Owner: "Document", Name: "N", Type: "int", Required: true},
+		{Path: "$.user", Owner: "Document", Name: "User", Type: "*struct{...}", Required: true, Nullable: true},
+		{Path: "$.user.name", Owner: "Document", Name: "Name", Type: "string", Required: true},
+	}, parser.Fields())
+}
+
+func TestParserForcedPresence(t *testing.T) {
+	t.Parallel()
+
+	parser := NewJSONParser(baseTypeName,
+		OptStringPointersWhenKeyMissing(true),
+		OptForceRequired("$.name", "$.user.email"),
+		OptForceOptional("$.id", "$.user.email"),
+		OptForceNullable("$.user", "$.count"),
+	)
+	require.NoError(t, parser.FeedBytes([]byte(`{"id":1,"count":2,"user":{"email":"e"}}`)))
+	require.NoError(t, parser.FeedBytes([]byte(`{"id":2,"count":3,"name":"x","user":{"email":"f"}}`)))
+
+	assert.Equal(t, `type Document struct {
+	Count *int   `+"`json:\"count\"`"+`
+	ID    *int   `+"`json:\"id,omitempty\"`"+`
+	Name  string `+"`json:\"name\"`"+`
+	User  *struct {
+		Email *string `+"`json:\"email,omitempty\"`"+`
+	} `+"`json:\"user\"`"+`
+}`, parser.String())
+}
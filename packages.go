@@ -0,0 +1,337 @@
+package json2go
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"path"
+	"sort"
+	"strings"
+)
+
+// goPackagesFile is a name of go file of each package emitted by GoPackagesEmitter.
+const goPackagesFile = "types.go"
+
+// GoPackagesEmitter emits go types described by IR in several packages, so types of large APIs aren't declared in
+// one package everything depends on. Types of values at json paths with prefixes of Packages are declared in their
+// packages, and other packages refer to them with qualified names, like billing.Invoice.
+//
+// Inline structs of values at prefixes get declared types, named like their fields. Types of values at several paths
+// belong to package of the first path they are found at, fields are visited in their order. Packages can't refer
+// to each other, like package of prefix referring to type of root package, such schemas fail with
+// ErrUnsupportedShape.
+type GoPackagesEmitter struct {
+	// Module is an import path of root package, like "example.com/api". Root package is named by its last element.
+	Module string
+	// Packages maps json path prefixes, like "$.billing" or "$.billing.*", to directories of packages relative to
+	// root package, like "billing". Types belong to package of the longest prefix of their path.
+	Packages map[string]string
+}
+
+// goPackages assigns declared types of IR to packages. Packages are identified by directories, "" is root package.
+type goPackages struct {
+	ir *Schema
+	// prefixes are json path prefixes of packages, the longest first.
+	prefixes []string
+	// dirs are directories of packages, by prefixes.
+	dirs map[string]string
+	// types are directories of packages of declared types, by names.
+	types map[string]string
+}
+
+// EmitFiles returns go files of packages, by paths relative to directory of root package, like "billing/types.go".
+func (e GoPackagesEmitter) EmitFiles(ir *Schema) (map[string][]byte, error) {
+	if err := validateIR(ir); err != nil {
+		return nil, err
+	}
+	if e.Module == "" {
+		return nil, fmt.Errorf("no import path of root package")
+	}
+	// Declared types are changed, so they are copied first.
+	data, err := MarshalIR(ir)
+	if err != nil {
+		return nil, err
+	}
+	if ir, err = UnmarshalIR(data); err != nil {
+		return nil, err
+	}
+
+	p := &goPackages{ir: ir, dirs: make(map[string]string), types: make(map[string]string)}
+	names := map[string]string{path.Base(e.Module): ""}
+	for prefix, dir := range e.Packages {
+		dir = path.Clean(dir)
+		if dir == "." || path.IsAbs(dir) || strings.HasPrefix(dir, "..") {
+			return nil, fmt.Errorf("invalid directory of package of %s: %q", prefix, dir)
+		}
+		prefix = strings.TrimSuffix(prefix, ".*")
+		p.prefixes = append(p.prefixes, prefix)
+		p.dirs[prefix] = dir
+	}
+	sort.Slice(p.prefixes, func(i, j int) bool {
+		if len(p.prefixes[i]) != len(p.prefixes[j]) {
+			return len(p.prefixes[i]) > len(p.prefixes[j])
+		}
+		return p.prefixes[i] < p.prefixes[j]
+	})
+	for _, prefix := range p.prefixes {
+		dir := p.dirs[prefix]
+		name := path.Base(dir)
+		if other, ok := names[name]; ok && other != dir {
+			return nil, fmt.Errorf("packages %s and %s have the same name", e.packagePath(other), e.packagePath(dir))
+		}
+		names[name] = dir
+	}
+	for name, dir := range names {
+		if !token.IsIdentifier(name) {
+			return nil, fmt.Errorf("invalid name of package %s: %q", e.packagePath(dir), name)
+		}
+	}
+
+	p.assign(ir.Root, rootPath)
+	for name := range ir.Types {
+		if _, ok := p.types[name]; !ok {
+			// Types not referenced from root type belong to root package.
+			p.types[name] = ""
+		}
+	}
+	if err := p.checkCycles(e); err != nil {
+		return nil, err
+	}
+
+	files := make(map[string][]byte)
+	dirs := map[string]bool{"": true}
+	for _, dir := range p.dirs {
+		dirs[dir] = true
+	}
+	for dir := range dirs {
+		src, err := p.emit(e, dir)
+		if err != nil {
+			return nil, err
+		}
+		if src != nil {
+			files[path.Join(dir, goPackagesFile)] = src
+		}
+	}
+	return files, nil
+}
+
+// packagePath returns import path of package in directory.
+func (e GoPackagesEmitter) packagePath(dir string) string {
+	if dir == "" {
+		return e.Module
+	}
+	return e.Module + "/" + dir
+}
+
+// packageOf returns directory of package of values at path.
+func (p *goPackages) packageOf(valuePath string) string {
+	for _, prefix := range p.prefixes {
+		if valuePath == prefix || strings.HasPrefix(valuePath, prefix+".") {
+			return p.dirs[prefix]
+		}
+	}
+	return ""
+}
+
+// assign assigns declared type, and types it refers to, to packages, unless it's already assigned.
+func (p *goPackages) assign(name, valuePath string) {
+	if _, ok := p.types[name]; ok {
+		return
+	}
+	p.types[name] = p.packageOf(valuePath)
+	p.assignType(p.ir.Types[name], valuePath)
+}
+
+func (p *goPackages) assignType(t *SchemaType, valuePath string) {
+	switch t.Kind {
+	case SchemaNamed:
+		p.assign(t.Name, valuePath)
+	case SchemaPointer, SchemaOptional, SchemaSlice, SchemaMap:
+		// Elements of arrays and values of maps have path of their container.
+		p.assignType(t.Elem, valuePath)
+	case SchemaStruct:
+		for i := range t.Fields {
+			f := &t.Fields[i]
+			fieldPath := childPath(valuePath, f.Key)
+			if _, ok := p.dirs[fieldPath]; ok {
+				p.declare(f)
+			}
+			p.assignType(f.Type, fieldPath)
+		}
+	}
+}
+
+// declare replaces inline struct of field, or struct of its elements, with declared type named like field.
+func (p *goPackages) declare(f *SchemaField) {
+	t := &f.Type
+	for (*t).Kind == SchemaPointer || (*t).Kind == SchemaOptional || (*t).Kind == SchemaSlice || (*t).Kind == SchemaMap {
+		t = &(*t).Elem
+	}
+	if (*t).Kind != SchemaStruct {
+		return
+	}
+	name := f.Name
+	for _, taken := p.ir.Types[name]; taken; _, taken = p.ir.Types[name] {
+		name = nextName(name)
+	}
+	p.ir.Types[name] = *t
+	*t = &SchemaType{Kind: SchemaNamed, Name: name}
+}
+
+// schemaTypeReferences calls f with names of declared types type refers to.
+func schemaTypeReferences(t *SchemaType, f func(name string)) {
+	if t == nil {
+		return
+	}
+	if t.Kind == SchemaNamed {
+		f(t.Name)
+	}
+	schemaTypeReferences(t.Elem, f)
+	for _, field := range t.Fields {
+		schemaTypeReferences(field.Type, f)
+	}
+}
+
+// checkCycles checks if packages don't refer to each other.
+func (p *goPackages) checkCycles(e GoPackagesEmitter) error {
+	imports := make(map[string]map[string]bool)
+	for name, dir := range p.types {
+		schemaTypeReferences(p.ir.Types[name], func(ref string) {
+			if other := p.types[ref]; other != dir {
+				if imports[dir] == nil {
+					imports[dir] = make(map[string]bool)
+				}
+				imports[dir][other] = true
+			}
+		})
+	}
+
+	var dirs []string
+	for dir := range imports {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	// Packages are visited in depth-first order, packages on stack are 1, finished packages are 2.
+	state := make(map[string]int)
+	var visit func(dir string) error
+	visit = func(dir string) error {
+		state[dir] = 1
+		var deps []string
+		for dep := range imports[dir] {
+			deps = append(deps, dep)
+		}
+		sort.Strings(deps)
+		for _, dep := range deps {
+			switch state[dep] {
+			case 1:
+				return fmt.Errorf("%w: types of packages %s and %s refer to each other", ErrUnsupportedShape,
+					e.packagePath(dir), e.packagePath(dep))
+			case 0:
+				if err := visit(dep); err != nil {
+					return err
+				}
+			}
+		}
+		state[dir] = 2
+		return nil
+	}
+	for _, dir := range dirs {
+		if state[dir] == 0 {
+			if err := visit(dir); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// emit returns go file of package in directory, or nil if package has no types.
+func (p *goPackages) emit(e GoPackagesEmitter, dir string) ([]byte, error) {
+	var names []string
+	for name, typeDir := range p.types {
+		if typeDir == dir && name != p.ir.Root {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	if p.types[p.ir.Root] == dir {
+		names = append([]string{p.ir.Root}, names...)
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	imports := make(map[string]bool)
+	var qualify func(t *SchemaType)
+	qualify = func(t *SchemaType) {
+		if t == nil {
+			return
+		}
+		switch t.Kind {
+		case SchemaTime:
+			imports["time"] = true
+		case SchemaOpaque:
+			imports["encoding/json"] = true
+		case SchemaNamed:
+			if other := p.types[t.Name]; other != dir {
+				imports[e.packagePath(other)] = true
+				t.Name = path.Base(e.packagePath(other)) + "." + t.Name
+			}
+		}
+		qualify(t.Elem)
+		for _, f := range t.Fields {
+			qualify(f.Type)
+		}
+	}
+	for _, name := range names {
+		qualify(p.ir.Types[name])
+	}
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "package %s\n\n", path.Base(e.packagePath(dir)))
+	if len(imports) == 1 {
+		for imp := range imports {
+			fmt.Fprintf(&src, "import %q\n\n", imp)
+		}
+	} else if len(imports) > 0 {
+		var std, module []string
+		for imp := range imports {
+			if strings.HasPrefix(imp, e.Module+"/") {
+				module = append(module, imp)
+			} else {
+				std = append(std, imp)
+			}
+		}
+		sort.Strings(std)
+		sort.Strings(module)
+		src.WriteString("import (\n")
+		for _, imp := range std {
+			fmt.Fprintf(&src, "%q\n", imp)
+		}
+		if len(std) > 0 && len(module) > 0 {
+			src.WriteString("\n")
+		}
+		for _, imp := range module {
+			fmt.Fprintf(&src, "%q\n", imp)
+		}
+		src.WriteString(")\n\n")
+	}
+	for i, name := range names {
+		if i > 0 {
+			src.WriteString("\n")
+		}
+		fmt.Fprintf(&src, "type %s %s\n", name, goTypeOfSchemaType(p.ir.Types[name]))
+	}
+	out, err := format.Source(src.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("%w: formatting go types: %v", ErrInternal, err)
+	}
+	return out, nil
+}
+
+// GeneratePackages returns go files of parsed types declared in several packages, by paths relative to directory of
+// root package, see GoPackagesEmitter.
+func (p *JSONParser) GeneratePackages(module string, packages map[string]string) (map[string][]byte, error) {
+	return GoPackagesEmitter{Module: module, Packages: packages}.EmitFiles(p.Schema())
+}
@@ -0,0 +1,91 @@
+package json2go
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeneratePackages(t *testing.T) {
+	t.Parallel()
+
+	p := NewJSONParser("Account", OptExtractCommonTypes(true))
+	require.NoError(t, p.FeedBytes([]byte(`{
+		"id": 1,
+		"billing": {"plan": "pro", "invoices": [{"total": 9.5, "issued": "2021-01-01T00:00:00Z"}], "address": {"city": "a"}},
+		"users": [{"name": "a", "address": {"city": "b"}}]
+	}`)))
+	files, err := p.GeneratePackages("example.com/api", map[string]string{
+		"$.billing.*": "billing",
+		"$.users":     "identity/users",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"types.go": `package api
+
+import (
+	"example.com/api/billing"
+	"example.com/api/identity/users"
+)
+
+type Account struct {
+	Billing billing.Billing ` + "`json:\"billing\"`" + `
+	ID      int             ` + "`json:\"id\"`" + `
+	Users   []users.Users   ` + "`json:\"users\"`" + `
+}
+`,
+		"billing/types.go": `package billing
+
+import "time"
+
+type Address struct {
+	City string ` + "`json:\"city\"`" + `
+}
+
+type Billing struct {
+	Address  Address ` + "`json:\"address\"`" + `
+	Invoices []struct {
+		Issued time.Time ` + "`json:\"issued\"`" + `
+		Total  float64   ` + "`json:\"total\"`" + `
+	} ` + "`json:\"invoices\"`" + `
+	Plan string ` + "`json:\"plan\"`" + `
+}
+`,
+		"identity/users/types.go": `package users
+
+import "example.com/api/billing"
+
+type Users struct {
+	Address billing.Address ` + "`json:\"address\"`" + `
+	Name    string          ` + "`json:\"name\"`" + `
+}
+`,
+	}, stringFiles(files))
+}
+
+func TestGeneratePackagesErrors(t *testing.T) {
+	t.Parallel()
+
+	p := NewJSONParser("Document", OptExtractCommonTypes(true))
+	require.NoError(t, p.FeedBytes([]byte(`{"a":{"x":{"id":1},"z":{"ok":true}},"b":{"y":{"id":2},"w":{"ok":false}}}`)))
+
+	_, err := p.GeneratePackages("example.com/api", map[string]string{"$.a": "a", "$.a.x": "b", "$.b": "b"})
+	assert.True(t, errors.Is(err, ErrUnsupportedShape), err)
+	_, err = p.GeneratePackages("example.com/api", map[string]string{"$.a": "x/a", "$.b": "y/a"})
+	assert.EqualError(t, err, "packages example.com/api/x/a and example.com/api/y/a have the same name")
+	_, err = p.GeneratePackages("example.com/api", map[string]string{"$.a": "../a"})
+	assert.Error(t, err)
+	_, err = p.GeneratePackages("", nil)
+	assert.Error(t, err)
+}
+
+// stringFiles returns contents of files as strings.
+func stringFiles(files map[string][]byte) map[string]string {
+	result := make(map[string]string)
+	for name, data := range files {
+		result[name] = string(data)
+	}
+	return result
+}
@@ -0,0 +1,129 @@
+package json2go
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParquetEmitter emits Parquet message type in schema text format of parquet-mr, like "message Document { ... }",
+// from IR of root struct type.
+//
+// Slices are LIST groups and maps are MAP groups of three-level structure of Parquet specification, structs are
+// groups and declared types are inlined. Fields of pointers, optional values or with omitempty are optional, other
+// fields are required. Times are int64 timestamps in microseconds. Any values, opaque values and recursive values
+// are binary JSON.
+type ParquetEmitter struct{}
+
+// Emit returns Parquet message type of root struct type.
+func (ParquetEmitter) Emit(ir *Schema) ([]byte, error) {
+	if err := validateIR(ir); err != nil {
+		return nil, err
+	}
+	root := irElemType(ir, ir.Types[ir.Root])
+	if root.Kind == SchemaSlice {
+		// Array documents are written as rows.
+		root = irElemType(ir, root.Elem)
+	}
+	if root.Kind != SchemaStruct {
+		return nil, fmt.Errorf("%w: Parquet schema needs struct root type, not %s", ErrUnsupportedShape, root.Kind)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "message %s {\n", parquetName(ir.Root))
+	parquetFields(&b, ir, root, "  ", map[string]bool{ir.Root: true})
+	b.WriteString("}\n")
+	return []byte(b.String()), nil
+}
+
+// parquetFields writes fields of struct type with indentation.
+func parquetFields(b *strings.Builder, ir *Schema, t *SchemaType, indent string, expanding map[string]bool) {
+	for _, f := range t.Fields {
+		repetition := "required"
+		if f.OmitEmpty || f.OmitZero {
+			repetition = "optional"
+		}
+		parquetField(b, ir, f.Type, repetition, parquetName(f.Key), indent, expanding)
+	}
+}
+
+// parquetField writes field of type with name, repetition and indentation.
+func parquetField(b *strings.Builder, ir *Schema, t *SchemaType, repetition, name, indent string, expanding map[string]bool) {
+	primitive := func(typ, annotation string) {
+		fmt.Fprintf(b, "%s%s %s %s%s;\n", indent, repetition, typ, name, annotation)
+	}
+	switch t.Kind {
+	case SchemaBool:
+		primitive("boolean", "")
+		return
+	case SchemaInt:
+		primitive("int64", "")
+		return
+	case SchemaFloat:
+		if t.Bits == 32 {
+			primitive("float", "")
+		} else {
+			primitive("double", "")
+		}
+		return
+	case SchemaString:
+		primitive("binary", " (STRING)")
+		return
+	case SchemaTime:
+		primitive("int64", " (TIMESTAMP(MICROS,true))")
+		return
+	case SchemaPointer, SchemaOptional:
+		parquetField(b, ir, t.Elem, "optional", name, indent, expanding)
+		return
+	case SchemaSlice:
+		fmt.Fprintf(b, "%s%s group %s (LIST) {\n", indent, repetition, name)
+		fmt.Fprintf(b, "%s  repeated group list {\n", indent)
+		parquetField(b, ir, t.Elem, "required", "element", indent+"    ", expanding)
+		fmt.Fprintf(b, "%s  }\n%s}\n", indent, indent)
+		return
+	case SchemaMap:
+		key := "binary key (STRING)"
+		if t.Key != nil && t.Key.Kind == SchemaInt {
+			key = "int64 key"
+		}
+		fmt.Fprintf(b, "%s%s group %s (MAP) {\n", indent, repetition, name)
+		fmt.Fprintf(b, "%s  repeated group key_value {\n", indent)
+		fmt.Fprintf(b, "%s    required %s;\n", indent, key)
+		parquetField(b, ir, t.Elem, "required", "value", indent+"    ", expanding)
+		fmt.Fprintf(b, "%s  }\n%s}\n", indent, indent)
+		return
+	case SchemaStruct:
+		fmt.Fprintf(b, "%s%s group %s {\n", indent, repetition, name)
+		parquetFields(b, ir, t, indent+"  ", expanding)
+		fmt.Fprintf(b, "%s}\n", indent)
+		return
+	case SchemaNamed:
+		if !expanding[t.Name] {
+			expanding[t.Name] = true
+			defer delete(expanding, t.Name)
+			parquetField(b, ir, ir.Types[t.Name], repetition, name, indent, expanding)
+			return
+		}
+	}
+	// Any values, json.RawMessage, types with own json methods and recursive types.
+	fmt.Fprintf(b, "%soptional binary %s (JSON);\n", indent, name)
+}
+
+// parquetName returns name of json key, without characters separating tokens of schema text format.
+func parquetName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(" \t\r\n;{}(),", r) {
+			return '_'
+		}
+		return r
+	}, key)
+	if name == "" {
+		return "_"
+	}
+	return name
+}
+
+// ParquetSchema returns Parquet message type describing parsed documents, see ParquetEmitter.
+func (p *JSONParser) ParquetSchema() (string, error) {
+	out, err := ParquetEmitter{}.Emit(p.Schema())
+	return string(out), err
+}
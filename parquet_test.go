@@ -0,0 +1,113 @@
+package json2go
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParquetSchema(t *testing.T) {
+	t.Parallel()
+
+	p := NewJSONParser(baseTypeName)
+	require.NoError(t, p.FeedBytes([]byte(`{"id": 1, "name": "a", "tags": ["x"], "addr": {"zip": "1"},
+		"at": "2021-01-01T00:00:00Z", "matrix": [[1.5]], "extra": 1}`)))
+	require.NoError(t, p.FeedBytes([]byte(`{"id": 2, "tags": [], "addr": {"zip": "2"},
+		"at": "2021-01-02T00:00:00Z", "matrix": [[1]], "extra": "x"}`)))
+
+	schema, err := p.ParquetSchema()
+	require.NoError(t, err)
+	assert.Equal(t, `message Document {
+  required group addr {
+    required binary zip (STRING);
+  }
+  required int64 at (TIMESTAMP(MICROS,true));
+  optional binary extra (JSON);
+  required int64 id;
+  required group matrix (LIST) {
+    repeated group list {
+      required group element (LIST) {
+        repeated group list {
+          required double element;
+        }
+      }
+    }
+  }
+  optional binary name (STRING);
+  required group tags (LIST) {
+    repeated group list {
+      required binary element (STRING);
+    }
+  }
+}
+`, schema)
+}
+
+func TestParquetEmitter(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		ir       *Schema
+		expected string
+		err      error
+	}{
+		{
+			name: "maps",
+			ir: &Schema{Root: "Document", Types: map[string]*SchemaType{"Document": {Kind: SchemaStruct, Fields: []SchemaField{
+				{Name: "Counts", Key: "counts", Type: &SchemaType{Kind: SchemaMap, Key: &SchemaType{Kind: SchemaInt},
+					Elem: &SchemaType{Kind: SchemaPointer, Elem: &SchemaType{Kind: SchemaFloat, Bits: 32}}}, OmitEmpty: true},
+				{Name: "Labels", Key: "labels", Type: &SchemaType{Kind: SchemaMap, Elem: &SchemaType{Kind: SchemaString}}},
+			}}}},
+			expected: `message Document {
+  optional group counts (MAP) {
+    repeated group key_value {
+      required int64 key;
+      optional float value;
+    }
+  }
+  required group labels (MAP) {
+    repeated group key_value {
+      required binary key (STRING);
+      required binary value (STRING);
+    }
+  }
+}
+`,
+		},
+		{
+			name: "recursive type and invalid names",
+			ir: &Schema{Root: "Node", Types: map[string]*SchemaType{"Node": {Kind: SchemaStruct, Fields: []SchemaField{
+				{Name: "FirstName", Key: "first name", Type: &SchemaType{Kind: SchemaBool}},
+				{Name: "Parent", Key: "parent", Type: &SchemaType{Kind: SchemaPointer, Elem: &SchemaType{Kind: SchemaNamed, Name: "Node"}}},
+			}}}},
+			expected: `message Node {
+  required boolean first_name;
+  optional binary parent (JSON);
+}
+`,
+		},
+		{
+			name: "root isn't struct",
+			ir:   &Schema{Root: "Document", Types: map[string]*SchemaType{"Document": {Kind: SchemaInt}}},
+			err:  ErrUnsupportedShape,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			out, err := ParquetEmitter{}.Emit(tc.ir)
+			if tc.err != nil {
+				assert.True(t, errors.Is(err, tc.err), "unexpected error: %v", err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(out))
+		})
+	}
+}
@@ -1,8 +1,13 @@
 package json2go
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"go/ast"
+	"text/template"
+	"time"
 )
 
 type options struct {
@@ -11,12 +16,115 @@ type options struct {
 	skipEmptyKeys                bool
 	makeMaps                     bool
 	makeMapsWhenMinAttributes    uint
+	makeMapsMaxDepth             uint
 	timeAsStr                    bool
+	rawMessagePaths              map[string]bool
+	mapPaths                     map[string]bool
+	structPaths                  map[string]bool
+	rawMessageForUnstable        bool
+	rawMessageMinKinds           uint
+	mapKeyTypes                  bool
+	tuples                       bool
+	collapseWrappers             bool
+	flattenChains                uint
+	float32                      bool
+	float32OnlyLossless          bool
+	nonFiniteNumbers             bool
+	decimals                     bool
+	decimalType                  string
+	decimalImport                string
+	boolStrings                  bool
+	numberLocale                 NumberLocale
+	epochUnits                   []EpochUnit
+	optionalType                 bool
+	presenceTracking             bool
+	tagTemplate                  *template.Template
+	tagTemplateErr               error
+	descriptions                 map[string]string
+	stringMethods                bool
+	redactPatterns               []string
+	redactedType                 bool
+	redactedTypePatterns         []string
+	jsonV2                       bool
+	jsonV2CaseInsensitive        bool
+	easyJSON                     bool
+	fastDecoders                 bool
+	fieldOrder                   FieldOrder
+	typeOrder                    TypeOrder
+	rootTypes                    RootTypes
+	rootElementType              bool
+	rootElementTypeName          string
+	sliceElements                SliceElements
+	nullElements                 NullElements
+	emptyValues                  EmptyValues
+	invalidUTF8                  InvalidUTF8
+	unknownFields                bool
+	commentMinPresence           float64
+	commentUnstable              bool
+	goModule                     *goModule
+	goModuleErr                  error
+	typeCheck                    bool
+	verifyDecoding               bool
+	verifyTags                   bool
+	strictness                   Strictness
+	progress                     func(Progress)
+	sampleLimit                  uint
+	sampleReservoir              bool
+	outlierTolerance             float64
+	exampleMaxLength             uint
+	exampleMaxSize               uint
+	widening                     WideningLattice
+	anonymize                    bool
+	pii                          bool
+	piiTag                       string
+	anonymizeSeed                int64
+	inputCacheSize               uint
+	duplicateKeys                DuplicateKeys
+	duplicateKeysCheck           bool
+	maxDepth                     uint
+	maxNodes                     uint
+	maxGeneratedFields           uint
+	logger                       Logger
+	metrics                      Metrics
+	nameMapping                  *NameMapping
+	singulars                    map[string]string
+	keySplitting                 KeySplitting
+	keySeparators                string
+	overrides                    Overrides
+	overridesErr                 error
+	timeFormats                  map[string]string
+	expandJSONStrings            bool
+	expandQueryStrings           bool
+	expandBase64JSON             bool
+	stringFormats                bool
+	protoJSON                    bool
+	geoJSON                      GeoJSON
+	jsonAPI                      bool
+	hal                          HAL
+	cloudEvents                  bool
+	structuredLogs               float64
+	recencyHalfLife              uint
+	plugins                      []*Plugin
+	timeFormatsErr               error
+	outputTemplate               *template.Template
+	outputTemplateErr            error
+	header                       bool
+	headerTemplate               *template.Template
+	headerTemplateErr            error
+	headerTimestamp              bool
+	headerCommand                string
+	headerConfig                 string
+	headerConfigErr              error
+	forcedRequired               map[string]bool
+	forcedNullable               map[string]bool
 }
 
 // JSONParserOpt is a type for setting parser options.
 type JSONParserOpt func(*options)
 
+// Logger is a function receiving debug messages from parser.
+type Logger func(msg string)
+
 // OptExtractCommonTypes toggles extracting common json nodes as separate types.
 func OptExtractCommonTypes(v bool) JSONParserOpt {
 	return func(o *options) {
@@ -47,6 +155,722 @@ func OptMakeMaps(v bool, minAttributes uint) JSONParserOpt {
 	}
 }
 
+// OptMakeMapsMaxDepth limits number of nested map levels created when OptMakeMaps is enabled.
+// Outermost objects are converted first, so for depth 2 `{"2021":{"01":{"clicks":5}}}`
+// becomes `map[string]map[string]struct{...}`. Depth 0 means no limit.
+func OptMakeMapsMaxDepth(depth uint) JSONParserOpt {
+	return func(o *options) {
+		o.makeMapsMaxDepth = depth
+	}
+}
+
+// OptMapKeyTypes toggles using int64 or uuid.UUID map keys, when all keys of a map are integers or uuids.
+// Both types are supported as map keys by encoding/json, so no custom unmarshaler is needed.
+func OptMapKeyTypes(v bool) JSONParserOpt {
+	return func(o *options) {
+		o.mapKeyTypes = v
+	}
+}
+
+// OptTuples toggles generating tuple types for fixed length arrays with values of different types, like `["name", 5]`.
+// Tuple type is a struct with field for each position and json methods converting it from/to array.
+// When disabled, such arrays are represented as []interface{}.
+func OptTuples(v bool) JSONParserOpt {
+	return func(o *options) {
+		o.tuples = v
+	}
+}
+
+// OptCollapseWrappers toggles collapsing objects, which have exactly one key everywhere they appear, like
+// `{"value": 3}`, to type of its value. Such attributes get helper types of values, with json methods unwrapping
+// and wrapping them. Extracted types of such objects are declared with types of values. Values represented by
+// interface{} or pointers aren't collapsed.
+func OptCollapseWrappers(v bool) JSONParserOpt {
+	return func(o *options) {
+		o.collapseWrappers = v
+	}
+}
+
+// OptFlattenChains toggles flattening chains of at least minDepth nested objects, which have exactly one key
+// everywhere they appear, like "a" of `{"a": {"b": {"c": 1}}}` with depth 2, to fields of values at their ends.
+// Fields are named with keys of chains, like ABC, and get helper types of values, with json methods unwrapping and
+// wrapping them, so intermediate structs aren't declared. Values represented by interface{} or pointers aren't
+// flattened. 0 disables flattening.
+func OptFlattenChains(minDepth uint) JSONParserOpt {
+	return func(o *options) {
+		o.flattenChains = minDepth
+	}
+}
+
+// OptWidening sets go types of values of two different types combined in one field, like integers and strings,
+// see WideningLattice. Zero lattice keeps default types.
+func OptWidening(lattice WideningLattice) JSONParserOpt {
+	return func(o *options) {
+		o.widening = lattice
+	}
+}
+
+// OptFloat32 toggles using float32 instead of float64 for floating point values.
+// If onlyLossless is true, float32 is used only when all values seen can be represented as float32
+// without losing precision, e.g. 0.25 or 12.5, but not 3.141592653589793.
+func OptFloat32(v bool, onlyLossless bool) JSONParserOpt {
+	return func(o *options) {
+		o.float32 = v
+		o.float32OnlyLossless = onlyLossless
+	}
+}
+
+// OptNonFiniteNumbers toggles tolerant parsing of NaN, Infinity and -Infinity literals, which some producers emit,
+// though they aren't valid json. Numbers with them have helper float64 type unmarshaled also from literals quoted as
+// strings, with generated function quoting them in data before unmarshaling. Integers with negative zero are float64.
+// Only go types are affected, schemas and types of other languages use defaults.
+func OptNonFiniteNumbers(v bool) JSONParserOpt {
+	return func(o *options) {
+		o.nonFiniteNumbers = v
+	}
+}
+
+// OptEpochTimes sets units of Unix times, represented in json as numbers. Attributes with timestamp-like keys,
+// like "created_at" or "ts", which all values are integers with magnitude of recent times in one of units,
+// get helper time types unmarshaling them. Units are checked in given order. No units means no conversion.
+func OptEpochTimes(units ...EpochUnit) JSONParserOpt {
+	return func(o *options) {
+		o.epochUnits = units
+	}
+}
+
+// OptExpandJSONStrings toggles expanding json objects and arrays encoded in json strings, like `{"meta":"{\"id\":1}"}`.
+// Attributes, which all values are such strings, get helper types of decoded values, with json methods decoding
+// and encoding them as strings. When disabled, such values are strings.
+func OptExpandJSONStrings(v bool) JSONParserOpt {
+	return func(o *options) {
+		o.expandJSONStrings = v
+	}
+}
+
+// OptExpandQueryStrings toggles expanding url encoded queries and form bodies in json strings, like `"a=1&b=x"`,
+// common in webhook and analytics payloads. Attributes, which all values are such strings, get helper structs
+// with field for each key, with json methods decoding and encoding them as strings. Values of repeated keys are arrays.
+func OptExpandQueryStrings(v bool) JSONParserOpt {
+	return func(o *options) {
+		o.expandQueryStrings = v
+	}
+}
+
+// OptExpandBase64JSON toggles decoding of JWTs and json objects or arrays encoded in base64 strings.
+// JWTs get types with claims decoded from token payload, without verification of signature, and base64 strings get
+// types of decoded values. Values are decoded at runtime by json methods of generated types.
+func OptExpandBase64JSON(v bool) JSONParserOpt {
+	return func(o *options) {
+		o.expandBase64JSON = v
+	}
+}
+
+// OptStringFormats toggles tracking formats of strings, like uuids, email addresses and urls, and enums of strings
+// with few distinct values. They are used by ZodSchema, CUESchema and PklSchema, which without this option
+// describe such values as plain strings. Tracking them slows down parsing.
+func OptStringFormats(v bool) JSONParserOpt {
+	return func(o *options) {
+		o.stringFormats = v
+	}
+}
+
+// formatsWanted returns formats of values used by enabled options. Other formats aren't detected by nodes,
+// because detecting formats of every value is costly.
+func (o *options) formatsWanted() int {
+	var formats int
+	if o.decimals {
+		formats |= formatDecimal
+	}
+	if o.boolStrings {
+		formats |= formatBoolString
+	}
+	if o.redactedType {
+		formats |= formatSecret
+	}
+	if l, ok := numberLocales[o.numberLocale]; ok {
+		formats |= formatDigitsString | l.format
+	}
+	for _, unit := range o.epochUnits {
+		formats |= epochUnits[unit].format
+	}
+	if o.expandJSONStrings {
+		formats |= formatJSONString
+	}
+	if o.expandQueryStrings {
+		formats |= formatQueryString
+	}
+	if o.expandBase64JSON {
+		formats |= formatJWT | formatBase64JSON
+	}
+	if o.protoJSON {
+		formats |= formatProtoDuration | formatProtoInt64
+	}
+	if o.pii {
+		formats |= formatDigitsString | formatEmail | formatPhone | formatSSN
+	}
+	if o.stringFormats {
+		// Strings with other formats aren't enums.
+		formats |= formatDecimal | formatDigitsString | formatBoolString | formatUUID | formatEmail | formatURL
+	}
+	return formats
+}
+
+// OptProtoJSON makes parser recognize conventions of protobuf json mapping, like responses of gRPC-gateway services.
+// Strings with durations, like "1.5s", are represented by helper type with time.Duration, strings with 64-bit integers
+// by helper type with int64, and objects with "@type" key, google.protobuf.Any values, by json.RawMessage.
+// Timestamps are time.Time, like other RFC 3339 times.
+func OptProtoJSON(v bool) JSONParserOpt {
+	return func(o *options) {
+		o.protoJSON = v
+	}
+}
+
+// OptGeoJSON sets representation of GeoJSON geometries, objects with "type" and "coordinates" or "geometries" keys.
+// See GeoJSON.
+func OptGeoJSON(repr GeoJSON) JSONParserOpt {
+	return func(o *options) {
+		o.geoJSON = repr
+	}
+}
+
+// OptJSONAPI makes parser recognize JSON:API documents. Attributes of resource objects of primary data and
+// included resources become fields of resource types, decoded from and encoded to "attributes" member.
+// Resource types get accessors of relationships, and document type gets method resolving included resources.
+func OptJSONAPI(v bool) JSONParserOpt {
+	return func(o *options) {
+		o.jsonAPI = v
+	}
+}
+
+// OptHAL sets handling of HAL hypermedia conventions, "_links" and "_embedded" members of resources. See HAL.
+func OptHAL(handling HAL) JSONParserOpt {
+	return func(o *options) {
+		o.hal = handling
+	}
+}
+
+// OptCloudEvents makes parser recognize CloudEvents envelopes in json format, single events or batches.
+// Context attributes defined by specification are fields of embedded CloudEvent type, and data payload gets named type.
+// Extension attributes are inferred like other attributes. Their fields with names of embedded CloudEvent field or of
+// its promoted fields, like "ID" key, are renamed with numeric suffix, warning is reported for each of them.
+func OptCloudEvents(v bool) JSONParserOpt {
+	return func(o *options) {
+		o.cloudEvents = v
+	}
+}
+
+// OptStructuredLogs makes parser treat root objects as structured log records, like lines of json log files.
+// Conventional keys, like "level", "ts", "msg" and "caller", get fields named Level, Time, Message and Caller.
+// Other keys present in less than minPresence fraction of records (like 0.5 for 50%), like keys of request context,
+// don't get fields: root type gets Extra map with their values instead, see OptUnknownFields. Zero disables it.
+func OptStructuredLogs(minPresence float64) JSONParserOpt {
+	return func(o *options) {
+		o.structuredLogs = minPresence
+	}
+}
+
+// OptRecencyDecay makes weights of inputs in presence of attributes decay with age: weight of input is halved
+// with every halfLife inputs consumed after it. Attributes present only in old inputs, like historical captures of
+// API, have low presence then, see OptCommentOutFields. Zero disables decay.
+func OptRecencyDecay(halfLife uint) JSONParserOpt {
+	return func(o *options) {
+		o.recencyHalfLife = halfLife
+	}
+}
+
+// OptPlugins enables plugins with custom format detectors, type mappers and emitters, see Plugin.
+// Types chosen by plugins don't replace types set with OptOverrides. Type mappers take precedence over detectors.
+func OptPlugins(plugins ...*Plugin) JSONParserOpt {
+	return func(o *options) {
+		o.plugins = append(o.plugins, plugins...)
+	}
+}
+
+// OptTimeAt forces time format of values at path, like "$.created", when time detection misses custom formats
+// or misdetects values, like version strings. Format is "rfc3339" for time.Time, "unix", "unix_s", "unix_ms", "unix_us"
+// or "unix_ns" for Unix times (see OptEpochTimes), "string" for values kept as they are, without time detection,
+// or go time layout, like "2006-01-02", for helper type unmarshaling strings with layout.
+// Invalid formats are reported by Generate.
+func OptTimeAt(path, format string) JSONParserOpt {
+	return func(o *options) {
+		if o.timeFormats == nil {
+			o.timeFormats = make(map[string]string)
+		}
+		o.timeFormats[path] = format
+		if err := validateTimeFormat(path, format); err != nil && o.timeFormatsErr == nil {
+			o.timeFormatsErr = err
+		}
+	}
+}
+
+// OptNumberLocale sets locale of numbers represented in json as strings, like "1.234,56".
+// Attributes, which all values are strings with numbers formatted in locale, get helper type unmarshaling them
+// as float64 numbers. Attributes with plain integers only, like "123", are kept as strings, as they are likely identifiers.
+func OptNumberLocale(locale NumberLocale) JSONParserOpt {
+	return func(o *options) {
+		o.numberLocale = locale
+	}
+}
+
+// OptDecimal toggles using decimal type for money-like values.
+// Value is money-like if its key ends with "amount", "price" or "total" and all its values are numbers,
+// or strings with numbers, with at most 2 decimal places.
+// By default github.com/shopspring/decimal type is used, see OptDecimalType.
+func OptDecimal(v bool) JSONParserOpt {
+	return func(o *options) {
+		o.decimals = v
+	}
+}
+
+// OptDecimalType sets type used for money-like values, e.g. "decimal.Decimal", and its import path.
+func OptDecimalType(typeName, importPath string) JSONParserOpt {
+	return func(o *options) {
+		o.decimalType = typeName
+		o.decimalImport = importPath
+	}
+}
+
+// OptCoerceBooleanStrings toggles using bool for attributes with string values only like
+// "true"/"false", "yes"/"no", "Y"/"N" or "1"/"0". Generated bool type has custom json unmarshaler.
+func OptCoerceBooleanStrings(v bool) JSONParserOpt {
+	return func(o *options) {
+		o.boolStrings = v
+	}
+}
+
+// OptOptionalType toggles using generated generic Optional[T] type instead of pointers for attributes
+// that were missing or null in some documents. Optional type tells absent attributes apart from null ones.
+// Generated code requires go 1.18, absent attributes are omitted when marshaling with go 1.24 or newer.
+func OptOptionalType(v bool) JSONParserOpt {
+	return func(o *options) {
+		o.optionalType = v
+	}
+}
+
+// OptPresenceTracking toggles generating UnmarshalJSON methods recording which keys were present,
+// and Has<Field>() methods reporting it, for named struct types.
+// Root type doesn't track presence when keys are nested with KeySplittingNested.
+func OptPresenceTracking(v bool) JSONParserOpt {
+	return func(o *options) {
+		o.presenceTracking = v
+	}
+}
+
+// OptUnknownFields toggles adding `Extra map[string]json.RawMessage` field to named struct types, with UnmarshalJSON
+// and MarshalJSON methods keeping values of keys not found in parsed documents, so they aren't lost.
+// Types with other generated json methods, for presence tracking, original field order or nested keys, don't keep them.
+// Fast decoders aren't generated for types keeping unknown keys.
+func OptUnknownFields(v bool) JSONParserOpt {
+	return func(o *options) {
+		o.unknownFields = v
+	}
+}
+
+// OptCommentOutFields toggles emitting low confidence attributes as commented out struct fields, with explanation,
+// so they are used only when uncommented on purpose. Attributes present in less than minPresence fraction of objects
+// (like 0.1 for 10%), or with values of different json kinds if unstable is true, have low confidence.
+// Attributes forced to be required, with OptForceRequired, are never commented out.
+func OptCommentOutFields(minPresence float64, unstable bool) JSONParserOpt {
+	return func(o *options) {
+		o.commentMinPresence = minPresence
+		o.commentUnstable = unstable
+	}
+}
+
+// OptGoModule sets directory of go module, in which generated code is used. Packages of types used by generated code,
+// like custom types set by overrides, are verified against its go.mod, missing ones are reported by Generate
+// as ErrMissingDependency. go.mod is searched in directory and its parents.
+func OptGoModule(dir string) JSONParserOpt {
+	return func(o *options) {
+		o.goModule, o.goModuleErr = readGoModule(dir)
+	}
+}
+
+// OptStrict sets strictness of inference. Generate fails with StrictError, listing paths of values compromised
+// at given or lower level, like values represented by interface{}. StrictnessNone means best effort types.
+func OptStrict(level Strictness) JSONParserOpt {
+	return func(o *options) {
+		o.strictness = level
+	}
+}
+
+// OptTypeCheck toggles type checking of generated code by Generate, with packages it imports.
+// Code with errors, like colliding identifiers or missing imports, is reported as ErrInvalidCode.
+// Packages outside of standard library aren't loaded, only names of types used from them are declared.
+func OptTypeCheck(v bool) JSONParserOpt {
+	return func(o *options) {
+		o.typeCheck = v
+	}
+}
+
+// OptVerifyTags toggles verification, that json tags of generated struct fields are decoded by encoding/json to exactly
+// the same keys as original json keys, also with tag template set with OptTagTemplate. Keys, which encoding/json can't
+// read from tags, like keys with quotes, backslashes or commas, fail generation with error matching
+// ErrUnsupportedShape. Without verification, such keys are only reported as warnings.
+func OptVerifyTags(v bool) JSONParserOpt {
+	return func(o *options) {
+		o.verifyTags = v
+	}
+}
+
+// OptVerifyDecoding toggles verification of generated types by Generate, decoding inputs consumed as bytes, or
+// from readers, with types constructed with reflection, see VerifyDecoding. Inputs are retained until then. Inputs, which don't decode,
+// fail generation with DecodeError, listing paths of values not fitting their types.
+func OptVerifyDecoding(v bool) JSONParserOpt {
+	return func(o *options) {
+		o.verifyDecoding = v
+	}
+}
+
+// OptTagTemplate sets text/template of struct field tags, executed for each field with TagData, e.g.:
+//
+//	json:"{{.EscapedKey}}{{if .Optional}},omitempty{{end}}" db:"{{.Snake}}"
+//
+// If template is invalid, or executed tag isn't a conventional struct tag of key:"value" pairs, Generate returns error
+// and default tags are used. Empty template means default tags.
+func OptTagTemplate(tmpl string) JSONParserOpt {
+	return func(o *options) {
+		o.tagTemplate, o.tagTemplateErr = nil, nil
+		if tmpl == "" {
+			return
+		}
+		o.tagTemplate, o.tagTemplateErr = template.New("tag").Parse(tmpl)
+		if o.tagTemplateErr != nil {
+			o.tagTemplate = nil
+		}
+	}
+}
+
+// OptDescriptions sets descriptions of json values by path, like "$.user.id", e.g. extracted from API docs.
+// Descriptions are added as doc comments of struct fields, description of "$" path is added to root type.
+func OptDescriptions(descriptions map[string]string) JSONParserOpt {
+	return func(o *options) {
+		o.descriptions = descriptions
+	}
+}
+
+// OptStringMethods toggles generating String() methods for named types, safe to use in logs.
+// Values of fields and map keys matching one of patterns, like "*token*", are redacted.
+// Patterns are matched with path.Match against lowercase json keys. If no patterns are set, DefaultRedactPatterns are used.
+func OptStringMethods(v bool, redactPatterns ...string) JSONParserOpt {
+	return func(o *options) {
+		if len(redactPatterns) == 0 {
+			redactPatterns = DefaultRedactPatterns
+		}
+		o.stringMethods = v
+		o.redactPatterns = redactPatterns
+	}
+}
+
+// OptRedactedType toggles using generated Redacted string type for sensitive string attributes.
+// Redacted type hides its value when formatted or marshaled to json, so decoded payloads are safe to log.
+// Attributes are sensitive if json key matches one of patterns (see OptStringMethods),
+// or if values look like credentials, like JWTs or authorization headers.
+// If no patterns are set, DefaultRedactPatterns are used.
+func OptRedactedType(v bool, patterns ...string) JSONParserOpt {
+	return func(o *options) {
+		if len(patterns) == 0 {
+			patterns = DefaultRedactPatterns
+		}
+		o.redactedType = v
+		o.redactedTypePatterns = patterns
+	}
+}
+
+// OptJSONv2 toggles generating struct tags following encoding/json/v2 conventions:
+// "omitzero" instead of "omitempty", and "format:RFC3339" for time attributes.
+// With caseInsensitive, "case:ignore" option is added, so keys are matched case insensitively like in encoding/json.
+// Generated types should be used with encoding/json/v2 only, encoding/json rejects "format" option of time attributes.
+func OptJSONv2(v bool, caseInsensitive bool) JSONParserOpt {
+	return func(o *options) {
+		o.jsonV2 = v
+		o.jsonV2CaseInsensitive = caseInsensitive
+	}
+}
+
+// OptEasyJSON toggles generating types ready for easyjson code generation: nested structs are extracted
+// as named types, and named types are marked with "//easyjson:json" comments.
+func OptEasyJSON(v bool) JSONParserOpt {
+	return func(o *options) {
+		o.easyJSON = v
+	}
+}
+
+// OptFastDecoders toggles generating UnmarshalJSON methods of named struct types, decoding scalar fields
+// without reflection. Other fields are decoded with encoding/json. Unlike encoding/json, keys are matched case sensitively.
+// Types with other generated UnmarshalJSON methods, like with OptPresenceTracking, don't get fast decoders.
+func OptFastDecoders(v bool) JSONParserOpt {
+	return func(o *options) {
+		o.fastDecoders = v
+	}
+}
+
+// OptFieldOrder sets order of keys in json marshaled from generated types.
+// With FieldOrderOriginal, MarshalJSON methods emitting keys in order of their first appearance in parsed documents
+// are generated for named struct types. Inline nested structs are still marshaled in struct fields order.
+// Key order is known only for input consumed by FeedBytes.
+func OptFieldOrder(order FieldOrder) JSONParserOpt {
+	return func(o *options) {
+		o.fieldOrder = order
+	}
+}
+
+// OptTypeOrder sets order of declarations of generated types. By default types are declared
+// after the first type referring to them, see TypeOrderReferencedAfter.
+func OptTypeOrder(order TypeOrder) JSONParserOpt {
+	return func(o *options) {
+		o.typeOrder = order
+	}
+}
+
+// OptRootTypes sets kind of root type declarations, aliases or defined types. See RootTypes.
+func OptRootTypes(kind RootTypes) JSONParserOpt {
+	return func(o *options) {
+		o.rootTypes = kind
+	}
+}
+
+// OptRootElementType toggles named type of elements of root arrays of objects, declared right after root type,
+// like `type Users []User` instead of `type Users []struct{...}`. name is a name of element type, singular of root
+// type name by default, like "User" for "Users", or root type name with "Item" suffix if it has no plural form.
+func OptRootElementType(v bool, name string) JSONParserOpt {
+	return func(o *options) {
+		o.rootElementType = v
+		o.rootElementTypeName = name
+	}
+}
+
+// OptSliceElements sets policy of declaring struct elements of slices as values or pointers,
+// regardless of null elements found in arrays. See SliceElements.
+func OptSliceElements(policy SliceElements) JSONParserOpt {
+	return func(o *options) {
+		o.sliceElements = policy
+	}
+}
+
+// OptEmptyValues sets policy of values seen only as empty objects or empty arrays. See EmptyValues.
+func OptEmptyValues(policy EmptyValues) JSONParserOpt {
+	return func(o *options) {
+		o.emptyValues = policy
+	}
+}
+
+// OptInvalidUTF8 sets policy of bytes of input, which aren't valid UTF-8, warning is reported for inputs with them.
+// See InvalidUTF8. Inputs with UTF-8 byte order mark, or UTF-16 encoded, are decoded regardless of policy.
+func OptInvalidUTF8(policy InvalidUTF8) JSONParserOpt {
+	return func(o *options) {
+		o.invalidUTF8 = policy
+	}
+}
+
+// OptNullElements sets representation of elements of arrays, in which nulls were found. See NullElements.
+func OptNullElements(repr NullElements) JSONParserOpt {
+	return func(o *options) {
+		o.nullElements = repr
+	}
+}
+
+// OptSampleLimit limits number of elements of each array used to infer types to first n elements.
+// Sampled types may be narrower than types of all values, warning is reported for each sampled array.
+// 0 means no limit.
+func OptSampleLimit(n uint) JSONParserOpt {
+	return func(o *options) {
+		o.sampleLimit = n
+		o.sampleReservoir = false
+	}
+}
+
+// OptOutlierTolerance sets fraction of non-null elements of each array, like 0.01, which may be rejected as outliers:
+// elements of other kinds than majority of elements, like a string in array of numbers. Such elements don't
+// contribute to inference, so array gets type of majority instead of interface{}, and warning listing their
+// indices is reported. 0 disables rejection.
+func OptOutlierTolerance(fraction float64) JSONParserOpt {
+	return func(o *options) {
+		o.outlierTolerance = fraction
+	}
+}
+
+// OptExampleLimits limits examples of values retained for Trace, so inputs with large strings, like embedded HTML
+// or base64 blobs, don't grow memory used by inference. String examples are truncated to maxLength runes,
+// 64 if 0. When string examples of all values take maxSize bytes, further values get no string examples.
+// 0 means no limit.
+func OptExampleLimits(maxLength, maxSize uint) JSONParserOpt {
+	return func(o *options) {
+		o.exampleMaxLength = maxLength
+		o.exampleMaxSize = maxSize
+	}
+}
+
+// OptReservoirSample limits number of elements of each array used to infer types to n randomly chosen elements.
+// Sampled types may be narrower than types of all values, warning is reported for each sampled array.
+// 0 means no limit.
+func OptReservoirSample(n uint) JSONParserOpt {
+	return func(o *options) {
+		o.sampleLimit = n
+		o.sampleReservoir = true
+	}
+}
+
+// OptAnonymize toggles replacing parsed values with synthetic values of the same formats before types are inferred,
+// so values derived from inputs, like enum values and warnings, don't leak production data. Types don't change,
+// except names of fields with keys anonymized like identifiers.
+// Replacements are derived from seed, 0 means random seed, see Anonymizer.
+func OptAnonymize(v bool, seed int64) JSONParserOpt {
+	return func(o *options) {
+		o.anonymize = v
+		o.anonymizeSeed = seed
+	}
+}
+
+// OptPII toggles annotating struct fields holding personal data, like emails, phone numbers, names of people,
+// postal addresses or US social security numbers, with their classes, like "email", for compliance tooling.
+// Fields are annotated with tag of given key, like `pii:"email"`, or with comments if tag is empty.
+// Classes are recognized by values and keys heuristically, see PIIFields.
+func OptPII(v bool, tag string) JSONParserOpt {
+	return func(o *options) {
+		o.pii = v
+		o.piiTag = tag
+	}
+}
+
+// OptInputCache enables skipping objects and arrays identical to ones already consumed by the same fields,
+// like repeated documents or their repeated parts. Hashes of at most size distinct values are remembered.
+// Only counts of skipped values are updated, so inferred types and presence of fields are the same as without cache.
+// It speeds up parsing streams with many repeated values. 0 disables cache.
+func OptInputCache(size uint) JSONParserOpt {
+	return func(o *options) {
+		o.inputCacheSize = size
+	}
+}
+
+// OptDuplicateKeys sets policy of handling duplicate keys in json objects consumed as bytes.
+// Warning is reported for each duplicate key. Without this option, duplicate keys are not detected and last value is used.
+func OptDuplicateKeys(policy DuplicateKeys) JSONParserOpt {
+	return func(o *options) {
+		o.duplicateKeys = policy
+		o.duplicateKeysCheck = true
+	}
+}
+
+// OptMaxDepth sets maximum nesting depth of objects and arrays in consumed inputs.
+// Deeper inputs are rejected with ErrDepthExceeded. 0 means no limit.
+func OptMaxDepth(depth uint) JSONParserOpt {
+	return func(o *options) {
+		o.maxDepth = depth
+	}
+}
+
+// OptMaxGeneratedFields sets maximum number of generated struct fields, keeping types of very wide documents
+// reviewable. When types have more fields, objects are summarized as maps, if their values have the same scalar
+// type, or as json.RawMessage values otherwise: deeper objects first, and of them, objects present in fewer documents
+// first. Warning is reported for each summarized object. Fields are counted before extraction of common types.
+// 0 means no limit.
+func OptMaxGeneratedFields(n uint) JSONParserOpt {
+	return func(o *options) {
+		o.maxGeneratedFields = n
+	}
+}
+
+// OptMaxNodes sets maximum number of nodes of inferred types tree, one for each distinct path of values.
+// Inputs adding more nodes are rejected with ErrNodeBudgetExceeded, types of values consumed before
+// the error aren't complete then. 0 means no limit.
+func OptMaxNodes(n uint) JSONParserOpt {
+	return func(o *options) {
+		o.maxNodes = n
+	}
+}
+
+// OptLogger sets logger receiving debug messages about inference decisions,
+// like type changes, map conversions and extracted types.
+func OptLogger(l Logger) JSONParserOpt {
+	return func(o *options) {
+		o.logger = l
+	}
+}
+
+// OptMetrics sets metrics receiving measurements of parser, like sizes of inputs and durations of conversions.
+func OptMetrics(m Metrics) JSONParserOpt {
+	return func(o *options) {
+		o.metrics = m
+	}
+}
+
+// OptNameMapping sets names of generated struct fields and types, by json path. See NameMapping.
+func OptNameMapping(m NameMapping) JSONParserOpt {
+	return func(o *options) {
+		o.nameMapping = &m
+	}
+}
+
+// OptSingulars sets singular forms of plural nouns, like "people": "person", overriding built-in ones.
+// Singular forms are used in names of types of array elements.
+func OptSingulars(singulars map[string]string) JSONParserOpt {
+	return func(o *options) {
+		o.singulars = singulars
+	}
+}
+
+// OptKeySplitting sets policy of handling keys with separators, like "x-request-id" or "user.name".
+// If separators are empty, DefaultKeySeparators are used.
+func OptKeySplitting(policy KeySplitting, separators string) JSONParserOpt {
+	return func(o *options) {
+		if separators == "" {
+			separators = DefaultKeySeparators
+		}
+		o.keySplitting = policy
+		o.keySeparators = separators
+	}
+}
+
+// OptOverrides sets choices overriding inferred types, like excluded attributes or forced types. See Overrides.
+// Invalid overrides are reported by Generate.
+func OptOverrides(o Overrides) JSONParserOpt {
+	return func(opts *options) {
+		opts.overrides = o
+		opts.overridesErr = o.validate()
+	}
+}
+
+// OptForceRequired marks attributes at given paths, like "$.user.id", as present in all objects,
+// regardless of parsed documents. It's useful for facts known from API documentation, but not observed in samples.
+func OptForceRequired(paths ...string) JSONParserOpt {
+	return func(o *options) {
+		o.forcedRequired = setPaths(o.forcedRequired, paths, true)
+	}
+}
+
+// OptForceOptional marks attributes at given paths as possibly missing, regardless of parsed documents.
+func OptForceOptional(paths ...string) JSONParserOpt {
+	return func(o *options) {
+		o.forcedRequired = setPaths(o.forcedRequired, paths, false)
+	}
+}
+
+// OptForceNullable marks values at given paths as possibly null, regardless of parsed documents.
+func OptForceNullable(paths ...string) JSONParserOpt {
+	return func(o *options) {
+		o.forcedNullable = setPaths(o.forcedNullable, paths, true)
+	}
+}
+
+func setPaths(m map[string]bool, paths []string, v bool) map[string]bool {
+	if m == nil {
+		m = make(map[string]bool)
+	}
+	for _, p := range paths {
+		m[p] = v
+	}
+	return m
+}
+
+// OptProgress sets callback called with progress of consuming input by FeedReaderContext.
+func OptProgress(f func(Progress)) JSONParserOpt {
+	return func(o *options) {
+		o.progress = f
+	}
+}
+
 // OptTimeAsString toggles using time.Time for valid time strings or just a string.
 func OptTimeAsString(v bool) JSONParserOpt {
 	return func(o *options) {
@@ -54,35 +878,177 @@ func OptTimeAsString(v bool) JSONParserOpt {
 	}
 }
 
-// JSONParser parses successive json inputs and returns go representation as string
+// OptRawMessageAt forces json.RawMessage type for values at given paths.
+// Path of root value is "$", object attributes are addressed with ".key", e.g. "$.user.settings".
+// Arrays are transparent in paths, so "$.items.meta" matches "meta" attribute in every element of "items" array.
+func OptRawMessageAt(paths ...string) JSONParserOpt {
+	return func(o *options) {
+		if o.rawMessagePaths == nil {
+			o.rawMessagePaths = make(map[string]bool)
+		}
+		for _, p := range paths {
+			o.rawMessagePaths[p] = true
+		}
+	}
+}
+
+// OptMapAt forces map types for objects at given paths, like "$.translations", regardless of OptMakeMaps.
+// Map values have type fitting values of all attributes. Paths of values of maps end with "*", like "$.translations.*".
+func OptMapAt(paths ...string) JSONParserOpt {
+	return func(o *options) {
+		if o.mapPaths == nil {
+			o.mapPaths = make(map[string]bool)
+		}
+		for _, p := range paths {
+			o.mapPaths[p] = true
+		}
+	}
+}
+
+// OptStructAt forces struct types for objects at given paths, like "$.config", which would be converted to maps
+// with OptMakeMaps.
+func OptStructAt(paths ...string) JSONParserOpt {
+	return func(o *options) {
+		if o.structPaths == nil {
+			o.structPaths = make(map[string]bool)
+		}
+		for _, p := range paths {
+			o.structPaths[p] = true
+		}
+	}
+}
+
+// OptRawMessageForUnstable toggles using json.RawMessage instead of interface{} for values with unstable shape.
+// minKinds defines minimum number of distinct json value kinds (bool, number, string, object, array)
+// that has to be seen for a value to be considered unstable.
+func OptRawMessageForUnstable(v bool, minKinds uint) JSONParserOpt {
+	return func(o *options) {
+		o.rawMessageForUnstable = v
+		o.rawMessageMinKinds = minKinds
+	}
+}
+
+// JSONParser parses successive json inputs and returns go representation as string.
+// It isn't safe for concurrent use, see Converter.
 type JSONParser struct {
 	rootNode *node
 	opts     options
+	sampler  *sampler
+	warnings []warning
+	// busy is a time spent consuming inputs, measured only with OptMetrics.
+	busy time.Duration
+	// multiRoot is true if attributes of root object are root types, see ConvertAll.
+	multiRoot bool
+	// anonymizer replaces parsed values with synthetic ones, see OptAnonymize.
+	anonymizer *Anonymizer
+	// inputs is a number of inputs consumed by root node, see Trace.
+	inputs int
+	// weights are weights of consumed inputs, see FeedWeighted.
+	weights inputWeights
+	// headerSources are inputs described in header, see AddHeaderSource.
+	headerSources []HeaderSource
+	// samples are inputs consumed as bytes, retained with OptVerifyDecoding.
+	samples [][]byte
 }
 
 // NewJSONParser creates new json Parser
 func NewJSONParser(rootTypeName string, opts ...JSONParserOpt) *JSONParser {
 	rootNode := newNode(rootTypeName)
 	rootNode.root = true
+	rootNode.document = true
+	rootNode.path = rootPath
 	p := JSONParser{
 		rootNode: rootNode,
-		opts:     options{},
+		opts: options{
+			decimalType:   "decimal.Decimal",
+			decimalImport: "github.com/shopspring/decimal",
+		},
 	}
 	for _, o := range opts {
 		o(&p.opts)
 	}
+	rootNode.logger = p.opts.logger
+	if p.opts.maxNodes > 0 {
+		rootNode.budget = &nodeBudget{left: int(p.opts.maxNodes) - 1}
+	}
+	rootNode.inputs = &p.inputs
+	rootNode.growCtx = &growContext{}
+	p.weights.halfLife = p.opts.recencyHalfLife
+	rootNode.weight = &p.weights.current
+	if p.opts.exampleMaxLength > 0 || p.opts.exampleMaxSize > 0 {
+		rootNode.examples = &exampleBudget{maxLength: int(p.opts.exampleMaxLength), left: -1}
+		if p.opts.exampleMaxLength == 0 {
+			rootNode.examples.maxLength = maxTraceExampleLength
+		}
+		if p.opts.exampleMaxSize > 0 {
+			rootNode.examples.left = int(p.opts.exampleMaxSize)
+		}
+	}
+	rootNode.formatsWanted = p.opts.formatsWanted()
+	rootNode.formats = rootNode.formatsWanted
+	rootNode.enums = p.opts.stringFormats
+	rootNode.detectors = pluginDetectors(p.opts.plugins)
+	rootNode.matching = rootNode.detectors
+	if p.opts.sampleLimit > 0 {
+		p.sampler = newSampler(p.opts.sampleLimit, p.opts.sampleReservoir)
+	}
+	if p.opts.inputCacheSize > 0 {
+		rootNode.cache = newSubtreeCache(p.opts.inputCacheSize)
+	}
+	if p.opts.anonymize {
+		p.anonymizer = NewAnonymizer(p.opts.anonymizeSeed)
+	}
 
 	return &p
 }
 
 // FeedBytes consumes json input as bytes. If input is invalid, json unmarshalling error is returned
 func (p *JSONParser) FeedBytes(input []byte) error {
+	return p.feedBytes(context.Background(), input)
+}
+
+// feedBytes consumes json input, like FeedBytes. Growth stops when context is canceled, its error is returned then.
+func (p *JSONParser) feedBytes(ctx context.Context, input []byte) (err error) {
+	defer recoverError(&err)
+	if p.opts.metrics != nil {
+		defer p.measureFeed(time.Now())
+		p.opts.metrics.ObserveInputSize(len(input))
+	}
+
+	if input, err = decodeInput(input, p.opts.invalidUTF8, p.warner(WarningEncoding)); err != nil {
+		return err
+	}
+	if p.opts.nonFiniteNumbers {
+		input = quoteNonFiniteNumbers(input)
+	}
+
 	var v interface{}
-	if err := json.Unmarshal(input, &v); err != nil {
+	if p.opts.duplicateKeysCheck {
+		if v, err = decodeWithDuplicateKeys(input, p.opts.duplicateKeys, p.warner(WarningDuplicateKey)); err != nil {
+			if errors.Is(err, ErrDuplicateKey) {
+				return err
+			}
+			return invalidJSONError{err: err}
+		}
+	} else if err := json.Unmarshal(input, &v); err != nil {
+		return invalidJSONError{err: err}
+	}
+
+	if err := p.feedContext(ctx, v); err != nil {
 		return err
 	}
 
-	p.FeedValue(v)
+	if p.opts.fieldOrder == FieldOrderOriginal {
+		order, err := jsonKeyOrder(input)
+		if err != nil {
+			return invalidJSONError{err: err}
+		}
+		p.rootNode.addKeyOrder(order)
+	}
+
+	if p.opts.verifyDecoding {
+		p.samples = append(p.samples, input)
+	}
 
 	return nil
 }
@@ -94,33 +1060,165 @@ func (p *JSONParser) FeedBytes(input []byte) error {
 //	* map[string]interface{}  - each value must meet these requirements
 //
 // json.Unmarshal to empty interface value provides perfect input (see example)
+//
+// Values nested deeper than limit set with OptMaxDepth are ignored, warning is reported then.
 func (p *JSONParser) FeedValue(input interface{}) {
+	if p.opts.metrics != nil {
+		defer p.measureFeed(time.Now())
+	}
+	if err := p.feed(input); err != nil {
+		p.warn(WarningDepthExceeded, err.Error())
+	}
+}
+
+func (p *JSONParser) feed(input interface{}) error {
+	return p.feedContext(context.Background(), input)
+}
+
+// feedContext consumes decoded json input. Growth stops when context is canceled, its error is returned then.
+func (p *JSONParser) feedContext(ctx context.Context, input interface{}) (err error) {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if p.opts.maxDepth > 0 && exceedsDepth(input, int(p.opts.maxDepth)) {
+		return fmt.Errorf("%w: input is nested deeper than %d levels", ErrDepthExceeded, p.opts.maxDepth)
+	}
+	if p.opts.nonFiniteNumbers {
+		input = nonFiniteValues(input)
+	}
+	if p.anonymizer != nil {
+		input = p.anonymizer.Value(input)
+	}
+	if p.opts.keySplitting == KeySplittingNested {
+		input = nestKeys(input, p.opts.keySeparators)
+	}
+	if p.opts.outlierTolerance > 0 {
+		input = rejectOutliers(input, rootPath, p.opts.outlierTolerance, p.warner(WarningOutlier))
+	}
+	if p.sampler != nil {
+		input = p.sampler.sample(input, rootPath, p.warner(WarningSampled))
+	}
+	defer recoverNodeBudget(&err)
+	defer recoverGrowCanceled(&err)
+	p.rootNode.growCtx.ctx = ctx
+	defer func() {
+		p.rootNode.growCtx.ctx = nil
+	}()
+	p.inputs++
+	p.weights.advance(p.rootNode)
 	p.rootNode.grow(input)
+
+	return nil
 }
 
-// String returns string representation of go struct fitting parsed json values
-func (p *JSONParser) String() string {
-	root := p.rootNode.clone()
+// Warnings returns warnings about parsed inputs, like information about applied sampling.
+func (p *JSONParser) Warnings() []string {
+	var warnings []string
+	for _, w := range p.warnings {
+		warnings = append(warnings, w.msg)
+	}
+	return warnings
+}
 
-	root.sort()
+// warning is a warning of category, see Warnings.
+type warning struct {
+	category string
+	msg      string
+}
 
-	if p.opts.skipEmptyKeys {
-		p.stripEmptyKeys(root)
+// warn adds warning of category, unless the same warning was already reported.
+func (p *JSONParser) warn(category, msg string) {
+	for _, w := range p.warnings {
+		if w.msg == msg {
+			return
+		}
 	}
-	if p.opts.makeMaps {
-		convertViableObjectsToMaps(root, p.opts.makeMapsWhenMinAttributes)
+	p.warnings = append(p.warnings, warning{category: category, msg: msg})
+	if p.opts.metrics != nil {
+		p.opts.metrics.IncWarnings(category)
 	}
+}
 
-	nodes := []*node{root}
-	if p.opts.extractCommonTypes {
-		nodes = extractCommonSubtrees(root)
+// warner returns function adding warnings of category.
+func (p *JSONParser) warner(category string) func(msg string) {
+	return func(msg string) {
+		p.warn(category, msg)
 	}
+}
 
+// String returns string representation of go struct fitting parsed json values
+func (p *JSONParser) String() string {
+	if p.opts.outputTemplate != nil || p.opts.header {
+		out, _ := p.Generate()
+		return out
+	}
+	nodes, _ := p.declNodes()
 	return astPrintDecls(
 		astMakeDecls(nodes, p.opts),
 	)
 }
 
+// Generate returns string representation of go struct fitting parsed json values.
+// Unlike String, it reports errors found during generation. Output is still usable then,
+// invalid parts are replaced with interface{}.
+func (p *JSONParser) Generate() (string, error) {
+	return p.GenerateContext(context.Background())
+}
+
+// GenerateContext is like Generate, but stops between stages of generation, like type checking, when context
+// is canceled, and returns its error then.
+func (p *JSONParser) GenerateContext(cancelCtx context.Context) (out string, err error) {
+	defer recoverError(&err)
+	if p.opts.metrics != nil {
+		defer p.measureConversion(time.Now())
+	}
+
+	nodes, nodesErr := p.declNodes()
+	if err := cancelCtx.Err(); err != nil {
+		return "", err
+	}
+	ctx := newASTContext(nodes, p.opts)
+	if nodesErr != nil {
+		ctx.fail(nodesErr)
+	}
+	decls := astGenerateDeclsWithContext(nodes, ctx)
+	out = astPrintDecls(decls)
+	if err := strictCheck(p.rootNode, nodes, p.opts); err != nil {
+		ctx.fail(err)
+	}
+	if err := cancelCtx.Err(); err != nil {
+		return "", err
+	}
+	if p.opts.typeCheck && ctx.err == nil {
+		ctx.err = typeCheck(out, ctx)
+	}
+	if err := cancelCtx.Err(); err != nil {
+		return "", err
+	}
+	if p.opts.verifyDecoding && ctx.err == nil {
+		ctx.err = VerifyDecoding(p.samples, p.Schema())
+	}
+	if p.opts.outputTemplateErr != nil {
+		return out, fmt.Errorf("invalid output template: %w", p.opts.outputTemplateErr)
+	}
+	header, err := p.header()
+	if err != nil {
+		return out, err
+	}
+	if p.opts.outputTemplate == nil {
+		if header != "" {
+			out = header + "\n\n" + out
+		}
+		return out, ctx.err
+	}
+
+	out, err = renderOutput(nodes, decls, out, header, ctx, p.opts.outputTemplate)
+	if ctx.err != nil {
+		err = ctx.err
+	}
+	return out, err
+}
+
 // ASTDecls returns ast type declarations
 func (p *JSONParser) ASTDecls() []ast.Decl {
 	p.rootNode.sort()
@@ -131,19 +1229,220 @@ func (p *JSONParser) ASTDecls() []ast.Decl {
 }
 
 func (p *JSONParser) ASTDeclsWithOpt() []ast.Decl {
+	nodes, _ := p.declNodes()
+	return astMakeDecls(nodes, p.opts)
+}
+
+// SuggestNames returns copy of name mapping, extended with current names of fields and types missing in it.
+// Mapping can be saved and edited, then used with OptNameMapping.
+func (p *JSONParser) SuggestNames(m NameMapping) NameMapping {
+	result := NameMapping{
+		Fields: make(map[string]string),
+		Types:  make(map[string]string),
+	}
+	for k, v := range m.Fields {
+		result.Fields[k] = v
+	}
+	for k, v := range m.Types {
+		result.Types[k] = v
+	}
+	nodes, _ := p.outputNodes()
+	result.addSuggestions(nodes)
+
+	return result
+}
+
+// Imports returns sorted list of packages used in generated types.
+func (p *JSONParser) Imports() []string {
+	nodes, _ := p.outputNodes()
+	ctx := newASTContext(nodes, p.opts)
+	astGenerateDeclsWithContext(nodes, ctx)
+
+	return ctx.importsList()
+}
+
+// ImportSpecs returns sorted list of imports of packages used in generated types, with aliases if needed,
+// like `"time"` or `sqlite3 "github.com/mattn/go-sqlite3"`.
+func (p *JSONParser) ImportSpecs() []string {
+	nodes, _ := p.outputNodes()
+	ctx := newASTContext(nodes, p.opts)
+	astGenerateDeclsWithContext(nodes, ctx)
+
+	return ctx.importSpecs()
+}
+
+// declNodes returns output nodes in order of type declarations in generated code, see outputNodes.
+func (p *JSONParser) declNodes() ([]*node, error) {
+	nodes, err := p.outputNodes()
+	return orderNodes(nodes, p.opts.typeOrder), err
+}
+
+// outputNodes returns copy of parsed nodes tree, transformed according to parser options.
+// First node is the root node, the rest are extracted types. Transformations, which fail, are skipped, and
+// their first error is returned with nodes, which are still usable.
+func (p *JSONParser) outputNodes() ([]*node, error) {
 	root := p.rootNode.clone()
+
 	root.sort()
+	if p.opts.keySplitting == KeySplittingCamelCase {
+		renameSeparatedKeys(root, p.opts.keySeparators)
+	}
+
+	applyEmptyValues(root, p.opts.emptyValues, p.warner(WarningEmptyValue))
 	if p.opts.skipEmptyKeys {
 		p.stripEmptyKeys(root)
 	}
+	p.opts.overrides.apply(root)
+	forceTimeFormats(root, p.opts.timeFormats)
+	applyGeoJSON(root, p.opts.geoJSON)
+	if p.opts.jsonAPI {
+		applyJSONAPI(root)
+	}
+	halLink, err := applyHAL(root, p.opts.hal)
+	if p.opts.cloudEvents {
+		applyCloudEvents(root, p.warner(WarningReservedName))
+	}
+	if p.opts.structuredLogs > 0 {
+		applyStructuredLogs(root, p.opts.structuredLogs)
+	}
+	p.applyPlugins(root)
+	forcePresence(root, p.opts.forcedRequired, p.opts.forcedNullable)
+	convertToRawMessages(root, p.opts)
+	if len(p.opts.mapPaths) > 0 {
+		forceMaps(root, p.opts.mapPaths)
+	}
 	if p.opts.makeMaps {
-		convertViableObjectsToMaps(root, p.opts.makeMapsWhenMinAttributes)
+		convertViableObjectsToMaps(root, p.opts.makeMapsWhenMinAttributes, p.opts.makeMapsMaxDepth, p.opts.structPaths)
+	}
+	if p.opts.commentMinPresence > 0 || p.opts.commentUnstable {
+		commentOutFields(root, p.opts.commentMinPresence, p.opts.commentUnstable, p.opts.forcedRequired)
+	}
+	if p.opts.maxGeneratedFields > 0 {
+		summarizeFields(root, p.opts.maxGeneratedFields, p.warner(WarningSummarized))
+	}
+	if p.opts.flattenChains > 0 {
+		flattenChains(root, p.opts.flattenChains)
 	}
+
+	if p.opts.nameMapping != nil {
+		p.opts.nameMapping.applyFieldNames(root)
+	}
+
+	var rootNames []string
+	if p.multiRoot {
+		for _, c := range root.children {
+			rootNames = append(rootNames, c.name)
+		}
+	}
+
 	nodes := []*node{root}
 	if p.opts.extractCommonTypes {
-		nodes = extractCommonSubtrees(root)
+		var extractErr error
+		nodes, extractErr = extractCommonSubtrees(root, p.opts.singulars, p.opts.nameMapping.assignedTypes(), rootNames...)
+		if err == nil {
+			err = extractErr
+		}
+	}
+	if p.multiRoot {
+		nodes = splitRoots(root, nodes[1:])
+	}
+	if p.opts.rootElementType {
+		nodes = extractRootElements(nodes, p.opts.rootElementTypeName, p.opts.singulars, p.opts.nameMapping.assignedTypes())
+	}
+	if p.opts.jsonAPI || p.opts.hal == HALTyped || p.opts.cloudEvents {
+		nodes = extractMarkedStructs(nodes, p.opts.singulars, p.opts.nameMapping.assignedTypes(), func(n *node) string {
+			switch {
+			case n.jsonAPI == jsonAPIResource || n.halEmbedded:
+				return n.name
+			case n.cloudEventData:
+				return root.name + n.name
+			}
+			return ""
+		})
+	}
+	if halLink != nil {
+		nodes = addHALLinkType(nodes, halLink)
+	}
+	if p.opts.easyJSON {
+		nodes = extractNestedStructs(nodes, p.opts.singulars, p.opts.nameMapping.assignedTypes())
+	}
+	if p.opts.nameMapping != nil {
+		p.opts.nameMapping.applyTypeNames(nodes)
+	}
+
+	p.renameReserved(nodes)
+	if p.opts.stringMethods {
+		for _, n := range nodes {
+			if n.t.id() == nodeTypeObject.id() && n.hasChildNamed(stringMethodName) {
+				p.warn(WarningReservedName, fmt.Sprintf("%s: field %s of type %s clashes with method %s, method isn't generated",
+					n.path, stringMethodName, n.name, stringMethodName))
+			}
+		}
+	}
+	for _, n := range nodes {
+		markUntaggableKeys(n, true, &p.opts, p.warner(WarningUntaggableKey))
+	}
+
+	if p.opts.logger != nil {
+		for _, n := range nodes {
+			logOutputDecisions(n)
+		}
+	}
+
+	return nodes, err
+}
+
+// renameReserved adds suffix to type and field names clashing with go keywords, predeclared identifiers
+// or packages imported by generated code. Renames are reported as warnings.
+func (p *JSONParser) renameReserved(nodes []*node) {
+	packages := reservedPackages(p.opts)
+
+	var renameFields func(n *node)
+	renameFields = func(n *node) {
+		for _, c := range n.children {
+			if c.name != "" && isReservedName(c.name, packages) {
+				name := c.name + reservedFieldNameSuffix
+				p.warn(WarningReservedName, fmt.Sprintf("%s: field name %q is reserved, renamed to %q", c.path, c.name, name))
+				c.name = name
+			}
+			renameFields(c)
+		}
+	}
+
+	for _, n := range nodes {
+		if isReservedName(n.name, packages) {
+			name := n.name + reservedTypeNameSuffix
+			p.warn(WarningReservedName, fmt.Sprintf("%s: type name %q is reserved, renamed to %q", n.path, n.name, name))
+			for _, rn := range nodes {
+				renameExtractedType(rn, n.name, name)
+			}
+			n.name = name
+		}
+		renameFields(n)
+	}
+}
+
+// logOutputDecisions reports decisions made by output transformations.
+func logOutputDecisions(n *node) {
+	switch n.t.id() {
+	case nodeTypeMap.id():
+		n.logf("object converted to map")
+	case nodeTypeRawMessage.id():
+		n.logf("using json.RawMessage")
+	case nodeTypeExtracted.id():
+		n.logf("using extracted type %s", n.externalTypeID)
+	}
+
+	for _, c := range n.children {
+		logOutputDecisions(c)
+	}
+}
+
+// recoverError recovers from panic and sets it as error, so invalid input can't crash application using parser.
+func recoverError(err *error) {
+	if r := recover(); r != nil {
+		*err = fmt.Errorf("%w: %v", ErrInternal, r)
 	}
-	return astMakeDecls(nodes, p.opts)
 }
 
 func (p *JSONParser) stripEmptyKeys(n *node) {
@@ -156,7 +1455,31 @@ func (p *JSONParser) stripEmptyKeys(n *node) {
 		if c.t.id() != nodeTypeInit.id() {
 			p.stripEmptyKeys(c)
 			newChildren = append(newChildren, n.children[i])
+		} else {
+			c.logf("key skipped, it has only null values")
 		}
 	}
 	n.children = newChildren
 }
+
+// convertToRawMessages changes type of nodes matching raw message options to json.RawMessage.
+func convertToRawMessages(n *node, opts options) {
+	switch {
+	case opts.rawMessagePaths[n.path]:
+		n.arrayLevel = 0
+	case opts.protoJSON && isProtoAnyNode(n):
+		// Arrays of google.protobuf.Any values are slices of json.RawMessage.
+	case opts.rawMessageForUnstable &&
+		n.t.id() == nodeTypeInterface.id() &&
+		n.kindsCount() >= int(opts.rawMessageMinKinds):
+	default:
+		for _, c := range n.children {
+			convertToRawMessages(c, opts)
+		}
+		return
+	}
+
+	n.t = nodeTypeRawMessage
+	n.children = nil
+	n.arrayWithNulls = false
+}
@@ -3,6 +3,7 @@ package json2go
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -76,6 +77,448 @@ func ExampleJSONParser_FeedValue() {
 	// }
 }
 
+func TestParserImports(t *testing.T) {
+	input := `{"date":"2020-10-03T15:04:05Z","price":"12.99","raw":[1,"a",true,{}]}`
+
+	parser := NewJSONParser(
+		baseTypeName,
+		OptDecimal(true),
+		OptRawMessageForUnstable(true, 3),
+	)
+	err := parser.FeedBytes([]byte(input))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"encoding/json", "github.com/shopspring/decimal", "time"}, parser.Imports())
+}
+
+func TestParserBoolStrings(t *testing.T) {
+	inputs := []string{
+		`{"active":"yes","enabled":"true","flag":"1","name":"x","version":"1","visible":"N"}`,
+		`{"active":"no","enabled":"False","flag":"0","name":"1","version":"1"}`,
+	}
+
+	parser := NewJSONParser(baseTypeName, OptCoerceBooleanStrings(true))
+	for _, in := range inputs {
+		err := parser.FeedBytes([]byte(in))
+		require.NoError(t, err)
+	}
+
+	out := parser.String()
+	assert.Contains(t, out, "Active  BoolString  `json:\"active\"`")
+	assert.Contains(t, out, "Enabled BoolString  `json:\"enabled\"`")
+	assert.Contains(t, out, "Flag    BoolString  `json:\"flag\"`")
+	assert.Contains(t, out, "Name    string      `json:\"name\"`")
+	assert.Contains(t, out, "Version string      `json:\"version\"`", "constant digit isn't a bool")
+	assert.Contains(t, out, "Visible *BoolString `json:\"visible,omitempty\"`")
+	assert.Equal(t, 1, strings.Count(out, "type BoolString bool"))
+	assert.Equal(t, []string{"encoding/json", "fmt", "strconv", "strings"}, parser.Imports())
+
+	out = runGeneratedCode(t, parser, `
+	var d Document
+	if err := json.NewDecoder(os.Stdin).Decode(&d); err != nil {
+		panic(err)
+	}
+	out, err := json.Marshal(d)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(out))
+	`, inputs[1])
+	assert.Equal(t, `{"active":"false","enabled":"false","flag":"false","name":"1","version":"1"}`+"\n", out)
+}
+
+func TestParserKeySplitting(t *testing.T) {
+	input := `{"x-request-id":"a","xrequest_id":1,"user.name":"b","user.id":2}`
+
+	parser := NewJSONParser(baseTypeName, OptKeySplitting(KeySplittingCamelCase, ""))
+	require.NoError(t, parser.FeedBytes([]byte(input)))
+	out := parser.String()
+	assert.Contains(t, out, "XRequestID string `json:\"x-request-id\"`")
+	assert.Contains(t, out, "XrequestID int    `json:\"xrequest_id\"`")
+	assert.Contains(t, out, "UserName   string `json:\"user.name\"`")
+
+	parser = NewJSONParser(baseTypeName, OptKeySplitting(KeySplittingNested, ""))
+	require.NoError(t, parser.FeedBytes([]byte(input)))
+	out = parser.String()
+	assert.Contains(t, out, "\tUser struct {\n\t\tID   int    `json:\"id\"`\n\t\tName string `json:\"name\"`\n\t} `json:\"user\"`")
+	assert.Contains(t, out, "func (v *Document) UnmarshalJSON(data []byte) error {")
+	assert.Equal(t, []string{"encoding/json", "sort", "strings"}, parser.Imports())
+}
+
+func TestParserTagTemplate(t *testing.T) {
+	input := `[{"user_id":1,"name":"a","requestId":"r"},{"user_id":2}]`
+
+	parser := NewJSONParser(baseTypeName, OptTagTemplate(`json:"{{.Key}}{{if .Optional}},omitempty{{end}}" db:"{{.Snake}}"`))
+	require.NoError(t, parser.FeedBytes([]byte(input)))
+	out, err := parser.Generate()
+	require.NoError(t, err)
+	assert.Contains(t, out, "Name      string `json:\"name,omitempty\" db:\"name\"`")
+	assert.Contains(t, out, "RequestID string `json:\"requestId,omitempty\" db:\"request_id\"`")
+	assert.Contains(t, out, "UserID    int    `json:\"user_id\" db:\"user_id\"`")
+
+	parser = NewJSONParser(baseTypeName, OptTagTemplate(`json:"{{.Key"`))
+	require.NoError(t, parser.FeedBytes([]byte(input)))
+	out, err = parser.Generate()
+	assert.Error(t, err)
+	assert.Contains(t, out, "UserID    int    `json:\"user_id\"`")
+
+	parser = NewJSONParser(baseTypeName, OptTagTemplate(`json:"{{.Missing}}"`))
+	require.NoError(t, parser.FeedBytes([]byte(input)))
+	_, err = parser.Generate()
+	assert.Error(t, err)
+
+	parser = NewJSONParser(baseTypeName, OptTagTemplate(`json:"{{.Key}}"`))
+	require.NoError(t, parser.FeedBytes([]byte(`{"weird\"key":1}`)))
+	out, err = parser.Generate()
+	assert.EqualError(t, err, `$.weird"key: executing tag template: malformed tag "json:\"weird\"key\"": missing space after value of key json`)
+	assert.Contains(t, out, "Weirdkey int `json:\"weird\\\"key\"`")
+	assert.Equal(t, []string{`$.weird"key: key "weird\"key" can't be represented in json tag, its values aren't decoded to field Weirdkey`}, parser.Warnings())
+
+	parser = NewJSONParser(baseTypeName, OptTagTemplate(`json:"{{.EscapedKey}}" db:"{{.Snake}}"`))
+	require.NoError(t, parser.FeedBytes([]byte(`{"weird\"key":1}`)))
+	out, err = parser.Generate()
+	require.NoError(t, err)
+	assert.Contains(t, out, "Weirdkey int `json:\"weird\\\"key\" db:\"weirdkey\"`")
+	assert.Len(t, parser.Warnings(), 1)
+}
+
+func TestValidateStructTag(t *testing.T) {
+	t.Parallel()
+
+	for _, tag := range []string{``, `json:"id"`, `json:"id,omitempty" db:"user_id"`, ` json:"a\"b"  xml:"-" `, `json:"a b\\"`} {
+		assert.NoError(t, validateStructTag(tag), tag)
+	}
+	for _, tag := range []string{`json:"a"b"`, `json:"id`, `json`, `json:id`, `:"id"`, `json:"id"db:"x"`, `json :"id"`, `json:"\q"`} {
+		assert.Error(t, validateStructTag(tag), tag)
+	}
+}
+
+func TestAstJSONTag(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		key      string
+		options  []string
+		expected string
+	}{
+		{key: "id", options: []string{"omitempty"}, expected: "`json:\"id,omitempty\"`"},
+		{key: "naïve", expected: "`json:\"naïve\"`"},
+		{key: `"quoted"`, expected: "`json:\"\\\"quoted\\\"\"`"},
+		{key: `a\b`, expected: "`json:\"a\\\\b\"`"},
+		{key: "a`b", expected: `"json:\"a` + "`" + `b\""`},
+		{key: "-", expected: "`json:\"-,\"`"},
+		{key: "-", options: []string{"omitempty"}, expected: "`json:\"-,omitempty\"`"},
+		{key: "page view", options: []string{"omitempty"}, expected: "`json:\"page view,omitempty\"`"},
+		{key: "utm`source,medium", options: []string{"omitempty"}, expected: `"json:\"utm` + "`" + `source,medium,omitempty\""`},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, astJSONTag(tc.key, tc.options...).Value, tc.key)
+	}
+}
+
+func TestParserVerifyTags(t *testing.T) {
+	t.Parallel()
+
+	const input = `{"naïve":1,"-":2,"a b":3,"x.y[0]":4,"ü/ß":5}`
+	parser := NewJSONParser(baseTypeName, OptVerifyTags(true))
+	require.NoError(t, parser.FeedBytes([]byte(input)))
+	out := runGeneratedCode(t, parser, `
+	var d Document
+	if err := json.NewDecoder(os.Stdin).Decode(&d); err != nil {
+		panic(err)
+	}
+	out, err := json.Marshal(d)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(out))
+	`, input)
+	var expected, actual interface{}
+	require.NoError(t, json.Unmarshal([]byte(input), &expected))
+	require.NoError(t, json.Unmarshal([]byte(out), &actual))
+	assert.Equal(t, expected, actual)
+
+	for _, key := range []string{`a"b`, `a\\b`, "a,b", "a`b", ""} {
+		// Keys of named types are decoded by their json methods.
+		input := fmt.Sprintf(`{"id":1,%q:2}`, key)
+		parser = NewJSONParser(baseTypeName, OptVerifyTags(true))
+		require.NoError(t, parser.FeedBytes([]byte(input)))
+		_, err := parser.Generate()
+		require.NoError(t, err, key)
+
+		input = fmt.Sprintf(`{"id":1,"nested":{%q:2}}`, key)
+		parser = NewJSONParser(baseTypeName)
+		require.NoError(t, parser.FeedBytes([]byte(input)))
+		_, err = parser.Generate()
+		require.NoError(t, err, key)
+
+		parser = NewJSONParser(baseTypeName, OptVerifyTags(true))
+		require.NoError(t, parser.FeedBytes([]byte(input)))
+		_, err = parser.Generate()
+		assert.True(t, errors.Is(err, ErrUnsupportedShape), "%s: %v", key, err)
+	}
+
+	parser = NewJSONParser(baseTypeName, OptVerifyTags(true), OptTagTemplate(`json:"{{.Snake}}"`))
+	require.NoError(t, parser.FeedBytes([]byte(`{"userId":1}`)))
+	_, err := parser.Generate()
+	assert.EqualError(t, err, `unsupported shape: $.userId: tag `+"`json:\"user_id\"`"+` has json key "user_id" instead of "userId"`)
+}
+
+func TestParserUntaggableKeys(t *testing.T) {
+	t.Parallel()
+
+	const input = `{"page view":1,"utm_source,medium":"a","cost $":1.5,"event` + "`" + `name":"b"}`
+	parser := NewJSONParser(baseTypeName)
+	require.NoError(t, parser.FeedBytes([]byte(input)))
+	out := runGeneratedCode(t, parser, `
+	var d Document
+	if err := json.NewDecoder(os.Stdin).Decode(&d); err != nil {
+		panic(err)
+	}
+	fmt.Println(d.Pageview, d.Cost, d.UtmSourcemedium, d.Eventname)
+	out, err := json.Marshal(d)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(out))
+	`, input)
+	assert.Equal(t, "1 1.5 a b\n"+`{"cost $":1.5,"event`+"`"+`name":"b","page view":1,"utm_source,medium":"a"}`+"\n", out)
+	assert.Equal(t, []string{
+		"$.event`name: key \"event`name\" can't be represented in json tag, field Eventname is decoded by UnmarshalJSON method of Document",
+		`$.utm_source,medium: key "utm_source,medium" can't be represented in json tag, field UtmSourcemedium is decoded by UnmarshalJSON method of Document`,
+	}, parser.Warnings())
+
+	// Key "c,d" isn't read as key "c".
+	const clashing = `{"c":1,"c,d":2,"n":{"c":3,"c,d":4}}`
+	parser = NewJSONParser(baseTypeName)
+	require.NoError(t, parser.FeedBytes([]byte(clashing)))
+	out = runGeneratedCode(t, parser, `
+	var d Document
+	if err := json.NewDecoder(os.Stdin).Decode(&d); err != nil {
+		panic(err)
+	}
+	fmt.Println(d.C, d.Cd, d.N.C, d.N.Cd)
+	`, clashing)
+	assert.Equal(t, "1 2 3 0\n", out)
+	assert.Equal(t, []string{
+		`$.c,d: key "c,d" can't be represented in json tag, field Cd is decoded by UnmarshalJSON method of Document`,
+		`$.n.c,d: key "c,d" can't be represented in json tag, its values aren't decoded to field Cd`,
+	}, parser.Warnings())
+
+	// Optional values are omitted like with omitempty, and values of unknown keys are kept.
+	parser = NewJSONParser(baseTypeName, OptUnknownFields(true))
+	require.NoError(t, parser.FeedBytes([]byte(`{"c":1,"c,d":2}`)))
+	require.NoError(t, parser.FeedBytes([]byte(`{"c":1}`)))
+	out = runGeneratedCode(t, parser, `
+	for _, input := range []string{`+"`"+`{"c":1,"c,d":2,"x":true}`+"`"+`, `+"`"+`{"c":1}`+"`"+`} {
+		var d Document
+		if err := json.Unmarshal([]byte(input), &d); err != nil {
+			panic(err)
+		}
+		out, err := json.Marshal(d)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println(len(d.Extra), string(out))
+	}
+	`, "")
+	assert.Equal(t, "1 {\"c\":1,\"c,d\":2,\"x\":true}\n0 {\"c\":1}\n", out)
+
+	parser = NewJSONParser(baseTypeName, OptTagTemplate(`json:"{{.EscapedKey}}"`))
+	require.NoError(t, parser.FeedBytes([]byte(input)))
+	_, err := parser.Generate()
+	require.NoError(t, err)
+	assert.Len(t, parser.Warnings(), 2, "keys are untaggable with template too")
+}
+
+func TestParserJSONv2(t *testing.T) {
+	inputs := []string{
+		`{"id":1,"created":"2020-10-03T15:04:05Z","name":"a","tags":["x"]}`,
+		`{"id":2,"created":"2020-10-04T15:04:05Z","updated":"2020-10-05T15:04:05Z"}`,
+	}
+
+	testCases := []struct {
+		name            string
+		caseInsensitive bool
+		expected        string
+	}{
+		{
+			name: "default",
+			expected: `type Document struct {
+	Created time.Time  ` + "`json:\"created,format:RFC3339\"`" + `
+	ID      int        ` + "`json:\"id\"`" + `
+	Name    string     ` + "`json:\"name,omitzero\"`" + `
+	Tags    []string   ` + "`json:\"tags,omitzero\"`" + `
+	Updated *time.Time ` + "`json:\"updated,omitzero,format:RFC3339\"`" + `
+}`,
+		},
+		{
+			name:            "case insensitive",
+			caseInsensitive: true,
+			expected: `type Document struct {
+	Created time.Time  ` + "`json:\"created,format:RFC3339,case:ignore\"`" + `
+	ID      int        ` + "`json:\"id,case:ignore\"`" + `
+	Name    string     ` + "`json:\"name,omitzero,case:ignore\"`" + `
+	Tags    []string   ` + "`json:\"tags,omitzero,case:ignore\"`" + `
+	Updated *time.Time ` + "`json:\"updated,omitzero,format:RFC3339,case:ignore\"`" + `
+}`,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			parser := NewJSONParser(baseTypeName, OptJSONv2(true, tc.caseInsensitive))
+			for _, in := range inputs {
+				require.NoError(t, parser.FeedBytes([]byte(in)))
+			}
+			assert.Equal(t, tc.expected, parser.String())
+		})
+	}
+}
+
+type unknownTestType string
+
+func (n unknownTestType) id() string               { return string(n) }
+func (n unknownTestType) fit(interface{}) nodeType { return n }
+func (n unknownTestType) expands(nodeType) bool    { return false }
+
+func TestParserGenerate(t *testing.T) {
+	parser := NewJSONParser(baseTypeName)
+	err := parser.FeedBytes([]byte(`{"a":1,"b":{"c":"x"}}`))
+	require.NoError(t, err)
+
+	out, err := parser.Generate()
+	require.NoError(t, err)
+	assert.Equal(t, parser.String(), out)
+
+	// Unknown node type is reported as error, generated type has interface{} instead.
+	parser.rootNode.getChild("b").t = unknownTestType("unknown")
+	out, err = parser.Generate()
+	assert.True(t, errors.Is(err, ErrUnsupportedShape))
+	assert.EqualError(t, err, "unsupported shape: $.b: unknown type: unknown")
+	assert.Contains(t, out, "B interface{} `json:\"b\"`")
+}
+
+func TestParserErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		opts     []JSONParserOpt
+		input    string
+		expected error
+	}{
+		{
+			name:     "invalid json",
+			input:    `{"a":`,
+			expected: ErrInvalidJSON,
+		},
+		{
+			name:     "invalid json with duplicate keys check",
+			opts:     []JSONParserOpt{OptDuplicateKeys(DuplicateKeysError)},
+			input:    `{"a":}`,
+			expected: ErrInvalidJSON,
+		},
+		{
+			name:     "duplicate key",
+			opts:     []JSONParserOpt{OptDuplicateKeys(DuplicateKeysError)},
+			input:    `{"a":1,"a":2}`,
+			expected: ErrDuplicateKey,
+		},
+		{
+			name:     "depth exceeded",
+			opts:     []JSONParserOpt{OptMaxDepth(2)},
+			input:    `{"a":[{"b":1}]}`,
+			expected: ErrDepthExceeded,
+		},
+		{
+			name:  "max depth",
+			opts:  []JSONParserOpt{OptMaxDepth(2)},
+			input: `{"a":[1],"b":{"c":1}}`,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			err := NewJSONParser(baseTypeName, tc.opts...).FeedBytes([]byte(tc.input))
+			if tc.expected == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.True(t, errors.Is(err, tc.expected), "unexpected error: %v", err)
+
+			err = NewJSONParser(baseTypeName, tc.opts...).FeedReader(strings.NewReader(tc.input))
+			assert.True(t, errors.Is(err, tc.expected), "unexpected stream error: %v", err)
+		})
+	}
+
+	var syntaxErr *json.SyntaxError
+	err := NewJSONParser(baseTypeName).FeedBytes([]byte(`{"a":}`))
+	assert.True(t, errors.As(err, &syntaxErr))
+}
+
+func TestParserLogger(t *testing.T) {
+	inputs := []string{
+		`{"a":1,"b":[1],"c":null,"m":{"x1":1,"x2":2},"o1":{"x":1,"y":"a"},"o2":{"x":1,"y":"a"}}`,
+		`{"a":1.5,"b":[[1]],"c":null,"m":{"x3":3},"o1":{"x":1,"y":"a"},"o2":{"x":1,"y":"a"}}`,
+	}
+
+	var messages []string
+	parser := NewJSONParser(
+		baseTypeName,
+		OptSkipEmptyKeys(true),
+		OptMakeMaps(true, 2),
+		OptExtractCommonTypes(true),
+		OptLogger(func(msg string) {
+			messages = append(messages, msg)
+		}),
+	)
+	for _, in := range inputs {
+		err := parser.FeedBytes([]byte(in))
+		require.NoError(t, err)
+	}
+	_ = parser.String()
+
+	assert.ElementsMatch(t, []string{
+		"$.a: type changed from int to float",
+		"$.b: type changed from []int to interface",
+		"$.c: key skipped, it has only null values",
+		"$.m: object converted to map",
+		"$.o1: using extracted type O",
+		"$.o2: using extracted type O",
+	}, messages)
+}
+
+func TestParserReservedNames(t *testing.T) {
+	parser := NewJSONParser(
+		baseTypeName,
+		OptExtractCommonTypes(true),
+		OptNameMapping(NameMapping{
+			Fields: map[string]string{"$.kind": "type"},
+			Types:  map[string]string{"$": "error", "$.a": "time"},
+		}),
+	)
+	err := parser.FeedBytes([]byte(`{"kind":"x","a":{"x":1,"y":2},"b":{"x":1,"y":2}}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, normalizeStr(`
+type errorType struct {
+	A         timeType `+"`json:\"a\"`"+`
+	B         timeType `+"`json:\"b\"`"+`
+	typeField string   `+"`json:\"kind\"`"+`
+}
+type timeType struct {
+	X int `+"`json:\"x\"`"+`
+	Y int `+"`json:\"y\"`"+`
+}`), normalizeStr(parser.String()))
+	assert.Equal(t, []string{
+		`$: type name "error" is reserved, renamed to "errorType"`,
+		`$.kind: field name "type" is reserved, renamed to "typeField"`,
+		`$.a: type name "time" is reserved, renamed to "timeType"`,
+	}, parser.Warnings())
+}
+
 // TestParser tests all cases from files in test/parser directory.
 func TestParser(t *testing.T) {
 	testfilesDir := "test/parser/"
@@ -107,12 +550,27 @@ func testFile(t *testing.T, name, inPath, outPath string) {
 
 	type testDef struct {
 		Options struct {
-			ExtractCommonTypes           bool `yaml:"extractCommonTypes"`
-			StringPointersWhenKeyMissing bool `yaml:"stringPointersWhenKeyMissing"`
-			SkipEmptyKeys                bool `yaml:"skipEmptyKeys"`
-			MakeMaps                     bool `yaml:"makeMaps"`
-			MakeMapsWhenMinAttributes    uint `yaml:"makeMapsWhenMinAttributes"`
-			TimeAsStr                    bool `yaml:"timeAsStr"`
+			ExtractCommonTypes           bool              `yaml:"extractCommonTypes"`
+			StringPointersWhenKeyMissing bool              `yaml:"stringPointersWhenKeyMissing"`
+			SkipEmptyKeys                bool              `yaml:"skipEmptyKeys"`
+			MakeMaps                     bool              `yaml:"makeMaps"`
+			MakeMapsWhenMinAttributes    uint              `yaml:"makeMapsWhenMinAttributes"`
+			MakeMapsMaxDepth             uint              `yaml:"makeMapsMaxDepth"`
+			TimeAsStr                    bool              `yaml:"timeAsStr"`
+			RawMessageAt                 []string          `yaml:"rawMessageAt"`
+			RawMessageForUnstable        bool              `yaml:"rawMessageForUnstable"`
+			RawMessageMinKinds           uint              `yaml:"rawMessageMinKinds"`
+			MapKeyTypes                  bool              `yaml:"mapKeyTypes"`
+			Tuples                       bool              `yaml:"tuples"`
+			Float32                      bool              `yaml:"float32"`
+			Float32OnlyLossless          bool              `yaml:"float32OnlyLossless"`
+			Decimal                      bool              `yaml:"decimal"`
+			DecimalType                  string            `yaml:"decimalType"`
+			FieldOrderOriginal           bool              `yaml:"fieldOrderOriginal"`
+			OptionalType                 bool              `yaml:"optionalType"`
+			PresenceTracking             bool              `yaml:"presenceTracking"`
+			Descriptions                 map[string]string `yaml:"descriptions"`
+			EasyJSON                     bool              `yaml:"easyJSON"`
 		} `yaml:"options"`
 		Out string `yaml:"out"`
 	}
@@ -134,7 +592,24 @@ func testFile(t *testing.T, name, inPath, outPath string) {
 				OptStringPointersWhenKeyMissing(tc.Options.StringPointersWhenKeyMissing),
 				OptSkipEmptyKeys(tc.Options.SkipEmptyKeys),
 				OptMakeMaps(tc.Options.MakeMaps, tc.Options.MakeMapsWhenMinAttributes),
+				OptMakeMapsMaxDepth(tc.Options.MakeMapsMaxDepth),
 				OptTimeAsString(tc.Options.TimeAsStr),
+				OptRawMessageAt(tc.Options.RawMessageAt...),
+				OptRawMessageForUnstable(tc.Options.RawMessageForUnstable, tc.Options.RawMessageMinKinds),
+				OptMapKeyTypes(tc.Options.MapKeyTypes),
+				OptTuples(tc.Options.Tuples),
+				OptFloat32(tc.Options.Float32, tc.Options.Float32OnlyLossless),
+				OptDecimal(tc.Options.Decimal),
+				OptOptionalType(tc.Options.OptionalType),
+				OptPresenceTracking(tc.Options.PresenceTracking),
+				OptDescriptions(tc.Options.Descriptions),
+				OptEasyJSON(tc.Options.EasyJSON),
+			}
+			if tc.Options.FieldOrderOriginal {
+				parserOpts = append(parserOpts, OptFieldOrder(FieldOrderOriginal))
+			}
+			if tc.Options.DecimalType != "" {
+				parserOpts = append(parserOpts, OptDecimalType(tc.Options.DecimalType, ""))
 			}
 			parser := NewJSONParser(baseTypeName, parserOpts...)
 			err = parser.FeedBytes(input)
@@ -220,6 +695,36 @@ func main() {
 	return filename
 }
 
+// runGeneratedCode runs program with types generated by parser and given main function body, with input on stdin.
+// Program imports packages used by generated types, and "bufio", "encoding/json", "fmt", "os" and "testing".
+func runGeneratedCode(t *testing.T, parser *JSONParser, mainBody string, input string) string {
+	t.Helper()
+
+	out, err := parser.Generate()
+	require.NoError(t, err)
+
+	imports := map[string]bool{"bufio": true, "encoding/json": true, "fmt": true, "os": true, "testing": true}
+	for _, imp := range parser.Imports() {
+		imports[imp] = true
+	}
+	var src bytes.Buffer
+	src.WriteString("package main\n\nimport (\n")
+	for imp := range imports {
+		fmt.Fprintf(&src, "\t%q\n", imp)
+	}
+	fmt.Fprintf(&src, ")\n\nvar _ = bufio.NewScanner\nvar _ = json.Marshal\nvar _ = fmt.Sprint\nvar _ = os.Exit\nvar _ = testing.Benchmark\n\n%s\n\nfunc main() {\n%s\n}\n", out, mainBody)
+
+	filename := path.Join(t.TempDir(), "main.go")
+	require.NoError(t, ioutil.WriteFile(filename, src.Bytes(), 0600))
+
+	runCmd := exec.Command("go", "run", filename)
+	runCmd.Stdin = strings.NewReader(input)
+	result, err := runCmd.CombinedOutput()
+	require.NoError(t, err, "running go code: %v, %s\n%s", err, result, src.String())
+
+	return string(result)
+}
+
 // normalizeStr trims string, replaces all tabs and space groups with single space, collapses multiple new lines into one.
 func normalizeStr(v string) string {
 	v = strings.TrimSpace(v)
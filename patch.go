@@ -0,0 +1,197 @@
+package json2go
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+// Markers of region of generated code in go file, see Patch.
+const (
+	RegionBegin = "// json2go:begin"
+	RegionEnd   = "// json2go:end"
+)
+
+// Patch returns source of go file src, with code between lines with RegionBegin and RegionEnd comments replaced
+// with generated code, so code outside of region, like helper methods of generated types, is kept across
+// regenerations. Imports of packages used by generated code are added, and imports of standard library packages
+// nothing uses anymore are removed. Markers may be followed by text, like "// json2go:begin Order types".
+// Doc comments of types and struct fields in region, like hand-written documentation, are kept for types with the same
+// names and fields with the same names, types and tags, unless generated code has own doc comments for them.
+// If file doesn't have single region, error matching ErrMissingRegion is returned.
+func (p *JSONParser) Patch(src []byte) ([]byte, error) {
+	begin, end, err := findRegion(src)
+	if err != nil {
+		return nil, err
+	}
+	code, err := p.Generate()
+	if err != nil {
+		return nil, err
+	}
+
+	code = keepDocComments(code, string(src[begin:end]))
+
+	out := append([]byte(nil), src[:begin]...)
+	out = append(out, code+"\n"...)
+	out = append(out, src[end:]...)
+	if out, err = fixImports(out, p.ImportSpecs()); err != nil {
+		return nil, fmt.Errorf("%w: patched file isn't valid go code: %v", ErrInternal, err)
+	}
+	return out, nil
+}
+
+// findRegion returns offsets of beginning and end of region of generated code in go file source, after line
+// with RegionBegin comment and at the beginning of line with RegionEnd comment.
+func findRegion(src []byte) (begin, end int, err error) {
+	begin, end = -1, -1
+	offset := 0
+	for _, line := range bytes.SplitAfter(src, []byte("\n")) {
+		trimmed := bytes.TrimSpace(line)
+		switch {
+		case isRegionMarker(trimmed, RegionBegin):
+			if begin >= 0 {
+				return 0, 0, fmt.Errorf("%w: second %q comment", ErrMissingRegion, RegionBegin)
+			}
+			begin = offset + len(line)
+		case isRegionMarker(trimmed, RegionEnd):
+			if begin < 0 || end >= 0 {
+				return 0, 0, fmt.Errorf("%w: %q comment without preceding %q comment", ErrMissingRegion, RegionEnd, RegionBegin)
+			}
+			end = offset
+		}
+		offset += len(line)
+	}
+	switch {
+	case begin < 0:
+		return 0, 0, fmt.Errorf("%w: no %q comment", ErrMissingRegion, RegionBegin)
+	case end < 0:
+		return 0, 0, fmt.Errorf("%w: no %q comment after %q comment", ErrMissingRegion, RegionEnd, RegionBegin)
+	}
+	return begin, end, nil
+}
+
+// isRegionMarker checks if trimmed line is a marker comment, optionally followed by text.
+func isRegionMarker(line []byte, marker string) bool {
+	rest := bytes.TrimPrefix(line, []byte(marker))
+	return len(rest) < len(line) && (len(rest) == 0 || rest[0] == ' ' || rest[0] == '\t')
+}
+
+// docComment is a doc comment of type or struct field, found by declaredDocs.
+type docComment struct {
+	// text is a comment source, with lines of group separated with new lines.
+	text string
+	// shape is a field source without comments and formatting, empty for types.
+	shape string
+	// offset is an offset of beginning of line of type or field.
+	offset int
+	// indent is a whitespace before type or field in its line.
+	indent string
+}
+
+// declaredDocs returns doc comments of types and struct fields declared in code, by names of types and paths of
+// fields, like "Order.Items.ID". Types and fields without doc comments have empty text.
+func declaredDocs(code string) (map[string]docComment, bool) {
+	const prefix = "package p\n"
+	src := prefix + code
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, false
+	}
+
+	docs := make(map[string]docComment)
+	add := func(key string, doc *ast.CommentGroup, pos token.Pos, shape string) {
+		offset := fset.Position(pos).Offset
+		line := strings.LastIndexByte(src[:offset], '\n') + 1
+		d := docComment{shape: shape, offset: line - len(prefix), indent: src[line:offset]}
+		if doc != nil {
+			text := src[fset.Position(doc.Pos()).Offset:fset.Position(doc.End()).Offset]
+			d.text = strings.Replace(text, "\n"+d.indent, "\n", -1)
+		}
+		docs[key] = d
+	}
+	var addFields func(path string, st *ast.StructType)
+	addFields = func(path string, st *ast.StructType) {
+		for _, f := range st.Fields.List {
+			if len(f.Names) != 1 {
+				continue
+			}
+			key := path + "." + f.Names[0].Name
+			add(key, f.Doc, f.Pos(), goShape(src[fset.Position(f.Pos()).Offset:fset.Position(f.End()).Offset]))
+			if nested := nestedStructType(f.Type); nested != nil {
+				addFields(key, nested)
+			}
+		}
+	}
+	for _, decl := range file.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			ts := spec.(*ast.TypeSpec)
+			doc, pos := ts.Doc, ts.Pos()
+			if !gd.Lparen.IsValid() {
+				doc, pos = gd.Doc, gd.Pos()
+			}
+			add(ts.Name.Name, doc, pos, "")
+			if st := nestedStructType(ts.Type); st != nil {
+				addFields(ts.Name.Name, st)
+			}
+		}
+	}
+	return docs, true
+}
+
+// nestedStructType returns struct type of expression, or of elements of slices, maps and pointers, or nil.
+func nestedStructType(expr ast.Expr) *ast.StructType {
+	for {
+		switch e := expr.(type) {
+		case *ast.StructType:
+			return e
+		case *ast.ArrayType:
+			expr = e.Elt
+		case *ast.MapType:
+			expr = e.Value
+		case *ast.StarExpr:
+			expr = e.X
+		default:
+			return nil
+		}
+	}
+}
+
+// keepDocComments returns generated code with doc comments of types and struct fields of old code, which generated
+// code has without doc comments. Fields must have the same shape. Code which isn't valid go is returned unchanged.
+func keepDocComments(code, old string) string {
+	oldDocs, ok := declaredDocs(old)
+	if !ok {
+		return code
+	}
+	docs, ok := declaredDocs(code)
+	if !ok {
+		return code
+	}
+
+	var kept []docComment
+	for key, d := range docs {
+		o, ok := oldDocs[key]
+		if ok && o.text != "" && d.text == "" && o.shape == d.shape {
+			d.text = o.text
+			kept = append(kept, d)
+		}
+	}
+	// Comments are inserted from the end, so offsets of preceding ones don't change.
+	sort.Slice(kept, func(i, j int) bool {
+		return kept[i].offset > kept[j].offset
+	})
+	for _, d := range kept {
+		comment := d.indent + strings.Replace(d.text, "\n", "\n"+d.indent, -1) + "\n"
+		code = code[:d.offset] + comment + code[d.offset:]
+	}
+	return code
+}
@@ -0,0 +1,241 @@
+package json2go
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONParser_Patch(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		src      string
+		expected string
+		err      string
+	}{
+		{
+			name: "region replaced",
+			src: `package api
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/example/unused"
+)
+
+// json2go:begin Order types
+type Order struct {
+	Old string ` + "`json:\"old\"`" + `
+}
+// json2go:end
+
+// String returns order summary.
+func (o Order) String() string { return fmt.Sprint(o.At) }
+`,
+			expected: `package api
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/example/unused"
+)
+
+// json2go:begin Order types
+type Order struct {
+	At time.Time ` + "`json:\"at\"`" + `
+	ID int       ` + "`json:\"id\"`" + `
+}
+
+// json2go:end
+
+// String returns order summary.
+func (o Order) String() string { return fmt.Sprint(o.At) }
+`,
+		},
+		{
+			name: "no imports",
+			src: `package api
+
+	// json2go:begin
+	// json2go:end
+`,
+			expected: `package api
+
+import (
+	"time"
+)
+
+// json2go:begin
+type Order struct {
+	At time.Time ` + "`json:\"at\"`" + `
+	ID int       ` + "`json:\"id\"`" + `
+}
+
+// json2go:end
+`,
+		},
+		{
+			name: "first import unused",
+			src: `package api
+
+import (
+	"bytes"
+	"fmt"
+)
+
+var _ = fmt.Sprint
+
+// json2go:begin
+// json2go:end
+`,
+			expected: `package api
+
+import (
+	"fmt"
+	"time"
+)
+
+var _ = fmt.Sprint
+
+// json2go:begin
+type Order struct {
+	At time.Time ` + "`json:\"at\"`" + `
+	ID int       ` + "`json:\"id\"`" + `
+}
+
+// json2go:end
+`,
+		},
+		{
+			name: "doc comments kept",
+			src: `package api
+
+import "time"
+
+// json2go:begin
+// Order is a customer order.
+type Order struct {
+	// At is a time of order,
+	// in UTC.
+	At time.Time ` + "`json:\"at\"`" + `
+	// ID is a number of order.
+	ID string ` + "`json:\"id\"`" + `
+	// Old is removed.
+	Old string ` + "`json:\"old\"`" + `
+}
+// json2go:end
+`,
+			expected: `package api
+
+import "time"
+
+// json2go:begin
+// Order is a customer order.
+type Order struct {
+	// At is a time of order,
+	// in UTC.
+	At time.Time ` + "`json:\"at\"`" + `
+	ID int       ` + "`json:\"id\"`" + `
+}
+
+// json2go:end
+`,
+		},
+		{
+			name: "no region",
+			src:  "package api\n\n// json2go:beginning\n",
+			err:  `missing region of generated code: no "// json2go:begin" comment`,
+		},
+		{
+			name: "no end",
+			src:  "package api\n\n// json2go:begin\n",
+			err:  `missing region of generated code: no "// json2go:end" comment after "// json2go:begin" comment`,
+		},
+		{
+			name: "two regions",
+			src:  "package api\n\n// json2go:begin\n// json2go:end\n// json2go:begin\n// json2go:end\n",
+			err:  `missing region of generated code: second "// json2go:begin" comment`,
+		},
+		{
+			name: "end before begin",
+			src:  "package api\n\n// json2go:end\n// json2go:begin\n",
+			err:  `missing region of generated code: "// json2go:end" comment without preceding "// json2go:begin" comment`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := NewJSONParser("Order")
+			require.NoError(t, p.FeedBytes([]byte(`{"at": "2021-01-01T00:00:00Z", "id": 1}`)))
+
+			out, err := p.Patch([]byte(tc.src))
+			if tc.err != "" {
+				require.Error(t, err)
+				assert.True(t, errors.Is(err, ErrMissingRegion), "got error %v", err)
+				assert.Equal(t, tc.err, err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(out))
+
+			again, err := p.Patch(out)
+			require.NoError(t, err)
+			assert.Equal(t, string(out), string(again))
+		})
+	}
+}
+
+func TestKeepDocComments(t *testing.T) {
+	t.Parallel()
+
+	old := `// Order is an order.
+type Order struct {
+	// Items are ordered items.
+	Items []struct {
+		// ID identifies item.
+		ID int ` + "`json:\"id\"`" + `
+	} ` + "`json:\"items\"`" + `
+	// Note is a note.
+	Note string ` + "`json:\"note\"`" + `
+}
+
+// Status is a status.
+type Status string
+`
+	code := `type Order struct {
+	Items []struct {
+		ID    int     ` + "`json:\"id\"`" + `
+		Price float64 ` + "`json:\"price\"`" + `
+	} ` + "`json:\"items\"`" + `
+	// Note is a generated note.
+	Note string ` + "`json:\"note\"`" + `
+}
+
+type Status int
+`
+	expected := `// Order is an order.
+type Order struct {
+	Items []struct {
+		// ID identifies item.
+		ID    int     ` + "`json:\"id\"`" + `
+		Price float64 ` + "`json:\"price\"`" + `
+	} ` + "`json:\"items\"`" + `
+	// Note is a generated note.
+	Note string ` + "`json:\"note\"`" + `
+}
+
+// Status is a status.
+type Status int
+`
+	assert.Equal(t, expected, keepDocComments(code, old))
+	assert.Equal(t, code, keepDocComments(code, "type {"))
+}
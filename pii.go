@@ -0,0 +1,239 @@
+package json2go
+
+import (
+	"go/ast"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Classes of personal data, see OptPII.
+const (
+	PIIEmail   = "email"
+	PIIPhone   = "phone"
+	PIIName    = "name"
+	PIIAddress = "address"
+	PIISSN     = "ssn"
+)
+
+var (
+	// phoneRe matches phone numbers with optional country code and separators, like "+1 (555) 123-4567".
+	phoneRe = regexp.MustCompile(`^\+?\(?\d[\d ().-]{5,22}\d$`)
+	// phoneLikeRe matches dates, times and IPv4 addresses, which aren't phone numbers.
+	phoneLikeRe = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}|^\d{1,3}(\.\d{1,3}){3}$|\d:\d`)
+	// ssnRe matches US social security numbers, like "123-45-6789".
+	ssnRe = regexp.MustCompile(`^(\d{3})-(\d{2})-(\d{4})$`)
+)
+
+// isPhoneString checks if string looks like a phone number, with 7 to 15 digits.
+func isPhoneString(s string) bool {
+	if !phoneRe.MatchString(s) || phoneLikeRe.MatchString(s) || decimalStringRe.MatchString(s) {
+		return false
+	}
+	digits := 0
+	for _, c := range s {
+		if c >= '0' && c <= '9' {
+			digits++
+		}
+	}
+	return digits >= 7 && digits <= 15
+}
+
+// isSSNString checks if string is US social security number, with valid area, group and serial numbers.
+func isSSNString(s string) bool {
+	m := ssnRe.FindStringSubmatch(s)
+	if m == nil {
+		return false
+	}
+	area, _ := strconv.Atoi(m[1])
+	return area != 0 && area != 666 && area < 900 && m[2] != "00" && m[3] != "0000"
+}
+
+// piiKeyWords are last words of keys of attributes with personal data, by class.
+var piiKeyWords = map[string]string{
+	"email":     PIIEmail,
+	"phone":     PIIPhone,
+	"telephone": PIIPhone,
+	"tel":       PIIPhone,
+	"mobile":    PIIPhone,
+	"fax":       PIIPhone,
+	"msisdn":    PIIPhone,
+	"ssn":       PIISSN,
+	"surname":   PIIName,
+	"street":    PIIAddress,
+	"postcode":  PIIAddress,
+	"zip":       PIIAddress,
+	"zipcode":   PIIAddress,
+}
+
+// piiKeyNouns are last words of keys, which are qualified by preceding words, like in "phone_number".
+var piiKeyNouns = map[string]bool{
+	"number": true, "no": true, "num": true, "nr": true, "address": true, "addr": true, "code": true,
+}
+
+// piiNameWords are words preceding "name" in keys of attributes with names of people, like "first_name".
+var piiNameWords = map[string]bool{
+	"first": true, "last": true, "full": true, "given": true, "family": true, "middle": true, "maiden": true,
+	"display": true, "nick": true, "sur": true,
+}
+
+// piiPersonWords are words of keys of objects describing people, whose names are personal data.
+var piiPersonWords = map[string]bool{
+	"user": true, "customer": true, "person": true, "contact": true, "author": true, "owner": true,
+	"employee": true, "patient": true, "member": true, "client": true, "recipient": true, "sender": true,
+	"buyer": true, "seller": true, "guest": true, "applicant": true, "holder": true, "cardholder": true,
+	"beneficiary": true, "profile": true, "assignee": true, "reporter": true,
+}
+
+// piiAddressWords are words of keys of postal addresses and their parts.
+var piiAddressWords = map[string]bool{
+	"address": true, "addr": true, "street": true, "postal": true, "postcode": true, "zip": true, "zipcode": true,
+}
+
+// piiAddressParts are last words of keys of address parts, which are personal data only in addresses.
+var piiAddressParts = map[string]bool{
+	"city": true, "state": true, "region": true, "country": true, "province": true, "county": true, "line": true,
+}
+
+// piiNotAddressWords are words of keys of addresses which aren't postal addresses, like "ip_address".
+var piiNotAddressWords = map[string]bool{
+	"email": true, "mail": true, "ip": true, "ipv4": true, "ipv6": true, "mac": true, "wallet": true, "remote": true,
+	"server": true, "host": true, "bind": true, "listen": true, "web": true, "url": true,
+}
+
+// keyWords returns lower case words of json key, like ["first", "name"] of "firstName".
+func keyWords(key string) []string {
+	return strings.Split(snakeCaseName(attrName(key)), "_")
+}
+
+// piiKeyClass returns class of personal data named by last word of key, or by the word before it,
+// like in "phone_number" or "email_address".
+func piiKeyClass(words []string) string {
+	last := words[len(words)-1]
+	if class, ok := piiKeyWords[last]; ok {
+		return class
+	}
+	if piiKeyNouns[last] && len(words) > 1 {
+		return piiKeyWords[words[len(words)-2]]
+	}
+	return ""
+}
+
+// piiClass returns class of personal data held by attribute node of object node parent, or "" if it doesn't
+// seem to hold personal data. Emails, phone numbers and social security numbers are recognized by values
+// or keys, names and postal addresses by keys, and by keys of parent objects.
+func piiClass(n, parent *node) string {
+	isString := n.t.id() == nodeTypeString.id()
+	if !isString && n.t.id() != nodeTypeInt.id() {
+		return ""
+	}
+	words := keyWords(n.key)
+	keyClass := piiKeyClass(words)
+
+	switch {
+	case isString && n.formats&formatEmail != 0, isString && keyClass == PIIEmail:
+		return PIIEmail
+	case isString && n.formats&formatSSN != 0, keyClass == PIISSN && (!isString || n.formats&formatDigitsString != 0):
+		return PIISSN
+	case isString && n.formats&formatPhone != 0 && (n.formats&formatDigitsString == 0 || keyClass == PIIPhone),
+		!isString && keyClass == PIIPhone:
+		// Numbers of digits only are phone numbers only with keys of phone numbers.
+		return PIIPhone
+	case keyClass == PIIPhone || keyClass == PIISSN:
+		// Values don't look like phone or social security numbers, like of "mobile_os".
+		return ""
+	}
+
+	isAddress := false
+	for _, w := range words {
+		if piiNotAddressWords[w] {
+			return ""
+		}
+		isAddress = isAddress || piiAddressWords[w]
+	}
+	if isAddress && (isString || keyClass == PIIAddress) {
+		// Only postal codes may be numbers.
+		return PIIAddress
+	}
+	if !isString {
+		return ""
+	}
+
+	last := words[len(words)-1]
+	switch {
+	case keyClass == PIIName:
+		return PIIName
+	case last == "name" && len(words) > 1 && piiNameWords[words[len(words)-2]]:
+		return PIIName
+	case last != "name" && strings.HasSuffix(last, "name") && piiNameWords[strings.TrimSuffix(last, "name")]:
+		// Words may be joined, like in "firstname".
+		return PIIName
+	case last == "name" && len(words) == 1 && parent != nil && isPersonKey(parent.key):
+		return PIIName
+	case piiAddressParts[last] && parent != nil:
+		for _, w := range keyWords(parent.key) {
+			if piiAddressWords[w] {
+				return PIIAddress
+			}
+		}
+	}
+	return ""
+}
+
+// isPersonKey checks if json key is a key of object describing person, like "customer" or "users".
+func isPersonKey(key string) bool {
+	words := keyWords(key)
+	last := words[len(words)-1]
+	return piiPersonWords[last] || piiPersonWords[strings.TrimSuffix(last, "s")]
+}
+
+// PIIFields returns classes of personal data held by attributes of parsed documents, by json paths,
+// like {"$.user.email": "email"}, see OptPII. Without OptPII, values aren't classified, only keys.
+func (p *JSONParser) PIIFields() map[string]string {
+	fields := make(map[string]string)
+	var visit func(n *node)
+	visit = func(n *node) {
+		for _, c := range n.children {
+			if class := piiClass(c, n); class != "" {
+				fields[c.path] = class
+			}
+			visit(c)
+		}
+	}
+	nodes, _ := p.outputNodes()
+	for _, n := range nodes {
+		visit(n)
+	}
+	return fields
+}
+
+// astAnnotatePII annotates struct field of attribute node of object node parent with class of personal data,
+// with comment or tag set with OptPII.
+func astAnnotatePII(field *ast.Field, n, parent *node, ctx *astContext) {
+	class := piiClass(n, parent)
+	if class == "" {
+		return
+	}
+	if ctx.opts.piiTag != "" {
+		astAppendTag(field.Tag, ctx.opts.piiTag, class)
+		return
+	}
+	if field.Doc == nil {
+		field.Doc = &ast.CommentGroup{}
+	}
+	field.Doc.List = append(field.Doc.List, &ast.Comment{Text: "// PII: " + class + "."})
+}
+
+// astAppendTag appends key with value to struct field tag.
+func astAppendTag(tag *ast.BasicLit, key, value string) {
+	s := strings.Trim(tag.Value, "`")
+	if strings.HasPrefix(tag.Value, `"`) {
+		s, _ = strconv.Unquote(tag.Value)
+	}
+	s += " " + key + ":" + strconv.Quote(value)
+	if strings.Contains(s, "`") {
+		tag.Value = strconv.Quote(s)
+		return
+	}
+	tag.Value = "`" + s + "`"
+}
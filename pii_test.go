@@ -0,0 +1,141 @@
+package json2go
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsPhoneString(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		s        string
+		expected bool
+	}{
+		{"+1 (555) 123-4567", true},
+		{"555-123-4567", true},
+		{"+48123456789", true},
+		{"123456", false},
+		{"2021-01-01", false},
+		{"10.0.0.1", false},
+		{"12:30:45", false},
+		{"12.50", false},
+		{"1234567890123456", false},
+		{"abc", false},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.s, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.expected, isPhoneString(tc.s))
+		})
+	}
+}
+
+func TestIsSSNString(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, isSSNString("123-45-6789"))
+	assert.False(t, isSSNString("000-45-6789"))
+	assert.False(t, isSSNString("666-45-6789"))
+	assert.False(t, isSSNString("900-45-6789"))
+	assert.False(t, isSSNString("123-00-6789"))
+	assert.False(t, isSSNString("123-45-0000"))
+	assert.False(t, isSSNString("123456789"))
+}
+
+func TestPIIFields(t *testing.T) {
+	t.Parallel()
+
+	p := NewJSONParser(baseTypeName, OptPII(true, ""))
+	require.NoError(t, p.FeedBytes([]byte(`{
+		"user": {"name": "John Doe", "firstName": "John", "email": "john@example.com", "phone": "+1 555 123 4567",
+			"mobile_os": "ios", "ssn": "123-45-6789", "ip_address": "10.0.0.1",
+			"address": {"street": "1 Main St", "city": "Springfield", "zip_code": 12345}},
+		"product": {"name": "Widget", "city": "Paris"},
+		"order_no": "1234567890",
+		"contact_email": "sales@example.com",
+		"email_status": "sent",
+		"tax_id": "123-45-6789",
+		"fax_number": "555 123 4567"}`)))
+
+	assert.Equal(t, map[string]string{
+		"$.user.name":             PIIName,
+		"$.user.firstName":        PIIName,
+		"$.user.email":            PIIEmail,
+		"$.user.phone":            PIIPhone,
+		"$.user.ssn":              PIISSN,
+		"$.user.address.street":   PIIAddress,
+		"$.user.address.city":     PIIAddress,
+		"$.user.address.zip_code": PIIAddress,
+		"$.contact_email":         PIIEmail,
+		"$.tax_id":                PIISSN,
+		"$.fax_number":            PIIPhone,
+	}, p.PIIFields())
+}
+
+func TestOptPII(t *testing.T) {
+	t.Parallel()
+
+	input := `{"customer": {"name": "Jane", "email": "jane@example.com", "id": 1}}`
+	testCases := []struct {
+		name     string
+		tag      string
+		expected string
+	}{
+		{
+			name: "comments",
+			expected: `type Document struct {
+	Customer struct {
+		// PII: email.
+		Email string ` + "`json:\"email\"`" + `
+		ID    int    ` + "`json:\"id\"`" + `
+		// PII: name.
+		Name string ` + "`json:\"name\"`" + `
+	} ` + "`json:\"customer\"`" + `
+}`,
+		},
+		{
+			name: "tag",
+			tag:  "pii",
+			expected: `type Document struct {
+	Customer struct {
+		Email string ` + "`json:\"email\" pii:\"email\"`" + `
+		ID    int    ` + "`json:\"id\"`" + `
+		Name  string ` + "`json:\"name\" pii:\"name\"`" + `
+	} ` + "`json:\"customer\"`" + `
+}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := NewJSONParser(baseTypeName, OptPII(true, tc.tag))
+			require.NoError(t, p.FeedBytes([]byte(input)))
+			assert.Equal(t, tc.expected, p.String())
+		})
+	}
+}
+
+func TestParserPIIFormatsNeedOption(t *testing.T) {
+	t.Parallel()
+
+	input := []byte(`{"a":"+1 415 555 0100","b":"078-05-1120"}`)
+	p := NewJSONParser(baseTypeName)
+	require.NoError(t, p.FeedBytes(input))
+	assert.Zero(t, p.rootNode.getChild("a").formats&formatPhone)
+	assert.Zero(t, p.rootNode.getChild("b").formats&formatSSN)
+	assert.Empty(t, p.PIIFields())
+
+	p = NewJSONParser(baseTypeName, OptPII(true, ""))
+	require.NoError(t, p.FeedBytes(input))
+	assert.NotZero(t, p.rootNode.getChild("a").formats&formatPhone)
+	assert.NotZero(t, p.rootNode.getChild("b").formats&formatSSN)
+	assert.Equal(t, map[string]string{"$.a": PIIPhone, "$.b": PIISSN}, p.PIIFields())
+}
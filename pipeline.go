@@ -0,0 +1,290 @@
+package json2go
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Reader is the first stage of Pipeline, splitting input into raw documents.
+type Reader interface {
+	// ReadDocument returns next raw document, or io.EOF after the last one.
+	ReadDocument() ([]byte, error)
+}
+
+// Tokenizer is the second stage of Pipeline, decoding raw documents into values of types json.Unmarshal uses
+// for empty interface: nil, bool, float64, string, []interface{} and map[string]interface{}.
+// Custom tokenizers let types of other formats than json be inferred.
+type Tokenizer interface {
+	Tokenize(doc []byte) (interface{}, error)
+}
+
+// Inferrer is the third stage of Pipeline, inferring types of decoded values. JSONParser is an Inferrer.
+type Inferrer interface {
+	Infer(v interface{}) error
+	// Schema returns inferred types.
+	Schema() *Schema
+}
+
+// Transform is the fourth stage of Pipeline, changing inferred types.
+type Transform interface {
+	Transform(ir *Schema) (*Schema, error)
+}
+
+// TransformFunc is a function used as Transform.
+type TransformFunc func(ir *Schema) (*Schema, error)
+
+// Transform calls f.
+func (f TransformFunc) Transform(ir *Schema) (*Schema, error) {
+	return f(ir)
+}
+
+// Pipeline converts input to output in stages: Reader → Tokenizer → Inferrer → Transforms → Emitter.
+// Each stage can be replaced, e.g. tokenizer of binary format can be used with default inference and go code.
+//
+// Nil Tokenizer means json documents. Nil Inferrer means JSONParser with default options and root type "Document".
+// Nil Emitter means go code: if inferrer has Generate method, like JSONParser, and transforms don't change
+// inferred types, its output is used, with all features of parser options. Otherwise, GoEmitter is used, so types
+// changed by transforms are emitted without helper types, comments and tags of parser options.
+type Pipeline struct {
+	Reader     Reader
+	Tokenizer  Tokenizer
+	Inferrer   Inferrer
+	Transforms []Transform
+	Emitter    Emitter
+}
+
+// Run runs pipeline stages, until reader has no more documents. Run stops when context is canceled,
+// its error is returned then.
+func (p Pipeline) Run(ctx context.Context) ([]byte, error) {
+	if p.Reader == nil {
+		return nil, fmt.Errorf("pipeline has no reader")
+	}
+	inferrer := p.Inferrer
+	if inferrer == nil {
+		inferrer = NewJSONParser(defaultRootName)
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		doc, err := p.Reader.ReadDocument()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := p.infer(inferrer, doc); err != nil {
+			return nil, err
+		}
+	}
+
+	g, generates := inferrer.(interface{ Generate() (string, error) })
+	if generates && p.Emitter == nil && len(p.Transforms) == 0 {
+		out, err := g.Generate()
+		return []byte(out), err
+	}
+
+	ir := inferrer.Schema()
+	var inferred []byte
+	if generates && p.Emitter == nil {
+		// Transforms may change types in place, so inferred types are compared encoded.
+		var err error
+		if inferred, err = MarshalIR(ir); err != nil {
+			return nil, err
+		}
+	}
+	for _, t := range p.Transforms {
+		var err error
+		if ir, err = t.Transform(ir); err != nil {
+			return nil, err
+		}
+	}
+	if p.Emitter != nil {
+		return p.Emitter.Emit(ir)
+	}
+	if generates {
+		transformed, err := MarshalIR(ir)
+		if err != nil {
+			return nil, err
+		}
+		if bytes.Equal(inferred, transformed) {
+			out, err := g.Generate()
+			return []byte(out), err
+		}
+	}
+	return GoEmitter{}.Emit(ir)
+}
+
+// infer tokenizes document and infers its types. Json documents are fed to JSONParser as bytes,
+// as some options, like OptFieldOrder, need raw input.
+func (p Pipeline) infer(inferrer Inferrer, doc []byte) error {
+	if p.Tokenizer == nil {
+		if jp, ok := inferrer.(*JSONParser); ok {
+			return jp.FeedBytes(doc)
+		}
+	}
+	tokenizer := p.Tokenizer
+	if tokenizer == nil {
+		tokenizer = JSONTokenizer{}
+	}
+	v, err := tokenizer.Tokenize(doc)
+	if err != nil {
+		return err
+	}
+	return inferrer.Infer(v)
+}
+
+// Infer consumes value decoded by Tokenizer, see FeedValue. Unlike FeedValue, it returns error of too deep values.
+func (p *JSONParser) Infer(v interface{}) error {
+	if p.opts.metrics != nil {
+		defer p.measureFeed(time.Now())
+	}
+	return p.feed(v)
+}
+
+// jsonReader reads stream of json documents.
+type jsonReader struct {
+	jd *json.Decoder
+	// nonFinite is true if NaN and infinity literals of stream are quoted, see NewInputReader.
+	nonFinite bool
+}
+
+// NewJSONReader returns Reader of stream of json documents, like FeedReader reads.
+func NewJSONReader(r io.Reader) Reader {
+	return jsonReader{jd: json.NewDecoder(r)}
+}
+
+// NewInputReader returns Reader of stream of json documents, decoded like FeedReader decodes them: input is
+// converted to UTF-8, with policy of invalid bytes set with OptInvalidUTF8, and with OptNonFiniteNumbers, NaN and
+// infinity literals are accepted and kept in documents. Warnings about decoded input are reported with warn,
+// unless it's nil.
+func NewInputReader(r io.Reader, warn func(msg string), opts ...JSONParserOpt) Reader {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if warn == nil {
+		warn = func(string) {}
+	}
+	var dr io.Reader = &decodingReader{r: r, d: inputDecoder{policy: o.invalidUTF8, warn: warn}}
+	if o.nonFiniteNumbers {
+		dr = &nonFiniteReader{r: dr}
+	}
+	return jsonReader{jd: json.NewDecoder(dr), nonFinite: o.nonFiniteNumbers}
+}
+
+func (r jsonReader) ReadDocument() ([]byte, error) {
+	var raw json.RawMessage
+	if err := r.jd.Decode(&raw); err != nil {
+		if _, ok := err.(*json.SyntaxError); ok || err == io.ErrUnexpectedEOF {
+			return nil, invalidJSONError{err: err}
+		}
+		return nil, err
+	}
+	if r.nonFinite {
+		return unquoteNonFiniteNumbers(raw), nil
+	}
+	return raw, nil
+}
+
+// JSONTokenizer decodes json documents.
+type JSONTokenizer struct{}
+
+// Tokenize decodes json document.
+func (JSONTokenizer) Tokenize(doc []byte) (interface{}, error) {
+	var v interface{}
+	if err := json.Unmarshal(doc, &v); err != nil {
+		return nil, invalidJSONError{err: err}
+	}
+	return v, nil
+}
+
+// GoEmitter emits declarations of go types described by IR. Opaque types are json.RawMessage and optional values
+// are pointers. Unlike JSONParser.Generate, it doesn't emit helper types with own json methods, and options of
+// parser, which aren't described by IR, like OptTagTemplate or OptStringMethods, don't apply.
+type GoEmitter struct{}
+
+// Emit returns go declarations of IR types, root type first.
+func (GoEmitter) Emit(ir *Schema) ([]byte, error) {
+	if err := validateIR(ir); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(ir.Types))
+	for name := range ir.Types {
+		if name != ir.Root {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var src bytes.Buffer
+	for i, name := range append([]string{ir.Root}, names...) {
+		if i > 0 {
+			src.WriteString("\n")
+		}
+		fmt.Fprintf(&src, "type %s %s\n", name, goTypeOfSchemaType(ir.Types[name]))
+	}
+	out, err := format.Source(src.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("%w: formatting go types: %v", ErrInternal, err)
+	}
+	return out, nil
+}
+
+// goTypeOfSchemaType returns go type expression of schema type.
+func goTypeOfSchemaType(t *SchemaType) string {
+	switch t.Kind {
+	case SchemaBool:
+		return "bool"
+	case SchemaInt:
+		return "int"
+	case SchemaFloat:
+		if t.Bits == 32 {
+			return "float32"
+		}
+		return "float64"
+	case SchemaString:
+		return "string"
+	case SchemaTime:
+		return "time.Time"
+	case SchemaOpaque:
+		return "json.RawMessage"
+	case SchemaPointer, SchemaOptional:
+		return "*" + goTypeOfSchemaType(t.Elem)
+	case SchemaSlice:
+		return "[]" + goTypeOfSchemaType(t.Elem)
+	case SchemaMap:
+		key := "string"
+		if t.Key != nil && t.Key.Kind == SchemaInt {
+			key = "int64"
+		}
+		return "map[" + key + "]" + goTypeOfSchemaType(t.Elem)
+	case SchemaStruct:
+		var fields []string
+		for _, f := range t.Fields {
+			tag := f.Key
+			if f.OmitEmpty {
+				tag += ",omitempty"
+			}
+			if f.OmitZero {
+				tag += ",omitzero"
+			}
+			fields = append(fields, fmt.Sprintf("%s %s `json:%s`", f.Name, goTypeOfSchemaType(f.Type), strconv.Quote(tag)))
+		}
+		return "struct {\n" + strings.Join(fields, "\n") + "\n}"
+	case SchemaNamed:
+		return t.Name
+	}
+	return "interface{}"
+}
@@ -0,0 +1,212 @@
+package json2go
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lineReader reads lines as documents.
+type lineReader struct {
+	s *bufio.Scanner
+}
+
+func (r lineReader) ReadDocument() ([]byte, error) {
+	if !r.s.Scan() {
+		return nil, io.EOF
+	}
+	return r.s.Bytes(), nil
+}
+
+// pairsTokenizer decodes "key=value;key=value" documents to objects, values are numbers when possible.
+type pairsTokenizer struct{}
+
+func (pairsTokenizer) Tokenize(doc []byte) (interface{}, error) {
+	obj := make(map[string]interface{})
+	for _, pair := range strings.Split(string(doc), ";") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return nil, errors.New("invalid pair: " + pair)
+		}
+		if f, err := strconv.ParseFloat(kv[1], 64); err == nil {
+			obj[kv[0]] = f
+		} else {
+			obj[kv[0]] = kv[1]
+		}
+	}
+	return obj, nil
+}
+
+func TestPipelineDefaultStages(t *testing.T) {
+	t.Parallel()
+
+	input := `{"id":1,"at":"2021-01-01T00:00:00Z"} {"id":2,"at":"2021-01-02T00:00:00Z","tags":["a"]}`
+	parser := NewJSONParser(baseTypeName, OptFieldOrder(FieldOrderOriginal))
+	out, err := Pipeline{Reader: NewJSONReader(strings.NewReader(input)), Inferrer: parser}.Run(context.Background())
+	require.NoError(t, err)
+
+	expected := NewJSONParser(baseTypeName, OptFieldOrder(FieldOrderOriginal))
+	require.NoError(t, expected.FeedReader(strings.NewReader(input)))
+	code, err := expected.Generate()
+	require.NoError(t, err)
+	assert.Equal(t, code, string(out))
+
+	// Transforms, which don't change types, keep output of parser.
+	noop := TransformFunc(func(ir *Schema) (*Schema, error) {
+		return ir, nil
+	})
+	parser = NewJSONParser(baseTypeName, OptFieldOrder(FieldOrderOriginal))
+	out, err = Pipeline{
+		Reader:     NewJSONReader(strings.NewReader(input)),
+		Inferrer:   parser,
+		Transforms: []Transform{noop},
+	}.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, code, string(out))
+}
+
+func TestPipelineCustomStages(t *testing.T) {
+	t.Parallel()
+
+	input := "id=1;name=a\nid=2;name=b;score=1.5\n"
+	dropScore := TransformFunc(func(ir *Schema) (*Schema, error) {
+		root := ir.Types[ir.Root]
+		var fields []SchemaField
+		for _, f := range root.Fields {
+			if f.Key != "score" {
+				fields = append(fields, f)
+			}
+		}
+		root.Fields = fields
+		return ir, nil
+	})
+
+	out, err := Pipeline{
+		Reader:     lineReader{s: bufio.NewScanner(strings.NewReader(input))},
+		Tokenizer:  pairsTokenizer{},
+		Transforms: []Transform{dropScore},
+	}.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "type Document struct {\n"+
+		"\tID   int    `json:\"id\"`\n"+
+		"\tName string `json:\"name\"`\n"+
+		"}\n", string(out))
+}
+
+func TestPipelineEmitter(t *testing.T) {
+	t.Parallel()
+
+	out, err := Pipeline{
+		Reader:   NewJSONReader(strings.NewReader(`{"a":1}`)),
+		Inferrer: NewJSONParser("Event"),
+		Emitter:  testEmitter{},
+	}.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "root Event", string(out))
+}
+
+func TestPipelineErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := Pipeline{Reader: NewJSONReader(strings.NewReader(`{"a":`))}.Run(context.Background())
+	assert.True(t, errors.Is(err, ErrInvalidJSON), "%v", err)
+
+	_, err = Pipeline{
+		Reader:    lineReader{s: bufio.NewScanner(strings.NewReader("a"))},
+		Tokenizer: pairsTokenizer{},
+	}.Run(context.Background())
+	assert.EqualError(t, err, "invalid pair: a")
+
+	_, err = Pipeline{
+		Reader:     NewJSONReader(strings.NewReader(`{"a":1}`)),
+		Transforms: []Transform{TransformFunc(func(*Schema) (*Schema, error) { return nil, errors.New("failed") })},
+	}.Run(context.Background())
+	assert.EqualError(t, err, "failed")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = Pipeline{Reader: NewJSONReader(strings.NewReader(`{"a":1}`))}.Run(ctx)
+	assert.Equal(t, context.Canceled, err)
+
+	_, err = Pipeline{}.Run(context.Background())
+	assert.Error(t, err)
+}
+
+func TestGoEmitter(t *testing.T) {
+	t.Parallel()
+
+	out, err := GoEmitter{}.Emit(&Schema{
+		Root: "Document",
+		Types: map[string]*SchemaType{
+			"Document": {Kind: SchemaStruct, Fields: []SchemaField{
+				{Name: "At", Key: "at", Type: &SchemaType{Kind: SchemaTime}},
+				{Name: "Items", Key: "items", Type: &SchemaType{Kind: SchemaSlice, Elem: &SchemaType{Kind: SchemaNamed, Name: "Item"}}, OmitEmpty: true},
+				{Name: "Meta", Key: "meta", Type: &SchemaType{Kind: SchemaMap, Key: &SchemaType{Kind: SchemaInt}, Elem: &SchemaType{Kind: SchemaAny}}},
+				{Name: "Raw", Key: "raw", Type: &SchemaType{Kind: SchemaOpaque}, OmitZero: true},
+			}},
+			"Item": {Kind: SchemaPointer, Elem: &SchemaType{Kind: SchemaFloat, Bits: 32}},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "type Document struct {\n"+
+		"\tAt    time.Time             `json:\"at\"`\n"+
+		"\tItems []Item                `json:\"items,omitempty\"`\n"+
+		"\tMeta  map[int64]interface{} `json:\"meta\"`\n"+
+		"\tRaw   json.RawMessage       `json:\"raw,omitzero\"`\n"+
+		"}\n\n"+
+		"type Item *float32\n", string(out))
+
+	_, err = GoEmitter{}.Emit(&Schema{Root: "Missing"})
+	assert.True(t, errors.Is(err, ErrInvalidIR))
+}
+
+func TestJSONReader(t *testing.T) {
+	t.Parallel()
+
+	r := NewJSONReader(bytes.NewReader([]byte(`1 {"a":[2]}`)))
+	doc, err := r.ReadDocument()
+	require.NoError(t, err)
+	assert.Equal(t, "1", string(doc))
+	doc, err = r.ReadDocument()
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":[2]}`, string(doc))
+	_, err = r.ReadDocument()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestInputReader(t *testing.T) {
+	t.Parallel()
+
+	var warnings []string
+	warn := func(msg string) {
+		warnings = append(warnings, msg)
+	}
+	input := append([]byte{0xef, 0xbb, 0xbf}, "1 {\"a\":\"Z\xfc\"}"...)
+	r := NewInputReader(bytes.NewReader(input), warn)
+	doc, err := r.ReadDocument()
+	require.NoError(t, err)
+	assert.Equal(t, "1", string(doc))
+	doc, err = r.ReadDocument()
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":"Z`+"�"+`"}`, string(doc))
+	_, err = r.ReadDocument()
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, []string{"input: invalid UTF-8 bytes replaced with U+FFFD"}, warnings)
+
+	r = NewInputReader(bytes.NewReader([]byte{0xff, 0xfe, '[', 0, '1', 0, ']', 0}), nil, OptInvalidUTF8(InvalidUTF8Latin1))
+	doc, err = r.ReadDocument()
+	require.NoError(t, err)
+	assert.Equal(t, "[1]", string(doc))
+
+	r = NewInputReader(bytes.NewReader([]byte("{\"a\":\"Z\xfc\"}")), nil, OptInvalidUTF8(InvalidUTF8Error))
+	_, err = r.ReadDocument()
+	assert.EqualError(t, err, "invalid UTF-8 byte 0xfc at offset 7")
+}
@@ -0,0 +1,156 @@
+package json2go
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// pklIdentifierRe matches Pkl identifiers, which don't need backticks as property names.
+var pklIdentifierRe = regexp.MustCompile(`^[A-Za-z_$][\w$]*$`)
+
+// pklKeywords are keywords of Pkl, quoted with backticks in property names.
+var pklKeywords = map[string]bool{
+	"abstract": true, "amends": true, "as": true, "case": true, "class": true, "const": true, "delete": true,
+	"else": true, "extends": true, "external": true, "false": true, "fixed": true, "for": true, "function": true,
+	"hidden": true, "if": true, "import": true, "in": true, "is": true, "let": true, "local": true, "module": true,
+	"new": true, "nothing": true, "null": true, "open": true, "out": true, "outer": true, "override": true,
+	"protected": true, "read": true, "record": true, "super": true, "switch": true, "this": true, "throw": true,
+	"trace": true, "true": true, "typealias": true, "unknown": true, "vararg": true, "when": true,
+}
+
+// PklSchema returns Pkl classes describing parsed documents, for validation of configuration files.
+// Properties are named after json keys, so values render to json documents like parsed ones. Objects
+// are classes named after go types, root class first. Like CUESchema, types are derived from parsed values:
+// uuids are constrained with pattern, enums are unions of string literal types, and attributes
+// missing in some objects or with null values are nullable. Times are strings. Formats and enums of strings
+// are tracked by parser only with OptStringFormats.
+func (p *JSONParser) PklSchema() (string, error) {
+	nodes, err := p.outputNodes()
+	if err != nil {
+		return "", err
+	}
+	s := pklSchema{names: make(map[string]bool)}
+	for _, n := range nodes {
+		s.names[n.name] = true
+	}
+
+	for _, n := range nodes {
+		if n.t.id() == nodeTypeObject.id() && n.arrayLevel == 0 {
+			s.class(n.name, n)
+			continue
+		}
+		// Only objects can be classes.
+		i := len(s.decls)
+		s.decls = append(s.decls, "")
+		s.decls[i] = fmt.Sprintf("typealias %s = %s\n", n.name, s.value(n, n.name+"Item"))
+	}
+	return strings.Join(s.decls, "\n"), nil
+}
+
+// pklSchema builds Pkl classes of nodes.
+type pklSchema struct {
+	// names are used names of classes.
+	names map[string]bool
+	// decls are sources of classes and type aliases.
+	decls []string
+}
+
+// class adds declaration of class with node's attributes, followed by classes of nested objects.
+func (s *pklSchema) class(name string, n *node) {
+	i := len(s.decls)
+	s.decls = append(s.decls, "")
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "class %s {\n", name)
+	for _, child := range n.children {
+		property := child.key
+		if !pklIdentifierRe.MatchString(property) || pklKeywords[property] {
+			property = "`" + property + "`"
+		}
+		typ := s.value(child, name+child.name)
+		if !child.required && typ != "Any" && !strings.HasSuffix(typ, "?") {
+			typ = pklNullable(typ)
+		}
+		fmt.Fprintf(&b, "  %s: %s\n", property, typ)
+	}
+	b.WriteString("}\n")
+	s.decls[i] = b.String()
+}
+
+// value returns Pkl type of node's values. Nested objects are classes named className.
+func (s *pklSchema) value(n *node, className string) string {
+	var typ string
+	switch n.t.id() {
+	case nodeTypeBool.id():
+		typ = "Boolean"
+	case nodeTypeInt.id():
+		typ = "Int"
+	case nodeTypeFloat.id():
+		typ = "Number"
+	case nodeTypeString.id():
+		typ = s.string(n)
+	case nodeTypeTime.id():
+		typ = "String"
+	case nodeTypeObject.id():
+		for s.names[className] {
+			className = nextName(className)
+		}
+		s.names[className] = true
+		s.class(className, n)
+		typ = className
+	case nodeTypeMap.id():
+		elem := "Any"
+		if len(n.children) > 0 {
+			elem = s.value(n.children[0], className+"Value")
+		}
+		typ = "Mapping<String, " + elem + ">"
+	case nodeTypeExtracted.id():
+		typ = "Any"
+		if !isGoType(n.externalTypeID) {
+			typ = n.externalTypeID
+		}
+	default:
+		typ = "Any"
+	}
+
+	unknown := typ == "Any"
+	for i := n.arrayLevel; i > 0; i-- {
+		if n.arrayWithNulls && i == n.arrayLevel && !unknown {
+			typ = pklNullable(typ)
+		}
+		typ = "Listing<" + typ + ">"
+		unknown = false
+	}
+	if n.nullable && !n.root && !unknown {
+		typ = pklNullable(typ)
+	}
+	return typ
+}
+
+// string returns Pkl type of strings, with pattern of uuids or enum values.
+func (s *pklSchema) string(n *node) string {
+	if n.formats&formatUUID != 0 {
+		return "String(matches(Regex(#\"" + uuidRe.String() + "\"#)))"
+	}
+	if n.isEnum() {
+		values := make([]string, 0, len(n.enumValues))
+		for _, v := range n.enumValues {
+			values = append(values, strconv.Quote(v))
+		}
+		return strings.Join(values, "|")
+	}
+	return "String"
+}
+
+// pklNullable returns nullable type, with parentheses around unions.
+func pklNullable(typ string) string {
+	if strings.HasSuffix(typ, "?") {
+		return typ
+	}
+	if strings.Contains(typ, "|") && !strings.HasSuffix(typ, ">") {
+		typ = "(" + typ + ")"
+	}
+	return typ + "?"
+}
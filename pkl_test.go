@@ -0,0 +1,89 @@
+package json2go
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPklSchema(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		opts     []JSONParserOpt
+		inputs   []string
+		expected string
+	}{
+		{
+			name: "formats, enums and nested classes",
+			inputs: []string{
+				`{"id": "3f2504e0-4f89-11d3-9a0c-0305e82c3301", "status": "active", "user-name": "a b", "class": "a b",
+					"f": 1.5, "tags": ["a", null], "limits": {"cpu": 1}, "level": "high"}`,
+				`{"id": "4f2504e0-4f89-11d3-9a0c-0305e82c3301", "status": "disabled", "class": "c d", "f": 2, "tags": [],
+					"level": "low"}`,
+				`{"id": "5f2504e0-4f89-11d3-9a0c-0305e82c3301", "status": "active", "class": "e f", "f": 3, "tags": [],
+					"level": "high"}`,
+				`{"id": "7f2504e0-4f89-11d3-9a0c-0305e82c3301", "status": "active", "class": "i j", "f": 5, "tags": [],
+					"level": null}`,
+				`{"id": "6f2504e0-4f89-11d3-9a0c-0305e82c3301", "status": "disabled", "class": "g h", "f": 4, "tags": [],
+					"level": "low"}`,
+			},
+			expected: `class Document {
+  ` + "`class`" + `: String
+  f: Number
+  id: String(matches(Regex(#"^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$"#)))
+  level: ("high"|"low")?
+  limits: DocumentLimits?
+  status: "active"|"disabled"
+  tags: Listing<String?>
+  ` + "`user-name`" + `: String?
+}
+
+class DocumentLimits {
+  cpu: Int
+}
+`,
+		},
+		{
+			name: "extracted types",
+			opts: []JSONParserOpt{OptExtractCommonTypes(true)},
+			inputs: []string{
+				`[{"from": {"city": "a", "zip": "1"}, "to": {"city": "b", "zip": "2"}, "tags": {"a": 1}}]`,
+			},
+			expected: `typealias Document = Listing<DocumentItem>
+
+class DocumentItem {
+  from: CityZip
+  tags: DocumentItemTags
+  to: CityZip
+}
+
+class DocumentItemTags {
+  a: Int
+}
+
+class CityZip {
+  city: String
+  zip: String
+}
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := NewJSONParser(baseTypeName, append(tc.opts, OptStringFormats(true))...)
+			for _, input := range tc.inputs {
+				require.NoError(t, p.FeedBytes([]byte(input)))
+			}
+			src, err := p.PklSchema()
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, src)
+		})
+	}
+}
@@ -0,0 +1,230 @@
+package json2go
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Plugin extends parser with custom string format detectors, type mappers and emitters, without changes
+// of json2go package. Plugins are enabled with OptPlugins. They are implemented in go and registered with RegisterPlugin,
+// e.g. in init function of package loaded as go plugin, or run as separate processes, see StartPluginProcess.
+type Plugin struct {
+	// Name identifies plugin, e.g. in warnings.
+	Name string
+	// Detectors are checked in order, first detector matching all string values at path sets their type.
+	Detectors []Detector
+	// TypeMappers are called in order, types chosen by earlier mappers take precedence.
+	TypeMappers []TypeMapper
+	// Emitters generate output from IR, by name, see JSONParser.Emit.
+	Emitters map[string]Emitter
+
+	// close stops plugin process.
+	close func() error
+}
+
+// Detector detects custom format of json strings, like IP addresses, and sets go type of values with format.
+type Detector interface {
+	// Format is a name of format, like "ipv4".
+	Format() string
+	// Match checks if json string has format.
+	Match(s string) bool
+	// GoType returns qualified go type of values with format, like "net/netip.Addr". Type must unmarshal json strings.
+	GoType() string
+}
+
+// TypeMapper chooses go types of values, replacing inferred types.
+type TypeMapper interface {
+	// MapTypes returns qualified go types, like "github.com/google/uuid.UUID", of values at json paths, like "$.user.id".
+	// Kinds are inferred kinds of values by path, array elements have kinds of elements. Mapped types of array values
+	// are types of elements. Paths missing in result keep inferred types.
+	MapTypes(kinds map[string]SchemaKind) (map[string]string, error)
+}
+
+// Emitter generates output, like code in other language, from IR of parsed documents, see JSONParser.Schema.
+type Emitter interface {
+	Emit(ir *Schema) ([]byte, error)
+}
+
+// Close stops plugin process, started with StartPluginProcess. It does nothing for other plugins.
+func (p *Plugin) Close() error {
+	if p.close == nil {
+		return nil
+	}
+	return p.close()
+}
+
+var (
+	pluginsMu sync.RWMutex
+	plugins   = make(map[string]*Plugin)
+)
+
+// RegisterPlugin makes plugin available by name, see Plugins. It panics, if plugin with the same name is registered.
+func RegisterPlugin(p *Plugin) {
+	pluginsMu.Lock()
+	defer pluginsMu.Unlock()
+
+	if _, ok := plugins[p.Name]; ok {
+		panic("json2go: plugin registered twice: " + p.Name)
+	}
+	plugins[p.Name] = p
+}
+
+// Plugins returns registered plugins, sorted by name.
+func Plugins() []*Plugin {
+	pluginsMu.RLock()
+	defer pluginsMu.RUnlock()
+
+	var result []*Plugin
+	for _, p := range plugins {
+		result = append(result, p)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// pluginDetectors returns detectors of all plugins, in order of plugins.
+func pluginDetectors(plugins []*Plugin) []Detector {
+	var detectors []Detector
+	for _, p := range plugins {
+		detectors = append(detectors, p.Detectors...)
+	}
+	return detectors
+}
+
+// matchingDetectors returns detectors matching json value. For arrays, detectors matching all elements are returned.
+// Nulls match all detectors, other values than strings match none.
+func matchingDetectors(detectors []Detector, v interface{}) []Detector {
+	switch typedValue := v.(type) {
+	case nil:
+		return detectors
+	case []interface{}:
+		for _, el := range typedValue {
+			if len(detectors) == 0 {
+				break
+			}
+			detectors = matchingDetectors(detectors, el)
+		}
+		return detectors
+	case string:
+		var matching []Detector
+		for _, d := range detectors {
+			if d.Match(typedValue) {
+				matching = append(matching, d)
+			}
+		}
+		return matching
+	}
+	return nil
+}
+
+// applyPlugins sets go types of nodes in subtree, chosen by type mappers and detectors of plugins.
+// Types set by overrides aren't changed. Errors of plugins are reported as warnings.
+func (p *JSONParser) applyPlugins(root *node) {
+	if len(p.opts.plugins) == 0 {
+		return
+	}
+
+	nodes := make(map[string][]*node)
+	kinds := make(map[string]SchemaKind)
+	var walk func(n *node)
+	walk = func(n *node) {
+		if _, ok := p.opts.overrides.Types[n.path]; !ok && n.t != nodeTypeExtracted && !n.root {
+			nodes[n.path] = append(nodes[n.path], n)
+			kinds[n.path] = n.schemaKind()
+		}
+		for _, c := range n.children {
+			walk(c)
+		}
+	}
+	walk(root)
+
+	mapped := make(map[string]string)
+	for _, plugin := range p.opts.plugins {
+		for _, m := range plugin.TypeMappers {
+			types, err := m.MapTypes(kinds)
+			if err != nil {
+				p.warn(WarningPlugin, fmt.Sprintf("plugin %s: mapping types: %v", plugin.Name, err))
+				continue
+			}
+			typePaths := make([]string, 0, len(types))
+			for path := range types {
+				typePaths = append(typePaths, path)
+			}
+			// Paths are sorted, so warnings are in the same order.
+			sort.Strings(typePaths)
+			for _, path := range typePaths {
+				goType := types[path]
+				if _, ok := mapped[path]; ok || nodes[path] == nil {
+					continue
+				}
+				if !isGoType(goType) {
+					p.warn(WarningPlugin, fmt.Sprintf("plugin %s: %s: invalid go type: %q", plugin.Name, path, goType))
+					continue
+				}
+				mapped[path] = goType
+			}
+		}
+	}
+
+	paths := make([]string, 0, len(nodes))
+	for path := range nodes {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		for _, n := range nodes[path] {
+			if goType, ok := mapped[path]; ok {
+				forceGoType(n, goType)
+				continue
+			}
+			if n.t.id() != nodeTypeString.id() && n.t.id() != nodeTypeTime.id() {
+				continue
+			}
+			for _, d := range n.matching {
+				if !isGoType(d.GoType()) {
+					p.warn(WarningPlugin, fmt.Sprintf("format %s: invalid go type: %q", d.Format(), d.GoType()))
+					continue
+				}
+				n.logf("values have format %s", d.Format())
+				forceGoType(n, d.GoType())
+				break
+			}
+		}
+	}
+}
+
+// schemaKind returns kind of node's values, or of array elements.
+func (n *node) schemaKind() SchemaKind {
+	switch n.t.id() {
+	case nodeTypeBool.id():
+		return SchemaBool
+	case nodeTypeInt.id():
+		return SchemaInt
+	case nodeTypeFloat.id():
+		return SchemaFloat
+	case nodeTypeString.id():
+		return SchemaString
+	case nodeTypeTime.id():
+		return SchemaTime
+	case nodeTypeObject.id():
+		return SchemaStruct
+	case nodeTypeMap.id():
+		return SchemaMap
+	case nodeTypeRawMessage.id(), nodeTypeExtracted.id():
+		return SchemaOpaque
+	}
+	return SchemaAny
+}
+
+// Emit returns output of emitter of enabled plugins, generated from IR of parsed documents, see JSONParser.Schema.
+func (p *JSONParser) Emit(name string) ([]byte, error) {
+	for _, plugin := range p.opts.plugins {
+		if e, ok := plugin.Emitters[name]; ok {
+			return e.Emit(p.Schema())
+		}
+	}
+	return nil, fmt.Errorf("unknown emitter: %s", name)
+}
@@ -0,0 +1,212 @@
+package json2go
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net/textproto"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testTypeMapper map[string]string
+
+func (m testTypeMapper) MapTypes(kinds map[string]SchemaKind) (map[string]string, error) {
+	if len(m) == 0 {
+		return nil, errors.New("no types")
+	}
+	return m, nil
+}
+
+type testEmitter struct{}
+
+func (testEmitter) Emit(ir *Schema) ([]byte, error) {
+	return []byte("root " + ir.Root), nil
+}
+
+func TestParserPlugins(t *testing.T) {
+	t.Parallel()
+
+	ipv4 := patternDetector{format: "ipv4", re: regexp.MustCompile(`^\d+\.\d+\.\d+\.\d+$`), goType: "net/netip.Addr"}
+	testCases := []struct {
+		name     string
+		plugin   *Plugin
+		opts     []JSONParserOpt
+		input    string
+		expected map[string]string
+		warnings []string
+	}{
+		{
+			name:     "detector",
+			plugin:   &Plugin{Name: "net", Detectors: []Detector{ipv4}},
+			input:    `{"ip":"10.0.0.1","ips":["10.0.0.2",null],"host":"example.com","n":1}`,
+			expected: map[string]string{"IP": "netip.Addr", "Ips": "[]*netip.Addr", "Host": "string", "N": "int"},
+		},
+		{
+			name:     "detector and other strings",
+			plugin:   &Plugin{Name: "net", Detectors: []Detector{ipv4}},
+			input:    `[{"ip":"10.0.0.1"},{"ip":"localhost"}]`,
+			expected: map[string]string{"IP": "string"},
+		},
+		{
+			name: "type mapper before detector",
+			plugin: &Plugin{
+				Name:        "net",
+				Detectors:   []Detector{ipv4},
+				TypeMappers: []TypeMapper{testTypeMapper{"$.ip": "example.com/net.IP", "$.n": "invalid type"}},
+			},
+			input:    `{"ip":"10.0.0.1","n":1}`,
+			expected: map[string]string{"IP": "net.IP", "N": "int"},
+			warnings: []string{`plugin net: $.n: invalid go type: "invalid type"`},
+		},
+		{
+			name:     "overrides",
+			plugin:   &Plugin{Name: "net", Detectors: []Detector{ipv4}},
+			opts:     []JSONParserOpt{OptOverrides(Overrides{Types: map[string]string{"$.ip": "string"}})},
+			input:    `{"ip":"10.0.0.1"}`,
+			expected: map[string]string{"IP": "string"},
+		},
+		{
+			name:     "type mapper error",
+			plugin:   &Plugin{Name: "empty", TypeMappers: []TypeMapper{testTypeMapper{}}},
+			input:    `{"n":1}`,
+			expected: map[string]string{"N": "int"},
+			warnings: []string{"plugin empty: mapping types: no types"},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			parser := NewJSONParser(baseTypeName, append(tc.opts, OptPlugins(tc.plugin))...)
+			require.NoError(t, parser.FeedBytes([]byte(tc.input)))
+
+			types := make(map[string]string)
+			for _, f := range parser.Fields() {
+				types[f.Name] = f.Type
+			}
+			assert.Equal(t, tc.expected, types)
+			assert.Equal(t, tc.warnings, parser.Warnings())
+		})
+	}
+}
+
+func TestParserEmit(t *testing.T) {
+	t.Parallel()
+
+	parser := NewJSONParser(baseTypeName, OptPlugins(&Plugin{Name: "test", Emitters: map[string]Emitter{"root": testEmitter{}}}))
+	require.NoError(t, parser.FeedBytes([]byte(`{"a":1}`)))
+
+	out, err := parser.Emit("root")
+	require.NoError(t, err)
+	assert.Equal(t, "root Document", string(out))
+
+	_, err = parser.Emit("other")
+	assert.Error(t, err)
+}
+
+func TestRegisterPlugin(t *testing.T) {
+	t.Parallel()
+
+	p := &Plugin{Name: "json2go-test-registered"}
+	RegisterPlugin(p)
+	assert.Contains(t, Plugins(), p)
+	assert.Panics(t, func() {
+		RegisterPlugin(&Plugin{Name: p.Name})
+	})
+}
+
+func TestStartPluginProcess(t *testing.T) {
+	t.Parallel()
+
+	plugin, err := StartPluginProcess(os.Args[0], "-test.run=^TestPluginProcessHelper$", "--", "json2go-plugin")
+	require.NoError(t, err)
+
+	assert.Equal(t, "helper", plugin.Name)
+	parser := NewJSONParser(baseTypeName, OptPlugins(plugin))
+	require.NoError(t, parser.FeedBytes([]byte(`{"id":"4a1c7f5e-0b1e-4c2a-9f1d-2f0e8b6c9a11","ip":"10.0.0.1","n":1}`)))
+
+	types := make(map[string]string)
+	for _, f := range parser.Fields() {
+		types[f.Name] = f.Type
+	}
+	assert.Equal(t, map[string]string{"ID": "uuid.UUID", "IP": "netip.Addr", "N": "int"}, types)
+
+	out, err := parser.Emit("kinds")
+	require.NoError(t, err)
+	assert.Equal(t, "Document: id ip n", string(out))
+
+	_, err = parser.Emit("missing")
+	assert.Error(t, err)
+
+	require.NoError(t, plugin.Close())
+}
+
+// TestPluginProcessHelper is a plugin process started by TestStartPluginProcess.
+func TestPluginProcessHelper(t *testing.T) {
+	if os.Args[len(os.Args)-1] != "json2go-plugin" {
+		return
+	}
+
+	r := textproto.NewReader(bufio.NewReader(os.Stdin))
+	for {
+		body, err := readRPCMessage(r, 0)
+		if err != nil {
+			os.Exit(1)
+		}
+		var req rpcMessage
+		if err := json.Unmarshal(body, &req); err != nil {
+			os.Exit(1)
+		}
+
+		resp := rpcMessage{ID: req.ID}
+		switch req.Method {
+		case rpcMethodInit:
+			resp.Result = PluginManifest{
+				Name:       "helper",
+				Detectors:  []PluginPatternDetector{{Format: "ipv4", Pattern: `\d+\.\d+\.\d+\.\d+`, GoType: "net/netip.Addr"}},
+				TypeMapper: true,
+				Emitters:   []string{"kinds"},
+			}
+		case PluginMethodMapTypes:
+			var params PluginMapTypesParams
+			_ = json.Unmarshal(req.Params, &params)
+			types := make(map[string]string)
+			if params.Kinds["$.id"] == SchemaString {
+				types["$.id"] = "github.com/google/uuid.UUID"
+			}
+			resp.Result = PluginMapTypesResult{Types: types}
+		case PluginMethodEmit:
+			var params PluginEmitParams
+			_ = json.Unmarshal(req.Params, &params)
+			if params.Emitter != "kinds" {
+				resp.Error = &rpcError{Code: rpcInvalidParams, Message: "unknown emitter"}
+				break
+			}
+			ir, err := UnmarshalIR(params.IR)
+			if err != nil {
+				resp.Error = &rpcError{Code: rpcInvalidParams, Message: err.Error()}
+				break
+			}
+			var keys []string
+			for _, f := range ir.Types[ir.Root].Fields {
+				keys = append(keys, f.Key)
+			}
+			resp.Result = PluginEmitResult{Output: ir.Root + ": " + strings.Join(keys, " ")}
+		case rpcMethodDown:
+			resp.Result = json.RawMessage("null")
+		case rpcMethodExit:
+			os.Exit(0)
+		}
+		if err := writeRPCMessage(os.Stdout, resp); err != nil {
+			os.Exit(1)
+		}
+	}
+}
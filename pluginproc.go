@@ -0,0 +1,242 @@
+package json2go
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"sync"
+)
+
+// Methods of plugin processes, besides "initialize", "shutdown" and "exit" of Language Server Protocol lifecycle.
+const (
+	// PluginMethodMapTypes maps types of values, see PluginMapTypesParams and PluginMapTypesResult.
+	PluginMethodMapTypes = "json2go/mapTypes"
+	// PluginMethodEmit generates output from IR, see PluginEmitParams and PluginEmitResult.
+	PluginMethodEmit = "json2go/emit"
+)
+
+// PluginManifest is a result of "initialize" request to plugin process, describing plugin.
+type PluginManifest struct {
+	Name string `json:"name"`
+	// Detectors detect formats of strings matching regular expressions, evaluated by json2go.
+	Detectors []PluginPatternDetector `json:"detectors,omitempty"`
+	// TypeMapper is true, if plugin handles PluginMethodMapTypes requests.
+	TypeMapper bool `json:"typeMapper,omitempty"`
+	// Emitters are names of emitters, handled with PluginMethodEmit requests.
+	Emitters []string `json:"emitters,omitempty"`
+}
+
+// PluginPatternDetector detects format of strings fully matching regular expression in RE2 syntax, see Detector.
+type PluginPatternDetector struct {
+	Format  string `json:"format"`
+	Pattern string `json:"pattern"`
+	GoType  string `json:"goType"`
+}
+
+// PluginMapTypesParams are parameters of PluginMethodMapTypes request, see TypeMapper.
+type PluginMapTypesParams struct {
+	Kinds map[string]SchemaKind `json:"kinds"`
+}
+
+// PluginMapTypesResult is a result of PluginMethodMapTypes request, see TypeMapper.
+type PluginMapTypesResult struct {
+	Types map[string]string `json:"types"`
+}
+
+// PluginEmitParams are parameters of PluginMethodEmit request. IR is written by MarshalIR.
+type PluginEmitParams struct {
+	Emitter string          `json:"emitter"`
+	IR      json.RawMessage `json:"ir"`
+}
+
+// PluginEmitResult is a result of PluginMethodEmit request.
+type PluginEmitResult struct {
+	Output string `json:"output"`
+}
+
+// StartPluginProcess starts plugin process and returns plugin calling it. Plugin process serves JSON-RPC 2.0 requests
+// read from stdin, writing responses to stdout, with messages framed like in ServeRPC. It's described with response
+// to "initialize" request, see PluginManifest, and stopped with "shutdown" request and "exit" notification by
+// Plugin.Close. Plugins can be written in any language, as they exchange IR, see MarshalIR.
+func StartPluginProcess(command string, args ...string) (*Plugin, error) {
+	cmd := exec.Command(command, args...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("starting plugin process: %w", err)
+	}
+
+	c := &pluginClient{cmd: cmd, w: stdin, r: textproto.NewReader(bufio.NewReader(stdout))}
+	var manifest PluginManifest
+	if err := c.call(rpcMethodInit, struct{}{}, &manifest); err != nil {
+		_ = c.kill()
+		return nil, err
+	}
+
+	plugin := &Plugin{Name: manifest.Name, close: c.close}
+	for _, d := range manifest.Detectors {
+		re, err := regexp.Compile("^(?:" + d.Pattern + ")$")
+		if err != nil {
+			_ = c.kill()
+			return nil, fmt.Errorf("plugin %s: format %s: %w", manifest.Name, d.Format, err)
+		}
+		plugin.Detectors = append(plugin.Detectors, patternDetector{format: d.Format, re: re, goType: d.GoType})
+	}
+	if manifest.TypeMapper {
+		plugin.TypeMappers = append(plugin.TypeMappers, processTypeMapper{c})
+	}
+	for _, name := range manifest.Emitters {
+		if plugin.Emitters == nil {
+			plugin.Emitters = make(map[string]Emitter)
+		}
+		plugin.Emitters[name] = processEmitter{client: c, name: name}
+	}
+	return plugin, nil
+}
+
+// pluginClient sends requests to plugin process.
+type pluginClient struct {
+	mu     sync.Mutex
+	cmd    *exec.Cmd
+	w      io.WriteCloser
+	r      *textproto.Reader
+	lastID int
+}
+
+// pluginResponse is a response of plugin process, with result decoded later.
+type pluginResponse struct {
+	ID     *json.RawMessage `json:"id"`
+	Result json.RawMessage  `json:"result"`
+	Error  *rpcError        `json:"error"`
+}
+
+// call sends request to plugin process and decodes result of its response.
+func (c *pluginClient) call(method string, params, result interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var data json.RawMessage
+	if params != nil {
+		var err error
+		if data, err = json.Marshal(params); err != nil {
+			return err
+		}
+	}
+	c.lastID++
+	id := json.RawMessage(strconv.Itoa(c.lastID))
+	if err := writeRPCMessage(c.w, rpcMessage{ID: &id, Method: method, Params: data}); err != nil {
+		return fmt.Errorf("plugin %s request: %w", method, err)
+	}
+
+	for {
+		body, err := readRPCMessage(c.r, 0)
+		if err != nil {
+			return fmt.Errorf("plugin %s response: %w", method, err)
+		}
+		var resp pluginResponse
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return fmt.Errorf("plugin %s response: %w", method, err)
+		}
+		if resp.ID == nil || string(*resp.ID) != string(id) {
+			// Notifications and responses to other requests are skipped.
+			continue
+		}
+		if resp.Error != nil {
+			return fmt.Errorf("plugin %s: %s", method, resp.Error.Message)
+		}
+		if result == nil {
+			return nil
+		}
+		if err := json.Unmarshal(resp.Result, result); err != nil {
+			return fmt.Errorf("plugin %s result: %w", method, err)
+		}
+		return nil
+	}
+}
+
+// close stops plugin process gracefully.
+func (c *pluginClient) close() error {
+	if err := c.call(rpcMethodDown, nil, nil); err != nil {
+		_ = c.kill()
+		return err
+	}
+	c.mu.Lock()
+	err := writeRPCMessage(c.w, rpcMessage{Method: rpcMethodExit})
+	c.mu.Unlock()
+	if err != nil {
+		_ = c.kill()
+		return err
+	}
+	if err := c.w.Close(); err != nil {
+		_ = c.kill()
+		return err
+	}
+	return c.cmd.Wait()
+}
+
+func (c *pluginClient) kill() error {
+	_ = c.w.Close()
+	_ = c.cmd.Process.Kill()
+	return c.cmd.Wait()
+}
+
+// patternDetector detects format of strings matching regular expression.
+type patternDetector struct {
+	format string
+	re     *regexp.Regexp
+	goType string
+}
+
+func (d patternDetector) Format() string {
+	return d.format
+}
+
+func (d patternDetector) Match(s string) bool {
+	return d.re.MatchString(s)
+}
+
+func (d patternDetector) GoType() string {
+	return d.goType
+}
+
+// processTypeMapper maps types with PluginMethodMapTypes requests.
+type processTypeMapper struct {
+	client *pluginClient
+}
+
+func (m processTypeMapper) MapTypes(kinds map[string]SchemaKind) (map[string]string, error) {
+	var result PluginMapTypesResult
+	if err := m.client.call(PluginMethodMapTypes, PluginMapTypesParams{Kinds: kinds}, &result); err != nil {
+		return nil, err
+	}
+	return result.Types, nil
+}
+
+// processEmitter generates output with PluginMethodEmit requests.
+type processEmitter struct {
+	client *pluginClient
+	name   string
+}
+
+func (e processEmitter) Emit(ir *Schema) ([]byte, error) {
+	data, err := MarshalIR(ir)
+	if err != nil {
+		return nil, err
+	}
+	var result PluginEmitResult
+	if err := e.client.call(PluginMethodEmit, PluginEmitParams{Emitter: e.name, IR: data}, &result); err != nil {
+		return nil, err
+	}
+	return []byte(result.Output), nil
+}
@@ -0,0 +1,30 @@
+package json2go
+
+import (
+	"io"
+	"io/ioutil"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ReadProfiles reads yaml, or json, encoded configs by profile name, like:
+//
+//	frontend:
+//	  rootName: Response
+//	  optionalType: true
+//	backend-strict:
+//	  strictness: error
+//	  typeCheck: true
+//
+// Profiles are selected by requests to Service, see Service.Profiles.
+func ReadProfiles(r io.Reader) (map[string]Config, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var profiles map[string]Config
+	if err := yaml.UnmarshalStrict(data, &profiles); err != nil {
+		return nil, err
+	}
+	return profiles, nil
+}
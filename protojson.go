@@ -0,0 +1,116 @@
+package json2go
+
+import (
+	"fmt"
+	"go/ast"
+	"regexp"
+)
+
+// protoAnyTypeKey is a key of type URL of google.protobuf.Any values in proto json.
+const protoAnyTypeKey = "@type"
+
+var (
+	// protoDurationRe matches google.protobuf.Duration strings, like "1.5s".
+	protoDurationRe = regexp.MustCompile(`^-?\d+(\.\d{1,9})?s$`)
+	// protoInt64Re matches 64-bit integers encoded as strings, like "-12", without leading zeros.
+	protoInt64Re = regexp.MustCompile(`^-?(0|[1-9]\d{0,18})$`)
+)
+
+// isProtoAnyNode checks if node is an object with type URL, like google.protobuf.Any values.
+func isProtoAnyNode(n *node) bool {
+	return n.t.id() == nodeTypeObject.id() && n.getChild(protoAnyTypeKey) != nil
+}
+
+// astIsProtoDurationNode checks if all node's values are google.protobuf.Duration strings.
+func astIsProtoDurationNode(n *node) bool {
+	return n.t == nodeTypeString && n.formats&formatProtoDuration != 0
+}
+
+// astIsProtoInt64Node checks if all node's values are 64-bit integers encoded as strings.
+func astIsProtoInt64Node(n *node) bool {
+	return n.t == nodeTypeString && n.formats&formatProtoInt64 != 0
+}
+
+// astTypeFromProtoDurationNode returns helper type of durations unmarshaled from google.protobuf.Duration strings.
+func astTypeFromProtoDurationNode(ctx *astContext) ast.Expr {
+	ctx.addImport("encoding/json")
+	ctx.addImport("fmt")
+	ctx.addImport("strconv")
+	ctx.addImport("strings")
+	ctx.addImport("time")
+
+	return ast.NewIdent(ctx.addSharedHelper("ProtoDuration", func(name string) string {
+		return fmt.Sprintf(`
+// %[1]s is a duration represented in json as google.protobuf.Duration string, like "1.5s".
+type %[1]s struct {
+	time.Duration
+}
+
+// UnmarshalJSON unmarshals duration from json string with seconds.
+func (d *%[1]s) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	if !strings.HasSuffix(s, "s") {
+		return fmt.Errorf("invalid duration value: %%q", s)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("invalid duration value: %%q", s)
+	}
+	d.Duration = v
+	return nil
+}
+
+// MarshalJSON marshals duration as json string with seconds.
+func (d %[1]s) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s")
+}
+`, name)
+	}))
+}
+
+// astTypeFromProtoInt64Node returns helper type of 64-bit integers unmarshaled from json strings.
+func astTypeFromProtoInt64Node(ctx *astContext) ast.Expr {
+	ctx.addImport("encoding/json")
+	ctx.addImport("strconv")
+
+	return ast.NewIdent(ctx.addSharedHelper("ProtoInt64", func(name string) string {
+		return fmt.Sprintf(`
+// %[1]s is a 64-bit integer represented in json as a string, like int64 values in proto json.
+type %[1]s int64
+
+// UnmarshalJSON unmarshals integer from json string or number.
+func (n *%[1]s) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		var v int64
+		if err := json.Unmarshal(data, &v); err != nil {
+			return err
+		}
+		*n = %[1]s(v)
+		return nil
+	}
+
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return err
+	}
+	*n = %[1]s(v)
+	return nil
+}
+
+// MarshalJSON marshals integer as json string.
+func (n %[1]s) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strconv.FormatInt(int64(n), 10))
+}
+`, name)
+	}))
+}
@@ -0,0 +1,104 @@
+package json2go
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserProtoJSON(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		disabled bool
+		inputs   []string
+		expected string
+	}{
+		{
+			name:     "disabled",
+			disabled: true,
+			inputs:   []string{`{"v":"1.5s"}`},
+			expected: "string",
+		},
+		{
+			name:     "duration",
+			inputs:   []string{`{"v":"1.5s"}`, `{"v":"-3.000000001s"}`, `{"v":"0s"}`},
+			expected: "ProtoDuration",
+		},
+		{
+			name:     "not duration",
+			inputs:   []string{`{"v":"1.5s"}`, `{"v":"1m"}`},
+			expected: "string",
+		},
+		{
+			name:     "int64",
+			inputs:   []string{`{"v":"9223372036854775807"}`, `{"v":"-1"}`, `{"v":"0"}`},
+			expected: "ProtoInt64",
+		},
+		{
+			name:     "leading zeros",
+			inputs:   []string{`{"v":"1"}`, `{"v":"01234"}`},
+			expected: "string",
+		},
+		{
+			name:     "array of int64",
+			inputs:   []string{`{"v":["1","2"]}`},
+			expected: "[]ProtoInt64",
+		},
+		{
+			name:     "timestamp",
+			inputs:   []string{`{"v":"2021-01-01T00:00:00.123456789Z"}`, `{"v":"2021-01-01T00:00:00Z"}`},
+			expected: "time.Time",
+		},
+		{
+			name:     "any",
+			inputs:   []string{`{"v":{"@type":"type.googleapis.com/google.rpc.ErrorInfo","reason":"x"}}`},
+			expected: "json.RawMessage",
+		},
+		{
+			name: "array of any",
+			inputs: []string{`{"v":[{"@type":"type.googleapis.com/google.rpc.ErrorInfo","reason":"x"},` +
+				`{"@type":"type.googleapis.com/google.rpc.RetryInfo","retryDelay":"1s"}]}`},
+			expected: "[]json.RawMessage",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			parser := NewJSONParser(baseTypeName, OptProtoJSON(!tc.disabled))
+			for _, input := range tc.inputs {
+				require.NoError(t, parser.FeedBytes([]byte(input)))
+			}
+
+			fields := parser.Fields()
+			require.Len(t, fields, 1)
+			assert.Equal(t, tc.expected, fields[0].Type)
+		})
+	}
+}
+
+func TestParserProtoJSONCode(t *testing.T) {
+	t.Parallel()
+
+	parser := NewJSONParser(baseTypeName, OptProtoJSON(true))
+	require.NoError(t, parser.FeedBytes([]byte(`{"id":"12","ttl":"1.5s"}`)))
+
+	out := runGeneratedCode(t, parser, `
+	var d Document
+	if err := json.NewDecoder(os.Stdin).Decode(&d); err != nil {
+		panic(err)
+	}
+	fmt.Println(int64(d.ID), d.TTL.Duration)
+	out, err := json.Marshal(d)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(out))
+`, `{"id":"-9007199254740993","ttl":"-0.000000001s"}`)
+	assert.Equal(t, "-9007199254740993 -1ns\n"+`{"id":"-9007199254740993","ttl":"-0.000000001s"}`+"\n", out)
+}
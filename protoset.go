@@ -0,0 +1,342 @@
+package json2go
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Types and labels of fields of protobuf messages, numbered like in google/protobuf/descriptor.proto.
+const (
+	protoTypeDouble  = 1
+	protoTypeFloat   = 2
+	protoTypeInt64   = 3
+	protoTypeInt32   = 5
+	protoTypeBool    = 8
+	protoTypeString  = 9
+	protoTypeMessage = 11
+
+	protoLabelOptional = 1
+	protoLabelRepeated = 3
+)
+
+// Well-known types of protobuf representing json values of types without message declarations.
+const (
+	protoTimestamp = ".google.protobuf.Timestamp"
+	protoValue     = ".google.protobuf.Value"
+	protoListValue = ".google.protobuf.ListValue"
+	protoStruct    = ".google.protobuf.Struct"
+)
+
+// protoDependencies are files declaring well-known types.
+var protoDependencies = map[string]string{
+	protoTimestamp: "google/protobuf/timestamp.proto",
+	protoValue:     "google/protobuf/struct.proto",
+	protoListValue: "google/protobuf/struct.proto",
+	protoStruct:    "google/protobuf/struct.proto",
+}
+
+// protoFile is a FileDescriptorProto of proto3 file.
+type protoFile struct {
+	name         string
+	pkg          string
+	dependencies []string
+	messages     []*protoMessage
+}
+
+// protoMessage is a DescriptorProto of message.
+type protoMessage struct {
+	name     string
+	fields   []protoField
+	nested   []*protoMessage
+	oneofs   []string
+	mapEntry bool
+}
+
+// protoField is a FieldDescriptorProto of message field.
+type protoField struct {
+	name     string
+	jsonName string
+	number   int
+	label    int
+	typ      int
+	typeName string
+	// optional is true for proto3 optional fields, members of synthetic oneof with index oneof.
+	optional bool
+	oneof    int
+}
+
+// ProtoDescriptorEmitter emits FileDescriptorSet, serialized in protobuf binary format, with single proto3 file
+// declaring messages of IR, root message first. Tooling can register the set with descriptors of well-known
+// types, which aren't included, without compiling .proto files.
+//
+// Messages keep go names and fields are go names in snake case, with json names of json keys, so json
+// mapping of messages matches parsed documents. Scalar fields of pointers, optional values or with omitempty
+// are proto3 optional, maps are map fields, and times are google.protobuf.Timestamp. Any values, nested
+// arrays and maps, which can't be values of map and repeated fields, are google.protobuf.Value,
+// ListValue and Struct.
+type ProtoDescriptorEmitter struct {
+	// Package is a protobuf package of messages, snake case name of root type if empty.
+	Package string
+}
+
+// Emit returns serialized FileDescriptorSet.
+func (e ProtoDescriptorEmitter) Emit(ir *Schema) ([]byte, error) {
+	f, err := e.file(ir)
+	if err != nil {
+		return nil, err
+	}
+	var set protoBuffer
+	set.message(1, f.marshal())
+	return set, nil
+}
+
+// file returns descriptor of file declaring messages of IR.
+func (e ProtoDescriptorEmitter) file(ir *Schema) (*protoFile, error) {
+	s, err := newIDLSchema(ir, "protobuf message", false)
+	if err != nil {
+		return nil, err
+	}
+	pkg := e.Package
+	if pkg == "" {
+		pkg = snakeCaseName(capnpName(ir.Root, true))
+	}
+	f := &protoFile{name: strings.Replace(pkg, ".", "/", -1) + ".proto", pkg: pkg}
+
+	// Declared names are sanitized, and may collide.
+	names := make(map[*SchemaType]string, len(s.decls))
+	used := make(map[string]bool, len(s.decls))
+	for _, d := range s.decls {
+		name := capnpName(d.name, true)
+		for used[name] {
+			name = nextName(name)
+		}
+		used[name] = true
+		names[d.t] = name
+	}
+
+	dependencies := make(map[string]bool)
+	b := protoBuilder{ir: ir, pkg: pkg, names: names, dependencies: dependencies}
+	for _, d := range s.decls {
+		f.messages = append(f.messages, b.message(names[d.t], d.fields))
+	}
+	for dependency := range dependencies {
+		f.dependencies = append(f.dependencies, dependency)
+	}
+	sort.Strings(f.dependencies)
+	return f, nil
+}
+
+// protoBuilder builds descriptors of messages.
+type protoBuilder struct {
+	ir    *Schema
+	pkg   string
+	names map[*SchemaType]string
+	// dependencies are files declaring used well-known types.
+	dependencies map[string]bool
+}
+
+// message returns descriptor of message with fields, and with entries of map fields as nested messages.
+func (b protoBuilder) message(name string, fields []SchemaField) *protoMessage {
+	m := &protoMessage{name: name}
+	used := make(map[string]bool, len(fields))
+	for i, sf := range fields {
+		fieldName := snakeCaseName(capnpName(sf.Name, true))
+		for used[fieldName] {
+			fieldName = nextName(fieldName)
+		}
+		used[fieldName] = true
+
+		f := protoField{name: fieldName, jsonName: sf.Key, number: i + 1, label: protoLabelOptional}
+		t := b.elem(sf.Type)
+		switch t.Kind {
+		case SchemaSlice:
+			f.label = protoLabelRepeated
+			f.typ, f.typeName = b.scalar(b.elem(t.Elem))
+		case SchemaMap:
+			entry := protoMapEntryName(fieldName)
+			m.nested = append(m.nested, b.entry(entry, t))
+			f.label = protoLabelRepeated
+			f.typ, f.typeName = protoTypeMessage, "."+b.pkg+"."+name+"."+entry
+		default:
+			f.typ, f.typeName = b.scalar(t)
+			if f.typ != protoTypeMessage && (sf.isOptional() || sf.Type.Kind == SchemaPointer || sf.Type.Kind == SchemaOptional) {
+				f.optional = true
+				f.oneof = len(m.oneofs)
+				m.oneofs = append(m.oneofs, "_"+fieldName)
+			}
+		}
+		m.fields = append(m.fields, f)
+	}
+	return m
+}
+
+// entry returns descriptor of entries of map field.
+func (b protoBuilder) entry(name string, t *SchemaType) *protoMessage {
+	key := protoField{name: "key", jsonName: "key", number: 1, label: protoLabelOptional, typ: protoTypeString}
+	if t.Key != nil && t.Key.Kind == SchemaInt {
+		key.typ = protoTypeInt64
+	}
+	value := protoField{name: "value", jsonName: "value", number: 2, label: protoLabelOptional}
+	value.typ, value.typeName = b.scalar(b.elem(t.Elem))
+	return &protoMessage{name: name, fields: []protoField{key, value}, mapEntry: true}
+}
+
+// elem returns type of values, without pointers and optional values, and with resolved names.
+func (b protoBuilder) elem(t *SchemaType) *SchemaType {
+	for {
+		switch t.Kind {
+		case SchemaPointer, SchemaOptional:
+			t = t.Elem
+		case SchemaNamed:
+			t = b.ir.Types[t.Name]
+		default:
+			return t
+		}
+	}
+}
+
+// scalar returns field type and name of message type of single values. Arrays and maps are well-known types,
+// for values of repeated and map fields.
+func (b protoBuilder) scalar(t *SchemaType) (typ int, typeName string) {
+	switch t.Kind {
+	case SchemaBool:
+		return protoTypeBool, ""
+	case SchemaInt:
+		if t.Bits == 32 {
+			return protoTypeInt32, ""
+		}
+		return protoTypeInt64, ""
+	case SchemaFloat:
+		if t.Bits == 32 {
+			return protoTypeFloat, ""
+		}
+		return protoTypeDouble, ""
+	case SchemaString:
+		return protoTypeString, ""
+	case SchemaStruct:
+		return protoTypeMessage, "." + b.pkg + "." + b.names[t]
+	case SchemaTime:
+		typeName = protoTimestamp
+	case SchemaSlice:
+		typeName = protoListValue
+	case SchemaMap:
+		typeName = protoStruct
+	default:
+		typeName = protoValue
+	}
+	b.dependencies[protoDependencies[typeName]] = true
+	return protoTypeMessage, typeName
+}
+
+// protoMapEntryName returns name of entry message of map field, like "LabelsEntry" of "labels".
+func protoMapEntryName(fieldName string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range fieldName {
+		if r == '_' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	b.WriteString("Entry")
+	return b.String()
+}
+
+// marshal returns serialized FileDescriptorProto.
+func (f *protoFile) marshal() []byte {
+	var b protoBuffer
+	b.string(1, f.name)
+	b.string(2, f.pkg)
+	for _, dependency := range f.dependencies {
+		b.string(3, dependency)
+	}
+	for _, m := range f.messages {
+		b.message(4, m.marshal())
+	}
+	b.string(12, "proto3")
+	return b
+}
+
+// marshal returns serialized DescriptorProto.
+func (m *protoMessage) marshal() []byte {
+	var b protoBuffer
+	b.string(1, m.name)
+	for _, f := range m.fields {
+		b.message(2, f.marshal())
+	}
+	for _, nested := range m.nested {
+		b.message(3, nested.marshal())
+	}
+	if m.mapEntry {
+		var options protoBuffer
+		options.varint(7, 1)
+		b.message(7, options)
+	}
+	for _, oneof := range m.oneofs {
+		var decl protoBuffer
+		decl.string(1, oneof)
+		b.message(8, decl)
+	}
+	return b
+}
+
+// marshal returns serialized FieldDescriptorProto.
+func (f protoField) marshal() []byte {
+	var b protoBuffer
+	b.string(1, f.name)
+	b.varint(3, uint64(f.number))
+	b.varint(4, uint64(f.label))
+	b.varint(5, uint64(f.typ))
+	if f.typeName != "" {
+		b.string(6, f.typeName)
+	}
+	if f.optional {
+		b.varint(9, uint64(f.oneof))
+	}
+	b.string(10, f.jsonName)
+	if f.optional {
+		b.varint(17, 1)
+	}
+	return b
+}
+
+// protoBuffer is a message serialized in protobuf binary format.
+type protoBuffer []byte
+
+// varint appends field with varint value.
+func (b *protoBuffer) varint(field int, v uint64) {
+	b.appendVarint(uint64(field) << 3)
+	b.appendVarint(v)
+}
+
+// string appends length-delimited field with string value.
+func (b *protoBuffer) string(field int, s string) {
+	b.message(field, []byte(s))
+}
+
+// message appends length-delimited field with serialized message.
+func (b *protoBuffer) message(field int, data []byte) {
+	b.appendVarint(uint64(field)<<3 | 2)
+	b.appendVarint(uint64(len(data)))
+	*b = append(*b, data...)
+}
+
+// appendVarint appends base 128 varint.
+func (b *protoBuffer) appendVarint(v uint64) {
+	for v >= 0x80 {
+		*b = append(*b, byte(v)|0x80)
+		v >>= 7
+	}
+	*b = append(*b, byte(v))
+}
+
+// ProtoDescriptorSet returns FileDescriptorSet describing parsed documents, see ProtoDescriptorEmitter.
+func (p *JSONParser) ProtoDescriptorSet(pkg string) ([]byte, error) {
+	return ProtoDescriptorEmitter{Package: pkg}.Emit(p.Schema())
+}
@@ -0,0 +1,131 @@
+package json2go
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProtoDescriptorEmitterFile(t *testing.T) {
+	t.Parallel()
+
+	ir := &Schema{Root: "Document", Types: map[string]*SchemaType{
+		"Document": {Kind: SchemaStruct, Fields: []SchemaField{
+			{Name: "UserID", Key: "userId", Type: &SchemaType{Kind: SchemaInt, Bits: 64}},
+			{Name: "Score", Key: "score", Type: &SchemaType{Kind: SchemaPointer, Elem: &SchemaType{Kind: SchemaFloat, Bits: 64}}},
+			{Name: "Tags", Key: "tags", Type: &SchemaType{Kind: SchemaSlice, Elem: &SchemaType{Kind: SchemaString}}},
+			{Name: "Matrix", Key: "matrix", Type: &SchemaType{Kind: SchemaSlice,
+				Elem: &SchemaType{Kind: SchemaSlice, Elem: &SchemaType{Kind: SchemaInt, Bits: 32}}}},
+			{Name: "Labels", Key: "labels", Type: &SchemaType{Kind: SchemaMap, Key: &SchemaType{Kind: SchemaString},
+				Elem: &SchemaType{Kind: SchemaNamed, Name: "Label"}}},
+			{Name: "At", Key: "at", Type: &SchemaType{Kind: SchemaTime}, OmitEmpty: true},
+			{Name: "Extra", Key: "extra", Type: &SchemaType{Kind: SchemaAny}},
+			{Name: "Addr", Key: "addr", Type: &SchemaType{Kind: SchemaStruct, Fields: []SchemaField{
+				{Name: "Zip", Key: "zip", Type: &SchemaType{Kind: SchemaString}, OmitEmpty: true},
+			}}},
+		}},
+		"Label": {Kind: SchemaStruct, Fields: []SchemaField{
+			{Name: "Parent", Key: "parent", Type: &SchemaType{Kind: SchemaPointer, Elem: &SchemaType{Kind: SchemaNamed, Name: "Label"}}},
+		}},
+	}}
+
+	f, err := ProtoDescriptorEmitter{Package: "acme.v1"}.file(ir)
+	require.NoError(t, err)
+	assert.Equal(t, &protoFile{
+		name:         "acme/v1.proto",
+		pkg:          "acme.v1",
+		dependencies: []string{"google/protobuf/struct.proto", "google/protobuf/timestamp.proto"},
+		messages: []*protoMessage{
+			{
+				name: "Document",
+				fields: []protoField{
+					{name: "user_id", jsonName: "userId", number: 1, label: protoLabelOptional, typ: protoTypeInt64},
+					{name: "score", jsonName: "score", number: 2, label: protoLabelOptional, typ: protoTypeDouble, optional: true},
+					{name: "tags", jsonName: "tags", number: 3, label: protoLabelRepeated, typ: protoTypeString},
+					{name: "matrix", jsonName: "matrix", number: 4, label: protoLabelRepeated, typ: protoTypeMessage,
+						typeName: protoListValue},
+					{name: "labels", jsonName: "labels", number: 5, label: protoLabelRepeated, typ: protoTypeMessage,
+						typeName: ".acme.v1.Document.LabelsEntry"},
+					{name: "at", jsonName: "at", number: 6, label: protoLabelOptional, typ: protoTypeMessage, typeName: protoTimestamp},
+					{name: "extra", jsonName: "extra", number: 7, label: protoLabelOptional, typ: protoTypeMessage, typeName: protoValue},
+					{name: "addr", jsonName: "addr", number: 8, label: protoLabelOptional, typ: protoTypeMessage,
+						typeName: ".acme.v1.DocumentAddr"},
+				},
+				nested: []*protoMessage{{
+					name: "LabelsEntry",
+					fields: []protoField{
+						{name: "key", jsonName: "key", number: 1, label: protoLabelOptional, typ: protoTypeString},
+						{name: "value", jsonName: "value", number: 2, label: protoLabelOptional, typ: protoTypeMessage,
+							typeName: ".acme.v1.Label"},
+					},
+					mapEntry: true,
+				}},
+				oneofs: []string{"_score"},
+			},
+			{
+				name: "Label",
+				fields: []protoField{
+					{name: "parent", jsonName: "parent", number: 1, label: protoLabelOptional, typ: protoTypeMessage,
+						typeName: ".acme.v1.Label"},
+				},
+			},
+			{
+				name: "DocumentAddr",
+				fields: []protoField{
+					{name: "zip", jsonName: "zip", number: 1, label: protoLabelOptional, typ: protoTypeString, optional: true},
+				},
+				oneofs: []string{"_zip"},
+			},
+		},
+	}, f)
+}
+
+func TestProtoDescriptorEmitter(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		ir       *Schema
+		expected []byte
+		err      error
+	}{
+		{
+			name: "required and proto3 optional fields",
+			ir: &Schema{Root: "M", Types: map[string]*SchemaType{
+				"M": {Kind: SchemaStruct, Fields: []SchemaField{
+					{Name: "X", Key: "x", Type: &SchemaType{Kind: SchemaString}},
+					{Name: "Y", Key: "y", Type: &SchemaType{Kind: SchemaInt, Bits: 64}, OmitEmpty: true},
+				}},
+			}},
+			expected: []byte("\x0a\x40" +
+				"\x0a\x07m.proto\x12\x01m\x22\x2a" +
+				"\x0a\x01M" +
+				"\x12\x0c\x0a\x01x\x18\x01\x20\x01\x28\x09\x52\x01x" +
+				"\x12\x11\x0a\x01y\x18\x02\x20\x01\x28\x03\x48\x00\x52\x01y\x88\x01\x01" +
+				"\x42\x04\x0a\x02_y" +
+				"\x62\x06proto3"),
+		},
+		{
+			name: "root isn't struct",
+			ir:   &Schema{Root: "Document", Types: map[string]*SchemaType{"Document": {Kind: SchemaString}}},
+			err:  ErrUnsupportedShape,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			out, err := ProtoDescriptorEmitter{}.Emit(tc.ir)
+			if tc.err != nil {
+				assert.True(t, errors.Is(err, tc.err), "unexpected error: %v", err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, out)
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package json2go
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// pythonKeywords are keywords of Python, and names of builtin constants, which can't be attribute names.
+var pythonKeywords = map[string]bool{
+	"False": true, "None": true, "True": true, "and": true, "as": true, "assert": true, "async": true, "await": true,
+	"break": true, "class": true, "continue": true, "def": true, "del": true, "elif": true, "else": true,
+	"except": true, "finally": true, "for": true, "from": true, "global": true, "if": true, "import": true,
+	"in": true, "is": true, "lambda": true, "nonlocal": true, "not": true, "or": true, "pass": true, "raise": true,
+	"return": true, "try": true, "while": true, "with": true, "yield": true,
+}
+
+// PythonEmitter emits Python classes from IR: Pydantic v2 models, or dataclasses. Classes of nested structs
+// are declared before classes using them, root class last.
+//
+// Classes keep go names and attributes are go names in snake case. Attributes named differently than json keys
+// have aliases: Field aliases of models, or "alias" metadata of dataclass fields, for libraries converting
+// dataclasses. Fields of pointers, optional values or with omitempty are Optional, with None defaults.
+// Times are datetimes, uuids are UUIDs and any or opaque values are Any.
+type PythonEmitter struct {
+	// Dataclasses emits keyword-only dataclasses instead of Pydantic models.
+	Dataclasses bool
+}
+
+// Emit returns Python module source.
+func (e PythonEmitter) Emit(ir *Schema) ([]byte, error) {
+	s, err := newIDLSchema(ir, "Python class", false)
+	if err != nil {
+		return nil, err
+	}
+
+	imports := make(map[string]map[string]bool)
+	addImport := func(module, name string) {
+		if imports[module] == nil {
+			imports[module] = make(map[string]bool)
+		}
+		imports[module][name] = true
+	}
+	if e.Dataclasses {
+		addImport("dataclasses", "dataclass")
+	} else {
+		addImport("pydantic", "BaseModel")
+	}
+
+	var classes strings.Builder
+	for i := len(s.decls) - 1; i >= 0; i-- {
+		d := s.decls[i]
+		if e.Dataclasses {
+			fmt.Fprintf(&classes, "\n\n@dataclass(kw_only=True)\nclass %s:\n", d.name)
+		} else {
+			fmt.Fprintf(&classes, "\n\nclass %s(BaseModel):\n", d.name)
+		}
+		if len(d.fields) == 0 {
+			classes.WriteString("    pass\n")
+		}
+		names := make(map[string]bool)
+		for _, f := range d.fields {
+			name := snakeCaseName(f.Name)
+			if pythonKeywords[name] || e.Dataclasses && name == "field" {
+				// Attribute "field" would shadow dataclasses.field in class body.
+				name += "_"
+			}
+			for names[name] {
+				name = nextName(name)
+			}
+			names[name] = true
+
+			typ := pythonType(ir, s, f.Type, addImport)
+			var args []string
+			if f.isOptional() {
+				if !strings.HasPrefix(typ, "Optional[") && typ != "Any" {
+					addImport("typing", "Optional")
+					typ = "Optional[" + typ + "]"
+				}
+				args = append(args, "default=None")
+			}
+			if name != f.Key {
+				if e.Dataclasses {
+					args = append(args, "metadata={"+strconv.Quote("alias")+": "+strconv.Quote(f.Key)+"}")
+				} else {
+					args = append(args, "alias="+strconv.Quote(f.Key))
+				}
+			}
+
+			field := fmt.Sprintf("    %s: %s", name, typ)
+			switch {
+			case len(args) == 1 && args[0] == "default=None":
+				field += " = None"
+			case len(args) > 0 && e.Dataclasses:
+				addImport("dataclasses", "field")
+				field += " = field(" + strings.Join(args, ", ") + ")"
+			case len(args) > 0:
+				addImport("pydantic", "Field")
+				field += " = Field(" + strings.Join(args, ", ") + ")"
+			}
+			classes.WriteString(field + "\n")
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("from __future__ import annotations\n")
+	for _, group := range [][]string{{"dataclasses", "datetime", "typing", "uuid"}, {"pydantic"}} {
+		var lines []string
+		for _, module := range group {
+			if imports[module] == nil {
+				continue
+			}
+			names := make([]string, 0, len(imports[module]))
+			for name := range imports[module] {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			lines = append(lines, fmt.Sprintf("from %s import %s\n", module, strings.Join(names, ", ")))
+		}
+		if len(lines) > 0 {
+			b.WriteString("\n" + strings.Join(lines, ""))
+		}
+	}
+	b.WriteString(classes.String())
+	return []byte(b.String()), nil
+}
+
+// pythonType returns Python type annotation of values. Imports of used types are added with addImport.
+func pythonType(ir *Schema, s *idlSchema, t *SchemaType, addImport func(module, name string)) string {
+	switch t.Kind {
+	case SchemaBool:
+		return "bool"
+	case SchemaInt:
+		return "int"
+	case SchemaFloat:
+		return "float"
+	case SchemaString:
+		if t.Format == SchemaFormatUUID {
+			addImport("uuid", "UUID")
+			return "UUID"
+		}
+		return "str"
+	case SchemaTime:
+		addImport("datetime", "datetime")
+		return "datetime"
+	case SchemaPointer, SchemaOptional:
+		elem := pythonType(ir, s, t.Elem, addImport)
+		if strings.HasPrefix(elem, "Optional[") || elem == "Any" {
+			return elem
+		}
+		addImport("typing", "Optional")
+		return "Optional[" + elem + "]"
+	case SchemaSlice:
+		return "list[" + pythonType(ir, s, t.Elem, addImport) + "]"
+	case SchemaMap:
+		key := "str"
+		if t.Key != nil {
+			key = pythonType(ir, s, t.Key, addImport)
+		}
+		return "dict[" + key + ", " + pythonType(ir, s, t.Elem, addImport) + "]"
+	case SchemaStruct:
+		return s.names[t]
+	case SchemaNamed:
+		return pythonType(ir, s, ir.Types[t.Name], addImport)
+	}
+	addImport("typing", "Any")
+	return "Any"
+}
+
+// PythonTypes returns Python classes describing parsed documents, Pydantic models or dataclasses, see PythonEmitter.
+func (p *JSONParser) PythonTypes(dataclasses bool) (string, error) {
+	out, err := PythonEmitter{Dataclasses: dataclasses}.Emit(p.Schema())
+	return string(out), err
+}
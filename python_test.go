@@ -0,0 +1,152 @@
+package json2go
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPythonTypes(t *testing.T) {
+	t.Parallel()
+
+	p := NewJSONParser(baseTypeName)
+	require.NoError(t, p.FeedBytes([]byte(`{"id": 1, "userName": "a", "tags": ["x"], "addr": {"zip": "1"},
+		"at": "2021-01-01T00:00:00Z", "extra": 1, "class": "c", "field": true}`)))
+	require.NoError(t, p.FeedBytes([]byte(`{"id": 2, "tags": [], "addr": {"zip": "2"},
+		"at": "2021-01-02T00:00:00Z", "extra": "x", "score": 0.5, "class": "c", "field": false}`)))
+
+	testCases := []struct {
+		name        string
+		dataclasses bool
+		expected    string
+	}{
+		{
+			name: "pydantic",
+			expected: `from __future__ import annotations
+
+from datetime import datetime
+from typing import Any, Optional
+
+from pydantic import BaseModel, Field
+
+
+class DocumentAddr(BaseModel):
+    zip: str
+
+
+class Document(BaseModel):
+    addr: DocumentAddr
+    at: datetime
+    class_: str = Field(alias="class")
+    extra: Any
+    field: bool
+    id: int
+    score: Optional[float] = None
+    tags: list[str]
+    user_name: Optional[str] = Field(default=None, alias="userName")
+`,
+		},
+		{
+			name:        "dataclasses",
+			dataclasses: true,
+			expected: `from __future__ import annotations
+
+from dataclasses import dataclass, field
+from datetime import datetime
+from typing import Any, Optional
+
+
+@dataclass(kw_only=True)
+class DocumentAddr:
+    zip: str
+
+
+@dataclass(kw_only=True)
+class Document:
+    addr: DocumentAddr
+    at: datetime
+    class_: str = field(metadata={"alias": "class"})
+    extra: Any
+    field_: bool = field(metadata={"alias": "field"})
+    id: int
+    score: Optional[float] = None
+    tags: list[str]
+    user_name: Optional[str] = field(default=None, metadata={"alias": "userName"})
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			src, err := p.PythonTypes(tc.dataclasses)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, src)
+		})
+	}
+}
+
+func TestPythonEmitter(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		ir       *Schema
+		expected string
+		err      error
+	}{
+		{
+			name: "recursive type, maps and empty class",
+			ir: &Schema{Root: "Node", Types: map[string]*SchemaType{
+				"Node": {Kind: SchemaStruct, Fields: []SchemaField{
+					{Name: "Parent", Key: "parent", Type: &SchemaType{Kind: SchemaPointer, Elem: &SchemaType{Kind: SchemaNamed, Name: "Node"}}},
+					{Name: "Votes", Key: "votes", Type: &SchemaType{Kind: SchemaMap, Key: &SchemaType{Kind: SchemaString, Format: SchemaFormatUUID},
+						Elem: &SchemaType{Kind: SchemaSlice, Elem: &SchemaType{Kind: SchemaOptional, Elem: &SchemaType{Kind: SchemaInt}}}}},
+					{Name: "Meta", Key: "meta", Type: &SchemaType{Kind: SchemaNamed, Name: "Meta"}},
+				}},
+				"Meta": {Kind: SchemaStruct},
+			}},
+			expected: `from __future__ import annotations
+
+from typing import Optional
+from uuid import UUID
+
+from pydantic import BaseModel
+
+
+class Meta(BaseModel):
+    pass
+
+
+class Node(BaseModel):
+    parent: Optional[Node] = None
+    votes: dict[UUID, list[Optional[int]]]
+    meta: Meta
+`,
+		},
+		{
+			name: "root isn't struct",
+			ir:   &Schema{Root: "Document", Types: map[string]*SchemaType{"Document": {Kind: SchemaString}}},
+			err:  ErrUnsupportedShape,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			out, err := PythonEmitter{}.Emit(tc.ir)
+			if tc.err != nil {
+				assert.True(t, errors.Is(err, tc.err), "unexpected error: %v", err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(out))
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package json2go
+
+import (
+	"fmt"
+	"go/ast"
+	"net/url"
+	"regexp"
+)
+
+// queryStringRe matches url encoded queries and form bodies with at least one key=value pair. Values can't contain
+// unescaped "=", so base64 strings with padding aren't matched.
+var queryStringRe = regexp.MustCompile(`^[\w.\-\[\]%+]+=[^=&\s]*(&[\w.\-\[\]%+]+=[^=&\s]*)*$`)
+
+// isQueryString checks if string is url encoded query or form body, like "a=1&b=2".
+// At least one value must be non-empty, so strings like "YWI=" aren't matched.
+func isQueryString(s string) bool {
+	if !queryStringRe.MatchString(s) {
+		return false
+	}
+	query, err := url.ParseQuery(s)
+	if err != nil {
+		return false
+	}
+	for _, values := range query {
+		for _, v := range values {
+			if v != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// decodeQueryString decodes url encoded query to json object value. Keys with one value are strings,
+// repeated keys are arrays of strings.
+func decodeQueryString(s string) map[string]interface{} {
+	query, _ := url.ParseQuery(s)
+	obj := make(map[string]interface{}, len(query))
+	for key, values := range query {
+		if len(values) == 1 {
+			obj[key] = values[0]
+			continue
+		}
+		var arr []interface{}
+		for _, v := range values {
+			arr = append(arr, v)
+		}
+		obj[key] = arr
+	}
+	return obj
+}
+
+// astIsQueryStringNode checks if all node's values are url encoded queries.
+func astIsQueryStringNode(n *node) bool {
+	return n.t == nodeTypeString && n.formats&formatQueryString != 0 && n.encoded != nil
+}
+
+// astTypeFromQueryStringNode returns helper type of values decoded from url encoded queries, with json methods
+// converting it from/to json string.
+func astTypeFromQueryStringNode(n *node, ctx *astContext) ast.Expr {
+	name := n.name
+	if n.arrayLevel > 0 {
+		// Each element of array is a query string.
+		name = singularName(name, ctx.opts.singulars)
+	}
+	name = ctx.uniqueName(name + "Query")
+	ctx.addImport("encoding/json")
+	ctx.addImport("fmt")
+	ctx.addImport("net/url")
+	valueType := astExprString(astTypeFromNode(n.encoded, ctx))
+
+	ctx.addHelper(fmt.Sprintf(`
+// %[1]s is a value encoded in json string as url query, like "a=1&b=2".
+type %[1]s %[2]s
+
+// UnmarshalJSON unmarshals value from json string with url query.
+func (v *%[1]s) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	query, err := url.ParseQuery(s)
+	if err != nil {
+		return err
+	}
+	fields := make(map[string]interface{}, len(query))
+	for key, values := range query {
+		if len(values) == 1 {
+			fields[key] = values[0]
+		} else {
+			fields[key] = values
+		}
+	}
+	if data, err = json.Marshal(fields); err != nil {
+		return err
+	}
+	type plain %[1]s
+	return json.Unmarshal(data, (*plain)(v))
+}
+
+// MarshalJSON marshals value as json string with url query.
+func (v %[1]s) MarshalJSON() ([]byte, error) {
+	type plain %[1]s
+	data, err := json.Marshal(plain(v))
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	query := url.Values{}
+	for key, value := range fields {
+		switch value := value.(type) {
+		case nil:
+		case []interface{}:
+			for _, el := range value {
+				query.Add(key, fmt.Sprint(el))
+			}
+		default:
+			query.Set(key, fmt.Sprint(value))
+		}
+	}
+	return json.Marshal(query.Encode())
+}
+`, name, valueType))
+
+	return ast.NewIdent(name)
+}
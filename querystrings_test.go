@@ -0,0 +1,92 @@
+package json2go
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsQueryString(t *testing.T) {
+	t.Parallel()
+
+	for _, s := range []string{"a=1", "a=1&b=", "utm_source=mail&utm_campaign=spring+sale", "ids[]=1&ids[]=2", "q=%C5%BC"} {
+		assert.True(t, isQueryString(s), s)
+	}
+	for _, s := range []string{"", "a", "a = 1", "=1", "a=1&", "YWJj==", "a=&b=", "a=%zz", "https://example.com/?a=1"} {
+		assert.False(t, isQueryString(s), s)
+	}
+}
+
+func TestParserExpandQueryStrings(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		expand   bool
+		inputs   []string
+		expected string
+	}{
+		{
+			name:     "disabled",
+			inputs:   []string{`{"form":"a=1&b=2"}`},
+			expected: "string",
+		},
+		{
+			name:     "query",
+			expand:   true,
+			inputs:   []string{`{"form":"a=1&b=2"}`, `{"form":"a=3"}`},
+			expected: "FormQuery",
+		},
+		{
+			name:     "array of queries",
+			expand:   true,
+			inputs:   []string{`{"forms":["a=1","a=2"]}`},
+			expected: "[]FormQuery",
+		},
+		{
+			name:     "some plain strings",
+			expand:   true,
+			inputs:   []string{`{"form":"a=1"}`, `{"form":"a"}`},
+			expected: "string",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			parser := NewJSONParser(baseTypeName, OptExpandQueryStrings(tc.expand))
+			for _, input := range tc.inputs {
+				require.NoError(t, parser.FeedBytes([]byte(input)))
+			}
+
+			fields := parser.Fields()
+			require.Len(t, fields, 1)
+			assert.Equal(t, tc.expected, fields[0].Type)
+		})
+	}
+}
+
+func TestParserExpandQueryStringsCode(t *testing.T) {
+	t.Parallel()
+
+	parser := NewJSONParser(baseTypeName, OptExpandQueryStrings(true))
+	require.NoError(t, parser.FeedBytes([]byte(`{"body":"event=click&tag=a&tag=b&ref="}`)))
+
+	out := runGeneratedCode(t, parser, `
+	var d Document
+	if err := json.NewDecoder(os.Stdin).Decode(&d); err != nil {
+		panic(err)
+	}
+	fmt.Println(d.Body.Event, d.Body.Tag, d.Body.Ref == "")
+	out, err := json.Marshal(d)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(out))
+`, `{"body":"event=open+page&tag=x&tag=y%26z&ref=home"}`)
+	assert.Equal(t, "open page [x y&z] false\n"+
+		`{"body":"event=open+page\u0026ref=home\u0026tag=x\u0026tag=y%26z"}`+"\n", out)
+}
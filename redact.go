@@ -0,0 +1,140 @@
+package json2go
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// DefaultRedactPatterns are patterns of sensitive json keys, used when no patterns are set.
+var DefaultRedactPatterns = []string{"*token*", "*password*", "*secret*"}
+
+// redactedValue replaces sensitive values in generated String() methods and Redacted type.
+const redactedValue = "[REDACTED]"
+
+// isSensitiveKey checks if json key matches one of patterns, like "*token*". Matching is case insensitive.
+func isSensitiveKey(key string, patterns []string) bool {
+	key = strings.ToLower(key)
+	for _, p := range patterns {
+		if ok, _ := path.Match(strings.ToLower(p), key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// validateRedactPatterns checks if all patterns have valid syntax.
+func validateRedactPatterns(patterns []string) error {
+	for _, p := range patterns {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("invalid redact pattern %q: %w", p, err)
+		}
+	}
+	return nil
+}
+
+// redactedStringHelperSrc returns source code of generated function formatting values like "%+v" format,
+// but with values of fields and map keys matching patterns redacted.
+func redactedStringHelperSrc(name string, patterns []string) string {
+	var quoted []string
+	for _, p := range patterns {
+		quoted = append(quoted, fmt.Sprintf("%q", strings.ToLower(p)))
+	}
+
+	return fmt.Sprintf(`
+// %[1]s formats value like "%%+v" format, with values of sensitive fields and map keys redacted.
+func %[1]s(v interface{}) string {
+	var b strings.Builder
+	%[1]sWrite(&b, reflect.ValueOf(v), true)
+	return b.String()
+}
+
+// %[1]sWrite writes formatted value. Values implementing fmt.Stringer are formatted with String method, except top value.
+func %[1]sWrite(b *strings.Builder, v reflect.Value, top bool) {
+	if !v.IsValid() {
+		b.WriteString("<nil>")
+		return
+	}
+	if !top && v.CanInterface() && (v.Kind() != reflect.Ptr || !v.IsNil()) {
+		if s, ok := v.Interface().(fmt.Stringer); ok {
+			b.WriteString(s.String())
+			return
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			b.WriteString("<nil>")
+			return
+		}
+		%[1]sWrite(b, v.Elem(), false)
+	case reflect.Struct:
+		b.WriteString("{")
+		first := true
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Type().Field(i)
+			if f.PkgPath != "" {
+				continue
+			}
+			if !first {
+				b.WriteString(" ")
+			}
+			first = false
+			b.WriteString(f.Name + ":")
+			key := strings.Split(f.Tag.Get("json"), ",")[0]
+			if key == "" {
+				key = f.Name
+			}
+			if %[1]sSensitive(key) {
+				b.WriteString(%[3]q)
+				continue
+			}
+			%[1]sWrite(b, v.Field(i), false)
+		}
+		b.WriteString("}")
+	case reflect.Slice, reflect.Array:
+		b.WriteString("[")
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				b.WriteString(" ")
+			}
+			%[1]sWrite(b, v.Index(i), false)
+		}
+		b.WriteString("]")
+	case reflect.Map:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		b.WriteString("map[")
+		for i, k := range keys {
+			if i > 0 {
+				b.WriteString(" ")
+			}
+			key := fmt.Sprint(k.Interface())
+			b.WriteString(key + ":")
+			if %[1]sSensitive(key) {
+				b.WriteString(%[3]q)
+				continue
+			}
+			%[1]sWrite(b, v.MapIndex(k), false)
+		}
+		b.WriteString("]")
+	default:
+		fmt.Fprint(b, v.Interface())
+	}
+}
+
+// %[1]sSensitive checks if json key matches one of sensitive key patterns.
+func %[1]sSensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, p := range []string{%[2]s} {
+		if ok, _ := path.Match(p, key); ok {
+			return true
+		}
+	}
+	return false
+}
+`, name, strings.Join(quoted, ", "), redactedValue)
+}
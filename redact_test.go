@@ -0,0 +1,110 @@
+package json2go
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsSensitiveKey(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		key       string
+		sensitive bool
+	}{
+		{key: "token", sensitive: true},
+		{key: "access_token", sensitive: true},
+		{key: "AccessToken", sensitive: true},
+		{key: "userPassword", sensitive: true},
+		{key: "client_secret", sensitive: true},
+		{key: "tokenizer", sensitive: true},
+		{key: "name", sensitive: false},
+		{key: "", sensitive: false},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.key, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.sensitive, isSensitiveKey(tc.key, DefaultRedactPatterns))
+		})
+	}
+}
+
+func TestParserStringMethods(t *testing.T) {
+	input := `{"id":1,"password":"p4ss","user":{"name":"x","accessToken":"t0k"},"headers":{"X-Secret":"s","Accept":"*/*"},"keys":[{"secret":"s","id":2}]}`
+
+	parser := NewJSONParser(baseTypeName, OptStringMethods(true), OptMakeMaps(true, 2))
+	assert.NoError(t, parser.FeedBytes([]byte(input)))
+	out := runGeneratedCode(t, parser, `
+	var doc Document
+	if err := json.NewDecoder(os.Stdin).Decode(&doc); err != nil {
+		panic(err)
+	}
+	fmt.Println(doc)
+	fmt.Println(&doc)
+`, input)
+
+	expected := "{Headers:map[Accept:*/* X-Secret:[REDACTED]] ID:1 Keys:[{ID:2 Secret:[REDACTED]}] " +
+		"Password:[REDACTED] User:map[accessToken:[REDACTED] name:x]}\n"
+	assert.Equal(t, expected+expected, out)
+
+	parser = NewJSONParser(baseTypeName, OptStringMethods(true, "[x"))
+	assert.NoError(t, parser.FeedBytes([]byte(input)))
+	_, err := parser.Generate()
+	assert.Error(t, err)
+}
+
+func TestParserStringMethodsFieldClash(t *testing.T) {
+	t.Parallel()
+
+	input := `{"string":"s","password":"p4ss","items":[{"string":"x","n":1}],"user":{"name":"a"}}`
+	parser := NewJSONParser(baseTypeName, OptStringMethods(true), OptExtractCommonTypes(true))
+	require.NoError(t, parser.FeedBytes([]byte(input)))
+	out := runGeneratedCode(t, parser, `
+	var doc Document
+	if err := json.NewDecoder(os.Stdin).Decode(&doc); err != nil {
+		panic(err)
+	}
+	fmt.Println(doc.String, doc.Items[0].String)
+	_, ok := interface{}(doc).(fmt.Stringer)
+	fmt.Println(ok)
+`, input)
+	assert.Equal(t, "s x\nfalse\n", out)
+
+	generated, err := parser.Generate()
+	require.NoError(t, err)
+	assert.NotContains(t, generated, "func (v Document) String() string")
+	assert.Equal(t, []string{
+		`$: field String of type Document clashes with method String, method isn't generated`,
+	}, parser.Warnings())
+}
+
+func TestParserRedactedType(t *testing.T) {
+	input := `{"id":1,"apiToken":"t0k","auth":"Bearer abc","name":"x","session":{"password":"p4ss"}}`
+
+	parser := NewJSONParser(baseTypeName, OptRedactedType(true))
+	assert.NoError(t, parser.FeedBytes([]byte(input)))
+	generated := parser.String()
+	assert.Contains(t, generated, "APIToken Redacted `json:\"apiToken\"`")
+	assert.Contains(t, generated, "Auth     Redacted `json:\"auth\"`")
+	assert.Contains(t, generated, "Name     string   `json:\"name\"`")
+	assert.Contains(t, generated, "Password Redacted `json:\"password\"`")
+
+	out := runGeneratedCode(t, parser, `
+	var doc Document
+	if err := json.NewDecoder(os.Stdin).Decode(&doc); err != nil {
+		panic(err)
+	}
+	fmt.Printf("%v %+v %#v\n", doc.APIToken, doc.Session, doc.Auth)
+	fmt.Println(string(doc.APIToken))
+	json.NewEncoder(os.Stdout).Encode(doc)
+`, input)
+
+	expected := "[REDACTED] {Password:[REDACTED]} [REDACTED]\n" +
+		"t0k\n" +
+		`{"apiToken":"[REDACTED]","auth":"[REDACTED]","id":1,"name":"x","session":{"password":"[REDACTED]"}}` + "\n"
+	assert.Equal(t, expected, out)
+}
@@ -0,0 +1,136 @@
+package json2go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// registryContentType is a content type of schema registry API requests.
+const registryContentType = "application/vnd.schemaregistry.v1+json"
+
+// SchemaRegistry is a client of Confluent compatible schema registry.
+type SchemaRegistry struct {
+	// URL is a base URL of registry API, like "http://localhost:8081".
+	URL string
+	// Client is used for requests, http.DefaultClient if nil.
+	Client *http.Client
+}
+
+// RegisteredSchema is a schema stored in registry.
+type RegisteredSchema struct {
+	Subject    string `json:"subject"`
+	Version    int    `json:"version"`
+	ID         int    `json:"id"`
+	SchemaType string `json:"schemaType,omitempty"` // empty for Avro schemas
+	Schema     string `json:"schema"`
+}
+
+// NewSchemaRegistry returns schema registry client using http.DefaultClient.
+func NewSchemaRegistry(registryURL string) *SchemaRegistry {
+	return &SchemaRegistry{URL: registryURL}
+}
+
+// Schema returns schema of subject in given version, version may be "latest".
+func (r *SchemaRegistry) Schema(ctx context.Context, subject, version string) (RegisteredSchema, error) {
+	var s RegisteredSchema
+	err := r.do(ctx, http.MethodGet, "/subjects/"+url.PathEscape(subject)+"/versions/"+url.PathEscape(version), nil, &s)
+	if err != nil {
+		return RegisteredSchema{}, err
+	}
+	if s.SchemaType == "" {
+		s.SchemaType = SchemaTypeAvro
+	}
+	return s, nil
+}
+
+// CheckCompatibility reports whether schema is compatible with latest version of subject.
+// Schemas of subjects without versions are compatible.
+func (r *SchemaRegistry) CheckCompatibility(ctx context.Context, subject, schemaType string, schema []byte) (bool, error) {
+	var result struct {
+		IsCompatible bool `json:"is_compatible"`
+	}
+	err := r.do(ctx, http.MethodPost, "/compatibility/subjects/"+url.PathEscape(subject)+"/versions/latest", registryRequest(schemaType, schema), &result)
+	var re httpError
+	if errors.As(err, &re) && re.status == http.StatusNotFound {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return result.IsCompatible, nil
+}
+
+// Register registers schema as new version of subject and returns schema id.
+func (r *SchemaRegistry) Register(ctx context.Context, subject, schemaType string, schema []byte) (int, error) {
+	var result struct {
+		ID int `json:"id"`
+	}
+	if err := r.do(ctx, http.MethodPost, "/subjects/"+url.PathEscape(subject)+"/versions", registryRequest(schemaType, schema), &result); err != nil {
+		return 0, err
+	}
+	return result.ID, nil
+}
+
+// Push registers schema as new version of subject if it's compatible with latest version, and returns schema id.
+// ErrIncompatibleSchema is returned for incompatible schemas.
+func (r *SchemaRegistry) Push(ctx context.Context, subject, schemaType string, schema []byte) (int, error) {
+	ok, err := r.CheckCompatibility(ctx, subject, schemaType, schema)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, fmt.Errorf("%w: subject %s", ErrIncompatibleSchema, subject)
+	}
+	return r.Register(ctx, subject, schemaType, schema)
+}
+
+// FeedRegistrySchema consumes schema of subject in given version from registry, see FeedSchema.
+func (p *JSONParser) FeedRegistrySchema(ctx context.Context, r *SchemaRegistry, subject, version string) error {
+	s, err := r.Schema(ctx, subject, version)
+	if err != nil {
+		return err
+	}
+	return p.FeedSchema(s.SchemaType, []byte(s.Schema))
+}
+
+// PushSchema pushes JSON Schema of parsed documents to registry as new version of subject, see SchemaRegistry.Push.
+func (p *JSONParser) PushSchema(ctx context.Context, r *SchemaRegistry, subject string) (int, error) {
+	schema, err := p.JSONSchema()
+	if err != nil {
+		return 0, err
+	}
+	return r.Push(ctx, subject, SchemaTypeJSON, schema)
+}
+
+func registryRequest(schemaType string, schema []byte) interface{} {
+	req := struct {
+		Schema     string `json:"schema"`
+		SchemaType string `json:"schemaType,omitempty"`
+	}{
+		Schema: string(schema),
+	}
+	if schemaType != SchemaTypeAvro {
+		// Avro is the default schema type.
+		req.SchemaType = schemaType
+	}
+	return req
+}
+
+func (r *SchemaRegistry) do(ctx context.Context, method, path string, body, result interface{}) error {
+	err := httpJSONRequest{
+		client:      r.Client,
+		method:      method,
+		url:         strings.TrimSuffix(r.URL, "/") + path,
+		contentType: registryContentType,
+		accept:      registryContentType,
+		body:        body,
+	}.do(ctx, result)
+	if err != nil {
+		return fmt.Errorf("schema registry: %w", err)
+	}
+	return nil
+}
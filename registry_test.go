@@ -0,0 +1,96 @@
+package json2go
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRegistry is a minimal schema registry keeping versions of subjects.
+type fakeRegistry struct {
+	versions   map[string][]string
+	compatible bool
+}
+
+func (f *fakeRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", registryContentType)
+	notFound := func() {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error_code":40401,"message":"Subject not found."}`))
+	}
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/subjects/events-value/versions/latest":
+		v := f.versions["events-value"]
+		if len(v) == 0 {
+			notFound()
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"subject": "events-value", "version": len(v), "id": len(v), "schema": v[len(v)-1], "schemaType": SchemaTypeJSON,
+		})
+	case r.Method == http.MethodPost && r.URL.Path == "/compatibility/subjects/events-value/versions/latest":
+		if len(f.versions["events-value"]) == 0 {
+			notFound()
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"is_compatible": f.compatible})
+	case r.Method == http.MethodPost && r.URL.Path == "/subjects/events-value/versions":
+		var req struct {
+			Schema     string `json:"schema"`
+			SchemaType string `json:"schemaType"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.SchemaType != SchemaTypeJSON {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = w.Write([]byte(`{"error_code":42201,"message":"Invalid schema"}`))
+			return
+		}
+		f.versions["events-value"] = append(f.versions["events-value"], req.Schema)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"id": len(f.versions["events-value"])})
+	default:
+		notFound()
+	}
+}
+
+func TestSchemaRegistry(t *testing.T) {
+	fake := &fakeRegistry{versions: map[string][]string{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	ctx := context.Background()
+	registry := NewSchemaRegistry(server.URL)
+
+	_, err := registry.Schema(ctx, "events-value", "latest")
+	assert.Error(t, err)
+
+	parser := NewJSONParser(baseTypeName)
+	require.NoError(t, parser.FeedBytes([]byte(`{"id":1,"name":"x"}`)))
+
+	// New subjects accept any schema.
+	id, err := parser.PushSchema(ctx, registry, "events-value")
+	require.NoError(t, err)
+	assert.Equal(t, 1, id)
+
+	_, err = parser.PushSchema(ctx, registry, "events-value")
+	assert.True(t, errors.Is(err, ErrIncompatibleSchema), "unexpected error: %v", err)
+
+	fake.compatible = true
+	id, err = parser.PushSchema(ctx, registry, "events-value")
+	require.NoError(t, err)
+	assert.Equal(t, 2, id)
+
+	s, err := registry.Schema(ctx, "events-value", "latest")
+	require.NoError(t, err)
+	assert.Equal(t, 2, s.Version)
+	assert.Equal(t, SchemaTypeJSON, s.SchemaType)
+
+	fromRegistry := NewJSONParser(baseTypeName)
+	require.NoError(t, fromRegistry.FeedRegistrySchema(ctx, registry, "events-value", "latest"))
+	assert.Equal(t, parser.String(), fromRegistry.String())
+}
@@ -0,0 +1,129 @@
+package json2go
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// headerConfigPrefix is a prefix of header line with json encoded resolved config, see OptHeaderConfig.
+const headerConfigPrefix = "// Config: "
+
+// Resolve returns config with defaults of options made explicit, like root name or names of default policies, so
+// parser created with resolved config generates the same code, even if defaults change in later versions of json2go.
+// Version of resolved config is set to version of json2go module, empty if it's unknown. Invalid names of
+// policies are reported as errors.
+func (c Config) Resolve() (Config, error) {
+	c.Version = moduleVersion()
+	if c.RootName == "" {
+		c.RootName = defaultRootName
+	}
+	lattice, err := ParseWideningLattice(c.Widening)
+	if err != nil {
+		return Config{}, err
+	}
+	c.Widening = lattice.resolved().String()
+
+	policies := []struct {
+		name  *string
+		def   string
+		parse func(name string) error
+	}{
+		{&c.KeySplitting, "none", func(name string) error { _, err := ParseKeySplitting(name); return err }},
+		{&c.GeoJSON, "none", func(name string) error { _, err := ParseGeoJSON(name); return err }},
+		{&c.HAL, "none", func(name string) error { _, err := ParseHAL(name); return err }},
+		{&c.RootTypes, "auto", func(name string) error { _, err := ParseRootTypes(name); return err }},
+		{&c.SliceElements, "inferred", func(name string) error { _, err := ParseSliceElements(name); return err }},
+		{&c.NullElements, "pointers", func(name string) error { _, err := ParseNullElements(name); return err }},
+		{&c.EmptyValues, "default", func(name string) error { _, err := ParseEmptyValues(name); return err }},
+		{&c.InvalidUTF8, "replace", func(name string) error { _, err := ParseInvalidUTF8(name); return err }},
+		{&c.NumberLocale, "none", func(name string) error { _, err := ParseNumberLocale(name); return err }},
+		{&c.Strictness, "none", func(name string) error { _, err := ParseStrictness(name); return err }},
+	}
+	for _, p := range policies {
+		if err := p.parse(*p.name); err != nil {
+			return Config{}, err
+		}
+		if *p.name == "" {
+			*p.name = p.def
+		}
+	}
+	for _, name := range c.EpochUnits {
+		if _, err := ParseEpochUnit(name); err != nil {
+			return Config{}, err
+		}
+	}
+
+	if c.ExampleMaxSize > 0 && c.ExampleMaxLength == 0 {
+		c.ExampleMaxLength = maxTraceExampleLength
+	}
+	if c.Header && c.HeaderTemplate == "" {
+		c.HeaderTemplate = defaultHeaderTemplate
+	}
+	return c, nil
+}
+
+// OptHeaderConfig toggles line of header with json encoded config, resolved with Config.Resolve, see OptHeader.
+// Config is read back from generated code with ReadConfig, to generate code exactly like last time.
+// If config can't be resolved, Generate returns error.
+func OptHeaderConfig(v bool, c Config) JSONParserOpt {
+	return func(o *options) {
+		o.headerConfig, o.headerConfigErr = "", nil
+		if !v {
+			return
+		}
+		resolved, err := c.Resolve()
+		if err != nil {
+			o.headerConfigErr = err
+			return
+		}
+		data, err := json.Marshal(resolved)
+		if err != nil {
+			o.headerConfigErr = err
+			return
+		}
+		o.headerConfig = string(data)
+	}
+}
+
+// ReadConfig reads yaml, or json, encoded config, like one written for Config.Resolve, or config in header of
+// generated go code, see OptHeaderConfig.
+func ReadConfig(r io.Reader) (Config, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return Config{}, err
+	}
+	var c Config
+	if line, ok := headerConfigLine(data); ok {
+		if err := json.Unmarshal([]byte(line), &c); err != nil {
+			return Config{}, fmt.Errorf("decoding config of header: %w", err)
+		}
+		return c, nil
+	}
+	if err := yaml.UnmarshalStrict(data, &c); err != nil {
+		return Config{}, err
+	}
+	return c, nil
+}
+
+// headerConfigLine returns json encoded config of leading comments of go code, and if it's found.
+func headerConfigLine(data []byte) (string, bool) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, headerConfigPrefix) {
+			return strings.TrimPrefix(line, headerConfigPrefix), true
+		}
+		if line != "" && !strings.HasPrefix(line, "//") {
+			return "", false
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,191 @@
+package json2go
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigResolve(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		config   Config
+		expected Config
+		err      string
+	}{
+		{
+			name: "defaults",
+			expected: Config{
+				RootName:      "Document",
+				Widening:      "int+float=float,number+string=interface,bool+string=interface,time+string=string",
+				KeySplitting:  "none",
+				GeoJSON:       "none",
+				HAL:           "none",
+				RootTypes:     "auto",
+				SliceElements: "inferred",
+				NullElements:  "pointers",
+				EmptyValues:   "default",
+				InvalidUTF8:   "replace",
+				NumberLocale:  "none",
+				Strictness:    "none",
+			},
+		},
+		{
+			name: "explicit",
+			config: Config{
+				RootName:       "Event",
+				Widening:       "int+float=number",
+				KeySplitting:   "camel",
+				Strictness:     "mixed",
+				ExampleMaxSize: 1024,
+				Header:         true,
+				HeaderTemplate: "// Generated.",
+			},
+			expected: Config{
+				RootName:         "Event",
+				Widening:         "int+float=number,number+string=interface,bool+string=interface,time+string=string",
+				KeySplitting:     "camel",
+				GeoJSON:          "none",
+				HAL:              "none",
+				RootTypes:        "auto",
+				SliceElements:    "inferred",
+				NullElements:     "pointers",
+				EmptyValues:      "default",
+				InvalidUTF8:      "replace",
+				NumberLocale:     "none",
+				Strictness:       "mixed",
+				ExampleMaxLength: 64,
+				ExampleMaxSize:   1024,
+				Header:           true,
+				HeaderTemplate:   "// Generated.",
+			},
+		},
+		{
+			name:   "invalid policy",
+			config: Config{NullElements: "zero"},
+			err:    "unknown null elements representation: zero",
+		},
+		{
+			name:   "invalid widening",
+			config: Config{Widening: "bool+string=float"},
+			err:    "widening float isn't allowed for bool+string",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			resolved, err := tc.config.Resolve()
+			if tc.err != "" {
+				require.Error(t, err)
+				assert.Equal(t, tc.err, err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, resolved)
+		})
+	}
+}
+
+func TestOptHeaderConfig(t *testing.T) {
+	t.Parallel()
+
+	input := []byte(`{"a": 1, "b": [1, 2.5], "c": {"x": "y"}}`)
+	config := Config{RootName: "Event", Header: true, HeaderConfig: true, Widening: "int+float=number"}
+	p := config.NewParser()
+	require.NoError(t, p.FeedBytes(input))
+	out, err := p.Generate()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(out, `// Code generated by json2go. DO NOT EDIT.
+// Config: {"rootName":"Event","numberLocale":"none","geoJSON":"none","hal":"none",`+
+		`"widening":"int+float=number,number+string=interface,bool+string=interface,time+string=string",`), out)
+
+	read, err := ReadConfig(strings.NewReader(out))
+	require.NoError(t, err)
+	resolved, err := config.Resolve()
+	require.NoError(t, err)
+	assert.Equal(t, resolved, read)
+
+	p = read.NewParser()
+	require.NoError(t, p.FeedBytes(input))
+	regenerated, err := p.Generate()
+	require.NoError(t, err)
+	assert.Equal(t, out, regenerated)
+
+	p = NewJSONParser(baseTypeName, OptHeader(true, ""), OptHeaderConfig(true, Config{HAL: "links"}))
+	require.NoError(t, p.FeedBytes(input))
+	_, err = p.Generate()
+	require.Error(t, err)
+	assert.Equal(t, "resolving config of header: unknown HAL handling: links", err.Error())
+}
+
+func TestReadConfig(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		input    string
+		expected Config
+		err      string
+	}{
+		{
+			name:     "yaml",
+			input:    "version: v1.2.0\nrootName: Event\nsliceElements: pointers\n",
+			expected: Config{Version: "v1.2.0", RootName: "Event", SliceElements: "pointers"},
+		},
+		{
+			name:     "json",
+			input:    `{"rootName": "Event", "tuples": true}`,
+			expected: Config{RootName: "Event", Tuples: true},
+		},
+		{
+			name: "header",
+			input: `// Code generated by json2go v1.2.0. DO NOT EDIT.
+// Config: {"version":"v1.2.0","rootName":"Event"}
+
+package main
+`,
+			expected: Config{Version: "v1.2.0", RootName: "Event"},
+		},
+		{
+			name: "config after header",
+			input: `package main
+
+// Config: {"rootName":"Event"}
+`,
+			err: "yaml",
+		},
+		{
+			name:  "invalid header",
+			input: "// Config: {\"rootName\":\n",
+			err:   "decoding config of header",
+		},
+		{
+			name:  "unknown option",
+			input: "rootNames: Event\n",
+			err:   "field rootNames not found",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			config, err := ReadConfig(strings.NewReader(tc.input))
+			if tc.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, config)
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package json2go
+
+import (
+	"fmt"
+	"go/ast"
+	"strings"
+)
+
+// RootTypes is a kind of declarations of root types, like `type Document []Item` or `type Document = []Item`.
+type RootTypes int
+
+const (
+	// RootTypesAuto declares aliases only for root time.Time, json.RawMessage and tuple types,
+	// so roots keep their json marshaling methods. Other root types are defined types.
+	RootTypesAuto RootTypes = iota
+	// RootTypesAlias declares aliases for all root types except structs, so roots keep methods of underlying types,
+	// like marshaling methods of map values. Roots with generated methods, like String methods, are still defined types.
+	RootTypesAlias
+	// RootTypesDefined declares defined types for all roots. Methods of underlying types are lost,
+	// e.g. root `type Document time.Time` is unmarshaled from json object, not string.
+	RootTypesDefined
+)
+
+// ParseRootTypes returns kind of root type declarations by name: "auto", "alias" or "defined". Empty name means auto.
+func ParseRootTypes(name string) (RootTypes, error) {
+	switch name {
+	case "", "auto":
+		return RootTypesAuto, nil
+	case "alias":
+		return RootTypesAlias, nil
+	case "defined":
+		return RootTypesDefined, nil
+	}
+	return RootTypesAuto, fmt.Errorf("unknown root types: %s", name)
+}
+
+// astRootTypeSpec returns declaration of root type, alias or defined type according to options.
+// hasMethods is true if methods are generated for slice and map types, these can't be aliases.
+// Aliases of time.Time, json.RawMessage and tuple types are already represented by "= T" type names.
+func astRootTypeSpec(n *node, typeExpr ast.Expr, hasMethods bool, ctx *astContext) *ast.TypeSpec {
+	spec := &ast.TypeSpec{
+		Name: ast.NewIdent(n.name),
+		Type: typeExpr,
+	}
+
+	ident, ok := typeExpr.(*ast.Ident)
+	isAlias := ok && strings.HasPrefix(ident.Name, "= ")
+	switch ctx.opts.rootTypes {
+	case RootTypesDefined:
+		if isAlias {
+			spec.Type = ast.NewIdent(strings.TrimPrefix(ident.Name, "= "))
+		}
+	case RootTypesAlias:
+		switch typeExpr.(type) {
+		case *ast.StructType:
+			return spec
+		case *ast.ArrayType, *ast.MapType:
+			if hasMethods {
+				return spec
+			}
+		}
+		if !isAlias {
+			// Any valid position makes printer print "=".
+			spec.Assign = 1
+		}
+	}
+
+	return spec
+}
+
+// extractRootElements moves structs of elements of root arrays to named types, see OptRootElementType.
+func extractRootElements(nodes []*node, name string, singulars, assigned map[string]string) []*node {
+	names := extractNames{
+		used:      make(map[string]bool),
+		assigned:  assigned,
+		claimed:   make(map[string]bool),
+		singulars: singulars,
+	}
+	for _, name := range assigned {
+		names.used[name] = true
+	}
+	for _, n := range nodes {
+		names.used[n.name] = true
+		names.claimed[n.name] = true
+	}
+
+	for _, n := range nodes {
+		if !n.document || n.arrayLevel == 0 || n.t.id() != nodeTypeObject.id() {
+			continue
+		}
+		if name != "" {
+			nodes = append(nodes, extractNamedStruct(n, name, names))
+		} else {
+			nodes = append(nodes, extractStruct(n, n.name, names))
+		}
+	}
+	return nodes
+}
@@ -0,0 +1,185 @@
+package json2go
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserRootTypes(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		input    string
+		opts     []JSONParserOpt
+		expected [3]string // auto, alias, defined
+	}{
+		{
+			name:  "slice",
+			input: `[1, 2]`,
+			expected: [3]string{
+				"type Document []int",
+				"type Document = []int",
+				"type Document []int",
+			},
+		},
+		{
+			name:  "map",
+			input: `{"a":1,"b":2,"c":3}`,
+			opts:  []JSONParserOpt{OptMakeMaps(true, 3)},
+			expected: [3]string{
+				"type Document map[string]int",
+				"type Document = map[string]int",
+				"type Document map[string]int",
+			},
+		},
+		{
+			name:  "scalar",
+			input: `"abc"`,
+			expected: [3]string{
+				"type Document string",
+				"type Document = string",
+				"type Document string",
+			},
+		},
+		{
+			name:  "time",
+			input: `"2020-01-01T00:00:00Z"`,
+			expected: [3]string{
+				"type Document = time.Time",
+				"type Document = time.Time",
+				"type Document time.Time",
+			},
+		},
+		{
+			name:  "struct",
+			input: `{"a":1}`,
+			expected: [3]string{
+				"type Document struct {\n\tA int `json:\"a\"`\n}",
+				"type Document struct {\n\tA int `json:\"a\"`\n}",
+				"type Document struct {\n\tA int `json:\"a\"`\n}",
+			},
+		},
+		{
+			name:  "slice with methods",
+			input: `[1, 2]`,
+			opts:  []JSONParserOpt{OptStringMethods(true)},
+			expected: [3]string{
+				"type Document []int",
+				"type Document []int",
+				"type Document []int",
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			for i, kind := range []RootTypes{RootTypesAuto, RootTypesAlias, RootTypesDefined} {
+				p := NewJSONParser("Document", append(tc.opts, OptRootTypes(kind))...)
+				require.NoError(t, p.FeedBytes([]byte(tc.input)))
+
+				// Only root type declaration is checked, helpers follow it.
+				assert.Equal(t, tc.expected[i], astPrintDecls(p.ASTDeclsWithOpt()[:1]))
+			}
+		})
+	}
+}
+
+func TestParseRootTypes(t *testing.T) {
+	t.Parallel()
+
+	kind, err := ParseRootTypes("alias")
+	require.NoError(t, err)
+	assert.Equal(t, RootTypesAlias, kind)
+
+	kind, err = ParseRootTypes("")
+	require.NoError(t, err)
+	assert.Equal(t, RootTypesAuto, kind)
+
+	_, err = ParseRootTypes("other")
+	assert.Error(t, err)
+}
+
+func TestParserRootElementType(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		rootName string
+		elemName string
+		input    string
+		expected string
+	}{
+		{
+			name:     "singular",
+			rootName: "Users",
+			input:    `[{"id":1},{"id":2}]`,
+			expected: "type Users []User\ntype User struct {\n\tID int `json:\"id\"`\n}",
+		},
+		{
+			name:     "named",
+			rootName: "Users",
+			elemName: "Account",
+			input:    `[{"id":1}]`,
+			expected: "type Users []Account\ntype Account struct {\n\tID int `json:\"id\"`\n}",
+		},
+		{
+			name:     "without plural",
+			rootName: "Document",
+			input:    `[[{"id":1}],[null]]`,
+			expected: "type Document [][]*DocumentItem\ntype DocumentItem struct {\n\tID int `json:\"id\"`\n}",
+		},
+		{
+			name:     "name of root",
+			rootName: "Users",
+			elemName: "Users",
+			input:    `[{"id":1}]`,
+			expected: "type Users []Users2\ntype Users2 struct {\n\tID int `json:\"id\"`\n}",
+		},
+		{
+			name:     "object root",
+			rootName: "User",
+			input:    `{"id":1}`,
+			expected: "type User struct {\n\tID int `json:\"id\"`\n}",
+		},
+		{
+			name:     "scalar elements",
+			rootName: "IDs",
+			input:    `[1,2]`,
+			expected: "type IDs []int",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			parser := NewJSONParser(tc.rootName, OptRootElementType(true, tc.elemName))
+			require.NoError(t, parser.FeedBytes([]byte(tc.input)))
+			assert.Equal(t, tc.expected, parser.String())
+		})
+	}
+}
+
+func TestParserRootElementTypeCode(t *testing.T) {
+	t.Parallel()
+
+	parser := NewJSONParser("Users", OptRootElementType(true, ""))
+	require.NoError(t, parser.FeedBytes([]byte(`[{"name":"a","tags":["x"]},{"name":"b"}]`)))
+
+	out := runGeneratedCode(t, parser, `
+	var users Users
+	if err := json.NewDecoder(os.Stdin).Decode(&users); err != nil {
+		panic(err)
+	}
+	var u User = users[1]
+	fmt.Println(len(users), users[0].Tags, u.Name)
+	`, `[{"name":"a","tags":["x"]},{"name":"b"}]`)
+	assert.Equal(t, "2 [x] b\n", out)
+}
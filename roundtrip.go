@@ -0,0 +1,661 @@
+package json2go
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	goparser "go/parser"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema is a description of generated types, telling how they decode and encode json values.
+// It's returned by JSONParser.Schema and used by VerifyRoundTrip. Schema is also an intermediate representation (IR)
+// of json2go, exchanged with other tools with MarshalIR and UnmarshalIR.
+type Schema struct {
+	// Root is a name of root type.
+	Root string `json:"root"`
+	// Types are declared types, by name.
+	Types map[string]*SchemaType `json:"types"`
+}
+
+// SchemaKind is a kind of SchemaType.
+type SchemaKind string
+
+// Kinds of schema types.
+const (
+	SchemaBool   SchemaKind = "bool"
+	SchemaInt    SchemaKind = "int"
+	SchemaFloat  SchemaKind = "float"
+	SchemaString SchemaKind = "string"
+	SchemaTime   SchemaKind = "time"
+	// SchemaAny is interface{}, numbers are decoded as float64.
+	SchemaAny SchemaKind = "any"
+	// SchemaOpaque are json.RawMessage, custom types and helper types with own json methods, values are kept as they are.
+	SchemaOpaque   SchemaKind = "opaque"
+	SchemaPointer  SchemaKind = "pointer"
+	SchemaSlice    SchemaKind = "slice"
+	SchemaMap      SchemaKind = "map"
+	SchemaStruct   SchemaKind = "struct"
+	SchemaOptional SchemaKind = "optional"
+	// SchemaNamed is a reference to declared type.
+	SchemaNamed SchemaKind = "named"
+)
+
+// SchemaFormatUUID is a format of strings decoded to uuid.UUID.
+const SchemaFormatUUID = "uuid"
+
+// SchemaType is a description of go type.
+type SchemaType struct {
+	Kind SchemaKind `json:"kind"`
+	// Name is a name of declared type, for SchemaNamed kind.
+	Name string `json:"name,omitempty"`
+	// Bits is a size of floats, 32 or 64.
+	Bits int `json:"bits,omitempty"`
+	// Format is a format of strings decoded to types validating them, like SchemaFormatUUID.
+	Format string `json:"format,omitempty"`
+	// Elem is a type of pointed value, slice element, map value or optional value.
+	Elem *SchemaType `json:"elem,omitempty"`
+	// Key is a type of map keys, SchemaString or SchemaInt.
+	Key *SchemaType `json:"key,omitempty"`
+	// SkipNulls is true for slices skipping null elements, see NullElementsSkip.
+	SkipNulls bool `json:"skipNulls,omitempty"`
+	// Fields are struct fields encoded in json.
+	Fields []SchemaField `json:"fields,omitempty"`
+	// KeepsUnknown is true for structs keeping values of unknown keys, see OptUnknownFields.
+	KeepsUnknown bool `json:"keepsUnknown,omitempty"`
+}
+
+// SchemaField is a description of struct field.
+type SchemaField struct {
+	// Name is a go field name.
+	Name string `json:"name"`
+	// Key is a json key.
+	Key  string      `json:"key"`
+	Type *SchemaType `json:"type"`
+	// OmitEmpty and OmitZero are set by json tag options.
+	OmitEmpty bool `json:"omitEmpty,omitempty"`
+	OmitZero  bool `json:"omitZero,omitempty"`
+}
+
+// Schema returns description of generated types, see VerifyRoundTrip.
+func (p *JSONParser) Schema() *Schema {
+	nodes, _ := p.declNodes()
+	ctx := newASTContext(nodes, p.opts)
+	decls := astGenerateDeclsWithContext(nodes, ctx)
+
+	s := &Schema{Types: make(map[string]*SchemaType)}
+	for i, n := range nodes {
+		if n.document && s.Root == "" {
+			s.Root = n.name
+		}
+		for _, spec := range decls[i].(*ast.GenDecl).Specs {
+			if ts, ok := spec.(*ast.TypeSpec); ok {
+				s.Types[ts.Name.Name] = nil
+			}
+		}
+	}
+	for i, n := range nodes {
+		for _, spec := range decls[i].(*ast.GenDecl).Specs {
+			ts, ok := spec.(*ast.TypeSpec)
+			if !ok {
+				continue
+			}
+			t := &SchemaType{Kind: SchemaOpaque}
+			if !(n.document && p.opts.keySplitting == KeySplittingNested) {
+				// Root type with nested keys has own UnmarshalJSON method.
+				t = schemaTypeFromString(strings.TrimPrefix(astExprString(ts.Type), "= "), s, ctx)
+			}
+			s.Types[ts.Name.Name] = t
+		}
+	}
+
+	return s
+}
+
+// schemaTypeFromString returns schema type of go type expression.
+func schemaTypeFromString(expr string, s *Schema, ctx *astContext) *SchemaType {
+	e, err := goparser.ParseExpr(expr)
+	if err != nil {
+		return &SchemaType{Kind: SchemaOpaque}
+	}
+	return schemaTypeFromExpr(e, s, ctx)
+}
+
+func schemaTypeFromExpr(expr ast.Expr, s *Schema, ctx *astContext) *SchemaType {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		switch e.Name {
+		case "bool":
+			return &SchemaType{Kind: SchemaBool}
+		case "int", "int64":
+			return &SchemaType{Kind: SchemaInt}
+		case "float64":
+			return &SchemaType{Kind: SchemaFloat, Bits: 64}
+		case "float32":
+			return &SchemaType{Kind: SchemaFloat, Bits: 32}
+		case "string":
+			return &SchemaType{Kind: SchemaString}
+		}
+		if _, ok := s.Types[e.Name]; ok {
+			return &SchemaType{Kind: SchemaNamed, Name: e.Name}
+		}
+	case *ast.SelectorExpr:
+		if x, ok := e.X.(*ast.Ident); ok && x.Name == "time" && e.Sel.Name == "Time" {
+			return &SchemaType{Kind: SchemaTime}
+		}
+		if x, ok := e.X.(*ast.Ident); ok && x.Name == "uuid" && e.Sel.Name == "UUID" {
+			return &SchemaType{Kind: SchemaString, Format: SchemaFormatUUID}
+		}
+	case *ast.InterfaceType:
+		return &SchemaType{Kind: SchemaAny}
+	case *ast.StarExpr:
+		return &SchemaType{Kind: SchemaPointer, Elem: schemaTypeFromExpr(e.X, s, ctx)}
+	case *ast.ArrayType:
+		if e.Len == nil {
+			return &SchemaType{Kind: SchemaSlice, Elem: schemaTypeFromExpr(e.Elt, s, ctx)}
+		}
+	case *ast.MapType:
+		return &SchemaType{
+			Kind: SchemaMap,
+			Key:  schemaTypeFromExpr(e.Key, s, ctx),
+			Elem: schemaTypeFromExpr(e.Value, s, ctx),
+		}
+	case *ast.IndexExpr:
+		x, _ := e.X.(*ast.Ident)
+		if x == nil {
+			break
+		}
+		elem := schemaTypeFromExpr(e.Index, s, ctx)
+		switch x.Name {
+		case ctx.sharedHelpers["Optional"]:
+			return &SchemaType{Kind: SchemaOptional, Elem: elem}
+		case ctx.sharedHelpers["SkipNulls"]:
+			return &SchemaType{Kind: SchemaSlice, Elem: elem, SkipNulls: true}
+		case ctx.sharedHelpers["Nullable"]:
+			return &SchemaType{Kind: SchemaPointer, Elem: elem}
+		}
+	case *ast.StructType:
+		return schemaStructType(e, s, ctx)
+	}
+	return &SchemaType{Kind: SchemaOpaque}
+}
+
+func schemaStructType(st *ast.StructType, s *Schema, ctx *astContext) *SchemaType {
+	t := &SchemaType{Kind: SchemaStruct}
+	for _, f := range st.Fields.List {
+		if len(f.Names) == 0 || !ast.IsExported(f.Names[0].Name) {
+			continue
+		}
+		field := SchemaField{
+			Name: f.Names[0].Name,
+			Key:  f.Names[0].Name,
+			Type: schemaTypeFromExpr(f.Type, s, ctx),
+		}
+		if f.Tag != nil {
+			tag, _ := strconv.Unquote(f.Tag.Value)
+			options := strings.Split(reflect.StructTag(tag).Get("json"), ",")
+			if n, ok := ctx.rawKeys[f]; ok {
+				// Value is decoded by json methods, see astAddKeyMethods.
+				field.Key = n.key
+				field.OmitEmpty = !n.required && astNonEmptyCondition("v", f.Type) != ""
+				t.Fields = append(t.Fields, field)
+				continue
+			}
+			if options[0] == "-" && len(options) == 1 {
+				if field.Type.Kind == SchemaMap {
+					// Values of unknown keys, see astAddKeyMethods.
+					t.KeepsUnknown = true
+				}
+				continue
+			}
+			if options[0] != "" {
+				field.Key = options[0]
+			}
+			for _, o := range options[1:] {
+				switch o {
+				case "omitempty":
+					field.OmitEmpty = true
+				case "omitzero":
+					field.OmitZero = true
+				case "string":
+					field.Type = &SchemaType{Kind: SchemaOpaque}
+				}
+			}
+		}
+		t.Fields = append(t.Fields, field)
+	}
+	return t
+}
+
+// RoundTripIssueKind is a kind of data loss found by VerifyRoundTrip.
+type RoundTripIssueKind string
+
+// Kinds of round trip issues.
+const (
+	// RoundTripDroppedKey is a key not decoded to any field.
+	RoundTripDroppedKey RoundTripIssueKind = "dropped key"
+	// RoundTripTypeMismatch is a value not fitting field type, decoding fails.
+	RoundTripTypeMismatch RoundTripIssueKind = "type mismatch"
+	// RoundTripLostPrecision is a number encoded with different value.
+	RoundTripLostPrecision RoundTripIssueKind = "lost precision"
+	// RoundTripChangedValue is a value encoded differently, like time in other format.
+	RoundTripChangedValue RoundTripIssueKind = "changed value"
+	// RoundTripNullBecameZero is a null value encoded as zero value.
+	RoundTripNullBecameZero RoundTripIssueKind = "null became zero"
+	// RoundTripBecameAbsent is a null or empty value, or null array element, that wasn't encoded.
+	RoundTripBecameAbsent RoundTripIssueKind = "became absent"
+	// RoundTripBecamePresent is an absent key encoded with zero value or null.
+	RoundTripBecamePresent RoundTripIssueKind = "became present"
+)
+
+// RoundTripIssue is a data loss found by VerifyRoundTrip.
+type RoundTripIssue struct {
+	// Path is a json path of value in sample, like "$.items[1].id".
+	Path   string
+	Kind   RoundTripIssueKind
+	Detail string
+}
+
+func (i RoundTripIssue) String() string {
+	if i.Detail == "" {
+		return fmt.Sprintf("%s: %s", i.Path, i.Kind)
+	}
+	return fmt.Sprintf("%s: %s: %s", i.Path, i.Kind, i.Detail)
+}
+
+// Report is a result of VerifyRoundTrip.
+type Report struct {
+	// Issues are data losses found in sample, sorted by path.
+	Issues []RoundTripIssue
+	// Output is sample decoded and encoded with generated types. Keys of objects are sorted.
+	Output []byte
+}
+
+// Lossless reports if sample was encoded without data loss.
+func (r Report) Lossless() bool {
+	return len(r.Issues) == 0
+}
+
+// VerifyRoundTrip decodes json sample with generated types described by schema, encodes it back,
+// and reports data lost on the way: dropped keys, numbers changed by precision of types,
+// and null values or absent keys confused with each other or zero values.
+// Types are simulated, following encoding/json rules. Types with own json methods, other than
+// helper types generated for options like OptOptionalType, are opaque: their values are kept as they are.
+func VerifyRoundTrip(sample []byte, generatedIR *Schema) (Report, error) {
+	if generatedIR == nil || generatedIR.Types[generatedIR.Root] == nil {
+		return Report{}, errors.New("schema has no root type")
+	}
+
+	d := json.NewDecoder(bytes.NewReader(sample))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return Report{}, invalidJSONError{err: err}
+	}
+
+	rt := roundTrip{schema: generatedIR}
+	out := rt.value(v, &SchemaType{Kind: SchemaNamed, Name: generatedIR.Root}, rootPath)
+	output, err := json.Marshal(out)
+	if err != nil {
+		return Report{}, fmt.Errorf("%w: encoding sample: %v", ErrInternal, err)
+	}
+
+	sort.SliceStable(rt.issues, func(i, j int) bool {
+		return rt.issues[i].Path < rt.issues[j].Path
+	})
+	return Report{Issues: rt.issues, Output: output}, nil
+}
+
+// roundTrip keeps state of VerifyRoundTrip. Values are represented like values decoded with json.Number.
+type roundTrip struct {
+	schema *Schema
+	issues []RoundTripIssue
+}
+
+func (rt *roundTrip) report(path string, kind RoundTripIssueKind, format string, args ...interface{}) {
+	rt.issues = append(rt.issues, RoundTripIssue{Path: path, Kind: kind, Detail: fmt.Sprintf(format, args...)})
+}
+
+// resolve returns declared type of named type.
+func (rt *roundTrip) resolve(t *SchemaType) *SchemaType {
+	for depth := 0; t.Kind == SchemaNamed; depth++ {
+		declared := rt.schema.Types[t.Name]
+		if declared == nil || depth > len(rt.schema.Types) {
+			return &SchemaType{Kind: SchemaOpaque}
+		}
+		t = declared
+	}
+	return t
+}
+
+// value returns value decoded to type t and encoded back.
+func (rt *roundTrip) value(v interface{}, t *SchemaType, path string) interface{} {
+	t = rt.resolve(t)
+	if v == nil {
+		switch t.Kind {
+		case SchemaAny, SchemaOpaque, SchemaPointer, SchemaSlice, SchemaMap, SchemaOptional:
+			return nil
+		}
+		// Decoding null leaves zero value.
+		rt.report(path, RoundTripNullBecameZero, "")
+		return rt.zero(t)
+	}
+
+	mismatch := func() interface{} {
+		rt.report(path, RoundTripTypeMismatch, "%s value into %s", jsonKindName(v), t.Kind)
+		return rt.zero(t)
+	}
+
+	switch t.Kind {
+	case SchemaBool:
+		if _, ok := v.(bool); !ok {
+			return mismatch()
+		}
+		return v
+	case SchemaInt:
+		n, ok := v.(json.Number)
+		if !ok {
+			return mismatch()
+		}
+		i, err := strconv.ParseInt(n.String(), 10, 64)
+		if err != nil {
+			return mismatch()
+		}
+		return json.Number(strconv.FormatInt(i, 10))
+	case SchemaFloat:
+		n, ok := v.(json.Number)
+		if !ok {
+			return mismatch()
+		}
+		return rt.float(n, t.Bits, path)
+	case SchemaString:
+		if _, ok := v.(string); !ok {
+			return mismatch()
+		}
+		return v
+	case SchemaTime:
+		s, ok := v.(string)
+		if !ok {
+			return mismatch()
+		}
+		tm, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return mismatch()
+		}
+		if out := tm.Format(time.RFC3339Nano); out != s {
+			rt.report(path, RoundTripChangedValue, "%q encoded as %q", s, out)
+			return out
+		}
+		return v
+	case SchemaAny:
+		return rt.any(v, path)
+	case SchemaPointer, SchemaOptional:
+		return rt.value(v, t.Elem, path)
+	case SchemaSlice:
+		elems, ok := v.([]interface{})
+		if !ok {
+			return mismatch()
+		}
+		out := make([]interface{}, 0, len(elems))
+		for i, e := range elems {
+			elemPath := fmt.Sprintf("%s[%d]", path, i)
+			if e == nil && t.SkipNulls {
+				rt.report(elemPath, RoundTripBecameAbsent, "null element skipped")
+				continue
+			}
+			out = append(out, rt.value(e, t.Elem, elemPath))
+		}
+		return out
+	case SchemaMap:
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return mismatch()
+		}
+		out := make(map[string]interface{}, len(obj))
+		for _, k := range sortedKeys(obj) {
+			key := k
+			if rt.resolve(t.Key).Kind == SchemaInt {
+				i, err := strconv.ParseInt(k, 10, 64)
+				if err != nil {
+					rt.report(path+"."+k, RoundTripTypeMismatch, "key %q into int", k)
+					continue
+				}
+				key = strconv.FormatInt(i, 10)
+			}
+			out[key] = rt.value(obj[k], t.Elem, path+"."+k)
+		}
+		return out
+	case SchemaStruct:
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return mismatch()
+		}
+		return rt.object(obj, t, path)
+	}
+	return v
+}
+
+// object returns json object decoded to struct type t and encoded back.
+func (rt *roundTrip) object(obj map[string]interface{}, t *SchemaType, path string) map[string]interface{} {
+	keys := sortedKeys(obj)
+
+	// Keys are matched with fields like encoding/json does: exact match is preferred over case insensitive one.
+	matched := make(map[int]string)
+	unmatched := make(map[string]bool)
+	for _, k := range keys {
+		unmatched[k] = true
+		for i, f := range t.Fields {
+			if _, ok := matched[i]; !ok && f.Key == k {
+				matched[i] = k
+				delete(unmatched, k)
+				break
+			}
+		}
+	}
+	for _, k := range keys {
+		if !unmatched[k] {
+			continue
+		}
+		for i, f := range t.Fields {
+			if _, ok := matched[i]; !ok && strings.EqualFold(f.Key, k) {
+				matched[i] = k
+				delete(unmatched, k)
+				rt.report(path+"."+k, RoundTripDroppedKey, "decoded to field %s, encoded as %q", f.Name, f.Key)
+				break
+			}
+		}
+	}
+
+	out := make(map[string]interface{})
+	for _, k := range keys {
+		if !unmatched[k] {
+			continue
+		}
+		if t.KeepsUnknown {
+			out[k] = obj[k]
+		} else {
+			rt.report(path+"."+k, RoundTripDroppedKey, "")
+		}
+	}
+
+	for i, f := range t.Fields {
+		k, present := matched[i]
+		if !present {
+			if v, ok := rt.missing(f); ok {
+				rt.report(path+"."+f.Key, RoundTripBecamePresent, "encoded as %s", jsonKindName(v))
+				out[f.Key] = v
+			}
+			continue
+		}
+
+		fieldPath := path + "." + k
+		v := rt.value(obj[k], f.Type, fieldPath)
+		if rt.omitted(v, f) {
+			rt.report(fieldPath, RoundTripBecameAbsent, "%s value omitted", jsonKindName(obj[k]))
+			continue
+		}
+		out[f.Key] = v
+	}
+	return out
+}
+
+// omitted checks if encoded value of field is omitted by its json tag options.
+func (rt *roundTrip) omitted(v interface{}, f SchemaField) bool {
+	switch rt.resolve(f.Type).Kind {
+	case SchemaStruct, SchemaTime, SchemaOpaque, SchemaOptional:
+		// Values of these kinds, when present, aren't empty.
+		return false
+	}
+	if !f.OmitEmpty && !f.OmitZero {
+		return false
+	}
+	switch vv := v.(type) {
+	case nil:
+		return true
+	case bool:
+		return !vv
+	case string:
+		return vv == ""
+	case json.Number:
+		return sameNumber(vv, "0")
+	case []interface{}:
+		return f.OmitEmpty && len(vv) == 0
+	case map[string]interface{}:
+		return f.OmitEmpty && len(vv) == 0
+	}
+	return false
+}
+
+// missing returns encoded value of field, which key is absent, and false if it's omitted.
+func (rt *roundTrip) missing(f SchemaField) (interface{}, bool) {
+	switch rt.resolve(f.Type).Kind {
+	case SchemaOptional:
+		return nil, !f.OmitZero
+	case SchemaOpaque:
+		// Zero values of opaque types are unknown, they are assumed to be empty.
+		return nil, !f.OmitEmpty && !f.OmitZero
+	}
+	v := rt.zero(f.Type)
+	return v, !rt.omitted(v, f)
+}
+
+// zero returns encoded zero value of type t.
+func (rt *roundTrip) zero(t *SchemaType) interface{} {
+	t = rt.resolve(t)
+	switch t.Kind {
+	case SchemaBool:
+		return false
+	case SchemaInt, SchemaFloat:
+		return json.Number("0")
+	case SchemaString:
+		return ""
+	case SchemaTime:
+		return time.Time{}.Format(time.RFC3339Nano)
+	case SchemaStruct:
+		out := make(map[string]interface{})
+		for _, f := range t.Fields {
+			if v, ok := rt.missing(f); ok {
+				out[f.Key] = v
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// float returns number decoded to float type of given size and encoded back.
+func (rt *roundTrip) float(n json.Number, bits int, path string) interface{} {
+	f, err := strconv.ParseFloat(n.String(), bits)
+	if err != nil {
+		rt.report(path, RoundTripTypeMismatch, "number %s into float%d", n, bits)
+		return json.Number("0")
+	}
+	out := json.Number(formatFloat(f, bits))
+	if !sameNumber(n, out) {
+		rt.report(path, RoundTripLostPrecision, "%s encoded as %s", n, out)
+	}
+	return out
+}
+
+// any returns value decoded to interface{} and encoded back.
+func (rt *roundTrip) any(v interface{}, path string) interface{} {
+	switch vv := v.(type) {
+	case json.Number:
+		return rt.float(vv, 64, path)
+	case []interface{}:
+		out := make([]interface{}, len(vv))
+		for i, e := range vv {
+			out[i] = rt.any(e, fmt.Sprintf("%s[%d]", path, i))
+		}
+		return out
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(vv))
+		for _, k := range sortedKeys(vv) {
+			out[k] = rt.any(vv[k], path+"."+k)
+		}
+		return out
+	}
+	return v
+}
+
+// formatFloat formats float like encoding/json does.
+func formatFloat(f float64, bits int) string {
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 {
+		if bits == 64 && (abs < 1e-6 || abs >= 1e21) || bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+	s := strconv.FormatFloat(f, format, -1, bits)
+	if format == 'e' {
+		// Exponents are shortened, like "1e-07" to "1e-7".
+		if n := len(s); n >= 4 && s[n-4] == 'e' && s[n-3] == '-' && s[n-2] == '0' {
+			s = s[:n-2] + s[n-1:]
+		}
+	}
+	return s
+}
+
+// sameNumber checks if json numbers have equal values.
+func sameNumber(a, b json.Number) bool {
+	ra, ok := new(big.Rat).SetString(a.String())
+	if !ok {
+		return false
+	}
+	rb, ok := new(big.Rat).SetString(b.String())
+	return ok && ra.Cmp(rb) == 0
+}
+
+// jsonKindName returns name of json kind of decoded value.
+func jsonKindName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	}
+	return "object"
+}
+
+func sortedKeys(obj map[string]interface{}) []string {
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,107 @@
+package json2go
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	parser := NewJSONParser(baseTypeName)
+	require.NoError(t, parser.FeedBytes([]byte(`{"id":1,"price":1.5,"name":"a","tags":["x"],"user":{"n":1},"at":"2021-01-01T00:00:00Z"}`)))
+	require.NoError(t, parser.FeedBytes([]byte(`{"id":2,"price":2,"name":null,"tags":null,"user":null,"at":"2021-01-01T00:00:00Z"}`)))
+	schema := parser.Schema()
+
+	testCases := []struct {
+		name   string
+		sample string
+		issues []RoundTripIssue
+		output string
+	}{
+		{
+			name:   "lossless",
+			sample: `{"id":3,"price":1.25,"name":"b","tags":["y"],"user":{"n":2},"at":"2021-01-01T00:00:00Z"}`,
+			output: `{"at":"2021-01-01T00:00:00Z","id":3,"name":"b","price":1.25,"tags":["y"],"user":{"n":2}}`,
+		},
+		{
+			name:   "dropped keys",
+			sample: `{"id":3,"price":1,"name":"b","tags":[],"user":{"n":2,"extra":1},"at":"2021-01-01T00:00:00Z","ID":4,"other":true}`,
+			issues: []RoundTripIssue{
+				{Path: "$.ID", Kind: RoundTripDroppedKey},
+				{Path: "$.other", Kind: RoundTripDroppedKey},
+				{Path: "$.user.extra", Kind: RoundTripDroppedKey},
+			},
+			output: `{"at":"2021-01-01T00:00:00Z","id":3,"name":"b","price":1,"tags":[],"user":{"n":2}}`,
+		},
+		{
+			name:   "precision and types",
+			sample: `{"id":1.5,"price":12345678901234567890,"tags":null,"user":null,"at":"2021-01-01T00:00:00.000Z","Name":"c"}`,
+			issues: []RoundTripIssue{
+				{Path: "$.Name", Kind: RoundTripDroppedKey, Detail: `decoded to field Name, encoded as "name"`},
+				{Path: "$.at", Kind: RoundTripChangedValue, Detail: `"2021-01-01T00:00:00.000Z" encoded as "2021-01-01T00:00:00Z"`},
+				{Path: "$.id", Kind: RoundTripTypeMismatch, Detail: "number value into int"},
+				{Path: "$.price", Kind: RoundTripLostPrecision, Detail: "12345678901234567890 encoded as 12345678901234567000"},
+			},
+			output: `{"at":"2021-01-01T00:00:00Z","id":0,"name":"c","price":12345678901234567000,"tags":null,"user":null}`,
+		},
+		{
+			name:   "null and absent",
+			sample: `{"id":null,"price":1,"tags":[]}`,
+			issues: []RoundTripIssue{
+				{Path: "$.at", Kind: RoundTripBecamePresent, Detail: "encoded as string"},
+				{Path: "$.id", Kind: RoundTripNullBecameZero},
+				{Path: "$.name", Kind: RoundTripBecamePresent, Detail: "encoded as null"},
+				{Path: "$.user", Kind: RoundTripBecamePresent, Detail: "encoded as null"},
+			},
+			output: `{"at":"0001-01-01T00:00:00Z","id":0,"name":null,"price":1,"tags":[],"user":null}`,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			report, err := VerifyRoundTrip([]byte(tc.sample), schema)
+			require.NoError(t, err)
+			assert.Equal(t, tc.issues, report.Issues)
+			assert.Equal(t, len(tc.issues) == 0, report.Lossless())
+			assert.Equal(t, tc.output, string(report.Output))
+		})
+	}
+}
+
+func TestVerifyRoundTripOptions(t *testing.T) {
+	t.Parallel()
+
+	samples := []string{`{"a":1,"b":[1,null],"c":{"x":1}}`, `{"b":[],"c":{"x":null}}`}
+	newSchema := func(opts ...JSONParserOpt) *Schema {
+		parser := NewJSONParser(baseTypeName, opts...)
+		for _, s := range samples {
+			require.NoError(t, parser.FeedBytes([]byte(s)))
+		}
+		return parser.Schema()
+	}
+
+	sample := []byte(`{"b":[2,null],"c":{"x":null},"d":1}`)
+
+	report, err := VerifyRoundTrip(sample, newSchema(OptOptionalType(true)))
+	require.NoError(t, err)
+	assert.Equal(t, []RoundTripIssue{{Path: "$.d", Kind: RoundTripDroppedKey}}, report.Issues)
+	assert.Equal(t, `{"b":[2,null],"c":{"x":null}}`, string(report.Output))
+
+	report, err = VerifyRoundTrip(sample, newSchema(OptNullElements(NullElementsSkip), OptUnknownFields(true)))
+	require.NoError(t, err)
+	assert.Equal(t, []RoundTripIssue{
+		{Path: "$.b[1]", Kind: RoundTripBecameAbsent, Detail: "null element skipped"},
+	}, report.Issues)
+	assert.Equal(t, `{"b":[2],"c":{"x":null},"d":1}`, string(report.Output))
+
+	_, err = VerifyRoundTrip([]byte(`{`), newSchema())
+	assert.Error(t, err)
+	_, err = VerifyRoundTrip(sample, &Schema{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,300 @@
+package json2go
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/textproto"
+	"strconv"
+	"strings"
+)
+
+// JSON-RPC error codes.
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+)
+
+// rpcMessageOverhead is a size in bytes allowed for rpc messages in addition to Service.MaxInputSize,
+// for parameters other than converted text.
+const rpcMessageOverhead = 1 << 20
+
+// RPC method names.
+const (
+	// RPCMethodConvert converts json text to go code, see RPCConvertParams and RPCConvertResult.
+	RPCMethodConvert = "json2go/convert"
+	rpcMethodInit    = "initialize"
+	rpcMethodDown    = "shutdown"
+	rpcMethodExit    = "exit"
+)
+
+// RPCConvertParams are parameters of RPCMethodConvert request.
+type RPCConvertParams struct {
+	// Text is json text, like text selected in editor. It may contain multiple documents.
+	Text   string `json:"text"`
+	Config Config `json:"config"`
+	// Profile selects named config of service, see Service.Profiles. Result of "initialize" request lists profiles.
+	Profile string `json:"profile,omitempty"`
+}
+
+// RPCConvertResult is a result of RPCMethodConvert request. Code is empty, if there are error diagnostics.
+type RPCConvertResult struct {
+	Code        string          `json:"code"`
+	Imports     []string        `json:"imports"`
+	Diagnostics []RPCDiagnostic `json:"diagnostics"`
+}
+
+// RPCDiagnostic is a problem found in converted text. Line and character are zero based, like in LSP,
+// and character counts UTF-16 code units.
+type RPCDiagnostic struct {
+	Severity  string `json:"severity"` // "error" or "warning"
+	Message   string `json:"message"`
+	Line      int    `json:"line"`
+	Character int    `json:"character"`
+}
+
+type rpcMessage struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+	Result  interface{}      `json:"result,omitempty"`
+	Error   *rpcError        `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// ServeRPC serves JSON-RPC 2.0 requests read from r, writing responses to w, until "exit" notification,
+// end of input or context cancellation. Messages are framed with Content-Length headers, like in Language Server Protocol,
+// so editor plugins can reuse their LSP clients. Service options are applied to all conversions.
+// With Service.MaxInputSize, messages larger than it by more than 1 MiB are skipped, with error responses.
+func ServeRPC(ctx context.Context, r io.Reader, w io.Writer, s *Service) error {
+	var maxSize int64
+	if s.MaxInputSize > 0 {
+		maxSize = s.MaxInputSize + rpcMessageOverhead
+	}
+	tr := textproto.NewReader(bufio.NewReader(r))
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		body, err := readRPCMessage(tr, maxSize)
+		if err == io.EOF {
+			return nil
+		}
+		if errors.Is(err, ErrInputTooLarge) {
+			if err := writeRPCMessage(w, rpcMessage{Error: &rpcError{Code: rpcInvalidRequest, Message: err.Error()}}); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		var req rpcMessage
+		if err := json.Unmarshal(body, &req); err != nil {
+			if err := writeRPCMessage(w, rpcMessage{Error: &rpcError{Code: rpcParseError, Message: err.Error()}}); err != nil {
+				return err
+			}
+			continue
+		}
+		if req.Method == rpcMethodExit {
+			return nil
+		}
+
+		resp := rpcMessage{ID: req.ID}
+		resp.Result, resp.Error = s.handleRPC(ctx, req.Method, req.Params)
+		if req.ID == nil {
+			// Notifications have no responses.
+			continue
+		}
+		if resp.Error == nil && resp.Result == nil {
+			resp.Result = json.RawMessage("null")
+		}
+		if err := writeRPCMessage(w, resp); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Service) handleRPC(ctx context.Context, method string, params json.RawMessage) (interface{}, *rpcError) {
+	switch method {
+	case rpcMethodInit:
+		return map[string]interface{}{
+			"capabilities": map[string]interface{}{"methods": []string{RPCMethodConvert}, "profiles": s.ProfileNames()},
+		}, nil
+	case rpcMethodDown:
+		return nil, nil
+	case RPCMethodConvert:
+		var p RPCConvertParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: err.Error()}
+		}
+		return s.convertText(ctx, p), nil
+	}
+	return nil, &rpcError{Code: rpcMethodNotFound, Message: "method not found: " + method}
+}
+
+// convertText converts json text, reporting problems as diagnostics.
+func (s *Service) convertText(ctx context.Context, params RPCConvertParams) RPCConvertResult {
+	result := RPCConvertResult{Imports: []string{}, Diagnostics: []RPCDiagnostic{}}
+
+	if err := s.admit(ctx, int64(len(params.Text))); err != nil {
+		result.Diagnostics = append(result.Diagnostics, RPCDiagnostic{Severity: "error", Message: err.Error()})
+		return result
+	}
+	p, err := s.newParser(params.Config, params.Profile)
+	if err != nil {
+		result.Diagnostics = append(result.Diagnostics, RPCDiagnostic{Severity: "error", Message: err.Error()})
+		return result
+	}
+	if err := p.FeedReaderContext(ctx, strings.NewReader(params.Text)); err != nil {
+		d := RPCDiagnostic{Severity: "error", Message: err.Error()}
+		if errors.Is(err, ErrInvalidJSON) {
+			if offset, ok := textSyntaxErrorOffset(params.Text, p.opts.nonFiniteNumbers); ok {
+				d.Line, d.Character = textPosition(params.Text, offset)
+			}
+		}
+		result.Diagnostics = append(result.Diagnostics, d)
+		return result
+	}
+
+	code, err := p.Generate()
+	if err != nil {
+		result.Diagnostics = append(result.Diagnostics, RPCDiagnostic{Severity: "error", Message: err.Error()})
+		return result
+	}
+	result.Code = code
+	result.Imports = append(result.Imports, p.Imports()...)
+	for _, w := range p.Warnings() {
+		result.Diagnostics = append(result.Diagnostics, RPCDiagnostic{Severity: "warning", Message: w})
+	}
+	return result
+}
+
+// textSyntaxErrorOffset returns byte offset in text of character, at which its json documents, decoded like
+// FeedReader decodes them, aren't valid. Offsets of syntax errors of json.Decoder aren't used, as they are relative
+// to decoded stream.
+func textSyntaxErrorOffset(text string, nonFinite bool) (int, bool) {
+	bom := 0
+	if strings.HasPrefix(text, "\ufeff") {
+		bom = len("\ufeff")
+	}
+	original := []byte(text[bom:])
+	data := original
+	if nonFinite {
+		data = quoteNonFiniteNumbers(data)
+	}
+
+	// Documents are skipped, until the invalid one, which is decoded alone, so offset of error is relative to it.
+	jd := json.NewDecoder(bytes.NewReader(data))
+	start := 0
+	for {
+		var raw json.RawMessage
+		if err := jd.Decode(&raw); err == io.EOF {
+			return 0, false
+		} else if err != nil {
+			break
+		}
+		start = bytes.Index(data[start:], raw) + start + len(raw)
+	}
+	start += len(data[start:]) - len(bytes.TrimLeft(data[start:], " \t\r\n"))
+	var v interface{}
+	err := json.Unmarshal(data[start:], &v)
+	var syntaxErr *json.SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		return 0, false
+	}
+	offset := start + int(syntaxErr.Offset) - 1
+	if offset < start {
+		offset = start
+	}
+	if nonFinite {
+		offset = unquotedOffset(data, original, offset)
+	}
+	return bom + offset, true
+}
+
+// unquotedOffset returns offset in original data, of byte at offset in data with quoted NaN and infinity literals,
+// see quoteNonFiniteNumbers. Quoting only inserts bytes, which are skipped.
+func unquotedOffset(quoted, original []byte, offset int) int {
+	j := 0
+	for i := 0; i < offset && j < len(original); i++ {
+		if quoted[i] == original[j] {
+			j++
+		}
+	}
+	return j
+}
+
+// textPosition returns zero based line and character of byte offset in text. Characters are UTF-16 code units,
+// like in LSP.
+func textPosition(text string, offset int) (line, character int) {
+	if offset > len(text) {
+		offset = len(text)
+	}
+	before := text[:offset]
+	line = strings.Count(before, "\n")
+	for _, r := range before[strings.LastIndex(before, "\n")+1:] {
+		character++
+		if r >= 0x10000 {
+			// Runes outside of basic multilingual plane are surrogate pairs.
+			character++
+		}
+	}
+	return line, character
+}
+
+// readRPCMessage reads message body framed with Content-Length header. Bodies larger than maxSize, unless it's 0,
+// are skipped, and error matching ErrInputTooLarge is returned.
+func readRPCMessage(tr *textproto.Reader, maxSize int64) ([]byte, error) {
+	header, err := tr.ReadMIMEHeader()
+	if err == io.EOF && len(header) == 0 {
+		return nil, io.EOF
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading rpc message header: %w", err)
+	}
+
+	length, err := strconv.ParseInt(header.Get("Content-Length"), 10, 64)
+	if err != nil || length < 0 {
+		return nil, fmt.Errorf("invalid rpc message Content-Length: %q", header.Get("Content-Length"))
+	}
+	if maxSize > 0 && length > maxSize {
+		if _, err := io.CopyN(ioutil.Discard, tr.R, length); err != nil {
+			return nil, fmt.Errorf("reading rpc message: %w", err)
+		}
+		return nil, fmt.Errorf("%w: rpc message has %d bytes, limit is %d bytes", ErrInputTooLarge, length, maxSize)
+	}
+	// Body isn't allocated at once, so wrong lengths don't allocate more memory than there is input.
+	var body bytes.Buffer
+	if _, err := io.CopyN(&body, tr.R, length); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, fmt.Errorf("reading rpc message: %w", err)
+	}
+	return body.Bytes(), nil
+}
+
+func writeRPCMessage(w io.Writer, m rpcMessage) error {
+	m.JSONRPC = "2.0"
+	body, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	return err
+}
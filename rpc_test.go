@@ -0,0 +1,140 @@
+package json2go
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServeRPC(t *testing.T) {
+	t.Parallel()
+
+	var in bytes.Buffer
+	for _, m := range []string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"json2go/convert","params":{"text":"{\"id\":1,\"at\":\"2020-01-01T00:00:00Z\"}","config":{"rootName":"Event"}}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"json2go/convert","params":{"text":"{\"id\":1,\n\"x\":}"}}`,
+		`{"jsonrpc":"2.0","method":"json2go/convert","params":{}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"unknown"}`,
+		`{"jsonrpc":"2.0","id":5,"method":"shutdown"}`,
+		`{"jsonrpc":"2.0","method":"exit"}`,
+		`{"jsonrpc":"2.0","id":6,"method":"initialize"}`,
+	} {
+		fmt.Fprintf(&in, "Content-Length: %d\r\n\r\n%s", len(m), m)
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, ServeRPC(context.Background(), &in, &out, &Service{}))
+
+	tr := textproto.NewReader(bufio.NewReader(&out))
+	var responses []map[string]interface{}
+	for {
+		body, err := readRPCMessage(tr, 0)
+		if err != nil {
+			break
+		}
+		var resp map[string]interface{}
+		require.NoError(t, json.Unmarshal(body, &resp))
+		responses = append(responses, resp)
+	}
+	require.Len(t, responses, 5, "notifications have no responses, messages after exit are not read")
+
+	convert := responses[1]["result"].(map[string]interface{})
+	assert.Equal(t, "type Event struct {\n\tAt time.Time `json:\"at\"`\n\tID int       `json:\"id\"`\n}", convert["code"])
+	assert.Equal(t, []interface{}{"time"}, convert["imports"])
+	assert.Empty(t, convert["diagnostics"])
+
+	invalid := responses[2]["result"].(map[string]interface{})
+	assert.Equal(t, "", invalid["code"])
+	diagnostics := invalid["diagnostics"].([]interface{})
+	require.Len(t, diagnostics, 1)
+	d := diagnostics[0].(map[string]interface{})
+	assert.Equal(t, "error", d["severity"])
+	assert.Equal(t, 1.0, d["line"])
+	assert.Equal(t, 4.0, d["character"])
+
+	assert.Equal(t, float64(rpcMethodNotFound), responses[3]["error"].(map[string]interface{})["code"])
+	assert.Contains(t, responses[4], "result")
+	assert.Nil(t, responses[4]["result"])
+}
+
+func TestTextPosition(t *testing.T) {
+	t.Parallel()
+
+	text := "ab\ncd\n"
+	for offset, expected := range [][2]int{{0, 0}, {0, 1}, {0, 2}, {1, 0}, {1, 1}, {1, 2}, {2, 0}, {2, 0}} {
+		line, character := textPosition(text, offset)
+		assert.Equal(t, expected, [2]int{line, character}, "offset %d", offset)
+	}
+
+	// Characters are UTF-16 code units.
+	line, character := textPosition("ä😀x", len("ä😀"))
+	assert.Equal(t, [2]int{0, 3}, [2]int{line, character})
+}
+
+func TestTextSyntaxErrorOffset(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name      string
+		text      string
+		nonFinite bool
+		expected  string // text before error
+	}{
+		{name: "first document", text: `{"a":}`, expected: `{"a":`},
+		{name: "later document", text: "{\"a\":1}\n {\"a\":2}\n\t{\"a\" 3}", expected: "{\"a\":1}\n {\"a\":2}\n\t{\"a\" "},
+		{name: "after document", text: `{} x`, expected: `{} `},
+		{name: "unexpected end", text: `{"a":[1`, expected: `{"a":[`},
+		{name: "byte order mark", text: "\ufeff{\"ä\":}", expected: "\ufeff{\"ä\":"},
+		{name: "non-finite numbers", text: `{"a":NaN,"b":-Infinity,"c":}`, nonFinite: true, expected: `{"a":NaN,"b":-Infinity,"c":`},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			offset, ok := textSyntaxErrorOffset(tc.text, tc.nonFinite)
+			require.True(t, ok)
+			assert.Equal(t, tc.expected, tc.text[:offset])
+		})
+	}
+
+	_, ok := textSyntaxErrorOffset(`{"a":1} {}`, false)
+	assert.False(t, ok)
+}
+
+func TestServeRPCMaxMessageSize(t *testing.T) {
+	t.Parallel()
+
+	var in bytes.Buffer
+	large := `{"jsonrpc":"2.0","id":1,"method":"json2go/convert","params":{"text":"` + strings.Repeat(" ", rpcMessageOverhead+10) + `{}"}}`
+	fmt.Fprintf(&in, "Content-Length: %d\r\n\r\n%s", len(large), large)
+	small := `{"jsonrpc":"2.0","id":2,"method":"shutdown"}`
+	fmt.Fprintf(&in, "Content-Length: %d\r\n\r\n%s", len(small), small)
+
+	var out bytes.Buffer
+	require.NoError(t, ServeRPC(context.Background(), &in, &out, &Service{MaxInputSize: 5}))
+	tr := textproto.NewReader(bufio.NewReader(&out))
+	body, err := readRPCMessage(tr, 0)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `"code":-32600`)
+	assert.Contains(t, string(body), "input too large")
+	body, err = readRPCMessage(tr, 0)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `"id":2`)
+
+	// Wrong lengths don't allocate more than input.
+	_, err = readRPCMessage(textproto.NewReader(bufio.NewReader(strings.NewReader("Content-Length: 1000000000000\r\n\r\n{}"))), 0)
+	assert.True(t, errors.Is(err, io.ErrUnexpectedEOF), "%v", err)
+}
@@ -0,0 +1,147 @@
+package json2go
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// rustKeywords are strict and reserved keywords of Rust, used as raw identifiers in field names.
+var rustKeywords = map[string]bool{
+	"abstract": true, "as": true, "async": true, "await": true, "become": true, "box": true, "break": true,
+	"const": true, "continue": true, "do": true, "dyn": true, "else": true, "enum": true, "extern": true,
+	"false": true, "final": true, "fn": true, "for": true, "gen": true, "if": true, "impl": true, "in": true,
+	"let": true, "loop": true, "macro": true, "match": true, "mod": true, "move": true, "mut": true,
+	"override": true, "priv": true, "pub": true, "ref": true, "return": true, "static": true, "struct": true,
+	"trait": true, "true": true, "try": true, "type": true, "typeof": true, "unsafe": true, "unsized": true,
+	"use": true, "virtual": true, "where": true, "while": true, "yield": true,
+}
+
+// rustNonRaw are keywords of Rust, which can't be raw identifiers.
+var rustNonRaw = map[string]bool{"crate": true, "self": true, "super": true, "Self": true}
+
+// RustEmitter emits Rust structs with serde attributes from IR, root struct first.
+//
+// Structs keep go names and fields are go names in snake case, renamed to json keys. Fields of pointers, optional
+// values or with omitempty are options, skipped when none, and options of recursive structs are boxed.
+// Times are chrono UTC date times, uuids are uuid crate uuids, any and opaque values are serde_json values.
+type RustEmitter struct{}
+
+// Emit returns Rust source of structs.
+func (RustEmitter) Emit(ir *Schema) ([]byte, error) {
+	s, err := newIDLSchema(ir, "Rust struct", false)
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	b.WriteString("use serde::{Deserialize, Serialize};\n")
+	for _, d := range s.decls {
+		fmt.Fprintf(&b, "\n#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]\npub struct %s {\n", d.name)
+		names := make(map[string]bool)
+		for _, f := range d.fields {
+			name := snakeCaseName(f.Name)
+			if rustNonRaw[name] {
+				name += "_"
+			}
+			for names[name] {
+				name = nextName(name)
+			}
+			names[name] = true
+
+			var attrs []string
+			if name != f.Key {
+				attrs = append(attrs, "rename = "+strconv.Quote(f.Key))
+			}
+			typ := rustType(ir, s, f.Type, d.t)
+			if f.isOptional() {
+				if !strings.HasPrefix(typ, "Option<") {
+					typ = "Option<" + typ + ">"
+				}
+				attrs = append(attrs, "default", `skip_serializing_if = "Option::is_none"`)
+			}
+			if len(attrs) > 0 {
+				fmt.Fprintf(&b, "    #[serde(%s)]\n", strings.Join(attrs, ", "))
+			}
+			if rustKeywords[name] {
+				name = "r#" + name
+			}
+			fmt.Fprintf(&b, "    pub %s: %s,\n", name, typ)
+		}
+		b.WriteString("}\n")
+	}
+	return []byte(b.String()), nil
+}
+
+// rustType returns Rust type of values of field of struct. Options of structs containing struct are boxed.
+func rustType(ir *Schema, s *idlSchema, t *SchemaType, parent *SchemaType) string {
+	switch t.Kind {
+	case SchemaBool:
+		return "bool"
+	case SchemaInt:
+		return "i64"
+	case SchemaFloat:
+		return fmt.Sprintf("f%d", t.Bits)
+	case SchemaString:
+		if t.Format == SchemaFormatUUID {
+			return "uuid::Uuid"
+		}
+		return "String"
+	case SchemaTime:
+		return "chrono::DateTime<chrono::Utc>"
+	case SchemaPointer, SchemaOptional:
+		elem := rustType(ir, s, t.Elem, parent)
+		if elem := irElemType(ir, t.Elem); elem.Kind == SchemaStruct && rustContains(ir, elem, parent, make(map[*SchemaType]bool)) {
+			return "Option<Box<" + s.names[elem] + ">>"
+		}
+		if strings.HasPrefix(elem, "Option<") {
+			return elem
+		}
+		return "Option<" + elem + ">"
+	case SchemaSlice:
+		return "Vec<" + rustType(ir, s, t.Elem, nil) + ">"
+	case SchemaMap:
+		key := "String"
+		if t.Key != nil {
+			key = rustType(ir, s, t.Key, nil)
+		}
+		return "std::collections::HashMap<" + key + ", " + rustType(ir, s, t.Elem, nil) + ">"
+	case SchemaStruct:
+		return s.names[t]
+	case SchemaNamed:
+		return rustType(ir, s, ir.Types[t.Name], parent)
+	}
+	return "serde_json::Value"
+}
+
+// rustContains checks if struct contains values of other struct, directly or in options, without indirection
+// of vectors or maps.
+func rustContains(ir *Schema, t, other *SchemaType, visited map[*SchemaType]bool) bool {
+	if t == other {
+		return true
+	}
+	if visited[t] {
+		return false
+	}
+	visited[t] = true
+	for _, f := range t.Fields {
+		elem := f.Type
+		for elem.Kind == SchemaPointer || elem.Kind == SchemaOptional || elem.Kind == SchemaNamed {
+			if elem.Kind == SchemaNamed {
+				elem = ir.Types[elem.Name]
+			} else {
+				elem = elem.Elem
+			}
+		}
+		if elem.Kind == SchemaStruct && rustContains(ir, elem, other, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+// RustTypes returns Rust structs describing parsed documents, see RustEmitter.
+func (p *JSONParser) RustTypes() (string, error) {
+	out, err := RustEmitter{}.Emit(p.Schema())
+	return string(out), err
+}
@@ -0,0 +1,105 @@
+package json2go
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRustTypes(t *testing.T) {
+	t.Parallel()
+
+	p := NewJSONParser(baseTypeName)
+	require.NoError(t, p.FeedBytes([]byte(`{"id": 1, "userName": "a", "tags": ["x"], "addr": {"zip": "1"},
+		"at": "2021-01-01T00:00:00Z", "extra": 1, "type": "t"}`)))
+	require.NoError(t, p.FeedBytes([]byte(`{"id": 2, "tags": [], "addr": {"zip": "2"},
+		"at": "2021-01-02T00:00:00Z", "extra": "x", "score": 0.5, "type": "t"}`)))
+
+	src, err := p.RustTypes()
+	require.NoError(t, err)
+	assert.Equal(t, `use serde::{Deserialize, Serialize};
+
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+pub struct Document {
+    pub addr: DocumentAddr,
+    pub at: chrono::DateTime<chrono::Utc>,
+    pub extra: serde_json::Value,
+    pub id: i64,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub score: Option<f64>,
+    pub tags: Vec<String>,
+    pub r#type: String,
+    #[serde(rename = "userName", default, skip_serializing_if = "Option::is_none")]
+    pub user_name: Option<String>,
+}
+
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+pub struct DocumentAddr {
+    pub zip: String,
+}
+`, src)
+}
+
+func TestRustEmitter(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		ir       *Schema
+		expected string
+		err      error
+	}{
+		{
+			name: "recursive types",
+			ir: &Schema{Root: "Node", Types: map[string]*SchemaType{
+				"Node": {Kind: SchemaStruct, Fields: []SchemaField{
+					{Name: "Children", Key: "children", Type: &SchemaType{Kind: SchemaSlice, Elem: &SchemaType{Kind: SchemaNamed, Name: "Node"}}},
+					{Name: "Link", Key: "link", Type: &SchemaType{Kind: SchemaPointer, Elem: &SchemaType{Kind: SchemaNamed, Name: "Link"}}},
+					{Name: "Self", Key: "self", Type: &SchemaType{Kind: SchemaMap, Key: &SchemaType{Kind: SchemaString, Format: SchemaFormatUUID},
+						Elem: &SchemaType{Kind: SchemaSlice, Elem: &SchemaType{Kind: SchemaFloat, Bits: 32}}}},
+				}},
+				"Link": {Kind: SchemaStruct, Fields: []SchemaField{
+					{Name: "Node", Key: "node", Type: &SchemaType{Kind: SchemaNamed, Name: "Node"}},
+				}},
+			}},
+			expected: `use serde::{Deserialize, Serialize};
+
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+pub struct Node {
+    pub children: Vec<Node>,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub link: Option<Box<Link>>,
+    #[serde(rename = "self")]
+    pub self_: std::collections::HashMap<uuid::Uuid, Vec<f32>>,
+}
+
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+pub struct Link {
+    pub node: Node,
+}
+`,
+		},
+		{
+			name: "root isn't struct",
+			ir:   &Schema{Root: "Document", Types: map[string]*SchemaType{"Document": {Kind: SchemaString}}},
+			err:  ErrUnsupportedShape,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			out, err := RustEmitter{}.Emit(tc.ir)
+			if tc.err != nil {
+				assert.True(t, errors.Is(err, tc.err), "unexpected error: %v", err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(out))
+		})
+	}
+}
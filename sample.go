@@ -0,0 +1,117 @@
+package json2go
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// sampleSeed is a seed of reservoir sampling, so generated types are reproducible.
+const sampleSeed = 1
+
+// sampler limits number of array elements contributing to inference.
+type sampler struct {
+	limit     int
+	reservoir bool
+	rnd       *rand.Rand
+	// sampledPaths are paths of arrays that were sampled.
+	sampledPaths map[string]bool
+}
+
+func newSampler(limit uint, reservoir bool) *sampler {
+	return &sampler{
+		limit:        int(limit),
+		reservoir:    reservoir,
+		rnd:          rand.New(rand.NewSource(sampleSeed)),
+		sampledPaths: make(map[string]bool),
+	}
+}
+
+// sample returns json value with all arrays longer than limit replaced with their samples. Values are copied only
+// on paths to sampled arrays, other values are returned as they are. warn is called once for each sampled array path.
+func (s *sampler) sample(v interface{}, path string, warn func(string)) interface{} {
+	out, _ := s.sampleValue(v, path, warn)
+	return out
+}
+
+// sampleValue returns sampled value, and true if it's a copy of v with sampled arrays.
+func (s *sampler) sampleValue(v interface{}, path string, warn func(string)) (interface{}, bool) {
+	switch typedValue := v.(type) {
+	case map[string]interface{}:
+		var out map[string]interface{}
+		// Keys are sorted, so random numbers of reservoir sampling are used in the same order.
+		for _, k := range sortedKeys(typedValue) {
+			el, sampled := s.sampleValue(typedValue[k], childPath(path, k), warn)
+			if !sampled {
+				continue
+			}
+			if out == nil {
+				out = make(map[string]interface{}, len(typedValue))
+				for key, value := range typedValue {
+					out[key] = value
+				}
+			}
+			out[k] = el
+		}
+		if out == nil {
+			return v, false
+		}
+		return out, true
+	case duplicateValues:
+		out, sampled := s.sampleElements(typedValue, path, warn)
+		if !sampled {
+			return v, false
+		}
+		return duplicateValues(out), true
+	case []interface{}:
+		arraySampled := false
+		if len(typedValue) > s.limit {
+			if !s.sampledPaths[path] {
+				s.sampledPaths[path] = true
+				warn(fmt.Sprintf("%s: array with %d elements sampled to %d elements", path, len(typedValue), s.limit))
+			}
+			typedValue = s.sampleArray(typedValue)
+			arraySampled = true
+		}
+		out, sampled := s.sampleElements(typedValue, path, warn)
+		return out, sampled || arraySampled
+	}
+
+	return v, false
+}
+
+// sampleElements returns sampled elements, and true if it's a copy of elements with sampled arrays.
+func (s *sampler) sampleElements(elements []interface{}, path string, warn func(string)) ([]interface{}, bool) {
+	var out []interface{}
+	for i, el := range elements {
+		sampledEl, sampled := s.sampleValue(el, path, warn)
+		if !sampled {
+			continue
+		}
+		if out == nil {
+			out = make([]interface{}, len(elements))
+			copy(out, elements)
+		}
+		out[i] = sampledEl
+	}
+	if out == nil {
+		return elements, false
+	}
+	return out, true
+}
+
+// sampleArray returns first elements of array, or random elements if reservoir sampling is used.
+func (s *sampler) sampleArray(in []interface{}) []interface{} {
+	if !s.reservoir {
+		return in[:s.limit]
+	}
+
+	out := make([]interface{}, s.limit)
+	copy(out, in)
+	for i := s.limit; i < len(in); i++ {
+		if j := s.rnd.Intn(i + 1); j < s.limit {
+			out[j] = in[i]
+		}
+	}
+
+	return out
+}
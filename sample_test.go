@@ -0,0 +1,100 @@
+package json2go
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSampler(t *testing.T) {
+	t.Parallel()
+
+	var items []interface{}
+	for i := 0; i < 100; i++ {
+		items = append(items, float64(i))
+	}
+	input := map[string]interface{}{
+		"items": items,
+		"short": []interface{}{"a", "b"},
+		"nested": []interface{}{
+			map[string]interface{}{"values": items},
+			map[string]interface{}{"values": items},
+		},
+	}
+
+	testCases := []struct {
+		name      string
+		reservoir bool
+	}{
+		{
+			name:      "first elements",
+			reservoir: false,
+		},
+		{
+			name:      "reservoir",
+			reservoir: true,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			var warnings []string
+			s := newSampler(10, tc.reservoir)
+			out := s.sample(input, rootPath, func(w string) {
+				warnings = append(warnings, w)
+			}).(map[string]interface{})
+
+			sampled := out["items"].([]interface{})
+			require.Len(t, sampled, 10)
+			if !tc.reservoir {
+				assert.Equal(t, items[:10], sampled)
+			}
+			for _, v := range sampled {
+				assert.Contains(t, items, v)
+			}
+			assert.Equal(t, []interface{}{"a", "b"}, out["short"])
+			for _, el := range out["nested"].([]interface{}) {
+				assert.Len(t, el.(map[string]interface{})["values"], 10)
+			}
+			assert.ElementsMatch(t, []string{
+				"$.items: array with 100 elements sampled to 10 elements",
+				"$.nested.values: array with 100 elements sampled to 10 elements",
+			}, warnings)
+
+			// Input is not modified.
+			assert.Len(t, input["items"], 100)
+			assert.Len(t, input["nested"].([]interface{})[0].(map[string]interface{})["values"], 100)
+		})
+	}
+}
+
+func TestSamplerCopiesSampledPaths(t *testing.T) {
+	t.Parallel()
+
+	nested := map[string]interface{}{"short": []interface{}{"a", "b"}}
+	input := map[string]interface{}{"nested": nested, "items": []interface{}{1.0, 2.0, 3.0}}
+
+	s := newSampler(2, false)
+	out := s.sample(input, rootPath, func(string) {}).(map[string]interface{})
+	assert.Len(t, out["items"], 2)
+	assert.Len(t, input["items"], 3)
+
+	// Values without sampled arrays are returned as they are.
+	out["nested"].(map[string]interface{})["x"] = true
+	assert.Equal(t, true, nested["x"])
+	s.sample(nested, rootPath, func(string) {}).(map[string]interface{})["y"] = true
+	assert.Equal(t, true, nested["y"])
+}
+
+func TestParserSampleLimit(t *testing.T) {
+	t.Parallel()
+
+	parser := NewJSONParser(baseTypeName, OptSampleLimit(2))
+	err := parser.FeedBytes([]byte(`{"values":[1,2,3.5]}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, normalizeStr("type Document struct {\n Values []int `json:\"values\"`\n}"), normalizeStr(parser.String()))
+	assert.Equal(t, []string{"$.values: array with 3 elements sampled to 2 elements"}, parser.Warnings())
+}
@@ -0,0 +1,409 @@
+package json2go
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Schema types, as used by schema registries.
+const (
+	SchemaTypeJSON = "JSON"
+	SchemaTypeAvro = "AVRO"
+)
+
+// jsonSchemaDialect is a JSON Schema version of generated schemas.
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema returns JSON Schema describing parsed documents. Extracted types are defined in "$defs".
+func (p *JSONParser) JSONSchema() ([]byte, error) {
+	nodes, err := p.outputNodes()
+	if err != nil {
+		return nil, err
+	}
+
+	schema := nodeSchema(nodes[0])
+	schema["$schema"] = jsonSchemaDialect
+	schema["title"] = nodes[0].name
+	if len(nodes) > 1 {
+		defs := make(map[string]interface{})
+		for _, n := range nodes[1:] {
+			defs[n.name] = nodeSchema(n)
+		}
+		schema["$defs"] = defs
+	}
+
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// nodeSchema returns JSON Schema of node's values.
+func nodeSchema(n *node) map[string]interface{} {
+	var schema map[string]interface{}
+	switch n.t.(type) {
+	case nodeBoolType:
+		schema = map[string]interface{}{"type": "boolean"}
+	case nodeIntType:
+		schema = map[string]interface{}{"type": "integer"}
+	case nodeFloatType:
+		schema = map[string]interface{}{"type": "number"}
+	case nodeStringType:
+		schema = map[string]interface{}{"type": "string"}
+	case nodeTimeType:
+		schema = map[string]interface{}{"type": "string", "format": "date-time"}
+	case nodeObjectType:
+		props := make(map[string]interface{})
+		var required []string
+		for _, c := range n.children {
+			props[c.key] = nodeSchema(c)
+			if c.required {
+				required = append(required, c.key)
+			}
+		}
+		schema = map[string]interface{}{"type": "object", "properties": props}
+		if len(required) > 0 {
+			sort.Strings(required)
+			schema["required"] = required
+		}
+	case nodeMapType:
+		schema = map[string]interface{}{"type": "object"}
+		if len(n.children) > 0 {
+			schema["additionalProperties"] = nodeSchema(n.children[0])
+		}
+	case nodeExtractedType:
+		if isGoType(n.externalTypeID) {
+			// Custom types may have any json representation.
+			schema = map[string]interface{}{}
+			break
+		}
+		schema = map[string]interface{}{"$ref": "#/$defs/" + n.externalTypeID}
+	default:
+		schema = map[string]interface{}{}
+	}
+
+	for i := n.arrayLevel; i > 0; i-- {
+		if n.arrayWithNulls && i == n.arrayLevel {
+			schema = nullableSchema(schema)
+		}
+		schema = map[string]interface{}{"type": "array", "items": schema}
+	}
+	if n.nullable && !n.root {
+		schema = nullableSchema(schema)
+	}
+
+	return schema
+}
+
+// nullableSchema returns schema allowing also null values.
+func nullableSchema(schema map[string]interface{}) map[string]interface{} {
+	if len(schema) == 0 {
+		// Any value is allowed.
+		return schema
+	}
+	if t, ok := schema["type"].(string); ok {
+		schema["type"] = []string{t, "null"}
+		return schema
+	}
+	return map[string]interface{}{
+		"anyOf": []interface{}{schema, map[string]interface{}{"type": "null"}},
+	}
+}
+
+// FeedSchema consumes schema, as if documents valid for schema were parsed.
+// Schema type is SchemaTypeJSON or SchemaTypeAvro. Schemas are converted to sample documents:
+// one with all attributes, and one with required attributes only, with nulls where allowed.
+// Recursive references are followed once.
+func (p *JSONParser) FeedSchema(schemaType string, schema []byte) (err error) {
+	defer recoverError(&err)
+
+	var s interface{}
+	if err := json.Unmarshal(schema, &s); err != nil {
+		return invalidJSONError{err: err}
+	}
+
+	var sample func(full bool) interface{}
+	switch strings.ToUpper(schemaType) {
+	case SchemaTypeJSON, "":
+		sample = func(full bool) interface{} {
+			sampler := p.newSchemaSampler(full)
+			sampler.root, _ = s.(map[string]interface{})
+			return sampler.expand("#", func() interface{} { return sampler.jsonSchema(s) })
+		}
+	case SchemaTypeAvro:
+		sample = func(full bool) interface{} {
+			return p.newSchemaSampler(full).avro(s, "")
+		}
+	default:
+		return fmt.Errorf("%w: schema type %q", ErrUnsupportedShape, schemaType)
+	}
+
+	for _, full := range []bool{true, false} {
+		if err := p.feed(sample(full)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// schemaSampler makes sample values valid for schema. Full sample has all attributes and no nulls,
+// other sample has only required attributes, and nulls where allowed.
+type schemaSampler struct {
+	full      bool
+	mapKeys   int                    // number of keys of sample maps
+	root      map[string]interface{} // root JSON Schema, for resolving references
+	named     map[string]interface{} // named Avro types by name and full name
+	expanding map[string]bool        // references or named types being expanded
+}
+
+func (p *JSONParser) newSchemaSampler(full bool) *schemaSampler {
+	mapKeys := 1
+	if p.opts.makeMaps && p.opts.makeMapsWhenMinAttributes > 1 {
+		// Enough keys for map conversion.
+		mapKeys = int(p.opts.makeMapsWhenMinAttributes)
+	}
+	return &schemaSampler{
+		full:      full,
+		mapKeys:   mapKeys,
+		named:     make(map[string]interface{}),
+		expanding: make(map[string]bool),
+	}
+}
+
+// sampleMap returns map with sample value under mapKeys keys.
+func (s *schemaSampler) sampleMap(value func() interface{}) map[string]interface{} {
+	m := make(map[string]interface{})
+	if !s.full {
+		return m
+	}
+	for i := 1; i <= s.mapKeys; i++ {
+		m["key"+strconv.Itoa(i)] = value()
+	}
+	return m
+}
+
+// expand returns sample of referenced schema, or nil if reference is already being expanded.
+func (s *schemaSampler) expand(ref string, sample func() interface{}) interface{} {
+	if s.expanding[ref] {
+		return nil
+	}
+	s.expanding[ref] = true
+	defer delete(s.expanding, ref)
+	return sample()
+}
+
+// jsonSchema returns sample value valid for JSON Schema.
+func (s *schemaSampler) jsonSchema(v interface{}) interface{} {
+	schema, ok := v.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	if ref, ok := schema["$ref"].(string); ok {
+		return s.expand(ref, func() interface{} {
+			return s.jsonSchema(resolveJSONSchemaRef(ref, s.root))
+		})
+	}
+	if v, ok := schema["const"]; ok {
+		return v
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok && len(enum) > 0 {
+		return enum[0]
+	}
+	for _, k := range []string{"anyOf", "oneOf", "allOf"} {
+		if variants, ok := schema[k].([]interface{}); ok {
+			var sample interface{}
+			for _, v := range variants {
+				if vs, ok := v.(map[string]interface{}); ok && vs["type"] == "null" {
+					if !s.full {
+						return nil
+					}
+					continue
+				}
+				if sample == nil {
+					sample = s.jsonSchema(v)
+				}
+			}
+			return sample
+		}
+	}
+
+	t := schema["type"]
+	if types, ok := t.([]interface{}); ok {
+		t = nil
+		for _, tt := range types {
+			if tt == "null" {
+				if !s.full {
+					return nil
+				}
+				continue
+			}
+			if t == nil {
+				t = tt
+			}
+		}
+	}
+	if t == nil {
+		if _, ok := schema["properties"]; ok {
+			t = "object"
+		} else if _, ok := schema["items"]; ok {
+			t = "array"
+		}
+	}
+
+	switch t {
+	case "boolean":
+		return true
+	case "integer":
+		return 1.0
+	case "number":
+		return 1.5
+	case "string":
+		switch schema["format"] {
+		case "date-time":
+			return "2006-01-02T15:04:05Z"
+		case "date":
+			return "2006-01-02"
+		}
+		return "string"
+	case "array":
+		if !s.full {
+			return []interface{}{}
+		}
+		return []interface{}{s.jsonSchema(schema["items"])}
+	case "object":
+		props, ok := schema["properties"].(map[string]interface{})
+		if !ok {
+			if additional, ok := schema["additionalProperties"].(map[string]interface{}); ok {
+				return s.sampleMap(func() interface{} { return s.jsonSchema(additional) })
+			}
+		}
+
+		required := make(map[string]bool)
+		if req, ok := schema["required"].([]interface{}); ok {
+			for _, r := range req {
+				if k, ok := r.(string); ok {
+					required[k] = true
+				}
+			}
+		}
+		obj := make(map[string]interface{})
+		for k, v := range props {
+			if s.full || required[k] {
+				obj[k] = s.jsonSchema(v)
+			}
+		}
+		return obj
+	}
+
+	return nil
+}
+
+// resolveJSONSchemaRef returns schema referenced with local reference, like "#/$defs/User".
+func resolveJSONSchemaRef(ref string, root map[string]interface{}) interface{} {
+	if !strings.HasPrefix(ref, "#") {
+		return nil
+	}
+
+	var v interface{} = root
+	for _, part := range strings.Split(strings.TrimPrefix(ref, "#"), "/") {
+		if part == "" {
+			continue
+		}
+		part = strings.NewReplacer("~1", "/", "~0", "~").Replace(part)
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = m[part]
+	}
+	return v
+}
+
+// avro returns sample json encoded value valid for Avro schema. Named types are defined in namespace.
+func (s *schemaSampler) avro(v interface{}, namespace string) interface{} {
+	switch schema := v.(type) {
+	case string:
+		switch schema {
+		case "boolean":
+			return true
+		case "int", "long":
+			return 1.0
+		case "float", "double":
+			return 1.5
+		case "string", "bytes":
+			return "string"
+		case "null":
+			return nil
+		}
+
+		name := schema
+		if !strings.Contains(name, ".") && namespace != "" {
+			name = namespace + "." + name
+		}
+		return s.expand(name, func() interface{} {
+			return s.avro(s.named[name], namespace)
+		})
+	case []interface{}:
+		// Union, json encoded as a value of one of types.
+		var sample interface{}
+		for _, v := range schema {
+			if v == "null" {
+				if !s.full {
+					return nil
+				}
+				continue
+			}
+			if sample == nil {
+				sample = s.avro(v, namespace)
+			}
+		}
+		return sample
+	case map[string]interface{}:
+		if name, ok := schema["name"].(string); ok {
+			if ns, ok := schema["namespace"].(string); ok {
+				namespace = ns
+			}
+			if !strings.Contains(name, ".") && namespace != "" {
+				name = namespace + "." + name
+			}
+			if _, ok := s.named[name]; !ok {
+				s.named[name] = schema
+				return s.expand(name, func() interface{} {
+					return s.avro(schema, namespace)
+				})
+			}
+		}
+
+		switch schema["type"] {
+		case "record", "error":
+			obj := make(map[string]interface{})
+			fields, _ := schema["fields"].([]interface{})
+			for _, f := range fields {
+				if fm, ok := f.(map[string]interface{}); ok {
+					if name, ok := fm["name"].(string); ok {
+						obj[name] = s.avro(fm["type"], namespace)
+					}
+				}
+			}
+			return obj
+		case "enum":
+			if symbols, ok := schema["symbols"].([]interface{}); ok && len(symbols) > 0 {
+				return symbols[0]
+			}
+			return "string"
+		case "fixed":
+			return "string"
+		case "array":
+			if !s.full {
+				return []interface{}{}
+			}
+			return []interface{}{s.avro(schema["items"], namespace)}
+		case "map":
+			return s.sampleMap(func() interface{} { return s.avro(schema["values"], namespace) })
+		}
+		return s.avro(schema["type"], namespace)
+	}
+
+	return nil
+}
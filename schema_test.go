@@ -0,0 +1,136 @@
+package json2go
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserJSONSchema(t *testing.T) {
+	parser := NewJSONParser(baseTypeName, OptExtractCommonTypes(true))
+	require.NoError(t, parser.FeedBytes([]byte(`{"id":1,"name":"x","at":"2020-01-01T00:00:00Z","tags":["a"],
+		"from":{"x":1.5,"y":2.5},"to":{"x":3.5,"y":4.5},"note":null}`)))
+	require.NoError(t, parser.FeedBytes([]byte(`{"id":2,"at":"2020-01-01T00:00:00Z","tags":[],"from":{"x":1.5,"y":2.5},
+		"to":{"x":3.5,"y":4.5},"note":"n"}`)))
+
+	schema, err := parser.JSONSchema()
+	require.NoError(t, err)
+
+	expected := `{
+  "$defs": {
+    "XY": {
+      "properties": {"x": {"type": "number"}, "y": {"type": "number"}},
+      "required": ["x", "y"],
+      "type": "object"
+    }
+  },
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "properties": {
+    "at": {"format": "date-time", "type": "string"},
+    "from": {"$ref": "#/$defs/XY"},
+    "id": {"type": "integer"},
+    "name": {"type": "string"},
+    "note": {"type": ["string", "null"]},
+    "tags": {"items": {"type": "string"}, "type": "array"},
+    "to": {"$ref": "#/$defs/XY"}
+  },
+  "required": ["at", "from", "id", "note", "tags", "to"],
+  "title": "Document",
+  "type": "object"
+}`
+	assert.JSONEq(t, expected, string(schema))
+
+	// Schema describes the same types.
+	fromSchema := NewJSONParser(baseTypeName, OptExtractCommonTypes(true))
+	require.NoError(t, fromSchema.FeedSchema(SchemaTypeJSON, schema))
+	assert.Equal(t, parser.String(), fromSchema.String())
+}
+
+func TestParserFeedSchema(t *testing.T) {
+	testCases := []struct {
+		name       string
+		schemaType string
+		schema     string
+		expected   string
+	}{
+		{
+			name:       "json schema",
+			schemaType: SchemaTypeJSON,
+			schema: `{
+				"type": "object",
+				"required": ["id", "user"],
+				"properties": {
+					"id": {"type": "integer"},
+					"score": {"type": "number"},
+					"user": {"$ref": "#/definitions/user"},
+					"status": {"enum": ["active", "deleted"]},
+					"parent": {"anyOf": [{"$ref": "#"}, {"type": "null"}]}
+				},
+				"definitions": {
+					"user": {"type": "object", "required": ["name"], "properties": {"name": {"type": "string"}, "tags": {"type": "array", "items": {"type": "string"}}}}
+				}
+			}`,
+			expected: `type Document struct {
+	ID     int         ` + "`json:\"id\"`" + `
+	Parent interface{} ` + "`json:\"parent,omitempty\"`" + `
+	Score  *float64    ` + "`json:\"score,omitempty\"`" + `
+	Status string      ` + "`json:\"status,omitempty\"`" + `
+	User   struct {
+		Name string   ` + "`json:\"name\"`" + `
+		Tags []string ` + "`json:\"tags,omitempty\"`" + `
+	} ` + "`json:\"user\"`" + `
+}`,
+		},
+		{
+			name:       "avro",
+			schemaType: SchemaTypeAvro,
+			schema: `{
+				"type": "record",
+				"name": "Event",
+				"namespace": "com.example",
+				"fields": [
+					{"name": "id", "type": "long"},
+					{"name": "kind", "type": {"type": "enum", "name": "Kind", "symbols": ["A", "B"]}},
+					{"name": "note", "type": ["null", "string"]},
+					{"name": "labels", "type": {"type": "map", "values": "string"}},
+					{"name": "items", "type": {"type": "array", "items": {"type": "record", "name": "Item", "fields": [{"name": "price", "type": "double"}]}}},
+					{"name": "first", "type": ["null", "com.example.Item"]}
+				]
+			}`,
+			expected: `type Document struct {
+	First *struct {
+		Price float64 ` + "`json:\"price\"`" + `
+	} ` + "`json:\"first\"`" + `
+	ID    int ` + "`json:\"id\"`" + `
+	Items []struct {
+		Price float64 ` + "`json:\"price\"`" + `
+	} ` + "`json:\"items\"`" + `
+	Kind   string            ` + "`json:\"kind\"`" + `
+	Labels map[string]string ` + "`json:\"labels\"`" + `
+	Note   *string           ` + "`json:\"note\"`" + `
+}`,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			parser := NewJSONParser(baseTypeName, OptMakeMaps(true, 3))
+			require.NoError(t, parser.FeedSchema(tc.schemaType, []byte(tc.schema)))
+			assert.Equal(t, tc.expected, parser.String())
+		})
+	}
+}
+
+func TestParserFeedSchemaErrors(t *testing.T) {
+	parser := NewJSONParser(baseTypeName)
+	assert.True(t, errors.Is(parser.FeedSchema(SchemaTypeJSON, []byte(`{`)), ErrInvalidJSON))
+	assert.True(t, errors.Is(parser.FeedSchema("PROTOBUF", []byte(`{}`)), ErrUnsupportedShape))
+
+	// Recursive schemas are consumed up to limited depth.
+	assert.NoError(t, parser.FeedSchema(SchemaTypeJSON, []byte(`{"type":"object","properties":{"child":{"$ref":"#"}}}`)))
+}
@@ -0,0 +1,251 @@
+package json2go
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Service converts json samples to go types, for use by remote clients.
+// Its methods follow Converter gRPC service defined in proto/json2go.proto. The module doesn't depend on gRPC,
+// the server is json2go-grpc command, a separate module in cmd/json2go-grpc. Other servers generated from
+// the definition delegate to Service, converting messages, e.g.:
+//
+//	func (s *server) Convert(ctx context.Context, req *json2gov1.ConvertRequest) (*json2gov1.ConvertResponse, error) {
+//		var config json2go.Config
+//		if err := json.Unmarshal(req.GetConfig().GetJson(), &config); err != nil {
+//			return nil, status.Error(codes.InvalidArgument, err.Error())
+//		}
+//		resp, err := s.service.Convert(ctx, &json2go.ConvertRequest{Config: config, Profile: req.Profile, Samples: req.Samples})
+//		if err != nil {
+//			return nil, status.Error(codes.InvalidArgument, err.Error())
+//		}
+//		return &json2gov1.ConvertResponse{Code: resp.Code, Imports: resp.Imports, Warnings: resp.Warnings}, nil
+//	}
+//
+// For a server without generated code, see -rpc option of json2go command serving JSON-RPC.
+type Service struct {
+	// Opts are applied after options from request config, like limits enforced by server, e.g. OptMaxDepth
+	// or OptMaxNodes.
+	Opts []JSONParserOpt
+	// Profiles are named configs selected by requests, like conventions of teams sharing server, see ReadProfiles.
+	// Config of request selecting profile is replaced by profile config, only its root name is used.
+	Profiles map[string]Config
+	// MaxInputSize limits size in bytes of samples of request, or of uploaded stream. 0 means no limit.
+	MaxInputSize int64
+	// RateLimiter limits rate of requests of each client, identified with ContextWithClient.
+	// Requests without client share limit. Nil means no limit.
+	RateLimiter *RateLimiter
+}
+
+// ConvertRequest is a request for go types of json samples.
+type ConvertRequest struct {
+	Config  Config
+	Profile string
+	Samples [][]byte
+}
+
+// ConvertResponse contains generated go types.
+type ConvertResponse struct {
+	Code     string
+	Imports  []string
+	Warnings []string
+}
+
+// ConvertChunk is a part of uploaded stream of json documents. Config and profile are read from the first chunk.
+type ConvertChunk struct {
+	Config  *Config
+	Profile string
+	Data    []byte
+}
+
+// ChunkReceiver receives chunks of uploaded stream. It returns io.EOF after last chunk.
+type ChunkReceiver interface {
+	Recv() (*ConvertChunk, error)
+}
+
+// DiffRequest is a request for difference between go types of base samples and samples.
+type DiffRequest struct {
+	Config      Config
+	Profile     string
+	BaseSamples [][]byte
+	Samples     [][]byte
+}
+
+// DiffResponse contains go types of both sample sets, and their line diff.
+// Diff is empty if types are the same.
+type DiffResponse struct {
+	BaseCode string
+	Code     string
+	Diff     string
+}
+
+// Convert returns go types of json samples. ErrInvalidJSON is returned for invalid samples.
+func (s *Service) Convert(ctx context.Context, req *ConvertRequest) (*ConvertResponse, error) {
+	if err := s.admit(ctx, samplesSize(req.Samples)); err != nil {
+		return nil, err
+	}
+	p, err := s.parse(ctx, req.Config, req.Profile, req.Samples)
+	if err != nil {
+		return nil, err
+	}
+	return s.response(ctx, p)
+}
+
+// ConvertStream returns go types of stream of json documents, received in chunks.
+// Documents may be split between chunks.
+func (s *Service) ConvertStream(ctx context.Context, stream ChunkReceiver) (*ConvertResponse, error) {
+	if err := s.admit(ctx, 0); err != nil {
+		return nil, err
+	}
+	first, err := stream.Recv()
+	if err == io.EOF {
+		first = &ConvertChunk{}
+	} else if err != nil {
+		return nil, err
+	}
+	var config Config
+	if first.Config != nil {
+		config = *first.Config
+	}
+	p, err := s.newParser(config, first.Profile)
+	if err != nil {
+		return nil, err
+	}
+
+	r, w := io.Pipe()
+	go func() {
+		chunk := first
+		for {
+			if len(chunk.Data) > 0 {
+				if _, err := w.Write(chunk.Data); err != nil {
+					return
+				}
+			}
+			var err error
+			if chunk, err = stream.Recv(); err != nil {
+				if err == io.EOF {
+					err = nil
+				}
+				w.CloseWithError(err)
+				return
+			}
+		}
+	}()
+
+	var input io.Reader = r
+	if s.MaxInputSize > 0 {
+		input = &sizeLimitReader{r: r, limit: s.MaxInputSize}
+	}
+	err = p.FeedReaderContext(ctx, input)
+	r.Close()
+	if err != nil {
+		return nil, err
+	}
+	return s.response(ctx, p)
+}
+
+// Diff returns go types of base samples and samples, and their line diff.
+func (s *Service) Diff(ctx context.Context, req *DiffRequest) (*DiffResponse, error) {
+	if err := s.admit(ctx, samplesSize(req.BaseSamples)+samplesSize(req.Samples)); err != nil {
+		return nil, err
+	}
+	base, err := s.parse(ctx, req.Config, req.Profile, req.BaseSamples)
+	if err != nil {
+		return nil, fmt.Errorf("base samples: %w", err)
+	}
+	baseCode, err := base.GenerateContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := s.parse(ctx, req.Config, req.Profile, req.Samples)
+	if err != nil {
+		return nil, err
+	}
+	code, err := p.GenerateContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DiffResponse{
+		BaseCode: baseCode,
+		Code:     code,
+		Diff:     DiffLines(baseCode, code),
+	}, nil
+}
+
+func (s *Service) parse(ctx context.Context, config Config, profile string, samples [][]byte) (*JSONParser, error) {
+	p, err := s.newParser(config, profile)
+	if err != nil {
+		return nil, err
+	}
+	for i, sample := range samples {
+		if err := p.feedBytes(ctx, sample); err != nil {
+			return nil, fmt.Errorf("sample %d: %w", i, err)
+		}
+	}
+	return p, nil
+}
+
+func (s *Service) response(ctx context.Context, p *JSONParser) (*ConvertResponse, error) {
+	code, err := p.GenerateContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &ConvertResponse{
+		Code:     code,
+		Imports:  p.Imports(),
+		Warnings: p.Warnings(),
+	}, nil
+}
+
+// newParser returns parser with options of request config, or of selected profile, followed by service options.
+// Options of request config reading files of server, like go.mod of GoModule or package sources of TypeCheck,
+// are cleared. Profiles are configs of server, they can set them.
+func (s *Service) newParser(config Config, profile string) (*JSONParser, error) {
+	config.GoModule = ""
+	config.TypeCheck = false
+	if profile != "" {
+		c, ok := s.Profiles[profile]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownProfile, profile)
+		}
+		if config.RootName != "" {
+			c.RootName = config.RootName
+		}
+		config = c
+	}
+	return config.NewParser(s.Opts...), nil
+}
+
+// ProfileNames returns sorted names of profiles.
+func (s *Service) ProfileNames() []string {
+	names := make([]string, 0, len(s.Profiles))
+	for name := range s.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// admit checks if request of client with input of size bytes is within rate and size limits.
+func (s *Service) admit(ctx context.Context, size int64) error {
+	if s.RateLimiter != nil && !s.RateLimiter.Allow(clientFromContext(ctx)) {
+		return ErrRateLimited
+	}
+	if s.MaxInputSize > 0 && size > s.MaxInputSize {
+		return fmt.Errorf("%w: %d bytes, limit is %d bytes", ErrInputTooLarge, size, s.MaxInputSize)
+	}
+	return nil
+}
+
+// samplesSize returns total size of samples in bytes.
+func samplesSize(samples [][]byte) int64 {
+	var size int64
+	for _, sample := range samples {
+		size += int64(len(sample))
+	}
+	return size
+}
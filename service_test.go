@@ -0,0 +1,142 @@
+package json2go
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chunks is a ChunkReceiver returning chunks from slice.
+type chunks []*ConvertChunk
+
+func (c *chunks) Recv() (*ConvertChunk, error) {
+	if len(*c) == 0 {
+		return nil, io.EOF
+	}
+	chunk := (*c)[0]
+	*c = (*c)[1:]
+	return chunk, nil
+}
+
+func TestServiceConvert(t *testing.T) {
+	t.Parallel()
+
+	s := &Service{}
+	resp, err := s.Convert(context.Background(), &ConvertRequest{
+		Config:  Config{RootName: "User", SkipEmptyKeys: true},
+		Samples: [][]byte{[]byte(`{"id":1,"at":"2020-01-01T00:00:00Z"}`), []byte(`{"id":2,"x":null}`)},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `type User struct {
+	At *time.Time `+"`json:\"at,omitempty\"`"+`
+	ID int        `+"`json:\"id\"`"+`
+}`, resp.Code)
+	assert.Equal(t, []string{"time"}, resp.Imports)
+
+	_, err = s.Convert(context.Background(), &ConvertRequest{Samples: [][]byte{[]byte(`{`)}})
+	assert.True(t, errors.Is(err, ErrInvalidJSON), "unexpected error: %v", err)
+
+	// Requests can't make server read its files.
+	resp, err = s.Convert(context.Background(), &ConvertRequest{
+		Config:  Config{GoModule: "/nonexistent", TypeCheck: true},
+		Samples: [][]byte{[]byte(`{"id":1}`)},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "type Document struct {\n\tID int `json:\"id\"`\n}", resp.Code)
+}
+
+func TestServiceConvertStream(t *testing.T) {
+	t.Parallel()
+
+	s := &Service{Opts: []JSONParserOpt{OptMaxDepth(2)}}
+	stream := &chunks{
+		{Config: &Config{RootName: "Event"}, Data: []byte(`{"id":1,"na`)},
+		{Data: []byte(`me":"x"} {"id"`)},
+		{Data: []byte(`:2}`)},
+	}
+	resp, err := s.ConvertStream(context.Background(), stream)
+	require.NoError(t, err)
+	assert.Equal(t, `type Event struct {
+	ID   int    `+"`json:\"id\"`"+`
+	Name string `+"`json:\"name,omitempty\"`"+`
+}`, resp.Code)
+
+	stream = &chunks{{Data: []byte(`{"a":{"b":{"c":1}}}`)}}
+	_, err = s.ConvertStream(context.Background(), stream)
+	assert.True(t, errors.Is(err, ErrDepthExceeded), "server options are applied: %v", err)
+}
+
+func TestServiceDiff(t *testing.T) {
+	t.Parallel()
+
+	s := &Service{}
+	resp, err := s.Diff(context.Background(), &DiffRequest{
+		BaseSamples: [][]byte{[]byte(`{"id":1,"name":"x"}`)},
+		Samples:     [][]byte{[]byte(`{"id":1.5,"name":"x"}`)},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, ` type Document struct {
+-	ID   int    `+"`json:\"id\"`"+`
+-	Name string `+"`json:\"name\"`"+`
++	ID   float64 `+"`json:\"id\"`"+`
++	Name string  `+"`json:\"name\"`"+`
+ }
+`, resp.Diff)
+
+	resp, err = s.Diff(context.Background(), &DiffRequest{
+		BaseSamples: [][]byte{[]byte(`{"id":1}`)},
+		Samples:     [][]byte{[]byte(`{"id":2}`)},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Diff)
+}
+
+func TestServiceProfiles(t *testing.T) {
+	t.Parallel()
+
+	profiles, err := ReadProfiles(strings.NewReader(`
+frontend:
+  rootName: Response
+  timeAsString: true
+backend-strict:
+  strictness: interfaces
+`))
+	require.NoError(t, err)
+	s := &Service{Profiles: profiles}
+	assert.Equal(t, []string{"backend-strict", "frontend"}, s.ProfileNames())
+
+	samples := [][]byte{[]byte(`{"id":1,"at":"2020-01-01T00:00:00Z","tags":[]}`)}
+	resp, err := s.Convert(context.Background(), &ConvertRequest{Profile: "frontend", Samples: samples})
+	require.NoError(t, err)
+	assert.Equal(t, `type Response struct {
+	At   string        `+"`json:\"at\"`"+`
+	ID   int           `+"`json:\"id\"`"+`
+	Tags []interface{} `+"`json:\"tags\"`"+`
+}`, resp.Code)
+
+	resp, err = s.Convert(context.Background(), &ConvertRequest{
+		Config:  Config{RootName: "User"},
+		Profile: "frontend",
+		Samples: [][]byte{[]byte(`{"at":"2020-01-01T00:00:00Z"}`)},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "type User struct {\n\tAt string `json:\"at\"`\n}", resp.Code, "root name of request is used with profile")
+
+	_, err = s.Convert(context.Background(), &ConvertRequest{Profile: "backend-strict", Samples: samples})
+	assert.True(t, errors.Is(err, ErrStrict), "%v", err)
+
+	stream := &chunks{{Profile: "backend-strict", Data: samples[0]}}
+	_, err = s.ConvertStream(context.Background(), stream)
+	assert.True(t, errors.Is(err, ErrStrict), "%v", err)
+
+	_, err = s.Diff(context.Background(), &DiffRequest{Profile: "backend", BaseSamples: samples, Samples: samples})
+	assert.True(t, errors.Is(err, ErrUnknownProfile), "%v", err)
+
+	_, err = ReadProfiles(strings.NewReader("frontend:\n  unknownOption: true\n"))
+	assert.Error(t, err)
+}
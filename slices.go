@@ -0,0 +1,158 @@
+package json2go
+
+import (
+	"fmt"
+	"go/ast"
+)
+
+// SliceElements is a policy of declaring struct elements of slices as values or pointers, like `[]Item` or `[]*Item`.
+type SliceElements int
+
+const (
+	// SliceElementsInferred uses pointers when null elements were found in arrays.
+	SliceElementsInferred SliceElements = iota
+	// SliceElementsValues always uses values. Null elements are decoded as zero values.
+	// Values are stored next to each other, which is easier on cache and garbage collector for large arrays.
+	SliceElementsValues
+	// SliceElementsPointers always uses pointers, so elements can be shared or modified in place.
+	SliceElementsPointers
+)
+
+// ParseSliceElements returns slice elements policy by name: "inferred", "values" or "pointers".
+// Empty name means inferred.
+func ParseSliceElements(name string) (SliceElements, error) {
+	switch name {
+	case "", "inferred":
+		return SliceElementsInferred, nil
+	case "values":
+		return SliceElementsValues, nil
+	case "pointers":
+		return SliceElementsPointers, nil
+	}
+	return SliceElementsInferred, fmt.Errorf("unknown slice elements policy: %s", name)
+}
+
+// NullElements is a representation of elements of arrays, in which nulls were found.
+type NullElements int
+
+const (
+	// NullElementsPointers uses pointer elements, like `[]*float64`.
+	NullElementsPointers NullElements = iota
+	// NullElementsSkip uses generic slice type, which skips null elements when unmarshaled, like `SkipNulls[float64]`.
+	NullElementsSkip
+	// NullElementsWrapper uses elements of generic type reporting null values, like `[]Nullable[float64]`.
+	NullElementsWrapper
+)
+
+// ParseNullElements returns null elements representation by name: "pointers", "skip" or "wrapper".
+// Empty name means pointers.
+func ParseNullElements(name string) (NullElements, error) {
+	switch name {
+	case "", "pointers":
+		return NullElementsPointers, nil
+	case "skip":
+		return NullElementsSkip, nil
+	case "wrapper":
+		return NullElementsWrapper, nil
+	}
+	return NullElementsPointers, fmt.Errorf("unknown null elements representation: %s", name)
+}
+
+// astElementShouldBeAPointer checks if elements of slices of node should be pointers.
+// Policy applies to struct elements, other elements are pointers if null elements were found
+// and they are represented by pointers.
+func astElementShouldBeAPointer(n *node, policy SliceElements, nulls NullElements) bool {
+	withNulls := n.arrayWithNulls && nulls == NullElementsPointers
+	switch n.t.id() {
+	case nodeTypeObject.id(), nodeTypeExtracted.id():
+	default:
+		return withNulls
+	}
+
+	switch policy {
+	case SliceElementsValues:
+		return false
+	case SliceElementsPointers:
+		return true
+	}
+	return withNulls
+}
+
+// astSkipNullsType returns generic slice type with given element type, skipping null elements when unmarshaled.
+func astSkipNullsType(elemType ast.Expr, ctx *astContext) ast.Expr {
+	ctx.addImport("encoding/json")
+	name := ctx.addSharedHelper("SkipNulls", func(name string) string {
+		return fmt.Sprintf(`
+// %[1]s is a slice unmarshaled from json array with null elements skipped.
+type %[1]s[T any] []T
+
+// UnmarshalJSON unmarshals json array, skipping null elements.
+func (s *%[1]s[T]) UnmarshalJSON(data []byte) error {
+	var elems []json.RawMessage
+	if err := json.Unmarshal(data, &elems); err != nil {
+		return err
+	}
+	if elems == nil {
+		*s = nil
+		return nil
+	}
+
+	*s = make(%[1]s[T], 0, len(elems))
+	for _, elem := range elems {
+		if string(elem) == "null" {
+			continue
+		}
+		var v T
+		if err := json.Unmarshal(elem, &v); err != nil {
+			return err
+		}
+		*s = append(*s, v)
+	}
+	return nil
+}
+`, name)
+	})
+
+	return &ast.IndexExpr{
+		X:     ast.NewIdent(name),
+		Index: elemType,
+	}
+}
+
+// astNullableType returns generic type of array elements that may be null.
+func astNullableType(elemType ast.Expr, ctx *astContext) ast.Expr {
+	ctx.addImport("encoding/json")
+	name := ctx.addSharedHelper("Nullable", func(name string) string {
+		return fmt.Sprintf(`
+// %[1]s is a value that may be null. Valid is false for null values.
+type %[1]s[T any] struct {
+	Valid bool
+	Value T
+}
+
+// UnmarshalJSON unmarshals value, or marks it as invalid if it's null.
+func (n *%[1]s[T]) UnmarshalJSON(data []byte) error {
+	var zero T
+	n.Value = zero
+	n.Valid = string(data) != "null"
+	if !n.Valid {
+		return nil
+	}
+	return json.Unmarshal(data, &n.Value)
+}
+
+// MarshalJSON marshals value, or null if it's invalid.
+func (n %[1]s[T]) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(n.Value)
+}
+`, name)
+	})
+
+	return &ast.IndexExpr{
+		X:     ast.NewIdent(name),
+		Index: elemType,
+	}
+}
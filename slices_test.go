@@ -0,0 +1,152 @@
+package json2go
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserSliceElements(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		input    string
+		policy   SliceElements
+		expected string
+	}{
+		{
+			name:     "inferred without nulls",
+			input:    `{"items":[{"a":1}],"ids":[1]}`,
+			policy:   SliceElementsInferred,
+			expected: "type Document struct {\n\tIds   []int `json:\"ids\"`\n\tItems []struct {\n\t\tA int `json:\"a\"`\n\t} `json:\"items\"`\n}",
+		},
+		{
+			name:     "inferred with nulls",
+			input:    `{"items":[{"a":1},null,{"a":2}],"ids":[1,null]}`,
+			policy:   SliceElementsInferred,
+			expected: "type Document struct {\n\tIds   []*int `json:\"ids\"`\n\tItems []*struct {\n\t\tA int `json:\"a\"`\n\t} `json:\"items\"`\n}",
+		},
+		{
+			name:     "values with nulls",
+			input:    `{"items":[{"a":1},null,{"a":2}],"ids":[1,null]}`,
+			policy:   SliceElementsValues,
+			expected: "type Document struct {\n\tIds   []*int `json:\"ids\"`\n\tItems []struct {\n\t\tA int `json:\"a\"`\n\t} `json:\"items\"`\n}",
+		},
+		{
+			name:     "pointers without nulls",
+			input:    `{"items":[{"a":1}],"ids":[1]}`,
+			policy:   SliceElementsPointers,
+			expected: "type Document struct {\n\tIds   []int `json:\"ids\"`\n\tItems []*struct {\n\t\tA int `json:\"a\"`\n\t} `json:\"items\"`\n}",
+		},
+		{
+			name:     "pointers to extracted type",
+			input:    `{"a":[{"x":1}],"b":[{"x":2}]}`,
+			policy:   SliceElementsPointers,
+			expected: "type Document struct {\n\tA []*X `json:\"a\"`\n\tB []*X `json:\"b\"`\n}\ntype X struct {\n\tX int `json:\"x\"`\n}",
+		},
+		{
+			name:     "pointers in root slice",
+			input:    `[{"a":1}]`,
+			policy:   SliceElementsPointers,
+			expected: "type Document []*struct {\n\tA int `json:\"a\"`\n}",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := NewJSONParser("Document", OptExtractCommonTypes(true), OptSliceElements(tc.policy))
+			require.NoError(t, p.FeedBytes([]byte(tc.input)))
+			assert.Equal(t, tc.expected, p.String())
+		})
+	}
+}
+
+func TestParserNullElements(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		input    string
+		repr     NullElements
+		expected string
+	}{
+		{
+			name:     "pointers",
+			input:    `{"values":[1.5,null]}`,
+			repr:     NullElementsPointers,
+			expected: "type Document struct {\n\tValues []*float64 `json:\"values\"`\n}",
+		},
+		{
+			name:     "skip",
+			input:    `{"values":[1.5,null],"ids":[1]}`,
+			repr:     NullElementsSkip,
+			expected: "type Document struct {\n\tIds    []int              `json:\"ids\"`\n\tValues SkipNulls[float64] `json:\"values\"`\n}",
+		},
+		{
+			name:     "skip in nested arrays",
+			input:    `{"values":[[1.5,null]]}`,
+			repr:     NullElementsSkip,
+			expected: "type Document struct {\n\tValues []SkipNulls[float64] `json:\"values\"`\n}",
+		},
+		{
+			name:     "skip in root array",
+			input:    `[1.5,null]`,
+			repr:     NullElementsSkip,
+			expected: "type Document = SkipNulls[float64]",
+		},
+		{
+			name:     "wrapper",
+			input:    `{"values":[1.5,null]}`,
+			repr:     NullElementsWrapper,
+			expected: "type Document struct {\n\tValues []Nullable[float64] `json:\"values\"`\n}",
+		},
+		{
+			name:     "wrapper of struct",
+			input:    `{"items":[{"a":1},null]}`,
+			repr:     NullElementsWrapper,
+			expected: "type Document struct {\n\tItems []Nullable[struct {\n\t\tA int `json:\"a\"`\n\t}] `json:\"items\"`\n}",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := NewJSONParser("Document", OptNullElements(tc.repr))
+			require.NoError(t, p.FeedBytes([]byte(tc.input)))
+			// Only root type declaration is checked, helpers follow it.
+			assert.Equal(t, tc.expected, astPrintDecls(p.ASTDeclsWithOpt()[:1]))
+		})
+	}
+}
+
+func TestParserNullElementsCode(t *testing.T) {
+	t.Parallel()
+
+	input := `{"values":[1.5,null,2],"items":[null,{"a":1}]}`
+	mainBody := `
+	var doc Document
+	if err := json.NewDecoder(os.Stdin).Decode(&doc); err != nil {
+		panic(err)
+	}
+	out, err := json.Marshal(doc)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Print(string(out))
+`
+
+	p := NewJSONParser("Document", OptNullElements(NullElementsSkip))
+	require.NoError(t, p.FeedBytes([]byte(input)))
+	assert.Equal(t, `{"items":[{"a":1}],"values":[1.5,2]}`, runGeneratedCode(t, p, mainBody, input))
+
+	p = NewJSONParser("Document", OptNullElements(NullElementsWrapper))
+	require.NoError(t, p.FeedBytes([]byte(input)))
+	assert.Equal(t, `{"items":[null,{"a":1}],"values":[1.5,null,2]}`, runGeneratedCode(t, p, mainBody, input))
+}
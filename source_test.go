@@ -0,0 +1,126 @@
+package json2go
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sourceTestExpected = `type Document struct {
+	ID   int    ` + "`json:\"id\"`" + `
+	Name string ` + "`json:\"name,omitempty\"`" + `
+}`
+
+func TestFeedSourceKafkaREST(t *testing.T) {
+	t.Parallel()
+
+	var polls int
+	var deleted bool
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/consumers/json2go":
+			_ = json.NewEncoder(w).Encode(map[string]string{"instance_id": "c1", "base_uri": server.URL + "/consumers/json2go/instances/c1"})
+		case r.Method == http.MethodPost && r.URL.Path == "/consumers/json2go/instances/c1/subscription":
+			var req struct {
+				Topics []string `json:"topics"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			assert.Equal(t, []string{"events"}, req.Topics)
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodGet && r.URL.Path == "/consumers/json2go/instances/c1/records":
+			assert.Equal(t, kafkaRESTJSONContentType, r.Header.Get("Accept"))
+			polls++
+			if polls != 2 {
+				// No records before consumer joins group, and after all were read.
+				_, _ = w.Write([]byte(`[]`))
+				return
+			}
+			_, _ = w.Write([]byte(`[{"topic":"events","key":null,"value":{"id":1,"name":"a"},"partition":0,"offset":0},
+				{"topic":"events","key":null,"value":{"id":2},"partition":0,"offset":1},
+				{"topic":"events","key":null,"value":{"id":3,"name":"c"},"partition":0,"offset":2}]`))
+		case r.Method == http.MethodDelete && r.URL.Path == "/consumers/json2go/instances/c1":
+			deleted = true
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	src := NewKafkaRESTSource(server.URL, "events")
+	src.PollInterval = time.Millisecond
+
+	parser := NewJSONParser(baseTypeName)
+	n, err := parser.FeedSource(context.Background(), src, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	require.NoError(t, src.Close())
+	assert.True(t, deleted)
+	assert.Equal(t, sourceTestExpected, parser.String())
+
+	// Topic is read until context is canceled.
+	src = NewKafkaRESTSource(server.URL, "events")
+	src.PollInterval = time.Millisecond
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	polls = 0
+	n, err = NewJSONParser(baseTypeName).FeedSource(ctx, src, 0)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Equal(t, 3, n)
+}
+
+func TestFeedSourceRabbitMQ(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.EscapedPath() != "/api/queues/%2F/events/get" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		user, password, ok := r.BasicAuth()
+		if !ok || user != "guest" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"not_authorised","reason":"Login failed"}`))
+			return
+		}
+
+		var req struct {
+			Count   int    `json:"count"`
+			AckMode string `json:"ackmode"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		assert.Equal(t, 10, req.Count)
+		assert.Equal(t, "ack_requeue_true", req.AckMode)
+
+		_, _ = w.Write([]byte(`[
+			{"payload_bytes":10,"redelivered":false,"exchange":"","routing_key":"events","payload":"{\"id\":1,\"name\":\"a\"}","payload_encoding":"string"},
+			{"payload_bytes":8,"redelivered":false,"exchange":"","routing_key":"events","payload":"eyJpZCI6Mn0=","payload_encoding":"base64"},
+			{"payload_bytes":3,"redelivered":false,"exchange":"","routing_key":"events","payload":"n/a","payload_encoding":"string"}
+		]`))
+	}))
+	defer server.Close()
+
+	authURL := strings.Replace(server.URL, "http://", "http://guest:secret@", 1)
+
+	var progress []Progress
+	parser := NewJSONParser(baseTypeName, OptProgress(func(p Progress) {
+		progress = append(progress, p)
+	}))
+	n, err := parser.FeedSource(context.Background(), NewRabbitMQSource(authURL, "events", 10), 0)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Len(t, progress, 3)
+	assert.Len(t, parser.Warnings(), 1, "invalid message is skipped")
+	assert.Equal(t, sourceTestExpected, parser.String())
+
+	_, err = NewJSONParser(baseTypeName).FeedSource(context.Background(), NewRabbitMQSource(server.URL, "events", 10), 0)
+	assert.EqualError(t, err, "rabbitmq management api: 401 Login failed")
+}
@@ -0,0 +1,94 @@
+package json2go
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SparkEmitter emits Spark SQL DDL of StructType from IR, like "`id` BIGINT NOT NULL, `tags` ARRAY<STRING>",
+// accepted by StructType.fromDDL and by schema options of Spark readers.
+//
+// Fields of pointers, optional values or with omitempty are nullable, other fields are NOT NULL. Declared types
+// are inlined. Any values, opaque values and recursive values are STRING, like raw json.
+type SparkEmitter struct{}
+
+// Emit returns Spark DDL of columns of root struct type.
+func (SparkEmitter) Emit(ir *Schema) ([]byte, error) {
+	if err := validateIR(ir); err != nil {
+		return nil, err
+	}
+	root := irElemType(ir, ir.Types[ir.Root])
+	if root.Kind == SchemaSlice {
+		// Array documents are read as rows.
+		root = irElemType(ir, root.Elem)
+	}
+	if root.Kind != SchemaStruct {
+		return nil, fmt.Errorf("%w: Spark schema needs struct root type, not %s", ErrUnsupportedShape, root.Kind)
+	}
+	return []byte(strings.Join(sparkFields(ir, root, " ", map[string]bool{ir.Root: true}), ", ")), nil
+}
+
+// sparkFields returns DDL of fields of struct type, with names separated from types with separator.
+func sparkFields(ir *Schema, t *SchemaType, separator string, expanding map[string]bool) []string {
+	var fields []string
+	for _, f := range t.Fields {
+		field := sparkName(f.Key) + separator + sparkType(ir, f.Type, expanding)
+		if !f.OmitEmpty && !f.OmitZero && f.Type.Kind != SchemaPointer && f.Type.Kind != SchemaOptional {
+			field += " NOT NULL"
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// sparkType returns Spark SQL type of type.
+func sparkType(ir *Schema, t *SchemaType, expanding map[string]bool) string {
+	switch t.Kind {
+	case SchemaBool:
+		return "BOOLEAN"
+	case SchemaInt:
+		return "BIGINT"
+	case SchemaFloat:
+		if t.Bits == 32 {
+			return "FLOAT"
+		}
+		return "DOUBLE"
+	case SchemaTime:
+		return "TIMESTAMP"
+	case SchemaPointer, SchemaOptional:
+		return sparkType(ir, t.Elem, expanding)
+	case SchemaSlice:
+		return "ARRAY<" + sparkType(ir, t.Elem, expanding) + ">"
+	case SchemaMap:
+		key := "STRING"
+		if t.Key != nil && t.Key.Kind == SchemaInt {
+			key = "BIGINT"
+		}
+		return "MAP<" + key + ", " + sparkType(ir, t.Elem, expanding) + ">"
+	case SchemaStruct:
+		return "STRUCT<" + strings.Join(sparkFields(ir, t, ": ", expanding), ", ") + ">"
+	case SchemaNamed:
+		if expanding[t.Name] {
+			break
+		}
+		expanding[t.Name] = true
+		defer delete(expanding, t.Name)
+		return sparkType(ir, ir.Types[t.Name], expanding)
+	}
+	// Strings, any values, json.RawMessage and types with own json methods.
+	return "STRING"
+}
+
+// sparkName returns field name quoted with backticks, if it isn't an identifier.
+func sparkName(key string) string {
+	if key != "" && bigQueryName(key) == key {
+		return key
+	}
+	return "`" + strings.Replace(key, "`", "``", -1) + "`"
+}
+
+// SparkSchema returns Spark SQL DDL of StructType describing parsed documents, see SparkEmitter.
+func (p *JSONParser) SparkSchema() (string, error) {
+	out, err := SparkEmitter{}.Emit(p.Schema())
+	return string(out), err
+}
@@ -0,0 +1,78 @@
+package json2go
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSparkSchema(t *testing.T) {
+	t.Parallel()
+
+	p := NewJSONParser(baseTypeName)
+	require.NoError(t, p.FeedBytes([]byte(`{"id": 1, "user-name": "a", "tags": ["x"], "addr": {"zip": "1"},
+		"at": "2021-01-01T00:00:00Z", "matrix": [[1]], "extra": {"a": 1}}`)))
+	require.NoError(t, p.FeedBytes([]byte(`{"id": 2, "user-name": "b", "tags": [], "addr": {"zip": "2"},
+		"at": "2021-01-02T00:00:00Z", "matrix": [[2]], "score": 1.5, "extra": "x"}`)))
+
+	ddl, err := p.SparkSchema()
+	require.NoError(t, err)
+	assert.Equal(t, "addr STRUCT<zip: STRING NOT NULL> NOT NULL, at TIMESTAMP NOT NULL, extra STRING NOT NULL, "+
+		"id BIGINT NOT NULL, matrix ARRAY<ARRAY<BIGINT>> NOT NULL, score DOUBLE, tags ARRAY<STRING> NOT NULL, "+
+		"`user-name` STRING NOT NULL", ddl)
+}
+
+func TestSparkEmitter(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		ir       *Schema
+		expected string
+		err      error
+	}{
+		{
+			name: "recursive type",
+			ir: &Schema{Root: "Node", Types: map[string]*SchemaType{"Node": {Kind: SchemaStruct, Fields: []SchemaField{
+				{Name: "Children", Key: "children", Type: &SchemaType{Kind: SchemaSlice, Elem: &SchemaType{Kind: SchemaNamed, Name: "Node"}}},
+			}}}},
+			expected: "children ARRAY<STRING> NOT NULL",
+		},
+		{
+			name: "maps and quoted names",
+			ir: &Schema{Root: "Document", Types: map[string]*SchemaType{"Document": {Kind: SchemaStruct, Fields: []SchemaField{
+				{Name: "Counts", Key: "counts", Type: &SchemaType{Kind: SchemaMap, Key: &SchemaType{Kind: SchemaInt},
+					Elem: &SchemaType{Kind: SchemaFloat, Bits: 32}}, OmitEmpty: true},
+				{Name: "A", Key: "a`b", Type: &SchemaType{Kind: SchemaPointer, Elem: &SchemaType{Kind: SchemaBool}}},
+			}}}},
+			expected: "counts MAP<BIGINT, FLOAT>, `a``b` BOOLEAN",
+		},
+		{
+			name:     "array root",
+			ir:       &Schema{Root: "Document", Types: map[string]*SchemaType{"Document": {Kind: SchemaSlice, Elem: &SchemaType{Kind: SchemaStruct, Fields: []SchemaField{{Name: "ID", Key: "id", Type: &SchemaType{Kind: SchemaInt}}}}}}},
+			expected: "id BIGINT NOT NULL",
+		},
+		{
+			name: "root isn't struct",
+			ir:   &Schema{Root: "Document", Types: map[string]*SchemaType{"Document": {Kind: SchemaString}}},
+			err:  ErrUnsupportedShape,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			out, err := SparkEmitter{}.Emit(tc.ir)
+			if tc.err != nil {
+				assert.True(t, errors.Is(err, tc.err), "unexpected error: %v", err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(out))
+		})
+	}
+}
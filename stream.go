@@ -0,0 +1,151 @@
+package json2go
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+)
+
+// Progress describes progress of consuming json input stream.
+type Progress struct {
+	// BytesRead is a number of bytes read from input.
+	BytesRead int64
+	// Documents is a number of json documents consumed.
+	Documents int
+}
+
+// ConvertContext consumes stream of json documents from reader and returns go representation of their type.
+// Reading and generation stop when context is canceled, its error is returned then. Other errors of generation
+// are ignored, like by String.
+func ConvertContext(ctx context.Context, r io.Reader, rootTypeName string, opts ...JSONParserOpt) (string, error) {
+	p := NewJSONParser(rootTypeName, opts...)
+	if err := p.FeedReaderContext(ctx, r); err != nil {
+		return "", err
+	}
+
+	out, err := p.GenerateContext(ctx)
+	if err != nil && ctx.Err() != nil {
+		return "", err
+	}
+	return out, nil
+}
+
+// FeedReader consumes stream of json documents from reader, separated with whitespace, like NDJSON, or concatenated,
+// like `{"a":1}{"a":2}`. Each document is a separate input. If any input is invalid, json decoding error is returned
+func (p *JSONParser) FeedReader(r io.Reader) error {
+	return p.FeedReaderContext(context.Background(), r)
+}
+
+// FeedReaderContext consumes stream of json documents from reader.
+// Reading and growing of types by read documents stop when context is canceled, its error is returned then.
+// Progress callback set with OptProgress is called after each document.
+func (p *JSONParser) FeedReaderContext(ctx context.Context, r io.Reader) error {
+	cr := &contextReader{ctx: ctx, r: r}
+	var dr io.Reader = &decodingReader{r: cr, d: inputDecoder{policy: p.opts.invalidUTF8, warn: p.warner(WarningEncoding)}}
+	if p.opts.nonFiniteNumbers {
+		dr = &nonFiniteReader{r: dr}
+	}
+	jd := json.NewDecoder(dr)
+
+	var progress Progress
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var err error
+		if p.needsRawInput() {
+			var raw json.RawMessage
+			if err = jd.Decode(&raw); err == nil {
+				err = p.feedBytes(ctx, raw)
+			}
+		} else {
+			var v interface{}
+			if err = jd.Decode(&v); err == nil {
+				err = p.feedContext(ctx, v)
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			if _, ok := err.(*json.SyntaxError); ok || err == io.ErrUnexpectedEOF {
+				return invalidJSONError{err: err}
+			}
+			return err
+		}
+
+		progress.BytesRead = cr.n
+		progress.Documents++
+		if p.opts.progress != nil {
+			p.opts.progress(progress)
+		}
+	}
+}
+
+// needsRawInput checks if inputs must be fed as bytes, to read keys order, find duplicate keys, to measure input
+// sizes, or to retain inputs verifying decoding.
+func (p *JSONParser) needsRawInput() bool {
+	return p.opts.fieldOrder == FieldOrderOriginal || p.opts.duplicateKeysCheck || p.opts.metrics != nil ||
+		p.opts.verifyDecoding
+}
+
+// growCheckInterval is a number of values grown between checks of context of input, see growContext.
+const growCheckInterval = 1024
+
+// growContext is a context of input growing tree, shared by its nodes, so growth of large inputs stops when
+// context is canceled.
+type growContext struct {
+	ctx    context.Context
+	values int
+}
+
+// growCanceled is a panic value stopping growth of tree, when context of input is canceled.
+type growCanceled struct {
+	err error
+}
+
+// check panics with growCanceled every growCheckInterval values, if context is canceled. Nil context isn't checked.
+func (c *growContext) check() {
+	if c == nil || c.ctx == nil {
+		return
+	}
+	c.values++
+	if c.values%growCheckInterval != 0 {
+		return
+	}
+	if err := c.ctx.Err(); err != nil {
+		panic(growCanceled{err: err})
+	}
+}
+
+// recoverGrowCanceled recovers from panic of canceled growth, setting error of context. Other panics are passed on.
+func recoverGrowCanceled(err *error) {
+	if r := recover(); r != nil {
+		canceled, ok := r.(growCanceled)
+		if !ok {
+			panic(r)
+		}
+		*err = canceled.err
+	}
+}
+
+// contextReader is a reader failing after context is canceled. It counts bytes read.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+	n   int64
+}
+
+func (r *contextReader) Read(b []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	n, err := r.r.Read(b)
+	r.n += int64(n)
+	return n, err
+}
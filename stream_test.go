@@ -0,0 +1,122 @@
+package json2go
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertContext(t *testing.T) {
+	t.Parallel()
+
+	input := `{"a":1,"b":"x"} {"a":2}
+{"a":3,"c":true}`
+
+	var progress []Progress
+	out, err := ConvertContext(context.Background(), strings.NewReader(input), baseTypeName,
+		OptProgress(func(p Progress) {
+			progress = append(progress, p)
+		}),
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, normalizeStr(`
+type Document struct {
+	A int     `+"`json:\"a\"`"+`
+	B string  `+"`json:\"b,omitempty\"`"+`
+	C *bool   `+"`json:\"c,omitempty\"`"+`
+}`), normalizeStr(out))
+	require.Len(t, progress, 3)
+	for i, p := range progress {
+		assert.Equal(t, i+1, p.Documents)
+	}
+	assert.Equal(t, int64(len(input)), progress[2].BytesRead)
+}
+
+func TestConvertContextInvalidInput(t *testing.T) {
+	t.Parallel()
+
+	_, err := ConvertContext(context.Background(), strings.NewReader(`{"a":1} {"a":`), baseTypeName)
+	assert.Error(t, err)
+}
+
+func TestConvertContextCanceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	input := strings.NewReader(strings.Repeat(`{"a":1}`, 1000))
+
+	var documents int
+	_, err := ConvertContext(ctx, input, baseTypeName, OptProgress(func(p Progress) {
+		documents = p.Documents
+		if p.Documents == 10 {
+			cancel()
+		}
+	}))
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 10, documents)
+}
+
+// cancelingDetector cancels context when it's called, and counts calls.
+type cancelingDetector struct {
+	cancel func()
+	calls  int
+}
+
+func (d *cancelingDetector) Format() string { return "any" }
+func (d *cancelingDetector) GoType() string { return "string" }
+
+func (d *cancelingDetector) Match(string) bool {
+	d.cancel()
+	d.calls++
+	return true
+}
+
+func TestFeedReaderContextCanceledDuringGrowth(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	detector := &cancelingDetector{cancel: cancel}
+	input := `[` + strings.Repeat(`{"s":"x"},`, 100000) + `{"s":"x"}]`
+
+	p := NewJSONParser(baseTypeName, OptPlugins(&Plugin{Name: "cancel", Detectors: []Detector{detector}}))
+	err := p.FeedReaderContext(ctx, strings.NewReader(input))
+	assert.Equal(t, context.Canceled, err)
+	assert.True(t, detector.calls <= growCheckInterval, "growth isn't stopped after %d values", detector.calls)
+
+	_, err = p.GenerateContext(ctx)
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestFeedReaderConcatenatedValues(t *testing.T) {
+	t.Parallel()
+
+	const input = `{"a":1}{"a":2,"b":"x"}{"a":3}`
+	expected := normalizeStr(`
+type Document struct {
+	A int    ` + "`json:\"a\"`" + `
+	B string ` + "`json:\"b,omitempty\"`" + `
+}`)
+
+	for name, opts := range map[string][]JSONParserOpt{
+		"decoded values": nil,
+		"raw values":     {OptInputCache(10)},
+	} {
+		var progress Progress
+		parser := NewJSONParser(baseTypeName, append(opts, OptProgress(func(p Progress) {
+			progress = p
+		}))...)
+		require.NoError(t, parser.FeedReader(strings.NewReader(input)), name)
+		assert.Equal(t, expected, normalizeStr(parser.String()), name)
+		assert.Equal(t, 3, progress.Documents, name)
+
+		converter := NewConverter(baseTypeName, opts...)
+		require.NoError(t, converter.FeedReaderContext(context.Background(), strings.NewReader(input)), name)
+		out, err := converter.Generate()
+		require.NoError(t, err, name)
+		assert.Equal(t, expected, normalizeStr(out), name)
+	}
+}
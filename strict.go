@@ -0,0 +1,156 @@
+package json2go
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Strictness is a severity of inference compromises, which fail Generate, see OptStrict.
+// Each level includes compromises of lower levels.
+type Strictness int
+
+const (
+	// StrictnessNone accepts all compromises, generated types are best effort.
+	StrictnessNone Strictness = iota
+	// StrictnessInterfaces fails when values are represented by interface{}, because they had values of
+	// different kinds, or only null values and empty arrays.
+	StrictnessInterfaces
+	// StrictnessMixed also fails when values of different json kinds are collapsed into one type,
+	// like json.RawMessage or boolean strings.
+	StrictnessMixed
+	// StrictnessLossless also fails when generated types drop information: keys with only null values are skipped,
+	// fields are commented out, or float32 can't represent all values.
+	StrictnessLossless
+)
+
+// ParseStrictness returns strictness by name: "none", "interfaces", "mixed" or "lossless". Empty name means none.
+func ParseStrictness(name string) (Strictness, error) {
+	switch name {
+	case "", "none":
+		return StrictnessNone, nil
+	case "interfaces":
+		return StrictnessInterfaces, nil
+	case "mixed":
+		return StrictnessMixed, nil
+	case "lossless":
+		return StrictnessLossless, nil
+	}
+	return StrictnessNone, fmt.Errorf("unknown strictness: %s", name)
+}
+
+func (s Strictness) String() string {
+	switch s {
+	case StrictnessInterfaces:
+		return "interfaces"
+	case StrictnessMixed:
+		return "mixed"
+	case StrictnessLossless:
+		return "lossless"
+	}
+	return "none"
+}
+
+// StrictViolation is a compromise of inference, found in strict mode.
+type StrictViolation struct {
+	// Path is a json path of values, like "$.user.id".
+	Path string
+	// Level is the lowest strictness, at which compromise fails.
+	Level Strictness
+	// Reason describes compromise.
+	Reason string
+}
+
+// StrictError is returned by Generate, when inference compromises values, with strictness set with OptStrict.
+// It matches ErrStrict.
+type StrictError struct {
+	Violations []StrictViolation
+}
+
+func (e *StrictError) Error() string {
+	var violations []string
+	for _, v := range e.Violations {
+		violations = append(violations, v.Path+": "+v.Reason)
+	}
+	return ErrStrict.Error() + ": " + strings.Join(violations, "; ")
+}
+
+func (e *StrictError) Is(target error) bool {
+	return target == ErrStrict
+}
+
+// strictCheck returns StrictError with compromises of output nodes, violating strictness set in options.
+// Raw parsed tree is checked for keys skipped by OptSkipEmptyKeys.
+func strictCheck(raw *node, nodes []*node, opts options) error {
+	if opts.strictness == StrictnessNone {
+		return nil
+	}
+
+	var violations []StrictViolation
+	report := func(n *node, level Strictness, format string, args ...interface{}) {
+		if level <= opts.strictness {
+			violations = append(violations, StrictViolation{Path: n.path, Level: level, Reason: fmt.Sprintf(format, args...)})
+		}
+	}
+
+	if opts.skipEmptyKeys {
+		exclude := make(map[string]bool, len(opts.overrides.Exclude))
+		for _, p := range opts.overrides.Exclude {
+			exclude[p] = true
+		}
+		var walkRaw func(n *node)
+		walkRaw = func(n *node) {
+			for _, c := range n.children {
+				if exclude[c.path] {
+					continue
+				}
+				if c.t.id() == nodeTypeInit.id() {
+					report(c, StrictnessLossless, "key skipped, it has only null values")
+					continue
+				}
+				walkRaw(c)
+			}
+		}
+		walkRaw(raw)
+	}
+
+	var walk func(n *node)
+	walk = func(n *node) {
+		switch {
+		case opts.overrides.Types[n.path] != "":
+			// Types set explicitly aren't compromises.
+		case opts.widening.widensInterface(n):
+			report(n, StrictnessMixed, "one type for values of different kinds: %s", strings.Join(n.kindNames(), ", "))
+		case n.t.id() != nodeTypeInterface.id() && opts.widening.widening(n) == WideningInterface:
+			report(n, StrictnessInterfaces, "interface{} for values of different types, set with widening lattice")
+		case n.t.id() == nodeTypeInterface.id() && n.kindsCount() > 1:
+			report(n, StrictnessInterfaces, "interface{} for values of different kinds: %s", strings.Join(n.kindNames(), ", "))
+		case n.t.id() == nodeTypeInterface.id():
+			report(n, StrictnessInterfaces, "interface{} for values of incompatible types")
+		case n.t.id() == nodeTypeInit.id():
+			report(n, StrictnessInterfaces, "interface{} for only null values and empty arrays")
+		case n.kindsCount() > 1:
+			report(n, StrictnessMixed, "one type for values of different kinds: %s", strings.Join(n.kindNames(), ", "))
+		case n.t.id() == nodeTypeFloat.id() && opts.float32 && !opts.float32OnlyLossless && n.needsFloat64:
+			report(n, StrictnessLossless, "float32 can't represent all values")
+		}
+		for _, c := range n.commented {
+			report(c, StrictnessLossless, "field commented out, %s", c.lowConfidence)
+		}
+		for _, c := range n.children {
+			walk(c)
+		}
+		if opts.tuples && n.isTuple() {
+			for _, c := range n.tuple {
+				walk(c)
+			}
+		}
+	}
+	for _, n := range nodes {
+		walk(n)
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+	return &StrictError{Violations: violations}
+}
@@ -0,0 +1,82 @@
+package json2go
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserStrict(t *testing.T) {
+	t.Parallel()
+
+	inputs := []string{
+		`{"id":1,"value":"a","raw":1,"empty":null,"price":3.141592653589793,"rare":1}`,
+		`{"id":2,"value":1,"raw":"b","empty":null,"price":1}`,
+		`{"id":3,"value":true,"raw":[1],"empty":null,"price":1}`,
+		`{"id":4,"value":"b","raw":{},"empty":null,"price":1}`,
+	}
+	baseOpts := []JSONParserOpt{
+		OptRawMessageAt("$.raw"),
+		OptSkipEmptyKeys(true),
+		OptFloat32(true, false),
+		OptCommentOutFields(0.5, false),
+	}
+
+	testCases := []struct {
+		level      Strictness
+		violations []StrictViolation
+	}{
+		{level: StrictnessNone},
+		{level: StrictnessInterfaces, violations: []StrictViolation{
+			{Path: "$.value", Level: StrictnessInterfaces, Reason: "interface{} for values of different kinds: bool, number, string"},
+		}},
+		{level: StrictnessMixed, violations: []StrictViolation{
+			{Path: "$.raw", Level: StrictnessMixed, Reason: "one type for values of different kinds: number, string, object, array"},
+			{Path: "$.value", Level: StrictnessInterfaces, Reason: "interface{} for values of different kinds: bool, number, string"},
+		}},
+		{level: StrictnessLossless, violations: []StrictViolation{
+			{Path: "$.empty", Level: StrictnessLossless, Reason: "key skipped, it has only null values"},
+			{Path: "$.rare", Level: StrictnessLossless, Reason: "field commented out, present in 1 of 4 objects"},
+			{Path: "$.price", Level: StrictnessLossless, Reason: "float32 can't represent all values"},
+			{Path: "$.raw", Level: StrictnessMixed, Reason: "one type for values of different kinds: number, string, object, array"},
+			{Path: "$.value", Level: StrictnessInterfaces, Reason: "interface{} for values of different kinds: bool, number, string"},
+		}},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.level.String(), func(t *testing.T) {
+			t.Parallel()
+
+			parser := NewJSONParser(baseTypeName, append(baseOpts, OptStrict(tc.level))...)
+			for _, input := range inputs {
+				require.NoError(t, parser.FeedBytes([]byte(input)))
+			}
+
+			_, err := parser.Generate()
+			if len(tc.violations) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			assert.True(t, errors.Is(err, ErrStrict))
+			var strictErr *StrictError
+			require.True(t, errors.As(err, &strictErr))
+			assert.Equal(t, tc.violations, strictErr.Violations)
+		})
+	}
+}
+
+func TestParserStrictOverrides(t *testing.T) {
+	t.Parallel()
+
+	parser := NewJSONParser(baseTypeName, OptStrict(StrictnessInterfaces), OptOverrides(Overrides{
+		Types: map[string]string{"$.value": "any"},
+	}))
+	require.NoError(t, parser.FeedBytes([]byte(`{"value":1,"nulls":null}`)))
+	require.NoError(t, parser.FeedBytes([]byte(`{"value":"a","nulls":null}`)))
+
+	_, err := parser.Generate()
+	assert.EqualError(t, err, "strict mode violation: $.nulls: interface{} for only null values and empty arrays")
+}
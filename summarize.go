@@ -0,0 +1,91 @@
+package json2go
+
+import (
+	"fmt"
+	"sort"
+)
+
+// summaryCandidate is an object, which may be summarized to keep number of generated fields within limit.
+type summaryCandidate struct {
+	n        *node
+	depth    int
+	presence float64
+}
+
+// summarizeFields replaces objects of tree with maps, or with json.RawMessage values, until tree has at most
+// maxFields struct fields. Deeper objects are summarized first, and of objects at the same depth, those present
+// in fewer objects of their parents. Objects with values of the same scalar type become maps. Root object isn't
+// summarized. warn is called for each summarized object.
+func summarizeFields(root *node, maxFields uint, warn func(string)) {
+	total := countFields(root)
+	if total <= int(maxFields) {
+		return
+	}
+
+	var candidates []summaryCandidate
+	var collect func(n *node, depth int)
+	collect = func(n *node, depth int) {
+		for _, c := range n.children {
+			if c.t.id() == nodeTypeObject.id() && len(c.children) > 0 {
+				presence := 1.0
+				if n.t.id() == nodeTypeObject.id() {
+					presence, _ = c.presence(n)
+				}
+				candidates = append(candidates, summaryCandidate{n: c, depth: depth + 1, presence: presence})
+			}
+			collect(c, depth+1)
+		}
+	}
+	collect(root, 0)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.depth != b.depth {
+			return a.depth > b.depth
+		}
+		if a.presence != b.presence {
+			return a.presence < b.presence
+		}
+		return a.n.path < b.n.path
+	})
+
+	for _, c := range candidates {
+		if total <= int(maxFields) {
+			return
+		}
+		fields := countFields(c.n)
+		if isScalarObject(c.n) {
+			tryConvertToMap(c.n, 1, nil)
+			warn(fmt.Sprintf("%s: object with %d fields is summarized as map, generated fields exceed limit of %d",
+				c.n.path, fields, maxFields))
+		} else {
+			c.n.t = nodeTypeRawMessage
+			c.n.children = nil
+			c.n.commented = nil
+			c.n.arrayWithNulls = false
+			warn(fmt.Sprintf("%s: object with %d fields is summarized as json.RawMessage, generated fields exceed limit of %d",
+				c.n.path, fields, maxFields))
+		}
+		total -= fields - countFields(c.n)
+	}
+}
+
+// countFields returns number of struct fields generated for objects of node subtree.
+func countFields(n *node) int {
+	count := 0
+	if n.t.id() == nodeTypeObject.id() {
+		count = len(n.children)
+	}
+	for _, c := range n.children {
+		count += countFields(c)
+	}
+	return count
+}
+
+// isScalarObject returns true for objects with values of the same scalar type, which can be map values.
+func isScalarObject(n *node) bool {
+	if mapValueStructureID(n, 1) == "" {
+		return false
+	}
+	value := n.children[0]
+	return len(value.children) == 0 && value.arrayLevel == 0 && value.t.id() != nodeTypeObject.id()
+}
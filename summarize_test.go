@@ -0,0 +1,38 @@
+package json2go
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserMaxGeneratedFields(t *testing.T) {
+	t.Parallel()
+
+	p := NewJSONParser(baseTypeName, OptMaxGeneratedFields(8), OptMakeMaps(false, 0))
+	require.NoError(t, p.FeedBytes([]byte(`{
+		"id": 1,
+		"user": {"name": "a", "profile": {"bio": "b", "links": {"web": "c"}}},
+		"metrics": {"cpu": 1.5, "mem": 2.5, "disk": 3.5}
+	}`)))
+	require.NoError(t, p.FeedBytes([]byte(`{"id": 2, "user": {"name": "b"}, "metrics": {"cpu": 1, "mem": 2, "disk": 3}}`)))
+	out, err := p.Generate()
+	require.NoError(t, err)
+	assert.Equal(t, "type Document struct {\n"+
+		"\tID      int `json:\"id\"`\n"+
+		"\tMetrics struct {\n"+
+		"\t\tCPU  float64 `json:\"cpu\"`\n"+
+		"\t\tDisk float64 `json:\"disk\"`\n"+
+		"\t\tMem  float64 `json:\"mem\"`\n"+
+		"\t} `json:\"metrics\"`\n"+
+		"\tUser struct {\n"+
+		"\t\tName    string          `json:\"name\"`\n"+
+		"\t\tProfile json.RawMessage `json:\"profile,omitempty\"`\n"+
+		"\t} `json:\"user\"`\n"+
+		"}", out)
+	assert.Equal(t, []string{
+		"$.user.profile.links: object with 1 fields is summarized as map, generated fields exceed limit of 8",
+		"$.user.profile: object with 2 fields is summarized as json.RawMessage, generated fields exceed limit of 8",
+	}, p.Warnings())
+}
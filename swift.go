@@ -0,0 +1,202 @@
+package json2go
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// swiftKeywords are keywords of Swift, quoted with backticks in property names.
+var swiftKeywords = map[string]bool{
+	"as": true, "associatedtype": true, "break": true, "case": true, "catch": true, "class": true, "continue": true,
+	"default": true, "defer": true, "deinit": true, "do": true, "else": true, "enum": true, "extension": true,
+	"fallthrough": true, "false": true, "fileprivate": true, "for": true, "func": true, "guard": true, "if": true,
+	"import": true, "in": true, "init": true, "inout": true, "internal": true, "is": true, "let": true, "nil": true,
+	"operator": true, "private": true, "protocol": true, "public": true, "repeat": true, "rethrows": true,
+	"return": true, "self": true, "static": true, "struct": true, "subscript": true, "super": true, "switch": true,
+	"throw": true, "throws": true, "true": true, "try": true, "typealias": true, "var": true, "where": true,
+	"while": true,
+}
+
+// swiftJSONValue is a source of Codable enum of any json values.
+const swiftJSONValue = `
+enum %[1]s: Codable, Equatable {
+    case null
+    case bool(Bool)
+    case number(Double)
+    case string(String)
+    case array([%[1]s])
+    case object([String: %[1]s])
+
+    init(from decoder: Decoder) throws {
+        let container = try decoder.singleValueContainer()
+        if container.decodeNil() {
+            self = .null
+        } else if let value = try? container.decode(Bool.self) {
+            self = .bool(value)
+        } else if let value = try? container.decode(Double.self) {
+            self = .number(value)
+        } else if let value = try? container.decode(String.self) {
+            self = .string(value)
+        } else if let value = try? container.decode([%[1]s].self) {
+            self = .array(value)
+        } else {
+            self = .object(try container.decode([String: %[1]s].self))
+        }
+    }
+
+    func encode(to encoder: Encoder) throws {
+        var container = encoder.singleValueContainer()
+        switch self {
+        case .null:
+            try container.encodeNil()
+        case .bool(let value):
+            try container.encode(value)
+        case .number(let value):
+            try container.encode(value)
+        case .string(let value):
+            try container.encode(value)
+        case .array(let value):
+            try container.encode(value)
+        case .object(let value):
+            try container.encode(value)
+        }
+    }
+}
+`
+
+// SwiftEmitter emits Swift Codable structs from IR, root struct first.
+//
+// Structs keep go names and properties are go names in lower camel case, with CodingKeys mapping them back
+// to json keys. Fields of pointers, optional values or with omitempty are optionals. Structs containing themselves
+// in optionals are final classes. Times are Dates, decoded with iso8601 date decoding strategy, uuids are UUIDs,
+// and any or opaque values are values of JSONValue enum, added to output.
+type SwiftEmitter struct{}
+
+// Emit returns Swift source of structs.
+func (SwiftEmitter) Emit(ir *Schema) ([]byte, error) {
+	s, err := newIDLSchema(ir, "Swift struct", false)
+	if err != nil {
+		return nil, err
+	}
+	jsonValue := "JSONValue"
+	for ir.Types[jsonValue] != nil {
+		jsonValue = nextName(jsonValue)
+	}
+
+	var b strings.Builder
+	b.WriteString("import Foundation\n")
+	usesJSONValue := false
+	for _, d := range s.decls {
+		kind := "struct"
+		if swiftContainsItself(ir, d.t) {
+			// Structs can't contain themselves.
+			kind = "final class"
+		}
+		fmt.Fprintf(&b, "\n%s %s: Codable {\n", kind, d.name)
+
+		var keys []string
+		renamed := false
+		names := make(map[string]bool)
+		for _, f := range d.fields {
+			name := lowerCamelCaseName(f.Name)
+			for names[name] {
+				name = nextName(name)
+			}
+			names[name] = true
+
+			typ := swiftType(ir, s, f.Type, jsonValue)
+			if strings.Contains(typ, jsonValue) {
+				usesJSONValue = true
+			}
+			if f.isOptional() && !strings.HasSuffix(typ, "?") {
+				typ += "?"
+			}
+			quoted := name
+			if swiftKeywords[name] {
+				quoted = "`" + name + "`"
+			}
+			fmt.Fprintf(&b, "    let %s: %s\n", quoted, typ)
+
+			key := "        case " + quoted
+			if name != f.Key {
+				renamed = true
+				key += " = " + strconv.Quote(f.Key)
+			}
+			keys = append(keys, key)
+		}
+		if renamed {
+			b.WriteString("\n    enum CodingKeys: String, CodingKey {\n")
+			b.WriteString(strings.Join(keys, "\n"))
+			b.WriteString("\n    }\n")
+		}
+		b.WriteString("}\n")
+	}
+	if usesJSONValue {
+		fmt.Fprintf(&b, swiftJSONValue, jsonValue)
+	}
+	return []byte(b.String()), nil
+}
+
+// swiftType returns Swift type of values. Any values are of type jsonValue.
+func swiftType(ir *Schema, s *idlSchema, t *SchemaType, jsonValue string) string {
+	switch t.Kind {
+	case SchemaBool:
+		return "Bool"
+	case SchemaInt:
+		return "Int"
+	case SchemaFloat:
+		if t.Bits == 32 {
+			return "Float"
+		}
+		return "Double"
+	case SchemaString:
+		if t.Format == SchemaFormatUUID {
+			return "UUID"
+		}
+		return "String"
+	case SchemaTime:
+		return "Date"
+	case SchemaPointer, SchemaOptional:
+		return strings.TrimSuffix(swiftType(ir, s, t.Elem, jsonValue), "?") + "?"
+	case SchemaSlice:
+		return "[" + swiftType(ir, s, t.Elem, jsonValue) + "]"
+	case SchemaMap:
+		// Dictionaries with other keys than strings and integers are encoded as arrays.
+		key := "String"
+		if t.Key != nil && t.Key.Kind == SchemaInt {
+			key = "Int"
+		}
+		return "[" + key + ": " + swiftType(ir, s, t.Elem, jsonValue) + "]"
+	case SchemaStruct:
+		return s.names[t]
+	case SchemaNamed:
+		return swiftType(ir, s, ir.Types[t.Name], jsonValue)
+	}
+	return jsonValue
+}
+
+// swiftContainsItself checks if struct contains values of its own type, directly or in optionals.
+func swiftContainsItself(ir *Schema, t *SchemaType) bool {
+	visited := make(map[*SchemaType]bool)
+	for _, f := range t.Fields {
+		elem := f.Type
+		for elem.Kind == SchemaPointer || elem.Kind == SchemaOptional || elem.Kind == SchemaNamed {
+			if elem.Kind == SchemaNamed {
+				elem = ir.Types[elem.Name]
+			} else {
+				elem = elem.Elem
+			}
+		}
+		if elem.Kind == SchemaStruct && rustContains(ir, elem, t, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+// SwiftTypes returns Swift Codable structs describing parsed documents, see SwiftEmitter.
+func (p *JSONParser) SwiftTypes() (string, error) {
+	out, err := SwiftEmitter{}.Emit(p.Schema())
+	return string(out), err
+}
@@ -0,0 +1,147 @@
+package json2go
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSwiftTypes(t *testing.T) {
+	t.Parallel()
+
+	p := NewJSONParser(baseTypeName)
+	require.NoError(t, p.FeedBytes([]byte(`{"id": 1, "user_name": "a", "tags": ["x"], "addr": {"zip": "1"},
+		"at": "2021-01-01T00:00:00Z", "class": "c"}`)))
+	require.NoError(t, p.FeedBytes([]byte(`{"id": 2, "tags": [], "addr": {"zip": "2"},
+		"at": "2021-01-02T00:00:00Z", "score": 0.5, "class": "c"}`)))
+
+	src, err := p.SwiftTypes()
+	require.NoError(t, err)
+	assert.Equal(t, `import Foundation
+
+struct Document: Codable {
+    let addr: DocumentAddr
+    let at: Date
+    let `+"`class`"+`: String
+    let id: Int
+    let score: Double?
+    let tags: [String]
+    let userName: String?
+
+    enum CodingKeys: String, CodingKey {
+        case addr
+        case at
+        case `+"`class`"+`
+        case id
+        case score
+        case tags
+        case userName = "user_name"
+    }
+}
+
+struct DocumentAddr: Codable {
+    let zip: String
+}
+`, src)
+}
+
+func TestSwiftEmitter(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		ir       *Schema
+		expected string
+		err      error
+	}{
+		{
+			name: "recursive type, maps and any values",
+			ir: &Schema{Root: "Node", Types: map[string]*SchemaType{
+				"Node": {Kind: SchemaStruct, Fields: []SchemaField{
+					{Name: "Parent", Key: "parent", Type: &SchemaType{Kind: SchemaPointer, Elem: &SchemaType{Kind: SchemaNamed, Name: "Node"}}},
+					{Name: "Weights", Key: "weights", Type: &SchemaType{Kind: SchemaMap, Key: &SchemaType{Kind: SchemaInt, Bits: 64},
+						Elem: &SchemaType{Kind: SchemaFloat, Bits: 32}}},
+					{Name: "Owners", Key: "owners", Type: &SchemaType{Kind: SchemaMap, Key: &SchemaType{Kind: SchemaString, Format: SchemaFormatUUID},
+						Elem: &SchemaType{Kind: SchemaString, Format: SchemaFormatUUID}}},
+					{Name: "Value", Key: "value", Type: &SchemaType{Kind: SchemaAny}, OmitEmpty: true},
+				}},
+				"JSONValue": {Kind: SchemaStruct},
+			}},
+			expected: `import Foundation
+
+final class Node: Codable {
+    let parent: Node?
+    let weights: [Int: Float]
+    let owners: [String: UUID]
+    let value: JSONValue2?
+}
+
+enum JSONValue2: Codable, Equatable {
+    case null
+    case bool(Bool)
+    case number(Double)
+    case string(String)
+    case array([JSONValue2])
+    case object([String: JSONValue2])
+
+    init(from decoder: Decoder) throws {
+        let container = try decoder.singleValueContainer()
+        if container.decodeNil() {
+            self = .null
+        } else if let value = try? container.decode(Bool.self) {
+            self = .bool(value)
+        } else if let value = try? container.decode(Double.self) {
+            self = .number(value)
+        } else if let value = try? container.decode(String.self) {
+            self = .string(value)
+        } else if let value = try? container.decode([JSONValue2].self) {
+            self = .array(value)
+        } else {
+            self = .object(try container.decode([String: JSONValue2].self))
+        }
+    }
+
+    func encode(to encoder: Encoder) throws {
+        var container = encoder.singleValueContainer()
+        switch self {
+        case .null:
+            try container.encodeNil()
+        case .bool(let value):
+            try container.encode(value)
+        case .number(let value):
+            try container.encode(value)
+        case .string(let value):
+            try container.encode(value)
+        case .array(let value):
+            try container.encode(value)
+        case .object(let value):
+            try container.encode(value)
+        }
+    }
+}
+`,
+		},
+		{
+			name: "root isn't struct",
+			ir:   &Schema{Root: "Document", Types: map[string]*SchemaType{"Document": {Kind: SchemaString}}},
+			err:  ErrUnsupportedShape,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			out, err := SwiftEmitter{}.Emit(tc.ir)
+			if tc.err != nil {
+				assert.True(t, errors.Is(err, tc.err), "unexpected error: %v", err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(out))
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package json2go
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// TagData is a struct field description, used to execute tag template set with OptTagTemplate.
+type TagData struct {
+	// Key is a json key.
+	Key string
+	// EscapedKey is a json key escaped for a quoted tag value, like `a\"b` for key `a"b`.
+	EscapedKey string
+	// Name is a go field name.
+	Name string
+	// Snake is a field name in snake case, e.g. "user_id" for "UserID".
+	Snake string
+	// Camel is a field name in lower camel case, e.g. "userID" for "UserID".
+	Camel string
+	// Path is a json path of the field, like "$.user.id".
+	Path string
+	// Optional is true if key was missing in some objects.
+	Optional bool
+	// Omit is an option for omitting empty values used in default json tag: "omitempty", "omitzero" or "".
+	Omit string
+	// String is true if value is encoded as json string, and tag needs "string" option.
+	String bool
+	// Format is a value of json v2 "format" option, like "RFC3339", or "".
+	Format string
+}
+
+// astTagFromTemplate returns struct field tag, executing tag template.
+// If template execution fails or executed tag is malformed, error is recorded and default json tag is returned.
+func astTagFromTemplate(n *node, omit string, asString bool, format string, ctx *astContext) *ast.BasicLit {
+	data := TagData{
+		Key:        n.key,
+		EscapedKey: escapeTagValue(n.key),
+		Name:       n.name,
+		Snake:      snakeCaseName(n.name),
+		Camel:      lowerCamelCaseName(n.name),
+		Path:       n.path,
+		Optional:   !n.required,
+		Omit:       omit,
+		String:     asString,
+		Format:     format,
+	}
+
+	var buf bytes.Buffer
+	if err := ctx.opts.tagTemplate.Execute(&buf, data); err != nil {
+		ctx.fail(fmt.Errorf("%s: executing tag template: %w", n.path, err))
+		return astJSONTag(n.key, astJSONTagOptions(omit, asString, format, ctx)...)
+	}
+
+	tag := buf.String()
+	if err := validateStructTag(tag); err != nil {
+		ctx.fail(fmt.Errorf("%s: executing tag template: %w", n.path, err))
+		return astJSONTag(n.key, astJSONTagOptions(omit, asString, format, ctx)...)
+	}
+	if strings.Contains(tag, "`") {
+		return &ast.BasicLit{Value: strconv.Quote(tag)}
+	}
+	return &ast.BasicLit{Value: "`" + tag + "`"}
+}
+
+// escapeTagValue escapes s like strconv.Quote, without surrounding quotes.
+func escapeTagValue(s string) string {
+	q := strconv.Quote(s)
+	return q[1 : len(q)-1]
+}
+
+// validateStructTag checks if tag is a conventional struct tag of space separated key:"value" pairs,
+// which reflect.StructTag.Lookup can read.
+func validateStructTag(tag string) error {
+	for rest := strings.TrimLeft(tag, " "); rest != ""; rest = strings.TrimLeft(rest, " ") {
+		i := strings.IndexAny(rest, ` :"`)
+		if i <= 0 || !strings.HasPrefix(rest[i:], `:"`) {
+			return fmt.Errorf("malformed tag %q", tag)
+		}
+		key := rest[:i]
+		rest = rest[i+1:]
+
+		// Value ends with first unescaped quote.
+		end := 1
+		for end < len(rest) && rest[end] != '"' {
+			if rest[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(rest) {
+			return fmt.Errorf("malformed tag %q: unterminated value of key %s", tag, key)
+		}
+		if _, err := strconv.Unquote(rest[:end+1]); err != nil {
+			return fmt.Errorf("malformed tag %q: invalid value of key %s", tag, key)
+		}
+		rest = rest[end+1:]
+		if rest != "" && rest[0] != ' ' {
+			return fmt.Errorf("malformed tag %q: missing space after value of key %s", tag, key)
+		}
+		if _, ok := reflect.StructTag(tag).Lookup(key); !ok {
+			return fmt.Errorf("malformed tag %q: key %s can't be read", tag, key)
+		}
+	}
+	return nil
+}
+
+// verifyJSONTag checks if encoding/json reads key from struct field tag literal.
+func verifyJSONTag(tag *ast.BasicLit, key string) error {
+	s, err := strconv.Unquote(tag.Value)
+	if err != nil {
+		return fmt.Errorf("invalid tag literal %s", tag.Value)
+	}
+	value, ok := reflect.StructTag(s).Lookup("json")
+	if !ok {
+		return fmt.Errorf("tag %s has no json key, field name is used instead of key %q", tag.Value, key)
+	}
+	name := value
+	if i := strings.Index(value, ","); i >= 0 {
+		name = value[:i]
+	}
+	switch {
+	case !isJSONTagName(key):
+		return fmt.Errorf("key %q can't be represented in json tag, its values aren't decoded", key)
+	case value == "-":
+		return fmt.Errorf("tag %s skips field with key %q", tag.Value, key)
+	case name != key:
+		return fmt.Errorf("tag %s has json key %q instead of %q", tag.Value, name, key)
+	}
+	return nil
+}
+
+// markUntaggableKeys marks and warns about keys of object attributes, which encoding/json can't read from json tags,
+// like keys with commas or quotes. Values of such keys are decoded by json methods of named struct types, see
+// astAddKeyMethods. Fields of other structs are tagged without key, and values of such keys aren't decoded.
+func markUntaggableKeys(n *node, named bool, opts *options, warn func(string)) {
+	// Tags from template aren't replaced.
+	captured := named && opts.tagTemplate == nil && astHasKeyMethods(n, opts)
+	for _, c := range n.children {
+		c.rawKey = false
+		if n.t.id() == nodeTypeObject.id() && !isJSONTagName(c.key) {
+			if captured {
+				c.rawKey = true
+				warn(fmt.Sprintf("%s: key %q can't be represented in json tag, field %s is decoded by UnmarshalJSON method of %s",
+					c.path, c.key, c.name, n.name))
+			} else {
+				warn(fmt.Sprintf("%s: key %q can't be represented in json tag, its values aren't decoded to field %s",
+					c.path, c.key, c.name))
+			}
+		}
+		markUntaggableKeys(c, false, opts, warn)
+	}
+}
+
+// isJSONTagName checks if name is used by encoding/json as key of struct field, like it checks names of json tags.
+// Other names are ignored, and field name is used instead.
+func isJSONTagName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, c := range name {
+		if !strings.ContainsRune("!#$%&()*+-./:;<=>?@[]^_{|}~ ", c) && !unicode.IsLetter(c) && !unicode.IsDigit(c) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,195 @@
+package json2go
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+)
+
+// TerraformEmitter emits go source of terraform-plugin-framework resource schema from IR, so providers can be
+// bootstrapped from samples of API responses. Source declares function returning schema.Schema of root type,
+// named like "DocumentSchema".
+//
+// Attribute names are field names in snake case, as terraform requires. Fields of pointers, optional values
+// and fields with omitempty are optional, other fields are required. Times are strings, values of any
+// or opaque kinds, like json.RawMessage, are dynamic.
+type TerraformEmitter struct {
+	// Package is a package name of source, "provider" if empty.
+	Package string
+}
+
+// Emit returns go source of function returning resource schema of root type of IR.
+func (e TerraformEmitter) Emit(ir *Schema) ([]byte, error) {
+	if err := validateIR(ir); err != nil {
+		return nil, err
+	}
+	pkg := e.Package
+	if pkg == "" {
+		pkg = "provider"
+	}
+
+	g := terraformGen{ir: ir, expanding: map[string]bool{ir.Root: true}}
+	attrs := g.attributes(ir.Types[ir.Root])
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by json2go. DO NOT EDIT.\n\npackage %s\n\nimport (\n", pkg)
+	if g.usesAttr {
+		src.WriteString("\"github.com/hashicorp/terraform-plugin-framework/attr\"\n")
+	}
+	src.WriteString("\"github.com/hashicorp/terraform-plugin-framework/resource/schema\"\n")
+	if g.usesTypes {
+		src.WriteString("\"github.com/hashicorp/terraform-plugin-framework/types\"\n")
+	}
+	fmt.Fprintf(&src, ")\n\n// %[1]sSchema returns terraform resource schema of %[1]s.\nfunc %[1]sSchema() schema.Schema {\n"+
+		"return schema.Schema{\nAttributes: %[2]s,\n}\n}\n", ir.Root, attrs)
+
+	out, err := format.Source(src.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("%w: formatting terraform schema: %v", ErrInternal, err)
+	}
+	return out, nil
+}
+
+// terraformGen generates expressions of terraform schema. Declared types being expanded are kept in expanding,
+// recursive references are dynamic.
+type terraformGen struct {
+	ir        *Schema
+	expanding map[string]bool
+	usesAttr  bool
+	usesTypes bool
+}
+
+// attributes returns map literal of attributes of struct fields.
+func (g *terraformGen) attributes(t *SchemaType) string {
+	var b strings.Builder
+	b.WriteString("map[string]schema.Attribute{\n")
+	if t.Kind == SchemaStruct {
+		for _, f := range t.Fields {
+			required := !f.OmitEmpty && !f.OmitZero && f.Type.Kind != SchemaPointer && f.Type.Kind != SchemaOptional
+			fmt.Fprintf(&b, "%s: %s,\n", strconv.Quote(snakeCaseName(f.Name)), g.attribute(f.Type, required))
+		}
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// attribute returns literal of attribute of type.
+func (g *terraformGen) attribute(t *SchemaType, required bool) string {
+	presence := "Optional: true"
+	if required {
+		presence = "Required: true"
+	}
+
+	if g.recursive(t) {
+		return "schema.DynamicAttribute{" + presence + "}"
+	}
+	defer g.expand(t)()
+
+	t = g.resolve(g.deref(t))
+	switch t.Kind {
+	case SchemaBool:
+		return "schema.BoolAttribute{" + presence + "}"
+	case SchemaInt:
+		return "schema.Int64Attribute{" + presence + "}"
+	case SchemaFloat:
+		return "schema.Float64Attribute{" + presence + "}"
+	case SchemaString, SchemaTime:
+		return "schema.StringAttribute{" + presence + "}"
+	case SchemaSlice, SchemaMap:
+		collection := "List"
+		if t.Kind == SchemaMap {
+			collection = "Map"
+		}
+		elem := g.resolve(g.deref(t.Elem))
+		if elem.Kind == SchemaStruct && !g.recursive(t.Elem) {
+			defer g.expand(t.Elem)()
+			return fmt.Sprintf("schema.%sNestedAttribute{NestedObject: schema.NestedAttributeObject{Attributes: %s}, %s}",
+				collection, g.attributes(elem), presence)
+		}
+		return fmt.Sprintf("schema.%sAttribute{ElementType: %s, %s}", collection, g.elemType(t.Elem), presence)
+	case SchemaStruct:
+		return fmt.Sprintf("schema.SingleNestedAttribute{Attributes: %s, %s}", g.attributes(t), presence)
+	}
+	return "schema.DynamicAttribute{" + presence + "}"
+}
+
+// elemType returns expression of attr.Type of collection elements.
+func (g *terraformGen) elemType(t *SchemaType) string {
+	g.usesTypes = true
+	if g.recursive(t) {
+		return "types.DynamicType"
+	}
+	defer g.expand(t)()
+
+	t = g.resolve(g.deref(t))
+	switch t.Kind {
+	case SchemaBool:
+		return "types.BoolType"
+	case SchemaInt:
+		return "types.Int64Type"
+	case SchemaFloat:
+		return "types.Float64Type"
+	case SchemaString, SchemaTime:
+		return "types.StringType"
+	case SchemaSlice:
+		return "types.ListType{ElemType: " + g.elemType(t.Elem) + "}"
+	case SchemaMap:
+		return "types.MapType{ElemType: " + g.elemType(t.Elem) + "}"
+	case SchemaStruct:
+		g.usesAttr = true
+		var b strings.Builder
+		b.WriteString("types.ObjectType{AttrTypes: map[string]attr.Type{\n")
+		for _, f := range t.Fields {
+			fmt.Fprintf(&b, "%s: %s,\n", strconv.Quote(snakeCaseName(f.Name)), g.elemType(f.Type))
+		}
+		b.WriteString("}}")
+		return b.String()
+	}
+	return "types.DynamicType"
+}
+
+// deref returns type of pointed or optional values.
+func (g *terraformGen) deref(t *SchemaType) *SchemaType {
+	for t.Kind == SchemaPointer || t.Kind == SchemaOptional {
+		t = t.Elem
+	}
+	return t
+}
+
+// resolve returns declared type of named type, or type itself.
+func (g *terraformGen) resolve(t *SchemaType) *SchemaType {
+	if t.Kind != SchemaNamed {
+		return t
+	}
+	return g.ir.Types[t.Name]
+}
+
+// recursive checks if type is a reference to declared type being expanded.
+func (g *terraformGen) recursive(t *SchemaType) bool {
+	t = g.deref(t)
+	return t.Kind == SchemaNamed && g.expanding[t.Name]
+}
+
+// expand marks referenced declared type as being expanded, until returned function is called.
+func (g *terraformGen) expand(t *SchemaType) func() {
+	t = g.deref(t)
+	if t.Kind != SchemaNamed {
+		return func() {}
+	}
+	g.expanding[t.Name] = true
+	return func() {
+		delete(g.expanding, t.Name)
+	}
+}
+
+// TerraformSchema returns go source of package pkg, with function returning terraform-plugin-framework resource
+// schema of parsed documents, see TerraformEmitter.
+func (p *JSONParser) TerraformSchema(pkg string) (string, error) {
+	out, err := TerraformEmitter{Package: pkg}.Emit(p.Schema())
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInternal, err)
+	}
+	return string(out), nil
+}
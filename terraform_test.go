@@ -0,0 +1,98 @@
+package json2go
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTerraformSchema(t *testing.T) {
+	t.Parallel()
+
+	p := NewJSONParser("Network")
+	require.NoError(t, p.FeedBytes([]byte(`{"name":"a","startAt":"2021-01-01T00:00:00Z","tags":["x"],"rules":[{"port":1}],`+
+		`"meta":{"size":1.5},"raw":[1,"a"]}`)))
+	require.NoError(t, p.FeedBytes([]byte(`{"name":"b","tags":[],"rules":[]}`)))
+
+	src, err := p.TerraformSchema("")
+	require.NoError(t, err)
+	assert.Equal(t, `// Code generated by json2go. DO NOT EDIT.
+
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// NetworkSchema returns terraform resource schema of Network.
+func NetworkSchema() schema.Schema {
+	return schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"meta": schema.SingleNestedAttribute{Attributes: map[string]schema.Attribute{
+				"size": schema.Float64Attribute{Required: true},
+			}, Optional: true},
+			"name": schema.StringAttribute{Required: true},
+			"raw":  schema.ListAttribute{ElementType: types.DynamicType, Optional: true},
+			"rules": schema.ListNestedAttribute{NestedObject: schema.NestedAttributeObject{Attributes: map[string]schema.Attribute{
+				"port": schema.Int64Attribute{Required: true},
+			}}, Required: true},
+			"start_at": schema.StringAttribute{Optional: true},
+			"tags":     schema.ListAttribute{ElementType: types.StringType, Required: true},
+		},
+	}
+}
+`, src)
+}
+
+func TestTerraformEmitter(t *testing.T) {
+	t.Parallel()
+
+	out, err := TerraformEmitter{Package: "tree"}.Emit(&Schema{
+		Root: "Node",
+		Types: map[string]*SchemaType{
+			"Node": {Kind: SchemaStruct, Fields: []SchemaField{
+				{Name: "Children", Key: "children", Type: &SchemaType{Kind: SchemaSlice, Elem: &SchemaType{Kind: SchemaNamed, Name: "Node"}}},
+				{Name: "Parent", Key: "parent", Type: &SchemaType{Kind: SchemaPointer, Elem: &SchemaType{Kind: SchemaNamed, Name: "Node"}}},
+				{Name: "LabelSets", Key: "labelSets", Type: &SchemaType{Kind: SchemaSlice, Elem: &SchemaType{Kind: SchemaSlice,
+					Elem: &SchemaType{Kind: SchemaNamed, Name: "Label"}}}, OmitEmpty: true},
+				{Name: "Owners", Key: "owners", Type: &SchemaType{Kind: SchemaMap, Elem: &SchemaType{Kind: SchemaNamed, Name: "Label"}}},
+			}},
+			"Label": {Kind: SchemaStruct, Fields: []SchemaField{
+				{Name: "Value", Key: "value", Type: &SchemaType{Kind: SchemaOptional, Elem: &SchemaType{Kind: SchemaBool}}},
+			}},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `// Code generated by json2go. DO NOT EDIT.
+
+package tree
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// NodeSchema returns terraform resource schema of Node.
+func NodeSchema() schema.Schema {
+	return schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"children": schema.ListAttribute{ElementType: types.DynamicType, Required: true},
+			"parent":   schema.DynamicAttribute{Optional: true},
+			"label_sets": schema.ListAttribute{ElementType: types.ListType{ElemType: types.ObjectType{AttrTypes: map[string]attr.Type{
+				"value": types.BoolType,
+			}}}, Optional: true},
+			"owners": schema.MapNestedAttribute{NestedObject: schema.NestedAttributeObject{Attributes: map[string]schema.Attribute{
+				"value": schema.BoolAttribute{Optional: true},
+			}}, Required: true},
+		},
+	}
+}
+`, string(out))
+
+	_, err = TerraformEmitter{}.Emit(&Schema{Root: "Missing"})
+	assert.True(t, errors.Is(err, ErrInvalidIR), "%v", err)
+}
@@ -0,0 +1,120 @@
+package json2go
+
+import (
+	"fmt"
+	"strings"
+)
+
+// thriftKeywords are keywords of Thrift IDL, which can't be field names.
+var thriftKeywords = map[string]bool{
+	"binary": true, "bool": true, "byte": true, "const": true, "double": true, "enum": true, "exception": true,
+	"extends": true, "false": true, "i8": true, "i16": true, "i32": true, "i64": true, "include": true, "list": true,
+	"map": true, "namespace": true, "oneway": true, "optional": true, "required": true, "service": true, "set": true,
+	"string": true, "struct": true, "throws": true, "true": true, "typedef": true, "union": true, "uuid": true, "void": true,
+}
+
+// thriftTypedefs are typedefs declared for values of IR kinds without Thrift types, in order of declarations.
+var thriftTypedefs = []struct {
+	name, typ, comment string
+}{
+	{"Timestamp", "i64", "Unix time in microseconds."},
+	{"UUID", "string", ""},
+	{"JSON", "string", "Any json value."},
+}
+
+// ThriftEmitter emits Apache Thrift IDL from IR, with structs of root type and of nested structs, root struct last.
+//
+// Field ids follow order of fields, field names are json keys, with invalid characters replaced. Fields of pointers,
+// optional values or with omitempty are optional, other fields have default requiredness. Times, uuids and
+// any or opaque values have typedefs Timestamp, UUID and JSON.
+type ThriftEmitter struct{}
+
+// Emit returns Thrift IDL.
+func (ThriftEmitter) Emit(ir *Schema) ([]byte, error) {
+	s, err := newIDLSchema(ir, "Thrift IDL", false)
+	if err != nil {
+		return nil, err
+	}
+
+	used := make(map[string]bool)
+	var structs strings.Builder
+	for i := len(s.decls) - 1; i >= 0; i-- {
+		d := s.decls[i]
+		fmt.Fprintf(&structs, "\nstruct %s {\n", capnpName(d.name, true))
+		names := make(map[string]bool)
+		for id, f := range d.fields {
+			name := bigQueryName(f.Key)
+			if thriftKeywords[name] {
+				name += "_"
+			}
+			for names[name] {
+				name = nextName(name)
+			}
+			names[name] = true
+
+			requiredness := ""
+			if f.isOptional() {
+				requiredness = "optional "
+			}
+			fmt.Fprintf(&structs, "  %d: %s%s %s\n", id+1, requiredness, thriftType(ir, s, f.Type, used), name)
+		}
+		structs.WriteString("}\n")
+	}
+
+	var b strings.Builder
+	for _, td := range thriftTypedefs {
+		if !used[td.name] {
+			continue
+		}
+		if td.comment != "" {
+			fmt.Fprintf(&b, "// %s\n", td.comment)
+		}
+		fmt.Fprintf(&b, "typedef %s %s\n", td.typ, td.name)
+	}
+	b.WriteString(structs.String())
+	return []byte(strings.TrimPrefix(b.String(), "\n")), nil
+}
+
+// thriftType returns Thrift type of values. Used typedefs are added to used.
+func thriftType(ir *Schema, s *idlSchema, t *SchemaType, used map[string]bool) string {
+	switch t.Kind {
+	case SchemaBool:
+		return "bool"
+	case SchemaInt:
+		return "i64"
+	case SchemaFloat:
+		// Thrift has no single precision floats.
+		return "double"
+	case SchemaString:
+		if t.Format == SchemaFormatUUID {
+			used["UUID"] = true
+			return "UUID"
+		}
+		return "string"
+	case SchemaTime:
+		used["Timestamp"] = true
+		return "Timestamp"
+	case SchemaPointer, SchemaOptional:
+		return thriftType(ir, s, t.Elem, used)
+	case SchemaSlice:
+		return "list<" + thriftType(ir, s, t.Elem, used) + ">"
+	case SchemaMap:
+		key := &SchemaType{Kind: SchemaString}
+		if t.Key != nil {
+			key = t.Key
+		}
+		return "map<" + thriftType(ir, s, key, used) + ", " + thriftType(ir, s, t.Elem, used) + ">"
+	case SchemaStruct:
+		return capnpName(s.names[t], true)
+	case SchemaNamed:
+		return thriftType(ir, s, ir.Types[t.Name], used)
+	}
+	used["JSON"] = true
+	return "JSON"
+}
+
+// ThriftIDL returns Thrift IDL describing parsed documents, see ThriftEmitter.
+func (p *JSONParser) ThriftIDL() (string, error) {
+	out, err := ThriftEmitter{}.Emit(p.Schema())
+	return string(out), err
+}
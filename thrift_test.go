@@ -0,0 +1,90 @@
+package json2go
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThriftIDL(t *testing.T) {
+	t.Parallel()
+
+	p := NewJSONParser(baseTypeName, OptMakeMaps(true, 2), OptMapKeyTypes(true))
+	require.NoError(t, p.FeedBytes([]byte(`{"id": 1, "user-name": "a", "tags": ["x"], "addr": {"zip": "1"},
+		"at": "2021-01-01T00:00:00Z", "extra": 1, "type": "t",
+		"votes": {"3f2504e0-4f89-11d3-9a0c-0305e82c3301": 1, "4f2504e0-4f89-11d3-9a0c-0305e82c3301": 2}}`)))
+	require.NoError(t, p.FeedBytes([]byte(`{"id": 2, "tags": [], "addr": {"zip": "2"},
+		"at": "2021-01-02T00:00:00Z", "extra": "x", "score": 0.5, "type": "t", "votes": {}}`)))
+
+	idl, err := p.ThriftIDL()
+	require.NoError(t, err)
+	assert.Equal(t, `// Unix time in microseconds.
+typedef i64 Timestamp
+typedef string UUID
+// Any json value.
+typedef string JSON
+
+struct DocumentAddr {
+  1: string zip
+}
+
+struct Document {
+  1: DocumentAddr addr
+  2: Timestamp at
+  3: JSON extra
+  4: i64 id
+  5: optional double score
+  6: list<string> tags
+  7: string type
+  8: optional string user_name
+  9: map<UUID, i64> votes
+}
+`, idl)
+}
+
+func TestThriftEmitter(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		ir       *Schema
+		expected string
+		err      error
+	}{
+		{
+			name: "recursive type and keywords",
+			ir: &Schema{Root: "Node", Types: map[string]*SchemaType{"Node": {Kind: SchemaStruct, Fields: []SchemaField{
+				{Name: "Children", Key: "children", Type: &SchemaType{Kind: SchemaSlice, Elem: &SchemaType{Kind: SchemaNamed, Name: "Node"}}},
+				{Name: "Map", Key: "map", Type: &SchemaType{Kind: SchemaMap, Key: &SchemaType{Kind: SchemaInt},
+					Elem: &SchemaType{Kind: SchemaOptional, Elem: &SchemaType{Kind: SchemaFloat, Bits: 32}}}, OmitEmpty: true},
+			}}}},
+			expected: `struct Node {
+  1: list<Node> children
+  2: optional map<i64, double> map_
+}
+`,
+		},
+		{
+			name: "root isn't struct",
+			ir:   &Schema{Root: "Document", Types: map[string]*SchemaType{"Document": {Kind: SchemaBool}}},
+			err:  ErrUnsupportedShape,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			out, err := ThriftEmitter{}.Emit(tc.ir)
+			if tc.err != nil {
+				assert.True(t, errors.Is(err, tc.err), "unexpected error: %v", err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(out))
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package json2go
+
+import (
+	"fmt"
+	"go/ast"
+	"strconv"
+	"time"
+)
+
+// Named time formats of OptTimeAt. Other formats are go time layouts, like "2006-01-02".
+const (
+	// timeFormatString keeps values as strings or numbers, without time detection.
+	timeFormatString = "string"
+	// timeFormatRFC3339 represents values as time.Time.
+	timeFormatRFC3339 = "rfc3339"
+)
+
+// timeFormatEpochUnits are formats of Unix times of OptTimeAt.
+var timeFormatEpochUnits = map[string]EpochUnit{
+	"unix":    EpochSeconds,
+	"unix_s":  EpochSeconds,
+	"unix_ms": EpochMillis,
+	"unix_us": EpochMicros,
+	"unix_ns": EpochNanos,
+}
+
+// timeLayoutReference is a time formatted with layouts to validate them.
+var timeLayoutReference = time.Date(2021, 11, 23, 21, 47, 38, 0, time.UTC)
+
+// validateTimeFormat returns error if format of values at path is neither named format nor go time layout.
+func validateTimeFormat(path, format string) error {
+	if _, ok := timeFormatEpochUnits[format]; ok || format == timeFormatString || format == timeFormatRFC3339 {
+		return nil
+	}
+	s := timeLayoutReference.Format(format)
+	if s == format {
+		return fmt.Errorf("invalid time format of %s: %q is neither string, rfc3339, unix, unix_s, unix_ms, unix_us, unix_ns "+
+			"nor go time layout", path, format)
+	}
+	if _, err := time.Parse(format, s); err != nil {
+		return fmt.Errorf("invalid time format of %s: %v", path, err)
+	}
+	return nil
+}
+
+// forceTimeFormats sets time formats of nodes at paths, changing their types to types of formats.
+func forceTimeFormats(n *node, formats map[string]string) {
+	if format, ok := formats[n.path]; ok {
+		n.timeFormat = format
+		_, epoch := timeFormatEpochUnits[format]
+		switch {
+		case format == timeFormatString:
+			if n.t == nodeTypeTime {
+				forceKind(n, nodeTypeString)
+			}
+		case format == timeFormatRFC3339:
+			forceKind(n, nodeTypeTime)
+		case epoch:
+			forceKind(n, nodeTypeInt)
+		default:
+			forceKind(n, nodeTypeString)
+		}
+	}
+	for _, c := range n.children {
+		forceTimeFormats(c, formats)
+	}
+}
+
+// astTypeFromTimeFormat returns type of times in format forced with OptTimeAt, or nil if values aren't times.
+func astTypeFromTimeFormat(n *node, ctx *astContext) ast.Expr {
+	if unit, ok := timeFormatEpochUnits[n.timeFormat]; ok {
+		return astTypeFromEpochNode(unit, ctx)
+	}
+	if n.timeFormat == "" || n.timeFormat == timeFormatString || n.timeFormat == timeFormatRFC3339 {
+		return nil
+	}
+
+	ctx.addImport("encoding/json")
+	ctx.addImport("time")
+	return ast.NewIdent(ctx.addNamedSharedHelper("TimeLayout "+n.timeFormat, "TimeLayout", func(name string) string {
+		return fmt.Sprintf(`
+// %[1]s is a time represented in json as a string with layout %[2]q.
+type %[1]s struct {
+	time.Time
+}
+
+// UnmarshalJSON unmarshals time from json string with layout %[2]q.
+func (t *%[1]s) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	v, err := time.Parse(%[3]s, s)
+	if err != nil {
+		return err
+	}
+	t.Time = v
+	return nil
+}
+
+// MarshalJSON marshals time as json string with layout %[2]q.
+func (t %[1]s) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.Format(%[3]s))
+}
+`, name, n.timeFormat, strconv.Quote(n.timeFormat))
+	}))
+}
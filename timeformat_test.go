@@ -0,0 +1,136 @@
+package json2go
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserTimeAt(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		opts     []JSONParserOpt
+		input    string
+		expected string
+	}{
+		{
+			name:     "not forced",
+			input:    `{"created":"2021-01-01T00:00:00Z"}`,
+			expected: "time.Time",
+		},
+		{
+			name:     "string",
+			opts:     []JSONParserOpt{OptTimeAt("$.created", "string")},
+			input:    `{"created":"2021-01-01T00:00:00Z"}`,
+			expected: "string",
+		},
+		{
+			name:     "string disables epoch detection",
+			opts:     []JSONParserOpt{OptEpochTimes(EpochSeconds), OptTimeAt("$.created", "string")},
+			input:    `{"created":1609459200}`,
+			expected: "int",
+		},
+		{
+			name:     "rfc3339 with time as string",
+			opts:     []JSONParserOpt{OptTimeAsString(true), OptTimeAt("$.created", "rfc3339")},
+			input:    `{"created":"2021-01-01T00:00:00Z"}`,
+			expected: "time.Time",
+		},
+		{
+			name:     "unix millis",
+			opts:     []JSONParserOpt{OptTimeAt("$.created", "unix_ms")},
+			input:    `{"created":1609459200123}`,
+			expected: "EpochMillis",
+		},
+		{
+			name:     "unix",
+			opts:     []JSONParserOpt{OptTimeAt("$.created", "unix")},
+			input:    `{"created":12}`,
+			expected: "EpochSeconds",
+		},
+		{
+			name:     "layout",
+			opts:     []JSONParserOpt{OptTimeAt("$.created", "2006-01-02")},
+			input:    `{"created":"2021-01-01"}`,
+			expected: "TimeLayout",
+		},
+		{
+			name:     "layout in array",
+			opts:     []JSONParserOpt{OptTimeAt("$.created", "2006-01-02")},
+			input:    `{"created":["2021-01-01",null]}`,
+			expected: "[]*TimeLayout",
+		},
+		{
+			name:     "other path",
+			opts:     []JSONParserOpt{OptTimeAt("$.updated", "string")},
+			input:    `{"created":"2021-01-01T00:00:00Z"}`,
+			expected: "time.Time",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			parser := NewJSONParser(baseTypeName, tc.opts...)
+			require.NoError(t, parser.FeedBytes([]byte(tc.input)))
+
+			fields := parser.Fields()
+			require.Len(t, fields, 1)
+			assert.Equal(t, tc.expected, fields[0].Type)
+		})
+	}
+}
+
+func TestParserTimeAtInvalidFormat(t *testing.T) {
+	t.Parallel()
+
+	for _, format := range []string{"unix_days", "version", ""} {
+		parser := NewJSONParser(baseTypeName, OptTimeAt("$.created", format))
+		require.NoError(t, parser.FeedBytes([]byte(`{"created":"1.2.3"}`)))
+
+		_, err := parser.Generate()
+		assert.Error(t, err, format)
+	}
+}
+
+func TestParserTimeAtCode(t *testing.T) {
+	t.Parallel()
+
+	parser := NewJSONParser(baseTypeName,
+		OptTimeAt("$.day", "2006-01-02"),
+		OptTimeAt("$.at", "02/01/2006 15:04"),
+		OptTimeAt("$.ts", "unix_ms"),
+	)
+	require.NoError(t, parser.FeedBytes([]byte(`{"day":"2021-01-01","at":"01/01/2021 10:00","ts":1}`)))
+
+	out := runGeneratedCode(t, parser, `
+	var d Document
+	if err := json.NewDecoder(os.Stdin).Decode(&d); err != nil {
+		panic(err)
+	}
+	fmt.Println(d.Day.Format(time.RFC3339), d.At.Format(time.RFC3339), d.Ts.Format(time.RFC3339Nano))
+	out, err := json.Marshal(d)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(out))
+`, `{"day":"2021-02-03","at":"04/05/2021 06:07","ts":1612137600456}`)
+	assert.Equal(t, "2021-02-03T00:00:00Z 2021-05-04T06:07:00Z 2021-02-01T00:00:00.456Z\n"+
+		`{"at":"04/05/2021 06:07","day":"2021-02-03","ts":1612137600456}`+"\n", out)
+}
+
+func TestValidateTimeFormat(t *testing.T) {
+	t.Parallel()
+
+	for _, format := range []string{"string", "rfc3339", "unix", "unix_ns", "2006-01-02", "Mon, 02 Jan 2006 15:04:05 MST"} {
+		assert.NoError(t, validateTimeFormat("$", format), format)
+	}
+	for _, format := range []string{"", "unix_days", "version"} {
+		assert.Error(t, validateTimeFormat("$", format), format)
+	}
+}
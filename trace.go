@@ -0,0 +1,108 @@
+package json2go
+
+import "sort"
+
+// maxTraceExampleLength is a default maximum length of string examples in trace, in runes, see OptExampleLimits.
+const maxTraceExampleLength = 64
+
+// TraceEntry links generated type or struct field to json path of its values, and to input in which it was first seen,
+// so generated declarations can be navigated back to data producing them.
+type TraceEntry struct {
+	// Type is a name of generated type.
+	Type string `json:"type"`
+	// Field is a name of struct field, with names of enclosing fields of anonymous structs, like "Address.City".
+	// It's empty for types.
+	Field string `json:"field,omitempty"`
+	// Path is a json path of values, like "$.user.address.city".
+	Path string `json:"path"`
+	// Input is a number of consumed input, counting from 1, in which value was first seen.
+	// It's 0 for values known only from other sources, like schemas.
+	Input int `json:"input,omitempty"`
+	// Example is a first scalar value, or first scalar element of arrays, strings are truncated.
+	Example interface{} `json:"example,omitempty"`
+}
+
+// Trace returns entries of generated types and their struct fields, in order of declarations and fields.
+// Types of extracted values link to input in which any of their values was first seen.
+// Examples are anonymized with OptAnonymize.
+func (p *JSONParser) Trace() []TraceEntry {
+	var entries []TraceEntry
+	nodes, _ := p.declNodes()
+	for _, n := range nodes {
+		entries = append(entries, TraceEntry{Type: n.name, Path: n.path, Input: n.input, Example: n.example})
+		entries = traceFields(entries, n.name, "", n)
+	}
+	return entries
+}
+
+// traceFields appends entries of fields of struct node of type, recursively for anonymous structs.
+func traceFields(entries []TraceEntry, typeName, prefix string, n *node) []TraceEntry {
+	if n.t.id() != nodeTypeObject.id() {
+		return entries
+	}
+	children := append([]*node(nil), n.children...)
+	sort.Slice(children, func(i, j int) bool {
+		return children[i].name < children[j].name
+	})
+	for _, c := range children {
+		if c.cloudEventAttribute {
+			continue
+		}
+		field := prefix + c.name
+		entries = append(entries, TraceEntry{Type: typeName, Field: field, Path: c.path, Input: c.input, Example: c.example})
+		entries = traceFields(entries, typeName, field+".", c)
+	}
+	return entries
+}
+
+// exampleBudget limits examples of values retained by nodes of tree, see OptExampleLimits.
+type exampleBudget struct {
+	// maxLength is a maximum length of string examples, in runes.
+	maxLength int
+	// left is a number of bytes of string examples, which can be still retained, negative for no limit.
+	left int
+}
+
+// example returns example of value, see traceExample, or nil if string example doesn't fit in budget.
+// Nil budget retains examples of default length without limit.
+func (b *exampleBudget) example(v interface{}) interface{} {
+	if b == nil {
+		return traceExample(v, maxTraceExampleLength)
+	}
+	example := traceExample(v, b.maxLength)
+	s, ok := example.(string)
+	if !ok || b.left < 0 {
+		return example
+	}
+	if len(s) > b.left {
+		b.left = 0
+		return nil
+	}
+	b.left -= len(s)
+	return example
+}
+
+// traceExample returns example of value: scalar, string truncated to maxLength runes, or example of first element
+// of array. Truncated strings are copied, so large values aren't retained by examples.
+func traceExample(v interface{}, maxLength int) interface{} {
+	switch typed := v.(type) {
+	case map[string]interface{}:
+		return nil
+	case []interface{}:
+		for _, el := range typed {
+			if el != nil {
+				return traceExample(el, maxLength)
+			}
+		}
+		return nil
+	case string:
+		runes := 0
+		for i := range typed {
+			if runes == maxLength {
+				return typed[:i] + "…"
+			}
+			runes++
+		}
+	}
+	return v
+}
@@ -0,0 +1,91 @@
+package json2go
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONParser_Trace(t *testing.T) {
+	t.Parallel()
+
+	p := NewJSONParser(baseTypeName, OptExtractCommonTypes(true))
+	require.NoError(t, p.FeedBytes([]byte(`{"user": {"id": 1, "tags": [null, "a"]}, "items": [{"sku": "a"}]}`)))
+	require.NoError(t, p.FeedBytes([]byte(`{"user": {"id": 2, "bio": "`+strings.Repeat("x", 70)+`", "addr": {"city": null}},
+		"returned": [{"sku": "b"}]}`)))
+
+	assert.Equal(t, []TraceEntry{
+		{Type: "Document", Path: "$", Input: 1},
+		{Type: "Document", Field: "Items", Path: "$.items", Input: 1},
+		{Type: "Document", Field: "Returned", Path: "$.returned", Input: 2},
+		{Type: "Document", Field: "User", Path: "$.user", Input: 1},
+		{Type: "Document", Field: "User.Addr", Path: "$.user.addr", Input: 2},
+		{Type: "Document", Field: "User.Addr.City", Path: "$.user.addr.city", Input: 2},
+		{Type: "Document", Field: "User.Bio", Path: "$.user.bio", Input: 2, Example: strings.Repeat("x", 64) + "…"},
+		{Type: "Document", Field: "User.ID", Path: "$.user.id", Input: 1, Example: float64(1)},
+		{Type: "Document", Field: "User.Tags", Path: "$.user.tags", Input: 1, Example: "a"},
+		{Type: "Sku", Path: "$.items", Input: 1},
+		{Type: "Sku", Field: "Sku", Path: "$.items.sku", Input: 1, Example: "a"},
+	}, p.Trace())
+}
+
+func TestJSONParser_TraceExampleLimits(t *testing.T) {
+	t.Parallel()
+
+	blob := strings.Repeat("é", 1<<20)
+	testCases := []struct {
+		name      string
+		maxLength uint
+		maxSize   uint
+		want      map[string]interface{}
+	}{
+		{
+			name: "defaults",
+			want: map[string]interface{}{
+				"$.blob": strings.Repeat("é", 64) + "…", "$.id": float64(1), "$.name": "alice", "$.tags": "abcdef",
+			},
+		},
+		{
+			name:      "length",
+			maxLength: 4,
+			want: map[string]interface{}{
+				"$.blob": "éééé…", "$.id": float64(1), "$.name": "alic…", "$.tags": "abcd…",
+			},
+		},
+		{
+			name:      "size",
+			maxLength: 4,
+			maxSize:   15,
+			want: map[string]interface{}{
+				"$.blob": "éééé…", "$.id": float64(1), "$.name": nil, "$.tags": nil,
+			},
+		},
+		{
+			name:    "size without length",
+			maxSize: 133,
+			want: map[string]interface{}{
+				"$.blob": strings.Repeat("é", 64) + "…", "$.id": float64(1), "$.name": nil, "$.tags": nil,
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := NewJSONParser(baseTypeName, OptExampleLimits(tc.maxLength, tc.maxSize))
+			p.FeedValue(map[string]interface{}{"blob": blob, "id": float64(1)})
+			p.FeedValue(map[string]interface{}{"name": "alice", "tags": []interface{}{"abcdef"}})
+
+			examples := make(map[string]interface{})
+			for _, e := range p.Trace() {
+				if e.Field != "" {
+					examples[e.Path] = e.Example
+				}
+			}
+			assert.Equal(t, tc.want, examples)
+		})
+	}
+}
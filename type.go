@@ -13,10 +13,13 @@ const (
 	nodeTypeInterface = nodeInterfaceType("interface")
 
 	// special types
-	nodeTypeExtracted = nodeExtractedType("extracted")
-	nodeTypeMap       = nodeMapType("map")
+	nodeTypeExtracted  = nodeExtractedType("extracted")
+	nodeTypeMap        = nodeMapType("map")
+	nodeTypeRawMessage = nodeRawMessageType("rawmessage")
 )
 
+// nodeType is a type of json value.
+// Implementations return type constants instead of receivers, so fitting values doesn't allocate.
 type nodeType interface {
 	id() string
 	fit(interface{}) nodeType
@@ -65,7 +68,7 @@ func (n nodeBoolType) expands(n2 nodeType) bool {
 func (n nodeBoolType) fit(v interface{}) nodeType {
 	switch v.(type) {
 	case bool:
-		return n
+		return nodeTypeBool
 	}
 
 	return nodeTypeInt.fit(v)
@@ -84,14 +87,14 @@ func (n nodeIntType) expands(n2 nodeType) bool {
 func (n nodeIntType) fit(v interface{}) nodeType {
 	switch typedValue := v.(type) {
 	case int, int8, int16, int32, int64:
-		return n
+		return nodeTypeInt
 	case float32:
 		if typedValue == float32(int(typedValue)) {
-			return n
+			return nodeTypeInt
 		}
 	case float64:
 		if typedValue == float64(int(typedValue)) {
-			return n
+			return nodeTypeInt
 		}
 	}
 
@@ -111,7 +114,7 @@ func (n nodeFloatType) expands(n2 nodeType) bool {
 func (n nodeFloatType) fit(v interface{}) nodeType {
 	switch v.(type) {
 	case float32, float64, int, int16, int32, int64:
-		return n
+		return nodeTypeFloat
 	}
 
 	return nodeTypeTime.fit(v)
@@ -131,7 +134,7 @@ func (n nodeTimeType) fit(v interface{}) nodeType {
 	switch vt := v.(type) {
 	case string:
 		if _, err := time.Parse(time.RFC3339, vt); err == nil {
-			return n
+			return nodeTypeTime
 		}
 	}
 
@@ -151,7 +154,7 @@ func (n nodeStringType) expands(n2 nodeType) bool {
 func (n nodeStringType) fit(v interface{}) nodeType {
 	switch v.(type) {
 	case string:
-		return n
+		return nodeTypeString
 	}
 
 	return nodeTypeObject.fit(v)
@@ -170,7 +173,7 @@ func (n nodeObjectType) expands(n2 nodeType) bool {
 func (n nodeObjectType) fit(v interface{}) nodeType {
 	switch v.(type) {
 	case map[string]interface{}:
-		return n
+		return nodeTypeObject
 	}
 
 	return nodeTypeInterface.fit(v)
@@ -187,7 +190,7 @@ func (n nodeInterfaceType) expands(n2 nodeType) bool {
 }
 
 func (n nodeInterfaceType) fit(v interface{}) nodeType {
-	return n
+	return nodeTypeInterface
 }
 
 type nodeExtractedType string
@@ -201,7 +204,7 @@ func (n nodeExtractedType) expands(n2 nodeType) bool {
 }
 
 func (n nodeExtractedType) fit(v interface{}) nodeType {
-	return n
+	return nodeTypeExtracted
 }
 
 type nodeMapType string
@@ -215,5 +218,19 @@ func (n nodeMapType) expands(n2 nodeType) bool {
 }
 
 func (n nodeMapType) fit(v interface{}) nodeType {
-	return n
+	return nodeTypeMap
+}
+
+type nodeRawMessageType string
+
+func (n nodeRawMessageType) id() string {
+	return string(n)
+}
+
+func (n nodeRawMessageType) expands(n2 nodeType) bool {
+	return n == n2
+}
+
+func (n nodeRawMessageType) fit(v interface{}) nodeType {
+	return nodeTypeRawMessage
 }
@@ -1,6 +1,7 @@
 package json2go
 
 import (
+	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -187,3 +188,104 @@ func TestTypeExpand(t *testing.T) {
 		})
 	}
 }
+
+func TestFitsFloat32(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    interface{}
+		expected bool
+	}{
+		{
+			name:     "not a number",
+			input:    "1.123456789123",
+			expected: true,
+		},
+		{
+			name:     "short float",
+			input:    0.1,
+			expected: true,
+		},
+		{
+			name:     "long float",
+			input:    3.141592653589793,
+			expected: false,
+		},
+		{
+			name:     "small int",
+			input:    1024,
+			expected: true,
+		},
+		{
+			name:     "big int",
+			input:    16777217,
+			expected: false,
+		},
+		{
+			name:     "2^24",
+			input:    float64(1 << 24),
+			expected: true,
+		},
+		{
+			name:     "-2^24",
+			input:    -float64(1 << 24),
+			expected: true,
+		},
+		{
+			name:     "2^24+1",
+			input:    float64(1<<24 + 1),
+			expected: false,
+		},
+		{
+			name:     "even int above 2^24",
+			input:    float64(1<<24 + 2),
+			expected: true,
+		},
+		{
+			name:     "round int above 2^24",
+			input:    1e10,
+			expected: true,
+		},
+		{
+			name:     "2^53",
+			input:    float64(1 << 53),
+			expected: false,
+		},
+		{
+			name:     "int64 2^53+1",
+			input:    int64(1<<53 + 1),
+			expected: false,
+		},
+		{
+			name:     "int32 2^24",
+			input:    int32(1 << 24),
+			expected: true,
+		},
+		{
+			name:     "array of short floats",
+			input:    []interface{}{0.5, 1.25, nil},
+			expected: true,
+		},
+		{
+			name:     "array with long float",
+			input:    []interface{}{0.5, []interface{}{1.000000001}},
+			expected: false,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, fitsFloat32(tc.input))
+		})
+	}
+
+	// Integers shortcut formatting, which should give the same results.
+	for _, base := range []float64{0, 1 << 24, 1 << 53} {
+		for d := -3.0; d <= 3; d++ {
+			for _, f := range []float64{base + d, -(base + d)} {
+				expected := strconv.FormatFloat(f, 'g', -1, 64) == strconv.FormatFloat(float64(float32(f)), 'g', -1, 32)
+				assert.Equal(t, expected, fitsFloat32(f), "%v", f)
+			}
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package json2go
+
+import (
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+)
+
+// typeCheckPackage is a name of package, in which generated code is type checked.
+const typeCheckPackage = "generated"
+
+// maxTypeCheckErrors limits number of type checking errors reported by Generate.
+const maxTypeCheckErrors = 10
+
+// typeCheck type checks generated code with imports used by it, see OptTypeCheck.
+func typeCheck(code string, ctx *astContext) error {
+	src := "package " + typeCheckPackage + "\n\n"
+	for _, spec := range ctx.importSpecs() {
+		src += "import " + spec + "\n"
+	}
+	src += "\n" + code + "\n"
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "generated.go", src, 0)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidCode, err)
+	}
+
+	var errs []string
+	conf := types.Config{
+		Importer: &typeCheckImporter{
+			std:      importer.ForCompiler(fset, "source", nil),
+			selected: selectedNames(file),
+		},
+		Error: func(err error) {
+			errs = append(errs, err.Error())
+		},
+	}
+	_, _ = conf.Check(typeCheckPackage, fset, []*ast.File{file}, nil)
+	if len(errs) == 0 {
+		return nil
+	}
+	if len(errs) > maxTypeCheckErrors {
+		errs = append(errs[:maxTypeCheckErrors], fmt.Sprintf("and %d more", len(errs)-maxTypeCheckErrors))
+	}
+	return fmt.Errorf("%w: %s", ErrInvalidCode, strings.Join(errs, "; "))
+}
+
+// typeCheckImporter imports standard packages from sources. Other packages may be not available, they are
+// replaced with packages declaring names selected from them in generated code as types, as generated code
+// refers to other packages' types only.
+type typeCheckImporter struct {
+	std      types.Importer
+	selected map[string][]string
+	packages map[string]*types.Package
+}
+
+func (imp *typeCheckImporter) Import(importPath string) (*types.Package, error) {
+	if isStdPackage(importPath) {
+		return imp.std.Import(importPath)
+	}
+	if pkg, ok := imp.packages[importPath]; ok {
+		return pkg, nil
+	}
+
+	name, _ := packageName(importPath)
+	pkg := types.NewPackage(importPath, name)
+	for _, sel := range imp.selected[importPath] {
+		tn := types.NewTypeName(token.NoPos, pkg, sel, nil)
+		types.NewNamed(tn, types.NewStruct(nil, nil), nil)
+		pkg.Scope().Insert(tn)
+	}
+	pkg.MarkComplete()
+
+	if imp.packages == nil {
+		imp.packages = make(map[string]*types.Package)
+	}
+	imp.packages[importPath] = pkg
+	return pkg, nil
+}
+
+// selectedNames returns names selected from imported packages in file, by import path.
+func selectedNames(file *ast.File) map[string][]string {
+	paths := make(map[string]string)
+	for _, spec := range file.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		name, _ := packageName(importPath)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		paths[name] = importPath
+	}
+
+	selected := make(map[string][]string)
+	seen := make(map[string]bool)
+	ast.Inspect(file, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if x, ok := sel.X.(*ast.Ident); ok {
+			if importPath, ok := paths[x.Name]; ok && !seen[importPath+"."+sel.Sel.Name] {
+				seen[importPath+"."+sel.Sel.Name] = true
+				selected[importPath] = append(selected[importPath], sel.Sel.Name)
+			}
+		}
+		return true
+	})
+	return selected
+}
+
+// isStdPackage checks if import path belongs to standard library, which has no dots in first path element.
+func isStdPackage(importPath string) bool {
+	return !strings.Contains(strings.Split(importPath, "/")[0], ".")
+}
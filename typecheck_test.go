@@ -0,0 +1,58 @@
+package json2go
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserTypeCheck(t *testing.T) {
+	t.Parallel()
+
+	input := `{"id":"a","price":1.5,"created":"2021-01-01T00:00:00Z","items":[{"n":1},null],"extra":{"x":1}}`
+
+	testCases := []struct {
+		name string
+		opts []JSONParserOpt
+	}{
+		{name: "default"},
+		{name: "decimal and custom types", opts: []JSONParserOpt{
+			OptDecimal(true),
+			OptOverrides(Overrides{Types: map[string]string{
+				"$.id":    "github.com/google/uuid.UUID",
+				"$.extra": "example.com/uuid.Extra",
+			}}),
+		}},
+		{name: "methods", opts: []JSONParserOpt{OptStringMethods(true), OptFastDecoders(true), OptUnknownFields(true)}},
+		{name: "presence", opts: []JSONParserOpt{OptPresenceTracking(true), OptOptionalType(true)}},
+		{name: "null elements", opts: []JSONParserOpt{OptNullElements(NullElementsWrapper), OptRootTypes(RootTypesDefined)}},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			parser := NewJSONParser(baseTypeName, append(tc.opts, OptTypeCheck(true))...)
+			require.NoError(t, parser.FeedBytes([]byte(input)))
+			_, err := parser.Generate()
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestParserTypeCheckError(t *testing.T) {
+	t.Parallel()
+
+	parser := NewJSONParser(baseTypeName, OptTypeCheck(true), OptOverrides(Overrides{
+		Types: map[string]string{"$.timeout": "time.Timeout"},
+	}))
+	require.NoError(t, parser.FeedBytes([]byte(`{"timeout":1}`)))
+
+	out, err := parser.Generate()
+	assert.True(t, errors.Is(err, ErrInvalidCode), "%v", err)
+	assert.Contains(t, err.Error(), "undefined: time.Timeout")
+	assert.Contains(t, out, "time.Timeout")
+}
@@ -0,0 +1,181 @@
+package json2go
+
+import (
+	"fmt"
+	"go/ast"
+	"strconv"
+	"strings"
+)
+
+// unknownFieldsField is a name of field keeping values of unknown keys.
+const unknownFieldsField = "Extra"
+
+// astHasKeyMethods checks if named type of node n gets json methods of keys, which can't be represented
+// in json tags, see astAddKeyMethods. Types with other json methods or embedded fields don't get them.
+func astHasKeyMethods(n *node, opts *options) bool {
+	wrapper := opts.collapseWrappers && astIsWrapperNode(n)
+	nestedKeys := n.document && opts.keySplitting == KeySplittingNested
+	ordered := opts.fieldOrder == FieldOrderOriginal && len(n.keyOrder) > 0
+	return n.t.id() == nodeTypeObject.id() && n.arrayLevel == 0 && n.jsonAPI != jsonAPIResource && !n.cloudEvent &&
+		!wrapper && !nestedKeys && !ordered && !opts.presenceTracking && !opts.easyJSON
+}
+
+// astAddKeyMethods adds UnmarshalJSON and MarshalJSON methods to named struct type, reading and writing values
+// of keys, which can't be represented in json tags, see markUntaggableKeys. If unknown is true, field keeping
+// values of keys unknown to type is added too.
+func astAddKeyMethods(n *node, st *ast.StructType, unknown bool, ctx *astContext) {
+	fieldNames := make(map[string]bool)
+	fieldTypes := make(map[string]ast.Expr)
+	for _, f := range st.Fields.List {
+		fieldNames[f.Names[0].Name] = true
+		fieldTypes[f.Names[0].Name] = f.Type
+	}
+	field := unknownFieldsField
+	for fieldNames[field] {
+		field = nextName(field)
+	}
+
+	var keys []string
+	var unmarshalRaw, marshalRaw strings.Builder
+	for _, c := range n.children {
+		keys = append(keys, strconv.Quote(c.key))
+		if !c.rawKey {
+			continue
+		}
+		fmt.Fprintf(&unmarshalRaw, `
+	if value, ok := keys[%[1]q]; ok {
+		if err := json.Unmarshal(value, &v.%[2]s); err != nil {
+			return err
+		}
+	}`, c.key, c.name)
+		value := fmt.Sprintf(`
+	if keys[%[1]q], err = json.Marshal(v.%[2]s); err != nil {
+		return nil, err
+	}`, c.key, c.name)
+		if cond := astNonEmptyCondition("v."+c.name, fieldTypes[c.name]); !c.required && cond != "" {
+			value = fmt.Sprintf(`
+	if %s {%s
+	}`, cond, strings.Replace(value, "\n", "\n\t", -1))
+		}
+		marshalRaw.WriteString(value)
+	}
+
+	ctx.addImport("encoding/json")
+	if !unknown {
+		ctx.addHelper(fmt.Sprintf(`
+// UnmarshalJSON unmarshals %[1]s, with values of keys, which can't be represented in json tags.
+func (v *%[1]s) UnmarshalJSON(data []byte) error {
+	type plain %[1]s
+	if err := json.Unmarshal(data, (*plain)(v)); err != nil {
+		return err
+	}
+
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return err
+	}%[2]s
+	return nil
+}
+
+// MarshalJSON marshals %[1]s, with values of keys, which can't be represented in json tags.
+func (v %[1]s) MarshalJSON() ([]byte, error) {
+	type plain %[1]s
+	data, err := json.Marshal(plain(v))
+	if err != nil {
+		return nil, err
+	}
+
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, err
+	}%[3]s
+	return json.Marshal(keys)
+}
+`, n.name, unmarshalRaw.String(), marshalRaw.String()))
+		return
+	}
+
+	st.Fields.List = append(st.Fields.List, &ast.Field{
+		Names: []*ast.Ident{ast.NewIdent(field)},
+		Type:  ast.NewIdent("map[string]json.RawMessage"),
+		Tag:   &ast.BasicLit{Value: "`json:\"-\"`"},
+	})
+
+	marshalData := `
+	data, err := json.Marshal(plain(v))
+	if err != nil || len(v.%[2]s) == 0 {
+		return data, err
+	}`
+	if marshalRaw.Len() > 0 {
+		marshalData = `
+	data, err := json.Marshal(plain(v))
+	if err != nil {
+		return nil, err
+	}`
+	}
+	ctx.addHelper(fmt.Sprintf(`
+// UnmarshalJSON unmarshals %[1]s, keeping values of unknown keys in %[2]s.
+func (v *%[1]s) UnmarshalJSON(data []byte) error {
+	type plain %[1]s
+	if err := json.Unmarshal(data, (*plain)(v)); err != nil {
+		return err
+	}
+
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return err
+	}%[4]s
+	for _, k := range []string{%[3]s} {
+		delete(keys, k)
+	}
+	v.%[2]s = nil
+	if len(keys) > 0 {
+		v.%[2]s = keys
+	}
+	return nil
+}
+
+// MarshalJSON marshals %[1]s with values of unknown keys from %[2]s.
+func (v %[1]s) MarshalJSON() ([]byte, error) {
+	type plain %[1]s`+marshalData+`
+
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, err
+	}%[5]s
+	for k, value := range v.%[2]s {
+		if _, ok := keys[k]; !ok {
+			keys[k] = value
+		}
+	}
+	return json.Marshal(keys)
+}
+`, n.name, field, strings.Join(keys, ", "), unmarshalRaw.String(), marshalRaw.String()))
+}
+
+// astNonEmptyCondition returns condition of value expression of given type, under which omitempty option
+// doesn't omit it, or empty string if value is never omitted.
+func astNonEmptyCondition(value string, t ast.Expr) string {
+	switch typ := t.(type) {
+	case *ast.StarExpr, *ast.InterfaceType:
+		return value + " != nil"
+	case *ast.MapType:
+		return "len(" + value + ") > 0"
+	case *ast.ArrayType:
+		if typ.Len == nil {
+			return "len(" + value + ") > 0"
+		}
+	case *ast.Ident:
+		switch typ.Name {
+		case "interface{}":
+			return value + " != nil"
+		case "string":
+			return value + ` != ""`
+		case "bool":
+			return value
+		case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "float32", "float64":
+			return value + " != 0"
+		}
+	}
+	return ""
+}
@@ -0,0 +1,56 @@
+package json2go
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserUnknownFields(t *testing.T) {
+	t.Parallel()
+
+	p := NewJSONParser("Document", OptUnknownFields(true))
+	require.NoError(t, p.FeedBytes([]byte(`{"id":1,"extra":"x","nested":{"a":1}}`)))
+	assert.Equal(t, "type Document struct {\n"+
+		"\tExtra  string `json:\"extra\"`\n"+
+		"\tID     int    `json:\"id\"`\n"+
+		"\tNested struct {\n"+
+		"\t\tA int `json:\"a\"`\n"+
+		"\t} `json:\"nested\"`\n"+
+		"\tExtra2 map[string]json.RawMessage `json:\"-\"`\n"+
+		"}", astPrintDecls(p.ASTDeclsWithOpt()[:1]))
+
+	input := `{"id":2,"extra":"y","nested":{"a":2},"added":[1,2],"other":{"b":true}}`
+	out := runGeneratedCode(t, p, `
+	var doc Document
+	if err := json.NewDecoder(os.Stdin).Decode(&doc); err != nil {
+		panic(err)
+	}
+	fmt.Println(len(doc.Extra2), string(doc.Extra2["added"]))
+	out, err := json.Marshal(doc)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Print(string(out))
+`, input)
+	assert.Equal(t, "2 [1,2]\n"+`{"added":[1,2],"extra":"y","id":2,"nested":{"a":2},"other":{"b":true}}`, out)
+}
+
+func TestParserUnknownFieldsWithOtherMethods(t *testing.T) {
+	t.Parallel()
+
+	p := NewJSONParser("Document", OptUnknownFields(true), OptPresenceTracking(true))
+	require.NoError(t, p.FeedBytes([]byte(`{"id":1}`)))
+	out, err := p.Generate()
+	require.NoError(t, err)
+	assert.NotContains(t, out, "json.RawMessage `json:\"-\"`")
+
+	p = NewJSONParser("Document", OptUnknownFields(true), OptFastDecoders(true))
+	require.NoError(t, p.FeedBytes([]byte(`{"id":1}`)))
+	out, err = p.Generate()
+	require.NoError(t, err)
+	assert.Contains(t, out, "keeping values of unknown keys in Extra")
+	assert.Equal(t, 1, strings.Count(out, "UnmarshalJSON(data []byte)"))
+}
@@ -0,0 +1,154 @@
+package json2go
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// maxGoValueDepth limits nesting of go values consumed by FeedGoValue, so values referencing themselves fail instead
+// of overflowing stack.
+const maxGoValueDepth = 1000
+
+// FromValue returns go representation of type of already decoded go value, like map[string]interface{} decoded by
+// other library, without serializing it to json first. See JSONParser.FeedGoValue for supported values.
+func FromValue(v interface{}, rootTypeName string, opts ...JSONParserOpt) (string, error) {
+	p := NewJSONParser(rootTypeName, opts...)
+	if err := p.FeedGoValue(v); err != nil {
+		return "", err
+	}
+
+	return p.Generate()
+}
+
+// FeedGoValue consumes go value, like values of decoded payloads held by services. Unlike FeedValue, values don't
+// have to be of types produced by json.Unmarshal, they are converted like json.Marshal would encode them:
+//
+//   - numbers of any numeric type and json.Number
+//   - strings, booleans and nil, also of named types and behind pointers and interfaces
+//   - slices and arrays, []byte is a base64 string
+//   - maps with string, integer or encoding.TextMarshaler keys
+//   - values implementing json.Marshaler or encoding.TextMarshaler, like time.Time
+//
+// Other values, like structs, fail with error matching ErrUnsupportedShape.
+func (p *JSONParser) FeedGoValue(v interface{}) error {
+	value, err := goValue(reflect.ValueOf(v), rootPath, 0)
+	if err != nil {
+		return err
+	}
+	return p.Infer(value)
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// goValue returns go value converted to value like json.Unmarshal decodes to empty interface.
+func goValue(v reflect.Value, path string, depth int) (interface{}, error) {
+	if depth > maxGoValueDepth {
+		return nil, fmt.Errorf("%w: %s: go value is nested deeper than %d levels", ErrDepthExceeded, path, maxGoValueDepth)
+	}
+	if !v.IsValid() {
+		return nil, nil
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return nil, nil
+		}
+	}
+
+	switch {
+	case v.Type() == reflect.TypeOf(json.Number("")):
+		f, err := v.Interface().(json.Number).Float64()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidJSON, path, err)
+		}
+		return f, nil
+	case v.Type().Implements(jsonMarshalerType):
+		data, err := v.Interface().(json.Marshaler).MarshalJSON()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		var out interface{}
+		if err := json.Unmarshal(data, &out); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, invalidJSONError{err: err})
+		}
+		return out, nil
+	case v.Type().Implements(textMarshalerType):
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return string(text), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return goValue(v.Elem(), path, depth+1)
+	case reflect.Bool:
+		return v.Bool(), nil
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			return base64.StdEncoding.EncodeToString(v.Bytes()), nil
+		}
+		out := make([]interface{}, v.Len())
+		for i := range out {
+			el, err := goValue(v.Index(i), path, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = el
+		}
+		return out, nil
+	case reflect.Map:
+		out := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key, err := goMapKey(iter.Key(), path)
+			if err != nil {
+				return nil, err
+			}
+			el, err := goValue(iter.Value(), childPath(path, key), depth+1)
+			if err != nil {
+				return nil, err
+			}
+			out[key] = el
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("%w: %s: go value of type %s isn't supported", ErrUnsupportedShape, path, v.Type())
+}
+
+// goMapKey returns key of go map as json key, like json.Marshal encodes it.
+func goMapKey(k reflect.Value, path string) (string, error) {
+	if k.Kind() == reflect.String {
+		return k.String(), nil
+	}
+	if k.Type().Implements(textMarshalerType) {
+		text, err := k.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", path, err)
+		}
+		return string(text), nil
+	}
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), nil
+	}
+	return "", fmt.Errorf("%w: %s: map key of type %s isn't supported", ErrUnsupportedShape, path, k.Type())
+}
@@ -0,0 +1,81 @@
+package json2go
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testLevel string
+
+type testCode int
+
+func (c testCode) MarshalText() ([]byte, error) {
+	return []byte("code-" + string(rune('a'+c))), nil
+}
+
+func TestFromValue(t *testing.T) {
+	t.Parallel()
+
+	count := 3
+	value := map[string]interface{}{
+		"id":      int64(1),
+		"count":   &count,
+		"ratio":   float32(0.5),
+		"amount":  json.Number("10"),
+		"level":   testLevel("debug"),
+		"tags":    []string{"a", "b"},
+		"scores":  [2]uint8{1, 2},
+		"payload": []byte("data"),
+		"created": time.Date(2020, 10, 3, 15, 4, 5, 0, time.UTC),
+		"raw":     json.RawMessage(`{"ok":true}`),
+		"codes":   map[testCode]bool{1: true},
+		"items":   map[int]map[string]interface{}{1: {"name": "x", "note": nil}},
+		"active":  true,
+		"missing": nil,
+	}
+	const input = `{"id":1,"count":3,"ratio":0.5,"amount":10,"level":"debug","tags":["a","b"],"scores":[1,2],
+		"payload":"ZGF0YQ==","created":"2020-10-03T15:04:05Z","raw":{"ok":true},"codes":{"code-b":true},
+		"items":{"1":{"name":"x","note":null}},"active":true,"missing":null}`
+
+	out, err := FromValue(value, baseTypeName)
+	require.NoError(t, err)
+	expected := NewJSONParser(baseTypeName)
+	require.NoError(t, expected.FeedBytes([]byte(input)))
+	expectedOut, err := expected.Generate()
+	require.NoError(t, err)
+	assert.Equal(t, expectedOut, out)
+
+	parser := NewJSONParser(baseTypeName)
+	require.NoError(t, parser.FeedGoValue([]map[string]int{{"a": 1}, {"a": 2, "b": 3}}))
+	assert.Equal(t, normalizeStr(`type Document []struct {
+		A int  `+"`json:\"a\"`"+`
+		B *int `+"`json:\"b,omitempty\"`"+`
+}`), normalizeStr(parser.String()))
+}
+
+func TestFromValueErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := FromValue(map[string]interface{}{"user": struct{ ID int }{1}}, baseTypeName)
+	assert.True(t, errors.Is(err, ErrUnsupportedShape), err)
+	assert.EqualError(t, err, "unsupported shape: $.user: go value of type struct { ID int } isn't supported")
+
+	_, err = FromValue(map[float64]int{1.5: 1}, baseTypeName)
+	assert.True(t, errors.Is(err, ErrUnsupportedShape), err)
+
+	_, err = FromValue(map[string]interface{}{"amount": json.Number("1x")}, baseTypeName)
+	assert.True(t, errors.Is(err, ErrInvalidJSON), err)
+
+	cyclic := map[string]interface{}{}
+	cyclic["self"] = cyclic
+	_, err = FromValue(cyclic, baseTypeName)
+	assert.True(t, errors.Is(err, ErrDepthExceeded), err)
+
+	_, err = FromValue(map[string]interface{}{"a": map[string]int{"b": 1}}, baseTypeName, OptMaxDepth(1))
+	assert.True(t, errors.Is(err, ErrDepthExceeded), err)
+}
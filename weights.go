@@ -0,0 +1,98 @@
+package json2go
+
+import (
+	"fmt"
+	"math"
+)
+
+// maxWeightScale is a scale of input weights, above which weights of tree are normalized, so they don't overflow.
+const maxWeightScale = 1e100
+
+// inputWeights assigns weights to consumed inputs, see FeedWeighted and OptRecencyDecay.
+// Presence of attributes is a ratio of weights, so instead of decaying weights of older inputs, weights of newer
+// inputs grow, and weights of tree are scaled down when they get too large.
+type inputWeights struct {
+	halfLife uint
+	scale    float64
+	// next is a weight of next input set with FeedWeighted, 0 means 1.
+	next float64
+	// current is a weight of input being consumed, shared by nodes.
+	current float64
+}
+
+// advance sets weight of next consumed input.
+func (w *inputWeights) advance(root *node) {
+	weight := 1.0
+	if w.next > 0 {
+		weight = w.next
+	}
+	if w.halfLife > 0 {
+		if w.scale == 0 {
+			w.scale = 1
+		} else {
+			w.scale *= math.Exp2(1 / float64(w.halfLife))
+		}
+		if w.scale > maxWeightScale {
+			root.scaleWeights(1 / w.scale)
+			w.scale = 1
+		}
+		weight *= w.scale
+	}
+	w.current = weight
+}
+
+// inputWeight returns weight of input being grown by node, 1 for nodes grown without weights.
+func (n *node) inputWeight() float64 {
+	if n.weight == nil {
+		return 1
+	}
+	return *n.weight
+}
+
+// scaleWeights multiplies weights of objects and attributes of tree by f.
+func (n *node) scaleWeights(f float64) {
+	n.weightedObjects *= f
+	n.weightedOccurrences *= f
+	for _, c := range n.children {
+		c.scaleWeights(f)
+	}
+	for _, pn := range n.tuple {
+		pn.scaleWeights(f)
+	}
+	if n.encoded != nil {
+		n.encoded.scaleWeights(f)
+	}
+}
+
+// presence returns weighted fraction of objects of parent with node's key, and if it differs from fraction of
+// their number.
+func (n *node) presence(parent *node) (float64, bool) {
+	if parent.weightedObjects <= 0 {
+		return 1, false
+	}
+	weighted := n.weightedOccurrences / parent.weightedObjects
+	return weighted, math.Abs(weighted-float64(n.occurrences)/float64(parent.objects)) > 1e-9
+}
+
+// presenceDesc returns readable presence of node in objects of parent, like "present in 1 of 3 objects".
+func (n *node) presenceDesc(parent *node, records string) string {
+	desc := fmt.Sprintf("present in %d of %d %s", n.occurrences, parent.objects, records)
+	if weighted, differs := n.presence(parent); differs {
+		desc += fmt.Sprintf(", %.0f%% by weight", weighted*100)
+	}
+	return desc
+}
+
+// FeedWeighted consumes json input as bytes, like FeedBytes, with weight of input in presence of attributes,
+// see OptCommentOutFields and OptStructuredLogs. Inputs consumed otherwise have weight 1, so samples representative
+// of current data may have higher weight than historical ones. Weight must be positive.
+func (p *JSONParser) FeedWeighted(input []byte, weight float64) error {
+	if !(weight > 0) || math.IsInf(weight, 1) {
+		return fmt.Errorf("invalid weight of input: %v", weight)
+	}
+	p.weights.next = weight
+	defer func() {
+		p.weights.next = 0
+	}()
+	return p.FeedBytes(input)
+}
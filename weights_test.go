@@ -0,0 +1,72 @@
+package json2go
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserRecencyDecay(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		halfLife uint
+		expected string
+	}{
+		{
+			name:     "without decay",
+			expected: "\tLegacy string `json:\"legacy,omitempty\"`\n",
+		},
+		{
+			name:     "decay",
+			halfLife: 2,
+			expected: "\t// Legacy: present in 4 of 8 objects, 20% by weight, uncomment to use it.\n",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := NewJSONParser(baseTypeName, OptCommentOutFields(0.25, false), OptRecencyDecay(tc.halfLife))
+			for i := 0; i < 4; i++ {
+				require.NoError(t, p.FeedBytes([]byte(`{"id":1,"legacy":"a"}`)))
+			}
+			for i := 0; i < 4; i++ {
+				require.NoError(t, p.FeedBytes([]byte(`{"id":1}`)))
+			}
+			out, err := p.Generate()
+			require.NoError(t, err)
+			assert.Contains(t, out, tc.expected)
+		})
+	}
+}
+
+func TestParserFeedWeighted(t *testing.T) {
+	t.Parallel()
+
+	p := NewJSONParser(baseTypeName, OptCommentOutFields(0.25, false))
+	require.NoError(t, p.FeedWeighted([]byte(`{"id":1,"legacy":"a"}`), 0.1))
+	require.NoError(t, p.FeedBytes([]byte(`{"id":1}`)))
+	out, err := p.Generate()
+	require.NoError(t, err)
+	assert.Contains(t, out, "\t// Legacy: present in 1 of 2 objects, 9% by weight, uncomment to use it.\n")
+
+	assert.EqualError(t, p.FeedWeighted([]byte(`{}`), 0), "invalid weight of input: 0")
+	assert.Error(t, p.FeedWeighted([]byte(`{}`), -1))
+}
+
+func TestInputWeightsNormalization(t *testing.T) {
+	t.Parallel()
+
+	p := NewJSONParser(baseTypeName, OptRecencyDecay(1))
+	for i := 0; i < 1000; i++ {
+		require.NoError(t, p.FeedBytes([]byte(`{"id":1}`)))
+	}
+	presence, _ := p.rootNode.getChild("id").presence(p.rootNode)
+	assert.InDelta(t, 1, presence, 1e-9)
+	assert.Less(t, p.rootNode.weightedObjects, maxWeightScale*2)
+}
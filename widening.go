@@ -0,0 +1,285 @@
+package json2go
+
+import (
+	"fmt"
+	"go/ast"
+	"strings"
+)
+
+// Widening is a go type of values of two different types, combined in one field, see WideningLattice.
+type Widening int
+
+const (
+	// WideningDefault uses default type of values, see WideningLattice.
+	WideningDefault Widening = iota
+	// WideningInterface uses interface{}.
+	WideningInterface
+	// WideningFloat uses float64, or float32 with OptFloat32.
+	WideningFloat
+	// WideningNumber uses json.Number, which is unmarshaled also from json strings with numbers.
+	WideningNumber
+	// WideningString uses helper string type, which is unmarshaled also from json numbers and bools, as their json
+	// representation.
+	WideningString
+)
+
+// ParseWidening returns widening by name: "default", "interface", "float", "number" or "string".
+// Empty name means default.
+func ParseWidening(name string) (Widening, error) {
+	switch name {
+	case "", "default":
+		return WideningDefault, nil
+	case "interface":
+		return WideningInterface, nil
+	case "float":
+		return WideningFloat, nil
+	case "number":
+		return WideningNumber, nil
+	case "string":
+		return WideningString, nil
+	}
+	return WideningDefault, fmt.Errorf("unknown widening: %s", name)
+}
+
+func (w Widening) String() string {
+	switch w {
+	case WideningInterface:
+		return "interface"
+	case WideningFloat:
+		return "float"
+	case WideningNumber:
+		return "number"
+	case WideningString:
+		return "string"
+	}
+	return "default"
+}
+
+// WideningLattice sets go types of values of two different types combined in one field, like integers and floating
+// point numbers. WideningDefault keeps default types, which are used also for widenings not allowed for pair:
+//
+//	pair             default    allowed
+//	int+float        float      float, number, interface
+//	number+string    interface  interface, number, string
+//	bool+string      interface  interface, string
+//	time+string      string     string, interface
+//
+// Values of more than two types, or of objects or arrays mixed with other values, are represented by interface{}.
+// Only go types are widened, schemas and types of other languages use defaults.
+type WideningLattice struct {
+	IntFloat     Widening
+	NumberString Widening
+	BoolString   Widening
+	TimeString   Widening
+}
+
+// wideningPairs are names of pairs of types in lattice specifications, with allowed widenings, the first one is
+// widening of default type.
+var wideningPairs = []struct {
+	name    string
+	field   func(l *WideningLattice) *Widening
+	allowed []Widening
+}{
+	{"int+float", func(l *WideningLattice) *Widening { return &l.IntFloat }, []Widening{WideningFloat, WideningNumber, WideningInterface}},
+	{"number+string", func(l *WideningLattice) *Widening { return &l.NumberString }, []Widening{WideningInterface, WideningNumber, WideningString}},
+	{"bool+string", func(l *WideningLattice) *Widening { return &l.BoolString }, []Widening{WideningInterface, WideningString}},
+	{"time+string", func(l *WideningLattice) *Widening { return &l.TimeString }, []Widening{WideningString, WideningInterface}},
+}
+
+// resolved returns lattice with WideningDefault of pairs replaced with widenings of their default types.
+func (l WideningLattice) resolved() WideningLattice {
+	for _, p := range wideningPairs {
+		if w := p.field(&l); *w == WideningDefault {
+			*w = p.allowed[0]
+		}
+	}
+	return l
+}
+
+// ParseWideningLattice returns lattice from comma separated list of pairs of types with their widenings, like
+// "int+float=number,number+string=string". Pairs missing in list use defaults.
+func ParseWideningLattice(spec string) (WideningLattice, error) {
+	var l WideningLattice
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 {
+			return WideningLattice{}, fmt.Errorf("invalid widening %q, expected pair=widening", item)
+		}
+		w, err := ParseWidening(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return WideningLattice{}, err
+		}
+		pair := strings.TrimSpace(parts[0])
+		found := false
+		for _, p := range wideningPairs {
+			if p.name != pair {
+				continue
+			}
+			found = true
+			if !isAllowedWidening(w, p.allowed) {
+				return WideningLattice{}, fmt.Errorf("widening %s isn't allowed for %s", w, pair)
+			}
+			*p.field(&l) = w
+		}
+		if !found {
+			return WideningLattice{}, fmt.Errorf("unknown widening pair: %s", pair)
+		}
+	}
+	return l, nil
+}
+
+// String returns specification of lattice, which can be parsed with ParseWideningLattice.
+func (l WideningLattice) String() string {
+	var items []string
+	for _, p := range wideningPairs {
+		if w := *p.field(&l); w != WideningDefault {
+			items = append(items, p.name+"="+w.String())
+		}
+	}
+	return strings.Join(items, ",")
+}
+
+func isAllowedWidening(w Widening, allowed []Widening) bool {
+	if w == WideningDefault {
+		return true
+	}
+	for _, a := range allowed {
+		if w == a {
+			return true
+		}
+	}
+	return false
+}
+
+// Bits of scalar types of values seen by node, see scalarTypes.
+const (
+	seenInt = 1 << iota
+	seenFloat
+	seenTime
+	seenString
+	// seenNonFinite are NaN and infinities, see OptNonFiniteNumbers.
+	seenNonFinite
+	// seenNegativeZero is a negative zero, which can't be represented with go integers.
+	seenNegativeZero
+)
+
+// scalarTypes returns bits of types of numbers and strings, or of all elements of arrays.
+func scalarTypes(v interface{}) int {
+	switch typedValue := v.(type) {
+	case []interface{}:
+		types := 0
+		for _, el := range typedValue {
+			types |= scalarTypes(el)
+		}
+		return types
+	case string:
+		// Checking prefix first avoids parsing all strings.
+		if len(typedValue) >= len("2006-01-02T15:04:05Z") && typedValue[4] == '-' && typedValue[10] == 'T' &&
+			nodeTypeTime.fit(typedValue) == nodeTypeTime {
+			return seenTime
+		}
+		return seenString
+	case bool, map[string]interface{}, nil:
+		return 0
+	}
+	types := seenFloat
+	if nodeTypeInt.fit(v) == nodeTypeInt {
+		types = seenInt
+	}
+	if f, ok := v.(float64); ok {
+		types |= nonFiniteTypes(f)
+	}
+	return types
+}
+
+// widening returns widening of values of node, which types were combined in one type, or WideningDefault.
+// Widenings not allowed for pair of types are reported as WideningDefault.
+func (l WideningLattice) widening(n *node) Widening {
+	var w Widening
+	var pair string
+	switch n.t.id() {
+	case nodeTypeFloat.id():
+		if n.seenTypes&seenInt != 0 && n.seenTypes&seenFloat != 0 {
+			w, pair = l.IntFloat, "int+float"
+		}
+	case nodeTypeString.id():
+		if n.seenTypes&seenTime != 0 && n.seenTypes&seenString != 0 {
+			w, pair = l.TimeString, "time+string"
+		}
+	case nodeTypeInterface.id():
+		if (n.arrayLevel > 0) != (n.seenKinds&kindArray != 0) {
+			// Arrays mixed with other values.
+			return WideningDefault
+		}
+		switch n.seenKinds &^ kindArray {
+		case kindNumber | kindString:
+			w, pair = l.NumberString, "number+string"
+		case kindBool | kindString:
+			w, pair = l.BoolString, "bool+string"
+		}
+	}
+	for _, p := range wideningPairs {
+		if p.name == pair && isAllowedWidening(w, p.allowed) {
+			return w
+		}
+	}
+	return WideningDefault
+}
+
+// widensInterface checks if values of node represented by interface{} by default are widened to other type.
+func (l WideningLattice) widensInterface(n *node) bool {
+	w := l.widening(n)
+	return n.t.id() == nodeTypeInterface.id() && w != WideningDefault && w != WideningInterface
+}
+
+// astTypeFromWideningLattice returns type of values of node widened with lattice, or nil for default type.
+func astTypeFromWideningLattice(n *node, ctx *astContext) ast.Expr {
+	switch ctx.opts.widening.widening(n) {
+	case WideningInterface:
+		if n.t.id() == nodeTypeInterface.id() {
+			return nil
+		}
+		return newEmptyInterfaceExpr()
+	case WideningFloat:
+		if n.t.id() == nodeTypeFloat.id() {
+			return nil
+		}
+		return astTypeFromFloatNode(n, ctx)
+	case WideningNumber:
+		ctx.addImport("encoding/json")
+		return ast.NewIdent("json.Number")
+	case WideningString:
+		if n.t.id() == nodeTypeString.id() {
+			return nil
+		}
+		return astScalarStringType(ctx)
+	}
+	return nil
+}
+
+// astScalarStringType returns helper string type, unmarshaled also from json numbers and bools.
+func astScalarStringType(ctx *astContext) ast.Expr {
+	ctx.addImport("encoding/json")
+	name := ctx.addSharedHelper("ScalarString", func(name string) string {
+		return fmt.Sprintf(`
+// %[1]s is a string, unmarshaled also from json numbers and bools, as their json representation.
+type %[1]s string
+
+// UnmarshalJSON unmarshals string from json string, number or bool.
+func (s *%[1]s) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		return json.Unmarshal(data, (*string)(s))
+	}
+	if string(data) != "null" {
+		*s = %[1]s(data)
+	}
+	return nil
+}
+`, name)
+	})
+	return ast.NewIdent(name)
+}
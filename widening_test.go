@@ -0,0 +1,184 @@
+package json2go
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWideningLattice(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		spec     string
+		expected WideningLattice
+		err      string
+	}{
+		{name: "empty", spec: ""},
+		{
+			name:     "pairs",
+			spec:     "int+float=number, number+string=string,time+string=default",
+			expected: WideningLattice{IntFloat: WideningNumber, NumberString: WideningString},
+		},
+		{name: "not allowed", spec: "bool+string=number", err: "widening number isn't allowed for bool+string"},
+		{name: "unknown pair", spec: "int+bool=string", err: "unknown widening pair: int+bool"},
+		{name: "unknown widening", spec: "int+float=decimal", err: "unknown widening: decimal"},
+		{name: "invalid", spec: "int+float", err: `invalid widening "int+float", expected pair=widening`},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			lattice, err := ParseWideningLattice(tc.spec)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, lattice)
+
+			parsed, err := ParseWideningLattice(lattice.String())
+			require.NoError(t, err)
+			assert.Equal(t, lattice, parsed)
+		})
+	}
+}
+
+func TestParserWidening(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		lattice  WideningLattice
+		inputs   []string
+		expected string
+	}{
+		{
+			name:     "int and float default",
+			inputs:   []string{`{"a":1}`, `{"a":1.5}`},
+			expected: "float64",
+		},
+		{
+			name:     "int and float number",
+			lattice:  WideningLattice{IntFloat: WideningNumber},
+			inputs:   []string{`{"a":1.5}`, `{"a":1}`},
+			expected: "json.Number",
+		},
+		{
+			name:     "only floats",
+			lattice:  WideningLattice{IntFloat: WideningNumber},
+			inputs:   []string{`{"a":1.5}`, `{"a":2.5}`},
+			expected: "float64",
+		},
+		{
+			name:     "number and string default",
+			inputs:   []string{`{"a":1}`, `{"a":"x"}`},
+			expected: "interface{}",
+		},
+		{
+			name:     "number and string",
+			lattice:  WideningLattice{NumberString: WideningString},
+			inputs:   []string{`{"a":1}`, `{"a":"x"}`},
+			expected: "ScalarString",
+		},
+		{
+			name:     "missing number or string",
+			lattice:  WideningLattice{NumberString: WideningString},
+			inputs:   []string{`{"a":1}`, `{"a":"x"}`, `{}`},
+			expected: "ScalarString",
+		},
+		{
+			name:     "array elements",
+			lattice:  WideningLattice{NumberString: WideningNumber},
+			inputs:   []string{`{"a":[1,"2"]}`},
+			expected: "[]json.Number",
+		},
+		{
+			name:     "arrays mixed with scalars",
+			lattice:  WideningLattice{NumberString: WideningNumber},
+			inputs:   []string{`{"a":[1]}`, `{"a":"2"}`},
+			expected: "interface{}",
+		},
+		{
+			name:     "bool and string",
+			lattice:  WideningLattice{BoolString: WideningString},
+			inputs:   []string{`{"a":true}`, `{"a":"x"}`},
+			expected: "ScalarString",
+		},
+		{
+			name:     "three kinds",
+			lattice:  WideningLattice{BoolString: WideningString, NumberString: WideningString},
+			inputs:   []string{`{"a":true}`, `{"a":"x"}`, `{"a":1}`},
+			expected: "interface{}",
+		},
+		{
+			name:     "time and string",
+			lattice:  WideningLattice{TimeString: WideningInterface},
+			inputs:   []string{`{"a":"x"}`, `{"a":"2020-01-01T00:00:00Z"}`},
+			expected: "interface{}",
+		},
+		{
+			name:     "not allowed",
+			lattice:  WideningLattice{TimeString: WideningNumber},
+			inputs:   []string{`{"a":"x"}`, `{"a":"2020-01-01T00:00:00Z"}`},
+			expected: "string",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			parser := NewJSONParser(baseTypeName, OptWidening(tc.lattice))
+			for _, input := range tc.inputs {
+				require.NoError(t, parser.FeedBytes([]byte(input)))
+			}
+
+			fields := parser.Fields()
+			require.Len(t, fields, 1)
+			assert.Equal(t, tc.expected, fields[0].Type)
+		})
+	}
+}
+
+func TestParserWideningStrict(t *testing.T) {
+	t.Parallel()
+
+	parser := NewJSONParser(baseTypeName, OptWidening(WideningLattice{NumberString: WideningNumber}), OptStrict(StrictnessInterfaces))
+	require.NoError(t, parser.FeedBytes([]byte(`{"a":1}`)))
+	require.NoError(t, parser.FeedBytes([]byte(`{"a":"2"}`)))
+	_, err := parser.Generate()
+	require.NoError(t, err)
+
+	parser = NewJSONParser(baseTypeName, OptWidening(WideningLattice{NumberString: WideningNumber}), OptStrict(StrictnessMixed))
+	require.NoError(t, parser.FeedBytes([]byte(`{"a":1}`)))
+	require.NoError(t, parser.FeedBytes([]byte(`{"a":"2"}`)))
+	_, err = parser.Generate()
+	assert.True(t, errors.Is(err, ErrStrict), "error: %v", err)
+}
+
+func TestParserWideningCode(t *testing.T) {
+	t.Parallel()
+
+	parser := NewJSONParser(baseTypeName, OptWidening(WideningLattice{NumberString: WideningString, IntFloat: WideningNumber}))
+	require.NoError(t, parser.FeedBytes([]byte(`{"id":1,"n":1}`)))
+	require.NoError(t, parser.FeedBytes([]byte(`{"id":"x","n":2.5}`)))
+
+	out := runGeneratedCode(t, parser, `
+	dec := json.NewDecoder(bufio.NewReader(os.Stdin))
+	for dec.More() {
+		var d Document
+		if err := dec.Decode(&d); err != nil {
+			panic(err)
+		}
+		fmt.Println(d.ID, d.N)
+	}
+`, `{"id":12,"n":3} {"id":"ab","n":0.5}`)
+	assert.Equal(t, "12 3\nab 0.5\n", out)
+}
@@ -0,0 +1,81 @@
+package json2go
+
+import (
+	"fmt"
+	"go/ast"
+)
+
+// astIsWrapperNode checks if node is an object with exactly one key everywhere it appears, which value can be
+// type with methods, see OptCollapseWrappers.
+func astIsWrapperNode(n *node) bool {
+	if n.t != nodeTypeObject || n.document || (n.root && n.arrayLevel > 0) || len(n.children) != 1 || len(n.commented) > 0 ||
+		n.cloudEvent || n.jsonAPI != jsonAPINone || n.halEmbedded {
+		return false
+	}
+	value := n.children[0]
+	if !value.required || value.nullable || (value.pointer != nil && *value.pointer) || value.cloudEventAttribute {
+		return false
+	}
+	switch value.t.(type) {
+	case nodeInterfaceType, nodeInitType:
+		// Methods can't be declared on interface types.
+		return value.arrayLevel > 0
+	}
+	return true
+}
+
+// astTypeFromWrapperNode returns helper type of value of wrapper object, with json methods converting it from/to
+// object. Extracted wrapper types are declared with type of value, so only their methods are added.
+func astTypeFromWrapperNode(n *node, ctx *astContext) ast.Expr {
+	value := n.children[0]
+	ctx.addImport("encoding/json")
+	valueType := astTypeFromNode(value, ctx)
+
+	name := n.name
+	typeDecl := ""
+	if !n.root {
+		if n.arrayLevel > 0 {
+			name = singularName(name, ctx.opts.singulars)
+		}
+		name = ctx.uniqueName(name)
+		typeDecl = fmt.Sprintf(`
+// %[1]s is a value of %[2]q key of json object wrapping it.
+type %[1]s %[3]s
+`, name, value.key, astExprString(valueType))
+	}
+
+	// Value is unwrapped to its own type, so its json methods are used. Values of anonymous struct types are
+	// unwrapped to plain type with the same fields, without repeating them.
+	plain, valueExpr := "", astExprString(valueType)
+	if _, ok := valueType.(*ast.StructType); ok {
+		plain, valueExpr = "type plain "+name+"\n\t", "plain"
+	}
+	ctx.addHelper(fmt.Sprintf(`%[1]s
+// UnmarshalJSON unmarshals value from json object wrapping it.
+func (v *%[2]s) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	%[4]svar wrapper struct {
+		Value %[5]s %[3]s
+	}
+	if err := json.Unmarshal(data, &wrapper); err != nil {
+		return err
+	}
+	*v = %[2]s(wrapper.Value)
+	return nil
+}
+
+// MarshalJSON marshals value wrapped in json object.
+func (v %[2]s) MarshalJSON() ([]byte, error) {
+	%[4]sreturn json.Marshal(struct {
+		Value %[5]s %[3]s
+	}{%[5]s(v)})
+}
+`, typeDecl, name, astJSONTag(value.key).Value, plain, valueExpr))
+
+	if n.root {
+		return valueType
+	}
+	return ast.NewIdent(name)
+}
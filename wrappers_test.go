@@ -0,0 +1,111 @@
+package json2go
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserCollapseWrappers(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		collapse bool
+		inputs   []string
+		expected string
+	}{
+		{
+			name:     "disabled",
+			inputs:   []string{`{"price":{"value":3}}`},
+			expected: "struct{...}",
+		},
+		{
+			name:     "scalar",
+			collapse: true,
+			inputs:   []string{`{"price":{"value":3}}`},
+			expected: "Price",
+		},
+		{
+			name:     "array",
+			collapse: true,
+			inputs:   []string{`{"prices":[{"value":3},{"value":4}]}`},
+			expected: "[]Price",
+		},
+		{
+			name:     "nullable wrapper",
+			collapse: true,
+			inputs:   []string{`{"price":{"value":3}}`, `{"price":null}`},
+			expected: "*Price",
+		},
+		{
+			name:     "missing key",
+			collapse: true,
+			inputs:   []string{`{"price":{"value":3}}`, `{"price":{}}`},
+			expected: "struct{...}",
+		},
+		{
+			name:     "other keys",
+			collapse: true,
+			inputs:   []string{`{"price":{"value":3}}`, `{"price":{"value":3,"currency":"EUR"}}`},
+			expected: "struct{...}",
+		},
+		{
+			name:     "interface value",
+			collapse: true,
+			inputs:   []string{`{"price":{"value":3}}`, `{"price":{"value":"3"}}`},
+			expected: "struct{...}",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			parser := NewJSONParser(baseTypeName, OptCollapseWrappers(tc.collapse))
+			for _, input := range tc.inputs {
+				require.NoError(t, parser.FeedBytes([]byte(input)))
+			}
+
+			fields := parser.Fields()
+			require.NotEmpty(t, fields)
+			assert.Equal(t, tc.expected, fields[0].Type)
+		})
+	}
+}
+
+func TestParserCollapseWrappersExtracted(t *testing.T) {
+	t.Parallel()
+
+	parser := NewJSONParser(baseTypeName, OptCollapseWrappers(true), OptExtractCommonTypes(true))
+	require.NoError(t, parser.FeedBytes([]byte(`{"price":{"amount":3},"tax":{"amount":1}}`)))
+
+	out, err := parser.Generate()
+	require.NoError(t, err)
+	assert.Contains(t, out, "\ntype Amount int\n")
+	assert.Contains(t, out, "func (v *Amount) UnmarshalJSON(data []byte) error {")
+}
+
+func TestParserCollapseWrappersCode(t *testing.T) {
+	t.Parallel()
+
+	parser := NewJSONParser(baseTypeName, OptCollapseWrappers(true))
+	require.NoError(t, parser.FeedBytes([]byte(`{"id":{"v":"a"},"tags":[{"tag":{"name":"x"}}],"meta":{"amount":{"n":2,"c":"x"}}}`)))
+
+	out := runGeneratedCode(t, parser, `
+	var d Document
+	if err := json.NewDecoder(os.Stdin).Decode(&d); err != nil {
+		panic(err)
+	}
+	fmt.Println(d.ID, d.Tags[0], d.Meta.N)
+	out, err := json.Marshal(d)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(out))
+`, `{"id":{"v":"b"},"tags":[{"tag":{"name":"y"}}],"meta":{"amount":{"n":5,"c":"z"}}}`)
+	assert.Equal(t, "b y 5\n"+
+		`{"id":{"v":"b"},"meta":{"amount":{"c":"z","n":5}},"tags":[{"tag":{"name":"y"}}]}`+"\n", out)
+}
@@ -0,0 +1,134 @@
+package json2go
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// jsIdentifierRe matches JavaScript identifiers, which don't need quotes as object keys.
+var jsIdentifierRe = regexp.MustCompile(`^[A-Za-z_$][\w$]*$`)
+
+// ZodSchema returns TypeScript module with Zod schemas describing parsed documents, for runtime validation
+// of documents in frontends, and types inferred from schemas. Root schema and schemas of extracted types are exported,
+// root schema last. Unlike Schema, schemas are derived from parsed values:
+//
+//   - strings with uuids, email addresses or urls have Zod refinements of formats,
+//   - strings with few distinct keyword values, each seen twice on average, are enums,
+//   - times are datetime strings, and numbers of integer types are integers,
+//   - attributes missing in some objects are optional.
+//
+// Formats and enums of strings are tracked by parser only with OptStringFormats.
+func (p *JSONParser) ZodSchema() (string, error) {
+	nodes, err := p.outputNodes()
+	if err != nil {
+		return "", err
+	}
+	z := zodSchema{declared: make(map[string]bool)}
+
+	var b strings.Builder
+	b.WriteString("import { z } from \"zod\";\n")
+	for i := len(nodes) - 1; i >= 0; i-- {
+		n := nodes[i]
+		fmt.Fprintf(&b, "\nexport const %[1]s = %[2]s;\nexport type %[1]s = z.infer<typeof %[1]s>;\n", n.name, z.value(n, ""))
+		z.declared[n.name] = true
+	}
+	return b.String(), nil
+}
+
+// zodSchema builds Zod schemas of nodes.
+type zodSchema struct {
+	// declared are names of declared schemas, other schemas are referenced lazily.
+	declared map[string]bool
+}
+
+// value returns Zod schema of node's values, with lines indented with indent.
+func (z zodSchema) value(n *node, indent string) string {
+	var schema string
+	switch n.t.id() {
+	case nodeTypeBool.id():
+		schema = "z.boolean()"
+	case nodeTypeInt.id():
+		schema = "z.number().int()"
+	case nodeTypeFloat.id():
+		schema = "z.number()"
+	case nodeTypeString.id():
+		schema = z.string(n)
+	case nodeTypeTime.id():
+		schema = "z.string().datetime({ offset: true })"
+	case nodeTypeObject.id():
+		schema = z.object(n, indent)
+	case nodeTypeMap.id():
+		elem := "z.unknown()"
+		if len(n.children) > 0 {
+			elem = z.value(n.children[0], indent)
+		}
+		schema = "z.record(z.string(), " + elem + ")"
+	case nodeTypeExtracted.id():
+		switch {
+		case isGoType(n.externalTypeID):
+			// Custom types may have any json representation.
+			schema = "z.unknown()"
+		case z.declared[n.externalTypeID]:
+			schema = n.externalTypeID
+		default:
+			schema = "z.lazy(() => " + n.externalTypeID + ")"
+		}
+	default:
+		schema = "z.unknown()"
+	}
+
+	unknown := schema == "z.unknown()"
+	for i := n.arrayLevel; i > 0; i-- {
+		if n.arrayWithNulls && i == n.arrayLevel && !unknown {
+			schema += ".nullable()"
+		}
+		schema = "z.array(" + schema + ")"
+		unknown = false
+	}
+	if n.nullable && !n.root && !unknown {
+		schema += ".nullable()"
+	}
+	return schema
+}
+
+// string returns Zod schema of strings, with format refinement or enum values.
+func (z zodSchema) string(n *node) string {
+	switch n.formats & (formatUUID | formatEmail | formatURL) {
+	case formatUUID:
+		return "z.string().uuid()"
+	case formatEmail:
+		return "z.string().email()"
+	case formatURL:
+		return "z.string().url()"
+	}
+	if n.isEnum() {
+		values, _ := json.Marshal(n.enumValues)
+		return "z.enum(" + strings.Replace(string(values), ",", ", ", -1) + ")"
+	}
+	return "z.string()"
+}
+
+// object returns Zod schema of objects with node's attributes.
+func (z zodSchema) object(n *node, indent string) string {
+	if len(n.children) == 0 {
+		return "z.object({})"
+	}
+	var b strings.Builder
+	b.WriteString("z.object({\n")
+	for _, c := range n.children {
+		key := c.key
+		if !jsIdentifierRe.MatchString(key) {
+			quoted, _ := json.Marshal(key)
+			key = string(quoted)
+		}
+		schema := z.value(c, indent+"  ")
+		if !c.required && schema != "z.unknown()" {
+			schema += ".optional()"
+		}
+		fmt.Fprintf(&b, "%s  %s: %s,\n", indent, key, schema)
+	}
+	b.WriteString(indent + "})")
+	return b.String()
+}
@@ -0,0 +1,125 @@
+package json2go
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestZodSchema(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		opts     []JSONParserOpt
+		inputs   []string
+		expected string
+	}{
+		{
+			name: "formats and enums",
+			inputs: []string{
+				`{"id": "3f2504e0-4f89-11d3-9a0c-0305e82c3301", "email": "a@example.com", "site": "https://example.com",
+					"status": "active", "user-name": "a b", "at": "2021-01-01T00:00:00Z", "n": 1, "f": 1.5, "any": 1}`,
+				`{"id": "4f2504e0-4f89-11d3-9a0c-0305e82c3301", "email": "b@example.com", "site": "http://example.com/b",
+					"status": "disabled", "at": "2021-01-01T00:00:00Z", "n": 2, "f": 2, "any": null}`,
+				`{"id": "5f2504e0-4f89-11d3-9a0c-0305e82c3301", "email": "c@example.com", "site": "http://example.com/c",
+					"status": "active", "at": null, "n": 3, "f": 3, "any": null}`,
+				`{"id": "6f2504e0-4f89-11d3-9a0c-0305e82c3301", "email": "d@example.com", "site": "http://example.com/d",
+					"status": "disabled", "at": "2021-01-01T00:00:00Z", "n": 4, "f": 4, "any": "a"}`,
+			},
+			expected: `import { z } from "zod";
+
+export const Document = z.object({
+  any: z.unknown(),
+  at: z.string().datetime({ offset: true }).nullable(),
+  email: z.string().email(),
+  f: z.number(),
+  id: z.string().uuid(),
+  n: z.number().int(),
+  site: z.string().url(),
+  status: z.enum(["active", "disabled"]),
+  "user-name": z.string().optional(),
+});
+export type Document = z.infer<typeof Document>;
+`,
+		},
+		{
+			name: "extracted types",
+			opts: []JSONParserOpt{OptExtractCommonTypes(true)},
+			inputs: []string{
+				`[{"from": {"city": "a", "zip": "1"}, "to": {"city": "b", "zip": "2"}, "tags": {"a": 1}}]`,
+			},
+			expected: `import { z } from "zod";
+
+export const CityZip = z.object({
+  city: z.string(),
+  zip: z.string(),
+});
+export type CityZip = z.infer<typeof CityZip>;
+
+export const Document = z.array(z.object({
+  from: CityZip,
+  tags: z.object({
+    a: z.number().int(),
+  }),
+  to: CityZip,
+}));
+export type Document = z.infer<typeof Document>;
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := NewJSONParser(baseTypeName, append(tc.opts, OptStringFormats(true))...)
+			for _, input := range tc.inputs {
+				require.NoError(t, p.FeedBytes([]byte(input)))
+			}
+			src, err := p.ZodSchema()
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, src)
+		})
+	}
+}
+
+func TestNodeIsEnum(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		inputs   []interface{}
+		expected bool
+		// merged is expected for node merged with its copy, with values seen twice.
+		merged bool
+	}{
+		{name: "repeated values", inputs: []interface{}{"a", "b", "a", nil, "b"}, expected: true, merged: true},
+		{name: "arrays", inputs: []interface{}{[]interface{}{"a", "b"}, []interface{}{"b", "a", nil}}, expected: true, merged: true},
+		{name: "distinct values", inputs: []interface{}{"a", "b", "c"}, expected: false, merged: true},
+		{name: "text", inputs: []interface{}{"a b", "a b"}, expected: false},
+		{name: "numbers", inputs: []interface{}{"1", "2", "1", "2"}, expected: false},
+		{name: "too many values", inputs: []interface{}{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k",
+			"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k"}, expected: false},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			n := newNode("")
+			for _, input := range tc.inputs {
+				n.grow(input)
+			}
+			assert.Equal(t, tc.expected, n.isEnum())
+
+			merged, err := mergeNodes([]*node{n, n.clone()})
+			require.NoError(t, err)
+			assert.Equal(t, tc.merged, merged.isEnum())
+			assert.Equal(t, 2*n.stringValues, merged.stringValues)
+		})
+	}
+}